@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/marcus/sidecar/internal/adapter/imported"
+	"github.com/marcus/sidecar/internal/app"
+)
+
+// runImport implements "sidecar import FILE...". Each file is registered as
+// a read-only session of the project, browsable in the conversations plugin.
+// It returns the process exit code.
+func runImport(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	project := fs.String("project", *projectRoot, "project to import the sessions into")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: sidecar import [-project dir] FILE...\n\n")
+		fmt.Fprintf(os.Stderr, "Import sessions from JSON transcripts (J in the conversation view),\n")
+		fmt.Fprintf(os.Stderr, "Markdown exports (E), or raw Claude Code and Codex JSONL files.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	workDir, err := filepath.Abs(*project)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to resolve project root: %v\n", err)
+		return 1
+	}
+	// Sessions belong to the main worktree, where the plugin detects them
+	root := app.GetMainWorktreePath(workDir)
+	if root == "" {
+		root = workDir
+	}
+
+	store := imported.New()
+	status := 0
+	for _, path := range fs.Args() {
+		s, format, err := store.Import(root, path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to import %s: %v\n", path, err)
+			status = 1
+			continue
+		}
+		fmt.Printf("Imported %q (%d messages, %s) from %s\n", s.Name, s.MessageCount, format, path)
+	}
+	return status
+}
@@ -15,10 +15,12 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/marcus/sidecar/internal/adapter"
 	_ "github.com/marcus/sidecar/internal/adapter/amp"
+	_ "github.com/marcus/sidecar/internal/adapter/archive"
 	_ "github.com/marcus/sidecar/internal/adapter/claudecode"
 	_ "github.com/marcus/sidecar/internal/adapter/codex"
 	_ "github.com/marcus/sidecar/internal/adapter/cursor"
 	_ "github.com/marcus/sidecar/internal/adapter/geminicli"
+	_ "github.com/marcus/sidecar/internal/adapter/imported"
 	_ "github.com/marcus/sidecar/internal/adapter/kiro"
 	_ "github.com/marcus/sidecar/internal/adapter/opencode"
 	_ "github.com/marcus/sidecar/internal/adapter/pi"
@@ -58,6 +60,10 @@ var (
 func main() {
 	flag.Parse()
 
+	if flag.Arg(0) == "import" {
+		os.Exit(runImport(flag.Args()[1:]))
+	}
+
 	// Unset TMUX so sidecar's internal tmux sessions are independent of any
 	// outer tmux session. This allows prefix+d to detach from the workspace's
 	// inner session rather than the user's outer tmux.
@@ -268,7 +274,8 @@ func effectiveVersion(v string) string {
 func init() {
 	// Customize usage output
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: sidecar [options]\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: sidecar [options]\n")
+		fmt.Fprintf(os.Stderr, "       sidecar [options] import FILE...\n\n")
 		fmt.Fprintf(os.Stderr, "A TUI dashboard for AI coding agents.\n\n")
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		flag.PrintDefaults()
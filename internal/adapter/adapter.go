@@ -33,6 +33,22 @@ type TargetedRefresher interface {
 	SessionByID(sessionID string) (*Session, error)
 }
 
+// Archiver is an optional interface for adapters that keep sidecar's own copy
+// of other adapters' sessions, so they outlive the agents' history pruning.
+type Archiver interface {
+	// SetRetention sets how long archived sessions are kept after their last
+	// activity and how much space the archive may use. Zero keeps the
+	// default; a negative value removes the limit.
+	SetRetention(maxAge time.Duration, maxBytes int64)
+	// ArchivedUpdatedAt returns the UpdatedAt of a session's archived copy.
+	ArchivedUpdatedAt(sessionID string) (time.Time, bool)
+	// ArchiveSession stores or replaces the copy of a session that belongs
+	// to the project at projectRoot.
+	ArchiveSession(projectRoot string, s Session, messages []Message) error
+	// Prune removes archived sessions beyond the retention limits.
+	Prune() error
+}
+
 // WatchScope indicates whether an adapter watches global or per-project paths.
 type WatchScope int
 
@@ -88,6 +104,10 @@ const (
 	SessionCategorySystem      = "system"
 )
 
+// DeletedWorktreeSuffix is appended to the worktree name of sessions whose
+// worktree no longer exists.
+const DeletedWorktreeSuffix = " (deleted)"
+
 // Session represents an AI coding session.
 type Session struct {
 	ID           string
@@ -116,6 +136,18 @@ type Session struct {
 	// Worktree fields - populated when session is from a different worktree
 	WorktreeName string // Branch name or directory name of the worktree (empty if main or non-worktree)
 	WorktreePath string // Absolute path to the worktree (empty if same as current workdir)
+
+	// Archived is true for sessions served from sidecar's archive rather than
+	// the agent's own storage.
+	Archived bool
+
+	// Imported is true for read-only sessions imported from a transcript
+	// file rather than recorded on this machine.
+	Imported bool
+
+	// Sub-agent lineage, reported by adapters where available
+	ParentSessionID  string `json:"parentSessionId,omitempty"`  // Session that spawned this sub-agent
+	ParentToolCallID string `json:"parentToolCallId,omitempty"` // Tool call in the parent that spawned it
 }
 
 // SizeLevel returns the severity level for this session's file size.
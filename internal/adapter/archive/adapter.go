@@ -0,0 +1,300 @@
+package archive
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/adapter/store"
+)
+
+const (
+	adapterID   = "archive"
+	adapterName = "Archive"
+	adapterIcon = "▤"
+
+	defaultMaxAge   = 365 * 24 * time.Hour
+	defaultMaxBytes = 1 << 30 // 1 GiB
+)
+
+// entry is the index record for one archived session.
+type entry struct {
+	store.Entry
+	Size       int64     `json:"size"` // compressed size of the message file
+	ArchivedAt time.Time `json:"archivedAt"`
+}
+
+// Adapter implements the adapter.Adapter interface for archived sessions.
+type Adapter struct {
+	dir   string
+	index *store.Index[entry]
+
+	mu       sync.Mutex // guards the retention limits
+	maxAge   time.Duration
+	maxBytes int64
+}
+
+// New creates an archive adapter stored in ~/.config/sidecar/archive.
+func New() *Adapter {
+	home, _ := os.UserHomeDir()
+	return NewWithDir(filepath.Join(home, ".config", "sidecar", "archive"))
+}
+
+// NewWithDir creates an archive adapter stored in dir.
+func NewWithDir(dir string) *Adapter {
+	return &Adapter{
+		dir:      dir,
+		index:    store.NewIndex(dir, func(e *entry) *store.Entry { return &e.Entry }),
+		maxAge:   defaultMaxAge,
+		maxBytes: defaultMaxBytes,
+	}
+}
+
+// ID returns the adapter identifier.
+func (a *Adapter) ID() string { return adapterID }
+
+// Name returns the human-readable adapter name.
+func (a *Adapter) Name() string { return adapterName }
+
+// Icon returns the adapter icon for badge display.
+func (a *Adapter) Icon() string { return adapterIcon }
+
+// Detect reports whether the archive holds sessions for the project.
+func (a *Adapter) Detect(projectRoot string) (bool, error) {
+	return a.index.HasProject(projectRoot)
+}
+
+// Capabilities returns the supported features. The archive only changes
+// when sidecar writes to it, so it has nothing to watch.
+func (a *Adapter) Capabilities() adapter.CapabilitySet {
+	return adapter.CapabilitySet{
+		adapter.CapSessions: true,
+		adapter.CapMessages: true,
+		adapter.CapUsage:    true,
+	}
+}
+
+// SetRetention sets the archive's age and size limits.
+func (a *Adapter) SetRetention(maxAge time.Duration, maxBytes int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.maxAge = defaultMaxAge
+	if maxAge != 0 {
+		a.maxAge = maxAge
+	}
+	a.maxBytes = defaultMaxBytes
+	if maxBytes != 0 {
+		a.maxBytes = maxBytes
+	}
+}
+
+// Sessions returns the archived sessions for the given project, sorted by
+// update time. They keep the originating agent's name and icon.
+func (a *Adapter) Sessions(projectRoot string) ([]adapter.Session, error) {
+	entries, err := a.index.Project(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []adapter.Session
+	for _, e := range entries {
+		s := e.Session
+		s.AdapterID = adapterID
+		s.Archived = true
+		s.IsActive = false
+		s.Path = ""
+		s.FileSize = 0
+		sessions = append(sessions, s)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].UpdatedAt.After(sessions[j].UpdatedAt)
+	})
+	return sessions, nil
+}
+
+// Messages returns the archived messages of a session.
+func (a *Adapter) Messages(sessionID string) ([]adapter.Message, error) {
+	e, err := a.index.Get(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return nil, fmt.Errorf("session %s is not archived", sessionID)
+	}
+
+	f, err := os.Open(filepath.Join(a.dir, e.File))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = zr.Close() }()
+
+	var messages []adapter.Message
+	if err := json.NewDecoder(zr).Decode(&messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// Usage returns token totals computed from the archived messages.
+func (a *Adapter) Usage(sessionID string) (*adapter.UsageStats, error) {
+	messages, err := a.Messages(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return store.Usage(messages), nil
+}
+
+// Watch is not supported; the archive has no external writers to follow.
+func (a *Adapter) Watch(projectRoot string) (<-chan adapter.Event, io.Closer, error) {
+	return nil, nil, nil
+}
+
+// DiscoverRelatedProjectDirs returns archived project roots that share the
+// main worktree's repository base name, so sessions from deleted worktrees
+// stay listed after their agent's own files are gone.
+func (a *Adapter) DiscoverRelatedProjectDirs(mainWorktreePath string) ([]string, error) {
+	absMain, err := filepath.Abs(mainWorktreePath)
+	if err != nil {
+		return nil, nil
+	}
+	repoName := filepath.Base(absMain)
+	if repoName == "" || repoName == "." || repoName == "/" {
+		return nil, nil
+	}
+
+	projects, err := a.index.Projects()
+	if err != nil {
+		return nil, err
+	}
+	var related []string
+	for _, project := range projects {
+		base := filepath.Base(project)
+		if base == repoName || strings.HasPrefix(base, repoName+"-") {
+			related = append(related, project)
+		}
+	}
+	return related, nil
+}
+
+// ArchivedUpdatedAt returns the UpdatedAt of a session's archived copy.
+func (a *Adapter) ArchivedUpdatedAt(sessionID string) (time.Time, bool) {
+	e, err := a.index.Get(sessionID)
+	if err != nil || e == nil {
+		return time.Time{}, false
+	}
+	return e.Session.UpdatedAt, true
+}
+
+// ArchiveSession writes a compressed copy of the session's messages and
+// records it in the index, replacing any earlier copy.
+func (a *Adapter) ArchiveSession(projectRoot string, s adapter.Session, messages []adapter.Message) error {
+	if s.Archived || s.Imported || s.AdapterID == adapterID {
+		return nil
+	}
+
+	name := store.SessionFile(s.AdapterID+"\x00"+s.ID, ".json.gz")
+	size, err := a.writeMessages(name, messages)
+	if err != nil {
+		return err
+	}
+
+	// Store the session as the agent reported it, minus per-load details.
+	// The worktree name is kept since it can't be derived once the worktree
+	// is deleted.
+	s.IsActive = false
+	s.Path = ""
+	s.WorktreeName = strings.TrimSuffix(s.WorktreeName, adapter.DeletedWorktreeSuffix)
+
+	return a.index.Put(&entry{
+		Entry:      store.Entry{Session: s, Project: store.ProjectKey(projectRoot), File: name},
+		Size:       size,
+		ArchivedAt: time.Now(),
+	})
+}
+
+// Prune removes sessions inactive for longer than the maximum age, then the
+// least recently active sessions until the archive fits its size limit.
+func (a *Adapter) Prune() error {
+	a.mu.Lock()
+	maxAge, maxBytes := a.maxAge, a.maxBytes
+	a.mu.Unlock()
+
+	// Files removed before a failure stay dropped from the index
+	var removeErr error
+	err := a.index.Update(func(index map[string]*entry) bool {
+		entries := make([]*entry, 0, len(index))
+		for _, e := range index {
+			entries = append(entries, e)
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].Session.UpdatedAt.After(entries[j].Session.UpdatedAt)
+		})
+
+		var total int64
+		removed := 0
+		for _, e := range entries {
+			expired := maxAge > 0 && time.Since(e.Session.UpdatedAt) > maxAge
+			if !expired && (maxBytes < 0 || total+e.Size <= maxBytes) {
+				total += e.Size
+				continue
+			}
+			if err := os.Remove(filepath.Join(a.dir, e.File)); err != nil && !os.IsNotExist(err) {
+				removeErr = err
+				break
+			}
+			delete(index, e.Session.ID)
+			removed++
+		}
+		return removed > 0
+	})
+	if removeErr != nil {
+		return removeErr
+	}
+	return err
+}
+
+// writeMessages writes the gzipped message file, returning its size.
+func (a *Adapter) writeMessages(name string, messages []adapter.Message) (int64, error) {
+	path := filepath.Join(a.dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return 0, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".archive-*")
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	zw := gzip.NewWriter(tmp)
+	err = json.NewEncoder(zw).Encode(messages)
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return 0, err
+	}
+	info, err := os.Stat(tmp.Name())
+	if err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
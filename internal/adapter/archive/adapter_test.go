@@ -0,0 +1,146 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/marcus/sidecar/internal/adapter"
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	a := NewWithDir(t.TempDir())
+	if found, _ := a.Detect("/proj"); found {
+		t.Error("an empty archive should not be detected")
+	}
+	updated := time.Now().Add(-time.Hour).Truncate(time.Second)
+	s := adapter.Session{
+		ID:          "ses-1",
+		Name:        "Fix login",
+		AdapterID:   "claude-code",
+		AdapterName: "Claude Code",
+		AdapterIcon: "◆",
+		UpdatedAt:   updated,
+		IsActive:    true,
+		Path:        "/home/u/.claude/projects/x/ses-1.jsonl",
+	}
+	messages := []adapter.Message{
+		{ID: "m1", Role: "user", Content: "fix it"},
+		{ID: "m2", Role: "assistant", Content: "done", TokenUsage: adapter.TokenUsage{InputTokens: 10, OutputTokens: 5},
+			ToolUses: []adapter.ToolUse{{ID: "t1", Name: "Edit", Input: `{"file_path":"a.go"}`}}},
+	}
+	if err := a.ArchiveSession("/proj", s, messages); err != nil {
+		t.Fatal(err)
+	}
+
+	// A fresh instance reads the same store
+	b := NewWithDir(a.dir)
+	if found, _ := b.Detect("/proj"); !found {
+		t.Error("the archive should be detected for a project with archived sessions")
+	}
+	if got, ok := b.ArchivedUpdatedAt("ses-1"); !ok || !got.Equal(updated) {
+		t.Fatalf("ArchivedUpdatedAt = %v, %v", got, ok)
+	}
+	sessions, err := b.Sessions("/proj")
+	if err != nil || len(sessions) != 1 {
+		t.Fatalf("Sessions = %+v, %v", sessions, err)
+	}
+	got := sessions[0]
+	if got.AdapterID != adapterID || !got.Archived || got.IsActive || got.Path != "" || got.AdapterName != "Claude Code" {
+		t.Errorf("archived session = %+v", got)
+	}
+	if other, _ := b.Sessions("/other"); len(other) != 0 {
+		t.Errorf("sessions leaked to another project: %+v", other)
+	}
+
+	msgs, err := b.Messages("ses-1")
+	if err != nil || len(msgs) != 2 || msgs[1].ToolUses[0].Name != "Edit" {
+		t.Fatalf("Messages = %+v, %v", msgs, err)
+	}
+	usage, err := b.Usage("ses-1")
+	if err != nil || usage.TotalInputTokens != 10 || usage.MessageCount != 2 {
+		t.Errorf("Usage = %+v, %v", usage, err)
+	}
+
+	// Archived sessions are never archived again
+	if err := b.ArchiveSession("/proj", got, msgs); err != nil {
+		t.Fatal(err)
+	}
+	if e, _ := b.index.Get("ses-1"); e == nil || e.Session.AdapterID != "claude-code" {
+		t.Error("re-archiving an archived copy should be a no-op")
+	}
+}
+
+func TestArchivePrune(t *testing.T) {
+	a := NewWithDir(t.TempDir())
+	now := time.Now()
+	archive := func(id string, age time.Duration) {
+		t.Helper()
+		s := adapter.Session{ID: id, AdapterID: "codex", UpdatedAt: now.Add(-age)}
+		if err := a.ArchiveSession("/proj", s, []adapter.Message{{ID: id, Role: "user", Content: id}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	archive("new", time.Hour)
+	archive("mid", 24*time.Hour)
+	archive("old", 400*24*time.Hour)
+
+	// The default one-year retention drops the old session and its file
+	old, _ := a.index.Get("old")
+	oldFile := filepath.Join(a.dir, old.File)
+	if err := a.Prune(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := a.ArchivedUpdatedAt("old"); ok {
+		t.Error("sessions past the maximum age should be pruned")
+	}
+	if _, err := os.Stat(oldFile); !os.IsNotExist(err) {
+		t.Error("pruned message file should be removed")
+	}
+
+	// The size limit keeps the most recently active sessions
+	newest, _ := a.index.Get("new")
+	a.SetRetention(-1, newest.Size)
+	if err := a.Prune(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := a.ArchivedUpdatedAt("new"); !ok {
+		t.Error("the newest session should be kept")
+	}
+	if _, ok := a.ArchivedUpdatedAt("mid"); ok {
+		t.Error("older sessions beyond the size limit should be pruned")
+	}
+}
+
+func TestArchiveWorktreeSessions(t *testing.T) {
+	a := NewWithDir(t.TempDir())
+	s := adapter.Session{
+		ID:           "ses-wt",
+		AdapterID:    "claude-code",
+		UpdatedAt:    time.Now(),
+		WorktreeName: "feature-login" + adapter.DeletedWorktreeSuffix,
+		WorktreePath: "/code/app-feature",
+	}
+	if err := a.ArchiveSession("/code/app-feature", s, []adapter.Message{{ID: "m1", Role: "user"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.ArchiveSession("/code/other", adapter.Session{ID: "ses-other", AdapterID: "codex"}, []adapter.Message{{ID: "m2", Role: "user"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The worktree keeps its real name, without the deleted marker
+	sessions, err := a.Sessions("/code/app-feature")
+	if err != nil || len(sessions) != 1 {
+		t.Fatalf("Sessions = %+v, %v", sessions, err)
+	}
+	if got := sessions[0]; got.WorktreeName != "feature-login" || got.WorktreePath != "/code/app-feature" {
+		t.Errorf("worktree = %q, %q", got.WorktreeName, got.WorktreePath)
+	}
+
+	// Archived worktrees of the repo are discovered from the main checkout
+	related, err := a.DiscoverRelatedProjectDirs("/code/app")
+	if err != nil || len(related) != 1 || related[0] != "/code/app-feature" {
+		t.Errorf("DiscoverRelatedProjectDirs = %v, %v", related, err)
+	}
+}
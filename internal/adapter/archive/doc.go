@@ -0,0 +1,5 @@
+// Package archive provides sidecar's own store of agent sessions. Sessions
+// from the other adapters are copied here as compressed JSON so they remain
+// browsable after the agent prunes or rotates its history, and the store is
+// served back as an adapter with archived sessions marked as such.
+package archive
@@ -0,0 +1,9 @@
+package archive
+
+import "github.com/marcus/sidecar/internal/adapter"
+
+func init() {
+	adapter.RegisterFactory(func() adapter.Adapter {
+		return New()
+	})
+}
@@ -2,6 +2,7 @@ package claudecode
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -137,16 +138,23 @@ func (a *Adapter) Sessions(projectRoot string) ([]adapter.Session, error) {
 		return nil, err
 	}
 
-	sessions := make([]adapter.Session, 0, len(entries))
-	seenPaths := make(map[string]struct{}, len(entries))
-	// Build new index, then swap atomically to avoid race with sessionFilePath()
-	newIndex := make(map[string]string, len(entries))
+	// Newer versions keep sub-agent transcripts in <session>/subagents
+	files := make([]sessionFile, 0, len(entries))
 	for _, e := range entries {
-		if !strings.HasSuffix(e.Name(), ".jsonl") {
-			continue
+		if e.IsDir() {
+			files = append(files, listSessionFiles(filepath.Join(dir, e.Name(), "subagents"))...)
+		} else if strings.HasSuffix(e.Name(), ".jsonl") {
+			files = append(files, sessionFile{path: filepath.Join(dir, e.Name()), entry: e})
 		}
+	}
 
-		path := filepath.Join(dir, e.Name())
+	sessions := make([]adapter.Session, 0, len(files))
+	seenPaths := make(map[string]struct{}, len(files))
+	// Build new index, then swap atomically to avoid race with sessionFilePath()
+	newIndex := make(map[string]string, len(files))
+	subAgentCalls := make(map[string]subAgentCall)
+	for _, f := range files {
+		e, path := f.entry, f.path
 		info, err := e.Info()
 		if err != nil {
 			continue
@@ -156,6 +164,9 @@ func (a *Adapter) Sessions(projectRoot string) ([]adapter.Session, error) {
 			continue
 		}
 		seenPaths[path] = struct{}{}
+		for agentID, toolUseID := range meta.SubAgentCalls {
+			subAgentCalls[agentID] = subAgentCall{sessionID: meta.SessionID, toolUseID: toolUseID}
+		}
 
 		// Skip sessions with no messages (metadata-only files)
 		if meta.MsgCount == 0 {
@@ -197,9 +208,24 @@ func (a *Adapter) Sessions(projectRoot string) ([]adapter.Session, error) {
 			MessageCount: meta.MsgCount,
 			FileSize:     info.Size(),
 			Path:         path, // td-dca6fe: tiered watching needs session file path
+
+			ParentSessionID: meta.ParentSessionID,
 		})
 	}
 
+	// Link sub-agents to the Task calls that spawned them
+	for i := range sessions {
+		if !sessions[i].IsSubAgent {
+			continue
+		}
+		if call, ok := subAgentCalls[strings.TrimPrefix(sessions[i].ID, "agent-")]; ok {
+			sessions[i].ParentToolCallID = call.toolUseID
+			if sessions[i].ParentSessionID == "" {
+				sessions[i].ParentSessionID = call.sessionID
+			}
+		}
+	}
+
 	// Atomically swap in the new index
 	a.mu.Lock()
 	a.sessionIndex = newIndex
@@ -246,6 +272,10 @@ func (a *Adapter) SessionByID(sessionID string) (*adapter.Session, error) {
 	}
 
 	isSubAgent := strings.HasPrefix(filepath.Base(path), "agent-")
+	var parentToolCallID string
+	if isSubAgent && meta.ParentSessionID != "" {
+		parentToolCallID = a.subAgentToolCallID(meta.ParentSessionID, strings.TrimPrefix(sessionID, "agent-"))
+	}
 
 	return &adapter.Session{
 		ID:           meta.SessionID,
@@ -263,9 +293,57 @@ func (a *Adapter) SessionByID(sessionID string) (*adapter.Session, error) {
 		IsSubAgent:   isSubAgent,
 		MessageCount: meta.MsgCount,
 		FileSize:     info.Size(),
+
+		ParentSessionID:  meta.ParentSessionID,
+		ParentToolCallID: parentToolCallID,
 	}, nil
 }
 
+// sessionFile is a session transcript found in a project directory.
+type sessionFile struct {
+	path  string
+	entry os.DirEntry
+}
+
+// listSessionFiles returns the .jsonl files in dir, if it exists.
+func listSessionFiles(dir string) []sessionFile {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var files []sessionFile
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".jsonl") {
+			files = append(files, sessionFile{path: filepath.Join(dir, e.Name()), entry: e})
+		}
+	}
+	return files
+}
+
+// subAgentCall is the Task tool call in a parent session that ran a sub-agent.
+type subAgentCall struct {
+	sessionID string
+	toolUseID string
+}
+
+// subAgentToolCallID returns the ID of the Task call in the parent session
+// that ran the given sub-agent, or "" if it isn't recorded.
+func (a *Adapter) subAgentToolCallID(parentSessionID, agentID string) string {
+	path := a.sessionFilePath(parentSessionID)
+	if path == "" {
+		return ""
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	meta, err := a.sessionMetadata(path, info)
+	if err != nil {
+		return ""
+	}
+	return meta.SubAgentCalls[agentID]
+}
+
 // Messages returns all messages for the given session.
 // Uses caching with incremental parsing for append-only growth optimization.
 func (a *Adapter) Messages(sessionID string) ([]adapter.Message, error) {
@@ -317,6 +395,17 @@ func (a *Adapter) Messages(sessionID string) ([]adapter.Message, error) {
 	return messages, nil
 }
 
+// ParseSessionFile parses a Claude Code session file outside the projects
+// directory, such as one copied from another machine.
+func ParseSessionFile(path string) ([]adapter.Message, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	messages, _, err := New().parseMessagesFull(path, info)
+	return messages, err
+}
+
 // parseMessagesFull parses all messages from a session file.
 func (a *Adapter) parseMessagesFull(path string, info os.FileInfo) ([]adapter.Message, messageCacheEntry, error) {
 	file, err := os.Open(path)
@@ -804,6 +893,13 @@ func (a *Adapter) parseSessionMetadataIncremental(path string, base *SessionMeta
 		MsgCount:         base.MsgCount,
 		TotalTokens:      base.TotalTokens,
 		FirstUserMessage: base.FirstUserMessage,
+		ParentSessionID:  base.ParentSessionID,
+	}
+	if len(base.SubAgentCalls) > 0 {
+		meta.SubAgentCalls = make(map[string]string, len(base.SubAgentCalls))
+		for k, v := range base.SubAgentCalls {
+			meta.SubAgentCalls[k] = v
+		}
 	}
 
 	// Copy model tracking maps
@@ -855,6 +951,13 @@ func (a *Adapter) processMetadataLine(line []byte, meta *SessionMetadata, modelC
 	if meta.Slug == "" && raw.Slug != "" {
 		meta.Slug = raw.Slug
 	}
+	// Sub-agent transcripts carry the spawning session's ID
+	if raw.IsSidechain && meta.ParentSessionID == "" && raw.SessionID != "" && raw.SessionID != meta.SessionID {
+		meta.ParentSessionID = raw.SessionID
+	}
+	if raw.Type == "user" && raw.Message != nil && bytes.Contains(line, agentIDKey) {
+		recordSubAgentCall(line, raw.Message, meta)
+	}
 	if meta.FirstUserMessage == "" && raw.Type == "user" && raw.Message != nil {
 		content, _, _ := a.parseContent(raw.Message.Content)
 		if content != "" {
@@ -884,6 +987,30 @@ func (a *Adapter) processMetadataLine(line []byte, meta *SessionMetadata, modelC
 	}
 }
 
+// agentIDKey is searched for before decoding Task tool results, which are rare.
+var agentIDKey = []byte(`"agentId"`)
+
+// recordSubAgentCall links a Task tool result to the sub-agent it ran.
+func recordSubAgentCall(line []byte, msg *MessageContent, meta *SessionMetadata) {
+	var res subAgentResult
+	if err := json.Unmarshal(line, &res); err != nil || res.ToolUseResult.AgentID == "" {
+		return
+	}
+	var blocks []ContentBlock
+	if err := json.Unmarshal(msg.Content, &blocks); err != nil {
+		return
+	}
+	for _, b := range blocks {
+		if b.Type == "tool_result" && b.ToolUseID != "" {
+			if meta.SubAgentCalls == nil {
+				meta.SubAgentCalls = make(map[string]string)
+			}
+			meta.SubAgentCalls[res.ToolUseResult.AgentID] = b.ToolUseID
+			return
+		}
+	}
+}
+
 // finalizeMetadataCost calculates PrimaryModel and EstCost from per-model tracking.
 func (a *Adapter) finalizeMetadataCost(meta *SessionMetadata, modelCounts map[string]int, modelTokens map[string]modelTokenEntry) {
 	var maxCount int
@@ -1160,3 +1160,58 @@ func TestSessionMetadataCacheInvalidation(t *testing.T) {
 		t.Errorf("expected 3 msgs after invalidation, got %d", meta2.MsgCount)
 	}
 }
+
+func TestSessionsSubAgentLinks(t *testing.T) {
+	tmpDir := t.TempDir()
+	projDir := tmpDir + "/-test-project"
+	if err := os.MkdirAll(projDir+"/parent-2/subagents", 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	parent := `{"type":"user","sessionId":"parent-1","timestamp":"2024-01-01T10:00:00Z","message":{"role":"user","content":"review the code"}}
+{"type":"assistant","sessionId":"parent-1","timestamp":"2024-01-01T10:00:05Z","message":{"role":"assistant","content":[{"type":"tool_use","id":"toolu_task","name":"Task","input":{"prompt":"find bugs"}}]}}
+{"type":"user","sessionId":"parent-1","timestamp":"2024-01-01T10:01:00Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_task","content":"none"}]},"toolUseResult":{"status":"completed","agentId":"abc123"}}
+`
+	child := `{"type":"user","sessionId":"parent-1","agentId":"abc123","isSidechain":true,"timestamp":"2024-01-01T10:00:06Z","message":{"role":"user","content":"find bugs"}}
+{"type":"assistant","sessionId":"parent-1","agentId":"abc123","isSidechain":true,"timestamp":"2024-01-01T10:00:50Z","message":{"role":"assistant","content":"none"}}
+`
+	nested := `{"type":"user","sessionId":"parent-2","agentId":"def456","isSidechain":true,"timestamp":"2024-01-01T11:00:00Z","message":{"role":"user","content":"write docs"}}
+`
+	for path, data := range map[string]string{
+		projDir + "/parent-1.jsonl":                        parent,
+		projDir + "/agent-abc123.jsonl":                    child,
+		projDir + "/parent-2/subagents/agent-def456.jsonl": nested,
+	} {
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := &Adapter{projectsDir: tmpDir, sessionIndex: make(map[string]string), metaCache: make(map[string]sessionMetaCacheEntry)}
+	sessions, err := a.Sessions("/test/project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	byID := make(map[string]adapter.Session)
+	for _, s := range sessions {
+		byID[s.ID] = s
+	}
+	if len(byID) != 3 {
+		t.Fatalf("expected 3 sessions, got %+v", sessions)
+	}
+	if s := byID["parent-1"]; s.IsSubAgent || s.ParentSessionID != "" {
+		t.Errorf("parent = %+v", s)
+	}
+	if s := byID["agent-abc123"]; !s.IsSubAgent || s.ParentSessionID != "parent-1" || s.ParentToolCallID != "toolu_task" {
+		t.Errorf("sub-agent = %+v", s)
+	}
+	if s := byID["agent-def456"]; !s.IsSubAgent || s.ParentSessionID != "parent-2" {
+		t.Errorf("nested sub-agent = %+v", s)
+	}
+
+	// Targeted refresh resolves the spawning call through the parent's metadata
+	s, err := a.SessionByID("agent-abc123")
+	if err != nil || s.ParentSessionID != "parent-1" || s.ParentToolCallID != "toolu_task" {
+		t.Errorf("SessionByID = %+v, %v", s, err)
+	}
+}
@@ -17,6 +17,16 @@ type RawMessage struct {
 	Version    string          `json:"version,omitempty"`
 	GitBranch  string          `json:"gitBranch,omitempty"`
 	Slug       string          `json:"slug,omitempty"`
+	// IsSidechain marks lines of a Task sub-agent transcript
+	IsSidechain bool `json:"isSidechain,omitempty"`
+}
+
+// subAgentResult is the part of a parent's Task tool result line that
+// identifies the sub-agent it ran.
+type subAgentResult struct {
+	ToolUseResult struct {
+		AgentID string `json:"agentId"`
+	} `json:"toolUseResult"`
 }
 
 // MessageContent holds the actual message data.
@@ -78,4 +88,7 @@ type SessionMetadata struct {
 	EstCost          float64 // Estimated cost based on model usage
 	PrimaryModel     string  // Most used model in session
 	FirstUserMessage string  // Content of the first user message (for title)
+	ParentSessionID  string  // Session that spawned this sub-agent (sidechain files)
+	// SubAgentCalls maps sub-agent IDs to the Task tool_use IDs that spawned them
+	SubAgentCalls map[string]string
 }
@@ -239,6 +239,18 @@ func (a *Adapter) Messages(sessionID string) ([]adapter.Message, error) {
 	return messages, nil
 }
 
+// ParseSessionFile parses a Codex session file outside the sessions
+// directory, such as one copied from another machine. Message IDs are
+// derived from sessionID.
+func ParseSessionFile(path, sessionID string) ([]adapter.Message, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	messages, _, err := New().parseMessagesFull(path, sessionID, info)
+	return messages, err
+}
+
 // parseMessagesFull parses all messages from a session file.
 func (a *Adapter) parseMessagesFull(path, sessionID string, info os.FileInfo) ([]adapter.Message, messageCacheEntry, error) {
 	file, err := os.Open(path)
@@ -0,0 +1,222 @@
+package imported
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/adapter/store"
+)
+
+const (
+	adapterID   = "imported"
+	adapterName = "Imported"
+	adapterIcon = "⇩"
+)
+
+// entry is the index record for one imported session.
+type entry struct {
+	store.Entry
+	Source     string    `json:"source"` // path the session was imported from
+	Format     Format    `json:"format"`
+	ImportedAt time.Time `json:"importedAt"`
+}
+
+// Adapter implements the adapter.Adapter interface for imported sessions.
+type Adapter struct {
+	dir   string
+	index *store.Index[entry]
+}
+
+// New creates an imported-sessions adapter stored in ~/.config/sidecar/imported.
+func New() *Adapter {
+	home, _ := os.UserHomeDir()
+	return NewWithDir(filepath.Join(home, ".config", "sidecar", "imported"))
+}
+
+// NewWithDir creates an imported-sessions adapter stored in dir.
+func NewWithDir(dir string) *Adapter {
+	return &Adapter{
+		dir:   dir,
+		index: store.NewIndex(dir, func(e *entry) *store.Entry { return &e.Entry }),
+	}
+}
+
+// ID returns the adapter identifier.
+func (a *Adapter) ID() string { return adapterID }
+
+// Name returns the human-readable adapter name.
+func (a *Adapter) Name() string { return adapterName }
+
+// Icon returns the adapter icon for badge display.
+func (a *Adapter) Icon() string { return adapterIcon }
+
+// Detect reports whether sessions were imported into the project.
+func (a *Adapter) Detect(projectRoot string) (bool, error) {
+	return a.index.HasProject(projectRoot)
+}
+
+// Capabilities returns the supported features. Imported sessions never
+// change, so there is nothing to watch.
+func (a *Adapter) Capabilities() adapter.CapabilitySet {
+	return adapter.CapabilitySet{
+		adapter.CapSessions: true,
+		adapter.CapMessages: true,
+		adapter.CapUsage:    true,
+	}
+}
+
+// Sessions returns the sessions imported into the given project, sorted by
+// update time. They keep the originating agent's name and icon when known.
+func (a *Adapter) Sessions(projectRoot string) ([]adapter.Session, error) {
+	entries, err := a.index.Project(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []adapter.Session
+	for _, e := range entries {
+		sessions = append(sessions, servedSession(e.Session))
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].UpdatedAt.After(sessions[j].UpdatedAt)
+	})
+	return sessions, nil
+}
+
+// Messages returns the messages of an imported session.
+func (a *Adapter) Messages(sessionID string) ([]adapter.Message, error) {
+	e, err := a.index.Get(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return nil, fmt.Errorf("session %s is not imported", sessionID)
+	}
+
+	data, err := os.ReadFile(filepath.Join(a.dir, e.File))
+	if err != nil {
+		return nil, err
+	}
+	var t adapter.Transcript
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, err
+	}
+	return t.Messages, nil
+}
+
+// Usage returns token totals computed from the imported messages.
+func (a *Adapter) Usage(sessionID string) (*adapter.UsageStats, error) {
+	messages, err := a.Messages(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return store.Usage(messages), nil
+}
+
+// Watch is not supported; imported sessions are read-only.
+func (a *Adapter) Watch(projectRoot string) (<-chan adapter.Event, io.Closer, error) {
+	return nil, nil, nil
+}
+
+// SearchMessages searches message content within a session.
+// Implements adapter.MessageSearcher interface.
+func (a *Adapter) SearchMessages(sessionID, query string, opts adapter.SearchOptions) ([]adapter.MessageMatch, error) {
+	messages, err := a.Messages(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, nil
+	}
+	return adapter.SearchMessagesSlice(messages, query, opts)
+}
+
+// Import parses a transcript file and stores it as a session of the project
+// at projectRoot. Importing a session again replaces the earlier copy; a
+// session already imported into another project is rejected, since sessions
+// are looked up by ID alone.
+func (a *Adapter) Import(projectRoot, path string) (adapter.Session, Format, error) {
+	t, format, err := ParseFile(path)
+	if err != nil {
+		return adapter.Session{}, "", err
+	}
+	if len(t.Messages) == 0 {
+		return adapter.Session{}, format, errors.New("no messages found")
+	}
+	project := store.ProjectKey(projectRoot)
+	existing, err := a.index.Get(t.Session.ID)
+	if err != nil {
+		return adapter.Session{}, format, err
+	}
+	if existing != nil && existing.Project != project {
+		return adapter.Session{}, format, fmt.Errorf("session %s is already imported into %s", t.Session.ID, existing.Project)
+	}
+
+	// Store the session as exported, minus details that only applied on
+	// the machine it came from
+	s := t.Session
+	s.IsActive = false
+	s.Path = ""
+	s.FileSize = 0
+	s.WorktreeName = ""
+	s.WorktreePath = ""
+	s.Archived = false
+	s.Imported = false
+	t.Session = s
+	t.Version = adapter.TranscriptVersion
+
+	name := store.SessionFile(s.ID, ".json")
+	if err := a.writeTranscript(name, t); err != nil {
+		return adapter.Session{}, format, err
+	}
+	source := path
+	if abs, err := filepath.Abs(path); err == nil {
+		source = abs
+	}
+
+	err = a.index.Put(&entry{
+		Entry:      store.Entry{Session: s, Project: project, File: name},
+		Source:     source,
+		Format:     format,
+		ImportedAt: time.Now(),
+	})
+	if err != nil {
+		return adapter.Session{}, format, err
+	}
+	return servedSession(s), format, nil
+}
+
+// servedSession marks a stored session as imported and read-only.
+func servedSession(s adapter.Session) adapter.Session {
+	if s.AdapterName == "" {
+		s.AdapterName = adapterName
+		s.AdapterIcon = adapterIcon
+	}
+	s.AdapterID = adapterID
+	s.Imported = true
+	return s
+}
+
+// writeTranscript atomically writes a transcript file.
+func (a *Adapter) writeTranscript(name string, t adapter.Transcript) error {
+	path := filepath.Join(a.dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,190 @@
+package imported
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcus/sidecar/internal/adapter"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestImportTranscript(t *testing.T) {
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	transcript := adapter.Transcript{
+		Version: adapter.TranscriptVersion,
+		Session: adapter.Session{
+			ID:          "ses-1",
+			Name:        "Fix login",
+			AdapterID:   "claude-code",
+			AdapterName: "Claude Code",
+			AdapterIcon: "◆",
+			CreatedAt:   created,
+			UpdatedAt:   created.Add(time.Minute),
+			IsActive:    true,
+			Path:        "/home/teammate/.claude/projects/x/ses-1.jsonl",
+		},
+		Messages: []adapter.Message{
+			{ID: "m1", Role: "user", Content: "fix the login bug", Timestamp: created},
+			{ID: "m2", Role: "assistant", Content: "done", Timestamp: created.Add(time.Minute),
+				TokenUsage: adapter.TokenUsage{InputTokens: 10, OutputTokens: 5},
+				ToolUses:   []adapter.ToolUse{{ID: "t1", Name: "Edit", Input: `{"file_path":"login.go"}`}}},
+		},
+	}
+	data, _ := json.Marshal(transcript)
+	path := writeFile(t, "ses-1.json", string(data))
+
+	a := NewWithDir(t.TempDir())
+	if found, _ := a.Detect("/proj"); found {
+		t.Error("an empty store should not be detected")
+	}
+	s, format, err := a.Import("/proj", path)
+	if err != nil || format != FormatJSON {
+		t.Fatalf("Import = %v, %v", format, err)
+	}
+	if s.AdapterID != adapterID || !s.Imported || s.IsActive || s.Path != "" || s.MessageCount != 2 {
+		t.Errorf("imported session = %+v", s)
+	}
+
+	// A fresh instance reads the same store
+	b := NewWithDir(a.dir)
+	if found, _ := b.Detect("/proj"); !found {
+		t.Error("the store should be detected for a project with imports")
+	}
+	sessions, err := b.Sessions("/proj")
+	if err != nil || len(sessions) != 1 || sessions[0].AdapterName != "Claude Code" || !sessions[0].Imported {
+		t.Fatalf("Sessions = %+v, %v", sessions, err)
+	}
+	if other, _ := b.Sessions("/other"); len(other) != 0 {
+		t.Errorf("sessions leaked to another project: %+v", other)
+	}
+	msgs, err := b.Messages("ses-1")
+	if err != nil || len(msgs) != 2 || msgs[1].ToolUses[0].Input != `{"file_path":"login.go"}` {
+		t.Fatalf("Messages = %+v, %v", msgs, err)
+	}
+	matches, err := b.SearchMessages("ses-1", "LOGIN", adapter.DefaultSearchOptions())
+	if err != nil || len(matches) != 2 || matches[0].MessageID != "m1" {
+		t.Errorf("SearchMessages = %+v, %v", matches, err)
+	}
+
+	// Importing again replaces the earlier copy
+	if _, _, err := b.Import("/proj", path); err != nil {
+		t.Fatal(err)
+	}
+	if sessions, _ := b.Sessions("/proj"); len(sessions) != 1 {
+		t.Errorf("re-import should replace, got %d sessions", len(sessions))
+	}
+
+	// The same session can't be imported into another project
+	if _, _, err := b.Import("/other", path); err == nil || !strings.Contains(err.Error(), "already imported into") {
+		t.Errorf("import into another project = %v, want an error", err)
+	}
+	if other, _ := b.Sessions("/other"); len(other) != 0 {
+		t.Errorf("rejected import was stored: %+v", other)
+	}
+}
+
+func TestImportJSONL(t *testing.T) {
+	tests := []struct {
+		name    string
+		lines   []string
+		format  Format
+		id      string
+		adapter string
+	}{
+		{
+			name: "claude code",
+			lines: []string{
+				`{"type":"user","uuid":"u1","sessionId":"cc-1","timestamp":"2024-01-15T10:00:00Z","message":{"role":"user","content":"Add tests"}}`,
+				`{"type":"assistant","uuid":"a1","sessionId":"cc-1","timestamp":"2024-01-15T10:00:05Z","message":{"role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"text","text":"Added"}],"usage":{"input_tokens":100,"output_tokens":50}}}`,
+			},
+			format:  FormatClaudeCode,
+			id:      "cc-1",
+			adapter: "Claude Code",
+		},
+		{
+			name: "codex",
+			lines: []string{
+				`{"timestamp":"2025-11-20T04:15:00Z","type":"session_meta","payload":{"id":"cx-1","cwd":"/tmp"}}`,
+				`{"timestamp":"2025-11-20T04:15:01Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"Add tests"}]}}`,
+				`{"timestamp":"2025-11-20T04:15:16Z","type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"Added"}]}}`,
+			},
+			format:  FormatCodex,
+			id:      "cx-1",
+			adapter: "Codex",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, "session.jsonl", strings.Join(tt.lines, "\n")+"\n")
+			tr, format, err := ParseFile(path)
+			if err != nil || format != tt.format {
+				t.Fatalf("ParseFile = %v, %v", format, err)
+			}
+			s := tr.Session
+			if s.ID != tt.id || s.AdapterName != tt.adapter || s.Name != "Add tests" || s.MessageCount != 2 || s.UpdatedAt.IsZero() {
+				t.Errorf("session = %+v", s)
+			}
+			if len(tr.Messages) != 2 || tr.Messages[1].Content != "Added" {
+				t.Errorf("messages = %+v", tr.Messages)
+			}
+		})
+	}
+
+	path := writeFile(t, "other.jsonl", `{"event":"something else"}`+"\n")
+	if _, _, err := ParseFile(path); err == nil {
+		t.Error("unrecognized JSONL should fail to parse")
+	}
+}
+
+func TestParseMarkdown(t *testing.T) {
+	md := "# Session: Fix login\n\n" +
+		"**Date**: 2024-05-01 23:58\n" +
+		"**Duration**: 1h 5m\n" +
+		"**Tokens**: 150\n" +
+		"**Estimated Cost**: $0.12\n\n---\n\n" +
+		"## User (23:58:00)\n\nfix it\n\n## Assistant (00:00:00)\n\n---\n\n" +
+		"## Assistant (00:01:30)\n\n*Model: Sonnet 4*\n\n*Tokens: in=100, out=50*\n\n" +
+		"<details>\n<summary>Thinking (12 tokens)</summary>\n\nLook at auth.go\n\n</details>\n\n" +
+		"Fixed the check.\n\n**Tools used:**\n- Edit: `auth.go`\n- Bash\n\n---\n\n"
+
+	tr, err := parseMarkdown(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := tr.Session
+	if s.Name != "Fix login" || s.Duration != 65*time.Minute || s.TotalTokens != 150 || s.EstCost != 0.12 || s.MessageCount != 2 {
+		t.Errorf("session = %+v", s)
+	}
+	if len(tr.Messages) != 2 {
+		t.Fatalf("got %d messages: %+v", len(tr.Messages), tr.Messages)
+	}
+	user, asst := tr.Messages[0], tr.Messages[1]
+	if user.Role != "user" || user.Content != "fix it\n\n## Assistant (00:00:00)" {
+		t.Errorf("user message = %+v", user)
+	}
+	if asst.Role != "assistant" || asst.Model != "Sonnet 4" || asst.InputTokens != 100 || asst.Content != "Fixed the check." {
+		t.Errorf("assistant message = %+v", asst)
+	}
+	if len(asst.ThinkingBlocks) != 1 || asst.ThinkingBlocks[0].Content != "Look at auth.go" || asst.ThinkingBlocks[0].TokenCount != 12 {
+		t.Errorf("thinking = %+v", asst.ThinkingBlocks)
+	}
+	if len(asst.ToolUses) != 2 || asst.ToolUses[0].Input != `{"file_path":"auth.go"}` || asst.ToolUses[1].Name != "Bash" {
+		t.Errorf("tools = %+v", asst.ToolUses)
+	}
+	// Clock times past midnight roll over to the next day
+	if d := asst.Timestamp.Sub(user.Timestamp); d != 3*time.Minute+30*time.Second {
+		t.Errorf("timestamps %v and %v are %v apart", user.Timestamp, asst.Timestamp, d)
+	}
+}
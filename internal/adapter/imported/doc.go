@@ -0,0 +1,6 @@
+// Package imported serves sessions imported from transcript files: sidecar's
+// JSON export, Markdown written by the conversations plugin's export, or raw
+// Claude Code and Codex JSONL copied from another machine. Imported sessions
+// are copied into sidecar's config directory as JSON transcripts and are
+// read-only.
+package imported
@@ -0,0 +1,406 @@
+package imported
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/adapter/claudecode"
+	"github.com/marcus/sidecar/internal/adapter/codex"
+	"github.com/marcus/sidecar/internal/adapter/pricing"
+)
+
+// Format identifies the format a session was imported from.
+type Format string
+
+const (
+	FormatJSON       Format = "json"        // sidecar's lossless transcript export
+	FormatMarkdown   Format = "markdown"    // conversations plugin Markdown export
+	FormatClaudeCode Format = "claude-code" // raw Claude Code session JSONL
+	FormatCodex      Format = "codex"       // raw Codex session JSONL
+)
+
+// maxTitleLen bounds session names derived from the first user message.
+const maxTitleLen = 50
+
+// ParseFile reads a transcript file, detecting its format from the content.
+func ParseFile(path string) (adapter.Transcript, Format, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return adapter.Transcript{}, "", err
+	}
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return adapter.Transcript{}, "", errors.New("file is empty")
+	}
+
+	if trimmed[0] == '{' {
+		if t, ok, err := parseTranscript(trimmed); ok || err != nil {
+			if err == nil {
+				fillSession(&t.Session, t.Messages)
+			}
+			return t, FormatJSON, err
+		}
+		return parseJSONL(path, trimmed)
+	}
+	if bytes.HasPrefix(trimmed, []byte("# Session:")) {
+		t, err := parseMarkdown(string(data))
+		return t, FormatMarkdown, err
+	}
+	return adapter.Transcript{}, "", errors.New("unrecognized transcript format")
+}
+
+// parseTranscript decodes a JSON transcript. It reports false if the data
+// is not a single transcript object, such as the first line of a JSONL file.
+func parseTranscript(data []byte) (adapter.Transcript, bool, error) {
+	var t adapter.Transcript
+	if err := json.Unmarshal(data, &t); err != nil || t.Version == 0 {
+		return adapter.Transcript{}, false, nil
+	}
+	if t.Version > adapter.TranscriptVersion {
+		return adapter.Transcript{}, true, fmt.Errorf("transcript version %d is newer than this sidecar supports", t.Version)
+	}
+	if t.Session.ID == "" {
+		return adapter.Transcript{}, true, errors.New("transcript has no session ID")
+	}
+	return t, true, nil
+}
+
+// jsonlRecord holds the fields used to recognize agent JSONL lines.
+type jsonlRecord struct {
+	Type      string          `json:"type"`
+	SessionID string          `json:"sessionId"`
+	Message   json.RawMessage `json:"message"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// parseJSONL parses a raw agent session file with the agent's own parser.
+func parseJSONL(path string, data []byte) (adapter.Transcript, Format, error) {
+	format, sessionID := detectJSONL(data)
+	if sessionID == "" {
+		sessionID = contentID(data)
+	}
+
+	var t adapter.Transcript
+	var err error
+	switch format {
+	case FormatClaudeCode:
+		t.Session = adapter.Session{ID: sessionID, AdapterName: "Claude Code", AdapterIcon: "◆"}
+		t.Messages, err = claudecode.ParseSessionFile(path)
+	case FormatCodex:
+		t.Session = adapter.Session{ID: sessionID, AdapterName: "Codex", AdapterIcon: "▶"}
+		t.Messages, err = codex.ParseSessionFile(path, sessionID)
+	default:
+		return t, "", errors.New("unrecognized JSONL format")
+	}
+	if err != nil {
+		return t, format, err
+	}
+	fillSession(&t.Session, t.Messages)
+	return t, format, nil
+}
+
+// detectJSONL identifies the agent that wrote a JSONL file and the session
+// ID it recorded, if any.
+func detectJSONL(data []byte) (Format, string) {
+	var format Format
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		var rec jsonlRecord
+		if json.Unmarshal(scanner.Bytes(), &rec) != nil {
+			continue
+		}
+		switch {
+		case rec.Payload != nil && rec.Type == "session_meta":
+			var meta struct {
+				ID string `json:"id"`
+			}
+			_ = json.Unmarshal(rec.Payload, &meta)
+			return FormatCodex, meta.ID
+		case rec.Payload != nil && (rec.Type == "response_item" || rec.Type == "event_msg" || rec.Type == "turn_context"):
+			format = FormatCodex
+		case rec.Message != nil && (rec.Type == "user" || rec.Type == "assistant"):
+			return FormatClaudeCode, rec.SessionID
+		}
+	}
+	return format, ""
+}
+
+var (
+	mdMessageHeading = regexp.MustCompile(`^## ([A-Za-z]+) \((\d{2}):(\d{2}):(\d{2})\)$`)
+	mdThinkingTokens = regexp.MustCompile(`^<summary>Thinking \((\d+) tokens\)</summary>$`)
+	mdMessageTokens  = regexp.MustCompile(`^\*Tokens: in=(\d+), out=(\d+)\*$`)
+	mdToolUse        = regexp.MustCompile("^- ([^:`]+)(?:: `(.*)`)?$")
+)
+
+// parseMarkdown parses the Markdown written by ExportSessionAsMarkdown.
+// Message boundaries are "## Role (15:04:05)" headings that follow a "---"
+// separator, so headings inside message content are left alone.
+func parseMarkdown(data string) (adapter.Transcript, error) {
+	lines := strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n")
+	s := adapter.Session{ID: contentID([]byte(data))}
+
+	// Header: title and metadata up to the first separator
+	i := 0
+	for ; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if mdMessageHeading.MatchString(line) {
+			break
+		}
+		if line == "---" {
+			i++
+			break
+		}
+		key, value, ok := markdownField(line)
+		switch {
+		case strings.HasPrefix(line, "# Session: "):
+			s.Name = strings.TrimPrefix(line, "# Session: ")
+		case !ok:
+		case key == "Date":
+			if t, err := time.ParseInLocation("2006-01-02 15:04", value, time.Local); err == nil {
+				s.CreatedAt = t
+			}
+		case key == "Duration":
+			if d, err := time.ParseDuration(strings.ReplaceAll(value, " ", "")); err == nil {
+				s.Duration = d
+			}
+		case key == "Tokens":
+			s.TotalTokens, _ = strconv.Atoi(value)
+		case key == "Estimated Cost":
+			s.EstCost, _ = strconv.ParseFloat(strings.TrimPrefix(value, "$"), 64)
+		}
+	}
+
+	// Split the rest into messages
+	var messages []adapter.Message
+	var body []string
+	var current *adapter.Message
+	day := s.CreatedAt
+	if day.IsZero() {
+		day = time.Now()
+	}
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	var last time.Time
+	afterSeparator := true
+	flush := func() {
+		if current != nil {
+			parseMarkdownBody(current, body)
+			messages = append(messages, *current)
+		}
+		body = nil
+	}
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if m := mdMessageHeading.FindStringSubmatch(trimmed); m != nil && afterSeparator {
+			flush()
+			h, _ := strconv.Atoi(m[2])
+			mi, _ := strconv.Atoi(m[3])
+			sec, _ := strconv.Atoi(m[4])
+			ts := day.Add(time.Duration(h)*time.Hour + time.Duration(mi)*time.Minute + time.Duration(sec)*time.Second)
+			if ts.Before(last) {
+				// Times are only exported as clock times; assume a rollover
+				day = day.AddDate(0, 0, 1)
+				ts = ts.AddDate(0, 0, 1)
+			}
+			last = ts
+			current = &adapter.Message{
+				ID:        fmt.Sprintf("md-%d", len(messages)+1),
+				Role:      strings.ToLower(m[1]),
+				Timestamp: ts,
+			}
+			afterSeparator = false
+			continue
+		}
+		if trimmed != "" {
+			afterSeparator = trimmed == "---"
+		}
+		body = append(body, line)
+	}
+	flush()
+
+	if len(messages) == 0 {
+		return adapter.Transcript{}, errors.New("no messages found in Markdown export")
+	}
+	fillSession(&s, messages)
+	return adapter.Transcript{Version: adapter.TranscriptVersion, Session: s, Messages: messages}, nil
+}
+
+// markdownField splits a "**Key**: value" header line.
+func markdownField(line string) (string, string, bool) {
+	if !strings.HasPrefix(line, "**") {
+		return "", "", false
+	}
+	key, value, ok := strings.Cut(line[2:], "**: ")
+	return key, strings.TrimSpace(value), ok
+}
+
+// parseMarkdownBody fills a message from the lines below its heading: model
+// and token lines, thinking blocks, content, and the tools-used list.
+func parseMarkdownBody(msg *adapter.Message, lines []string) {
+	// Drop the trailing separator and blank lines
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "---" {
+		lines = lines[:len(lines)-1]
+	}
+
+	// Leading metadata and thinking blocks
+	for len(lines) > 0 {
+		line := strings.TrimSpace(lines[0])
+		switch {
+		case line == "":
+			lines = lines[1:]
+			continue
+		case strings.HasPrefix(line, "*Model: ") && strings.HasSuffix(line, "*"):
+			msg.Model = strings.TrimSuffix(strings.TrimPrefix(line, "*Model: "), "*")
+			lines = lines[1:]
+			continue
+		case line == "*Bookmark*" || strings.HasPrefix(line, "*Bookmark: ") && strings.HasSuffix(line, "*"):
+			// Bookmark notes are sidecar annotations, not message content
+			lines = lines[1:]
+			continue
+		case mdMessageTokens.MatchString(line):
+			m := mdMessageTokens.FindStringSubmatch(line)
+			msg.InputTokens, _ = strconv.Atoi(m[1])
+			msg.OutputTokens, _ = strconv.Atoi(m[2])
+			lines = lines[1:]
+			continue
+		case line == "<details>" && len(lines) > 1 && mdThinkingTokens.MatchString(strings.TrimSpace(lines[1])):
+			end := -1
+			for j := 2; j < len(lines); j++ {
+				if strings.TrimSpace(lines[j]) == "</details>" {
+					end = j
+					break
+				}
+			}
+			if end < 0 {
+				break
+			}
+			tokens, _ := strconv.Atoi(mdThinkingTokens.FindStringSubmatch(strings.TrimSpace(lines[1]))[1])
+			msg.ThinkingBlocks = append(msg.ThinkingBlocks, adapter.ThinkingBlock{
+				Content:    strings.Trim(strings.Join(lines[2:end], "\n"), "\n"),
+				TokenCount: tokens,
+			})
+			lines = lines[end+1:]
+			continue
+		}
+		break
+	}
+
+	// Trailing tools-used list
+	for j := len(lines) - 1; j >= 0; j-- {
+		line := strings.TrimSpace(lines[j])
+		if line == "**Tools used:**" {
+			for _, item := range lines[j+1:] {
+				m := mdToolUse.FindStringSubmatch(strings.TrimSpace(item))
+				if m == nil {
+					continue
+				}
+				tool := adapter.ToolUse{
+					ID:   fmt.Sprintf("%s-tool-%d", msg.ID, len(msg.ToolUses)+1),
+					Name: m[1],
+				}
+				if m[2] != "" {
+					input, _ := json.Marshal(map[string]string{"file_path": m[2]})
+					tool.Input = string(input)
+				}
+				msg.ToolUses = append(msg.ToolUses, tool)
+			}
+			lines = lines[:j]
+			break
+		}
+		if line != "" && !mdToolUse.MatchString(line) {
+			break
+		}
+	}
+
+	msg.Content = strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// fillSession derives session fields the source format did not record from
+// the messages.
+func fillSession(s *adapter.Session, messages []adapter.Message) {
+	var first, last time.Time
+	var tokens int
+	var cost float64
+	count := 0
+	for _, m := range messages {
+		if !m.Timestamp.IsZero() {
+			if first.IsZero() || m.Timestamp.Before(first) {
+				first = m.Timestamp
+			}
+			if m.Timestamp.After(last) {
+				last = m.Timestamp
+			}
+		}
+		tokens += m.InputTokens + m.OutputTokens
+		if m.Model != "" {
+			cost += pricing.ModelCost(m.Model, pricing.Usage{
+				InputTokens:  m.InputTokens,
+				OutputTokens: m.OutputTokens,
+				CacheRead:    m.CacheRead,
+				CacheWrite:   m.CacheWrite,
+			})
+		}
+		if m.Role == "user" || m.Role == "assistant" {
+			count++
+		}
+		if s.Name == "" && m.Role == "user" {
+			s.Name = sessionTitle(m.Content)
+		}
+	}
+
+	if s.CreatedAt.IsZero() {
+		s.CreatedAt = first
+	}
+	if s.UpdatedAt.IsZero() {
+		s.UpdatedAt = last
+		if s.UpdatedAt.IsZero() {
+			s.UpdatedAt = s.CreatedAt
+		}
+	}
+	if s.Duration == 0 && !first.IsZero() {
+		s.Duration = last.Sub(first)
+	}
+	if s.TotalTokens == 0 {
+		s.TotalTokens = tokens
+	}
+	if s.EstCost == 0 {
+		s.EstCost = cost
+	}
+	if s.MessageCount == 0 {
+		s.MessageCount = count
+	}
+	if s.Name == "" {
+		s.Name = s.ID
+	}
+}
+
+// sessionTitle returns the first line of a message, shortened for display.
+func sessionTitle(content string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(content), "\n")
+	if runes := []rune(line); len(runes) > maxTitleLen {
+		return string(runes[:maxTitleLen-1]) + "…"
+	}
+	return line
+}
+
+// contentID derives a stable session ID for formats that don't record one,
+// so importing the same file again replaces the earlier import.
+func contentID(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "import-" + hex.EncodeToString(sum[:6])
+}
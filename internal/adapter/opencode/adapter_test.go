@@ -211,6 +211,11 @@ func TestSessions_WithTestdata(t *testing.T) {
 			t.Error("subagent session should have IsSubAgent=true")
 		}
 	}
+	for _, s := range sessions {
+		if s.ID == "ses_subagent" && s.ParentSessionID != "ses_test_main" {
+			t.Errorf("subagent ParentSessionID = %q, want %q", s.ParentSessionID, "ses_test_main")
+		}
+	}
 }
 
 func TestMessages_WithTestdata(t *testing.T) {
@@ -0,0 +1,4 @@
+// Package store holds what sidecar's own session stores share: a JSON
+// index of stored sessions that several sidecar instances can read and
+// write, and helpers for naming message files and totalling usage.
+package store
@@ -0,0 +1,213 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/marcus/sidecar/internal/adapter"
+)
+
+const (
+	indexFile   = "index.json"
+	sessionsDir = "sessions"
+)
+
+// Entry is the part of an index record every store keeps. Stores embed it
+// in their own record type.
+type Entry struct {
+	Session adapter.Session `json:"session"`
+	Project string          `json:"project"` // absolute project root the session belongs to
+	File    string          `json:"file"`    // message file, relative to the store dir
+}
+
+// Index is the index.json of a session store, keyed by session ID.
+type Index[E any] struct {
+	dir   string
+	entry func(*E) *Entry
+
+	mu      sync.Mutex
+	records map[string]*E // session ID -> record
+	mod     time.Time     // index file mod time when last read or written
+}
+
+// NewIndex returns the index of the store in dir. entry returns the
+// embedded Entry of a record.
+func NewIndex[E any](dir string, entry func(*E) *Entry) *Index[E] {
+	return &Index[E]{dir: dir, entry: entry}
+}
+
+// HasProject reports whether any record belongs to the project.
+func (x *Index[E]) HasProject(projectRoot string) (bool, error) {
+	records, err := x.Project(projectRoot)
+	return len(records) > 0, err
+}
+
+// Project returns the records of the project.
+func (x *Index[E]) Project(projectRoot string) ([]*E, error) {
+	project := ProjectKey(projectRoot)
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if err := x.load(); err != nil {
+		return nil, err
+	}
+	var records []*E
+	for _, r := range x.records {
+		if x.entry(r).Project == project {
+			records = append(records, r)
+		}
+	}
+	return records, nil
+}
+
+// Projects returns the distinct project roots that have records, sorted.
+func (x *Index[E]) Projects() ([]string, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if err := x.load(); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var projects []string
+	for _, r := range x.records {
+		if p := x.entry(r).Project; !seen[p] {
+			seen[p] = true
+			projects = append(projects, p)
+		}
+	}
+	sort.Strings(projects)
+	return projects, nil
+}
+
+// Get returns the record of a session, or nil.
+func (x *Index[E]) Get(sessionID string) (*E, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if err := x.load(); err != nil {
+		return nil, err
+	}
+	return x.records[sessionID], nil
+}
+
+// Put adds a record, replacing any earlier one for the session.
+func (x *Index[E]) Put(r *E) error {
+	return x.Update(func(records map[string]*E) bool {
+		records[x.entry(r).Session.ID] = r
+		return true
+	})
+}
+
+// Update calls fn with the current records and saves them if fn reports a
+// change. The index stays locked while fn runs.
+func (x *Index[E]) Update(fn func(records map[string]*E) bool) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if err := x.load(); err != nil {
+		return err
+	}
+	if !fn(x.records) {
+		return nil
+	}
+	return x.save()
+}
+
+// load reads the index if it changed on disk since it was last read, so
+// several sidecar instances can share the store. Caller must hold x.mu.
+func (x *Index[E]) load() error {
+	path := filepath.Join(x.dir, indexFile)
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		if x.records == nil {
+			x.records = make(map[string]*E)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if x.records != nil && info.ModTime().Equal(x.mod) {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var records []*E
+	if err := json.Unmarshal(data, &records); err != nil {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	x.records = make(map[string]*E, len(records))
+	for _, r := range records {
+		x.records[x.entry(r).Session.ID] = r
+	}
+	x.mod = info.ModTime()
+	return nil
+}
+
+// save atomically writes the index. Caller must hold x.mu.
+func (x *Index[E]) save() error {
+	records := make([]*E, 0, len(x.records))
+	for _, r := range x.records {
+		records = append(records, r)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return x.entry(records[i]).Session.ID < x.entry(records[j]).Session.ID
+	})
+	data, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(x.dir, 0755); err != nil {
+		return err
+	}
+	path := filepath.Join(x.dir, indexFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	if info, err := os.Stat(path); err == nil {
+		x.mod = info.ModTime()
+	}
+	return nil
+}
+
+// SessionFile returns the message file for key, relative to the store dir.
+// Keys are hashed since agents use characters that aren't safe in file
+// names.
+func SessionFile(key, ext string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(sessionsDir, hex.EncodeToString(sum[:16])+ext)
+}
+
+// ProjectKey normalizes a project root for matching.
+func ProjectKey(projectRoot string) string {
+	if abs, err := filepath.Abs(projectRoot); err == nil {
+		return abs
+	}
+	return filepath.Clean(projectRoot)
+}
+
+// Usage totals the token usage of stored messages.
+func Usage(messages []adapter.Message) *adapter.UsageStats {
+	stats := &adapter.UsageStats{}
+	for _, m := range messages {
+		stats.TotalInputTokens += m.InputTokens
+		stats.TotalOutputTokens += m.OutputTokens
+		stats.TotalCacheRead += m.CacheRead
+		stats.TotalCacheWrite += m.CacheWrite
+	}
+	stats.MessageCount = len(messages)
+	return stats
+}
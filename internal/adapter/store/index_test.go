@@ -0,0 +1,68 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcus/sidecar/internal/adapter"
+)
+
+type testRecord struct {
+	Entry
+	Note string `json:"note"`
+}
+
+func newTestIndex(dir string) *Index[testRecord] {
+	return NewIndex(dir, func(r *testRecord) *Entry { return &r.Entry })
+}
+
+func TestIndexShared(t *testing.T) {
+	dir := t.TempDir()
+	a, b := newTestIndex(dir), newTestIndex(dir)
+
+	rec := &testRecord{Entry: Entry{Session: adapter.Session{ID: "s1"}, Project: ProjectKey("/proj"), File: "f"}, Note: "x"}
+	if err := a.Put(rec); err != nil {
+		t.Fatal(err)
+	}
+
+	// Entry fields are stored flat next to the store's own
+	data, err := os.ReadFile(filepath.Join(dir, indexFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"project":"/proj","file":"f","note":"x"`) {
+		t.Errorf("index = %s", data)
+	}
+
+	// Another instance sees the record; a later write shows up in the first
+	if ok, err := b.HasProject("/proj"); err != nil || !ok {
+		t.Fatalf("HasProject = %v, %v", ok, err)
+	}
+	time.Sleep(10 * time.Millisecond) // distinct mod time
+	err = b.Update(func(records map[string]*testRecord) bool {
+		delete(records, "s1")
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, err := a.Get("s1"); err != nil || r != nil {
+		t.Errorf("Get after delete = %+v, %v", r, err)
+	}
+	if recs, _ := a.Project("/other"); len(recs) != 0 {
+		t.Errorf("other project = %+v", recs)
+	}
+}
+
+func TestSessionFile(t *testing.T) {
+	f := SessionFile("claude-code\x00abc", ".json.gz")
+	if filepath.Dir(f) != sessionsDir || !strings.HasSuffix(f, ".json.gz") || f != SessionFile("claude-code\x00abc", ".json.gz") {
+		t.Errorf("SessionFile = %q", f)
+	}
+	if f == SessionFile("codex\x00abc", ".json.gz") {
+		t.Error("different keys should get different files")
+	}
+}
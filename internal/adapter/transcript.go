@@ -0,0 +1,13 @@
+package adapter
+
+// TranscriptVersion is the current version of the transcript format.
+const TranscriptVersion = 1
+
+// Transcript is sidecar's lossless session export: the session and all of
+// its messages as the adapter reported them, serialized as JSON. Transcripts
+// can be imported on another machine and browsed like native sessions.
+type Transcript struct {
+	Version  int       `json:"version"`
+	Session  Session   `json:"session"`
+	Messages []Message `json:"messages"`
+}
@@ -11,6 +11,9 @@ import (
 // ToastMsg is re-exported from msg package for backward compatibility.
 type ToastMsg = msg.ToastMsg
 
+// BudgetStatusMsg is re-exported from msg package.
+type BudgetStatusMsg = msg.BudgetStatusMsg
+
 // ShowToast is re-exported from msg package for backward compatibility.
 var ShowToast = msg.ShowToast
 
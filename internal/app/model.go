@@ -203,6 +203,9 @@ type Model struct {
 	statusExpiry  time.Time
 	statusIsError bool
 
+	// Budget indicator reported by the conversations plugin
+	budgetStatus BudgetStatusMsg
+
 	// Error handling
 	lastError error
 
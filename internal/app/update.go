@@ -199,6 +199,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.statusIsError = msg.IsError
 		return m, nil
 
+	case BudgetStatusMsg:
+		m.budgetStatus = msg
+		return m, nil
+
 	case RefreshMsg:
 		m.ui.MarkRefresh()
 		// Refresh active plugin
@@ -1318,7 +1322,7 @@ func isRootContext(ctx string) bool {
 		return true
 	case "git-status", "git-status-commits", "git-status-diff", "git-commit-preview":
 		return true
-	case "file-browser-tree", "file-browser-preview":
+	case "file-browser-tree", "file-browser-preview", "file-browser-structured":
 		return true
 	case "workspace-list", "workspace-preview":
 		return true
@@ -1362,7 +1366,7 @@ func isGlobalRefreshContext(ctx string) bool {
 		return true
 
 	// File browser preview - 'r' refreshes (no text input)
-	case "file-browser-preview":
+	case "file-browser-preview", "file-browser-structured":
 		return true
 
 	// Contexts where 'r' should be forwarded to plugin:
@@ -10,6 +10,7 @@ import (
 	"github.com/marcus/sidecar/internal/keymap"
 	"github.com/marcus/sidecar/internal/modal"
 	"github.com/marcus/sidecar/internal/mouse"
+	"github.com/marcus/sidecar/internal/msg"
 	"github.com/marcus/sidecar/internal/plugin"
 	"github.com/marcus/sidecar/internal/styles"
 	"github.com/marcus/sidecar/internal/ui"
@@ -514,6 +515,7 @@ func (m Model) renderHeader() string {
 	if m.showClock {
 		clock = styles.BarText.Render(m.ui.Clock.Format("15:04"))
 	}
+	clock = m.renderBudgetIndicator() + clock
 
 	// Calculate spacing (always use finalTitleWidth so tabs don't shift)
 	tabWidth := lipgloss.Width(tabBar)
@@ -530,6 +532,22 @@ func (m Model) renderHeader() string {
 	return styles.Header.Width(m.width).Render(header)
 }
 
+// renderBudgetIndicator renders the budget closest to its limit, colored
+// by how close it is.
+func (m Model) renderBudgetIndicator() string {
+	if m.budgetStatus.Text == "" {
+		return ""
+	}
+	style := styles.BarText
+	switch m.budgetStatus.Level {
+	case msg.BudgetWarning:
+		style = lipgloss.NewStyle().Foreground(styles.Warning).Bold(true)
+	case msg.BudgetExceeded:
+		style = lipgloss.NewStyle().Foreground(styles.Error).Bold(true)
+	}
+	return style.Render(m.budgetStatus.Text) + " "
+}
+
 // getTabBounds calculates the X position bounds for each tab in the header.
 // Used for mouse click detection on tabs.
 func (m Model) getTabBounds() []TabBounds {
@@ -565,7 +583,7 @@ func (m Model) getTabBounds() []TabBounds {
 	}
 
 	// Clock width
-	clock := styles.BarText.Render(m.ui.Clock.Format("15:04"))
+	clock := m.renderBudgetIndicator() + styles.BarText.Render(m.ui.Clock.Format("15:04"))
 	clockWidth := lipgloss.Width(clock)
 
 	// Calculate spacing
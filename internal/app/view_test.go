@@ -7,6 +7,7 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/marcus/sidecar/internal/config"
+	"github.com/marcus/sidecar/internal/msg"
 	"github.com/marcus/sidecar/internal/plugin"
 )
 
@@ -239,6 +240,24 @@ func TestRenderHeader_ShowClockConfig(t *testing.T) {
 	})
 }
 
+func TestRenderHeader_BudgetIndicator(t *testing.T) {
+	m := Model{
+		ui:       &UIState{Clock: time.Now()},
+		registry: plugin.NewRegistry(nil),
+		width:    120,
+		intro:    IntroModel{Done: true},
+	}
+	if strings.Contains(m.renderHeader(), "$") {
+		t.Error("header should not show a budget before one is reported")
+	}
+
+	updated, _ := m.Update(BudgetStatusMsg{Text: "$23/$20 day", Level: msg.BudgetExceeded})
+	header := updated.(Model).renderHeader()
+	if !strings.Contains(header, "$23/$20 day") {
+		t.Errorf("header should show the budget, got %q", header)
+	}
+}
+
 func TestIntroActive_SetFalseAfterCompletion(t *testing.T) {
 	m := Model{
 		intro: IntroModel{
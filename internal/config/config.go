@@ -1,6 +1,11 @@
 package config
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
 
 // Config is the root configuration structure.
 type Config struct {
@@ -9,6 +14,36 @@ type Config struct {
 	Keymap   KeymapConfig   `json:"keymap"`
 	UI       UIConfig       `json:"ui"`
 	Features FeaturesConfig `json:"features"`
+	Budgets  []BudgetConfig `json:"budgets,omitempty"`
+	History  HistoryConfig  `json:"history"`
+}
+
+// HistoryConfig configures local file history, which the file browser and
+// git status plugins both snapshot into.
+type HistoryConfig struct {
+	// MaxSizeMB caps local history snapshots in .sidecar/history
+	// (0 = 100, negative = disable local history).
+	MaxSizeMB int `json:"maxSizeMB,omitempty"`
+}
+
+// BudgetConfig limits the tokens or estimated cost of agent sessions over
+// a period. A session counts toward the period it was last active in.
+type BudgetConfig struct {
+	Name string `json:"name,omitempty"` // label for the header and alerts
+	// Project limits the budget to one project root (supports ~ expansion).
+	// Empty applies it to every project.
+	Project string `json:"project,omitempty"`
+	// Scope is "project" (all the project's worktrees, default) or
+	// "worktree" (the current worktree only).
+	Scope string `json:"scope,omitempty"`
+	// Worktree limits worktree scope to worktrees whose name matches this
+	// glob instead of the current worktree. Example: "feature-*".
+	Worktree string  `json:"worktree,omitempty"`
+	Period   string  `json:"period,omitempty"` // "day" (default), "week" or "month"
+	Cost     float64 `json:"cost,omitempty"`   // max estimated cost in dollars (0 = no limit)
+	Tokens   int     `json:"tokens,omitempty"` // max total tokens (0 = no limit)
+	// WarnAt is the fraction of the limit that raises a warning (0 = 0.8).
+	WarnAt float64 `json:"warnAt,omitempty"`
 }
 
 // FeaturesConfig holds feature flag settings.
@@ -37,12 +72,22 @@ type PluginsConfig struct {
 	Conversations ConversationsPluginConfig `json:"conversations"`
 	Workspace     WorkspacePluginConfig     `json:"workspace"`
 	Notes         NotesPluginConfig         `json:"notes"`
+	FileBrowser   FileBrowserPluginConfig   `json:"file-browser"`
 }
 
 // GitStatusPluginConfig configures the git status plugin.
 type GitStatusPluginConfig struct {
 	Enabled         bool          `json:"enabled"`
 	RefreshInterval time.Duration `json:"refreshInterval"`
+	// CommitMessageCommand generates a commit message from the staged diff.
+	// Run via `sh -c` in the repo root; the prompt (template instructions, recent
+	// commit subjects, staged diff) is written to stdin and stdout becomes the message.
+	// Example: "claude -p" or "llm -s 'Write a git commit message'". Empty disables.
+	CommitMessageCommand string `json:"commitMessageCommand,omitempty"`
+	// CommitMessageTemplate selects the message style requested from the command.
+	// Values: "conventional" (Conventional Commits), "" (match repo history),
+	// or any other text, which is used verbatim as the instructions.
+	CommitMessageTemplate string `json:"commitMessageTemplate,omitempty"`
 }
 
 // TDMonitorPluginConfig configures the TD monitor plugin.
@@ -60,6 +105,20 @@ type ConversationsPluginConfig struct {
 	// Example: ["interactive"] hides cron/system sessions by default.
 	// Empty or omitted means show all sessions (no filter).
 	DefaultCategoryFilter []string `json:"defaultCategoryFilter,omitempty"`
+	// Archive copies sessions into sidecar's own compressed store so they
+	// stay browsable after the agent prunes its history. Default: true.
+	Archive bool `json:"archive"`
+	// ArchiveMaxAge is how long archived sessions are kept after their last
+	// activity (0 = 1 year, negative = forever).
+	ArchiveMaxAge time.Duration `json:"archiveMaxAge,omitempty"`
+	// ArchiveMaxSizeMB caps the archive; the least recently active sessions
+	// expire first (0 = 1024, negative = unlimited).
+	ArchiveMaxSizeMB int `json:"archiveMaxSizeMB,omitempty"`
+	// HandoffSummaryCommand summarizes a session for a cross-agent handoff.
+	// Run via `sh -c` in the project root; instructions and the session as
+	// Markdown are written to stdin and stdout replaces the built-in turn
+	// summary. Example: "claude -p". Empty uses the built-in summary.
+	HandoffSummaryCommand string `json:"handoffSummaryCommand,omitempty"`
 }
 
 // WorkspacePluginConfig configures the workspace plugin.
@@ -81,6 +140,19 @@ type WorkspacePluginConfig struct {
 	InteractivePasteKey string `json:"interactivePasteKey,omitempty"`
 }
 
+// FileBrowserPluginConfig configures the file browser plugin.
+type FileBrowserPluginConfig struct {
+	// SearchBackend selects the project search engine: "auto" (ripgrep when
+	// installed, otherwise built-in), "ripgrep" or "builtin".
+	SearchBackend string `json:"searchBackend,omitempty"`
+	// TrashMaxAge is how long deleted files stay in the trash (0 = 30 days,
+	// negative = never expire).
+	TrashMaxAge time.Duration `json:"trashMaxAge,omitempty"`
+	// TrashMaxSizeMB caps the project's trashed files; the oldest expire
+	// first (0 = 1024, negative = unlimited).
+	TrashMaxSizeMB int `json:"trashMaxSizeMB,omitempty"`
+}
+
 // NotesPluginConfig configures the notes plugin.
 type NotesPluginConfig struct {
 	// DefaultEditor sets the default editor mode when pressing Enter on a note.
@@ -128,6 +200,7 @@ func Default() *Config {
 			Conversations: ConversationsPluginConfig{
 				Enabled:       true,
 				ClaudeDataDir: "~/.claude",
+				Archive:       true,
 			},
 			Workspace: WorkspacePluginConfig{
 				DirPrefix:           true,
@@ -161,5 +234,37 @@ func (c *Config) Validate() error {
 	if c.Plugins.Workspace.TmuxCaptureMaxBytes <= 0 {
 		c.Plugins.Workspace.TmuxCaptureMaxBytes = 2 * 1024 * 1024
 	}
+	// A bad budget is dropped with a warning rather than failing Load, so a
+	// typo doesn't keep sidecar from starting.
+	budgets := c.Budgets[:0]
+	for i, b := range c.Budgets {
+		if err := b.validate(); err != nil {
+			slog.Warn("config: ignoring invalid budget", "index", i, "error", err)
+			continue
+		}
+		if b.WarnAt <= 0 || b.WarnAt >= 1 {
+			b.WarnAt = 0.8
+		}
+		budgets = append(budgets, b)
+	}
+	c.Budgets = budgets
+	return nil
+}
+
+// validate reports why a budget can't be tracked.
+func (b BudgetConfig) validate() error {
+	switch b.Scope {
+	case "", "project", "worktree":
+	default:
+		return fmt.Errorf("unknown scope %q (want project or worktree)", b.Scope)
+	}
+	switch b.Period {
+	case "", "day", "week", "month":
+	default:
+		return fmt.Errorf("unknown period %q (want day, week or month)", b.Period)
+	}
+	if b.Cost <= 0 && b.Tokens <= 0 {
+		return errors.New("set cost or tokens")
+	}
 	return nil
 }
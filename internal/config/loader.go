@@ -35,6 +35,12 @@ type rawConfig struct {
 	Keymap   KeymapConfig      `json:"keymap"`
 	UI       rawUIConfig       `json:"ui"`
 	Features FeaturesConfig    `json:"features"`
+	Budgets  []BudgetConfig    `json:"budgets"`
+	History  rawHistoryConfig  `json:"history"`
+}
+
+type rawHistoryConfig struct {
+	MaxSizeMB *int `json:"maxSizeMB"`
 }
 
 type rawUIConfig struct {
@@ -59,7 +65,14 @@ type rawPluginsConfig struct {
 	GitStatus     rawGitStatusConfig     `json:"git-status"`
 	TDMonitor     rawTDMonitorConfig     `json:"td-monitor"`
 	Conversations rawConversationsConfig `json:"conversations"`
-	Workspace     rawWorkspaceConfig      `json:"workspace"`
+	Workspace     rawWorkspaceConfig     `json:"workspace"`
+	FileBrowser   rawFileBrowserConfig   `json:"file-browser"`
+}
+
+type rawFileBrowserConfig struct {
+	SearchBackend  string `json:"searchBackend"`
+	TrashMaxAge    string `json:"trashMaxAge"`
+	TrashMaxSizeMB *int   `json:"trashMaxSizeMB"`
 }
 
 type rawWorkspaceConfig struct {
@@ -72,8 +85,10 @@ type rawWorkspaceConfig struct {
 }
 
 type rawGitStatusConfig struct {
-	Enabled         *bool  `json:"enabled"`
-	RefreshInterval string `json:"refreshInterval"`
+	Enabled               *bool  `json:"enabled"`
+	RefreshInterval       string `json:"refreshInterval"`
+	CommitMessageCommand  string `json:"commitMessageCommand"`
+	CommitMessageTemplate string `json:"commitMessageTemplate"`
 }
 
 type rawTDMonitorConfig struct {
@@ -83,8 +98,13 @@ type rawTDMonitorConfig struct {
 }
 
 type rawConversationsConfig struct {
-	Enabled       *bool  `json:"enabled"`
-	ClaudeDataDir string `json:"claudeDataDir"`
+	Enabled          *bool  `json:"enabled"`
+	ClaudeDataDir    string `json:"claudeDataDir"`
+	Archive          *bool  `json:"archive"`
+	ArchiveMaxAge    string `json:"archiveMaxAge"`
+	ArchiveMaxSizeMB *int   `json:"archiveMaxSizeMB"`
+
+	HandoffSummaryCommand string `json:"handoffSummaryCommand"`
 }
 
 // Load loads configuration from the default location.
@@ -124,6 +144,10 @@ func LoadFrom(path string) (*Config, error) {
 	// Expand paths
 	cfg.Plugins.Conversations.ClaudeDataDir = ExpandPath(cfg.Plugins.Conversations.ClaudeDataDir)
 
+	for i := range cfg.Budgets {
+		cfg.Budgets[i].Project = ExpandPath(cfg.Budgets[i].Project)
+	}
+
 	// Expand paths in project list and warn if path doesn't exist
 	for i := range cfg.Projects.List {
 		cfg.Projects.List[i].Path = ExpandPath(cfg.Projects.List[i].Path)
@@ -165,6 +189,12 @@ func mergeConfig(cfg *Config, raw *rawConfig) {
 			cfg.Plugins.GitStatus.RefreshInterval = d
 		}
 	}
+	if raw.Plugins.GitStatus.CommitMessageCommand != "" {
+		cfg.Plugins.GitStatus.CommitMessageCommand = raw.Plugins.GitStatus.CommitMessageCommand
+	}
+	if raw.Plugins.GitStatus.CommitMessageTemplate != "" {
+		cfg.Plugins.GitStatus.CommitMessageTemplate = raw.Plugins.GitStatus.CommitMessageTemplate
+	}
 
 	// TD Monitor
 	if raw.Plugins.TDMonitor.Enabled != nil {
@@ -186,6 +216,20 @@ func mergeConfig(cfg *Config, raw *rawConfig) {
 	if raw.Plugins.Conversations.ClaudeDataDir != "" {
 		cfg.Plugins.Conversations.ClaudeDataDir = raw.Plugins.Conversations.ClaudeDataDir
 	}
+	if raw.Plugins.Conversations.Archive != nil {
+		cfg.Plugins.Conversations.Archive = *raw.Plugins.Conversations.Archive
+	}
+	if raw.Plugins.Conversations.ArchiveMaxAge != "" {
+		if d, err := time.ParseDuration(raw.Plugins.Conversations.ArchiveMaxAge); err == nil {
+			cfg.Plugins.Conversations.ArchiveMaxAge = d
+		}
+	}
+	if raw.Plugins.Conversations.ArchiveMaxSizeMB != nil {
+		cfg.Plugins.Conversations.ArchiveMaxSizeMB = *raw.Plugins.Conversations.ArchiveMaxSizeMB
+	}
+	if raw.Plugins.Conversations.HandoffSummaryCommand != "" {
+		cfg.Plugins.Conversations.HandoffSummaryCommand = raw.Plugins.Conversations.HandoffSummaryCommand
+	}
 
 	// Workspace
 	if raw.Plugins.Workspace.DirPrefix != nil {
@@ -207,6 +251,19 @@ func mergeConfig(cfg *Config, raw *rawConfig) {
 		cfg.Plugins.Workspace.InteractivePasteKey = raw.Plugins.Workspace.InteractivePasteKey
 	}
 
+	// File Browser
+	if raw.Plugins.FileBrowser.SearchBackend != "" {
+		cfg.Plugins.FileBrowser.SearchBackend = raw.Plugins.FileBrowser.SearchBackend
+	}
+	if raw.Plugins.FileBrowser.TrashMaxAge != "" {
+		if d, err := time.ParseDuration(raw.Plugins.FileBrowser.TrashMaxAge); err == nil {
+			cfg.Plugins.FileBrowser.TrashMaxAge = d
+		}
+	}
+	if raw.Plugins.FileBrowser.TrashMaxSizeMB != nil {
+		cfg.Plugins.FileBrowser.TrashMaxSizeMB = *raw.Plugins.FileBrowser.TrashMaxSizeMB
+	}
+
 	// Keymap
 	if raw.Keymap.Overrides != nil {
 		for k, v := range raw.Keymap.Overrides {
@@ -249,6 +306,16 @@ func mergeConfig(cfg *Config, raw *rawConfig) {
 			cfg.Features.Flags[k] = v
 		}
 	}
+
+	// Budgets
+	if raw.Budgets != nil {
+		cfg.Budgets = raw.Budgets
+	}
+
+	// Local history
+	if raw.History.MaxSizeMB != nil {
+		cfg.History.MaxSizeMB = *raw.History.MaxSizeMB
+	}
 }
 
 // ExpandPath expands ~ to home directory.
@@ -39,9 +39,25 @@ func TestLoadFrom_ValidJSON(t *testing.T) {
 		"plugins": {
 			"git-status": {
 				"enabled": false,
-				"refreshInterval": "5s"
+				"refreshInterval": "5s",
+				"commitMessageCommand": "llm -s 'commit message'",
+				"commitMessageTemplate": "conventional"
+			},
+			"file-browser": {
+				"searchBackend": "builtin"
+			},
+			"conversations": {
+				"archive": false,
+				"archiveMaxAge": "4380h",
+				"archiveMaxSizeMB": -1
 			}
-		}
+		},
+		"budgets": [
+			{"name": "daily", "cost": 20},
+			{"name": "typo", "period": "year", "cost": 5},
+			{"project": "~/code/app", "scope": "worktree", "worktree": "feature-*", "period": "month", "tokens": 5000000, "warnAt": 0.9}
+		],
+		"history": {"maxSizeMB": -1}
 	}`)
 
 	if err := os.WriteFile(path, content, 0644); err != nil {
@@ -59,6 +75,32 @@ func TestLoadFrom_ValidJSON(t *testing.T) {
 	if cfg.Plugins.GitStatus.RefreshInterval != 5*time.Second {
 		t.Errorf("got refresh %v, want 5s", cfg.Plugins.GitStatus.RefreshInterval)
 	}
+	if cfg.Plugins.GitStatus.CommitMessageCommand != "llm -s 'commit message'" {
+		t.Errorf("got commitMessageCommand %q", cfg.Plugins.GitStatus.CommitMessageCommand)
+	}
+	if cfg.Plugins.GitStatus.CommitMessageTemplate != "conventional" {
+		t.Errorf("got commitMessageTemplate %q, want conventional", cfg.Plugins.GitStatus.CommitMessageTemplate)
+	}
+	if cfg.Plugins.FileBrowser.SearchBackend != "builtin" {
+		t.Errorf("got searchBackend %q, want builtin", cfg.Plugins.FileBrowser.SearchBackend)
+	}
+	conv := cfg.Plugins.Conversations
+	if conv.Archive || conv.ArchiveMaxAge != 4380*time.Hour || conv.ArchiveMaxSizeMB != -1 {
+		t.Errorf("got archive %v, maxAge %v, maxSizeMB %d", conv.Archive, conv.ArchiveMaxAge, conv.ArchiveMaxSizeMB)
+	}
+	if len(cfg.Budgets) != 2 {
+		t.Fatalf("got %d budgets, want 2 (invalid one dropped)", len(cfg.Budgets))
+	}
+	if b := cfg.Budgets[0]; b.Name != "daily" || b.Cost != 20 || b.WarnAt != 0.8 {
+		t.Errorf("got budget %+v", b)
+	}
+	if cfg.History.MaxSizeMB != -1 {
+		t.Errorf("got history maxSizeMB %d, want -1", cfg.History.MaxSizeMB)
+	}
+	home, _ := os.UserHomeDir()
+	if b := cfg.Budgets[1]; b.Project != filepath.Join(home, "code/app") || b.Scope != "worktree" || b.Period != "month" || b.Tokens != 5000000 || b.WarnAt != 0.9 {
+		t.Errorf("got budget %+v", b)
+	}
 	// Default values should still be present
 	if !cfg.Plugins.TDMonitor.Enabled {
 		t.Error("td-monitor should still be enabled (default)")
@@ -111,6 +153,20 @@ func TestValidate(t *testing.T) {
 	if cfg.Plugins.GitStatus.RefreshInterval != time.Second {
 		t.Errorf("got %v, want 1s after validation", cfg.Plugins.GitStatus.RefreshInterval)
 	}
+
+	// Invalid budgets are dropped without failing validation
+	cfg.Budgets = []BudgetConfig{
+		{Period: "year", Cost: 1},
+		{Name: "ok", Tokens: 100},
+		{Scope: "repo", Cost: 1},
+		{Period: "day"},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if len(cfg.Budgets) != 1 || cfg.Budgets[0].Name != "ok" {
+		t.Errorf("got budgets %+v, want only ok", cfg.Budgets)
+	}
 }
 
 func TestLoadFrom_ProjectsList(t *testing.T) {
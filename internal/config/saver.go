@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 // saveConfig is the JSON-marshaling intermediary that uses string durations.
@@ -27,12 +28,21 @@ type savePluginsConfig struct {
 	GitStatus     saveGitStatusConfig     `json:"git-status,omitempty"`
 	TDMonitor     saveTDMonitorConfig     `json:"td-monitor,omitempty"`
 	Conversations saveConversationsConfig `json:"conversations,omitempty"`
-	Workspace     saveWorkspaceConfig      `json:"workspace,omitempty"`
+	Workspace     saveWorkspaceConfig     `json:"workspace,omitempty"`
+	FileBrowser   saveFileBrowserConfig   `json:"file-browser,omitempty"`
+}
+
+type saveFileBrowserConfig struct {
+	SearchBackend  string `json:"searchBackend,omitempty"`
+	TrashMaxAge    string `json:"trashMaxAge,omitempty"`
+	TrashMaxSizeMB int    `json:"trashMaxSizeMB,omitempty"`
 }
 
 type saveGitStatusConfig struct {
-	Enabled         *bool  `json:"enabled,omitempty"`
-	RefreshInterval string `json:"refreshInterval,omitempty"`
+	Enabled               *bool  `json:"enabled,omitempty"`
+	RefreshInterval       string `json:"refreshInterval,omitempty"`
+	CommitMessageCommand  string `json:"commitMessageCommand,omitempty"`
+	CommitMessageTemplate string `json:"commitMessageTemplate,omitempty"`
 }
 
 type saveTDMonitorConfig struct {
@@ -42,8 +52,13 @@ type saveTDMonitorConfig struct {
 }
 
 type saveConversationsConfig struct {
-	Enabled       *bool  `json:"enabled,omitempty"`
-	ClaudeDataDir string `json:"claudeDataDir,omitempty"`
+	Enabled          *bool  `json:"enabled,omitempty"`
+	ClaudeDataDir    string `json:"claudeDataDir,omitempty"`
+	Archive          *bool  `json:"archive,omitempty"`
+	ArchiveMaxAge    string `json:"archiveMaxAge,omitempty"`
+	ArchiveMaxSizeMB int    `json:"archiveMaxSizeMB,omitempty"`
+
+	HandoffSummaryCommand string `json:"handoffSummaryCommand,omitempty"`
 }
 
 type saveWorkspaceConfig struct {
@@ -65,8 +80,10 @@ func toSaveConfig(cfg *Config) saveConfig {
 		},
 		Plugins: savePluginsConfig{
 			GitStatus: saveGitStatusConfig{
-				Enabled:         &cfg.Plugins.GitStatus.Enabled,
-				RefreshInterval: cfg.Plugins.GitStatus.RefreshInterval.String(),
+				Enabled:               &cfg.Plugins.GitStatus.Enabled,
+				RefreshInterval:       cfg.Plugins.GitStatus.RefreshInterval.String(),
+				CommitMessageCommand:  cfg.Plugins.GitStatus.CommitMessageCommand,
+				CommitMessageTemplate: cfg.Plugins.GitStatus.CommitMessageTemplate,
 			},
 			TDMonitor: saveTDMonitorConfig{
 				Enabled:         &cfg.Plugins.TDMonitor.Enabled,
@@ -74,8 +91,13 @@ func toSaveConfig(cfg *Config) saveConfig {
 				DBPath:          cfg.Plugins.TDMonitor.DBPath,
 			},
 			Conversations: saveConversationsConfig{
-				Enabled:       &cfg.Plugins.Conversations.Enabled,
-				ClaudeDataDir: cfg.Plugins.Conversations.ClaudeDataDir,
+				Enabled:          &cfg.Plugins.Conversations.Enabled,
+				ClaudeDataDir:    cfg.Plugins.Conversations.ClaudeDataDir,
+				Archive:          &cfg.Plugins.Conversations.Archive,
+				ArchiveMaxAge:    maxAgeString(cfg.Plugins.Conversations.ArchiveMaxAge),
+				ArchiveMaxSizeMB: cfg.Plugins.Conversations.ArchiveMaxSizeMB,
+
+				HandoffSummaryCommand: cfg.Plugins.Conversations.HandoffSummaryCommand,
 			},
 			Workspace: saveWorkspaceConfig{
 				DirPrefix:            &cfg.Plugins.Workspace.DirPrefix,
@@ -85,6 +107,11 @@ func toSaveConfig(cfg *Config) saveConfig {
 				InteractiveCopyKey:   cfg.Plugins.Workspace.InteractiveCopyKey,
 				InteractivePasteKey:  cfg.Plugins.Workspace.InteractivePasteKey,
 			},
+			FileBrowser: saveFileBrowserConfig{
+				SearchBackend:  cfg.Plugins.FileBrowser.SearchBackend,
+				TrashMaxAge:    maxAgeString(cfg.Plugins.FileBrowser.TrashMaxAge),
+				TrashMaxSizeMB: cfg.Plugins.FileBrowser.TrashMaxSizeMB,
+			},
 		},
 		Keymap:   cfg.Keymap,
 		UI:       cfg.UI,
@@ -92,6 +119,14 @@ func toSaveConfig(cfg *Config) saveConfig {
 	}
 }
 
+// maxAgeString formats an age limit, omitting the default.
+func maxAgeString(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
+}
+
 // Save writes the config to ~/.config/sidecar/config.json, preserving
 // any keys it doesn't manage (e.g. "prompts").
 func Save(cfg *Config) error {
@@ -72,6 +72,12 @@ func DefaultBindings() []Binding {
 		{Key: "y", Command: "yank-file", Context: "git-status"},
 		{Key: "Y", Command: "yank-path", Context: "git-status"},
 		{Key: "D", Command: "discard-changes", Context: "git-status"},
+		{Key: "B", Command: "bisect", Context: "git-status"},
+		{Key: "R", Command: "reflog", Context: "git-status"},
+		{Key: "alt+z", Command: "undo", Context: "git-status"},
+		{Key: "M", Command: "remotes", Context: "git-status"},
+		{Key: "m", Command: "submodules", Context: "git-status"},
+		{Key: "backspace", Command: "leave-submodule", Context: "git-status"},
 		{Key: "\\", Command: "toggle-sidebar", Context: "git-status"},
 
 		// Git status commits context (sidebar)
@@ -92,6 +98,12 @@ func DefaultBindings() []Binding {
 		{Key: "v", Command: "toggle-graph", Context: "git-status-commits"},
 		{Key: "P", Command: "push", Context: "git-status-commits"},
 		{Key: "L", Command: "pull", Context: "git-status-commits"},
+		{Key: "B", Command: "bisect", Context: "git-status-commits"},
+		{Key: "R", Command: "reflog", Context: "git-status-commits"},
+		{Key: "alt+z", Command: "undo", Context: "git-status-commits"},
+		{Key: "M", Command: "remotes", Context: "git-status-commits"},
+		{Key: "m", Command: "submodules", Context: "git-status-commits"},
+		{Key: "backspace", Command: "leave-submodule", Context: "git-status-commits"},
 		{Key: "\\", Command: "toggle-sidebar", Context: "git-status-commits"},
 
 		// Git history search modal context
@@ -104,6 +116,39 @@ func DefaultBindings() []Binding {
 		{Key: "alt+r", Command: "toggle-regex", Context: "git-history-search"},
 		{Key: "alt+c", Command: "toggle-case", Context: "git-history-search"},
 
+		// Git bisect modal context
+		{Key: "b", Command: "bisect-bad", Context: "git-bisect"},
+		{Key: "g", Command: "bisect-good", Context: "git-bisect"},
+		{Key: "s", Command: "bisect-skip", Context: "git-bisect"},
+		{Key: "r", Command: "bisect-run", Context: "git-bisect"},
+		{Key: "x", Command: "bisect-reset", Context: "git-bisect"},
+		{Key: "esc", Command: "close", Context: "git-bisect"},
+
+		// Git reflog browser context
+		{Key: "enter", Command: "view-diff", Context: "git-reflog"},
+		{Key: "X", Command: "reset-hard", Context: "git-reflog"},
+		{Key: "u", Command: "undo", Context: "git-reflog"},
+		{Key: "tab", Command: "toggle-ref", Context: "git-reflog"},
+		{Key: "esc", Command: "close", Context: "git-reflog"},
+
+		// Git submodules modal
+		{Key: "enter", Command: "enter-submodule", Context: "git-submodules"},
+		{Key: "i", Command: "init-submodule", Context: "git-submodules"},
+		{Key: "u", Command: "update-submodule", Context: "git-submodules"},
+		{Key: "s", Command: "stage-submodule", Context: "git-submodules"},
+		{Key: "esc", Command: "close", Context: "git-submodules"},
+
+		// Git remotes pane
+		{Key: "a", Command: "add-remote", Context: "git-remotes"},
+		{Key: "f", Command: "fetch-remote", Context: "git-remotes"},
+		{Key: "u", Command: "set-upstream", Context: "git-remotes"},
+		{Key: "r", Command: "rename-remote", Context: "git-remotes"},
+		{Key: "e", Command: "edit-remote-url", Context: "git-remotes"},
+		{Key: "d", Command: "remove-remote", Context: "git-remotes"},
+		{Key: "P", Command: "push-to-remote", Context: "git-remotes"},
+		{Key: "L", Command: "pull-from-remote", Context: "git-remotes"},
+		{Key: "esc", Command: "close", Context: "git-remotes"},
+
 		// Git path filter modal context
 		{Key: "enter", Command: "apply-filter", Context: "git-path-filter"},
 		{Key: "esc", Command: "cancel", Context: "git-path-filter"},
@@ -154,6 +199,7 @@ func DefaultBindings() []Binding {
 		{Key: "p", Command: "push", Context: "git-push-menu"},
 		{Key: "f", Command: "force-push", Context: "git-push-menu"},
 		{Key: "u", Command: "push-upstream", Context: "git-push-menu"},
+		{Key: "R", Command: "cycle-remote", Context: "git-push-menu"},
 		{Key: "esc", Command: "cancel", Context: "git-push-menu"},
 
 		// Git pull menu context
@@ -161,6 +207,7 @@ func DefaultBindings() []Binding {
 		{Key: "r", Command: "pull-rebase", Context: "git-pull-menu"},
 		{Key: "f", Command: "pull-ff-only", Context: "git-pull-menu"},
 		{Key: "a", Command: "pull-autostash", Context: "git-pull-menu"},
+		{Key: "R", Command: "cycle-remote", Context: "git-pull-menu"},
 		{Key: "esc", Command: "cancel", Context: "git-pull-menu"},
 
 		// Issue preview context
@@ -186,10 +233,15 @@ func DefaultBindings() []Binding {
 		{Key: "y", Command: "confirm-pop", Context: "git-stash-pop"},
 		{Key: "esc", Command: "dismiss", Context: "git-stash-pop"},
 
+		// Git hard reset / branch delete confirm context
+		{Key: "y", Command: "confirm", Context: "git-confirm-undoable"},
+		{Key: "esc", Command: "dismiss", Context: "git-confirm-undoable"},
+
 		// Git commit context
 		{Key: "ctrl+s", Command: "execute-commit", Context: "git-commit"},
 		{Key: "ctrl+enter", Command: "execute-commit", Context: "git-commit"},
 		{Key: "esc", Command: "cancel", Context: "git-commit"},
+		{Key: "ctrl+g", Command: "generate-message", Context: "git-commit"},
 
 		// Git history context
 		{Key: "esc", Command: "close-history", Context: "git-history"},
@@ -221,6 +273,11 @@ func DefaultBindings() []Binding {
 		{Key: "Y", Command: "yank-resume", Context: "conversations-sidebar"},
 		{Key: "C", Command: "toggle-category", Context: "conversations-sidebar"},
 		{Key: "R", Command: "resume-in-workspace", Context: "conversations-sidebar"},
+		{Key: "D", Command: "file-changes", Context: "conversations-sidebar"},
+		{Key: "space", Command: "toggle-sub-agents", Context: "conversations-sidebar"},
+		{Key: "T", Command: "edit-tags", Context: "conversations-sidebar"},
+		{Key: "O", Command: "tool-analytics", Context: "conversations-sidebar"},
+		{Key: "=", Command: "mark-compare", Context: "conversations-sidebar"},
 
 		// Conversations main context (two-pane mode, right pane focused)
 		{Key: "tab", Command: "switch-pane", Context: "conversations-main"},
@@ -239,6 +296,49 @@ func DefaultBindings() []Binding {
 		{Key: "y", Command: "yank-details", Context: "conversations-main"},
 		{Key: "Y", Command: "yank-resume", Context: "conversations-main"},
 		{Key: "R", Command: "resume-in-workspace", Context: "conversations-main"},
+		{Key: "D", Command: "file-changes", Context: "conversations-main"},
+		{Key: "J", Command: "export-json", Context: "conversations-main"},
+		{Key: "a", Command: "open-linked-session", Context: "conversations-main"},
+		{Key: "b", Command: "bookmark-message", Context: "conversations-main"},
+		{Key: "]", Command: "next-bookmark", Context: "conversations-main"},
+		{Key: "[", Command: "prev-bookmark", Context: "conversations-main"},
+		{Key: "s", Command: "toggle-star", Context: "conversations-main"},
+		{Key: "T", Command: "edit-tags", Context: "conversations-main"},
+		{Key: "O", Command: "tool-analytics", Context: "conversations-main"},
+
+		// Conversations search context
+		{Key: "enter", Command: "select", Context: "conversations-search"},
+		{Key: "esc", Command: "cancel", Context: "conversations-search"},
+		{Key: "tab", Command: "complete-query", Context: "conversations-search"},
+		{Key: "ctrl+s", Command: "save-query", Context: "conversations-search"},
+
+		// Conversations tool analytics context
+		{Key: "esc", Command: "back", Context: "conversations-tools"},
+		{Key: "j", Command: "scroll", Context: "conversations-tools"},
+		{Key: "k", Command: "scroll", Context: "conversations-tools"},
+		{Key: "enter", Command: "open-call", Context: "conversations-tools"},
+		{Key: "tab", Command: "switch-focus", Context: "conversations-tools"},
+		{Key: "/", Command: "filter", Context: "conversations-tools"},
+		{Key: "e", Command: "toggle-errors", Context: "conversations-tools"},
+		{Key: "s", Command: "toggle-scope", Context: "conversations-tools"},
+		{Key: "r", Command: "refresh", Context: "conversations-tools"},
+		{Key: "enter", Command: "apply", Context: "conversations-tools-filter"},
+		{Key: "tab", Command: "complete-query", Context: "conversations-tools-filter"},
+		{Key: "esc", Command: "cancel", Context: "conversations-tools-filter"},
+
+		// Conversations session comparison context
+		{Key: "esc", Command: "back", Context: "conversations-compare"},
+		{Key: "j", Command: "scroll", Context: "conversations-compare"},
+		{Key: "k", Command: "scroll", Context: "conversations-compare"},
+		{Key: "s", Command: "swap-sides", Context: "conversations-compare"},
+
+		// Conversations file changes context
+		{Key: "esc", Command: "back", Context: "conversations-changes"},
+		{Key: "j", Command: "scroll", Context: "conversations-changes"},
+		{Key: "k", Command: "scroll", Context: "conversations-changes"},
+		{Key: "enter", Command: "view-turn", Context: "conversations-changes"},
+		{Key: "o", Command: "open-file", Context: "conversations-changes"},
+		{Key: "d", Command: "git-diff", Context: "conversations-changes"},
 
 		// File browser tree context
 		{Key: "tab", Command: "switch-pane", Context: "file-browser-tree"},
@@ -246,6 +346,8 @@ func DefaultBindings() []Binding {
 		{Key: "/", Command: "search", Context: "file-browser-tree"},
 		{Key: "ctrl+p", Command: "quick-open", Context: "file-browser-tree"},
 		{Key: "f", Command: "project-search", Context: "file-browser-tree"},
+		{Key: "O", Command: "symbol-outline", Context: "file-browser-tree"},
+		{Key: "ctrl+t", Command: "project-symbols", Context: "file-browser-tree"},
 		{Key: "t", Command: "new-tab", Context: "file-browser-tree"},
 		{Key: "[", Command: "prev-tab", Context: "file-browser-tree"},
 		{Key: "]", Command: "next-tab", Context: "file-browser-tree"},
@@ -256,6 +358,14 @@ func DefaultBindings() []Binding {
 		{Key: "y", Command: "yank", Context: "file-browser-tree"},
 		{Key: "Y", Command: "copy-path", Context: "file-browser-tree"},
 		{Key: "p", Command: "paste", Context: "file-browser-tree"},
+		{Key: "space", Command: "toggle-select", Context: "file-browser-tree"},
+		{Key: "V", Command: "select-range", Context: "file-browser-tree"},
+		{Key: "*", Command: "glob-select", Context: "file-browser-tree"},
+		{Key: "u", Command: "undo-batch", Context: "file-browser-tree"},
+		{Key: "esc", Command: "clear-selection", Context: "file-browser-tree"},
+		{Key: "T", Command: "trash", Context: "file-browser-tree"},
+		{Key: "=", Command: "mark-compare", Context: "file-browser-tree"},
+		{Key: "+", Command: "compare-with", Context: "file-browser-tree"},
 		{Key: "s", Command: "sort", Context: "file-browser-tree"},
 		{Key: "r", Command: "refresh", Context: "file-browser-tree"},
 		{Key: "m", Command: "move", Context: "file-browser-tree"},
@@ -274,6 +384,14 @@ func DefaultBindings() []Binding {
 		{Key: "/", Command: "search-content", Context: "file-browser-preview"},
 		{Key: "ctrl+p", Command: "quick-open", Context: "file-browser-preview"},
 		{Key: "f", Command: "project-search", Context: "file-browser-preview"},
+		{Key: "d", Command: "goto-definition", Context: "file-browser-preview"},
+		{Key: "ctrl+o", Command: "jump-back", Context: "file-browser-preview"},
+		{Key: "O", Command: "symbol-outline", Context: "file-browser-preview"},
+		{Key: "ctrl+t", Command: "project-symbols", Context: "file-browser-preview"},
+		{Key: "}", Command: "next-change", Context: "file-browser-preview"},
+		{Key: "{", Command: "prev-change", Context: "file-browser-preview"},
+		{Key: "p", Command: "peek-change", Context: "file-browser-preview"},
+		{Key: "b", Command: "toggle-diff-base", Context: "file-browser-preview"},
 		{Key: "[", Command: "prev-tab", Context: "file-browser-preview"},
 		{Key: "]", Command: "next-tab", Context: "file-browser-preview"},
 		{Key: "x", Command: "close-tab", Context: "file-browser-preview"},
@@ -284,6 +402,8 @@ func DefaultBindings() []Binding {
 		{Key: "e", Command: "edit", Context: "file-browser-preview"},
 		{Key: "E", Command: "edit-external", Context: "file-browser-preview"},
 		{Key: "B", Command: "blame", Context: "file-browser-preview"},
+		{Key: "L", Command: "local-history", Context: "file-browser-preview"},
+		{Key: "F", Command: "tail", Context: "file-browser-preview"},
 		{Key: "m", Command: "toggle-markdown", Context: "file-browser-preview"},
 		{Key: "esc", Command: "back", Context: "file-browser-preview"},
 		{Key: "h", Command: "back", Context: "file-browser-preview"},
@@ -291,6 +411,25 @@ func DefaultBindings() []Binding {
 		{Key: "Y", Command: "yank-path", Context: "file-browser-preview"},
 		{Key: "\\", Command: "toggle-sidebar", Context: "file-browser-preview"},
 		{Key: "w", Command: "toggle-wrap", Context: "file-browser-preview"},
+		{Key: "v", Command: "toggle-structured", Context: "file-browser-preview"},
+
+		// File browser tail mode context
+		{Key: "space", Command: "toggle-follow", Context: "file-browser-tail"},
+		{Key: "/", Command: "filter-tail", Context: "file-browser-tail"},
+		{Key: "F", Command: "tail", Context: "file-browser-tail"},
+		{Key: "esc", Command: "back", Context: "file-browser-tail"},
+		{Key: "j", Command: "cursor-down", Context: "file-browser-tail"},
+		{Key: "k", Command: "cursor-up", Context: "file-browser-tail"},
+
+		// File browser structured view context (JSON/YAML tree, CSV/TSV table, SQLite tables)
+		{Key: "enter", Command: "toggle-node", Context: "file-browser-structured"},
+		{Key: "y", Command: "copy-path", Context: "file-browser-structured"},
+		{Key: "s", Command: "sort-column", Context: "file-browser-structured"},
+		{Key: "t", Command: "next-table", Context: "file-browser-structured"},
+		{Key: "n", Command: "next-page", Context: "file-browser-structured"},
+		{Key: "N", Command: "prev-page", Context: "file-browser-structured"},
+		{Key: "v", Command: "toggle-structured", Context: "file-browser-structured"},
+		{Key: "esc", Command: "back", Context: "file-browser-structured"},
 
 		// File browser tree search context
 		{Key: "esc", Command: "cancel", Context: "file-browser-search"},
@@ -312,6 +451,40 @@ func DefaultBindings() []Binding {
 		{Key: "ctrl+n", Command: "cursor-down", Context: "file-browser-quick-open"},
 		{Key: "ctrl+p", Command: "cursor-up", Context: "file-browser-quick-open"},
 
+		// File browser symbol palette context (outline, project symbols, definitions)
+		{Key: "esc", Command: "cancel", Context: "file-browser-symbols"},
+		{Key: "enter", Command: "select", Context: "file-browser-symbols"},
+		{Key: "tab", Command: "toggle-scope", Context: "file-browser-symbols"},
+		{Key: "up", Command: "cursor-up", Context: "file-browser-symbols"},
+		{Key: "down", Command: "cursor-down", Context: "file-browser-symbols"},
+		{Key: "ctrl+n", Command: "cursor-down", Context: "file-browser-symbols"},
+		{Key: "ctrl+p", Command: "cursor-up", Context: "file-browser-symbols"},
+
+		// File browser compare context
+		{Key: "enter", Command: "open-diff", Context: "file-browser-compare"},
+		{Key: "v", Command: "toggle-diff-view", Context: "file-browser-compare"},
+		{Key: "n", Command: "next-file", Context: "file-browser-compare"},
+		{Key: "esc", Command: "close", Context: "file-browser-compare"},
+		{Key: "j", Command: "cursor-down", Context: "file-browser-compare"},
+		{Key: "k", Command: "cursor-up", Context: "file-browser-compare"},
+
+		// File browser local history context
+		{Key: "enter", Command: "restore", Context: "file-browser-history"},
+		{Key: "r", Command: "restore", Context: "file-browser-history"},
+		{Key: "space", Command: "mark-base", Context: "file-browser-history"},
+		{Key: "esc", Command: "close", Context: "file-browser-history"},
+		{Key: "j", Command: "cursor-down", Context: "file-browser-history"},
+		{Key: "k", Command: "cursor-up", Context: "file-browser-history"},
+
+		// File browser trash context
+		{Key: "enter", Command: "restore", Context: "file-browser-trash"},
+		{Key: "r", Command: "restore", Context: "file-browser-trash"},
+		{Key: "D", Command: "purge", Context: "file-browser-trash"},
+		{Key: "E", Command: "empty", Context: "file-browser-trash"},
+		{Key: "esc", Command: "close", Context: "file-browser-trash"},
+		{Key: "j", Command: "cursor-down", Context: "file-browser-trash"},
+		{Key: "k", Command: "cursor-up", Context: "file-browser-trash"},
+
 		// File browser project search context
 		{Key: "esc", Command: "cancel", Context: "file-browser-project-search"},
 		{Key: "enter", Command: "select", Context: "file-browser-project-search"},
@@ -327,6 +500,24 @@ func DefaultBindings() []Binding {
 		{Key: "ctrl+e", Command: "open-in-editor", Context: "file-browser-project-search"},
 		{Key: "ctrl+d", Command: "page-down", Context: "file-browser-project-search"},
 		{Key: "ctrl+u", Command: "page-up", Context: "file-browser-project-search"},
+		{Key: "ctrl+r", Command: "toggle-replace", Context: "file-browser-project-search"},
+
+		// File browser project replace context (project search with replace mode on)
+		{Key: "esc", Command: "cancel", Context: "file-browser-project-replace"},
+		{Key: "enter", Command: "select", Context: "file-browser-project-replace"},
+		{Key: "up", Command: "cursor-up", Context: "file-browser-project-replace"},
+		{Key: "down", Command: "cursor-down", Context: "file-browser-project-replace"},
+		{Key: "ctrl+n", Command: "cursor-down", Context: "file-browser-project-replace"},
+		{Key: "ctrl+p", Command: "cursor-up", Context: "file-browser-project-replace"},
+		{Key: "ctrl+r", Command: "toggle-replace", Context: "file-browser-project-replace"},
+		{Key: "ctrl+f", Command: "switch-field", Context: "file-browser-project-replace"},
+		{Key: "ctrl+x", Command: "exclude-match", Context: "file-browser-project-replace"},
+		{Key: "alt+x", Command: "exclude-file", Context: "file-browser-project-replace"},
+		{Key: "ctrl+s", Command: "apply-replace", Context: "file-browser-project-replace"},
+		{Key: "ctrl+z", Command: "undo-replace", Context: "file-browser-project-replace"},
+		{Key: "alt+r", Command: "toggle-regex", Context: "file-browser-project-replace"},
+		{Key: "alt+c", Command: "toggle-case", Context: "file-browser-project-replace"},
+		{Key: "alt+w", Command: "toggle-word", Context: "file-browser-project-replace"},
 
 		// File browser file operation context
 		{Key: "esc", Command: "cancel", Context: "file-browser-file-op"},
@@ -0,0 +1,454 @@
+// Package localhistory keeps snapshots of project files between commits,
+// like an IDE's local history. Snapshots are content-addressed under
+// .sidecar/history/objects, so identical versions are stored once, and an
+// append-only index.jsonl records which version of which file was seen
+// when. The store is size-capped: the oldest snapshots are dropped first,
+// but the newest snapshot of each file is always kept.
+package localhistory
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/marcus/sidecar/internal/config"
+)
+
+const (
+	// DefaultMaxSize is the total size of stored versions above which the
+	// oldest snapshots are dropped.
+	DefaultMaxSize int64 = 100 << 20
+	// MaxFileSize is the largest file that is snapshotted.
+	MaxFileSize int64 = 1 << 20
+	// MaxPerFile is how many snapshots are kept for one file.
+	MaxPerFile = 50
+
+	indexName = "index.jsonl"
+	// binarySniffLen is how much of a file is checked for NUL bytes.
+	binarySniffLen = 8000
+)
+
+// Snapshot is one recorded version of a file.
+type Snapshot struct {
+	Path string    `json:"path"` // Relative to the project root
+	Hash string    `json:"hash"` // SHA-256 of the content
+	Time time.Time `json:"time"`
+	Size int64     `json:"size"`
+}
+
+// fileStamp identifies an unchanged file without rereading it.
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// Store is the local history of one project. It is safe for concurrent use.
+type Store struct {
+	root    string
+	dir     string
+	maxSize int64
+
+	mu      sync.Mutex
+	loaded  bool
+	byPath  map[string][]Snapshot // Oldest first
+	objects map[string]int64      // Hash -> size of referenced objects
+	total   int64                 // Sum of objects
+	stamps  map[string]fileStamp  // Last snapshotted stat per path
+}
+
+var (
+	storesMu sync.Mutex
+	stores   = make(map[string]*Store)
+)
+
+// Open returns the history store for the project rooted at root. Callers
+// opening the same root share one store, so plugins snapshotting the same
+// project don't race on the index.
+func Open(root string) *Store {
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	storesMu.Lock()
+	defer storesMu.Unlock()
+	if s, ok := stores[root]; ok {
+		return s
+	}
+	s := &Store{
+		root:    root,
+		dir:     filepath.Join(root, ".sidecar", "history"),
+		maxSize: DefaultMaxSize,
+	}
+	stores[root] = s
+	return s
+}
+
+// OpenLimited returns the store for root capped at maxMB megabytes, or nil
+// when maxMB is negative, meaning local history is disabled. Zero uses
+// DefaultMaxSize.
+func OpenLimited(root string, maxMB int) *Store {
+	if maxMB < 0 {
+		return nil
+	}
+	s := Open(root)
+	s.SetMaxSize(int64(maxMB) << 20)
+	return s
+}
+
+// OpenFromConfig returns the store for root capped by the shared history
+// section of cfg (see OpenLimited). A nil cfg uses the default cap.
+func OpenFromConfig(root string, cfg *config.Config) *Store {
+	var maxMB int
+	if cfg != nil {
+		maxMB = cfg.History.MaxSizeMB
+	}
+	return OpenLimited(root, maxMB)
+}
+
+// SetMaxSize changes the size cap. Values <= 0 restore the default.
+func (s *Store) SetMaxSize(n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n <= 0 {
+		n = DefaultMaxSize
+	}
+	s.maxSize = n
+}
+
+// Root returns the project root.
+func (s *Store) Root() string { return s.root }
+
+// ObjectPath returns where a snapshot's content is stored.
+func (s *Store) ObjectPath(snap Snapshot) string {
+	return filepath.Join(s.dir, "objects", snap.Hash[:2], snap.Hash[2:])
+}
+
+// rel converts an absolute or root-relative path to a clean relative path,
+// rejecting paths outside the project and inside .sidecar.
+func (s *Store) rel(path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(s.root, path)
+	}
+	rel, err := filepath.Rel(s.root, filepath.Clean(path))
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the project", path)
+	}
+	if rel == ".sidecar" || strings.HasPrefix(rel, ".sidecar"+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is sidecar data", rel)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// Snapshot records the current content of path if it differs from the last
+// snapshot. Directories, large files and binary files are skipped. It
+// reports whether a new snapshot was added.
+func (s *Store) Snapshot(path string) (bool, error) {
+	rel, err := s.rel(path)
+	if err != nil {
+		return false, err
+	}
+	abs := filepath.Join(s.root, filepath.FromSlash(rel))
+	info, err := os.Stat(abs)
+	if err != nil {
+		return false, err
+	}
+	if !info.Mode().IsRegular() || info.Size() > MaxFileSize {
+		return false, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return false, err
+	}
+	stamp := fileStamp{size: info.Size(), modTime: info.ModTime()}
+	if s.stamps[rel] == stamp {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		return false, err
+	}
+	s.stamps[rel] = stamp
+	if bytes.IndexByte(data[:min(len(data), binarySniffLen)], 0) >= 0 {
+		return false, nil
+	}
+	return s.add(rel, data, time.Now())
+}
+
+// SnapshotAll snapshots each path, returning how many new snapshots were
+// added. Paths that vanished or can't be read are skipped.
+func (s *Store) SnapshotAll(paths []string) int {
+	n := 0
+	for _, p := range paths {
+		if added, err := s.Snapshot(p); err == nil && added {
+			n++
+		}
+	}
+	return n
+}
+
+// add stores data as the newest version of rel. Callers hold s.mu.
+func (s *Store) add(rel string, data []byte, now time.Time) (bool, error) {
+	sum := sha256.Sum256(data)
+	snap := Snapshot{Path: rel, Hash: hex.EncodeToString(sum[:]), Time: now, Size: int64(len(data))}
+	if snaps := s.byPath[rel]; len(snaps) > 0 && snaps[len(snaps)-1].Hash == snap.Hash {
+		return false, nil
+	}
+
+	if _, ok := s.objects[snap.Hash]; !ok {
+		if err := s.writeObject(snap, data); err != nil {
+			return false, err
+		}
+		s.objects[snap.Hash] = snap.Size
+		s.total += snap.Size
+	}
+	if err := s.appendIndex(snap); err != nil {
+		return false, err
+	}
+	s.byPath[rel] = append(s.byPath[rel], snap)
+
+	if len(s.byPath[rel]) > MaxPerFile || s.total > s.maxSize {
+		if err := s.prune(); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// writeObject stores content under its hash via a temp file, so a crash
+// never leaves a truncated object.
+func (s *Store) writeObject(snap Snapshot, data []byte) error {
+	path := s.ObjectPath(snap)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
+
+func (s *Store) appendIndex(snap Snapshot) error {
+	line, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(s.dir, indexName), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(line, '\n'))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// load reads the index once. Callers hold s.mu.
+func (s *Store) load() error {
+	if s.loaded {
+		return nil
+	}
+	s.byPath = make(map[string][]Snapshot)
+	s.objects = make(map[string]int64)
+	s.stamps = make(map[string]fileStamp)
+	s.total = 0
+
+	f, err := os.Open(filepath.Join(s.dir, indexName))
+	if errors.Is(err, fs.ErrNotExist) {
+		s.loaded = true
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var snap Snapshot
+		if json.Unmarshal(scanner.Bytes(), &snap) != nil || len(snap.Hash) != sha256.Size*2 || snap.Path == "" {
+			continue // Skip torn or foreign lines
+		}
+		if _, ok := s.objects[snap.Hash]; !ok {
+			if _, err := os.Stat(s.ObjectPath(snap)); err != nil {
+				continue
+			}
+			s.objects[snap.Hash] = snap.Size
+			s.total += snap.Size
+		}
+		s.byPath[snap.Path] = append(s.byPath[snap.Path], snap)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	for _, snaps := range s.byPath {
+		sort.SliceStable(snaps, func(i, j int) bool { return snaps[i].Time.Before(snaps[j].Time) })
+	}
+	s.loaded = true
+	return nil
+}
+
+// prune enforces the per-file and total size limits, removes unreferenced
+// objects and rewrites the index. Callers hold s.mu.
+func (s *Store) prune() error {
+	for rel, snaps := range s.byPath {
+		if len(snaps) > MaxPerFile {
+			s.byPath[rel] = snaps[len(snaps)-MaxPerFile:]
+		}
+	}
+	s.recount()
+
+	if s.total > s.maxSize {
+		// Drop the oldest snapshots across all files, keeping each file's newest
+		var candidates []Snapshot
+		for _, snaps := range s.byPath {
+			candidates = append(candidates, snaps[:len(snaps)-1]...)
+		}
+		sort.Slice(candidates, func(i, j int) bool { return candidates[i].Time.Before(candidates[j].Time) })
+		for _, c := range candidates {
+			if s.total <= s.maxSize {
+				break
+			}
+			s.removeSnapshot(c)
+			s.recount()
+		}
+	}
+
+	// Delete objects no longer referenced
+	_ = filepath.WalkDir(filepath.Join(s.dir, "objects"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		hash := filepath.Base(filepath.Dir(path)) + d.Name()
+		if _, ok := s.objects[hash]; !ok {
+			_ = os.Remove(path)
+		}
+		return nil
+	})
+	return s.writeIndex()
+}
+
+// removeSnapshot drops one snapshot from the in-memory index.
+func (s *Store) removeSnapshot(snap Snapshot) {
+	snaps := s.byPath[snap.Path]
+	for i, other := range snaps {
+		if other == snap {
+			s.byPath[snap.Path] = append(snaps[:i:i], snaps[i+1:]...)
+			return
+		}
+	}
+}
+
+// recount rebuilds the referenced object set from the index.
+func (s *Store) recount() {
+	s.objects = make(map[string]int64)
+	s.total = 0
+	for _, snaps := range s.byPath {
+		for _, snap := range snaps {
+			if _, ok := s.objects[snap.Hash]; !ok {
+				s.objects[snap.Hash] = snap.Size
+				s.total += snap.Size
+			}
+		}
+	}
+}
+
+// writeIndex replaces the index with the in-memory snapshots.
+func (s *Store) writeIndex() error {
+	var all []Snapshot
+	for _, snaps := range s.byPath {
+		all = append(all, snaps...)
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Time.Before(all[j].Time) })
+
+	var buf bytes.Buffer
+	for _, snap := range all {
+		line, err := json.Marshal(snap)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	tmp := filepath.Join(s.dir, indexName+".tmp")
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(s.dir, indexName))
+}
+
+// List returns the snapshots of path, newest first.
+func (s *Store) List(path string) ([]Snapshot, error) {
+	rel, err := s.rel(path)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	snaps := s.byPath[rel]
+	out := make([]Snapshot, len(snaps))
+	for i, snap := range snaps {
+		out[len(snaps)-1-i] = snap
+	}
+	return out, nil
+}
+
+// Read returns a snapshot's content.
+func (s *Store) Read(snap Snapshot) ([]byte, error) {
+	return os.ReadFile(s.ObjectPath(snap))
+}
+
+// Restore writes a snapshot's content back to its file. The current
+// content is snapshotted first so the restore itself can be undone.
+func (s *Store) Restore(snap Snapshot) error {
+	data, err := s.Read(snap)
+	if err != nil {
+		return err
+	}
+	abs := filepath.Join(s.root, filepath.FromSlash(snap.Path))
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(abs); err == nil {
+		mode = info.Mode().Perm()
+		if _, err := s.Snapshot(abs); err != nil {
+			return err
+		}
+	} else if err := os.MkdirAll(filepath.Dir(abs), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(abs, data, mode); err != nil {
+		return err
+	}
+	// Record the restored content so the timeline shows it as current
+	_, err = s.Snapshot(abs)
+	return err
+}
@@ -0,0 +1,143 @@
+package localhistory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newStore returns an unshared store so tests don't see each other's state.
+func newStore(t *testing.T) (*Store, string) {
+	t.Helper()
+	root := t.TempDir()
+	return &Store{root: root, dir: filepath.Join(root, ".sidecar", "history"), maxSize: DefaultMaxSize}, root
+}
+
+// write writes content and bumps the mtime so the stat check sees a change.
+func write(t *testing.T, path, content string, n int) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ts := time.Now().Add(time.Duration(n) * time.Second)
+	if err := os.Chtimes(path, ts, ts); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSnapshotDedupAndList(t *testing.T) {
+	s, root := newStore(t)
+	file := filepath.Join(root, "a.txt")
+
+	write(t, file, "one\n", 1)
+	if added, err := s.Snapshot(file); err != nil || !added {
+		t.Fatalf("first snapshot: added=%v err=%v", added, err)
+	}
+	if added, _ := s.Snapshot(file); added {
+		t.Error("unchanged file should not be snapshotted again")
+	}
+	write(t, file, "one\n", 2)
+	if added, _ := s.Snapshot(file); added {
+		t.Error("same content with a new mtime should dedupe")
+	}
+	write(t, file, "two\n", 3)
+	if added, _ := s.Snapshot("a.txt"); !added {
+		t.Error("changed content should be snapshotted")
+	}
+
+	// Identical content in another file shares the object
+	other := filepath.Join(root, "b.txt")
+	write(t, other, "two\n", 4)
+	if _, err := s.Snapshot(other); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.objects) != 2 {
+		t.Errorf("objects = %d, want 2", len(s.objects))
+	}
+
+	snaps, err := s.List(file)
+	if err != nil || len(snaps) != 2 {
+		t.Fatalf("List = %v, %v", snaps, err)
+	}
+	if data, _ := s.Read(snaps[0]); string(data) != "two\n" {
+		t.Errorf("newest snapshot = %q", data)
+	}
+
+	// A fresh store reads the same history from the index
+	reopened := &Store{root: root, dir: s.dir, maxSize: DefaultMaxSize}
+	if snaps, _ := reopened.List("a.txt"); len(snaps) != 2 {
+		t.Errorf("reopened List = %v", snaps)
+	}
+}
+
+func TestSnapshotSkips(t *testing.T) {
+	s, root := newStore(t)
+	bin := filepath.Join(root, "blob.bin")
+	if err := os.WriteFile(bin, []byte{'a', 0, 'b'}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if added, _ := s.Snapshot(bin); added {
+		t.Error("binary files should be skipped")
+	}
+	if _, err := s.Snapshot(filepath.Join(root, ".sidecar", "x")); err == nil {
+		t.Error(".sidecar paths should be rejected")
+	}
+	if _, err := s.Snapshot(filepath.Join(filepath.Dir(root), "outside")); err == nil {
+		t.Error("paths outside the project should be rejected")
+	}
+}
+
+func TestRestoreSnapshotsCurrentContent(t *testing.T) {
+	s, root := newStore(t)
+	file := filepath.Join(root, "a.txt")
+	write(t, file, "original\n", 1)
+	_, _ = s.Snapshot(file)
+	write(t, file, "agent rewrite\n", 2)
+
+	snaps, _ := s.List(file)
+	if err := s.Restore(snaps[0]); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(file); string(data) != "original\n" {
+		t.Errorf("restored content = %q", data)
+	}
+	snaps, _ = s.List(file)
+	if len(snaps) != 3 {
+		t.Fatalf("restore should record the overwritten and restored versions, got %d", len(snaps))
+	}
+	if data, _ := s.Read(snaps[1]); string(data) != "agent rewrite\n" {
+		t.Errorf("overwritten version = %q", data)
+	}
+}
+
+func TestPruneLimits(t *testing.T) {
+	s, root := newStore(t)
+	file := filepath.Join(root, "a.txt")
+	for i := 0; i < MaxPerFile+5; i++ {
+		write(t, file, string(rune('a'+i%26))+time.Duration(i).String(), i)
+		if _, err := s.Snapshot(file); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if snaps, _ := s.List(file); len(snaps) != MaxPerFile {
+		t.Errorf("kept %d snapshots, want %d", len(snaps), MaxPerFile)
+	}
+	entries, _ := filepath.Glob(filepath.Join(s.dir, "objects", "*", "*"))
+	if len(entries) != MaxPerFile {
+		t.Errorf("objects on disk = %d, want %d", len(entries), MaxPerFile)
+	}
+
+	// The size cap drops old snapshots but keeps every file's newest
+	s.SetMaxSize(10)
+	other := filepath.Join(root, "b.txt")
+	write(t, other, "0123456789", 100)
+	if _, err := s.Snapshot(other); err != nil {
+		t.Fatal(err)
+	}
+	a, _ := s.List(file)
+	b, _ := s.List(other)
+	if len(a) != 1 || len(b) != 1 {
+		t.Errorf("after size prune: a=%d b=%d", len(a), len(b))
+	}
+}
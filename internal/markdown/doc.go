@@ -1,4 +1,5 @@
 // Package markdown wraps Glamour for cached markdown-to-ANSI rendering with
 // width-based revalidation, falling back to plain text wrapping for narrow
-// terminals.
+// terminals. It also strips the code fences chat-style tools wrap their
+// output in.
 package markdown
@@ -0,0 +1,18 @@
+package markdown
+
+import "strings"
+
+// StripCodeFence trims surrounding whitespace and removes a markdown code
+// fence wrapping the whole text, as chat-style tools tend to add around
+// their answers.
+func StripCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") {
+		return text
+	}
+	lines := strings.Split(text, "\n")[1:]
+	if n := len(lines); n > 0 && strings.TrimSpace(lines[n-1]) == "```" {
+		lines = lines[:n-1]
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
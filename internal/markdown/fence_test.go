@@ -0,0 +1,22 @@
+package markdown
+
+import "testing"
+
+func TestStripCodeFence(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"  plain text\n\n", "plain text"},
+		{"```\nfeat: add\n\nbody\n```", "feat: add\n\nbody"},
+		{"```text\nsummary\n```\n", "summary"},
+		{"```\nunclosed", "unclosed"},
+		{"text with ``` inside", "text with ``` inside"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := StripCodeFence(tt.in); got != tt.want {
+			t.Errorf("StripCodeFence(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -22,3 +22,39 @@ func ShowToast(message string, duration time.Duration) tea.Cmd {
 		}
 	}
 }
+
+// NavigateToFileMsg asks the file browser to reveal and preview a file.
+type NavigateToFileMsg struct {
+	Path string // Relative path from workdir
+}
+
+// ShowFileDiffMsg asks git status to open the working tree diff of a file.
+type ShowFileDiffMsg struct {
+	Path string // Relative path from workdir
+}
+
+// FilesChangedMsg is broadcast after a plugin rewrites files on disk so
+// other plugins can refresh.
+type FilesChangedMsg struct {
+	Paths []string // Paths relative to the project root
+}
+
+// RunInShellMsg asks the workspace plugin to open a new shell and run a
+// command in it, so long-running output can be followed there.
+type RunInShellMsg struct {
+	Command string
+}
+
+// Budget levels reported in BudgetStatusMsg.
+const (
+	BudgetOK = iota
+	BudgetWarning
+	BudgetExceeded
+)
+
+// BudgetStatusMsg updates the budget indicator in the header. An empty
+// Text hides it.
+type BudgetStatusMsg struct {
+	Text  string // e.g. "$12.40/$20 day"
+	Level int    // BudgetOK, BudgetWarning or BudgetExceeded
+}
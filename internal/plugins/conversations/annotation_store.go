@@ -0,0 +1,273 @@
+package conversations
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Bookmark marks a message with an optional note.
+type Bookmark struct {
+	MessageID string    `json:"messageId"`
+	Note      string    `json:"note,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// SessionAnnotations holds the user's annotations on one session.
+type SessionAnnotations struct {
+	Starred   bool       `json:"starred,omitempty"`
+	Tags      []string   `json:"tags,omitempty"`
+	Bookmarks []Bookmark `json:"bookmarks,omitempty"`
+}
+
+// IsEmpty returns true if the session has no annotations.
+func (a *SessionAnnotations) IsEmpty() bool {
+	return a == nil || (!a.Starred && len(a.Tags) == 0 && len(a.Bookmarks) == 0)
+}
+
+// starred and tags are nil-safe accessors for sessions without annotations.
+func (a *SessionAnnotations) starred() bool { return a != nil && a.Starred }
+
+func (a *SessionAnnotations) tags() []string {
+	if a == nil {
+		return nil
+	}
+	return a.Tags
+}
+
+// clone returns a deep copy, safe to hand to a tea.Cmd while the plugin
+// keeps editing the original.
+func (a *SessionAnnotations) clone() *SessionAnnotations {
+	if a == nil {
+		return nil
+	}
+	c := *a
+	c.Tags = slices.Clone(a.Tags)
+	c.Bookmarks = slices.Clone(a.Bookmarks)
+	return &c
+}
+
+// HasTag returns true if the session is tagged with tag.
+func (a *SessionAnnotations) HasTag(tag string) bool {
+	return a != nil && slices.Contains(a.Tags, tag)
+}
+
+// Bookmark returns the bookmark on a message, or nil.
+func (a *SessionAnnotations) Bookmark(messageID string) *Bookmark {
+	if a == nil {
+		return nil
+	}
+	for i := range a.Bookmarks {
+		if a.Bookmarks[i].MessageID == messageID {
+			return &a.Bookmarks[i]
+		}
+	}
+	return nil
+}
+
+// annotationKey identifies an annotated session. Session IDs are only
+// unique per adapter.
+type annotationKey struct {
+	AdapterID string
+	SessionID string
+}
+
+// AnnotationStore keeps session annotations in a SQLite database owned by
+// sidecar, so they survive changes to the agents' session files.
+type AnnotationStore struct {
+	db *sql.DB
+}
+
+// DefaultAnnotationsPath returns the annotations database path,
+// ~/.config/sidecar/annotations.db.
+func DefaultAnnotationsPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "sidecar", "annotations.db"), nil
+}
+
+// OpenAnnotationStore opens or creates the annotations database at path.
+func OpenAnnotationStore(path string) (*AnnotationStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("create annotations dir: %w", err)
+	}
+	db, err := sql.Open("sqlite3", path+"?_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	s := &AnnotationStore{db: db}
+	if err := s.initSchema(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("init schema: %w", err)
+	}
+	return s, nil
+}
+
+// Close closes the database connection.
+func (s *AnnotationStore) Close() error {
+	if s.db != nil {
+		return s.db.Close()
+	}
+	return nil
+}
+
+// initSchema creates the annotation tables if they don't exist.
+func (s *AnnotationStore) initSchema() error {
+	schema := `
+CREATE TABLE IF NOT EXISTS session_stars (
+    adapter_id TEXT NOT NULL,
+    session_id TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    PRIMARY KEY (adapter_id, session_id)
+);
+CREATE TABLE IF NOT EXISTS session_tags (
+    adapter_id TEXT NOT NULL,
+    session_id TEXT NOT NULL,
+    tag TEXT NOT NULL,
+    PRIMARY KEY (adapter_id, session_id, tag)
+);
+CREATE TABLE IF NOT EXISTS message_bookmarks (
+    adapter_id TEXT NOT NULL,
+    session_id TEXT NOT NULL,
+    message_id TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL,
+    PRIMARY KEY (adapter_id, session_id, message_id)
+);
+CREATE INDEX IF NOT EXISTS idx_session_tags_tag ON session_tags(tag);
+`
+	_, err := s.db.Exec(schema)
+	return err
+}
+
+// All loads every session's annotations.
+func (s *AnnotationStore) All() (map[annotationKey]*SessionAnnotations, error) {
+	all := make(map[annotationKey]*SessionAnnotations)
+	get := func(k annotationKey) *SessionAnnotations {
+		a := all[k]
+		if a == nil {
+			a = &SessionAnnotations{}
+			all[k] = a
+		}
+		return a
+	}
+
+	rows, err := s.db.Query(`SELECT adapter_id, session_id FROM session_stars`)
+	if err != nil {
+		return nil, fmt.Errorf("query stars: %w", err)
+	}
+	for rows.Next() {
+		var k annotationKey
+		if err := rows.Scan(&k.AdapterID, &k.SessionID); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		get(k).Starred = true
+	}
+	_ = rows.Close()
+
+	rows, err = s.db.Query(`SELECT adapter_id, session_id, tag FROM session_tags ORDER BY tag`)
+	if err != nil {
+		return nil, fmt.Errorf("query tags: %w", err)
+	}
+	for rows.Next() {
+		var k annotationKey
+		var tag string
+		if err := rows.Scan(&k.AdapterID, &k.SessionID, &tag); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		a := get(k)
+		a.Tags = append(a.Tags, tag)
+	}
+	_ = rows.Close()
+
+	rows, err = s.db.Query(`SELECT adapter_id, session_id, message_id, note, created_at FROM message_bookmarks ORDER BY created_at`)
+	if err != nil {
+		return nil, fmt.Errorf("query bookmarks: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var k annotationKey
+		var b Bookmark
+		var created string
+		if err := rows.Scan(&k.AdapterID, &k.SessionID, &b.MessageID, &b.Note, &created); err != nil {
+			return nil, err
+		}
+		b.CreatedAt, _ = time.Parse(time.RFC3339, created)
+		a := get(k)
+		a.Bookmarks = append(a.Bookmarks, b)
+	}
+	return all, rows.Err()
+}
+
+// SetStarred stars or unstars a session.
+func (s *AnnotationStore) SetStarred(k annotationKey, starred bool) error {
+	var err error
+	if starred {
+		_, err = s.db.Exec(`INSERT OR IGNORE INTO session_stars (adapter_id, session_id, created_at) VALUES (?, ?, ?)`,
+			k.AdapterID, k.SessionID, time.Now().UTC().Format(time.RFC3339))
+	} else {
+		_, err = s.db.Exec(`DELETE FROM session_stars WHERE adapter_id = ? AND session_id = ?`, k.AdapterID, k.SessionID)
+	}
+	return err
+}
+
+// SetTags replaces a session's tags.
+func (s *AnnotationStore) SetTags(k annotationKey, tags []string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`DELETE FROM session_tags WHERE adapter_id = ? AND session_id = ?`, k.AdapterID, k.SessionID); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO session_tags (adapter_id, session_id, tag) VALUES (?, ?, ?)`,
+			k.AdapterID, k.SessionID, tag); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// SetBookmark adds or updates the bookmark on a message.
+func (s *AnnotationStore) SetBookmark(k annotationKey, b Bookmark) error {
+	_, err := s.db.Exec(`
+		INSERT INTO message_bookmarks (adapter_id, session_id, message_id, note, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (adapter_id, session_id, message_id) DO UPDATE SET note = excluded.note
+	`, k.AdapterID, k.SessionID, b.MessageID, b.Note, b.CreatedAt.UTC().Format(time.RFC3339))
+	return err
+}
+
+// RemoveBookmark removes the bookmark on a message.
+func (s *AnnotationStore) RemoveBookmark(k annotationKey, messageID string) error {
+	_, err := s.db.Exec(`DELETE FROM message_bookmarks WHERE adapter_id = ? AND session_id = ? AND message_id = ?`,
+		k.AdapterID, k.SessionID, messageID)
+	return err
+}
+
+// ParseTags splits comma- or space-separated tags, normalizing them to
+// lowercase without a leading '#' and dropping duplicates.
+func ParseTags(input string) []string {
+	var tags []string
+	for _, f := range strings.FieldsFunc(input, func(r rune) bool { return r == ',' || r == ' ' }) {
+		tag := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(f), "#"))
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	slices.Sort(tags)
+	return tags
+}
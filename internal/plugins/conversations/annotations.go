@@ -0,0 +1,384 @@
+package conversations
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/app"
+	"github.com/marcus/sidecar/internal/modal"
+	"github.com/marcus/sidecar/internal/ui"
+)
+
+// Annotate modal field IDs
+const (
+	annotateInputID  = "annotate-input"
+	annotateSaveID   = "annotate-save"
+	annotateRemoveID = "annotate-remove"
+	annotateCancelID = "annotate-cancel"
+)
+
+// Annotate modal kinds
+const (
+	annotateTags     = 0 // edit a session's tags
+	annotateBookmark = 1 // bookmark a message with a note
+)
+
+// initAnnotations opens the annotations database once and loads it. A
+// missing database leaves annotations disabled rather than failing Init.
+func (p *Plugin) initAnnotations() {
+	if p.annotationStore == nil {
+		if p.annotationsPath == "" {
+			return
+		}
+		store, err := OpenAnnotationStore(p.annotationsPath)
+		if err != nil {
+			if p.ctx != nil && p.ctx.Logger != nil {
+				p.ctx.Logger.Warn("conversations: annotations unavailable", "error", err)
+			}
+			return
+		}
+		p.annotationStore = store
+	}
+	p.loadAnnotations()
+}
+
+// loadAnnotations reloads all annotations from the store.
+func (p *Plugin) loadAnnotations() {
+	if p.annotationStore == nil {
+		return
+	}
+	all, err := p.annotationStore.All()
+	if err != nil {
+		if p.ctx != nil && p.ctx.Logger != nil {
+			p.ctx.Logger.Warn("conversations: load annotations failed", "error", err)
+		}
+		return
+	}
+	p.annotations = all
+}
+
+// annotationKeyFor returns the key a session's annotations are stored
+// under. Archived and imported sessions are served by other adapters than
+// the one they were annotated under, so they reuse any annotations made
+// under their original adapter.
+func (p *Plugin) annotationKeyFor(s adapter.Session) annotationKey {
+	k := annotationKey{AdapterID: s.AdapterID, SessionID: s.ID}
+	if _, ok := p.annotations[k]; ok || (!s.Archived && !s.Imported) {
+		return k
+	}
+	for other := range p.annotations {
+		if other.SessionID == s.ID {
+			return other
+		}
+	}
+	return k
+}
+
+// annotationsFor returns a session's annotations, or nil.
+func (p *Plugin) annotationsFor(s adapter.Session) *SessionAnnotations {
+	if len(p.annotations) == 0 {
+		return nil
+	}
+	return p.annotations[p.annotationKeyFor(s)]
+}
+
+// selectedAnnotations returns the selected session's annotations, or nil.
+func (p *Plugin) selectedAnnotations() *SessionAnnotations {
+	if s := p.findSelectedSession(); s != nil {
+		return p.annotationsFor(*s)
+	}
+	return nil
+}
+
+// editAnnotations applies edit to a session's annotations in memory and
+// persists the change with save.
+func (p *Plugin) editAnnotations(s adapter.Session, edit func(*SessionAnnotations), save func(*AnnotationStore, annotationKey) error) tea.Cmd {
+	if p.annotationStore == nil {
+		return func() tea.Msg {
+			return app.ToastMsg{Message: "Annotations unavailable", Duration: 2 * time.Second, IsError: true}
+		}
+	}
+	if p.annotations == nil {
+		p.annotations = make(map[annotationKey]*SessionAnnotations)
+	}
+	k := p.annotationKeyFor(s)
+	a := p.annotations[k]
+	if a == nil {
+		a = &SessionAnnotations{}
+	}
+	edit(a)
+	if a.IsEmpty() {
+		delete(p.annotations, k)
+	} else {
+		p.annotations[k] = a
+	}
+	p.hitRegionsDirty = true
+
+	store := p.annotationStore
+	return func() tea.Msg {
+		if err := save(store, k); err != nil {
+			return app.ToastMsg{Message: "Save annotation failed: " + err.Error(), Duration: 3 * time.Second, IsError: true}
+		}
+		return nil
+	}
+}
+
+// toggleStar stars or unstars the selected session.
+func (p *Plugin) toggleStar() tea.Cmd {
+	s := p.findSelectedSession()
+	if s == nil {
+		return nil
+	}
+	starred := !p.annotationsFor(*s).starred()
+	return p.editAnnotations(*s,
+		func(a *SessionAnnotations) { a.Starred = starred },
+		func(store *AnnotationStore, k annotationKey) error { return store.SetStarred(k, starred) },
+	)
+}
+
+// setTags replaces the selected session's tags.
+func (p *Plugin) setTags(s adapter.Session, tags []string) tea.Cmd {
+	return p.editAnnotations(s,
+		func(a *SessionAnnotations) { a.Tags = tags },
+		func(store *AnnotationStore, k annotationKey) error { return store.SetTags(k, tags) },
+	)
+}
+
+// setBookmark bookmarks a message, keeping the original bookmark time when
+// only the note changes.
+func (p *Plugin) setBookmark(s adapter.Session, messageID, note string) tea.Cmd {
+	b := Bookmark{MessageID: messageID, Note: note, CreatedAt: time.Now()}
+	return p.editAnnotations(s,
+		func(a *SessionAnnotations) {
+			if existing := a.Bookmark(messageID); existing != nil {
+				existing.Note = note
+				b.CreatedAt = existing.CreatedAt
+				return
+			}
+			a.Bookmarks = append(a.Bookmarks, b)
+		},
+		func(store *AnnotationStore, k annotationKey) error { return store.SetBookmark(k, b) },
+	)
+}
+
+// removeBookmark removes the bookmark on a message.
+func (p *Plugin) removeBookmark(s adapter.Session, messageID string) tea.Cmd {
+	return p.editAnnotations(s,
+		func(a *SessionAnnotations) {
+			for i := range a.Bookmarks {
+				if a.Bookmarks[i].MessageID == messageID {
+					a.Bookmarks = append(a.Bookmarks[:i], a.Bookmarks[i+1:]...)
+					return
+				}
+			}
+		},
+		func(store *AnnotationStore, k annotationKey) error { return store.RemoveBookmark(k, messageID) },
+	)
+}
+
+// allTags returns every tag in use, sorted.
+func (p *Plugin) allTags() []string {
+	var tags []string
+	for _, a := range p.annotations {
+		tags = append(tags, a.Tags...)
+	}
+	return ParseTags(strings.Join(tags, ","))
+}
+
+// openTagEditor opens the annotate modal on the selected session's tags.
+func (p *Plugin) openTagEditor() tea.Cmd {
+	s := p.findSelectedSession()
+	if s == nil {
+		return nil
+	}
+	p.openAnnotateModal(annotateTags, *s, "", strings.Join(p.annotationsFor(*s).tags(), ", "))
+	return nil
+}
+
+// openBookmarkEditor opens the annotate modal on the selected message's
+// bookmark note.
+func (p *Plugin) openBookmarkEditor() tea.Cmd {
+	s := p.findSelectedSession()
+	msg := p.getSelectedMessage()
+	if p.turnViewMode {
+		// A turn is bookmarked through its first message
+		msg = nil
+		if p.turnCursor >= 0 && p.turnCursor < len(p.turns) && len(p.turns[p.turnCursor].Messages) > 0 {
+			msg = &p.turns[p.turnCursor].Messages[0]
+		}
+	}
+	if s == nil || msg == nil {
+		return nil
+	}
+	note := ""
+	if b := p.annotationsFor(*s).Bookmark(msg.ID); b != nil {
+		note = b.Note
+	}
+	p.openAnnotateModal(annotateBookmark, *s, msg.ID, note)
+	return nil
+}
+
+// jumpToBookmark moves the cursor to the next (dir > 0) or previous
+// bookmarked message or turn, wrapping around.
+func (p *Plugin) jumpToBookmark(dir int) {
+	ann := p.selectedAnnotations()
+	if ann == nil || len(ann.Bookmarks) == 0 {
+		return
+	}
+	if p.turnViewMode {
+		n := len(p.turns)
+		for step := 1; step <= n; step++ {
+			i := ((p.turnCursor+dir*step)%n + n) % n
+			for _, m := range p.turns[i].Messages {
+				if ann.Bookmark(m.ID) != nil {
+					p.turnCursor = i
+					p.ensureTurnCursorVisible()
+					return
+				}
+			}
+		}
+		return
+	}
+	indices := p.visibleMessageIndices()
+	n := len(indices)
+	cur := max(0, slices.Index(indices, p.messageCursor))
+	for step := 1; step <= n; step++ {
+		idx := indices[((cur+dir*step)%n+n)%n]
+		if ann.Bookmark(p.messages[idx].ID) != nil {
+			p.messageCursor = idx
+			p.ensureMessageCursorVisible()
+			return
+		}
+	}
+}
+
+// openAnnotateModal opens the annotate modal with its input set to value.
+func (p *Plugin) openAnnotateModal(kind int, s adapter.Session, messageID, value string) {
+	p.annotateKind = kind
+	p.annotateSession = s
+	p.annotateMessageID = messageID
+	p.annotateInput = textinput.New()
+	p.annotateInput.CharLimit = 200
+	p.annotateInput.SetValue(value)
+	p.annotateInput.Focus()
+	if kind == annotateTags {
+		p.annotateInput.Placeholder = "incident, refactor"
+	} else {
+		p.annotateInput.Placeholder = "optional note"
+	}
+	p.annotateModal = nil
+	p.annotateModalWidth = 0
+	p.showAnnotateModal = true
+}
+
+// resetAnnotateModal closes the annotate modal.
+func (p *Plugin) resetAnnotateModal() {
+	p.showAnnotateModal = false
+	p.annotateModal = nil
+	p.annotateSession = adapter.Session{}
+	p.annotateMessageID = ""
+}
+
+// annotateHasExisting reports whether the modal edits an existing
+// bookmark, which can then be removed.
+func (p *Plugin) annotateHasExisting() bool {
+	return p.annotateKind == annotateBookmark && p.annotationsFor(p.annotateSession).Bookmark(p.annotateMessageID) != nil
+}
+
+// ensureAnnotateModal builds or caches the annotate modal.
+func (p *Plugin) ensureAnnotateModal() {
+	modalW := min(50, max(20, p.width-4))
+	if p.annotateModal != nil && p.annotateModalWidth == modalW {
+		return
+	}
+	p.annotateModalWidth = modalW
+
+	title, label := "Tag Session", "Tags (comma separated):"
+	if p.annotateKind == annotateBookmark {
+		title, label = "Bookmark Message", "Note:"
+	}
+	buttons := []modal.ButtonDef{modal.Btn(" Save ", annotateSaveID)}
+	if p.annotateHasExisting() {
+		buttons = append(buttons, modal.Btn(" Remove ", annotateRemoveID, modal.BtnDanger()))
+	}
+	buttons = append(buttons, modal.Btn(" Cancel ", annotateCancelID))
+
+	m := modal.New(title,
+		modal.WithWidth(modalW),
+		modal.WithPrimaryAction(annotateSaveID),
+		modal.WithHints(false),
+	)
+	if p.annotateKind == annotateTags {
+		if tags := p.allTags(); len(tags) > 0 {
+			m.AddSection(modal.Text("In use: " + strings.Join(tags, ", "))).
+				AddSection(modal.Spacer())
+		}
+	}
+	p.annotateModal = m.
+		AddSection(modal.Text(label)).
+		AddSection(modal.Input(annotateInputID, &p.annotateInput)).
+		AddSection(modal.Spacer()).
+		AddSection(modal.Buttons(buttons...))
+	p.annotateModal.SetFocus(annotateInputID)
+}
+
+// applyAnnotateAction handles a modal action.
+func (p *Plugin) applyAnnotateAction(action string) tea.Cmd {
+	kind, s, messageID := p.annotateKind, p.annotateSession, p.annotateMessageID
+	value := strings.TrimSpace(p.annotateInput.Value())
+	switch action {
+	case annotateSaveID:
+		p.resetAnnotateModal()
+		if kind == annotateTags {
+			return p.setTags(s, ParseTags(value))
+		}
+		return p.setBookmark(s, messageID, value)
+	case annotateRemoveID:
+		p.resetAnnotateModal()
+		return p.removeBookmark(s, messageID)
+	case annotateCancelID, "cancel":
+		p.resetAnnotateModal()
+	}
+	return nil
+}
+
+// handleAnnotateModalKeys handles keyboard input for the annotate modal.
+func (p *Plugin) handleAnnotateModalKeys(msg tea.KeyMsg) tea.Cmd {
+	p.ensureAnnotateModal()
+	action, cmd := p.annotateModal.HandleKey(msg)
+	if action != "" {
+		return p.applyAnnotateAction(action)
+	}
+	return cmd
+}
+
+// handleAnnotateModalMouse handles mouse input for the annotate modal.
+func (p *Plugin) handleAnnotateModalMouse(msg tea.MouseMsg) tea.Cmd {
+	p.ensureAnnotateModal()
+	return p.applyAnnotateAction(p.annotateModal.HandleMouse(msg, p.mouseHandler))
+}
+
+// renderAnnotateModal renders the annotate modal over the background.
+func (p *Plugin) renderAnnotateModal(width, height int) string {
+	p.ensureAnnotateModal()
+	background := p.renderTwoPane()
+	rendered := p.annotateModal.Render(width, height, p.mouseHandler)
+	return ui.OverlayModal(background, rendered, width, height)
+}
+
+// renderAnnotationBadge returns the star and tags shown after a session's
+// title, e.g. "★ #incident #refactor".
+func renderAnnotationBadge(a *SessionAnnotations) string {
+	var parts []string
+	if a.starred() {
+		parts = append(parts, "★")
+	}
+	for _, tag := range a.tags() {
+		parts = append(parts, "#"+tag)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,314 @@
+package conversations
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/adapter/imported"
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+func openTestAnnotationStore(t *testing.T) *AnnotationStore {
+	t.Helper()
+	store, err := OpenAnnotationStore(filepath.Join(t.TempDir(), "annotations.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func TestAnnotationStore(t *testing.T) {
+	store := openTestAnnotationStore(t)
+	k := annotationKey{AdapterID: "claude-code", SessionID: "s1"}
+	other := annotationKey{AdapterID: "codex", SessionID: "s1"}
+
+	if err := store.SetStarred(k, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetTags(k, []string{"incident", "refactor"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetTags(other, []string{"good-prompt"}); err != nil {
+		t.Fatal(err)
+	}
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := store.SetBookmark(k, Bookmark{MessageID: "m1", Note: "first", CreatedAt: created}); err != nil {
+		t.Fatal(err)
+	}
+	// Updating a bookmark keeps its creation time
+	if err := store.SetBookmark(k, Bookmark{MessageID: "m1", Note: "edited", CreatedAt: created.Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetBookmark(k, Bookmark{MessageID: "m2", CreatedAt: created}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.RemoveBookmark(k, "m2"); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := store.All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := all[k]
+	if a == nil || !a.Starred || strings.Join(a.Tags, ",") != "incident,refactor" {
+		t.Fatalf("annotations = %+v", a)
+	}
+	if len(a.Bookmarks) != 1 || a.Bookmarks[0].Note != "edited" || !a.Bookmarks[0].CreatedAt.Equal(created) {
+		t.Errorf("bookmarks = %+v", a.Bookmarks)
+	}
+	if o := all[other]; o == nil || o.Starred || !o.HasTag("good-prompt") {
+		t.Errorf("other adapter's session = %+v", o)
+	}
+
+	// Unstarring and clearing tags removes the rows
+	if err := store.SetStarred(k, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetTags(k, nil); err != nil {
+		t.Fatal(err)
+	}
+	all, _ = store.All()
+	if a := all[k]; a.Starred || len(a.Tags) != 0 {
+		t.Errorf("after clearing = %+v", a)
+	}
+}
+
+func TestInitAnnotations(t *testing.T) {
+	// Without a database path annotations stay disabled
+	p := NewWithAnnotationsPath("")
+	if err := p.Init(&plugin.Context{WorkDir: "/proj"}); err != nil {
+		t.Fatal(err)
+	}
+	if p.annotationStore != nil {
+		t.Fatal("store opened without a path")
+	}
+
+	path := filepath.Join(t.TempDir(), "annotations.db")
+	p = NewWithAnnotationsPath(path)
+	if err := p.Init(&plugin.Context{WorkDir: "/proj"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(p.Stop)
+	if p.annotationStore == nil {
+		t.Fatal("store not opened")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	got := ParseTags(" #Refactor, incident  refactor,,good-prompt ")
+	if strings.Join(got, ",") != "good-prompt,incident,refactor" {
+		t.Errorf("ParseTags = %v", got)
+	}
+}
+
+func TestSearchFiltersMatchesAnnotations(t *testing.T) {
+	ann := &SessionAnnotations{Starred: true, Tags: []string{"incident", "refactor"}}
+	tests := []struct {
+		name    string
+		filters SearchFilters
+		ann     *SessionAnnotations
+		want    bool
+	}{
+		{"no filters", SearchFilters{}, nil, true},
+		{"starred", SearchFilters{Starred: true}, ann, true},
+		{"starred, none", SearchFilters{Starred: true}, nil, false},
+		{"all tags", SearchFilters{Tags: []string{"incident", "refactor"}}, ann, true},
+		{"missing tag", SearchFilters{Tags: []string{"incident", "good-prompt"}}, ann, false},
+	}
+	for _, tt := range tests {
+		if got := tt.filters.MatchesAnnotations(tt.ann); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if f := (SearchFilters{Tags: []string{"incident"}, Starred: true}); !f.IsActive() || f.String() != "[starred] [tag:incident]" {
+		t.Errorf("IsActive = %v, String = %q", f.IsActive(), f.String())
+	}
+}
+
+func newAnnotatedPlugin(t *testing.T) *Plugin {
+	t.Helper()
+	p := New()
+	p.ctx = &plugin.Context{WorkDir: "/proj"}
+	p.annotationStore = openTestAnnotationStore(t)
+	now := time.Now()
+	p.sessions = []adapter.Session{
+		{ID: "s1", Name: "Outage", AdapterID: "claude-code", UpdatedAt: now},
+		{ID: "s2", Name: "Cleanup", AdapterID: "claude-code", UpdatedAt: now.Add(-time.Minute)},
+	}
+	p.setSelectedSession("s1")
+	return p
+}
+
+func TestAnnotationsPluginFlow(t *testing.T) {
+	p := newAnnotatedPlugin(t)
+
+	cmd := p.toggleStar()
+	if cmd == nil {
+		t.Fatal("expected a save command")
+	}
+	if msg := cmd(); msg != nil {
+		t.Fatalf("save failed: %+v", msg)
+	}
+
+	// Tags are edited through the modal
+	p.openTagEditor()
+	if !p.showAnnotateModal || p.annotateKind != annotateTags {
+		t.Fatal("tag modal not open")
+	}
+	p.annotateInput.SetValue("Incident, #refactor")
+	if msg := p.applyAnnotateAction(annotateSaveID)(); msg != nil {
+		t.Fatalf("save failed: %+v", msg)
+	}
+	if p.showAnnotateModal {
+		t.Error("modal should close on save")
+	}
+
+	// Reloading from the store gives the same annotations
+	p.annotations = nil
+	p.loadAnnotations()
+	ann := p.selectedAnnotations()
+	if !ann.starred() || strings.Join(ann.tags(), ",") != "incident,refactor" {
+		t.Fatalf("annotations = %+v", ann)
+	}
+
+	// Tag and starred filters hide the untagged session
+	p.filters = SearchFilters{Tags: []string{"incident"}}
+	p.filterActive = true
+	if got := p.visibleSessions(); len(got) != 1 || got[0].ID != "s1" {
+		t.Errorf("filtered = %v", sessionIDs(got))
+	}
+	p.filters = SearchFilters{}
+	p.filterActive = false
+
+	// "#tag" searches tags
+	p.searchMode = true
+	p.searchQuery = "#inc"
+	p.filterSessions()
+	if len(p.searchResults) != 1 || p.searchResults[0].ID != "s1" {
+		t.Errorf("tag search = %v", sessionIDs(p.searchResults))
+	}
+	p.searchMode = false
+}
+
+func TestBookmarks(t *testing.T) {
+	p := newAnnotatedPlugin(t)
+	p.messages = []adapter.Message{
+		{ID: "m1", Role: "user", Content: "one"},
+		{ID: "m2", Role: "assistant", Content: "two"},
+		{ID: "m3", Role: "user", Content: "three"},
+	}
+	p.messageCursor = 1
+
+	p.openBookmarkEditor()
+	if !p.showAnnotateModal || p.annotateMessageID != "m2" {
+		t.Fatalf("bookmark modal = %v %q", p.showAnnotateModal, p.annotateMessageID)
+	}
+	p.annotateInput.SetValue("root cause")
+	if msg := p.applyAnnotateAction(annotateSaveID)(); msg != nil {
+		t.Fatalf("save failed: %+v", msg)
+	}
+	if b := p.selectedAnnotations().Bookmark("m2"); b == nil || b.Note != "root cause" {
+		t.Fatalf("bookmark = %+v", b)
+	}
+	lines := strings.Join(p.renderMessageBubble(p.messages[1], 1, 60), "\n")
+	if !strings.Contains(lines, "◆ root cause") {
+		t.Errorf("bookmark note not rendered:\n%s", lines)
+	}
+
+	// Jumping wraps around to the only bookmark
+	p.messageCursor = 2
+	p.jumpToBookmark(1)
+	if p.messageCursor != 1 {
+		t.Errorf("messageCursor = %d, want 1", p.messageCursor)
+	}
+
+	// Reopening an existing bookmark offers removal
+	p.openBookmarkEditor()
+	if !p.annotateHasExisting() {
+		t.Error("existing bookmark not detected")
+	}
+	if msg := p.applyAnnotateAction(annotateRemoveID)(); msg != nil {
+		t.Fatalf("remove failed: %+v", msg)
+	}
+	if p.selectedAnnotations() != nil {
+		t.Errorf("annotations left after removing the only bookmark: %+v", p.selectedAnnotations())
+	}
+}
+
+func TestArchivedSessionKeepsAnnotations(t *testing.T) {
+	p := newAnnotatedPlugin(t)
+	if msg := p.toggleStar()(); msg != nil {
+		t.Fatalf("save failed: %+v", msg)
+	}
+
+	archived := adapter.Session{ID: "s1", AdapterID: "archive", Archived: true}
+	if !p.annotationsFor(archived).starred() {
+		t.Error("archived copy should show the original session's star")
+	}
+	if k := p.annotationKeyFor(archived); k.AdapterID != "claude-code" {
+		t.Errorf("archived key = %+v", k)
+	}
+	// Live sessions of other adapters don't share annotations
+	if p.annotationsFor(adapter.Session{ID: "s1", AdapterID: "codex"}) != nil {
+		t.Error("annotations leaked to another adapter's session")
+	}
+}
+
+func TestExportIncludesAnnotations(t *testing.T) {
+	ts := time.Date(2025, 1, 15, 10, 30, 0, 0, time.Local)
+	session := &adapter.Session{ID: "s1", Name: "Outage", CreatedAt: ts}
+	messages := []adapter.Message{
+		{ID: "m1", Role: "user", Content: "Why is it down?", Timestamp: ts},
+		{ID: "m2", Role: "assistant", Content: "The cache expired.", Timestamp: ts.Add(time.Minute)},
+	}
+	ann := &SessionAnnotations{
+		Starred:   true,
+		Tags:      []string{"incident"},
+		Bookmarks: []Bookmark{{MessageID: "m2", Note: "root cause", CreatedAt: ts}},
+	}
+
+	md := ExportSessionAsMarkdown(session, messages, ann)
+	for _, want := range []string{"**Starred**: yes\n", "**Tags**: incident\n", "*Bookmark: root cause*\n"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+
+	// The note isn't imported as message content
+	path := filepath.Join(t.TempDir(), "session.md")
+	if err := os.WriteFile(path, []byte(md), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tr, _, err := imported.ParseFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tr.Messages[1].Content; got != "The cache expired." {
+		t.Errorf("imported content = %q", got)
+	}
+
+	data, err := ExportSessionAsJSON(session, messages, ann)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Session     adapter.Session     `json:"session"`
+		Annotations *SessionAnnotations `json:"annotations"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Session.ID != "s1" || decoded.Annotations == nil || decoded.Annotations.Bookmarks[0].Note != "root cause" {
+		t.Errorf("json = %s", data)
+	}
+}
@@ -0,0 +1,109 @@
+package conversations
+
+import (
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+// archiveInterval is the minimum time between archive passes. Session
+// reloads are frequent while agents run; archiving on each would rewrite
+// active sessions constantly.
+const archiveInterval = 10 * time.Minute
+
+// initArchiver finds the archive adapter and applies the configured
+// retention. The archive is looked up among all adapters rather than the
+// detected ones so a project's first sessions can be archived.
+func (p *Plugin) initArchiver(ctx *plugin.Context) {
+	p.archiver = nil
+	p.lastArchive = time.Time{}
+	if ctx.Config == nil || !ctx.Config.Plugins.Conversations.Archive {
+		return
+	}
+	for _, a := range ctx.Adapters {
+		if ar, ok := a.(adapter.Archiver); ok {
+			cfg := ctx.Config.Plugins.Conversations
+			maxBytes := int64(cfg.ArchiveMaxSizeMB) << 20
+			if cfg.ArchiveMaxSizeMB < 0 {
+				maxBytes = -1
+			}
+			ar.SetRetention(cfg.ArchiveMaxAge, maxBytes)
+			p.archiver = ar
+			return
+		}
+	}
+}
+
+// archiveSessions copies live sessions that changed since they were last
+// archived into the archive, then applies its retention limits. It runs at
+// most once per archiveInterval.
+func (p *Plugin) archiveSessions() tea.Cmd {
+	if p.archiver == nil || time.Since(p.lastArchive) < archiveInterval {
+		return nil
+	}
+	p.lastArchive = time.Now()
+
+	archiver := p.archiver
+	adapters := p.adapters
+	sessions := make([]adapter.Session, len(p.sessions))
+	copy(sessions, p.sessions)
+	workDir := p.ctx.WorkDir
+	if abs, err := filepath.Abs(workDir); err == nil {
+		workDir = abs
+	}
+	logger := p.ctx.Logger
+
+	return func() tea.Msg {
+		for _, s := range sessions {
+			if s.Archived || s.Imported || s.FileSize >= adapter.HugeSessionThreshold {
+				continue
+			}
+			if at, ok := archiver.ArchivedUpdatedAt(s.ID); ok && !s.UpdatedAt.After(at) {
+				continue
+			}
+			a := adapters[s.AdapterID]
+			if a == nil {
+				continue
+			}
+			messages, err := a.Messages(s.ID)
+			if err != nil || len(messages) == 0 {
+				continue
+			}
+			project := workDir
+			if s.WorktreePath != "" {
+				project = s.WorktreePath
+			}
+			if err := archiver.ArchiveSession(project, s, messages); err != nil && logger != nil {
+				logger.Warn("conversations: archive session failed", "session", s.ID, "error", err)
+			}
+		}
+		if err := archiver.Prune(); err != nil && logger != nil {
+			logger.Warn("conversations: archive prune failed", "error", err)
+		}
+		return nil
+	}
+}
+
+// mergeSessions adds sessions to the list, deduplicating by ID. A live
+// session replaces its archived copy; an archived copy never replaces a
+// live session.
+func mergeSessions(existing, incoming []adapter.Session) []adapter.Session {
+	index := make(map[string]int, len(existing))
+	for i, s := range existing {
+		index[s.ID] = i
+	}
+	for _, s := range incoming {
+		i, ok := index[s.ID]
+		switch {
+		case !ok:
+			index[s.ID] = len(existing)
+			existing = append(existing, s)
+		case existing[i].Archived && !s.Archived:
+			existing[i] = s
+		}
+	}
+	return existing
+}
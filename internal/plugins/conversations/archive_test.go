@@ -0,0 +1,80 @@
+package conversations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+// fakeArchiver records archived sessions in memory.
+type fakeArchiver struct {
+	mockAdapter
+	archived map[string]time.Time
+	pruned   bool
+}
+
+func (f *fakeArchiver) SetRetention(time.Duration, int64) {}
+func (f *fakeArchiver) ArchivedUpdatedAt(id string) (time.Time, bool) {
+	at, ok := f.archived[id]
+	return at, ok
+}
+func (f *fakeArchiver) ArchiveSession(_ string, s adapter.Session, _ []adapter.Message) error {
+	f.archived[s.ID] = s.UpdatedAt
+	return nil
+}
+func (f *fakeArchiver) Prune() error {
+	f.pruned = true
+	return nil
+}
+
+// messageAdapter returns one message for every session.
+type messageAdapter struct{ mockAdapter }
+
+func (m *messageAdapter) Messages(sessionID string) ([]adapter.Message, error) {
+	return []adapter.Message{{ID: sessionID + "-1", Role: "user", Content: "hi"}}, nil
+}
+
+func TestMergeSessionsPrefersLive(t *testing.T) {
+	archived := adapter.Session{ID: "a", AdapterID: "archive", Archived: true}
+	live := adapter.Session{ID: "a", AdapterID: "claude-code"}
+
+	got := mergeSessions([]adapter.Session{archived}, []adapter.Session{live, {ID: "b", Archived: true}})
+	if len(got) != 2 || got[0].Archived || got[0].AdapterID != "claude-code" {
+		t.Errorf("live session should replace its archived copy: %+v", got)
+	}
+	got = mergeSessions(got, []adapter.Session{archived})
+	if got[0].Archived {
+		t.Error("an archived copy should not replace a live session")
+	}
+}
+
+func TestArchiveSessions(t *testing.T) {
+	now := time.Now()
+	ar := &fakeArchiver{archived: map[string]time.Time{"same": now}}
+	p := New()
+	p.ctx = &plugin.Context{WorkDir: "/proj"}
+	p.archiver = ar
+	p.adapters = map[string]adapter.Adapter{"mock": &messageAdapter{}}
+	p.sessions = []adapter.Session{
+		{ID: "new", AdapterID: "mock", UpdatedAt: now},
+		{ID: "same", AdapterID: "mock", UpdatedAt: now},
+		{ID: "old-copy", AdapterID: "archive", Archived: true, UpdatedAt: now},
+		{ID: "huge", AdapterID: "mock", UpdatedAt: now, FileSize: adapter.HugeSessionThreshold},
+	}
+
+	cmd := p.archiveSessions()
+	if cmd == nil {
+		t.Fatal("expected an archive pass")
+	}
+	cmd()
+	if _, ok := ar.archived["new"]; !ok || len(ar.archived) != 2 || !ar.pruned {
+		t.Errorf("archived = %v, pruned = %v", ar.archived, ar.pruned)
+	}
+
+	// Passes are rate limited
+	if p.archiveSessions() != nil {
+		t.Error("a second pass within the interval should be skipped")
+	}
+}
@@ -0,0 +1,289 @@
+package conversations
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/app"
+	"github.com/marcus/sidecar/internal/config"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+)
+
+// budgetUsage is a budget's spend in its current period.
+type budgetUsage struct {
+	Budget config.BudgetConfig
+	Index  int       // position in the config, for alert tracking
+	Start  time.Time // start of the current period
+	Cost   float64
+	Tokens int
+}
+
+// Fraction returns how much of the budget is used; the larger of the cost
+// and token fractions when both are limited.
+func (u *budgetUsage) Fraction() float64 {
+	var f float64
+	if u.Budget.Cost > 0 {
+		f = u.Cost / u.Budget.Cost
+	}
+	if u.Budget.Tokens > 0 {
+		f = max(f, float64(u.Tokens)/float64(u.Budget.Tokens))
+	}
+	return f
+}
+
+// Level returns the alert level for the usage.
+func (u *budgetUsage) Level() int {
+	switch f := u.Fraction(); {
+	case f >= 1:
+		return appmsg.BudgetExceeded
+	case f >= u.warnAt():
+		return appmsg.BudgetWarning
+	}
+	return appmsg.BudgetOK
+}
+
+func (u *budgetUsage) warnAt() float64 {
+	if u.Budget.WarnAt > 0 && u.Budget.WarnAt < 1 {
+		return u.Budget.WarnAt
+	}
+	return 0.8
+}
+
+// Label returns the budget's name, or its period and scope.
+func (u *budgetUsage) Label() string {
+	if u.Budget.Name != "" {
+		return u.Budget.Name
+	}
+	label := budgetPeriod(u.Budget)
+	if u.Budget.Scope == "worktree" {
+		label += " worktree"
+	}
+	return label
+}
+
+// Amount returns spend against the limit that is closest to running out,
+// e.g. "$12.40/$20" or "1.2M/5M tok".
+func (u *budgetUsage) Amount() string {
+	costFrac, tokenFrac := -1.0, -1.0
+	if u.Budget.Cost > 0 {
+		costFrac = u.Cost / u.Budget.Cost
+	}
+	if u.Budget.Tokens > 0 {
+		tokenFrac = float64(u.Tokens) / float64(u.Budget.Tokens)
+	}
+	if tokenFrac > costFrac {
+		return fmt.Sprintf("%s/%s tok", formatK(u.Tokens), formatK(u.Budget.Tokens))
+	}
+	return fmt.Sprintf("%s/%s", formatBudgetCost(u.Cost), formatBudgetCost(u.Budget.Cost))
+}
+
+// formatBudgetCost formats dollars, dropping cents from whole limits.
+func formatBudgetCost(c float64) string {
+	if c == float64(int(c)) && c >= 1 {
+		return fmt.Sprintf("$%d", int(c))
+	}
+	return fmt.Sprintf("$%.2f", c)
+}
+
+// budgetPeriod returns the budget's period, defaulting to day.
+func budgetPeriod(b config.BudgetConfig) string {
+	if b.Period == "" {
+		return "day"
+	}
+	return b.Period
+}
+
+// budgetPeriodStart returns the start of the period containing now. Weeks
+// start on Monday.
+func budgetPeriodStart(period string, now time.Time) time.Time {
+	y, m, d := now.Date()
+	switch period {
+	case "week":
+		offset := (int(now.Weekday()) + 6) % 7
+		return time.Date(y, m, d-offset, 0, 0, 0, 0, now.Location())
+	case "month":
+		return time.Date(y, m, 1, 0, 0, 0, 0, now.Location())
+	}
+	return time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+}
+
+// budgetAppliesTo returns true if the budget covers the project.
+func budgetAppliesTo(b config.BudgetConfig, projectRoot string) bool {
+	return b.Project == "" || filepath.Clean(b.Project) == filepath.Clean(projectRoot)
+}
+
+// budgetCounts returns true if the session counts toward the budget's
+// scope. Imported transcripts never count.
+func budgetCounts(b config.BudgetConfig, s *adapter.Session) bool {
+	if s.Imported {
+		return false
+	}
+	if b.Scope != "worktree" {
+		return true
+	}
+	if b.Worktree == "" {
+		return s.WorktreePath == ""
+	}
+	ok, _ := path.Match(b.Worktree, s.WorktreeName)
+	return ok
+}
+
+// computeBudgets returns the current period's usage of each budget that
+// applies to the project. Indexed sessions count the messages sent in the
+// period; others spread their totals evenly over their lifetime.
+func computeBudgets(budgets []config.BudgetConfig, projectRoot string, sessions []adapter.Session, index map[string]*sessionMessageInfo, now time.Time) []budgetUsage {
+	var usages []budgetUsage
+	for i, b := range budgets {
+		if !budgetAppliesTo(b, projectRoot) {
+			continue
+		}
+		u := budgetUsage{Budget: b, Index: i, Start: budgetPeriodStart(budgetPeriod(b), now)}
+		for j := range sessions {
+			s := &sessions[j]
+			if s.UpdatedAt.Before(u.Start) || !budgetCounts(b, s) {
+				continue
+			}
+			cost, tokens := sessionUsageBetween(s, index[s.ID], u.Start, now)
+			u.Cost += cost
+			u.Tokens += tokens
+		}
+		usages = append(usages, u)
+	}
+	return usages
+}
+
+// sessionUsageBetween returns a session's cost and tokens in [start, end).
+// Growth since the session was indexed is spread over the time since.
+func sessionUsageBetween(s *adapter.Session, info *sessionMessageInfo, start, end time.Time) (float64, int) {
+	if info == nil || len(info.Usage) == 0 {
+		f := overlapFraction(s.CreatedAt, s.UpdatedAt, start, end)
+		return s.EstCost * f, int(math.Round(float64(s.TotalTokens) * f))
+	}
+	var cost float64
+	var tokens int
+	for _, m := range info.Usage {
+		at := m.At
+		if at.IsZero() {
+			at = s.CreatedAt
+		}
+		if !at.Before(start) && at.Before(end) {
+			cost += m.Cost
+			tokens += m.Tokens
+		}
+	}
+	if s.UpdatedAt.After(info.UpdatedAt) {
+		f := overlapFraction(info.UpdatedAt, s.UpdatedAt, start, end)
+		cost += max(s.EstCost-info.EstCost, 0) * f
+		tokens += int(math.Round(float64(max(s.TotalTokens-info.TotalTokens, 0)) * f))
+	}
+	return cost, tokens
+}
+
+// overlapFraction returns the fraction of [from, to] that falls in
+// [start, end]. A zero from or an empty span is the instant to.
+func overlapFraction(from, to, start, end time.Time) float64 {
+	if from.IsZero() || !to.After(from) {
+		if to.Before(start) || to.After(end) {
+			return 0
+		}
+		return 1
+	}
+	lo, hi := from, to
+	if start.After(lo) {
+		lo = start
+	}
+	if end.Before(hi) {
+		hi = end
+	}
+	if !hi.After(lo) {
+		return 0
+	}
+	return float64(hi.Sub(lo)) / float64(to.Sub(from))
+}
+
+// budgetIndexSessions returns the sessions whose messages budgets need:
+// those counting toward a budget that were active in its period. Sessions
+// indexed today aren't indexed again as they grow; sessionUsageBetween
+// places the growth instead.
+func budgetIndexSessions(budgets []config.BudgetConfig, projectRoot string, sessions []adapter.Session, index map[string]*sessionMessageInfo, now time.Time) []adapter.Session {
+	today := budgetPeriodStart("day", now)
+	var out []adapter.Session
+	for j := range sessions {
+		s := &sessions[j]
+		if info := index[s.ID]; info != nil && !info.UpdatedAt.Before(today) {
+			continue
+		}
+		for _, b := range budgets {
+			if budgetAppliesTo(b, projectRoot) && budgetCounts(b, s) &&
+				!s.UpdatedAt.Before(budgetPeriodStart(budgetPeriod(b), now)) {
+				out = append(out, *s)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// checkBudgets recomputes budget usage after sessions change. It updates
+// the header indicator and toasts once per period when a budget reaches
+// its warning level or runs out.
+func (p *Plugin) checkBudgets() tea.Cmd {
+	var budgets []config.BudgetConfig
+	projectRoot := ""
+	if p.ctx != nil && p.ctx.Config != nil {
+		budgets = p.ctx.Config.Budgets
+		projectRoot = p.ctx.ProjectRoot
+		if projectRoot == "" {
+			projectRoot = p.ctx.WorkDir
+		}
+	}
+	now := time.Now()
+	usages := computeBudgets(budgets, projectRoot, p.sessions, p.messageIndex, now)
+
+	// The header shows the budget closest to its limit
+	var status app.BudgetStatusMsg
+	var closest *budgetUsage
+	for i := range usages {
+		if closest == nil || usages[i].Fraction() > closest.Fraction() {
+			closest = &usages[i]
+		}
+	}
+	if closest != nil {
+		status = app.BudgetStatusMsg{Text: closest.Amount() + " " + closest.Label(), Level: closest.Level()}
+	}
+
+	// Index sessions so their usage is placed by message time
+	cmds := []tea.Cmd{p.indexSessions(budgetIndexSessions(budgets, projectRoot, p.sessions, p.messageIndex, now))}
+	if status != p.budgetStatus {
+		p.budgetStatus = status
+		cmds = append(cmds, func() tea.Msg { return status })
+	}
+
+	for i := range usages {
+		u := &usages[i]
+		level := u.Level()
+		key := fmt.Sprintf("%d@%s", u.Index, u.Start.Format(time.DateOnly))
+		if level == appmsg.BudgetOK || p.budgetAlerts[key] >= level {
+			continue
+		}
+		p.budgetAlerts[key] = level
+		toast := app.ToastMsg{
+			Message:  fmt.Sprintf("Budget %s at %.0f%%: %s", u.Label(), 100*u.Fraction(), u.Amount()),
+			Duration: 5 * time.Second,
+		}
+		if level == appmsg.BudgetExceeded {
+			toast.Message = fmt.Sprintf("⚠ Over budget %s: %s", u.Label(), u.Amount())
+			toast.Duration = 10 * time.Second
+			toast.IsError = true
+		}
+		cmds = append(cmds, func() tea.Msg { return toast })
+		// Only one alert at a time to avoid toast spam
+		break
+	}
+	return tea.Batch(cmds...)
+}
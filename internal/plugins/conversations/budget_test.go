@@ -0,0 +1,204 @@
+package conversations
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/app"
+	"github.com/marcus/sidecar/internal/config"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+func TestBudgetPeriodStart(t *testing.T) {
+	now := time.Date(2026, 10, 15, 14, 30, 0, 0, time.Local) // Thursday
+	tests := []struct {
+		period string
+		want   time.Time
+	}{
+		{"day", time.Date(2026, 10, 15, 0, 0, 0, 0, time.Local)},
+		{"week", time.Date(2026, 10, 12, 0, 0, 0, 0, time.Local)},
+		{"month", time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		if got := budgetPeriodStart(tt.period, now); !got.Equal(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.period, got, tt.want)
+		}
+	}
+	// Sunday belongs to the week starting the previous Monday
+	sunday := time.Date(2026, 10, 18, 9, 0, 0, 0, time.Local)
+	if got := budgetPeriodStart("week", sunday); got.Day() != 12 {
+		t.Errorf("sunday week start = %v", got)
+	}
+}
+
+func TestComputeBudgets(t *testing.T) {
+	now := time.Date(2026, 10, 15, 14, 0, 0, 0, time.Local)
+	sessions := []adapter.Session{
+		{ID: "today", EstCost: 5, TotalTokens: 1000, UpdatedAt: now.Add(-time.Hour)},
+		{ID: "feature", EstCost: 3, TotalTokens: 500, UpdatedAt: now.Add(-2 * time.Hour), WorktreeName: "feature-x", WorktreePath: "/proj-feature-x"},
+		{ID: "yesterday", EstCost: 7, TotalTokens: 2000, UpdatedAt: now.Add(-24 * time.Hour)},
+		{ID: "imported", EstCost: 100, TotalTokens: 100000, UpdatedAt: now, Imported: true},
+	}
+	budgets := []config.BudgetConfig{
+		{Cost: 10},
+		{Period: "month", Tokens: 5000},
+		{Scope: "worktree", Cost: 10},
+		{Scope: "worktree", Worktree: "feature-*", Cost: 10},
+		{Project: "/other", Cost: 1},
+	}
+
+	usages := computeBudgets(budgets, "/proj", sessions, nil, now)
+	if len(usages) != 4 {
+		t.Fatalf("got %d usages, want 4 (other project skipped)", len(usages))
+	}
+	want := []struct {
+		cost   float64
+		tokens int
+	}{
+		{8, 1500},  // today, all worktrees
+		{15, 3500}, // month
+		{5, 1000},  // current worktree only
+		{3, 500},   // matching worktrees
+	}
+	for i, w := range want {
+		if usages[i].Cost != w.cost || usages[i].Tokens != w.tokens {
+			t.Errorf("budget %d: got $%v %d tokens, want $%v %d", i, usages[i].Cost, usages[i].Tokens, w.cost, w.tokens)
+		}
+	}
+
+	if u := usages[0]; u.Level() != appmsg.BudgetWarning || u.Amount() != "$8/$10" || u.Label() != "day" {
+		t.Errorf("day budget: level %d, amount %q, label %q", u.Level(), u.Amount(), u.Label())
+	}
+	if u := usages[1]; u.Level() != appmsg.BudgetOK || u.Amount() != "3.5k/5.0k tok" {
+		t.Errorf("month budget: level %d, amount %q", u.Level(), u.Amount())
+	}
+}
+
+func TestComputeBudgets_SpansPeriods(t *testing.T) {
+	now := time.Date(2026, 10, 15, 14, 0, 0, 0, time.Local)
+	yesterday := now.Add(-24 * time.Hour)
+	sessions := []adapter.Session{
+		// Not indexed: totals spread over the session's lifetime
+		{ID: "long", EstCost: 12, TotalTokens: 2400, CreatedAt: yesterday, UpdatedAt: now},
+		// Indexed two hours ago, grown by $2 and 1000 tokens since
+		{ID: "indexed", EstCost: 5, TotalTokens: 2500, CreatedAt: yesterday, UpdatedAt: now},
+	}
+	index := map[string]*sessionMessageInfo{
+		"indexed": {
+			UpdatedAt: now.Add(-2 * time.Hour), EstCost: 3, TotalTokens: 1500,
+			Usage: []messageUsage{
+				{At: yesterday.Add(-4 * time.Hour), Tokens: 1000, Cost: 2},
+				{At: now.Add(-5 * time.Hour), Tokens: 500, Cost: 1},
+			},
+		},
+	}
+	budgets := []config.BudgetConfig{{Cost: 100}, {Period: "month", Cost: 100}}
+
+	usages := computeBudgets(budgets, "/proj", sessions, index, now)
+	want := []struct {
+		cost   float64
+		tokens int
+	}{
+		{7 + 3, 1400 + 1500}, // 14 of long's 24 hours, plus today's message and growth
+		{12 + 5, 2400 + 2500},
+	}
+	for i, w := range want {
+		if math.Abs(usages[i].Cost-w.cost) > 1e-9 || usages[i].Tokens != w.tokens {
+			t.Errorf("budget %d: got $%v %d tokens, want $%v %d", i, usages[i].Cost, usages[i].Tokens, w.cost, w.tokens)
+		}
+	}
+}
+
+func TestCheckBudgets_IndexesMessages(t *testing.T) {
+	now := time.Now()
+	p := New()
+	p.ctx = &plugin.Context{WorkDir: "/proj", ProjectRoot: "/proj", Config: &config.Config{
+		Budgets: []config.BudgetConfig{{Name: "daily", Cost: 10}},
+	}}
+	// $6 of sonnet input yesterday and $3 today
+	p.adapters = map[string]adapter.Adapter{"mock": &callAdapter{messages: map[string][]adapter.Message{
+		"s1": {
+			{ID: "m1", Role: "assistant", Model: "claude-sonnet-4", Timestamp: now.Add(-24 * time.Hour), TokenUsage: adapter.TokenUsage{InputTokens: 2_000_000}},
+			{ID: "m2", Role: "assistant", Model: "claude-sonnet-4", Timestamp: now, TokenUsage: adapter.TokenUsage{InputTokens: 1_000_000}},
+		},
+	}}}
+	p.sessions = []adapter.Session{{ID: "s1", AdapterID: "mock", MessageCount: 2, EstCost: 9, TotalTokens: 3_000_000,
+		CreatedAt: now.Add(-24 * time.Hour), UpdatedAt: now}}
+
+	var index *MessageIndexMsg
+	for _, m := range runBatch(p.checkBudgets()) {
+		if m, ok := m.(MessageIndexMsg); ok {
+			index = &m
+		}
+	}
+	if index == nil {
+		t.Fatal("checkBudgets should index the session's messages")
+	}
+
+	var status *app.BudgetStatusMsg
+	for _, m := range runBatch(p.applyMessageIndex(*index)) {
+		if m, ok := m.(app.BudgetStatusMsg); ok {
+			status = &m
+		}
+	}
+	if status == nil || status.Text != "$3/$10 daily" || status.Level != appmsg.BudgetOK {
+		t.Errorf("status = %+v", status)
+	}
+}
+
+func TestCheckBudgets(t *testing.T) {
+	now := time.Now()
+	p := New()
+	p.ctx = &plugin.Context{WorkDir: "/proj", ProjectRoot: "/proj", Config: &config.Config{
+		Budgets: []config.BudgetConfig{{Name: "daily", Cost: 10}},
+	}}
+	p.sessions = []adapter.Session{{ID: "s1", EstCost: 9, UpdatedAt: now}}
+
+	status, toast := budgetMsgs(p)
+	if status == nil || status.Text != "$9/$10 daily" || status.Level != appmsg.BudgetWarning {
+		t.Fatalf("status = %+v", status)
+	}
+	if toast == nil || toast.IsError || !strings.Contains(toast.Message, "daily at 90%") {
+		t.Fatalf("toast = %+v", toast)
+	}
+
+	// Nothing changed: no status update and no repeated alert
+	if status, toast := budgetMsgs(p); status != nil || toast != nil {
+		t.Errorf("repeat check: status = %+v, toast = %+v", status, toast)
+	}
+
+	// Going over budget alerts again
+	p.sessions[0].EstCost = 12
+	status, toast = budgetMsgs(p)
+	if status == nil || status.Level != appmsg.BudgetExceeded {
+		t.Errorf("status = %+v", status)
+	}
+	if toast == nil || !toast.IsError || !strings.Contains(toast.Message, "Over budget daily: $12/$10") {
+		t.Errorf("toast = %+v", toast)
+	}
+
+	// Without budgets the indicator is cleared
+	p.ctx.Config.Budgets = nil
+	if status, _ := budgetMsgs(p); status == nil || status.Text != "" {
+		t.Errorf("status = %+v", status)
+	}
+}
+
+// budgetMsgs runs checkBudgets and returns the status and toast it sends.
+func budgetMsgs(p *Plugin) (*app.BudgetStatusMsg, *app.ToastMsg) {
+	var status *app.BudgetStatusMsg
+	var toast *app.ToastMsg
+	for _, m := range runBatch(p.checkBudgets()) {
+		switch m := m.(type) {
+		case app.BudgetStatusMsg:
+			status = &m
+		case app.ToastMsg:
+			toast = &m
+		}
+	}
+	return status, toast
+}
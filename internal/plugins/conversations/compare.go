@@ -0,0 +1,494 @@
+package conversations
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/marcus/sidecar/internal/adapter"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/styles"
+)
+
+// compareSide is one session of a comparison.
+type compareSide struct {
+	Session   adapter.Session
+	Summary   SessionSummary
+	Exchanges []compareExchange
+	ToolCalls int
+}
+
+// compareExchange is a user prompt and the assistant turns answering it,
+// the unit turns are aligned by.
+type compareExchange struct {
+	Prompt   string
+	Tokens   int // input and output
+	Cost     float64
+	Tools    int
+	Duration time.Duration
+}
+
+// sessionCompare is the state of the comparison view.
+type sessionCompare struct {
+	LeftID, RightID string
+	Left, Right     *compareSide
+	Loading         bool
+	Err             error
+}
+
+// CompareLoadedMsg delivers the messages of both compared sessions.
+type CompareLoadedMsg struct {
+	Epoch           uint64
+	LeftID, RightID string
+	Left, Right     []adapter.Message
+	Err             error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m CompareLoadedMsg) GetEpoch() uint64 { return m.Epoch }
+
+// newCompareSide summarizes a session's messages for comparison.
+func newCompareSide(s adapter.Session, messages []adapter.Message) *compareSide {
+	duration := s.Duration
+	if duration == 0 && !s.CreatedAt.IsZero() {
+		duration = s.UpdatedAt.Sub(s.CreatedAt)
+	}
+	side := &compareSide{
+		Session: s,
+		Summary: ComputeSessionSummary(messages, duration),
+	}
+	for _, n := range side.Summary.ToolCounts {
+		side.ToolCalls += n
+	}
+	side.Exchanges = groupExchanges(GroupMessagesIntoTurns(messages))
+	return side
+}
+
+// groupExchanges splits turns into exchanges, each starting at a user turn
+// with a prompt. User turns only carrying tool results stay in the
+// exchange they belong to.
+func groupExchanges(turns []Turn) []compareExchange {
+	var exchanges []compareExchange
+	var msgs []adapter.Message
+	var current *compareExchange
+	flush := func() {
+		if current == nil {
+			return
+		}
+		summary := ComputeSessionSummary(msgs, 0)
+		current.Tokens = summary.TotalTokensIn + summary.TotalTokensOut
+		current.Cost = summary.TotalCost
+		if first, last := msgs[0].Timestamp, msgs[len(msgs)-1].Timestamp; !first.IsZero() && last.After(first) {
+			current.Duration = last.Sub(first)
+		}
+		exchanges = append(exchanges, *current)
+	}
+	for i := range turns {
+		t := &turns[i]
+		if t.Role == "user" && isPromptTurn(t) {
+			flush()
+			current = &compareExchange{Prompt: t.Preview(200)}
+			msgs = nil
+		} else if current == nil {
+			current = &compareExchange{}
+		}
+		msgs = append(msgs, t.Messages...)
+		current.Tools += t.ToolCount
+	}
+	flush()
+	return exchanges
+}
+
+// isPromptTurn returns true if a user turn has more than tool results.
+func isPromptTurn(t *Turn) bool {
+	for _, msg := range t.Messages {
+		if msg.Content == "" || msg.Content == "[1 tool result(s)]" {
+			continue
+		}
+		toolResultsOnly := len(msg.ContentBlocks) > 0
+		for _, b := range msg.ContentBlocks {
+			if b.Type != "tool_result" {
+				toolResultsOnly = false
+			}
+		}
+		if !toolResultsOnly {
+			return true
+		}
+	}
+	return false
+}
+
+// markOrCompare marks the selected session for comparison, or compares it
+// with the session marked earlier. Pressing it again on the marked session
+// clears the mark.
+func (p *Plugin) markOrCompare() tea.Cmd {
+	id := p.selectedSession
+	if id == "" {
+		return nil
+	}
+	switch p.compareMark {
+	case "":
+		p.compareMark = id
+		return appmsg.ShowToast("Marked "+p.sessionDisplayName(id, 30)+" for compare (= on another session to compare)", 2*time.Second)
+	case id:
+		p.compareMark = ""
+		return appmsg.ShowToast("Compare mark cleared", 2*time.Second)
+	}
+	left := p.compareMark
+	p.compareMark = ""
+	return p.openCompare(left, id)
+}
+
+// openCompare opens the comparison view and loads both sessions.
+func (p *Plugin) openCompare(leftID, rightID string) tea.Cmd {
+	p.view = ViewCompare
+	p.compare = &sessionCompare{LeftID: leftID, RightID: rightID, Loading: true}
+	p.compareLines = nil
+	p.compareScroll = 0
+
+	var epoch uint64
+	if p.ctx != nil {
+		epoch = p.ctx.Epoch
+	}
+	// Copy the sessions so the Cmd doesn't read p.sessions concurrently
+	type source struct {
+		adapter adapter.Adapter
+		session adapter.Session
+		found   bool
+	}
+	var sources [2]source
+	for i, id := range []string{leftID, rightID} {
+		sources[i].adapter = p.adapterForSession(id)
+		if s := p.findSession(id); s != nil {
+			sources[i].session, sources[i].found = *s, true
+		}
+	}
+	return func() tea.Msg {
+		msg := CompareLoadedMsg{Epoch: epoch, LeftID: leftID, RightID: rightID}
+		var loaded [2][]adapter.Message
+		for i, src := range sources {
+			switch {
+			case src.adapter == nil || !src.found:
+				msg.Err = fmt.Errorf("session not found")
+			case src.session.SizeLevel() >= 2:
+				msg.Err = fmt.Errorf("%s is too large to compare (%.0fMB)", src.session.Slug, src.session.SizeMB())
+			}
+			if msg.Err != nil {
+				return msg
+			}
+			messages, err := src.adapter.Messages(src.session.ID)
+			if err != nil {
+				msg.Err = err
+				return msg
+			}
+			loaded[i] = messages
+		}
+		msg.Left, msg.Right = loaded[0], loaded[1]
+		return msg
+	}
+}
+
+// findSession returns the session with the ID, or nil.
+func (p *Plugin) findSession(id string) *adapter.Session {
+	for i := range p.sessions {
+		if p.sessions[i].ID == id {
+			return &p.sessions[i]
+		}
+	}
+	return nil
+}
+
+// applyCompareLoaded fills the comparison once both sessions are loaded.
+func (p *Plugin) applyCompareLoaded(msg CompareLoadedMsg) {
+	c := p.compare
+	if c == nil || c.LeftID != msg.LeftID || c.RightID != msg.RightID {
+		return
+	}
+	c.Loading = false
+	c.Err = msg.Err
+	if msg.Err != nil {
+		return
+	}
+	left, right := p.findSession(msg.LeftID), p.findSession(msg.RightID)
+	if left == nil || right == nil {
+		c.Err = fmt.Errorf("session not found")
+		return
+	}
+	c.Left = newCompareSide(*left, msg.Left)
+	c.Right = newCompareSide(*right, msg.Right)
+}
+
+// closeCompare returns to the session list.
+func (p *Plugin) closeCompare() {
+	p.view = ViewSessions
+	p.compare = nil
+	p.compareLines = nil
+	p.compareScroll = 0
+	p.hitRegionsDirty = true
+}
+
+// updateCompare handles key events in the comparison view.
+func (p *Plugin) updateCompare(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
+	maxScroll := max(len(p.compareLines)-(p.height-2), 0)
+
+	switch msg.String() {
+	case "esc", "q":
+		p.closeCompare()
+
+	case "j", "down":
+		p.compareScroll = min(p.compareScroll+1, maxScroll)
+
+	case "k", "up":
+		p.compareScroll = max(p.compareScroll-1, 0)
+
+	case "g":
+		p.compareScroll = 0
+
+	case "G":
+		p.compareScroll = maxScroll
+
+	case "ctrl+d":
+		p.compareScroll = min(p.compareScroll+10, maxScroll)
+
+	case "ctrl+u":
+		p.compareScroll = max(p.compareScroll-10, 0)
+
+	case "s":
+		// Swap sides so deltas read the other way
+		if c := p.compare; c != nil {
+			c.LeftID, c.RightID = c.RightID, c.LeftID
+			c.Left, c.Right = c.Right, c.Left
+		}
+	}
+	return p, nil
+}
+
+// compareColumns returns the label and per-session column widths.
+func (p *Plugin) compareColumns() (label, col int) {
+	width := max(p.width-2, 40)
+	label = 14
+	col = max((width-label-14)/2, 12)
+	return label, col
+}
+
+// renderCompare renders the comparison view.
+func (p *Plugin) renderCompare() string {
+	width := max(p.width-2, 40)
+	rule := func(ch string) string { return styles.Muted.Render(strings.Repeat(ch, width)) }
+	var lines []string
+	lines = append(lines, styles.Title.Render(" Compare Sessions"))
+	lines = append(lines, rule("━"))
+
+	c := p.compare
+	switch {
+	case c == nil:
+		return strings.Join(lines, "\n")
+	case c.Loading:
+		lines = append(lines, styles.Muted.Render(" Loading sessions…"))
+		p.compareLines = lines
+		return strings.Join(lines, "\n")
+	case c.Err != nil:
+		lines = append(lines, styles.StatusDeleted.Render(" Unable to compare: "+c.Err.Error()))
+		p.compareLines = lines
+		return strings.Join(lines, "\n")
+	}
+
+	labelW, colW := p.compareColumns()
+	l, r := c.Left, c.Right
+	row := func(label, left, right, delta string) string {
+		return styles.Muted.Render(padToWidth(" "+label, labelW)) +
+			styles.Body.Render(padToWidth(ansi.Truncate(left, colW-1, "…"), colW)) +
+			styles.Body.Render(padToWidth(ansi.Truncate(right, colW-1, "…"), colW)) +
+			delta
+	}
+	name := func(s *compareSide) string {
+		return strings.TrimSpace(s.Session.AdapterIcon + " " + p.sessionDisplayName(s.Session.ID, colW))
+	}
+
+	// Totals
+	lines = append(lines, styles.Subtitle.Render(row("", "A: "+name(l), "B: "+name(r), "Δ B−A")))
+	lines = append(lines, rule("─"))
+	lines = append(lines, row("Agent", l.Session.AdapterName, r.Session.AdapterName, ""))
+	lines = append(lines, row("Model", compareModelName(l.Summary.PrimaryModel), compareModelName(r.Summary.PrimaryModel), ""))
+	lines = append(lines, row("Duration", formatSessionDuration(l.Summary.Duration), formatSessionDuration(r.Summary.Duration),
+		renderDurationDelta(r.Summary.Duration-l.Summary.Duration)))
+	lines = append(lines, row("Prompts", fmt.Sprint(len(l.Exchanges)), fmt.Sprint(len(r.Exchanges)),
+		renderCountDelta(len(r.Exchanges)-len(l.Exchanges))))
+	lines = append(lines, row("Messages", fmt.Sprint(l.Summary.MessageCount), fmt.Sprint(r.Summary.MessageCount),
+		renderCountDelta(r.Summary.MessageCount-l.Summary.MessageCount)))
+	lTokens := l.Summary.TotalTokensIn + l.Summary.TotalTokensOut
+	rTokens := r.Summary.TotalTokensIn + r.Summary.TotalTokensOut
+	lines = append(lines, row("Tokens", formatK(lTokens), formatK(rTokens), renderCountDelta(rTokens-lTokens)))
+	lines = append(lines, row("Cost", formatCost(l.Summary.TotalCost), formatCost(r.Summary.TotalCost),
+		renderCostDelta(r.Summary.TotalCost-l.Summary.TotalCost)))
+	lines = append(lines, row("Tool calls", fmt.Sprint(l.ToolCalls), fmt.Sprint(r.ToolCalls), renderCountDelta(r.ToolCalls-l.ToolCalls)))
+	lines = append(lines, row("Files", fmt.Sprint(l.Summary.FileCount), fmt.Sprint(r.Summary.FileCount),
+		renderCountDelta(r.Summary.FileCount-l.Summary.FileCount)))
+	lines = append(lines, "")
+
+	// Tool calls by tool
+	tools := make(map[string]bool)
+	for name := range l.Summary.ToolCounts {
+		tools[name] = true
+	}
+	for name := range r.Summary.ToolCounts {
+		tools[name] = true
+	}
+	if len(tools) > 0 {
+		names := make([]string, 0, len(tools))
+		for name := range tools {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			ti := l.Summary.ToolCounts[names[i]] + r.Summary.ToolCounts[names[i]]
+			tj := l.Summary.ToolCounts[names[j]] + r.Summary.ToolCounts[names[j]]
+			if ti != tj {
+				return ti > tj
+			}
+			return names[i] < names[j]
+		})
+		lines = append(lines, styles.Title.Render(" Tools"))
+		lines = append(lines, rule("─"))
+		for _, name := range names {
+			ln, rn := l.Summary.ToolCounts[name], r.Summary.ToolCounts[name]
+			lines = append(lines, row(ansi.Truncate(name, labelW-2, "…"), fmt.Sprint(ln), fmt.Sprint(rn), renderCountDelta(rn-ln)))
+		}
+		lines = append(lines, "")
+	}
+
+	// Files touched by either session
+	if l.Summary.FileCount+r.Summary.FileCount > 0 {
+		lines = append(lines, styles.Title.Render(" Files"))
+		lines = append(lines, rule("─"))
+		inLeft := make(map[string]bool, len(l.Summary.FilesTouched))
+		for _, f := range l.Summary.FilesTouched {
+			inLeft[f] = true
+		}
+		inRight := make(map[string]bool, len(r.Summary.FilesTouched))
+		for _, f := range r.Summary.FilesTouched {
+			inRight[f] = true
+		}
+		var files []string
+		files = append(files, l.Summary.FilesTouched...)
+		for _, f := range r.Summary.FilesTouched {
+			if !inLeft[f] {
+				files = append(files, f)
+			}
+		}
+		sort.Strings(files)
+		for _, f := range files {
+			tag, style := " A+B ", styles.Body
+			switch {
+			case !inRight[f]:
+				tag, style = " A   ", styles.StatusDeleted
+			case !inLeft[f]:
+				tag, style = "   B ", styles.StatusCompleted
+			}
+			path := p.compareFilePath(f)
+			if over := ansi.StringWidth(path) - (width - 6); over > 0 {
+				path = ansi.TruncateLeft(path, over+1, "…")
+			}
+			lines = append(lines, styles.Muted.Render(tag)+style.Render(path))
+		}
+		lines = append(lines, "")
+	}
+
+	// Aligned turns
+	lines = append(lines, styles.Title.Render(" Turns"))
+	lines = append(lines, rule("─"))
+	for i := range max(len(l.Exchanges), len(r.Exchanges)) {
+		var le, re *compareExchange
+		if i < len(l.Exchanges) {
+			le = &l.Exchanges[i]
+		}
+		if i < len(r.Exchanges) {
+			re = &r.Exchanges[i]
+		}
+		prompt := func(e *compareExchange) string {
+			switch {
+			case e == nil:
+				return "—"
+			case e.Prompt == "":
+				return "(no prompt)"
+			}
+			return strings.Join(strings.Fields(e.Prompt), " ")
+		}
+		stats := func(e *compareExchange) string {
+			if e == nil {
+				return ""
+			}
+			s := fmt.Sprintf("%s tok %s %d tools", formatK(e.Tokens), formatCost(e.Cost), e.Tools)
+			if e.Duration > 0 {
+				s += " " + formatSessionDuration(e.Duration)
+			}
+			return s
+		}
+		delta := ""
+		if le != nil && re != nil {
+			delta = renderCountDelta(re.Tokens-le.Tokens) + styles.Muted.Render(" tok ") + renderCostDelta(re.Cost-le.Cost)
+		}
+		lines = append(lines, row(fmt.Sprintf("#%d", i+1), prompt(le), prompt(re), ""))
+		lines = append(lines, styles.Muted.Render(row("", stats(le), stats(re), ""))+delta)
+	}
+
+	p.compareLines = lines
+	p.compareScroll = min(p.compareScroll, max(len(lines)-(p.height-2), 0))
+	end := min(p.compareScroll+p.height-2, len(lines))
+	return strings.Join(lines[p.compareScroll:end], "\n")
+}
+
+// compareModelName returns a model's short name, or the model ID.
+func compareModelName(model string) string {
+	if short := modelShortName(model); short != "" {
+		return short
+	}
+	return model
+}
+
+// compareFilePath shortens a file path relative to the project.
+func (p *Plugin) compareFilePath(path string) string {
+	if p.ctx != nil && p.ctx.WorkDir != "" {
+		if rel, err := filepath.Rel(p.ctx.WorkDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+	}
+	return path
+}
+
+// renderCountDelta renders a signed count difference; fewer is green.
+func renderCountDelta(n int) string {
+	switch {
+	case n > 0:
+		return styles.StatusDeleted.Render("+" + formatK(n))
+	case n < 0:
+		return styles.StatusCompleted.Render("−" + formatK(-n))
+	}
+	return styles.Muted.Render("=")
+}
+
+// renderCostDelta renders a signed cost difference; cheaper is green.
+func renderCostDelta(d float64) string {
+	switch {
+	case d >= 0.005:
+		return styles.StatusDeleted.Render(fmt.Sprintf("+$%.2f", d))
+	case d <= -0.005:
+		return styles.StatusCompleted.Render(fmt.Sprintf("−$%.2f", -d))
+	}
+	return styles.Muted.Render("=")
+}
+
+// renderDurationDelta renders a signed duration difference; faster is green.
+func renderDurationDelta(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return styles.StatusDeleted.Render("+" + formatSessionDuration(d))
+	case d <= -time.Second:
+		return styles.StatusCompleted.Render("−" + formatSessionDuration(-d))
+	}
+	return styles.Muted.Render("=")
+}
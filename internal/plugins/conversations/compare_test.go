@@ -0,0 +1,132 @@
+package conversations
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+// compareMessages returns a session answering each prompt with one
+// assistant message and one tool call.
+func compareMessages(model string, tokens int, file string, prompts ...string) []adapter.Message {
+	start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	var msgs []adapter.Message
+	for i, prompt := range prompts {
+		ts := start.Add(time.Duration(i) * 10 * time.Minute)
+		msgs = append(msgs,
+			adapter.Message{ID: prompt, Role: "user", Content: prompt, Timestamp: ts},
+			adapter.Message{ID: prompt + "-a", Role: "assistant", Model: model, Content: "working", Timestamp: ts.Add(time.Minute),
+				TokenUsage: adapter.TokenUsage{InputTokens: tokens, OutputTokens: tokens / 10},
+				ToolUses:   []adapter.ToolUse{{ID: prompt + "-t", Name: "Edit", Input: `{"file_path":"` + file + `"}`}},
+			},
+			// Tool results come back as user messages and don't start a new exchange
+			adapter.Message{ID: prompt + "-r", Role: "user", Content: "[1 tool result(s)]", Timestamp: ts.Add(2 * time.Minute),
+				ContentBlocks: []adapter.ContentBlock{{Type: "tool_result", ToolUseID: prompt + "-t", ToolOutput: "ok"}},
+			},
+			adapter.Message{ID: prompt + "-b", Role: "assistant", Model: model, Content: "done", Timestamp: ts.Add(3 * time.Minute),
+				TokenUsage: adapter.TokenUsage{InputTokens: tokens, OutputTokens: tokens / 10},
+			},
+		)
+	}
+	return msgs
+}
+
+func TestGroupExchanges(t *testing.T) {
+	msgs := compareMessages("claude-opus-4", 1000, "/proj/a.go", "fix the bug", "add a test")
+	exchanges := groupExchanges(GroupMessagesIntoTurns(msgs))
+	if len(exchanges) != 2 {
+		t.Fatalf("got %d exchanges, want 2", len(exchanges))
+	}
+	e := exchanges[0]
+	if e.Prompt != "fix the bug" || e.Tokens != 2200 || e.Tools != 1 || e.Duration != 3*time.Minute || e.Cost <= 0 {
+		t.Errorf("exchange = %+v", e)
+	}
+
+	// Assistant output before the first prompt gets its own exchange
+	leading := append([]adapter.Message{{ID: "x", Role: "assistant", Content: "hello"}}, msgs...)
+	if got := groupExchanges(GroupMessagesIntoTurns(leading)); len(got) != 3 || got[0].Prompt != "" {
+		t.Errorf("exchanges = %+v", got)
+	}
+}
+
+func TestCompareSessions(t *testing.T) {
+	now := time.Now()
+	p := New()
+	p.ctx = &plugin.Context{WorkDir: "/proj"}
+	p.width, p.height = 160, 60
+	p.adapters = map[string]adapter.Adapter{"mock": &callAdapter{messages: map[string][]adapter.Message{
+		"claude": compareMessages("claude-opus-4", 1000, "/proj/a.go", "fix the bug", "add a test"),
+		"codex":  compareMessages("gpt-5", 3000, "/proj/b.go", "fix the bug"),
+	}}}
+	p.sessions = []adapter.Session{
+		{ID: "claude", Name: "claude run", AdapterID: "mock", AdapterName: "Claude Code", UpdatedAt: now, Duration: 20 * time.Minute},
+		{ID: "codex", Name: "codex run", AdapterID: "mock", AdapterName: "Codex", UpdatedAt: now, Duration: 5 * time.Minute},
+	}
+
+	// The first = marks a session, = on the same session clears the mark
+	p.selectedSession = "claude"
+	p.markOrCompare()
+	if p.compareMark != "claude" || p.view != ViewSessions {
+		t.Fatalf("mark = %q, view = %v", p.compareMark, p.view)
+	}
+	p.markOrCompare()
+	if p.compareMark != "" {
+		t.Fatal("second = on the marked session should clear the mark")
+	}
+
+	p.markOrCompare()
+	p.selectedSession = "codex"
+	cmd := p.markOrCompare()
+	if p.view != ViewCompare || p.compareMark != "" || !p.compare.Loading {
+		t.Fatalf("view = %v, mark = %q, compare = %+v", p.view, p.compareMark, p.compare)
+	}
+	if !strings.Contains(p.renderCompare(), "Loading sessions") {
+		t.Error("view should show loading")
+	}
+	p.Update(cmd())
+
+	c := p.compare
+	if c.Loading || c.Err != nil || c.Left.Session.ID != "claude" || c.Right.Session.ID != "codex" {
+		t.Fatalf("compare = %+v", c)
+	}
+	if len(c.Left.Exchanges) != 2 || len(c.Right.Exchanges) != 1 || c.Left.ToolCalls != 2 || c.Right.ToolCalls != 1 {
+		t.Errorf("left = %+v, right = %+v", c.Left, c.Right)
+	}
+
+	out := p.renderCompare()
+	for _, want := range []string{"Claude Code", "Codex", "opus", "fix the bug", "add a test", "−15m", "a.go", "b.go", "Edit"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("view missing %q", want)
+		}
+	}
+
+	// Swapping sides flips the deltas
+	p.updateCompare(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	if c.Left.Session.ID != "codex" || !strings.Contains(p.renderCompare(), "+15m") {
+		t.Error("sides should be swapped")
+	}
+
+	p.updateCompare(tea.KeyMsg{Type: tea.KeyEsc})
+	if p.view != ViewSessions || p.compare != nil {
+		t.Errorf("view = %v after esc", p.view)
+	}
+}
+
+func TestCompareTooLarge(t *testing.T) {
+	p := New()
+	p.ctx = &plugin.Context{WorkDir: "/proj"}
+	p.adapters = map[string]adapter.Adapter{"mock": &callAdapter{}}
+	p.sessions = []adapter.Session{
+		{ID: "a", AdapterID: "mock"},
+		{ID: "b", Slug: "huge", AdapterID: "mock", FileSize: 600 * 1024 * 1024},
+	}
+	cmd := p.openCompare("a", "b")
+	p.Update(cmd())
+	if p.compare.Err == nil || !strings.Contains(p.renderCompare(), "huge is too large to compare") {
+		t.Errorf("err = %v", p.compare.Err)
+	}
+}
@@ -1,6 +1,7 @@
 package conversations
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,8 +12,10 @@ import (
 	"github.com/marcus/sidecar/internal/adapter"
 )
 
-// ExportSessionAsMarkdown converts a session and its messages to markdown format.
-func ExportSessionAsMarkdown(session *adapter.Session, messages []adapter.Message) string {
+// ExportSessionAsMarkdown converts a session and its messages to markdown
+// format. Annotations, if any, add star and tag header lines and a note
+// under each bookmarked message.
+func ExportSessionAsMarkdown(session *adapter.Session, messages []adapter.Message, ann *SessionAnnotations) string {
 	var sb strings.Builder
 
 	// Header
@@ -36,6 +39,12 @@ func ExportSessionAsMarkdown(session *adapter.Session, messages []adapter.Messag
 		if session.EstCost > 0 {
 			sb.WriteString(fmt.Sprintf("**Estimated Cost**: $%.2f\n", session.EstCost))
 		}
+		if ann.starred() {
+			sb.WriteString("**Starred**: yes\n")
+		}
+		if tags := ann.tags(); len(tags) > 0 {
+			sb.WriteString(fmt.Sprintf("**Tags**: %s\n", strings.Join(tags, ", ")))
+		}
 		sb.WriteString("\n---\n\n")
 	}
 
@@ -59,6 +68,15 @@ func ExportSessionAsMarkdown(session *adapter.Session, messages []adapter.Messag
 			sb.WriteString(fmt.Sprintf("*Tokens: in=%d, out=%d*\n\n", msg.InputTokens, msg.OutputTokens))
 		}
 
+		// Bookmark note
+		if b := ann.Bookmark(msg.ID); b != nil {
+			if b.Note != "" {
+				sb.WriteString(fmt.Sprintf("*Bookmark: %s*\n\n", b.Note))
+			} else {
+				sb.WriteString("*Bookmark*\n\n")
+			}
+		}
+
 		// Thinking blocks (if any)
 		if len(msg.ThinkingBlocks) > 0 {
 			for _, tb := range msg.ThinkingBlocks {
@@ -99,8 +117,8 @@ func CopyToClipboard(content string) error {
 }
 
 // ExportSessionToFile writes a session to a markdown file.
-func ExportSessionToFile(session *adapter.Session, messages []adapter.Message, workDir string) (string, error) {
-	md := ExportSessionAsMarkdown(session, messages)
+func ExportSessionToFile(session *adapter.Session, messages []adapter.Message, ann *SessionAnnotations, workDir string) (string, error) {
+	md := ExportSessionAsMarkdown(session, messages, ann)
 
 	// Generate filename from session name or ID
 	name := "session"
@@ -121,6 +139,51 @@ func ExportSessionToFile(session *adapter.Session, messages []adapter.Message, w
 	return filename, nil
 }
 
+// annotatedTranscript is a transcript with the session's annotations.
+// Importers that don't know about annotations ignore the extra field.
+type annotatedTranscript struct {
+	adapter.Transcript
+	Annotations *SessionAnnotations `json:"annotations,omitempty"`
+}
+
+// ExportSessionAsJSON converts a session and its messages to a lossless JSON
+// transcript that can be imported with "sidecar import". Details that only
+// apply on this machine, such as the session file path, are left out.
+func ExportSessionAsJSON(session *adapter.Session, messages []adapter.Message, ann *SessionAnnotations) ([]byte, error) {
+	t := annotatedTranscript{Transcript: adapter.Transcript{Version: adapter.TranscriptVersion, Messages: messages}}
+	if !ann.IsEmpty() {
+		t.Annotations = ann
+	}
+	if session != nil {
+		t.Session = *session
+	}
+	t.Session.IsActive = false
+	t.Session.Path = ""
+	t.Session.FileSize = 0
+	t.Session.WorktreeName = ""
+	t.Session.WorktreePath = ""
+	return json.MarshalIndent(t, "", "  ")
+}
+
+// ExportSessionToJSONFile writes a session to a JSON transcript file.
+func ExportSessionToJSONFile(session *adapter.Session, messages []adapter.Message, ann *SessionAnnotations, workDir string) (string, error) {
+	data, err := ExportSessionAsJSON(session, messages, ann)
+	if err != nil {
+		return "", err
+	}
+
+	name := "session"
+	if session != nil && session.Name != "" {
+		name = sanitizeFilename(session.Name)
+	}
+	timestamp := time.Now().Format("20060102-150405")
+	filename := fmt.Sprintf("%s-%s.json", name, timestamp)
+	if err := os.WriteFile(filepath.Join(workDir, filename), data, 0644); err != nil {
+		return "", err
+	}
+	return filename, nil
+}
+
 // formatExportDuration formats duration for export.
 func formatExportDuration(d time.Duration) string {
 	if d < time.Minute {
@@ -1,11 +1,14 @@
 package conversations
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/adapter/imported"
 )
 
 func TestSanitizeFilename(t *testing.T) {
@@ -66,7 +69,7 @@ func TestExportSessionAsMarkdown_NilSession(t *testing.T) {
 	messages := []adapter.Message{
 		{Role: "user", Content: "hello", Timestamp: time.Now()},
 	}
-	result := ExportSessionAsMarkdown(nil, messages)
+	result := ExportSessionAsMarkdown(nil, messages, nil)
 	if !strings.Contains(result, "Unknown Session") {
 		t.Error("nil session should use 'Unknown Session'")
 	}
@@ -106,7 +109,7 @@ func TestExportSessionAsMarkdown_BasicSession(t *testing.T) {
 		},
 	}
 
-	result := ExportSessionAsMarkdown(session, messages)
+	result := ExportSessionAsMarkdown(session, messages, nil)
 
 	checks := []string{
 		"Test Session",
@@ -132,7 +135,7 @@ func TestExportSessionAsMarkdown_EmptyMessages(t *testing.T) {
 		Name:      "Empty",
 		CreatedAt: time.Now(),
 	}
-	result := ExportSessionAsMarkdown(session, nil)
+	result := ExportSessionAsMarkdown(session, nil, nil)
 	if !strings.Contains(result, "Empty") {
 		t.Error("session name should be in header")
 	}
@@ -157,7 +160,7 @@ func TestExportSessionAsMarkdown_WithThinkingBlocks(t *testing.T) {
 		},
 	}
 
-	result := ExportSessionAsMarkdown(session, messages)
+	result := ExportSessionAsMarkdown(session, messages, nil)
 	if !strings.Contains(result, "<details>") {
 		t.Error("thinking blocks should use <details> tag")
 	}
@@ -168,3 +171,61 @@ func TestExportSessionAsMarkdown_WithThinkingBlocks(t *testing.T) {
 		t.Error("should show token count in thinking summary")
 	}
 }
+
+func TestExportSessionImportRoundTrip(t *testing.T) {
+	ts := time.Date(2025, 1, 15, 10, 30, 0, 0, time.Local)
+	session := &adapter.Session{
+		ID:          "ses_test123",
+		Name:        "Test Session",
+		AdapterID:   "claude-code",
+		AdapterName: "Claude Code",
+		CreatedAt:   ts,
+		UpdatedAt:   ts.Add(time.Minute),
+		Path:        "/home/u/.claude/projects/x/ses_test123.jsonl",
+	}
+	messages := []adapter.Message{
+		{ID: "m1", Role: "user", Content: "How does auth work?", Timestamp: ts},
+		{ID: "m2", Role: "assistant", Content: "Auth uses JWT tokens.", Timestamp: ts.Add(time.Minute),
+			Model:          "claude-sonnet-4-20250514",
+			TokenUsage:     adapter.TokenUsage{InputTokens: 1000, OutputTokens: 500, CacheRead: 20},
+			ThinkingBlocks: []adapter.ThinkingBlock{{Content: "Check middleware", TokenCount: 4}},
+			ToolUses:       []adapter.ToolUse{{ID: "t1", Name: "Read", Input: `{"file_path":"/src/auth.go"}`, Output: "package auth"}},
+		},
+	}
+	dir := t.TempDir()
+
+	// The JSON transcript keeps everything but machine-local details
+	jsonFile, err := ExportSessionToJSONFile(session, messages, nil, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr, format, err := imported.ParseFile(filepath.Join(dir, jsonFile))
+	if err != nil || format != imported.FormatJSON {
+		t.Fatalf("ParseFile(json) = %v, %v", format, err)
+	}
+	if tr.Session.Name != "Test Session" || tr.Session.Path != "" || tr.Session.AdapterName != "Claude Code" {
+		t.Errorf("session = %+v", tr.Session)
+	}
+	got := tr.Messages[1]
+	if got.CacheRead != 20 || got.ToolUses[0].Output != "package auth" || !got.Timestamp.Equal(messages[1].Timestamp) {
+		t.Errorf("message not preserved: %+v", got)
+	}
+
+	// Markdown keeps the conversation and its visible metadata
+	mdPath := filepath.Join(dir, "session.md")
+	if err := os.WriteFile(mdPath, []byte(ExportSessionAsMarkdown(session, messages, nil)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tr, format, err = imported.ParseFile(mdPath)
+	if err != nil || format != imported.FormatMarkdown {
+		t.Fatalf("ParseFile(markdown) = %v, %v", format, err)
+	}
+	if len(tr.Messages) != 2 {
+		t.Fatalf("got %d messages", len(tr.Messages))
+	}
+	got = tr.Messages[1]
+	if got.Content != "Auth uses JWT tokens." || got.InputTokens != 1000 || got.ThinkingBlocks[0].Content != "Check middleware" ||
+		got.ToolUses[0].Name != "Read" || !got.Timestamp.Equal(messages[1].Timestamp) {
+		t.Errorf("markdown message = %+v", got)
+	}
+}
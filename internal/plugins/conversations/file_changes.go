@@ -0,0 +1,443 @@
+package conversations
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/plugins/gitstatus"
+)
+
+// FileOp is the kind of change a tool call made to a file.
+type FileOp string
+
+const (
+	FileOpEdit   FileOp = "edit"
+	FileOpWrite  FileOp = "write"
+	FileOpDelete FileOp = "delete"
+	FileOpMove   FileOp = "move"
+	FileOpCopy   FileOp = "copy"
+)
+
+// changeDiffContext is the number of context lines kept around edits.
+const changeDiffContext = 3
+
+// FileChange is a single file mutation made by a tool call, normalized
+// across adapters.
+type FileChange struct {
+	Path         string
+	NewPath      string // destination of a move or copy
+	Op           FileOp
+	Tool         string
+	Diff         *gitstatus.ParsedDiff // nil when the tool input doesn't carry the content
+	Command      string                // shell command the change was inferred from
+	Reason       string                // assistant text that preceded the call
+	MessageIndex int
+	MessageID    string
+	Timestamp    time.Time
+	Failed       bool // the tool reported an error
+}
+
+// DiffStats returns the number of added and removed lines in the change.
+func (c *FileChange) DiffStats() (added, removed int) {
+	if c.Diff == nil {
+		return 0, 0
+	}
+	for _, h := range c.Diff.Hunks {
+		for _, l := range h.Lines {
+			switch l.Type {
+			case gitstatus.LineAdd:
+				added++
+			case gitstatus.LineRemove:
+				removed++
+			}
+		}
+	}
+	return added, removed
+}
+
+// Input field names used by the different agents' file tools.
+var (
+	changePathKeys    = []string{"file_path", "filePath", "path", "target_file", "notebook_path"}
+	changeOldKeys     = []string{"old_string", "oldString", "old_str"}
+	changeNewKeys     = []string{"new_string", "newString", "new_str"}
+	changeContentKeys = []string{"content", "file_text", "code_edit", "new_source", "contents"}
+)
+
+// ExtractFileChanges returns the file mutations made by tool calls in the
+// messages, in order. Changes are recognized by the shape of the tool input
+// rather than by tool name so that every adapter's edit, write, patch and
+// shell tools are covered.
+func ExtractFileChanges(messages []adapter.Message) []FileChange {
+	failed := make(map[string]bool)
+	for _, msg := range messages {
+		for _, b := range msg.ContentBlocks {
+			if b.Type == "tool_result" && b.IsError && b.ToolUseID != "" {
+				failed[b.ToolUseID] = true
+			}
+		}
+	}
+
+	var changes []FileChange
+	add := func(i int, msg adapter.Message, id, name, input, reason string) {
+		for _, c := range toolFileChanges(name, input) {
+			c.Tool = name
+			c.Reason = firstLine(reason)
+			c.MessageIndex = i
+			c.MessageID = msg.ID
+			c.Timestamp = msg.Timestamp
+			c.Failed = id != "" && failed[id]
+			changes = append(changes, c)
+		}
+	}
+
+	for i, msg := range messages {
+		if msg.Role != "assistant" {
+			continue
+		}
+		hasBlocks := false
+		for _, b := range msg.ContentBlocks {
+			if b.Type == "tool_use" {
+				hasBlocks = true
+				break
+			}
+		}
+		if !hasBlocks {
+			for _, tu := range msg.ToolUses {
+				add(i, msg, tu.ID, tu.Name, tu.Input, msg.Content)
+			}
+			continue
+		}
+		reason := ""
+		for _, b := range msg.ContentBlocks {
+			switch b.Type {
+			case "text":
+				if strings.TrimSpace(b.Text) != "" {
+					reason = b.Text
+				}
+			case "tool_use":
+				add(i, msg, b.ToolUseID, b.ToolName, b.ToolInput, reason)
+			}
+		}
+	}
+	return changes
+}
+
+// toolFileChanges returns the changes a single tool call made.
+func toolFileChanges(name, input string) []FileChange {
+	var data map[string]any
+	if err := json.Unmarshal([]byte(input), &data); err != nil {
+		// Codex sends apply_patch input as the raw patch text
+		if strings.Contains(input, patchBegin) {
+			return parsePatchChanges(input)
+		}
+		return nil
+	}
+
+	for _, v := range data {
+		if s, ok := v.(string); ok && strings.Contains(s, patchBegin) {
+			return parsePatchChanges(s)
+		}
+	}
+
+	lower := strings.ToLower(name)
+	path := firstString(data, changePathKeys)
+	if path == "" {
+		for _, key := range []string{"command", "cmd"} {
+			if cmd := commandString(data[key]); cmd != "" && isShellTool(lower, data[key]) {
+				return shellFileChanges(cmd)
+			}
+		}
+		return nil
+	}
+
+	if edits, ok := data["edits"].([]any); ok {
+		var changes []FileChange
+		for _, e := range edits {
+			m, ok := e.(map[string]any)
+			if !ok {
+				continue
+			}
+			changes = append(changes, editChange(path, firstString(m, changeOldKeys), firstString(m, changeNewKeys)))
+		}
+		return changes
+	}
+
+	_, hasOld := lookupString(data, changeOldKeys)
+	newText, hasNew := lookupString(data, changeNewKeys)
+	if hasOld || hasNew {
+		return []FileChange{editChange(path, firstString(data, changeOldKeys), newText)}
+	}
+
+	if content, ok := lookupString(data, changeContentKeys); ok && nameContains(lower, "write", "create", "edit", "save") {
+		return []FileChange{{
+			Path: path,
+			Op:   FileOpWrite,
+			Diff: gitstatus.DiffText(path, path, "", content, changeDiffContext),
+		}}
+	}
+
+	if nameContains(lower, "delete", "remove") {
+		return []FileChange{{Path: path, Op: FileOpDelete}}
+	}
+	return nil
+}
+
+// editChange builds a string replacement change.
+func editChange(path, oldText, newText string) FileChange {
+	return FileChange{
+		Path: path,
+		Op:   FileOpEdit,
+		Diff: gitstatus.DiffText(path, path, oldText, newText, changeDiffContext),
+	}
+}
+
+const patchBegin = "*** Begin Patch"
+
+// parsePatchChanges parses an apply_patch envelope into one change per file.
+// Update hunks are reassembled into old and new text so they can be diffed
+// like any other edit.
+func parsePatchChanges(patch string) []FileChange {
+	var changes []FileChange
+	var cur *FileChange
+	var oldText, newText strings.Builder
+
+	flush := func() {
+		if cur == nil {
+			return
+		}
+		if cur.Op != FileOpDelete {
+			cur.Diff = gitstatus.DiffText(cur.Path, cur.Path, oldText.String(), newText.String(), changeDiffContext)
+		}
+		changes = append(changes, *cur)
+		cur = nil
+		oldText.Reset()
+		newText.Reset()
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(patch, "\r\n", "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "*** Add File: "):
+			flush()
+			cur = &FileChange{Path: strings.TrimSpace(strings.TrimPrefix(line, "*** Add File: ")), Op: FileOpWrite}
+		case strings.HasPrefix(line, "*** Update File: "):
+			flush()
+			cur = &FileChange{Path: strings.TrimSpace(strings.TrimPrefix(line, "*** Update File: ")), Op: FileOpEdit}
+		case strings.HasPrefix(line, "*** Delete File: "):
+			flush()
+			cur = &FileChange{Path: strings.TrimSpace(strings.TrimPrefix(line, "*** Delete File: ")), Op: FileOpDelete}
+		case strings.HasPrefix(line, "*** Move to: "):
+			if cur != nil {
+				cur.NewPath = strings.TrimSpace(strings.TrimPrefix(line, "*** Move to: "))
+				cur.Op = FileOpMove
+			}
+		case strings.HasPrefix(line, "*** "), cur == nil, cur.Op == FileOpDelete:
+			// Envelope markers, and lines outside a file section
+		case strings.HasPrefix(line, "@@"):
+			// Hunks are separate snippets; keep them apart in the diff
+			if oldText.Len() > 0 || newText.Len() > 0 {
+				oldText.WriteString("⋯\n")
+				newText.WriteString("⋯\n")
+			}
+		case strings.HasPrefix(line, "+"):
+			newText.WriteString(line[1:] + "\n")
+		case strings.HasPrefix(line, "-"):
+			oldText.WriteString(line[1:] + "\n")
+		default:
+			line = strings.TrimPrefix(line, " ")
+			oldText.WriteString(line + "\n")
+			newText.WriteString(line + "\n")
+		}
+	}
+	flush()
+	return changes
+}
+
+// isShellTool reports whether a tool runs shell commands. Command arrays
+// (Codex exec calls) are always treated as shell commands.
+func isShellTool(lowerName string, command any) bool {
+	if _, ok := command.([]any); ok {
+		return true
+	}
+	return nameContains(lowerName, "bash", "shell", "terminal", "exec", "run_command", "run_cmd")
+}
+
+// commandString returns a shell command from a string or argv array. Arrays
+// of the form [sh, -c, script] yield the script.
+func commandString(v any) string {
+	switch c := v.(type) {
+	case string:
+		return c
+	case []any:
+		args := make([]string, 0, len(c))
+		for _, a := range c {
+			if s, ok := a.(string); ok {
+				args = append(args, s)
+			}
+		}
+		if n := len(args); n >= 3 && (args[n-2] == "-c" || args[n-2] == "-lc") {
+			return args[n-1]
+		}
+		return strings.Join(args, " ")
+	}
+	return ""
+}
+
+var (
+	// shellSeparator splits a script into simple commands.
+	shellSeparator = regexp.MustCompile(`&&|\|\||[;|\n]`)
+	// shellRedirect finds output redirections to a file.
+	shellRedirect = regexp.MustCompile(`(?:^|[^0-9&<>])>>?\s*([^\s;&|<>()]+)`)
+	// heredocStart finds a here-document and captures its delimiter.
+	heredocStart = regexp.MustCompile(`<<-?\s*['"]?(\w+)['"]?`)
+)
+
+// shellFileChanges infers file changes from a shell command. This is a
+// heuristic: only common file commands and redirections are recognized, and
+// no diff is available.
+func shellFileChanges(cmd string) []FileChange {
+	if strings.Contains(cmd, patchBegin) {
+		return parsePatchChanges(cmd)
+	}
+
+	var changes []FileChange
+	add := func(op FileOp, path, newPath string) {
+		path = strings.Trim(path, `'"`)
+		if path == "" || path == "/dev/null" || strings.HasPrefix(path, "/dev/") {
+			return
+		}
+		changes = append(changes, FileChange{Path: path, NewPath: newPath, Op: op, Command: strings.TrimSpace(cmd)})
+	}
+
+	for _, segment := range shellSeparator.Split(stripHeredocs(cmd), -1) {
+		for _, m := range shellRedirect.FindAllStringSubmatch(segment, -1) {
+			add(FileOpWrite, m[1], "")
+		}
+		args := shellArgs(segment)
+		if len(args) == 0 {
+			continue
+		}
+		switch args[0] {
+		case "rm", "unlink":
+			for _, a := range args[1:] {
+				add(FileOpDelete, a, "")
+			}
+		case "mv", "cp":
+			if len(args) < 3 {
+				continue
+			}
+			op := FileOpMove
+			if args[0] == "cp" {
+				op = FileOpCopy
+			}
+			dst := strings.Trim(args[len(args)-1], `'"`)
+			for _, a := range args[1 : len(args)-1] {
+				add(op, a, dst)
+			}
+		case "touch", "tee":
+			for _, a := range args[1:] {
+				add(FileOpWrite, a, "")
+			}
+		case "sed", "perl":
+			if len(args) < 3 || !hasInPlaceFlag(args) {
+				continue
+			}
+			add(FileOpEdit, args[len(args)-1], "")
+		}
+	}
+	return changes
+}
+
+// shellArgs splits a simple command into its words, dropping flags,
+// redirections, leading sudo/env assignments and everything after a
+// redirection operator.
+func shellArgs(segment string) []string {
+	var args []string
+	for _, f := range strings.Fields(segment) {
+		if strings.ContainsAny(f, "<>") {
+			break
+		}
+		if len(args) == 0 && (f == "sudo" || f == "command" || strings.Contains(f, "=")) {
+			continue
+		}
+		if len(args) > 0 && strings.HasPrefix(f, "-") && args[0] != "sed" && args[0] != "perl" {
+			continue
+		}
+		args = append(args, f)
+	}
+	return args
+}
+
+// hasInPlaceFlag reports whether sed or perl arguments edit in place.
+func hasInPlaceFlag(args []string) bool {
+	for _, a := range args[1:] {
+		if strings.HasPrefix(a, "-i") || (strings.HasPrefix(a, "-") && !strings.HasPrefix(a, "--") && strings.Contains(a, "i")) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripHeredocs removes here-document bodies so their contents aren't
+// mistaken for commands.
+func stripHeredocs(cmd string) string {
+	lines := strings.Split(cmd, "\n")
+	out := make([]string, 0, len(lines))
+	delim := ""
+	for _, line := range lines {
+		if delim != "" {
+			if strings.TrimSpace(line) == delim {
+				delim = ""
+			}
+			continue
+		}
+		if m := heredocStart.FindStringSubmatch(line); m != nil {
+			delim = m[1]
+			line = heredocStart.ReplaceAllString(line, "")
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
+
+// lookupString returns the first string value present under one of keys.
+func lookupString(data map[string]any, keys []string) (string, bool) {
+	for _, k := range keys {
+		if s, ok := data[k].(string); ok {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+// firstString returns the first non-empty string value under one of keys.
+func firstString(data map[string]any, keys []string) string {
+	for _, k := range keys {
+		if s, ok := data[k].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// nameContains reports whether a tool name contains any of the substrings.
+func nameContains(s string, subs ...string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// firstLine returns the first non-blank line of s, trimmed.
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
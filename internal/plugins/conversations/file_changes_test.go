@@ -0,0 +1,151 @@
+package conversations
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+func TestToolFileChanges(t *testing.T) {
+	tests := []struct {
+		name  string
+		tool  string
+		input string
+		want  []string // "op path [newpath] +added -removed"
+	}{
+		{"claude edit", "Edit", `{"file_path":"/p/a.go","old_string":"x := 1","new_string":"x := 2"}`, []string{"edit /p/a.go +1 -1"}},
+		{"claude write", "Write", `{"file_path":"b.txt","content":"one\ntwo\n"}`, []string{"write b.txt +2 -0"}},
+		{"claude read", "Read", `{"file_path":"b.txt"}`, nil},
+		{"multi edit", "MultiEdit", `{"file_path":"c.go","edits":[{"old_string":"a","new_string":"b"},{"old_string":"c","new_string":""}]}`, []string{"edit c.go +1 -1", "edit c.go +0 -1"}},
+		{"gemini replace", "replace", `{"file_path":"d.go","old_string":"a","new_string":"b\nc"}`, []string{"edit d.go +2 -1"}},
+		{"cursor edit", "edit_file", `{"target_file":"e.go","code_edit":"package e"}`, []string{"write e.go +1 -0"}},
+		{"kiro str_replace", "fs_write", `{"command":"str_replace","path":"f.go","old_str":"a","new_str":"b"}`, []string{"edit f.go +1 -1"}},
+		{"grep with path", "Grep", `{"pattern":"x","path":"src"}`, nil},
+		{"codex patch", "apply_patch", "*** Begin Patch\n*** Update File: g.go\n@@ func main\n ctx\n-old\n+new\n*** Add File: h.go\n+package h\n*** Delete File: i.go\n*** Update File: j.go\n*** Move to: k.go\n*** End Patch", []string{"edit g.go +1 -1", "write h.go +1 -0", "delete i.go +0 -0", "move j.go k.go +0 -0"}},
+		{"codex exec", "shell", `{"command":["bash","-lc","rm -f old.log && mv a.txt b.txt"]}`, []string{"delete old.log +0 -0", "move a.txt b.txt +0 -0"}},
+		{"bash redirects", "Bash", `{"command":"go test ./... 2>&1 | tee out.txt; echo hi > notes.md >/dev/null"}`, []string{"write out.txt +0 -0", "write notes.md +0 -0"}},
+		{"bash sed", "Bash", `{"command":"sed -i 's/a/b/' main.go && sed 's/a/b/' other.go"}`, []string{"edit main.go +0 -0"}},
+		{"bash heredoc", "Bash", "{\"command\":\"cat > gen.go <<'EOF'\\nrm -rf /\\nEOF\"}", []string{"write gen.go +0 -0"}},
+		{"bash read only", "Bash", `{"command":"ls -la && git status"}`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, c := range toolFileChanges(tt.tool, tt.input) {
+				a, r := c.DiffStats()
+				s := string(c.Op) + " " + c.Path
+				if c.NewPath != "" {
+					s += " " + c.NewPath
+				}
+				got = append(got, fmt.Sprintf("%s +%d -%d", s, a, r))
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractFileChanges(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	messages := []adapter.Message{
+		{ID: "u1", Role: "user", Content: "fix the bug"},
+		{ID: "a1", Role: "assistant", Timestamp: ts, ContentBlocks: []adapter.ContentBlock{
+			{Type: "text", Text: "The nil check is missing.\nLet me add it."},
+			{Type: "tool_use", ToolUseID: "t1", ToolName: "Edit", ToolInput: `{"file_path":"a.go","old_string":"x","new_string":"y"}`},
+			{Type: "tool_use", ToolUseID: "t2", ToolName: "Write", ToolInput: `{"file_path":"b.go","content":"z"}`},
+		}},
+		{ID: "u2", Role: "user", ContentBlocks: []adapter.ContentBlock{
+			{Type: "tool_result", ToolUseID: "t1"},
+			{Type: "tool_result", ToolUseID: "t2", IsError: true},
+		}},
+		// Adapters without content blocks only fill ToolUses
+		{ID: "a2", Role: "assistant", Content: "Cleaning up", ToolUses: []adapter.ToolUse{
+			{ID: "t3", Name: "Bash", Input: `{"command":"rm tmp.txt"}`},
+		}},
+	}
+
+	changes := ExtractFileChanges(messages)
+	if len(changes) != 3 {
+		t.Fatalf("got %d changes: %+v", len(changes), changes)
+	}
+	if c := changes[0]; c.Path != "a.go" || c.Reason != "The nil check is missing." || c.MessageIndex != 1 || c.Failed || !c.Timestamp.Equal(ts) {
+		t.Errorf("first change = %+v", c)
+	}
+	if !changes[1].Failed {
+		t.Error("write with an error result should be marked failed")
+	}
+	if c := changes[2]; c.Op != FileOpDelete || c.Reason != "Cleaning up" || c.Command != "rm tmp.txt" {
+		t.Errorf("shell change = %+v", c)
+	}
+}
+
+func TestChangesView(t *testing.T) {
+	p := New()
+	p.ctx = &plugin.Context{WorkDir: "/proj"}
+	p.width, p.height = 150, 30
+	p.activePane = PaneMessages
+	p.sessions = []adapter.Session{{ID: "s1", Name: "Test", AdapterID: "mock", UpdatedAt: time.Now()}}
+	p.selectedSession = "s1"
+	messages := []adapter.Message{
+		{ID: "u0", Role: "user", Content: "start"},
+		{ID: "a0", Role: "assistant", ToolUses: []adapter.ToolUse{
+			{ID: "t0", Name: "Write", Input: `{"file_path":"/proj/old.go","content":"package old"}`},
+		}},
+		{ID: "u1", Role: "user", Content: "rename it"},
+		{ID: "a1", Role: "assistant", ToolUses: []adapter.ToolUse{
+			{ID: "t1", Name: "Edit", Input: `{"file_path":"/proj/pkg/a.go","old_string":"Foo","new_string":"Bar"}`},
+			{ID: "t2", Name: "Edit", Input: `{"file_path":"/elsewhere/b.go","old_string":"a","new_string":"b"}`},
+		}},
+	}
+	p.adapters = map[string]adapter.Adapter{"mock": &callAdapter{messages: map[string][]adapter.Message{"s1": messages}}}
+	// Only the latest page is loaded in the message list
+	p.messages = messages[2:]
+	p.turns = GroupMessagesIntoTurns(p.messages)
+
+	_, cmd := p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'D'}})
+	if !p.changesMode || p.FocusContext() != "conversations-changes" || cmd == nil {
+		t.Fatal("D should open the changes view")
+	}
+	_, _ = p.Update(cmd())
+	if len(p.changes) != 3 {
+		t.Fatalf("got %d changes, want all 3 from the full session", len(p.changes))
+	}
+
+	// Changes outside the loaded page have no turn to show
+	if idx := p.changeTurnIndex(p.selectedChange()); idx != -1 {
+		t.Errorf("turn index = %d, want -1 for an unloaded message", idx)
+	}
+	_, _ = p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	view := ansi.Strip(p.renderChangesPaneContent(100, 20))
+	for _, want := range []string{"3 changes to 3 files, +3 -2", "/proj/pkg/a.go", "turn 2 · Edit", "Bar"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+
+	// o opens project files in the file browser, relative to the workdir
+	_, cmd = p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'o'}})
+	if cmd == nil {
+		t.Fatal("o should navigate to the file")
+	}
+	if path, ok := p.changeProjectPath(p.selectedChange()); !ok || path != "pkg/a.go" {
+		t.Errorf("project path = %q, %v", path, ok)
+	}
+	_, _ = p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	if _, ok := p.changeProjectPath(p.selectedChange()); ok {
+		t.Error("files outside the workdir should not resolve to a project path")
+	}
+
+	// enter shows the turn that made the change
+	_, _ = p.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if p.changesMode || !p.detailMode || p.detailTurn == nil || p.detailTurn.Role != "assistant" {
+		t.Error("enter should open the change's turn")
+	}
+}
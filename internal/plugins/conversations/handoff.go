@@ -0,0 +1,442 @@
+package conversations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/markdown"
+	"github.com/marcus/sidecar/internal/plugins/workspace"
+)
+
+const (
+	// handoffSummaryTimeout bounds how long the summary command may run.
+	handoffSummaryTimeout = 2 * time.Minute
+	// handoffMaxTurns is the number of most recent turns in the built-in summary.
+	handoffMaxTurns = 30
+	// handoffMaxGoal caps the original goal, in runes.
+	handoffMaxGoal = 2000
+	// handoffMaxDiff caps the uncommitted diff, in bytes.
+	handoffMaxDiff = 30 * 1024
+	// handoffMaxTranscript caps the transcript sent to the summary command, in bytes.
+	handoffMaxTranscript = 200 * 1024
+)
+
+// handoffSummaryInstructions precede the transcript sent to the summary command.
+const handoffSummaryInstructions = "Summarize the coding agent session below for another agent that will continue the work. " +
+	"List what was done, decisions made and why, what was tried and failed, and what remains. " +
+	"Be concise and concrete. Output only the summary.\n\n"
+
+// HandoffInput is the source material for a handoff context package.
+type HandoffInput struct {
+	Session  *adapter.Session
+	Messages []adapter.Message
+	Summary  string // summary command output; replaces the built-in turn summary
+	Diff     string // uncommitted changes in the session's directory
+	WorkDir  string // paths under it are shown relative
+}
+
+// BuildHandoffContext assembles the initial prompt for an agent taking over
+// a session: the original goal, progress so far, files touched, uncommitted
+// changes and open TODOs.
+func BuildHandoffContext(in HandoffInput) string {
+	var sb strings.Builder
+
+	from := "another coding agent"
+	if in.Session != nil {
+		name := in.Session.Name
+		if name == "" {
+			name = in.Session.ID
+		}
+		agent := in.Session.AdapterName
+		if agent == "" {
+			agent = in.Session.AdapterID
+		}
+		from = fmt.Sprintf("a %s session (%q)", agent, name)
+	}
+	sb.WriteString("You are taking over a task from " + from + ". ")
+	sb.WriteString("The context below was generated from that session. ")
+	sb.WriteString("Check the current state of the files before making changes, then continue the work.\n")
+
+	if goal := handoffGoal(in.Messages); goal != "" {
+		sb.WriteString("\n## Original goal\n\n")
+		sb.WriteString(goal + "\n")
+	}
+
+	sb.WriteString("\n## Progress so far\n\n")
+	if summary := strings.TrimSpace(in.Summary); summary != "" {
+		sb.WriteString(summary + "\n")
+	} else {
+		sb.WriteString(handoffTurnSummary(GroupMessagesIntoTurns(in.Messages)))
+	}
+
+	if files := handoffFiles(in.Messages, in.WorkDir); len(files) > 0 {
+		sb.WriteString("\n## Files touched\n\n")
+		for _, f := range files {
+			sb.WriteString("- " + f + "\n")
+		}
+	}
+
+	if diff := strings.TrimSpace(in.Diff); diff != "" {
+		if len(diff) > handoffMaxDiff {
+			n := handoffMaxDiff
+			for n > 0 && !utf8.RuneStart(diff[n]) {
+				n--
+			}
+			diff = diff[:n] + "\n[diff truncated]"
+		}
+		sb.WriteString("\n## Uncommitted changes\n\n```diff\n")
+		sb.WriteString(diff + "\n```\n")
+	}
+
+	if todos := handoffTodos(in.Messages); len(todos) > 0 {
+		sb.WriteString("\n## Open TODOs\n\n")
+		for _, t := range todos {
+			sb.WriteString("- " + t + "\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// handoffGoal returns the first user message with real content.
+func handoffGoal(messages []adapter.Message) string {
+	for _, msg := range messages {
+		if msg.Role != "user" {
+			continue
+		}
+		goal := stripXMLTags(msg.Content)
+		if goal == "" || strings.HasPrefix(msg.Content, "[") && strings.HasSuffix(msg.Content, "tool result(s)]") {
+			continue
+		}
+		if runes := []rune(goal); len(runes) > handoffMaxGoal {
+			goal = string(runes[:handoffMaxGoal]) + "..."
+		}
+		return goal
+	}
+	return ""
+}
+
+// handoffTurnSummary condenses turns to one line each: user requests and
+// assistant replies with the tools they called.
+func handoffTurnSummary(turns []Turn) string {
+	if len(turns) == 0 {
+		return "No messages.\n"
+	}
+
+	var sb strings.Builder
+	if omitted := len(turns) - handoffMaxTurns; omitted > 0 {
+		sb.WriteString(fmt.Sprintf("(%d earlier turns omitted)\n", omitted))
+		turns = turns[omitted:]
+	}
+	for _, t := range turns {
+		preview := strings.Join(strings.Fields(t.Preview(200)), " ")
+		tools := handoffToolCounts(t.Messages)
+		if preview == "" && tools == "" {
+			continue
+		}
+		role := t.Role
+		if role != "" {
+			role = strings.ToUpper(role[:1]) + role[1:]
+		}
+		line := "- " + role + ": " + preview
+		if tools != "" {
+			if preview == "" {
+				line += "[" + tools + "]"
+			} else {
+				line += " [" + tools + "]"
+			}
+		}
+		sb.WriteString(line + "\n")
+	}
+	return sb.String()
+}
+
+// handoffToolCounts returns "Edit ×2, Bash" style counts of the tools called.
+func handoffToolCounts(messages []adapter.Message) string {
+	counts := make(map[string]int)
+	var order []string
+	for _, msg := range messages {
+		for _, tc := range handoffToolCalls(msg) {
+			if counts[tc.name] == 0 {
+				order = append(order, tc.name)
+			}
+			counts[tc.name]++
+		}
+	}
+	parts := make([]string, len(order))
+	for i, name := range order {
+		parts[i] = name
+		if n := counts[name]; n > 1 {
+			parts[i] = fmt.Sprintf("%s ×%d", name, n)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// handoffToolCall is a tool name and its JSON input.
+type handoffToolCall struct {
+	name, input string
+}
+
+// handoffToolCalls returns the tool calls of a message from its content
+// blocks, falling back to ToolUses for adapters that don't produce blocks.
+func handoffToolCalls(msg adapter.Message) []handoffToolCall {
+	var calls []handoffToolCall
+	for _, b := range msg.ContentBlocks {
+		if b.Type == "tool_use" && b.ToolName != "" {
+			calls = append(calls, handoffToolCall{b.ToolName, b.ToolInput})
+		}
+	}
+	if len(calls) > 0 {
+		return calls
+	}
+	for _, tu := range msg.ToolUses {
+		if tu.Name != "" {
+			calls = append(calls, handoffToolCall{tu.Name, tu.Input})
+		}
+	}
+	return calls
+}
+
+// handoffFiles lists the files changed by successful tool calls, with the
+// operations applied to each.
+func handoffFiles(messages []adapter.Message, workDir string) []string {
+	ops := make(map[string][]string)
+	var order []string
+	add := func(path string, op FileOp) {
+		if _, ok := ops[path]; !ok {
+			order = append(order, path)
+		}
+		for _, existing := range ops[path] {
+			if existing == string(op) {
+				return
+			}
+		}
+		ops[path] = append(ops[path], string(op))
+	}
+	for _, c := range ExtractFileChanges(messages) {
+		if c.Failed || c.Path == "" {
+			continue
+		}
+		path := handoffRelPath(c.Path, workDir)
+		if c.NewPath != "" {
+			path += " → " + handoffRelPath(c.NewPath, workDir)
+		}
+		add(path, c.Op)
+	}
+
+	files := make([]string, len(order))
+	for i, path := range order {
+		files[i] = fmt.Sprintf("%s (%s)", path, strings.Join(ops[path], ", "))
+	}
+	return files
+}
+
+// handoffRelPath returns path relative to workDir when it lies under it.
+func handoffRelPath(path, workDir string) string {
+	if workDir == "" || !filepath.IsAbs(path) {
+		return path
+	}
+	if rel, err := filepath.Rel(workDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// handoffTodos returns the unfinished items of the last todo list the agent
+// wrote: Claude Code's TodoWrite ({"todos":[{content,status}]}) or Codex's
+// update_plan ({"plan":[{step,status}]}).
+func handoffTodos(messages []adapter.Message) []string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		calls := handoffToolCalls(messages[i])
+		for j := len(calls) - 1; j >= 0; j-- {
+			lower := strings.ToLower(calls[j].name)
+			if !nameContains(lower, "todo", "plan") {
+				continue
+			}
+			var data map[string]any
+			if err := json.Unmarshal([]byte(calls[j].input), &data); err != nil {
+				continue
+			}
+			items, ok := data["todos"].([]any)
+			if !ok {
+				if items, ok = data["plan"].([]any); !ok {
+					continue
+				}
+			}
+			var open []string
+			for _, item := range items {
+				m, ok := item.(map[string]any)
+				if !ok {
+					continue
+				}
+				text := firstString(m, []string{"content", "step", "text", "title"})
+				status := firstString(m, []string{"status", "state"})
+				if text == "" || status == "completed" || status == "done" || status == "cancelled" {
+					continue
+				}
+				if status != "" && status != "pending" {
+					text += " (" + strings.ReplaceAll(status, "_", " ") + ")"
+				}
+				open = append(open, text)
+			}
+			return open
+		}
+	}
+	return nil
+}
+
+// SummarizeForHandoff runs command through sh in workDir with instructions
+// and the session as Markdown on stdin, and returns its output.
+func SummarizeForHandoff(ctx context.Context, workDir, command string, session *adapter.Session, messages []adapter.Message) (string, error) {
+	transcript := ExportSessionAsMarkdown(session, messages, nil)
+	if len(transcript) > handoffMaxTranscript {
+		// Keep the end of the session, where the current state is
+		start := len(transcript) - handoffMaxTranscript
+		for start < len(transcript) && !utf8.RuneStart(transcript[start]) {
+			start++
+		}
+		transcript = "[earlier messages truncated]\n\n" + transcript[start:]
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = workDir
+	cmd.Stdin = strings.NewReader(handoffSummaryInstructions + transcript)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("summary command timed out")
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", errors.New(msg)
+		}
+		return "", err
+	}
+
+	summary := markdown.StripCodeFence(stdout.String())
+	if summary == "" {
+		return "", fmt.Errorf("summary command produced no output")
+	}
+	return summary, nil
+}
+
+// handoffDiff returns the uncommitted changes in dir, or "" if there are
+// none or dir isn't a git repository.
+func handoffDiff(dir string) string {
+	out, err := exec.Command("git", "-C", dir, "diff", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return string(out)
+}
+
+// handoffSummaryCommand returns the configured summary command, if any.
+func (p *Plugin) handoffSummaryCommand() string {
+	if p.ctx == nil || p.ctx.Config == nil {
+		return ""
+	}
+	return strings.TrimSpace(p.ctx.Config.Plugins.Conversations.HandoffSummaryCommand)
+}
+
+// prepareHandoff builds the handoff context for session in the background
+// and returns a HandoffReadyMsg carrying the workspace message to send.
+func (p *Plugin) prepareHandoff(session adapter.Session, msg workspace.ResumeConversationMsg) tea.Cmd {
+	a := p.adapterForSession(session.ID)
+	if a == nil {
+		a = p.adapters[session.AdapterID]
+	}
+	var epoch uint64
+	workDir := ""
+	if p.ctx != nil {
+		epoch = p.ctx.Epoch
+		workDir = p.ctx.WorkDir
+	}
+	command := p.handoffSummaryCommand()
+
+	return func() tea.Msg {
+		if a == nil {
+			return HandoffReadyMsg{Epoch: epoch, Err: fmt.Errorf("no adapter for session")}
+		}
+		messages, err := a.Messages(session.ID)
+		if err != nil {
+			return HandoffReadyMsg{Epoch: epoch, Err: err}
+		}
+
+		// The session's own worktree holds its uncommitted work
+		dir := workDir
+		if session.WorktreePath != "" {
+			dir = session.WorktreePath
+		}
+
+		var warning, summary string
+		if command != "" {
+			ctx, cancel := context.WithTimeout(context.Background(), handoffSummaryTimeout)
+			summary, err = SummarizeForHandoff(ctx, dir, command, &session, messages)
+			cancel()
+			if err != nil {
+				warning = "Summary command failed, using turn summary: " + err.Error()
+			}
+		}
+
+		msg.HandoffPrompt = BuildHandoffContext(HandoffInput{
+			Session:  &session,
+			Messages: messages,
+			Summary:  summary,
+			Diff:     handoffDiff(dir),
+			WorkDir:  dir,
+		})
+		return HandoffReadyMsg{Epoch: epoch, Resume: msg, Warning: warning}
+	}
+}
+
+// HandoffReadyMsg is sent when a handoff context package has been built.
+type HandoffReadyMsg struct {
+	Epoch   uint64
+	Resume  workspace.ResumeConversationMsg
+	Warning string // summary command failure; the built-in summary was used
+	Err     error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m HandoffReadyMsg) GetEpoch() uint64 { return m.Epoch }
+
+// sortedHandoffWorktrees returns the worktree paths offered as handoff
+// targets, main worktree first, with display labels.
+func sortedHandoffWorktrees(workDir string, paths []string, names map[string]string) ([]string, []string) {
+	seen := make(map[string]bool)
+	var out []string
+	for _, path := range append([]string{workDir}, paths...) {
+		if path == "" || seen[filepath.Clean(path)] {
+			continue
+		}
+		seen[filepath.Clean(path)] = true
+		out = append(out, path)
+	}
+	if len(out) > 1 {
+		rest := out[1:]
+		sort.Strings(rest)
+	}
+	labels := make([]string, len(out))
+	for i, path := range out {
+		label := names[path]
+		if label == "" {
+			label = filepath.Base(path)
+		}
+		labels[i] = label
+	}
+	return out, labels
+}
@@ -0,0 +1,146 @@
+package conversations
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/plugins/workspace"
+)
+
+func handoffMessages() []adapter.Message {
+	return []adapter.Message{
+		{ID: "u1", Role: "user", Content: "<user_query>Add rate limiting to the API</user_query>"},
+		{ID: "a1", Role: "assistant", Content: "Adding a limiter.", ToolUses: []adapter.ToolUse{
+			{ID: "t1", Name: "Edit", Input: `{"file_path":"/proj/api/limit.go","old_string":"a","new_string":"b"}`},
+			{ID: "t2", Name: "Edit", Input: `{"file_path":"/proj/api/limit.go","old_string":"b","new_string":"c"}`},
+			{ID: "t3", Name: "Bash", Input: `{"command":"go test ./..."}`},
+		}},
+		{ID: "u2", Role: "user", Content: "[1 tool result(s)]"},
+		{ID: "a2", Role: "assistant", ContentBlocks: []adapter.ContentBlock{
+			{Type: "tool_use", ToolUseID: "t4", ToolName: "TodoWrite", ToolInput: `{"todos":[` +
+				`{"content":"Add limiter","status":"completed"},` +
+				`{"content":"Wire middleware","status":"in_progress"},` +
+				`{"content":"Document config","status":"pending"}]}`},
+			{Type: "tool_use", ToolUseID: "t5", ToolName: "Write", ToolInput: `{"file_path":"/proj/api/broken.go","content":"x"}`},
+			{Type: "tool_result", ToolUseID: "t5", IsError: true},
+		}},
+	}
+}
+
+func TestBuildHandoffContext(t *testing.T) {
+	session := &adapter.Session{ID: "ses-1", Name: "Rate limits", AdapterName: "Claude Code"}
+	got := BuildHandoffContext(HandoffInput{
+		Session:  session,
+		Messages: handoffMessages(),
+		Diff:     "diff --git a/api/limit.go b/api/limit.go\n+c\n",
+		WorkDir:  "/proj",
+	})
+
+	for _, want := range []string{
+		`from a Claude Code session ("Rate limits")`,
+		"## Original goal\n\nAdd rate limiting to the API\n",
+		"- Assistant: Adding a limiter. [Edit ×2, Bash]",
+		"[TodoWrite, Write]",
+		"## Files touched\n\n- api/limit.go (edit)\n\n",
+		"```diff\ndiff --git a/api/limit.go b/api/limit.go\n+c\n```",
+		"## Open TODOs\n\n- Wire middleware (in progress)\n- Document config\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("handoff context missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "broken.go") || strings.Contains(got, "Add limiter") {
+		t.Errorf("failed changes and completed TODOs should be left out:\n%s", got)
+	}
+
+	// A summary replaces the turn list
+	got = BuildHandoffContext(HandoffInput{Session: session, Messages: handoffMessages(), Summary: "Limiter added; middleware next."})
+	if !strings.Contains(got, "## Progress so far\n\nLimiter added; middleware next.\n") || strings.Contains(got, "- Assistant:") {
+		t.Errorf("summary not used:\n%s", got)
+	}
+}
+
+func TestHandoffTruncatesOnRuneBoundary(t *testing.T) {
+	got := BuildHandoffContext(HandoffInput{Diff: "+" + strings.Repeat("é", handoffMaxDiff)})
+	if !strings.Contains(got, "[diff truncated]") || !utf8.ValidString(got) {
+		t.Error("truncated diff should end on a whole character")
+	}
+
+	out := filepath.Join(t.TempDir(), "stdin.txt")
+	t.Setenv("OUT", out)
+	messages := []adapter.Message{{ID: "u1", Role: "user", Content: strings.Repeat("日本", handoffMaxTranscript/3)}}
+	summary, err := SummarizeForHandoff(context.Background(), t.TempDir(), `cat > "$OUT"; printf '%s\n' '`+"```"+`' done '`+"```"+`'`, &adapter.Session{ID: "s"}, messages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary != "done" {
+		t.Errorf("summary = %q, want fences stripped", summary)
+	}
+	stdin, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(stdin), "[earlier messages truncated]") || !utf8.ValidString(string(stdin)) {
+		t.Error("truncated transcript should start on a whole character")
+	}
+}
+
+func TestHandoffTodosCodexPlan(t *testing.T) {
+	msgs := []adapter.Message{{Role: "assistant", ToolUses: []adapter.ToolUse{{
+		Name:  "update_plan",
+		Input: `{"plan":[{"step":"Read code","status":"completed"},{"step":"Fix bug","status":"pending"}]}`,
+	}}}}
+	if got := handoffTodos(msgs); len(got) != 1 || got[0] != "Fix bug" {
+		t.Errorf("handoffTodos = %v", got)
+	}
+}
+
+func TestHandoffModalFlow(t *testing.T) {
+	p := New()
+	p.ctx = &plugin.Context{WorkDir: "/proj"}
+	p.adapters = map[string]adapter.Adapter{"mock": &messageAdapter{}}
+	p.sessions = []adapter.Session{{ID: "s1", Name: "Fix it", AdapterID: "mock", AdapterName: "Mock"}}
+	p.cachedWorktreePaths = []string{"/proj", "/proj-feature"}
+	p.cachedWorktreeNames = map[string]string{"/proj-feature": "feature"}
+
+	// Sessions without a resume command default to a handoff
+	p.openResumeModal()
+	if !p.showResumeModal || p.resumeType != resumeTypeHandoffNew {
+		t.Fatalf("modal open = %v, type = %d", p.showResumeModal, p.resumeType)
+	}
+	if len(p.resumeWorktreePaths) != 2 || p.resumeWorktreeLabels[1] != "feature" {
+		t.Errorf("worktrees = %v %v", p.resumeWorktreePaths, p.resumeWorktreeLabels)
+	}
+
+	p.resumeType = resumeTypeHandoffExisting
+	p.resumeWorktreeIdx = 1
+	p.resumeAgentIdx = 1 // Codex
+	cmd := p.executeResume()
+	if cmd == nil || p.showResumeModal {
+		t.Fatal("expected the modal to close and a handoff to start")
+	}
+
+	var ready HandoffReadyMsg
+	for _, c := range cmd().(tea.BatchMsg) {
+		if m, ok := c().(HandoffReadyMsg); ok {
+			ready = m
+		}
+	}
+	if ready.Err != nil {
+		t.Fatal(ready.Err)
+	}
+	r := ready.Resume
+	if r.Type != "existing" || r.WorktreePath != "/proj-feature" || r.AgentType != workspace.AgentCodex {
+		t.Errorf("resume msg = %+v", r)
+	}
+	if !strings.Contains(r.HandoffPrompt, "## Original goal\n\nhi\n") {
+		t.Errorf("handoff prompt = %q", r.HandoffPrompt)
+	}
+}
@@ -13,8 +13,22 @@ func (p *Plugin) handleMouse(msg tea.MouseMsg) (*Plugin, tea.Cmd) {
 		cmd := p.handleResumeModalMouse(msg)
 		return p, cmd
 	}
+	if p.showAnnotateModal {
+		return p, p.handleAnnotateModalMouse(msg)
+	}
 
 	action := p.mouseHandler.HandleMouse(msg)
+	if p.view == ViewToolAnalytics {
+		return p, p.handleToolAnalyticsMouse(action)
+	}
+	if p.view == ViewCompare {
+		switch action.Type {
+		case mouse.ActionScrollUp, mouse.ActionScrollDown:
+			maxScroll := max(len(p.compareLines)-(p.height-2), 0)
+			p.compareScroll = max(min(p.compareScroll+action.Delta, maxScroll), 0)
+		}
+		return p, nil
+	}
 
 	switch action.Type {
 	case mouse.ActionClick:
@@ -176,6 +190,10 @@ func (p *Plugin) handleMouseScroll(action mouse.MouseAction) (*Plugin, tea.Cmd)
 		if action.X < p.sidebarWidth+2 {
 			return p.scrollSidebar(action.Delta)
 		}
+		if p.changesMode {
+			p.changeDiffScroll = max(p.changeDiffScroll+action.Delta, 0)
+			return p, nil
+		}
 		if p.detailMode {
 			return p.scrollDetailPane(action.Delta)
 		}
@@ -187,6 +205,10 @@ func (p *Plugin) handleMouseScroll(action mouse.MouseAction) (*Plugin, tea.Cmd)
 		return p.scrollSidebar(action.Delta)
 
 	case regionMainPane, regionTurnItem, regionMessageItem:
+		if p.changesMode {
+			p.changeDiffScroll = max(p.changeDiffScroll+action.Delta, 0)
+			return p, nil
+		}
 		if p.detailMode {
 			return p.scrollDetailPane(action.Delta)
 		}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"io"
 	"log"
-	"sort"
 	"sync"
 	"time"
 
@@ -60,6 +59,8 @@ const (
 	regionMessageItem = "message-item" // Conversation flow: click to select (Data: msg index)
 	regionToolExpand  = "tool-expand"  // Conversation flow: toggle tool output (Data: tool_use_id)
 	regionShowMore    = "show-more"    // Conversation flow: expand long message (Data: msg ID)
+	regionToolRow     = "tool-row"     // Tool analytics: per-tool table row (Data: tool index)
+	regionToolCall    = "tool-call"    // Tool analytics: call row (Data: call index)
 )
 
 // View represents the current view mode.
@@ -70,6 +71,8 @@ const (
 	ViewMessages
 	ViewAnalytics
 	ViewMessageDetail
+	ViewToolAnalytics
+	ViewCompare
 )
 
 // FocusPane represents which pane is active in two-pane mode.
@@ -105,6 +108,15 @@ type Plugin struct {
 	hasMoreSessions bool // displayedCount < len(sessions) (td-7198a5)
 	loadingAdapters bool // true while adapter batches are still arriving (td-7198a5)
 
+	// Sub-agent tree, rebuilt by sortAndIndexSessions
+	subAgentTotals    map[string]subAgentTotals // parent ID -> aggregated sub-agents
+	subAgentCalls     map[string]string         // spawning tool call ID -> sub-agent ID
+	expandedSubAgents map[string]bool           // parent ID -> sub-agents shown
+
+	// Session archive
+	archiver    adapter.Archiver // nil when archiving is disabled
+	lastArchive time.Time
+
 	// Message view state
 	selectedSession string
 	loadedSession   string // sessionID that p.messages currently represent
@@ -133,10 +145,39 @@ type Plugin struct {
 	detailTurn   *Turn // turn being viewed in detail
 	detailScroll int
 
+	// File changes view state
+	changesMode      bool         // true when the main pane shows the files-touched timeline
+	changes          []FileChange // file changes extracted from the session's tool calls
+	changesLoading   bool
+	changesErr       error
+	changeCursor     int
+	changeScroll     int
+	changeDiffScroll int
+
 	// Analytics view state
 	analyticsScrollOff int
 	analyticsLines     []string // pre-rendered lines for scrolling
 
+	// Tool analytics view state
+	toolScope      string // session ID, or "" for all sessions
+	toolReport     *toolReport
+	toolFilters    SearchFilters
+	toolQuery      string
+	toolQueryMode  bool // editing the filter query
+	toolQueryInput string
+	toolQueryErr   error
+	toolFocus      int
+	toolCursor     int // selected tool
+	toolCallCursor int // selected call of the tool
+	toolScroll     int
+	toolErrorsOnly bool // list only failed calls
+
+	// Session comparison view state
+	compareMark   string // session ID marked with =, empty when none
+	compare       *sessionCompare
+	compareLines  []string // rendered lines for scrolling
+	compareScroll int
+
 	// Layout state
 	activePane         FocusPane // Which pane is focused
 	sidebarRestore     FocusPane // Tracks pane focused before collapse; restored on expand via toggleSidebar()
@@ -171,6 +212,15 @@ type Plugin struct {
 	searchMode    bool
 	searchQuery   string
 	searchResults []adapter.Session
+	searchFilters SearchFilters // last query that parsed
+	searchErr     error         // query syntax error, shown in the search bar
+	searchHints   []string      // completion candidates from tab
+	querySaveMode bool          // naming the query to save
+	querySaveName string
+
+	// Message index for the model, tool and file query filters
+	messageIndex    map[string]*sessionMessageInfo
+	messageIndexing bool
 
 	// Filter state
 	filterMode             bool
@@ -221,6 +271,10 @@ type Plugin struct {
 	// Large session warning tracking (td-ee67d8)
 	warnedSessions map[string]bool // session ID -> already warned about size
 
+	// Budget tracking
+	budgetStatus app.BudgetStatusMsg // last status sent to the header
+	budgetAlerts map[string]int      // budget and period -> highest level alerted
+
 	// Pi adapter discovery toast (td-697e89)
 	piDiscoveryToastShown bool // true after showing one-time Pi discovery toast
 
@@ -233,13 +287,28 @@ type Plugin struct {
 	showResumeModal       bool
 	resumeModal           *modal.Modal
 	resumeModalWidth      int
-	resumeType            int // 0=shell, 1=worktree
+	resumeType            int // 0=shell, 1=worktree, 2=handoff new worktree, 3=handoff existing worktree
 	resumeNameInput       textinput.Model
 	resumeBaseBranchInput textinput.Model
 	resumeAgentIdx        int
 	resumeSkipPermissions bool
 	resumeFocus           int
 	resumeSession         *adapter.Session
+	resumeWorktreeIdx     int      // handoff target in resumeWorktreePaths
+	resumeWorktreePaths   []string // existing worktrees offered for handoffs
+	resumeWorktreeLabels  []string
+
+	// Annotations: stars, tags and message bookmarks
+	annotationStore    *AnnotationStore
+	annotationsPath    string // annotations database, "" to disable annotations
+	annotations        map[annotationKey]*SessionAnnotations
+	showAnnotateModal  bool
+	annotateModal      *modal.Modal
+	annotateModalWidth int
+	annotateKind       int // annotateTags or annotateBookmark
+	annotateInput      textinput.Model
+	annotateSession    adapter.Session
+	annotateMessageID  string
 
 	// Content search state (td-6ac70a: cross-conversation search)
 	contentSearchMode  bool                // True when content search modal is open
@@ -265,8 +334,16 @@ type msgLinePos struct {
 	LineCount int // number of lines this message takes
 }
 
-// New creates a new conversations plugin.
+// New creates a new conversations plugin that keeps annotations in the
+// shared database at DefaultAnnotationsPath.
 func New() *Plugin {
+	path, _ := DefaultAnnotationsPath()
+	return NewWithAnnotationsPath(path)
+}
+
+// NewWithAnnotationsPath creates a new conversations plugin that keeps
+// annotations in the database at path. An empty path disables annotations.
+func NewWithAnnotationsPath(path string) *Plugin {
 	renderer, err := NewGlamourRenderer()
 	if err != nil {
 		log.Printf("warn: glamour init failed: %v", err)
@@ -277,6 +354,7 @@ func New() *Plugin {
 		pageSize:            defaultPageSize,
 		displayedCount:      defaultSessionPageSize,
 		expandedThinking:    make(map[string]bool),
+		expandedSubAgents:   make(map[string]bool),
 		expandedMessages:    make(map[string]bool),
 		expandedToolResults: make(map[string]bool),
 		mouseHandler:        mouse.NewHandler(),
@@ -289,7 +367,9 @@ func New() *Plugin {
 		sidebarVisible:      true, // Sidebar visible by default
 		sidebarRestore:      PaneSidebar,
 		warnedSessions:      make(map[string]bool),
+		budgetAlerts:        make(map[string]int),
 		skeleton:            ui.NewSkeleton(8, nil), // 8 placeholder rows
+		annotationsPath:     path,
 	}
 	p.coalescer = NewEventCoalescer(0, coalesceChan)
 	return p
@@ -323,6 +403,9 @@ func (p *Plugin) resetState() {
 
 	// Session list state
 	p.sessions = nil
+	p.subAgentTotals = nil
+	p.subAgentCalls = nil
+	p.expandedSubAgents = make(map[string]bool)
 	p.cursor = 0
 	p.scrollOff = 0
 	p.displayedCount = defaultSessionPageSize
@@ -356,9 +439,23 @@ func (p *Plugin) resetState() {
 	p.detailTurn = nil
 	p.detailScroll = 0
 
+	// File changes view state
+	p.closeChanges()
+
 	// Analytics view state
 	p.analyticsScrollOff = 0
 	p.analyticsLines = nil
+	p.toolScope = ""
+	p.toolReport = nil
+	p.toolFilters = SearchFilters{}
+	p.toolQuery = ""
+	p.toolQueryMode = false
+	p.toolQueryInput = ""
+	p.toolQueryErr = nil
+	p.compareMark = ""
+	p.compare = nil
+	p.compareLines = nil
+	p.compareScroll = 0
 
 	// Layout state - reset to defaults but preserve sidebarWidth (persisted)
 	p.activePane = PaneSidebar
@@ -371,6 +468,13 @@ func (p *Plugin) resetState() {
 	p.searchMode = false
 	p.searchQuery = ""
 	p.searchResults = nil
+	p.searchFilters = SearchFilters{}
+	p.searchErr = nil
+	p.searchHints = nil
+	p.querySaveMode = false
+	p.querySaveName = ""
+	p.messageIndex = nil
+	p.messageIndexing = false
 
 	// Filter state
 	p.filterMode = false
@@ -402,6 +506,8 @@ func (p *Plugin) resetState() {
 
 	// Large session warning tracking
 	p.warnedSessions = make(map[string]bool)
+	p.budgetStatus = app.BudgetStatusMsg{}
+	p.budgetAlerts = make(map[string]int)
 
 	// Recreate coalescer infrastructure (td-84a1cb)
 	// The old coalescer has closed=true and channel is closed after Stop()
@@ -451,8 +557,13 @@ func (p *Plugin) Init(ctx *plugin.Context) error {
 		p.defaultCategoryFilter = []string{adapter.SessionCategoryInteractive}
 	}
 
+	p.initArchiver(ctx)
+	p.initAnnotations()
 	p.adapters = make(map[string]adapter.Adapter)
 	for id, a := range ctx.Adapters {
+		if _, ok := a.(adapter.Archiver); ok && p.archiver == nil {
+			continue
+		}
 		found, err := a.Detect(ctx.ProjectRoot)
 		if err != nil || !found {
 			continue
@@ -500,6 +611,10 @@ func (p *Plugin) Stop() {
 	})
 	p.closeWatchers()
 	p.watchChan = nil
+	if p.annotationStore != nil {
+		_ = p.annotationStore.Close()
+		p.annotationStore = nil
+	}
 }
 
 func (p *Plugin) closeWatchers() {
@@ -558,10 +673,17 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 			cmd := p.handleResumeModalKeys(msg)
 			return p, cmd
 		}
+		if p.showAnnotateModal {
+			return p, p.handleAnnotateModalKeys(msg)
+		}
 
 		switch p.view {
 		case ViewAnalytics:
 			return p.updateAnalytics(msg)
+		case ViewToolAnalytics:
+			return p.updateToolAnalytics(msg)
+		case ViewCompare:
+			return p.updateCompare(msg)
 		default:
 			// Route based on active pane
 			if p.activePane == PaneMessages {
@@ -587,20 +709,9 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		}
 
 		// Merge new sessions, deduplicating by ID
-		seen := make(map[string]bool, len(p.sessions))
-		for _, s := range p.sessions {
-			seen[s.ID] = true
-		}
-		for _, s := range msg.Sessions {
-			if !seen[s.ID] {
-				seen[s.ID] = true
-				p.sessions = append(p.sessions, s)
-			}
-		}
-		// Re-sort by UpdatedAt descending
-		sort.Slice(p.sessions, func(i, j int) bool {
-			return p.sessions[i].UpdatedAt.After(p.sessions[j].UpdatedAt)
-		})
+		p.sessions = mergeSessions(p.sessions, msg.Sessions)
+		// Re-sort by UpdatedAt descending, sub-agents under their parents
+		p.sortAndIndexSessions()
 
 		// Update pagination state (td-7198a5)
 		if p.displayedCount == 0 {
@@ -636,6 +747,13 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 			if cmd := p.checkPiDiscoveryToast(); cmd != nil {
 				cmds = append(cmds, cmd)
 			}
+			if cmd := p.checkBudgets(); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			// Copy new and updated sessions into the archive
+			if cmd := p.archiveSessions(); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
 			// Schedule settle check for skeleton hide
 			if !p.initialLoadDone {
 				p.loadSettleToken++
@@ -666,6 +784,7 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 			return p, nil // Ignore stale message from previous project
 		}
 		p.sessions = msg.Sessions
+		p.sortAndIndexSessions()
 		// Update session pagination state (td-7198a5)
 		if p.displayedCount == 0 {
 			p.displayedCount = defaultSessionPageSize
@@ -696,11 +815,13 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 				p.messages = nil
 				p.turns = nil
 				p.sessionSummary = nil
+				p.closeChanges()
 			}
 		}
 
 		// Check for large session warnings (td-ee67d8)
 		warningCmd := p.checkLargeSessionWarnings()
+		budgetCmd := p.checkBudgets()
 
 		// Schedule settle check for skeleton hide (td-6cc19f)
 		// If more sessions arrive before settle, the token will be invalidated
@@ -728,6 +849,9 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		if warningCmd != nil {
 			cmds = append(cmds, warningCmd)
 		}
+		if budgetCmd != nil {
+			cmds = append(cmds, budgetCmd)
+		}
 		if settleCmd != nil {
 			cmds = append(cmds, settleCmd)
 		}
@@ -760,13 +884,11 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		for _, s := range refreshMap {
 			p.sessions = append(p.sessions, *s)
 		}
-		// Re-sort by UpdatedAt descending
-		sort.Slice(p.sessions, func(i, j int) bool {
-			return p.sessions[i].UpdatedAt.After(p.sessions[j].UpdatedAt)
-		})
+		// Re-sort by UpdatedAt descending, sub-agents under their parents
+		p.sortAndIndexSessions()
 		p.hasMoreSessions = len(p.sessions) > p.displayedCount
 		p.updateTieredHotTargets()
-		return p, nil
+		return p, p.checkBudgets()
 
 	case LoadSettledMsg:
 		// Only settle if token matches (no new sessions arrived) (td-6cc19f)
@@ -883,6 +1005,10 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 			p.hitRegionsDirty = true
 		}
 
+		var changesCmd tea.Cmd
+		if isIncremental {
+			changesCmd = p.refreshChanges(msg.SessionID)
+		}
 		p.hasMore = len(msg.Messages) >= p.pageSize
 
 		// Update pagination state (td-313ea851)
@@ -926,7 +1052,7 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 			}
 		}
 
-		return p, nil
+		return p, changesCmd
 
 	case WatchStartedMsg:
 		// Watcher started, store channel and start listening
@@ -997,6 +1123,27 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 
 		return p, tea.Batch(cmds...)
 
+	case HandoffReadyMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		if msg.Err != nil {
+			return p, func() tea.Msg {
+				return app.ToastMsg{Message: "Handoff failed: " + msg.Err.Error(), Duration: 3 * time.Second, IsError: true}
+			}
+		}
+		resume := msg.Resume
+		cmds := []tea.Cmd{
+			app.FocusPlugin("workspace-manager"),
+			func() tea.Msg { return resume },
+		}
+		if msg.Warning != "" {
+			cmds = append(cmds, func() tea.Msg {
+				return app.ToastMsg{Message: msg.Warning, Duration: 4 * time.Second, IsError: true}
+			})
+		}
+		return p, tea.Batch(cmds...)
+
 	case tea.WindowSizeMsg:
 		p.width = msg.Width
 		p.height = msg.Height
@@ -1035,6 +1182,26 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		}
 		return p, nil
 
+	case ChangesLoadedMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		p.applyChangesLoaded(msg)
+		return p, nil
+
+	case CompareLoadedMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		p.applyCompareLoaded(msg)
+		return p, nil
+
+	case MessageIndexMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		return p, p.applyMessageIndex(msg)
+
 	case ContentSearchResultsMsg:
 		if plugin.IsStale(p.ctx, msg) {
 			return p, nil // Ignore stale message from previous project
@@ -1083,6 +1250,10 @@ func (p *Plugin) View(width, height int) string {
 		content := p.renderResumeModal(width, height)
 		return lipgloss.NewStyle().Width(width).Height(height).MaxHeight(height).Render(content)
 	}
+	if p.showAnnotateModal {
+		content := p.renderAnnotateModal(width, height)
+		return lipgloss.NewStyle().Width(width).Height(height).MaxHeight(height).Render(content)
+	}
 
 	var content string
 	if len(p.adapters) == 0 {
@@ -1091,6 +1262,10 @@ func (p *Plugin) View(width, height int) string {
 		switch p.view {
 		case ViewAnalytics:
 			content = p.renderAnalytics()
+		case ViewToolAnalytics:
+			content = p.renderToolAnalytics()
+		case ViewCompare:
+			content = p.renderCompare()
 		default:
 			content = p.renderTwoPane()
 		}
@@ -1124,6 +1299,8 @@ func (p *Plugin) Commands() []plugin.Command {
 		return []plugin.Command{
 			{ID: "select", Name: "Select", Description: "Select search result", Category: plugin.CategoryActions, Context: "conversations-search", Priority: 1},
 			{ID: "cancel", Name: "Cancel", Description: "Cancel search", Category: plugin.CategoryActions, Context: "conversations-search", Priority: 1},
+			{ID: "complete-query", Name: "Complete", Description: "Complete query term", Category: plugin.CategoryActions, Context: "conversations-search", Priority: 2},
+			{ID: "save-query", Name: "Save", Description: "Save query as @name", Category: plugin.CategoryActions, Context: "conversations-search", Priority: 3},
 		}
 	}
 	if p.filterMode {
@@ -1132,6 +1309,14 @@ func (p *Plugin) Commands() []plugin.Command {
 			{ID: "cancel", Name: "Cancel", Description: "Cancel filter", Category: plugin.CategoryActions, Context: "conversations-filter", Priority: 1},
 		}
 	}
+	if p.changesMode {
+		return []plugin.Command{
+			{ID: "back", Name: "Back", Description: "Return to turn list", Category: plugin.CategoryNavigation, Context: "conversations-changes", Priority: 1},
+			{ID: "view-turn", Name: "Turn", Description: "View the turn that made the change", Category: plugin.CategoryView, Context: "conversations-changes", Priority: 2},
+			{ID: "open-file", Name: "Open", Description: "Open file in file browser", Category: plugin.CategoryActions, Context: "conversations-changes", Priority: 3},
+			{ID: "git-diff", Name: "Git Diff", Description: "Show current git diff of the file", Category: plugin.CategoryActions, Context: "conversations-changes", Priority: 4},
+		}
+	}
 	// Detail mode (right pane shows turn detail)
 	if p.detailMode {
 		return []plugin.Command{
@@ -1145,10 +1330,17 @@ func (p *Plugin) Commands() []plugin.Command {
 			{ID: "toggle-view", Name: "View", Description: "Toggle conversation/turn view", Category: plugin.CategoryView, Context: "conversations-main", Priority: 1},
 			{ID: "detail", Name: "Detail", Description: "View turn details", Category: plugin.CategoryView, Context: "conversations-main", Priority: 2},
 			{ID: "expand", Name: "Expand", Description: "Expand selected item", Category: plugin.CategoryView, Context: "conversations-main", Priority: 3},
+			{ID: "file-changes", Name: "Changes", Description: "Show files changed in session", Category: plugin.CategoryView, Context: "conversations-main", Priority: 3},
+			{ID: "tool-analytics", Name: "Tools", Description: "Tool call analytics for this session", Category: plugin.CategoryView, Context: "conversations-main", Priority: 4},
 			{ID: "content-search", Name: "Find", Description: "Search content (F)", Category: plugin.CategorySearch, Context: "conversations-main", Priority: 3},
 			{ID: "back", Name: "Back", Description: "Return to sidebar", Category: plugin.CategoryNavigation, Context: "conversations-main", Priority: 4},
 			{ID: "open", Name: "Open", Description: "Open in CLI", Category: plugin.CategoryActions, Context: "conversations-main", Priority: 5},
 			{ID: "yank", Name: "Yank", Description: "Yank turn content", Category: plugin.CategoryActions, Context: "conversations-main", Priority: 6},
+			{ID: "export-json", Name: "Export JSON", Description: "Export session as a JSON transcript", Category: plugin.CategoryActions, Context: "conversations-main", Priority: 6},
+			{ID: "open-linked-session", Name: "Sub-agent", Description: "Open linked sub-agent or parent session", Category: plugin.CategoryNavigation, Context: "conversations-main", Priority: 6},
+			{ID: "bookmark-message", Name: "Bookmark", Description: "Bookmark message with a note", Category: plugin.CategoryActions, Context: "conversations-main", Priority: 6},
+			{ID: "toggle-star", Name: "Star", Description: "Star or unstar session", Category: plugin.CategoryActions, Context: "conversations-main", Priority: 7},
+			{ID: "edit-tags", Name: "Tags", Description: "Edit session tags", Category: plugin.CategoryActions, Context: "conversations-main", Priority: 7},
 			{ID: "toggle-sidebar", Name: "Sidebar", Description: "Toggle sidebar visibility", Category: plugin.CategoryView, Context: "conversations-main", Priority: 7},
 		}
 	}
@@ -1157,6 +1349,29 @@ func (p *Plugin) Commands() []plugin.Command {
 			{ID: "back", Name: "Back", Description: "Return to conversations", Category: plugin.CategoryNavigation, Context: "analytics", Priority: 1},
 		}
 	}
+	if p.view == ViewCompare {
+		return []plugin.Command{
+			{ID: "back", Name: "Back", Description: "Return to conversations", Category: plugin.CategoryNavigation, Context: "conversations-compare", Priority: 1},
+			{ID: "swap-sides", Name: "Swap", Description: "Swap compared sessions", Category: plugin.CategoryView, Context: "conversations-compare", Priority: 2},
+		}
+	}
+	if p.view == ViewToolAnalytics {
+		if p.toolQueryMode {
+			return []plugin.Command{
+				{ID: "apply", Name: "Apply", Description: "Apply filter", Category: plugin.CategorySearch, Context: "conversations-tools-filter", Priority: 1},
+				{ID: "complete-query", Name: "Complete", Description: "Complete query term", Category: plugin.CategorySearch, Context: "conversations-tools-filter", Priority: 2},
+				{ID: "cancel", Name: "Cancel", Description: "Cancel filter", Category: plugin.CategoryActions, Context: "conversations-tools-filter", Priority: 1},
+			}
+		}
+		return []plugin.Command{
+			{ID: "back", Name: "Back", Description: "Return to conversations", Category: plugin.CategoryNavigation, Context: "conversations-tools", Priority: 1},
+			{ID: "open-call", Name: "Open", Description: "Open the selected call", Category: plugin.CategoryNavigation, Context: "conversations-tools", Priority: 1},
+			{ID: "filter", Name: "Filter", Description: "Filter by adapter, model or date", Category: plugin.CategorySearch, Context: "conversations-tools", Priority: 2},
+			{ID: "toggle-errors", Name: "Errors", Description: "Show failed or all calls", Category: plugin.CategoryView, Context: "conversations-tools", Priority: 2},
+			{ID: "toggle-scope", Name: "Scope", Description: "Selected session or all sessions", Category: plugin.CategoryView, Context: "conversations-tools", Priority: 3},
+			{ID: "switch-focus", Name: "Focus", Description: "Switch between tools and calls", Category: plugin.CategoryNavigation, Context: "conversations-tools", Priority: 3},
+		}
+	}
 	return []plugin.Command{
 		{ID: "view-session", Name: "View", Description: "View session messages", Category: plugin.CategoryView, Context: "conversations-sidebar", Priority: 1},
 		{ID: "search", Name: "Search", Description: "Search conversations", Category: plugin.CategorySearch, Context: "conversations-sidebar", Priority: 2},
@@ -1164,6 +1379,11 @@ func (p *Plugin) Commands() []plugin.Command {
 		{ID: "content-search", Name: "Find", Description: "Search content (F)", Category: plugin.CategorySearch, Context: "conversations-sidebar", Priority: 2},
 		{ID: "toggle-category", Name: "Category", Description: "Toggle category filter", Category: plugin.CategorySearch, Context: "conversations-sidebar", Priority: 3},
 		{ID: "resume-in-workspace", Name: "Resume", Description: "Resume in workspace", Category: plugin.CategoryActions, Context: "conversations-sidebar", Priority: 3},
+		{ID: "toggle-star", Name: "Star", Description: "Star or unstar session", Category: plugin.CategoryActions, Context: "conversations-sidebar", Priority: 3},
+		{ID: "edit-tags", Name: "Tags", Description: "Edit session tags", Category: plugin.CategoryActions, Context: "conversations-sidebar", Priority: 4},
+		{ID: "toggle-sub-agents", Name: "Sub-agents", Description: "Expand or collapse sub-agents", Category: plugin.CategoryView, Context: "conversations-sidebar", Priority: 3},
+		{ID: "tool-analytics", Name: "Tools", Description: "Tool call analytics", Category: plugin.CategoryView, Context: "conversations-sidebar", Priority: 4},
+		{ID: "mark-compare", Name: "Compare", Description: "Mark for compare, or compare with marked session", Category: plugin.CategoryView, Context: "conversations-sidebar", Priority: 5},
 		{ID: "yank-details", Name: "Copy Details", Description: "Copy session details", Category: plugin.CategoryActions, Context: "conversations-sidebar", Priority: 3},
 		{ID: "yank-resume", Name: "Copy Resume", Description: "Copy resume command", Category: plugin.CategoryActions, Context: "conversations-sidebar", Priority: 4},
 		{ID: "toggle-sidebar", Name: "Sidebar", Description: "Toggle sidebar visibility", Category: plugin.CategoryView, Context: "conversations-sidebar", Priority: 5},
@@ -1180,12 +1400,18 @@ func (p *Plugin) FocusContext() string {
 	if p.showResumeModal {
 		return "conversations-resume-modal"
 	}
+	if p.showAnnotateModal {
+		return "conversations-annotate-modal"
+	}
 	if p.searchMode {
 		return "conversations-search"
 	}
 	if p.filterMode {
 		return "conversations-filter"
 	}
+	if p.changesMode {
+		return "conversations-changes"
+	}
 	// Detail mode (right pane shows turn detail)
 	if p.detailMode {
 		return "turn-detail"
@@ -1193,6 +1419,13 @@ func (p *Plugin) FocusContext() string {
 	switch p.view {
 	case ViewAnalytics:
 		return "analytics"
+	case ViewToolAnalytics:
+		if p.toolQueryMode {
+			return "conversations-tools-filter"
+		}
+		return "conversations-tools"
+	case ViewCompare:
+		return "conversations-compare"
 	default:
 		// Return context based on active pane
 		if p.activePane == PaneSidebar {
@@ -1205,7 +1438,8 @@ func (p *Plugin) FocusContext() string {
 // ConsumesTextInput reports whether conversation UI currently has a focused
 // text-entry flow where app shortcuts should not intercept characters.
 func (p *Plugin) ConsumesTextInput() bool {
-	return p.searchMode || p.filterMode || p.contentSearchMode
+	return p.searchMode || p.filterMode || p.contentSearchMode || p.showAnnotateModal ||
+		(p.view == ViewToolAnalytics && p.toolQueryMode)
 }
 
 // Diagnostics returns plugin health info.
@@ -1248,9 +1482,10 @@ func (p *Plugin) Diagnostics() []plugin.Diagnostic {
 func (p *Plugin) copySessionToClipboard() tea.Cmd {
 	session := p.findSelectedSession()
 	messages := p.messages
+	ann := p.selectedAnnotations().clone()
 
 	return func() tea.Msg {
-		md := ExportSessionAsMarkdown(session, messages)
+		md := ExportSessionAsMarkdown(session, messages, ann)
 		if err := CopyToClipboard(md); err != nil {
 			return app.ToastMsg{Message: "Copy failed: " + err.Error(), Duration: 2 * time.Second, IsError: true}
 		}
@@ -1262,10 +1497,11 @@ func (p *Plugin) copySessionToClipboard() tea.Cmd {
 func (p *Plugin) exportSessionToFile() tea.Cmd {
 	session := p.findSelectedSession()
 	messages := p.messages
+	ann := p.selectedAnnotations().clone()
 	workDir := p.ctx.WorkDir
 
 	return func() tea.Msg {
-		filename, err := ExportSessionToFile(session, messages, workDir)
+		filename, err := ExportSessionToFile(session, messages, ann, workDir)
 		if err != nil {
 			return app.ToastMsg{Message: "Export failed: " + err.Error(), Duration: 2 * time.Second, IsError: true}
 		}
@@ -1273,6 +1509,35 @@ func (p *Plugin) exportSessionToFile() tea.Cmd {
 	}
 }
 
+// exportSessionToJSON exports the current session as a lossless JSON
+// transcript. All messages are reloaded from the adapter since p.messages
+// only holds the loaded page.
+func (p *Plugin) exportSessionToJSON() tea.Cmd {
+	session := p.findSelectedSession()
+	if session == nil {
+		return nil
+	}
+	s := *session
+	a := p.adapterForSession(s.ID)
+	if a == nil {
+		return nil
+	}
+	ann := p.annotationsFor(s).clone()
+	workDir := p.ctx.WorkDir
+
+	return func() tea.Msg {
+		messages, err := a.Messages(s.ID)
+		if err == nil {
+			var filename string
+			filename, err = ExportSessionToJSONFile(&s, messages, ann, workDir)
+			if err == nil {
+				return app.ToastMsg{Message: "Exported to " + filename, Duration: 2 * time.Second}
+			}
+		}
+		return app.ToastMsg{Message: "Export failed: " + err.Error(), Duration: 2 * time.Second, IsError: true}
+	}
+}
+
 // Message types
 type SessionsLoadedMsg struct {
 	Epoch    uint64 // Epoch when request was issued (for stale detection)
@@ -1,13 +1,16 @@
 package conversations
 
 import (
+	"slices"
 	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/app"
 	appmsg "github.com/marcus/sidecar/internal/msg"
 	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/state"
 )
 
 // Update methods for handling key events in various views
@@ -165,6 +168,14 @@ func (p *Plugin) updateSessions(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 		p.view = ViewAnalytics
 		return p, nil
 
+	case "O":
+		// Tool call analytics across sessions
+		return p, p.openToolAnalytics("")
+
+	case "=":
+		// Compare two sessions side by side
+		return p, p.markOrCompare()
+
 	case "y":
 		// Yank session details to clipboard
 		return p, p.yankSessionDetails()
@@ -180,6 +191,41 @@ func (p *Plugin) updateSessions(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 	case "R":
 		// Open resume modal for workspace
 		return p, p.openResumeModal()
+
+	case "s":
+		// Star or unstar the selected session
+		if len(sessions) > 0 && p.cursor < len(sessions) {
+			p.setSelectedSession(sessions[p.cursor].ID)
+			return p, p.toggleStar()
+		}
+
+	case "T":
+		// Edit the selected session's tags
+		if len(sessions) > 0 && p.cursor < len(sessions) {
+			p.setSelectedSession(sessions[p.cursor].ID)
+			return p, p.openTagEditor()
+		}
+
+	case " ":
+		// Expand or collapse the selected session's sub-agents
+		prev := p.selectedSession
+		p.toggleSubAgents()
+		if p.selectedSession != prev {
+			return p, p.schedulePreviewLoad(p.selectedSession)
+		}
+		return p, nil
+
+	case "D":
+		// Open the session's file changes alongside its messages
+		if len(sessions) > 0 && p.cursor < len(sessions) {
+			p.setSelectedSession(sessions[p.cursor].ID)
+			p.activePane = PaneMessages
+			return p, tea.Batch(
+				p.openChanges(),
+				p.loadMessages(p.selectedSession),
+				p.loadUsage(p.selectedSession),
+			)
+		}
 	}
 
 	return p, nil
@@ -227,11 +273,18 @@ func (p *Plugin) toggleCategoryFilter() tea.Cmd {
 
 // updateSearch handles key events in search mode.
 func (p *Plugin) updateSearch(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
+	if p.querySaveMode {
+		return p, p.updateQuerySave(msg)
+	}
+
 	switch msg.String() {
 	case "esc":
 		p.searchMode = false
 		p.searchQuery = ""
 		p.searchResults = nil
+		p.searchFilters = SearchFilters{}
+		p.searchErr = nil
+		p.searchHints = nil
 		p.cursor = 0
 		p.scrollOff = 0
 		if len(p.sessions) > 0 {
@@ -256,10 +309,26 @@ func (p *Plugin) updateSearch(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 	case "backspace":
 		if len(p.searchQuery) > 0 {
 			p.searchQuery = p.searchQuery[:len(p.searchQuery)-1]
-			p.filterSessions()
-			p.cursor = 0
-			p.scrollOff = 0
+			return p, p.searchQueryChanged()
+		}
+
+	case "tab":
+		completed, hints := completeQuery(p.searchQuery, p.queryValues, p.savedQueryNames())
+		if len(hints) > 1 {
+			p.searchHints = hints
 		}
+		if completed != p.searchQuery {
+			p.searchQuery = completed
+			cmd := p.searchQueryChanged()
+			if len(hints) > 1 {
+				p.searchHints = hints
+			}
+			return p, cmd
+		}
+
+	case "ctrl+s":
+		p.querySaveMode = true
+		p.querySaveName = ""
 
 	case "up", "ctrl+p":
 		if p.cursor > 0 {
@@ -350,20 +419,117 @@ func (p *Plugin) updateSearch(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 		// Add character to search query
 		if len(msg.String()) == 1 {
 			p.searchQuery += msg.String()
-			p.filterSessions()
-			p.cursor = 0
-			p.scrollOff = 0
-			sessions := p.visibleSessions()
-			if len(sessions) > 0 {
-				p.setSelectedSession(sessions[0].ID)
-				return p, p.schedulePreviewLoad(p.selectedSession)
-			}
+			return p, p.searchQueryChanged()
 		}
 	}
 
 	return p, nil
 }
 
+// searchQueryChanged re-runs the search after the query changes, selecting
+// the first result and indexing messages if the query needs them.
+func (p *Plugin) searchQueryChanged() tea.Cmd {
+	p.searchHints = nil
+	p.filterSessions()
+	p.cursor = 0
+	p.scrollOff = 0
+
+	var cmds []tea.Cmd
+	if p.searchErr == nil {
+		cmds = append(cmds, p.indexMessagesFor(p.searchFilters))
+	}
+	if sessions := p.visibleSessions(); len(sessions) > 0 {
+		p.setSelectedSession(sessions[0].ID)
+		cmds = append(cmds, p.schedulePreviewLoad(p.selectedSession))
+	}
+	return tea.Batch(cmds...)
+}
+
+// updateQuerySave handles naming the search query to save. Saving an
+// empty query deletes the name.
+func (p *Plugin) updateQuerySave(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		p.querySaveMode = false
+	case "enter":
+		name := strings.TrimPrefix(p.querySaveName, "@")
+		if name == "" {
+			return nil
+		}
+		p.querySaveMode = false
+		query := strings.TrimSpace(p.searchQuery)
+		if query == "@"+name {
+			return appmsg.ShowToast("A query can't save itself", 2*time.Second)
+		}
+		if err := state.SetSavedQuery(name, query); err != nil {
+			return func() tea.Msg {
+				return app.ToastMsg{Message: "Save failed: " + err.Error(), Duration: 3 * time.Second, IsError: true}
+			}
+		}
+		if query == "" {
+			return appmsg.ShowToast("Deleted @"+name, 2*time.Second)
+		}
+		return appmsg.ShowToast("Saved @"+name, 2*time.Second)
+	case "backspace":
+		if n := len(p.querySaveName); n > 0 {
+			p.querySaveName = p.querySaveName[:n-1]
+		}
+	default:
+		// Names are single words so @name parses as one term
+		if k := msg.String(); len(k) == 1 && !strings.ContainsAny(k, " \t\"@") {
+			p.querySaveName += k
+		}
+	}
+	return nil
+}
+
+// queryValues returns known values of a query field, for completion.
+func (p *Plugin) queryValues(field string) []string {
+	var values []string
+	add := func(v string) {
+		if v != "" && !slices.Contains(values, v) {
+			values = append(values, v)
+		}
+	}
+	switch field {
+	case "adapter":
+		for id := range p.adapters {
+			add(id)
+		}
+	case "model":
+		for _, m := range []string{"opus", "sonnet", "haiku"} {
+			add(m)
+		}
+		for _, info := range p.messageIndex {
+			for _, m := range info.Models {
+				add(m)
+			}
+		}
+	case "tool":
+		for _, info := range p.messageIndex {
+			for _, t := range info.Tools {
+				add(t)
+			}
+		}
+	case "worktree":
+		for _, s := range p.sessions {
+			add(s.WorktreeName)
+		}
+	case "tag":
+		values = p.allTags()
+	}
+	return values
+}
+
+// savedQueryNames returns the names of saved queries.
+func (p *Plugin) savedQueryNames() []string {
+	var names []string
+	for name := range state.GetSavedQueries() {
+		names = append(names, name)
+	}
+	return names
+}
+
 // updateAnalytics handles key events in analytics view.
 func (p *Plugin) updateAnalytics(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 	// Calculate max scroll based on content
@@ -410,6 +576,9 @@ func (p *Plugin) updateAnalytics(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 
 // updateMessages handles key events in message view (now uses turns).
 func (p *Plugin) updateMessages(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
+	if p.changesMode {
+		return p.updateChangesMode(msg)
+	}
 	// In detail mode, handle detail-specific navigation
 	if p.detailMode {
 		return p.updateDetailMode(msg)
@@ -706,10 +875,38 @@ func (p *Plugin) updateMessages(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 			return p, p.exportSessionToFile()
 		}
 
+	case "J":
+		// Export session as a JSON transcript
+		if p.selectedSession != "" {
+			return p, p.exportSessionToJSON()
+		}
+
 	case " ":
 		// Load more messages (would need to implement paging in adapter)
 		return p, nil
 
+	case "a":
+		// Jump to the sub-agent a tool call spawned, or back to the parent
+		return p, p.openLinkedSession()
+
+	case "b":
+		// Bookmark the selected message with a note
+		return p, p.openBookmarkEditor()
+
+	case "]":
+		p.jumpToBookmark(1)
+
+	case "[":
+		p.jumpToBookmark(-1)
+
+	case "s":
+		// Star or unstar the session
+		return p, p.toggleStar()
+
+	case "T":
+		// Edit the session's tags
+		return p, p.openTagEditor()
+
 	case "y":
 		// Yank current turn content to clipboard
 		return p, p.yankTurnContent()
@@ -725,6 +922,16 @@ func (p *Plugin) updateMessages(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 	case "F":
 		// Open content search modal (td-6ac70a)
 		return p.openContentSearch()
+
+	case "D":
+		// Show files changed by the session's tool calls
+		return p, p.openChanges()
+
+	case "O":
+		// Tool call analytics for this session
+		if p.selectedSession != "" {
+			return p, p.openToolAnalytics(p.selectedSession)
+		}
 	}
 
 	return p, nil
@@ -790,6 +997,12 @@ func (p *Plugin) updateFilter(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 			return p, nil
 		}
 	}
+	for _, opt := range tagFilterOptions(p.allTags()) {
+		if key == opt.key {
+			p.filters.ToggleTag(opt.tag)
+			return p, nil
+		}
+	}
 
 	switch key {
 	case "esc":
@@ -802,6 +1015,7 @@ func (p *Plugin) updateFilter(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 		p.filterActive = p.filters.IsActive()
 		p.cursor = 0
 		p.scrollOff = 0
+		return p, p.indexMessagesFor(p.filters)
 
 	case "1":
 		// Toggle model filter: opus
@@ -843,6 +1057,10 @@ func (p *Plugin) updateFilter(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 		// Toggle active only
 		p.filters.ActiveOnly = !p.filters.ActiveOnly
 
+	case "*":
+		// Toggle starred only
+		p.filters.Starred = !p.filters.Starred
+
 	case "x":
 		// Clear all filters
 		p.filters = SearchFilters{}
@@ -156,8 +156,14 @@ func (p *Plugin) loadSessions() tea.Cmd {
 							absWtPath = abs
 						}
 						if absWtPath != currentPath {
-							wtSessions[i].WorktreeName = wtName
+							// Archived sessions remember their worktree's real name
+							if wtSessions[i].WorktreeName == "" {
+								wtSessions[i].WorktreeName = wtName
+							}
 							wtSessions[i].WorktreePath = absWtPath
+						} else {
+							wtSessions[i].WorktreeName = ""
+							wtSessions[i].WorktreePath = ""
 						}
 						adapterSess = append(adapterSess, wtSessions[i])
 					}
@@ -166,7 +172,7 @@ func (p *Plugin) loadSessions() tea.Cmd {
 				for i := range adapterSess {
 					if adapterSess[i].WorktreePath != "" {
 						if _, err := os.Stat(adapterSess[i].WorktreePath); os.IsNotExist(err) {
-							adapterSess[i].WorktreeName = adapterSess[i].WorktreeName + " (deleted)"
+							adapterSess[i].WorktreeName = adapterSess[i].WorktreeName + adapter.DeletedWorktreeSuffix
 						}
 					}
 				}
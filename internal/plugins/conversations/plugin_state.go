@@ -1,11 +1,11 @@
 package conversations
 
 import (
-	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/state"
 )
 
 // Session selection and state management methods
@@ -26,6 +26,7 @@ func (p *Plugin) setSelectedSession(sessionID string) {
 	p.detailMode = false
 	p.detailTurn = nil
 	p.detailScroll = 0
+	p.closeChanges()
 	p.expandedThinking = make(map[string]bool)
 	// Reset conversation flow view state
 	p.expandedMessages = make(map[string]bool)
@@ -93,20 +94,26 @@ func (p *Plugin) getSelectedMessage() *adapter.Message {
 
 // Session filtering methods
 
-// filterSessions filters sessions based on search query.
+// filterSessions filters sessions by the search bar query (see ParseQuery).
 func (p *Plugin) filterSessions() {
 	if p.searchQuery == "" {
 		p.searchResults = nil
+		p.searchFilters = SearchFilters{}
+		p.searchErr = nil
 		return
 	}
 
-	query := strings.ToLower(p.searchQuery)
+	// A query with a syntax error keeps the last results
+	filters, err := ParseQuery(p.searchQuery, state.GetSavedQueries())
+	p.searchErr = err
+	if err != nil {
+		return
+	}
+	p.searchFilters = filters
+
 	var results []adapter.Session
 	for _, s := range p.sessions {
-		if strings.Contains(strings.ToLower(s.Name), query) ||
-			strings.Contains(strings.ToLower(s.Slug), query) ||
-			strings.Contains(s.ID, query) ||
-			strings.Contains(strings.ToLower(s.AdapterName), query) {
+		if p.sessionMatches(&filters, s) {
 			results = append(results, s)
 		}
 	}
@@ -123,18 +130,18 @@ func (p *Plugin) visibleSessions() []adapter.Session {
 	if p.filterActive && p.filters.IsActive() {
 		var filtered []adapter.Session
 		for _, s := range p.sessions {
-			if p.filters.Matches(s) {
+			if p.sessionMatches(&p.filters, s) {
 				filtered = append(filtered, s)
 			}
 		}
-		return filtered
+		return p.hideCollapsedSubAgents(filtered)
 	}
 
 	// Apply session pagination (td-7198a5)
 	if p.displayedCount > 0 && p.displayedCount < len(p.sessions) {
-		return p.sessions[:p.displayedCount]
+		return p.hideCollapsedSubAgents(p.sessions[:p.displayedCount])
 	}
-	return p.sessions
+	return p.hideCollapsedSubAgents(p.sessions)
 }
 
 // loadMoreSessions increases the displayed session count by one page (td-7198a5).
@@ -277,11 +284,11 @@ func (p *Plugin) countHeaderLinesBetween(start, end int) int {
 	headerLines := 0
 	currentGroup := ""
 	if start > 0 && start < len(sessions) {
-		currentGroup = getSessionGroup(sessions[start].UpdatedAt)
+		currentGroup = p.sessionListGroup(sessions, start)
 	}
 
 	for i := start; i <= end && i < len(sessions); i++ {
-		sessionGroup := getSessionGroup(sessions[i].UpdatedAt)
+		sessionGroup := p.sessionListGroup(sessions, i)
 		if sessionGroup != currentGroup {
 			// Group header line
 			headerLines++
@@ -3290,7 +3290,7 @@ func TestLargeSessionWarning(t *testing.T) {
 // resets state when switching projects via Stop() + Init() + Start().
 func TestPluginReinitOnProjectSwitch(t *testing.T) {
 	t.Run("state is reset on Init", func(t *testing.T) {
-		p := New()
+		p := NewWithAnnotationsPath("")
 
 		// Set up initial state simulating an active session
 		ctx1 := &plugin.Context{
@@ -3362,7 +3362,7 @@ func TestPluginReinitOnProjectSwitch(t *testing.T) {
 	})
 
 	t.Run("worktree cache is invalidated on Init", func(t *testing.T) {
-		p := New()
+		p := NewWithAnnotationsPath("")
 
 		ctx1 := &plugin.Context{
 			WorkDir:  "/project/a",
@@ -3401,7 +3401,7 @@ func TestPluginReinitOnProjectSwitch(t *testing.T) {
 	})
 
 	t.Run("coalescer is recreated on Init", func(t *testing.T) {
-		p := New()
+		p := NewWithAnnotationsPath("")
 
 		ctx1 := &plugin.Context{
 			WorkDir:  "/project/a",
@@ -3451,7 +3451,7 @@ func TestPluginReinitOnProjectSwitch(t *testing.T) {
 	})
 
 	t.Run("context is updated on Init", func(t *testing.T) {
-		p := New()
+		p := NewWithAnnotationsPath("")
 
 		ctx1 := &plugin.Context{
 			WorkDir:  "/project/a",
@@ -0,0 +1,521 @@
+package conversations
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// queryDateLayout is the date format accepted by after: and before:.
+const queryDateLayout = "2006-01-02"
+
+// maxSavedQueryDepth bounds @name expansion so saved queries that refer to
+// each other can't loop.
+const maxSavedQueryDepth = 8
+
+// QueryError is a query syntax error at a byte offset in the query.
+type QueryError struct {
+	Pos int
+	Msg string
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("col %d: %s", e.Pos+1, e.Msg)
+}
+
+// queryField describes a query field for parsing, errors and completion.
+type queryField struct {
+	name    string
+	aliases []string
+	numeric bool   // takes a comparison: cost>2, tokens<=100k
+	example string // shown when the value is missing
+}
+
+var queryFields = []queryField{
+	{name: "adapter", example: "adapter:claude-code,codex"},
+	{name: "model", example: "model:opus"},
+	{name: "category", aliases: []string{"cat"}, example: "category:interactive"},
+	{name: "worktree", aliases: []string{"wt"}, example: "worktree:feat/*"},
+	{name: "tag", example: "tag:incident"},
+	{name: "is", example: "is:starred"},
+	{name: "has", example: "has:tool:Bash"},
+	{name: "date", example: "date:week"},
+	{name: "after", example: "after:2026-09-01"},
+	{name: "before", example: "before:30d"},
+	{name: "cost", numeric: true, example: "cost>2"},
+	{name: "tokens", numeric: true, example: "tokens>100k"},
+	{name: "msgs", aliases: []string{"messages"}, numeric: true, example: "msgs>=10"},
+}
+
+// Fixed values offered by completion and checked by the parser.
+var (
+	queryIsValues       = []string{"active", "starred"}
+	queryHasValues      = []string{"tool:", "file:"}
+	queryDateValues     = []string{"today", "yesterday", "week", "month"}
+	queryCategoryValues = []string{"interactive", "cron", "system"}
+)
+
+// lookupQueryField finds a field by name or alias.
+func lookupQueryField(name string) *queryField {
+	for i := range queryFields {
+		if queryFields[i].name == name || slices.Contains(queryFields[i].aliases, name) {
+			return &queryFields[i]
+		}
+	}
+	return nil
+}
+
+// queryToken is a whitespace-separated query term with quotes removed.
+type queryToken struct {
+	text   string
+	pos    int
+	quoted bool // the term started with a quote, so it's always text
+}
+
+// tokenizeQuery splits a query into terms. Double quotes group words,
+// either around a whole term ("db migration") or a value (tag:"a b").
+func tokenizeQuery(input string) ([]queryToken, error) {
+	var tokens []queryToken
+	i := 0
+	for i < len(input) {
+		if unicode.IsSpace(rune(input[i])) {
+			i++
+			continue
+		}
+		tok := queryToken{pos: i, quoted: input[i] == '"'}
+		var sb strings.Builder
+		for i < len(input) && !unicode.IsSpace(rune(input[i])) {
+			if input[i] != '"' {
+				sb.WriteByte(input[i])
+				i++
+				continue
+			}
+			end := strings.IndexByte(input[i+1:], '"')
+			if end < 0 {
+				return nil, &QueryError{Pos: i, Msg: "unterminated quote"}
+			}
+			sb.WriteString(input[i+1 : i+1+end])
+			i += end + 2
+		}
+		tok.text = sb.String()
+		tokens = append(tokens, tok)
+	}
+	return tokens, nil
+}
+
+// ParseQuery parses a search bar query into filters. Free text and quoted
+// phrases become the text query; field terms like adapter:codex, cost>2 or
+// has:tool:Bash set filters; #tag is short for tag:tag* and @name expands a
+// saved query.
+func ParseQuery(input string, saved map[string]string) (SearchFilters, error) {
+	var f SearchFilters
+	var text []string
+	if err := parseQueryInto(&f, &text, input, saved, 0); err != nil {
+		return SearchFilters{}, err
+	}
+	f.Query = strings.Join(text, " ")
+	return f, nil
+}
+
+func parseQueryInto(f *SearchFilters, text *[]string, input string, saved map[string]string, depth int) error {
+	tokens, err := tokenizeQuery(input)
+	if err != nil {
+		return err
+	}
+	for _, tok := range tokens {
+		if err := applyQueryTerm(f, text, tok, saved, depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyQueryTerm(f *SearchFilters, text *[]string, tok queryToken, saved map[string]string, depth int) error {
+	term := tok.text
+	if tok.quoted || term == "" {
+		*text = append(*text, term)
+		return nil
+	}
+
+	if name, ok := strings.CutPrefix(term, "@"); ok {
+		q, found := saved[name]
+		if !found {
+			return &QueryError{Pos: tok.pos, Msg: fmt.Sprintf("no saved query @%s", name)}
+		}
+		if depth >= maxSavedQueryDepth {
+			return &QueryError{Pos: tok.pos, Msg: fmt.Sprintf("@%s: saved queries nest too deeply", name)}
+		}
+		if err := parseQueryInto(f, text, q, saved, depth+1); err != nil {
+			var msg string
+			if qe, ok := err.(*QueryError); ok {
+				msg = qe.Msg
+			} else {
+				msg = err.Error()
+			}
+			return &QueryError{Pos: tok.pos, Msg: fmt.Sprintf("@%s: %s", name, msg)}
+		}
+		return nil
+	}
+
+	if tag, ok := strings.CutPrefix(term, "#"); ok && tag != "" {
+		f.Tags = append(f.Tags, strings.ToLower(tag)+"*")
+		return nil
+	}
+
+	i := strings.IndexAny(term, ":<>=")
+	if i < 0 {
+		*text = append(*text, term)
+		return nil
+	}
+	if i == 0 {
+		return &QueryError{Pos: tok.pos, Msg: fmt.Sprintf("missing field name before %q", term[:1])}
+	}
+
+	name := strings.ToLower(term[:i])
+	field := lookupQueryField(name)
+	if field == nil {
+		msg := fmt.Sprintf("unknown field %q", name)
+		if s := suggestQueryField(name); s != "" {
+			msg += fmt.Sprintf(", did you mean %q?", s)
+		} else {
+			msg += ` (quote text containing ":")`
+		}
+		return &QueryError{Pos: tok.pos, Msg: msg}
+	}
+
+	op, value := splitQueryOp(term[i:])
+	valuePos := tok.pos + i + len(op)
+	if value == "" {
+		return &QueryError{Pos: valuePos, Msg: fmt.Sprintf("%s: missing value, e.g. %s", field.name, field.example)}
+	}
+	if field.numeric {
+		return applyQueryComparison(f, field.name, op, value, valuePos)
+	}
+	if op != ":" {
+		return &QueryError{Pos: tok.pos + i, Msg: fmt.Sprintf("%s doesn't support %q, use %s:", field.name, op, field.name)}
+	}
+
+	switch field.name {
+	case "adapter":
+		for _, v := range splitQueryList(value) {
+			f.Adapters = append(f.Adapters, strings.ToLower(v))
+		}
+	case "model":
+		for _, v := range splitQueryList(value) {
+			f.Models = append(f.Models, strings.ToLower(v))
+		}
+	case "category":
+		for _, v := range splitQueryList(value) {
+			v = strings.ToLower(v)
+			if !slices.Contains(queryCategoryValues, v) {
+				return &QueryError{Pos: valuePos, Msg: fmt.Sprintf("unknown category %q (%s)", v, strings.Join(queryCategoryValues, ", "))}
+			}
+			f.Categories = append(f.Categories, v)
+		}
+	case "worktree":
+		f.Worktrees = append(f.Worktrees, splitQueryList(value)...)
+	case "tag":
+		for _, v := range splitQueryList(value) {
+			f.Tags = append(f.Tags, strings.ToLower(strings.TrimPrefix(v, "#")))
+		}
+	case "is":
+		switch strings.ToLower(value) {
+		case "active":
+			f.ActiveOnly = true
+		case "starred":
+			f.Starred = true
+		default:
+			return &QueryError{Pos: valuePos, Msg: fmt.Sprintf("is: expects %s, not %q", strings.Join(queryIsValues, " or "), value)}
+		}
+	case "has":
+		kind, arg, _ := strings.Cut(value, ":")
+		switch strings.ToLower(kind) {
+		case "tool":
+			if arg == "" {
+				return &QueryError{Pos: valuePos, Msg: "has:tool: missing tool name, e.g. has:tool:Bash"}
+			}
+			f.Tools = append(f.Tools, arg)
+		case "file":
+			if arg == "" {
+				return &QueryError{Pos: valuePos, Msg: "has:file: missing path, e.g. has:file:auth.go"}
+			}
+			f.HasFiles = append(f.HasFiles, arg)
+		default:
+			return &QueryError{Pos: valuePos, Msg: fmt.Sprintf("has: expects tool:NAME or file:PATH, not %q", value)}
+		}
+	case "date":
+		preset := strings.ToLower(value)
+		if !slices.Contains(queryDateValues, preset) {
+			return &QueryError{Pos: valuePos, Msg: fmt.Sprintf("date: expects %s, not %q", strings.Join(queryDateValues, ", "), value)}
+		}
+		f.DateRange = DateRange{}
+		f.SetDateRange(preset)
+	case "after", "before":
+		t, err := parseQueryDate(value, time.Now())
+		if err != nil {
+			return &QueryError{Pos: valuePos, Msg: fmt.Sprintf("%s: %v", field.name, err)}
+		}
+		f.DateRange.Preset = ""
+		if field.name == "after" {
+			f.DateRange.Start = t
+		} else {
+			f.DateRange.End = t
+		}
+	}
+	return nil
+}
+
+// splitQueryOp splits ">=2" into ">=" and "2".
+func splitQueryOp(s string) (op, value string) {
+	for _, op := range []string{">=", "<=", ">", "<", "=", ":"} {
+		if v, ok := strings.CutPrefix(s, op); ok {
+			return op, v
+		}
+	}
+	return s[:1], s[1:]
+}
+
+// splitQueryList splits a comma-separated value, dropping empty items.
+func splitQueryList(value string) []string {
+	var items []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			items = append(items, v)
+		}
+	}
+	return items
+}
+
+// applyQueryComparison applies cost, tokens or msgs comparisons. Bounds
+// are stored inclusively; ":" and "=" set both.
+func applyQueryComparison(f *SearchFilters, field, op, value string, pos int) error {
+	if field == "cost" {
+		v, err := strconv.ParseFloat(strings.TrimPrefix(value, "$"), 64)
+		if err != nil || v < 0 {
+			return &QueryError{Pos: pos, Msg: fmt.Sprintf("cost: %q isn't a dollar amount, e.g. cost>2.50", value)}
+		}
+		lo, hi := v, v
+		switch op {
+		case ">":
+			lo = math.Nextafter(v, math.Inf(1))
+		case "<":
+			hi = math.Nextafter(v, math.Inf(-1))
+		}
+		if op != "<" && op != "<=" {
+			f.MinCost = lo
+		}
+		if op != ">" && op != ">=" {
+			if hi <= 0 {
+				return &QueryError{Pos: pos, Msg: fmt.Sprintf("cost%s%s matches nothing", op, value)}
+			}
+			f.MaxCost = hi
+		}
+		return nil
+	}
+
+	n, err := parseQueryCount(value)
+	if err != nil {
+		return &QueryError{Pos: pos, Msg: fmt.Sprintf("%s: %q isn't a count, e.g. %s>100k", field, value, field)}
+	}
+	lo, hi := n, n
+	switch op {
+	case ">":
+		lo = n + 1
+	case "<":
+		hi = n - 1
+	}
+	minN, maxN := &f.MinTokens, &f.MaxTokens
+	if field == "msgs" {
+		minN, maxN = &f.MinMessages, &f.MaxMessages
+	}
+	if op != "<" && op != "<=" {
+		*minN = lo
+	}
+	if op != ">" && op != ">=" {
+		if hi <= 0 {
+			return &QueryError{Pos: pos, Msg: fmt.Sprintf("%s%s%s matches nothing", field, op, value)}
+		}
+		*maxN = hi
+	}
+	return nil
+}
+
+// parseQueryCount parses counts like 500, 100k or 1.5M.
+func parseQueryCount(s string) (int, error) {
+	mult := 1.0
+	switch {
+	case strings.HasSuffix(s, "k"), strings.HasSuffix(s, "K"):
+		mult, s = 1e3, s[:len(s)-1]
+	case strings.HasSuffix(s, "m"), strings.HasSuffix(s, "M"):
+		mult, s = 1e6, s[:len(s)-1]
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid count")
+	}
+	return int(v * mult), nil
+}
+
+// parseQueryDate parses YYYY-MM-DD or a relative day count like 30d,
+// returning the start of that day in local time.
+func parseQueryDate(s string, now time.Time) (time.Time, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return time.Time{}, fmt.Errorf("%q isn't a day count, e.g. 30d", s)
+		}
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		return today.AddDate(0, 0, -n), nil
+	}
+	t, err := time.ParseInLocation(queryDateLayout, s, now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q isn't a date, use YYYY-MM-DD or 30d", s)
+	}
+	return t, nil
+}
+
+// suggestQueryField returns the field closest to a misspelled name: one
+// within two edits, or else one the name is a prefix of.
+func suggestQueryField(name string) string {
+	best, bestDist := "", 3
+	for _, f := range queryFields {
+		for _, n := range append([]string{f.name}, f.aliases...) {
+			if d := editDistance(name, n); d < bestDist {
+				best, bestDist = f.name, d
+			}
+		}
+	}
+	if best != "" {
+		return best
+	}
+	for _, f := range queryFields {
+		if strings.HasPrefix(f.name, name) {
+			return f.name
+		}
+	}
+	return ""
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// completeQuery completes the last term of a query. values supplies the
+// known values for a field ("adapter", "tool", ...) and saved the saved
+// query names. It returns the completed query and the candidates for the
+// term, for display when there's more than one.
+func completeQuery(input string, values func(field string) []string, saved []string) (string, []string) {
+	start := strings.LastIndexFunc(input, unicode.IsSpace) + 1
+	head, term := input[:start], input[start:]
+
+	var prefix string // part of the term kept as is
+	var candidates []string
+	switch {
+	case strings.HasPrefix(term, "@"):
+		for _, name := range saved {
+			candidates = append(candidates, "@"+name)
+		}
+	case strings.HasPrefix(term, "#"):
+		for _, tag := range values("tag") {
+			candidates = append(candidates, "#"+tag)
+		}
+	case strings.Contains(term, ":"):
+		i := strings.Index(term, ":")
+		field := lookupQueryField(strings.ToLower(term[:i]))
+		if field == nil {
+			return input, nil
+		}
+		prefix, term = term[:i+1], term[i+1:]
+		var opts []string
+		switch field.name {
+		case "is":
+			opts = queryIsValues
+		case "date":
+			opts = queryDateValues
+		case "category":
+			opts = queryCategoryValues
+		case "has":
+			if kind, _, ok := strings.Cut(term, ":"); ok {
+				prefix += kind + ":"
+				term = term[len(kind)+1:]
+				if strings.EqualFold(kind, "tool") {
+					opts = values("tool")
+				}
+			} else {
+				opts = queryHasValues
+			}
+		default:
+			opts = values(field.name)
+		}
+		// Complete the last item of a comma list
+		if j := strings.LastIndex(term, ","); j >= 0 {
+			prefix += term[:j+1]
+			term = term[j+1:]
+		}
+		candidates = opts
+	default:
+		for _, f := range queryFields {
+			if f.numeric {
+				candidates = append(candidates, f.name)
+			} else {
+				candidates = append(candidates, f.name+":")
+			}
+		}
+	}
+
+	var matches []string
+	lower := strings.ToLower(term)
+	for _, c := range candidates {
+		if strings.HasPrefix(strings.ToLower(c), lower) && !slices.Contains(matches, c) {
+			matches = append(matches, c)
+		}
+	}
+	slices.Sort(matches)
+	if len(matches) == 0 {
+		return input, nil
+	}
+
+	completed := commonPrefix(matches)
+	if len(completed) < len(term) {
+		completed = term
+	}
+	if len(matches) == 1 && !strings.HasSuffix(completed, ":") && lookupQueryField(completed) == nil {
+		completed += " "
+	}
+	return head + prefix + completed, matches
+}
+
+// commonPrefix returns the longest prefix shared by all of ss,
+// case-insensitively, in the case of the first.
+func commonPrefix(ss []string) string {
+	p := ss[0]
+	for _, s := range ss[1:] {
+		n := 0
+		for n < len(p) && n < len(s) && unicode.ToLower(rune(p[n])) == unicode.ToLower(rune(s[n])) {
+			n++
+		}
+		p = p[:n]
+	}
+	return p
+}
@@ -0,0 +1,228 @@
+package conversations
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/adapter"
+)
+
+// sessionMessageInfo records what a session's messages used, for the
+// model, tool and file query filters and for budgets.
+type sessionMessageInfo struct {
+	UpdatedAt time.Time // session UpdatedAt when indexed
+	Models    []string
+	Tools     []string
+	Files     []string
+	Calls     []toolCall
+	Usage     []messageUsage
+
+	// Session totals when indexed, so budgets can place later growth
+	EstCost     float64
+	TotalTokens int
+}
+
+// messageUsage is one message's token use and estimated cost.
+type messageUsage struct {
+	At     time.Time // zero if no message so far had a timestamp
+	Tokens int
+	Cost   float64
+}
+
+// MessageIndexMsg delivers indexed message info for sessions.
+type MessageIndexMsg struct {
+	Epoch uint64
+	Infos map[string]*sessionMessageInfo
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m MessageIndexMsg) GetEpoch() uint64 { return m.Epoch }
+
+// indexSessionMessages collects the models, tools and files used in a
+// session's messages.
+func indexSessionMessages(messages []adapter.Message) *sessionMessageInfo {
+	info := &sessionMessageInfo{}
+	add := func(list *[]string, v string) {
+		if v != "" && !slices.Contains(*list, v) {
+			*list = append(*list, v)
+		}
+	}
+	var at time.Time
+	for _, msg := range messages {
+		add(&info.Models, msg.Model)
+		// Messages without a timestamp are placed with the one before
+		if !msg.Timestamp.IsZero() {
+			at = msg.Timestamp
+		}
+		if u := msg.TokenUsage; u != (adapter.TokenUsage{}) {
+			info.Usage = append(info.Usage, messageUsage{
+				At:     at,
+				Tokens: u.InputTokens + u.OutputTokens + u.CacheRead + u.CacheWrite,
+				Cost:   estimateTotalCost(msg.Model, u.InputTokens, u.OutputTokens, u.CacheRead, u.CacheWrite),
+			})
+		}
+		for _, tu := range msg.ToolUses {
+			add(&info.Tools, tu.Name)
+			add(&info.Files, extractFilePath(tu.Input))
+		}
+		for _, b := range msg.ContentBlocks {
+			if b.Type == "tool_use" {
+				add(&info.Tools, b.ToolName)
+				add(&info.Files, extractFilePath(b.ToolInput))
+			}
+		}
+	}
+	for _, c := range ExtractFileChanges(messages) {
+		add(&info.Files, c.Path)
+		add(&info.Files, c.NewPath)
+	}
+	info.Calls = extractToolCalls(messages)
+	return info
+}
+
+// messageInfoFor returns a session's indexed message info, or nil if it
+// hasn't been indexed since it last changed.
+func (p *Plugin) messageInfoFor(s adapter.Session) *sessionMessageInfo {
+	info := p.messageIndex[s.ID]
+	if info == nil || info.UpdatedAt.Before(s.UpdatedAt) {
+		return nil
+	}
+	return info
+}
+
+// sessionMatches checks a session against filters, including its
+// annotations and indexed messages.
+func (p *Plugin) sessionMatches(f *SearchFilters, s adapter.Session) bool {
+	return f.Matches(s) &&
+		f.MatchesAnnotations(p.annotationsFor(s)) &&
+		f.MatchesMessages(p.messageInfoFor(s))
+}
+
+// isIndexingMessages returns true while sessions needed by f are being
+// indexed, so results may still grow.
+func (p *Plugin) isIndexingMessages(f *SearchFilters) bool {
+	return p.messageIndexing && f.NeedsMessages()
+}
+
+// indexMessagesFor returns a command indexing the messages of sessions
+// the filters need, or nil if there are none. Only sessions passing the
+// cheaper filters are indexed.
+func (p *Plugin) indexMessagesFor(f SearchFilters) tea.Cmd {
+	if !f.NeedsMessages() {
+		return nil
+	}
+	var sessions []adapter.Session
+	for _, s := range p.sessions {
+		if f.Matches(s) && f.MatchesAnnotations(p.annotationsFor(s)) {
+			sessions = append(sessions, s)
+		}
+	}
+	return p.indexSessions(sessions)
+}
+
+// indexSessions returns a command indexing the messages of sessions that
+// haven't been indexed since they last changed, or nil if there are none.
+// Huge sessions and ones without messages are skipped.
+func (p *Plugin) indexSessions(sessions []adapter.Session) tea.Cmd {
+	if p.messageIndexing {
+		return nil
+	}
+
+	type job struct {
+		session adapter.Session
+		adapter adapter.Adapter
+	}
+	var jobs []job
+	for _, s := range sessions {
+		if s.MessageCount == 0 || s.SizeLevel() >= 2 || p.messageInfoFor(s) != nil {
+			continue
+		}
+		if a := p.adapters[s.AdapterID]; a != nil {
+			jobs = append(jobs, job{session: s, adapter: a})
+		}
+	}
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	var epoch uint64
+	if p.ctx != nil {
+		epoch = p.ctx.Epoch
+	}
+	p.messageIndexing = true
+	return func() tea.Msg {
+		infos := make(map[string]*sessionMessageInfo, len(jobs))
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, searchConcurrency())
+		for _, j := range jobs {
+			wg.Add(1)
+			go func(j job) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				// Unreadable sessions get an empty entry so they aren't retried
+				info := &sessionMessageInfo{}
+				if messages, err := j.adapter.Messages(j.session.ID); err == nil {
+					info = indexSessionMessages(messages)
+				}
+				info.UpdatedAt = j.session.UpdatedAt
+				info.EstCost, info.TotalTokens = j.session.EstCost, j.session.TotalTokens
+				for i := range info.Calls {
+					info.Calls[i].SessionID = j.session.ID
+				}
+				mu.Lock()
+				infos[j.session.ID] = info
+				mu.Unlock()
+			}(j)
+		}
+		wg.Wait()
+		return MessageIndexMsg{Epoch: epoch, Infos: infos}
+	}
+}
+
+// applyMessageIndex stores indexed info and refreshes the session list.
+// It indexes again if sessions changed while indexing.
+func (p *Plugin) applyMessageIndex(msg MessageIndexMsg) tea.Cmd {
+	p.messageIndexing = false
+	if p.messageIndex == nil {
+		p.messageIndex = make(map[string]*sessionMessageInfo)
+	}
+	for id, info := range msg.Infos {
+		p.messageIndex[id] = info
+	}
+	p.hitRegionsDirty = true
+
+	// Budgets count indexed sessions by message time
+	return tea.Batch(p.refreshIndexedViews(), p.checkBudgets())
+}
+
+// refreshIndexedViews refreshes views that depend on the message index and
+// returns a command indexing any sessions they still need.
+func (p *Plugin) refreshIndexedViews() tea.Cmd {
+	if p.view == ViewToolAnalytics {
+		p.refreshToolReport()
+		return p.indexSessions(p.toolAnalyticsSessions())
+	}
+
+	var next tea.Cmd
+	switch {
+	case p.searchMode:
+		p.filterSessions()
+		if p.searchErr == nil {
+			next = p.indexMessagesFor(p.searchFilters)
+		}
+	case p.filterActive:
+		next = p.indexMessagesFor(p.filters)
+	default:
+		return nil
+	}
+	if n := len(p.visibleSessions()); p.cursor >= n {
+		p.cursor = max(n-1, 0)
+		p.ensureCursorVisible()
+	}
+	return next
+}
@@ -0,0 +1,300 @@
+package conversations
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+func TestParseQuery(t *testing.T) {
+	f, err := ParseQuery(`adapter:codex model:opus cost>2 tokens>100k worktree:feat/* after:2026-09-01 has:tool:Bash "db migration" fix`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(f.Adapters, ",") != "codex" || strings.Join(f.Models, ",") != "opus" {
+		t.Errorf("adapters = %v, models = %v", f.Adapters, f.Models)
+	}
+	if f.MinCost <= 2 || f.MinCost != math.Nextafter(2, 3) || f.MaxCost != 0 {
+		t.Errorf("cost = %v..%v", f.MinCost, f.MaxCost)
+	}
+	if f.MinTokens != 100001 || f.MaxTokens != 0 {
+		t.Errorf("tokens = %d..%d", f.MinTokens, f.MaxTokens)
+	}
+	if strings.Join(f.Worktrees, ",") != "feat/*" || strings.Join(f.Tools, ",") != "Bash" {
+		t.Errorf("worktrees = %v, tools = %v", f.Worktrees, f.Tools)
+	}
+	if want := time.Date(2026, 9, 1, 0, 0, 0, 0, time.Local); !f.DateRange.Start.Equal(want) || !f.DateRange.End.IsZero() {
+		t.Errorf("date range = %+v", f.DateRange)
+	}
+	if f.Query != "db migration fix" {
+		t.Errorf("Query = %q", f.Query)
+	}
+
+	f, err = ParseQuery(`msgs<=20 cost:1.5 is:starred is:active #inc tag:"Good-Prompt" category:cron,system has:file:auth.go`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.MaxMessages != 20 || f.MinMessages != 0 || f.MinCost != 1.5 || f.MaxCost != 1.5 {
+		t.Errorf("msgs = %d..%d, cost = %v..%v", f.MinMessages, f.MaxMessages, f.MinCost, f.MaxCost)
+	}
+	if !f.Starred || !f.ActiveOnly || strings.Join(f.Tags, ",") != "inc*,good-prompt" {
+		t.Errorf("starred = %v, active = %v, tags = %v", f.Starred, f.ActiveOnly, f.Tags)
+	}
+	if strings.Join(f.Categories, ",") != "cron,system" || strings.Join(f.HasFiles, ",") != "auth.go" {
+		t.Errorf("categories = %v, files = %v", f.Categories, f.HasFiles)
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		pos   int
+		msg   string
+	}{
+		{`modle:opus`, 0, `did you mean "model"?`},
+		{`fix http://x`, 4, `quote text containing ":"`},
+		{`adapter:`, 8, "missing value, e.g. adapter:"},
+		{`cost>abc`, 5, "isn't a dollar amount"},
+		{`tokens>lots`, 7, "isn't a count"},
+		{`tokens<1`, 7, "matches nothing"},
+		{`adapter>codex`, 7, `doesn't support ">"`},
+		{`after:yesterday`, 6, "isn't a date"},
+		{`date:decade`, 5, "date: expects today"},
+		{`is:old`, 3, "is: expects active or starred"},
+		{`has:commit`, 4, "has: expects tool:NAME or file:PATH"},
+		{`has:tool:`, 4, "missing tool name"},
+		{`category:chat`, 9, "unknown category"},
+		{`say "hello`, 4, "unterminated quote"},
+		{`:x`, 0, "missing field name"},
+		{`@nope`, 0, "no saved query @nope"},
+	}
+	for _, tt := range tests {
+		_, err := ParseQuery(tt.query, nil)
+		qe, ok := err.(*QueryError)
+		if !ok {
+			t.Errorf("%q: err = %v, want a QueryError", tt.query, err)
+			continue
+		}
+		if qe.Pos != tt.pos || !strings.Contains(qe.Msg, tt.msg) {
+			t.Errorf("%q: got %d %q, want %d %q", tt.query, qe.Pos, qe.Msg, tt.pos, tt.msg)
+		}
+	}
+}
+
+func TestParseQuerySaved(t *testing.T) {
+	saved := map[string]string{
+		"costly": "cost>=5 tokens>1M",
+		"bash":   "@costly has:tool:Bash",
+		"loop":   "@loop",
+		"broken": "cost>",
+	}
+	f, err := ParseQuery("@bash adapter:codex", saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.MinCost != 5 || f.MinTokens != 1000001 || strings.Join(f.Tools, ",") != "Bash" || f.HasAdapter("codex") != true {
+		t.Errorf("expanded = %+v", f)
+	}
+
+	if _, err := ParseQuery("@loop", saved); err == nil || !strings.Contains(err.Error(), "nest too deeply") {
+		t.Errorf("loop err = %v", err)
+	}
+	// Errors inside a saved query point at the @name
+	_, err = ParseQuery("fix @broken", saved)
+	if qe, ok := err.(*QueryError); !ok || qe.Pos != 4 || !strings.HasPrefix(qe.Msg, "@broken: cost: missing value") {
+		t.Errorf("broken err = %v", err)
+	}
+}
+
+func TestCompleteQuery(t *testing.T) {
+	values := func(field string) []string {
+		switch field {
+		case "adapter":
+			return []string{"claude-code", "codex", "cursor"}
+		case "tool":
+			return []string{"Bash", "Read"}
+		case "tag":
+			return []string{"incident"}
+		}
+		return nil
+	}
+	saved := []string{"costly", "cost-week"}
+	tests := []struct {
+		input string
+		want  string
+		hints int
+	}{
+		{"ad", "adapter:", 1},
+		{"co", "cost", 1},
+		{"adapter:c", "adapter:c", 3},
+		{"adapter:cl", "adapter:claude-code ", 1},
+		{"adapter:codex,cu", "adapter:codex,cursor ", 1},
+		{"fix has:t", "fix has:tool:", 1},
+		{"has:tool:b", "has:tool:Bash ", 1},
+		{"is:s", "is:starred ", 1},
+		{"@co", "@cost", 2},
+		{"#in", "#incident ", 1},
+		{"zzz", "zzz", 0},
+		{"nope:x", "nope:x", 0},
+	}
+	for _, tt := range tests {
+		got, hints := completeQuery(tt.input, values, saved)
+		if got != tt.want || len(hints) != tt.hints {
+			t.Errorf("completeQuery(%q) = %q %v, want %q with %d hints", tt.input, got, hints, tt.want, tt.hints)
+		}
+	}
+}
+
+func TestSearchFiltersQueryFields(t *testing.T) {
+	s := adapter.Session{ID: "s1", WorktreeName: "feat/login", EstCost: 3, MessageCount: 12,
+		UpdatedAt: time.Date(2026, 9, 10, 12, 0, 0, 0, time.Local)}
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"worktree:feat/*", true},
+		{"worktree:fix/*,feat/login", true},
+		{"worktree:main", false},
+		{"cost>2", true},
+		{"cost>3", false},
+		{"cost<=3", true},
+		{"msgs>=12 msgs<20", true},
+		{"msgs>12", false},
+		{"after:2026-09-01 before:2026-09-30", true},
+		{"after:2026-09-11", false},
+	}
+	for _, tt := range tests {
+		f, err := ParseQuery(tt.query, nil)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.query, err)
+		}
+		if got := f.Matches(s); got != tt.want {
+			t.Errorf("%q: Matches = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSearchFiltersMatchesMessages(t *testing.T) {
+	info := &sessionMessageInfo{
+		Models: []string{"claude-opus-4-20250514"},
+		Tools:  []string{"Bash", "Edit"},
+		Files:  []string{"/src/auth/login.go"},
+	}
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"model:opus", true},
+		{"model:sonnet", false},
+		{"model:sonnet,opus", true},
+		{"has:tool:bash has:tool:Edit", true},
+		{"has:tool:Write", false},
+		{"has:file:login.go", true},
+		{"has:file:auth/login.go", true},
+		{"has:file:gin.go", false},
+		{"has:file:*.go", true},
+	}
+	for _, tt := range tests {
+		f, _ := ParseQuery(tt.query, nil)
+		if got := f.MatchesMessages(info); got != tt.want {
+			t.Errorf("%q: MatchesMessages = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+	// Sessions that haven't been indexed don't match message filters
+	if f, _ := ParseQuery("has:tool:Bash", nil); f.MatchesMessages(nil) {
+		t.Error("unindexed session matched")
+	}
+}
+
+// toolAdapter returns messages calling the given tools for each session.
+type toolAdapter struct {
+	mockAdapter
+	tools map[string][]string
+}
+
+func (a *toolAdapter) Messages(sessionID string) ([]adapter.Message, error) {
+	msg := adapter.Message{ID: sessionID + "-1", Role: "assistant", Model: "claude-opus-4"}
+	for _, name := range a.tools[sessionID] {
+		msg.ToolUses = append(msg.ToolUses, adapter.ToolUse{Name: name, Input: `{"file_path":"/src/main.go"}`})
+	}
+	return []adapter.Message{msg}, nil
+}
+
+func TestSearchQueryIndexesMessages(t *testing.T) {
+	p := New()
+	p.ctx = &plugin.Context{WorkDir: "/proj"}
+	p.adapters = map[string]adapter.Adapter{"mock": &toolAdapter{tools: map[string][]string{
+		"s1": {"Bash"},
+		"s2": {"Read"},
+	}}}
+	now := time.Now()
+	p.sessions = []adapter.Session{
+		{ID: "s1", AdapterID: "mock", MessageCount: 1, UpdatedAt: now},
+		{ID: "s2", AdapterID: "mock", MessageCount: 1, UpdatedAt: now.Add(-time.Minute)},
+		{ID: "s3", AdapterID: "mock", UpdatedAt: now.Add(-2 * time.Minute)},
+	}
+	p.searchMode = true
+	p.searchQuery = "has:tool:Bash"
+
+	cmd := p.searchQueryChanged()
+	if len(p.searchResults) != 0 || !p.isIndexingMessages(&p.searchFilters) {
+		t.Fatalf("before indexing: results = %v, indexing = %v", sessionIDs(p.searchResults), p.messageIndexing)
+	}
+	if !strings.Contains(p.renderSearchLine(80), "indexing…") {
+		t.Error("search line should show indexing")
+	}
+
+	var indexed MessageIndexMsg
+	for _, m := range runBatch(cmd) {
+		if im, ok := m.(MessageIndexMsg); ok {
+			indexed = im
+		}
+	}
+	if len(indexed.Infos) != 2 {
+		t.Fatalf("indexed %d sessions, want 2 (s3 has no messages)", len(indexed.Infos))
+	}
+	if next := p.applyMessageIndex(indexed); next != nil {
+		t.Error("nothing left to index")
+	}
+	if got := sessionIDs(p.searchResults); len(got) != 1 || got[0] != "s1" {
+		t.Errorf("results = %v", got)
+	}
+
+	// A session that changed since indexing needs indexing again
+	p.sessions[0].UpdatedAt = now.Add(time.Minute)
+	if p.messageInfoFor(p.sessions[0]) != nil || p.indexMessagesFor(p.searchFilters) == nil {
+		t.Error("stale index entry should be re-indexed")
+	}
+
+	// Syntax errors are shown and keep the last results
+	p.searchQuery = "has:tool:Bash cost>"
+	p.searchQueryChanged()
+	if p.searchErr == nil || len(p.searchResults) != 1 {
+		t.Errorf("err = %v, results = %v", p.searchErr, sessionIDs(p.searchResults))
+	}
+	if line := p.renderSearchLine(200); !strings.Contains(line, "✗ cost: missing value") {
+		t.Errorf("search line = %q", line)
+	}
+}
+
+// runBatch runs a command, flattening batches, and returns the messages.
+func runBatch(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	msg := cmd()
+	batch, ok := msg.(tea.BatchMsg)
+	if !ok {
+		return []tea.Msg{msg}
+	}
+	var msgs []tea.Msg
+	for _, c := range batch {
+		msgs = append(msgs, runBatch(c)...)
+	}
+	return msgs
+}
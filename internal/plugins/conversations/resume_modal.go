@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -16,8 +17,10 @@ import (
 
 // Resume target type constants
 const (
-	resumeTypeShell    = 0
-	resumeTypeWorktree = 1
+	resumeTypeShell           = 0
+	resumeTypeWorktree        = 1
+	resumeTypeHandoffNew      = 2 // hand off to another agent in a new worktree
+	resumeTypeHandoffExisting = 3 // hand off to another agent in an existing worktree
 )
 
 // Modal field IDs
@@ -26,15 +29,17 @@ const (
 	resumeNameFieldID     = "resume-name"
 	resumeBaseFieldID     = "resume-base"
 	resumeAgentListID     = "resume-agent-list"
+	resumeWorktreeListID  = "resume-worktree-list"
 	resumeSkipPermsID     = "resume-skip-perms"
 	resumeSubmitID        = "resume-submit"
 	resumeCancelID        = "resume-cancel"
 	resumeTypeItemPrefix  = "resume-type-"
 	resumeAgentItemPrefix = "resume-agent-"
+	resumeWTItemPrefix    = "resume-worktree-"
 )
 
 // resumeTypeLabels are the options for resume type selection
-var resumeTypeLabels = []string{"Shell", "New Worktree"}
+var resumeTypeLabels = []string{"Shell", "New Worktree", "Handoff: New Worktree", "Handoff: Existing Worktree"}
 
 // ensureResumeModal builds or caches the resume modal.
 func (p *Plugin) ensureResumeModal() {
@@ -74,6 +79,15 @@ func (p *Plugin) ensureResumeModal() {
 		}
 	}
 
+	// Build existing worktree list for handoffs
+	worktreeItems := make([]modal.ListItem, len(p.resumeWorktreeLabels))
+	for i, label := range p.resumeWorktreeLabels {
+		worktreeItems[i] = modal.ListItem{
+			ID:    fmt.Sprintf("%s%d", resumeWTItemPrefix, i),
+			Label: label,
+		}
+	}
+
 	p.resumeModal = modal.New("Resume in Workspace",
 		modal.WithWidth(modalW),
 		modal.WithPrimaryAction(resumeSubmitID),
@@ -82,7 +96,7 @@ func (p *Plugin) ensureResumeModal() {
 		AddSection(p.resumeSessionInfoSection()).
 		AddSection(modal.Spacer()).
 		AddSection(modal.Text("Resume in:")).
-		AddSection(modal.List(resumeTypeListID, typeItems, &p.resumeType, modal.WithMaxVisible(2))).
+		AddSection(modal.List(resumeTypeListID, typeItems, &p.resumeType, modal.WithMaxVisible(len(typeItems)))).
 		AddSection(modal.Spacer()).
 		// Worktree-specific fields (shown when type == worktree)
 		AddSection(modal.When(p.isResumeWorktreeMode, modal.Text("Branch name:"))).
@@ -91,8 +105,12 @@ func (p *Plugin) ensureResumeModal() {
 		AddSection(modal.When(p.isResumeWorktreeMode, modal.Text("Base branch:"))).
 		AddSection(modal.When(p.isResumeWorktreeMode, modal.Input(resumeBaseFieldID, &p.resumeBaseBranchInput, modal.WithSubmitOnEnter(false)))).
 		AddSection(modal.When(p.isResumeWorktreeMode, modal.Spacer())).
-		AddSection(modal.When(p.isResumeWorktreeMode, modal.Text("Agent:"))).
-		AddSection(modal.When(p.isResumeWorktreeMode, modal.List(resumeAgentListID, agentItems, &p.resumeAgentIdx, modal.WithMaxVisible(len(agentItems))))).
+		// Existing worktree list (shown for handoff into an existing worktree)
+		AddSection(modal.When(p.isResumeExistingMode, modal.Text("Worktree:"))).
+		AddSection(modal.When(p.isResumeExistingMode, modal.List(resumeWorktreeListID, worktreeItems, &p.resumeWorktreeIdx, modal.WithMaxVisible(min(len(worktreeItems), 5))))).
+		AddSection(modal.When(p.isResumeExistingMode, modal.Spacer())).
+		AddSection(modal.When(p.isResumeAgentMode, modal.Text("Agent:"))).
+		AddSection(modal.When(p.isResumeAgentMode, modal.List(resumeAgentListID, agentItems, &p.resumeAgentIdx, modal.WithMaxVisible(len(agentItems))))).
 		AddSection(modal.When(p.shouldShowResumeSkipPerms, modal.Spacer())).
 		AddSection(modal.When(p.shouldShowResumeSkipPerms, modal.Checkbox(resumeSkipPermsID, "Auto-approve all actions", &p.resumeSkipPermissions))).
 		AddSection(modal.Spacer()).
@@ -129,14 +147,29 @@ func (p *Plugin) resumeSessionInfoSection() modal.Section {
 	)
 }
 
-// isResumeWorktreeMode returns true when worktree type is selected.
+// isResumeWorktreeMode returns true when a new worktree will be created.
 func (p *Plugin) isResumeWorktreeMode() bool {
-	return p.resumeType == resumeTypeWorktree
+	return p.resumeType == resumeTypeWorktree || p.resumeType == resumeTypeHandoffNew
+}
+
+// isResumeExistingMode returns true when handing off into an existing worktree.
+func (p *Plugin) isResumeExistingMode() bool {
+	return p.resumeType == resumeTypeHandoffExisting
+}
+
+// isResumeHandoffMode returns true when a handoff type is selected.
+func (p *Plugin) isResumeHandoffMode() bool {
+	return p.resumeType == resumeTypeHandoffNew || p.resumeType == resumeTypeHandoffExisting
+}
+
+// isResumeAgentMode returns true when an agent is started in a worktree.
+func (p *Plugin) isResumeAgentMode() bool {
+	return p.resumeType != resumeTypeShell
 }
 
 // shouldShowResumeSkipPerms returns true when skip permissions checkbox should show.
 func (p *Plugin) shouldShowResumeSkipPerms() bool {
-	if !p.isResumeWorktreeMode() {
+	if !p.isResumeAgentMode() {
 		return false
 	}
 	if p.resumeAgentIdx < 0 || p.resumeAgentIdx >= len(workspace.AgentTypeOrder) {
@@ -167,25 +200,31 @@ func (p *Plugin) handleResumeModalKeys(msg tea.KeyMsg) tea.Cmd {
 		return nil
 	}
 
-	// Handle type item selection
-	if strings.HasPrefix(action, resumeTypeItemPrefix) {
-		var idx int
+	p.applyResumeListAction(action)
+
+	return cmd
+}
+
+// applyResumeListAction applies a type, agent or worktree list selection.
+func (p *Plugin) applyResumeListAction(action string) {
+	var idx int
+	switch {
+	case strings.HasPrefix(action, resumeTypeItemPrefix):
 		_, _ = fmt.Sscanf(action, resumeTypeItemPrefix+"%d", &idx)
 		if idx >= 0 && idx < len(resumeTypeLabels) {
 			p.resumeType = idx
 		}
-	}
-
-	// Handle agent item selection
-	if strings.HasPrefix(action, resumeAgentItemPrefix) {
-		var idx int
+	case strings.HasPrefix(action, resumeAgentItemPrefix):
 		_, _ = fmt.Sscanf(action, resumeAgentItemPrefix+"%d", &idx)
 		if idx >= 0 && idx < len(workspace.AgentTypeOrder) {
 			p.resumeAgentIdx = idx
 		}
+	case strings.HasPrefix(action, resumeWTItemPrefix):
+		_, _ = fmt.Sscanf(action, resumeWTItemPrefix+"%d", &idx)
+		if idx >= 0 && idx < len(p.resumeWorktreePaths) {
+			p.resumeWorktreeIdx = idx
+		}
 	}
-
-	return cmd
 }
 
 // handleResumeModalMouse handles mouse input for the resume modal.
@@ -204,23 +243,7 @@ func (p *Plugin) handleResumeModalMouse(msg tea.MouseMsg) tea.Cmd {
 		return nil
 	}
 
-	// Handle type item selection
-	if strings.HasPrefix(action, resumeTypeItemPrefix) {
-		var idx int
-		_, _ = fmt.Sscanf(action, resumeTypeItemPrefix+"%d", &idx)
-		if idx >= 0 && idx < len(resumeTypeLabels) {
-			p.resumeType = idx
-		}
-	}
-
-	// Handle agent item selection
-	if strings.HasPrefix(action, resumeAgentItemPrefix) {
-		var idx int
-		_, _ = fmt.Sscanf(action, resumeAgentItemPrefix+"%d", &idx)
-		if idx >= 0 && idx < len(workspace.AgentTypeOrder) {
-			p.resumeAgentIdx = idx
-		}
-	}
+	p.applyResumeListAction(action)
 
 	return nil
 }
@@ -250,17 +273,13 @@ func (p *Plugin) openResumeModal() tea.Cmd {
 		}
 	}
 
-	// Check if adapter supports resume
-	cmd := resumeCommand(session)
-	if cmd == "" {
-		return func() tea.Msg {
-			return app.ToastMsg{Message: "Resume not supported for " + session.AdapterName, IsError: true}
-		}
-	}
-
-	// Initialize modal state
+	// Initialize modal state. Sessions that can't be resumed directly can
+	// still be handed off to an agent.
 	p.resumeSession = session
 	p.resumeType = resumeTypeShell // Default to shell
+	if session.Imported || resumeCommand(session) == "" {
+		p.resumeType = resumeTypeHandoffNew
+	}
 	p.resumeFocus = 0
 
 	// Initialize name input with sanitized session name
@@ -279,6 +298,14 @@ func (p *Plugin) openResumeModal() tea.Cmd {
 	p.resumeAgentIdx = defaultAgentIdxForAdapter(session.AdapterID)
 	p.resumeSkipPermissions = false
 
+	// Offer the project's worktrees as handoff targets
+	var workDir string
+	if p.ctx != nil {
+		workDir = p.ctx.WorkDir
+	}
+	p.resumeWorktreePaths, p.resumeWorktreeLabels = sortedHandoffWorktrees(workDir, p.cachedWorktreePaths, p.cachedWorktreeNames)
+	p.resumeWorktreeIdx = 0
+
 	// Clear cached modal to rebuild with new session
 	p.resumeModal = nil
 	p.resumeModalWidth = 0
@@ -296,6 +323,9 @@ func (p *Plugin) resetResumeModal() {
 	p.resumeFocus = 0
 	p.resumeAgentIdx = 0
 	p.resumeSkipPermissions = false
+	p.resumeWorktreeIdx = 0
+	p.resumeWorktreePaths = nil
+	p.resumeWorktreeLabels = nil
 }
 
 // executeResume sends the resume message to workspace plugin.
@@ -305,11 +335,20 @@ func (p *Plugin) executeResume() tea.Cmd {
 		return nil
 	}
 
+	if p.isResumeHandoffMode() {
+		return p.executeHandoff()
+	}
+
 	// Generate resume command
 	resumeCmd := resumeCommand(session)
+	if session.Imported {
+		return func() tea.Msg {
+			return app.ToastMsg{Message: "Imported sessions are read-only; use a handoff", IsError: true}
+		}
+	}
 	if resumeCmd == "" {
 		return func() tea.Msg {
-			return app.ToastMsg{Message: "Resume not supported for " + session.AdapterName, IsError: true}
+			return app.ToastMsg{Message: "Resume not supported for " + session.AdapterName + "; use a handoff", IsError: true}
 		}
 	}
 
@@ -343,6 +382,53 @@ func (p *Plugin) executeResume() tea.Cmd {
 	)
 }
 
+// executeHandoff closes the modal and builds the handoff context in the
+// background; the workspace message is sent once it's ready.
+func (p *Plugin) executeHandoff() tea.Cmd {
+	session := *p.resumeSession
+
+	var agentType workspace.AgentType
+	if p.resumeAgentIdx >= 0 && p.resumeAgentIdx < len(workspace.AgentTypeOrder) {
+		agentType = workspace.AgentTypeOrder[p.resumeAgentIdx]
+	}
+	if agentType == "" || agentType == workspace.AgentNone {
+		return func() tea.Msg {
+			return app.ToastMsg{Message: "Choose an agent to hand off to", IsError: true}
+		}
+	}
+
+	msg := workspace.ResumeConversationMsg{
+		SessionID: session.ID,
+		AdapterID: session.AdapterID,
+		AgentType: agentType,
+		SkipPerms: p.resumeSkipPermissions,
+	}
+	if p.resumeType == resumeTypeHandoffExisting {
+		if p.resumeWorktreeIdx < 0 || p.resumeWorktreeIdx >= len(p.resumeWorktreePaths) {
+			return func() tea.Msg {
+				return app.ToastMsg{Message: "No worktree selected", IsError: true}
+			}
+		}
+		msg.Type = "existing"
+		msg.WorktreePath = p.resumeWorktreePaths[p.resumeWorktreeIdx]
+	} else {
+		msg.Type = "worktree"
+		msg.WorktreeName = p.resumeNameInput.Value()
+		msg.BaseBranch = p.resumeBaseBranchInput.Value()
+		if msg.BaseBranch == "" {
+			msg.BaseBranch = "HEAD"
+		}
+	}
+
+	p.resetResumeModal()
+	return tea.Batch(
+		func() tea.Msg {
+			return app.ToastMsg{Message: "Preparing handoff to " + workspace.AgentDisplayNames[agentType] + "...", Duration: 2 * time.Second}
+		},
+		p.prepareHandoff(session, msg),
+	)
+}
+
 // getSessionForResume returns the session to resume, checking both selectedSession ID and cursor.
 func (p *Plugin) getSessionForResume() *adapter.Session {
 	// If in message view, find session by selectedSession ID
@@ -2,6 +2,7 @@ package conversations
 
 import (
 	"fmt"
+	"path"
 	"slices"
 	"strings"
 	"time"
@@ -20,6 +21,15 @@ type SearchFilters struct {
 	MaxTokens  int       // Sessions with < N tokens
 	ActiveOnly bool      // Only currently active
 	HasFiles   []string  // Sessions that touched these files
+	Tags       []string  // Sessions tagged with all of these
+	Starred    bool      // Only starred sessions
+
+	MinCost     float64  // Sessions costing at least $N
+	MaxCost     float64  // Sessions costing at most $N
+	MinMessages int      // Sessions with at least N messages
+	MaxMessages int      // Sessions with at most N messages
+	Worktrees   []string // Worktree name globs ["feat/*"]
+	Tools       []string // Sessions that called all of these tools
 }
 
 // DateRange represents a date range filter.
@@ -39,7 +49,15 @@ func (f *SearchFilters) IsActive() bool {
 		f.MinTokens > 0 ||
 		f.MaxTokens > 0 ||
 		f.ActiveOnly ||
-		len(f.HasFiles) > 0
+		len(f.HasFiles) > 0 ||
+		len(f.Tags) > 0 ||
+		f.Starred ||
+		f.MinCost > 0 ||
+		f.MaxCost > 0 ||
+		f.MinMessages > 0 ||
+		f.MaxMessages > 0 ||
+		len(f.Worktrees) > 0 ||
+		len(f.Tools) > 0
 }
 
 // ToggleAdapter toggles an adapter in the filter list.
@@ -100,6 +118,20 @@ func (f *SearchFilters) HasCategory(cat string) bool {
 	return slices.Contains(f.Categories, cat)
 }
 
+// ToggleTag toggles a tag in the filter list.
+func (f *SearchFilters) ToggleTag(tag string) {
+	if i := slices.Index(f.Tags, tag); i >= 0 {
+		f.Tags = slices.Delete(f.Tags, i, i+1)
+		return
+	}
+	f.Tags = append(f.Tags, tag)
+}
+
+// HasTag returns true if the tag is in the filter list.
+func (f *SearchFilters) HasTag(tag string) bool {
+	return slices.Contains(f.Tags, tag)
+}
+
 // SetDateRange sets the date range preset.
 func (f *SearchFilters) SetDateRange(preset string) {
 	if f.DateRange.Preset == preset {
@@ -154,12 +186,22 @@ func (f *SearchFilters) Matches(session adapter.Session) bool {
 		return false
 	}
 
-	// Model filter - Would need session.PrimaryModel field to check this
-	// For now, skip model filtering at session level
+	// Worktree filter (sessions from the current worktree have no name)
+	if len(f.Worktrees) > 0 && !slices.ContainsFunc(f.Worktrees, func(pattern string) bool {
+		return globMatch(pattern, session.WorktreeName)
+	}) {
+		return false
+	}
+
+	// Models, tools and files are recorded per message, so they are
+	// checked by MatchesMessages.
 
-	// Date range filter
-	if f.DateRange.Preset != "" {
-		if session.UpdatedAt.Before(f.DateRange.Start) || session.UpdatedAt.After(f.DateRange.End) {
+	// Date range filter (queries may set only one end)
+	if f.DateRange.Preset != "" || !f.DateRange.Start.IsZero() || !f.DateRange.End.IsZero() {
+		if !f.DateRange.Start.IsZero() && session.UpdatedAt.Before(f.DateRange.Start) {
+			return false
+		}
+		if !f.DateRange.End.IsZero() && session.UpdatedAt.After(f.DateRange.End) {
 			return false
 		}
 	}
@@ -172,6 +214,22 @@ func (f *SearchFilters) Matches(session adapter.Session) bool {
 		return false
 	}
 
+	// Cost filters
+	if f.MinCost > 0 && session.EstCost < f.MinCost {
+		return false
+	}
+	if f.MaxCost > 0 && session.EstCost > f.MaxCost {
+		return false
+	}
+
+	// Message count filters
+	if f.MinMessages > 0 && session.MessageCount < f.MinMessages {
+		return false
+	}
+	if f.MaxMessages > 0 && session.MessageCount > f.MaxMessages {
+		return false
+	}
+
 	// Active only filter
 	if f.ActiveOnly && !session.IsActive {
 		return false
@@ -180,6 +238,78 @@ func (f *SearchFilters) Matches(session adapter.Session) bool {
 	return true
 }
 
+// MatchesAnnotations checks a session's annotations against the tag and
+// starred filters. Annotations live outside the session, so they are
+// checked separately from Matches.
+func (f *SearchFilters) MatchesAnnotations(a *SessionAnnotations) bool {
+	if f.Starred && !a.starred() {
+		return false
+	}
+	for _, tag := range f.Tags {
+		if !slices.ContainsFunc(a.tags(), func(t string) bool { return globMatch(tag, t) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// NeedsMessages returns true if the filters check message contents, which
+// MatchesMessages needs an index of.
+func (f *SearchFilters) NeedsMessages() bool {
+	return len(f.Models) > 0 || len(f.Tools) > 0 || len(f.HasFiles) > 0
+}
+
+// MatchesMessages checks the model, tool and file filters against what a
+// session's messages used. A model matches by substring ("opus" matches
+// "claude-opus-4"), tools by name and files by path suffix or glob.
+func (f *SearchFilters) MatchesMessages(info *sessionMessageInfo) bool {
+	if !f.NeedsMessages() {
+		return true
+	}
+	if info == nil {
+		return false
+	}
+	if len(f.Models) > 0 && !slices.ContainsFunc(f.Models, func(m string) bool {
+		m = strings.ToLower(m)
+		return slices.ContainsFunc(info.Models, func(model string) bool {
+			return strings.Contains(strings.ToLower(model), m)
+		})
+	}) {
+		return false
+	}
+	for _, tool := range f.Tools {
+		if !slices.ContainsFunc(info.Tools, func(t string) bool { return strings.EqualFold(t, tool) }) {
+			return false
+		}
+	}
+	for _, file := range f.HasFiles {
+		if !slices.ContainsFunc(info.Files, func(path string) bool { return fileMatches(file, path) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// globMatch matches s against a shell glob, falling back to equality for
+// malformed patterns.
+func globMatch(pattern, s string) bool {
+	ok, err := path.Match(pattern, s)
+	if err != nil {
+		return pattern == s
+	}
+	return ok
+}
+
+// fileMatches matches a file path against a has:file pattern: a glob on the
+// path or its base name, or otherwise a path suffix ("auth.go" matches
+// "/src/auth.go").
+func fileMatches(pattern, file string) bool {
+	if strings.ContainsAny(pattern, "*?[") {
+		return globMatch(pattern, file) || globMatch(pattern, path.Base(file))
+	}
+	return file == pattern || strings.HasSuffix(file, "/"+strings.TrimPrefix(pattern, "/"))
+}
+
 // String formats active filters for display.
 func (f *SearchFilters) String() string {
 	var parts []string
@@ -193,8 +323,18 @@ func (f *SearchFilters) String() string {
 	if len(f.Categories) > 0 {
 		parts = append(parts, "[category:"+strings.Join(f.Categories, ",")+"]")
 	}
+	if len(f.Worktrees) > 0 {
+		parts = append(parts, "[worktree:"+strings.Join(f.Worktrees, ",")+"]")
+	}
 	if f.DateRange.Preset != "" {
 		parts = append(parts, "["+f.DateRange.Preset+"]")
+	} else {
+		if !f.DateRange.Start.IsZero() {
+			parts = append(parts, "[after:"+f.DateRange.Start.Format(queryDateLayout)+"]")
+		}
+		if !f.DateRange.End.IsZero() {
+			parts = append(parts, "[before:"+f.DateRange.End.Format(queryDateLayout)+"]")
+		}
 	}
 	if f.MinTokens > 0 {
 		parts = append(parts, "[tokens:>"+formatTokenCount(f.MinTokens)+"]")
@@ -202,9 +342,33 @@ func (f *SearchFilters) String() string {
 	if f.MaxTokens > 0 {
 		parts = append(parts, "[tokens:<"+formatTokenCount(f.MaxTokens)+"]")
 	}
+	if f.MinCost > 0 {
+		parts = append(parts, fmt.Sprintf("[cost:>$%.2f]", f.MinCost))
+	}
+	if f.MaxCost > 0 {
+		parts = append(parts, fmt.Sprintf("[cost:<$%.2f]", f.MaxCost))
+	}
+	if f.MinMessages > 0 {
+		parts = append(parts, fmt.Sprintf("[msgs:>%d]", f.MinMessages))
+	}
+	if f.MaxMessages > 0 {
+		parts = append(parts, fmt.Sprintf("[msgs:<%d]", f.MaxMessages))
+	}
 	if f.ActiveOnly {
 		parts = append(parts, "[active]")
 	}
+	if f.Starred {
+		parts = append(parts, "[starred]")
+	}
+	if len(f.Tags) > 0 {
+		parts = append(parts, "[tag:"+strings.Join(f.Tags, ",")+"]")
+	}
+	if len(f.Tools) > 0 {
+		parts = append(parts, "[tool:"+strings.Join(f.Tools, ",")+"]")
+	}
+	if len(f.HasFiles) > 0 {
+		parts = append(parts, "[file:"+strings.Join(f.HasFiles, ",")+"]")
+	}
 
 	return strings.Join(parts, " ")
 }
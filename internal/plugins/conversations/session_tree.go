@@ -0,0 +1,266 @@
+package conversations
+
+import (
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/adapter"
+)
+
+// subAgentTotals aggregates the sub-agents nested under a session.
+type subAgentTotals struct {
+	Count       int
+	TotalTokens int
+	EstCost     float64
+}
+
+// sortSessions sorts sessions newest first and moves each sub-agent directly
+// after its parent, so the list reads as a tree. Sub-agents whose parent
+// isn't in the list stay where their update time puts them.
+func sortSessions(sessions []adapter.Session) {
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].UpdatedAt.After(sessions[j].UpdatedAt)
+	})
+
+	present := make(map[string]bool, len(sessions))
+	for _, s := range sessions {
+		present[s.ID] = true
+	}
+	children := make(map[string][]adapter.Session)
+	var roots []adapter.Session
+	for _, s := range sessions {
+		if s.ParentSessionID != "" && s.ParentSessionID != s.ID && present[s.ParentSessionID] {
+			children[s.ParentSessionID] = append(children[s.ParentSessionID], s)
+		} else {
+			roots = append(roots, s)
+		}
+	}
+	if len(children) == 0 {
+		return
+	}
+
+	ordered := make([]adapter.Session, 0, len(sessions))
+	placed := make(map[string]bool, len(sessions))
+	var add func(s adapter.Session)
+	add = func(s adapter.Session) {
+		if placed[s.ID] {
+			return
+		}
+		placed[s.ID] = true
+		ordered = append(ordered, s)
+		for _, c := range children[s.ID] {
+			add(c)
+		}
+	}
+	for _, s := range roots {
+		add(s)
+	}
+	// Sessions in a parent cycle have no root; keep them rather than drop them
+	for _, s := range sessions {
+		add(s)
+	}
+	copy(sessions, ordered)
+}
+
+// buildSessionTree returns the sub-agent totals of every session with
+// sub-agents, including nested ones, and maps spawning tool call IDs to the
+// sub-agent sessions they started.
+func buildSessionTree(sessions []adapter.Session) (map[string]subAgentTotals, map[string]string) {
+	byID := make(map[string]*adapter.Session, len(sessions))
+	for i := range sessions {
+		byID[sessions[i].ID] = &sessions[i]
+	}
+
+	totals := make(map[string]subAgentTotals)
+	calls := make(map[string]string)
+	for _, s := range sessions {
+		if s.ParentSessionID == "" {
+			continue
+		}
+		if s.ParentToolCallID != "" {
+			calls[s.ParentToolCallID] = s.ID
+		}
+		// Credit every ancestor; seen guards against parent cycles
+		seen := map[string]bool{s.ID: true}
+		for parent := byID[s.ParentSessionID]; parent != nil && !seen[parent.ID]; parent = byID[parent.ParentSessionID] {
+			seen[parent.ID] = true
+			t := totals[parent.ID]
+			t.Count++
+			t.TotalTokens += s.TotalTokens
+			t.EstCost += s.EstCost
+			totals[parent.ID] = t
+		}
+	}
+	return totals, calls
+}
+
+// sortAndIndexSessions orders p.sessions as a tree and rebuilds the
+// sub-agent indexes. Call it whenever p.sessions changes.
+func (p *Plugin) sortAndIndexSessions() {
+	sortSessions(p.sessions)
+	p.subAgentTotals, p.subAgentCalls = buildSessionTree(p.sessions)
+}
+
+// hasVisibleParent reports whether s is a sub-agent whose parent is in the list.
+func (p *Plugin) hasVisibleParent(s adapter.Session) bool {
+	if s.ParentSessionID == "" {
+		return false
+	}
+	_, ok := p.subAgentTotals[s.ParentSessionID]
+	return ok
+}
+
+// hideCollapsedSubAgents drops sub-agents whose parent is in the list but
+// collapsed, or itself hidden.
+func (p *Plugin) hideCollapsedSubAgents(sessions []adapter.Session) []adapter.Session {
+	if len(p.subAgentTotals) == 0 {
+		return sessions
+	}
+	listed := make(map[string]bool, len(sessions))
+	for _, s := range sessions {
+		listed[s.ID] = true
+	}
+	hidden := make(map[string]bool)
+	var out []adapter.Session
+	for _, s := range sessions {
+		// Parents precede their sub-agents, so hidden ancestors are known
+		if listed[s.ParentSessionID] && (hidden[s.ParentSessionID] || !p.expandedSubAgents[s.ParentSessionID]) {
+			hidden[s.ID] = true
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// sessionListGroup returns the time group a list row is shown under.
+// Nested sub-agents stay in their parent's group.
+func (p *Plugin) sessionListGroup(sessions []adapter.Session, i int) string {
+	for i > 0 && p.hasVisibleParent(sessions[i]) {
+		i--
+	}
+	return getSessionGroup(sessions[i].UpdatedAt)
+}
+
+// sessionDisplayName returns the name of a listed session, truncated to
+// maxLen runes.
+func (p *Plugin) sessionDisplayName(id string, maxLen int) string {
+	name := shortID(id)
+	for i := range p.sessions {
+		if p.sessions[i].ID == id && p.sessions[i].Name != "" {
+			name = p.sessions[i].Name
+			break
+		}
+	}
+	if runes := []rune(name); len(runes) > maxLen {
+		name = string(runes[:maxLen-3]) + "..."
+	}
+	return name
+}
+
+// toggleSubAgents expands or collapses the sub-agents of the session under
+// the cursor, or of its parent when the cursor is on a sub-agent.
+func (p *Plugin) toggleSubAgents() {
+	sessions := p.visibleSessions()
+	if p.cursor < 0 || p.cursor >= len(sessions) {
+		return
+	}
+	s := sessions[p.cursor]
+	id := s.ID
+	if _, ok := p.subAgentTotals[id]; !ok {
+		if !p.hasVisibleParent(s) {
+			return
+		}
+		id = s.ParentSessionID
+	}
+
+	p.expandedSubAgents[id] = !p.expandedSubAgents[id]
+	p.hitRegionsDirty = true
+
+	// Keep the cursor on the toggled session when its sub-agents fold away
+	for i, v := range p.visibleSessions() {
+		if v.ID == id {
+			if !p.expandedSubAgents[id] {
+				p.cursor = i
+			}
+			break
+		}
+	}
+	p.ensureCursorVisible()
+	if sessions = p.visibleSessions(); p.cursor < len(sessions) {
+		p.setSelectedSession(sessions[p.cursor].ID)
+	}
+}
+
+// linkedSessionForMessage returns the sub-agent spawned by a tool call in
+// msg, or "" if none is known.
+func (p *Plugin) linkedSessionForMessage(msg *adapter.Message) string {
+	if msg == nil || len(p.subAgentCalls) == 0 {
+		return ""
+	}
+	for _, b := range msg.ContentBlocks {
+		if b.Type == "tool_use" {
+			if id := p.subAgentCalls[b.ToolUseID]; id != "" {
+				return id
+			}
+		}
+	}
+	for _, tu := range msg.ToolUses {
+		if id := p.subAgentCalls[tu.ID]; id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// openLinkedSession opens the sub-agent spawned by the selected message, or
+// the parent session when viewing a sub-agent.
+func (p *Plugin) openLinkedSession() tea.Cmd {
+	target := p.linkedSessionForMessage(p.getSelectedMessage())
+	if target == "" {
+		if s := p.findSelectedSession(); s != nil && p.hasVisibleParent(*s) {
+			target = s.ParentSessionID
+		}
+	}
+	if target == "" {
+		return nil
+	}
+	return p.openSession(target)
+}
+
+// openSession selects a session in the list, expanding its ancestors so it
+// is visible, and loads its messages.
+func (p *Plugin) openSession(id string) tea.Cmd {
+	byID := make(map[string]adapter.Session, len(p.sessions))
+	for _, s := range p.sessions {
+		byID[s.ID] = s
+	}
+	s, ok := byID[id]
+	if !ok {
+		return nil
+	}
+	seen := map[string]bool{id: true}
+	for parent := s.ParentSessionID; parent != "" && !seen[parent]; parent = byID[parent].ParentSessionID {
+		seen[parent] = true
+		if _, ok := byID[parent]; !ok {
+			break
+		}
+		p.expandedSubAgents[parent] = true
+	}
+	p.hitRegionsDirty = true
+
+	sessions := p.visibleSessions()
+	for i := range sessions {
+		if sessions[i].ID == id {
+			p.cursor = i
+			p.ensureCursorVisible()
+			break
+		}
+	}
+	p.setSelectedSession(id)
+	p.activePane = PaneMessages
+	return tea.Batch(
+		p.loadMessages(id),
+		p.loadUsage(id),
+	)
+}
@@ -0,0 +1,139 @@
+package conversations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+func treeSessions() []adapter.Session {
+	now := time.Now()
+	return []adapter.Session{
+		{ID: "child", ParentSessionID: "root", ParentToolCallID: "task-1", UpdatedAt: now, TotalTokens: 100, EstCost: 0.5},
+		{ID: "other", UpdatedAt: now.Add(-time.Minute)},
+		{ID: "root", UpdatedAt: now.Add(-2 * time.Minute), TotalTokens: 1000},
+		{ID: "grandchild", ParentSessionID: "child", UpdatedAt: now.Add(-3 * time.Minute), TotalTokens: 10, EstCost: 0.25},
+		{ID: "orphan", ParentSessionID: "missing", UpdatedAt: now.Add(-4 * time.Minute)},
+	}
+}
+
+func sessionIDs(sessions []adapter.Session) []string {
+	ids := make([]string, len(sessions))
+	for i, s := range sessions {
+		ids[i] = s.ID
+	}
+	return ids
+}
+
+func TestSortSessionsNestsSubAgents(t *testing.T) {
+	sessions := treeSessions()
+	sortSessions(sessions)
+
+	want := []string{"other", "root", "child", "grandchild", "orphan"}
+	got := sessionIDs(sessions)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("order = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestSortSessionsParentCycle(t *testing.T) {
+	sessions := []adapter.Session{
+		{ID: "a", ParentSessionID: "b"},
+		{ID: "b", ParentSessionID: "a"},
+	}
+	sortSessions(sessions)
+	if len(sessions) != 2 || sessions[0].ID == sessions[1].ID {
+		t.Errorf("cycle lost sessions: %v", sessionIDs(sessions))
+	}
+}
+
+func TestBuildSessionTree(t *testing.T) {
+	totals, calls := buildSessionTree(treeSessions())
+
+	root := totals["root"]
+	if root.Count != 2 || root.TotalTokens != 110 || root.EstCost != 0.75 {
+		t.Errorf("root totals = %+v", root)
+	}
+	if child := totals["child"]; child.Count != 1 || child.TotalTokens != 10 {
+		t.Errorf("child totals = %+v", child)
+	}
+	if _, ok := totals["missing"]; ok {
+		t.Error("sessions outside the list should not get totals")
+	}
+	if calls["task-1"] != "child" {
+		t.Errorf("calls = %v", calls)
+	}
+}
+
+func TestToggleSubAgents(t *testing.T) {
+	p := New()
+	p.ctx = &plugin.Context{WorkDir: "/proj"}
+	p.sessions = treeSessions()
+	p.sortAndIndexSessions()
+
+	// Collapsed by default; the orphan stays visible
+	if got := sessionIDs(p.visibleSessions()); len(got) != 3 || got[2] != "orphan" {
+		t.Fatalf("collapsed = %v", got)
+	}
+
+	p.cursor = 1 // root
+	p.toggleSubAgents()
+	if got := sessionIDs(p.visibleSessions()); len(got) != 4 || got[2] != "child" {
+		t.Fatalf("expanded = %v", got)
+	}
+
+	// A sub-agent with its own sub-agents expands them
+	p.cursor = 2 // child
+	p.toggleSubAgents()
+	if got := sessionIDs(p.visibleSessions()); len(got) != 5 || got[3] != "grandchild" {
+		t.Fatalf("nested expanded = %v", got)
+	}
+
+	// Toggling from a leaf collapses its parent and keeps the cursor there
+	p.cursor = 3 // grandchild
+	p.toggleSubAgents()
+	if got := p.visibleSessions(); len(got) != 4 || got[p.cursor].ID != "child" {
+		t.Errorf("after collapse = %v, cursor %d", sessionIDs(got), p.cursor)
+	}
+}
+
+func TestOpenLinkedSession(t *testing.T) {
+	p := New()
+	p.ctx = &plugin.Context{WorkDir: "/proj"}
+	p.adapters = map[string]adapter.Adapter{"mock": &messageAdapter{}}
+	p.sessions = treeSessions()
+	for i := range p.sessions {
+		p.sessions[i].AdapterID = "mock"
+	}
+	p.sortAndIndexSessions()
+	p.setSelectedSession("root")
+	p.messages = []adapter.Message{{ID: "m1", Role: "assistant", ContentBlocks: []adapter.ContentBlock{
+		{Type: "tool_use", ToolUseID: "task-1", ToolName: "Task"},
+	}}}
+	p.messageCursor = 0
+
+	if p.linkedSessionForMessage(&p.messages[0]) != "child" {
+		t.Fatal("tool call not linked to its sub-agent")
+	}
+	if cmd := p.openSession("grandchild"); cmd == nil {
+		t.Fatal("expected messages to load")
+	}
+	if p.selectedSession != "grandchild" || p.activePane != PaneMessages {
+		t.Errorf("selected = %q, pane = %v", p.selectedSession, p.activePane)
+	}
+	// Ancestors expand so the opened session is visible in the list
+	if got := p.visibleSessions(); p.cursor >= len(got) || got[p.cursor].ID != "grandchild" {
+		t.Errorf("cursor %d not on grandchild in %v", p.cursor, sessionIDs(got))
+	}
+
+	// With no linked tool call, a sub-agent opens its parent
+	p.messages = nil
+	p.openLinkedSession()
+	if p.selectedSession != "child" {
+		t.Errorf("selected = %q, want parent", p.selectedSession)
+	}
+}
@@ -0,0 +1,751 @@
+package conversations
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/mouse"
+	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/state"
+	"github.com/marcus/sidecar/internal/styles"
+)
+
+// maxFailingCommands is the number of failing shell commands listed.
+const maxFailingCommands = 10
+
+// Focus targets in the tool analytics view.
+const (
+	toolFocusTools = iota
+	toolFocusCalls
+)
+
+// toolCall is one tool invocation and its outcome.
+type toolCall struct {
+	SessionID  string
+	MessageID  string
+	Tool       string
+	Model      string
+	Timestamp  time.Time
+	Command    string // first line of a shell tool's command
+	HasResult  bool
+	Failed     bool
+	Error      string        // first line of the error output
+	OutputSize int           // bytes of output
+	Duration   time.Duration // until the result arrived, 0 if unknown
+}
+
+// extractToolCalls returns the tool calls in the messages, matched to
+// their results by tool use ID.
+func extractToolCalls(messages []adapter.Message) []toolCall {
+	var calls []toolCall
+	byID := make(map[string]int)
+	for _, msg := range messages {
+		add := func(id, name, input string) {
+			if id != "" {
+				byID[id] = len(calls)
+			}
+			calls = append(calls, toolCall{
+				MessageID: msg.ID,
+				Tool:      name,
+				Model:     msg.Model,
+				Timestamp: msg.Timestamp,
+				Command:   toolCommand(name, input),
+			})
+		}
+
+		// Prefer content blocks; ToolUses may repeat the same calls
+		hasBlocks := slices.ContainsFunc(msg.ContentBlocks, func(b adapter.ContentBlock) bool {
+			return b.Type == "tool_use"
+		})
+		for _, b := range msg.ContentBlocks {
+			if b.Type == "tool_use" {
+				add(b.ToolUseID, b.ToolName, b.ToolInput)
+			}
+		}
+		for _, tu := range msg.ToolUses {
+			if !hasBlocks {
+				add(tu.ID, tu.Name, tu.Input)
+			}
+			if i, ok := byID[tu.ID]; ok && tu.Output != "" && !calls[i].HasResult {
+				calls[i].HasResult = true
+				calls[i].OutputSize = len(tu.Output)
+			}
+		}
+
+		for _, b := range msg.ContentBlocks {
+			i, ok := byID[b.ToolUseID]
+			if b.Type != "tool_result" || !ok {
+				continue
+			}
+			c := &calls[i]
+			c.HasResult = true
+			c.OutputSize = len(b.ToolOutput)
+			c.Failed = b.IsError
+			if b.IsError {
+				c.Error = firstLine(b.ToolOutput)
+			}
+			if !c.Timestamp.IsZero() && msg.Timestamp.After(c.Timestamp) {
+				c.Duration = msg.Timestamp.Sub(c.Timestamp)
+			}
+		}
+	}
+	return calls
+}
+
+// toolCommand returns the first line of a shell tool call's command, or
+// "" for other tools.
+func toolCommand(name, input string) string {
+	var data map[string]any
+	if err := json.Unmarshal([]byte(input), &data); err != nil {
+		return ""
+	}
+	lower := strings.ToLower(name)
+	for _, key := range []string{"command", "cmd"} {
+		if cmd := commandString(data[key]); cmd != "" && isShellTool(lower, data[key]) {
+			return firstLine(cmd)
+		}
+	}
+	return ""
+}
+
+// toolStats aggregates the calls of one tool.
+type toolStats struct {
+	Tool        string
+	Calls       int
+	Errors      int
+	Results     int // calls with a result
+	OutputBytes int
+	Timed       int // calls with a known duration
+	Duration    time.Duration
+}
+
+// ErrorRate returns the fraction of calls that failed.
+func (s *toolStats) ErrorRate() float64 {
+	if s.Calls == 0 {
+		return 0
+	}
+	return float64(s.Errors) / float64(s.Calls)
+}
+
+// AvgOutput returns the average output size in bytes.
+func (s *toolStats) AvgOutput() int {
+	if s.Results == 0 {
+		return 0
+	}
+	return s.OutputBytes / s.Results
+}
+
+// AvgDuration returns the average time until a result arrived.
+func (s *toolStats) AvgDuration() time.Duration {
+	if s.Timed == 0 {
+		return 0
+	}
+	return s.Duration / time.Duration(s.Timed)
+}
+
+// failingCommand counts failures of one shell command.
+type failingCommand struct {
+	Command string
+	Count   int
+}
+
+// toolReport is the aggregated tool analytics for a set of sessions.
+type toolReport struct {
+	Sessions        int // sessions with calls
+	Pending         int // sessions not yet indexed
+	Calls           int
+	Errors          int
+	Tools           []toolStats // most used first
+	FailingCommands []failingCommand
+	CallsByTool     map[string][]toolCall // newest first
+}
+
+// buildToolReport aggregates tool calls.
+func buildToolReport(calls []toolCall) *toolReport {
+	r := &toolReport{CallsByTool: make(map[string][]toolCall)}
+	byTool := make(map[string]*toolStats)
+	sessions := make(map[string]bool)
+	commands := make(map[string]int)
+	for _, c := range calls {
+		s := byTool[c.Tool]
+		if s == nil {
+			s = &toolStats{Tool: c.Tool}
+			byTool[c.Tool] = s
+		}
+		s.Calls++
+		r.Calls++
+		if c.Failed {
+			s.Errors++
+			r.Errors++
+			if c.Command != "" {
+				commands[c.Command]++
+			}
+		}
+		if c.HasResult {
+			s.Results++
+			s.OutputBytes += c.OutputSize
+		}
+		if c.Duration > 0 {
+			s.Timed++
+			s.Duration += c.Duration
+		}
+		sessions[c.SessionID] = true
+		r.CallsByTool[c.Tool] = append(r.CallsByTool[c.Tool], c)
+	}
+	r.Sessions = len(sessions)
+
+	for _, s := range byTool {
+		r.Tools = append(r.Tools, *s)
+	}
+	sort.Slice(r.Tools, func(i, j int) bool {
+		if r.Tools[i].Calls != r.Tools[j].Calls {
+			return r.Tools[i].Calls > r.Tools[j].Calls
+		}
+		return r.Tools[i].Tool < r.Tools[j].Tool
+	})
+
+	for cmd, n := range commands {
+		r.FailingCommands = append(r.FailingCommands, failingCommand{Command: cmd, Count: n})
+	}
+	sort.Slice(r.FailingCommands, func(i, j int) bool {
+		if r.FailingCommands[i].Count != r.FailingCommands[j].Count {
+			return r.FailingCommands[i].Count > r.FailingCommands[j].Count
+		}
+		return r.FailingCommands[i].Command < r.FailingCommands[j].Command
+	})
+	if len(r.FailingCommands) > maxFailingCommands {
+		r.FailingCommands = r.FailingCommands[:maxFailingCommands]
+	}
+
+	for tool, list := range r.CallsByTool {
+		sort.SliceStable(list, func(i, j int) bool { return list[i].Timestamp.After(list[j].Timestamp) })
+		r.CallsByTool[tool] = list
+	}
+	return r
+}
+
+// callMatches checks a call against the per-call parts of the filters:
+// models, tools and dates.
+func callMatches(f *SearchFilters, c *toolCall) bool {
+	if len(f.Models) > 0 && !slices.ContainsFunc(f.Models, func(m string) bool {
+		return strings.Contains(strings.ToLower(c.Model), m)
+	}) {
+		return false
+	}
+	if len(f.Tools) > 0 && !slices.ContainsFunc(f.Tools, func(t string) bool { return strings.EqualFold(t, c.Tool) }) {
+		return false
+	}
+	if !c.Timestamp.IsZero() {
+		if !f.DateRange.Start.IsZero() && c.Timestamp.Before(f.DateRange.Start) {
+			return false
+		}
+		if !f.DateRange.End.IsZero() && c.Timestamp.After(f.DateRange.End) {
+			return false
+		}
+	}
+	return true
+}
+
+// toolSessionFilters returns the session-level part of the filters. A
+// session updated after the end date can still have calls inside it, so
+// only the start date applies to sessions.
+func toolSessionFilters(f SearchFilters) SearchFilters {
+	f.Models = nil
+	f.Tools = nil
+	f.DateRange.End = time.Time{}
+	return f
+}
+
+// toolAnalyticsSessions returns the sessions in the view's scope that pass
+// the session-level filters.
+func (p *Plugin) toolAnalyticsSessions() []adapter.Session {
+	sf := toolSessionFilters(p.toolFilters)
+	var sessions []adapter.Session
+	for _, s := range p.sessions {
+		if p.toolScope != "" && s.ID != p.toolScope {
+			continue
+		}
+		if sf.Matches(s) && sf.MatchesAnnotations(p.annotationsFor(s)) {
+			sessions = append(sessions, s)
+		}
+	}
+	return sessions
+}
+
+// refreshToolReport rebuilds the report from indexed sessions, keeping
+// the selected tool.
+func (p *Plugin) refreshToolReport() {
+	selected := p.selectedToolStats()
+	sf := toolSessionFilters(p.toolFilters)
+
+	var calls []toolCall
+	pending := 0
+	for _, s := range p.toolAnalyticsSessions() {
+		info := p.messageInfoFor(s)
+		if info == nil {
+			if s.MessageCount > 0 && s.SizeLevel() < 2 && p.adapters[s.AdapterID] != nil {
+				pending++
+			}
+			continue
+		}
+		if !sf.MatchesMessages(info) {
+			continue
+		}
+		for i := range info.Calls {
+			if c := &info.Calls[i]; callMatches(&p.toolFilters, c) {
+				calls = append(calls, *c)
+			}
+		}
+	}
+	p.toolReport = buildToolReport(calls)
+	p.toolReport.Pending = pending
+
+	p.toolCursor = 0
+	if selected != nil {
+		for i := range p.toolReport.Tools {
+			if p.toolReport.Tools[i].Tool == selected.Tool {
+				p.toolCursor = i
+			}
+		}
+	}
+	p.toolCallCursor = min(p.toolCallCursor, max(len(p.selectedToolCalls())-1, 0))
+	if len(p.selectedToolCalls()) == 0 {
+		p.toolFocus = toolFocusTools
+	}
+}
+
+// openToolAnalytics opens the tool analytics view for one session, or all
+// sessions when sessionID is empty, and starts indexing their messages.
+func (p *Plugin) openToolAnalytics(sessionID string) tea.Cmd {
+	p.view = ViewToolAnalytics
+	p.toolScope = sessionID
+	p.toolFocus = toolFocusTools
+	p.toolCursor = 0
+	p.toolCallCursor = 0
+	p.toolScroll = 0
+	p.toolErrorsOnly = true
+	p.refreshToolReport()
+	return p.indexSessions(p.toolAnalyticsSessions())
+}
+
+// closeToolAnalytics returns to the session list.
+func (p *Plugin) closeToolAnalytics() {
+	p.view = ViewSessions
+	p.toolReport = nil
+	p.toolQueryMode = false
+	p.hitRegionsDirty = true
+}
+
+// selectedToolStats returns the selected tool's stats, or nil.
+func (p *Plugin) selectedToolStats() *toolStats {
+	if p.toolReport == nil || p.toolCursor < 0 || p.toolCursor >= len(p.toolReport.Tools) {
+		return nil
+	}
+	return &p.toolReport.Tools[p.toolCursor]
+}
+
+// selectedToolCalls returns the calls listed for the selected tool:
+// failures only, unless all calls are shown.
+func (p *Plugin) selectedToolCalls() []toolCall {
+	s := p.selectedToolStats()
+	if s == nil {
+		return nil
+	}
+	calls := p.toolReport.CallsByTool[s.Tool]
+	if !p.toolErrorsOnly {
+		return calls
+	}
+	var failed []toolCall
+	for _, c := range calls {
+		if c.Failed {
+			failed = append(failed, c)
+		}
+	}
+	return failed
+}
+
+// openToolCall opens the session of the selected call at its message.
+func (p *Plugin) openToolCall() tea.Cmd {
+	calls := p.selectedToolCalls()
+	if p.toolCallCursor >= len(calls) {
+		return nil
+	}
+	c := calls[p.toolCallCursor]
+	p.closeToolAnalytics()
+	p.pendingScrollMsgID = c.MessageID
+	p.pendingScrollActive = c.MessageID != ""
+	return p.openSession(c.SessionID)
+}
+
+// updateToolAnalytics handles key events in the tool analytics view.
+func (p *Plugin) updateToolAnalytics(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
+	if p.toolQueryMode {
+		return p, p.updateToolQuery(msg)
+	}
+
+	calls := p.selectedToolCalls()
+	switch msg.String() {
+	case "esc", "q", "O":
+		p.closeToolAnalytics()
+
+	case "j", "down":
+		if p.toolFocus == toolFocusCalls {
+			p.toolCallCursor = min(p.toolCallCursor+1, max(len(calls)-1, 0))
+		} else if p.toolReport != nil && p.toolCursor < len(p.toolReport.Tools)-1 {
+			p.toolCursor++
+			p.toolCallCursor = 0
+		}
+
+	case "k", "up":
+		if p.toolFocus == toolFocusCalls {
+			p.toolCallCursor = max(p.toolCallCursor-1, 0)
+		} else if p.toolCursor > 0 {
+			p.toolCursor--
+			p.toolCallCursor = 0
+		}
+
+	case "g":
+		if p.toolFocus == toolFocusCalls {
+			p.toolCallCursor = 0
+		} else {
+			p.toolCursor = 0
+			p.toolCallCursor = 0
+		}
+
+	case "G":
+		if p.toolFocus == toolFocusCalls {
+			p.toolCallCursor = max(len(calls)-1, 0)
+		} else if p.toolReport != nil {
+			p.toolCursor = max(len(p.toolReport.Tools)-1, 0)
+			p.toolCallCursor = 0
+		}
+
+	case "tab", "shift+tab":
+		if p.toolFocus == toolFocusCalls || len(calls) == 0 {
+			p.toolFocus = toolFocusTools
+		} else {
+			p.toolFocus = toolFocusCalls
+		}
+
+	case "enter":
+		if p.toolFocus == toolFocusCalls {
+			return p, p.openToolCall()
+		}
+		if len(calls) > 0 {
+			p.toolFocus = toolFocusCalls
+		}
+
+	case "e":
+		p.toolErrorsOnly = !p.toolErrorsOnly
+		p.toolCallCursor = 0
+		if len(p.selectedToolCalls()) == 0 {
+			p.toolFocus = toolFocusTools
+		}
+
+	case "s":
+		// Switch between the selected session and all sessions
+		if p.toolScope != "" {
+			return p, p.openToolAnalytics("")
+		}
+		if p.selectedSession != "" {
+			return p, p.openToolAnalytics(p.selectedSession)
+		}
+
+	case "/":
+		p.toolQueryMode = true
+		p.toolQueryInput = p.toolQuery
+		p.toolQueryErr = nil
+
+	case "r":
+		p.refreshToolReport()
+		return p, p.indexSessions(p.toolAnalyticsSessions())
+	}
+	return p, nil
+}
+
+// handleToolAnalyticsMouse selects tool and call rows on click and opens
+// a call when it's clicked again. The wheel moves the selection.
+func (p *Plugin) handleToolAnalyticsMouse(action mouse.MouseAction) tea.Cmd {
+	switch action.Type {
+	case mouse.ActionScrollUp, mouse.ActionScrollDown:
+		key := "j"
+		if action.Delta < 0 {
+			key = "k"
+		}
+		_, cmd := p.updateToolAnalytics(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		return cmd
+
+	case mouse.ActionClick, mouse.ActionDoubleClick:
+		if action.Region == nil {
+			return nil
+		}
+		idx, _ := action.Region.Data.(int)
+		switch action.Region.ID {
+		case regionToolRow:
+			if p.toolReport != nil && idx < len(p.toolReport.Tools) {
+				if idx != p.toolCursor {
+					p.toolCallCursor = 0
+				}
+				p.toolCursor = idx
+				p.toolFocus = toolFocusTools
+			}
+		case regionToolCall:
+			if idx >= len(p.selectedToolCalls()) {
+				return nil
+			}
+			selected := p.toolFocus == toolFocusCalls && idx == p.toolCallCursor
+			p.toolCallCursor = idx
+			p.toolFocus = toolFocusCalls
+			if selected || action.Type == mouse.ActionDoubleClick {
+				return p.openToolCall()
+			}
+		}
+	}
+	return nil
+}
+
+// updateToolQuery handles editing the tool analytics filter query.
+func (p *Plugin) updateToolQuery(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		p.toolQueryMode = false
+		p.toolQueryErr = nil
+	case "enter":
+		f, err := ParseQuery(p.toolQueryInput, state.GetSavedQueries())
+		if err != nil {
+			p.toolQueryErr = err
+			return nil
+		}
+		p.toolQueryMode = false
+		p.toolQuery = strings.TrimSpace(p.toolQueryInput)
+		p.toolFilters = f
+		p.refreshToolReport()
+		return p.indexSessions(p.toolAnalyticsSessions())
+	case "tab":
+		p.toolQueryInput, _ = completeQuery(p.toolQueryInput, p.queryValues, p.savedQueryNames())
+		p.toolQueryErr = nil
+	case "backspace":
+		if n := len(p.toolQueryInput); n > 0 {
+			p.toolQueryInput = p.toolQueryInput[:n-1]
+		}
+		p.toolQueryErr = nil
+	default:
+		if k := msg.String(); len(k) == 1 {
+			p.toolQueryInput += k
+			p.toolQueryErr = nil
+		}
+	}
+	return nil
+}
+
+// renderToolAnalytics renders the tool analytics view.
+func (p *Plugin) renderToolAnalytics() string {
+	width := max(p.width-2, 20)
+	var lines []string
+	var rows []toolRowRegion
+	rule := func(ch string) string { return styles.Muted.Render(strings.Repeat(ch, width)) }
+
+	scope := "All sessions"
+	if p.toolScope != "" {
+		scope = p.sessionDisplayName(p.toolScope, width/2)
+	}
+	lines = append(lines, styles.Title.Render(" Tool Analytics")+styles.Muted.Render("  "+scope))
+	lines = append(lines, rule("━"))
+
+	// Filter line
+	switch {
+	case p.toolQueryMode:
+		line := styles.Muted.Render(" Filter: ") + styles.StatusInProgress.Render(p.toolQueryInput+"█")
+		if p.toolQueryErr != nil {
+			msg := p.toolQueryErr.Error()
+			if qe, ok := p.toolQueryErr.(*QueryError); ok {
+				msg = qe.Msg
+			}
+			line += " " + styles.StatusBlocked.Render("✗ "+msg)
+		}
+		lines = append(lines, ansi.Truncate(line, width, "…"))
+	case p.toolQuery != "":
+		lines = append(lines, ansi.Truncate(styles.Muted.Render(" Filter: ")+styles.Body.Render(p.toolQuery), width, "…"))
+	default:
+		lines = append(lines, styles.Muted.Render(" Filter: none (/ to filter by adapter:, model:, date: …)"))
+	}
+
+	r := p.toolReport
+	if r == nil {
+		r = &toolReport{}
+	}
+	summary := fmt.Sprintf(" %s calls │ %s failed (%.1f%%) │ %d sessions",
+		formatLargeNumber(r.Calls), formatLargeNumber(r.Errors), percent(r.Errors, r.Calls), r.Sessions)
+	if r.Pending > 0 {
+		summary += fmt.Sprintf(" │ indexing %d…", r.Pending)
+	}
+	lines = append(lines, styles.Body.Render(summary))
+	lines = append(lines, "")
+
+	if len(r.Tools) == 0 {
+		if r.Pending > 0 {
+			lines = append(lines, styles.Muted.Render(" Reading sessions…"))
+		} else {
+			lines = append(lines, styles.Muted.Render(" No tool calls found"))
+		}
+		return p.scrollToolLines(lines, -1, nil)
+	}
+
+	// Per-tool table
+	lines = append(lines, styles.Title.Render(" Tools"))
+	lines = append(lines, styles.Subtitle.Render(fmt.Sprintf("   %-20s %7s %7s %6s %9s %9s", "Tool", "Calls", "Errors", "Err%", "Avg out", "Avg time")))
+	lines = append(lines, rule("─"))
+	selectedLine := -1
+	for i := range r.Tools {
+		s := &r.Tools[i]
+		avgTime := "-"
+		if d := s.AvgDuration(); d > 0 {
+			avgTime = formatToolDuration(d)
+		}
+		rows = append(rows, toolRowRegion{line: len(lines), id: regionToolRow, index: i})
+		row := fmt.Sprintf(" %-20s %7d %7d %5.1f%% %9s %9s",
+			ansi.Truncate(s.Tool, 20, "…"), s.Calls, s.Errors, 100*s.ErrorRate(), formatLargeNumber(s.AvgOutput())+"B", avgTime)
+		if i == p.toolCursor {
+			if p.toolFocus == toolFocusTools {
+				selectedLine = len(lines)
+			}
+			lines = append(lines, styles.ListItemSelected.Render(padToWidth(">"+row, width)))
+			continue
+		}
+		style := styles.Body
+		if s.ErrorRate() >= 0.2 {
+			style = styles.StatusDeleted
+		}
+		lines = append(lines, style.Render(" "+row))
+	}
+	lines = append(lines, "")
+
+	// Most common failing shell commands
+	if len(r.FailingCommands) > 0 {
+		lines = append(lines, styles.Title.Render(" Failing Shell Commands"))
+		lines = append(lines, rule("─"))
+		for _, fc := range r.FailingCommands {
+			count := styles.StatusDeleted.Render(fmt.Sprintf(" %4d× ", fc.Count))
+			lines = append(lines, count+styles.Body.Render(ansi.Truncate(fc.Command, width-7, "…")))
+		}
+		lines = append(lines, "")
+	}
+
+	// Calls of the selected tool
+	if s := p.selectedToolStats(); s != nil {
+		title := " Failed Calls: " + s.Tool
+		toggle := "e: show all calls"
+		if !p.toolErrorsOnly {
+			title = " Calls: " + s.Tool
+			toggle = "e: failures only"
+		}
+		lines = append(lines, styles.Title.Render(title)+styles.Muted.Render("  "+toggle+"  enter: open"))
+		lines = append(lines, rule("─"))
+		calls := p.selectedToolCalls()
+		if len(calls) == 0 {
+			lines = append(lines, styles.Muted.Render(" None"))
+		}
+		for i := range calls {
+			rows = append(rows, toolRowRegion{line: len(lines), id: regionToolCall, index: i})
+			row := p.renderToolCallRow(&calls[i], width)
+			if i == p.toolCallCursor && p.toolFocus == toolFocusCalls {
+				selectedLine = len(lines)
+				lines = append(lines, styles.ListItemSelected.Render(padToWidth(ansi.Strip(row), width)))
+				continue
+			}
+			lines = append(lines, row)
+		}
+	}
+
+	return p.scrollToolLines(lines, selectedLine, rows)
+}
+
+// renderToolCallRow renders one call: time, session and what it ran or
+// why it failed.
+func (p *Plugin) renderToolCallRow(c *toolCall, width int) string {
+	ts := "           "
+	if !c.Timestamp.IsZero() {
+		ts = c.Timestamp.Local().Format("Jan 02 15:04")
+	}
+	session := p.sessionDisplayName(c.SessionID, 24)
+	detail := c.Command
+	if c.Failed && c.Error != "" {
+		if detail != "" {
+			detail += " — "
+		}
+		detail += c.Error
+	}
+	prefix := fmt.Sprintf(" %s  %-24s  ", ts, session)
+	detail = ansi.Truncate(detail, max(width-ansi.StringWidth(prefix), 10), "…")
+	style := styles.Body
+	if c.Failed {
+		style = styles.StatusDeleted
+	}
+	return styles.Muted.Render(prefix) + style.Render(detail)
+}
+
+// toolRowRegion records which line of the tool analytics view shows a
+// clickable tool or call row.
+type toolRowRegion struct {
+	line  int
+	id    string
+	index int
+}
+
+// scrollToolLines applies the view's scroll offset, keeping the selected
+// line visible, and registers hit regions for the visible rows.
+func (p *Plugin) scrollToolLines(lines []string, selected int, rows []toolRowRegion) string {
+	height := max(p.height-2, 1)
+	if selected >= 0 {
+		if selected < p.toolScroll {
+			p.toolScroll = selected
+		}
+		if selected >= p.toolScroll+height {
+			p.toolScroll = selected - height + 1
+		}
+	}
+	p.toolScroll = max(min(p.toolScroll, len(lines)-height), 0)
+	end := min(p.toolScroll+height, len(lines))
+
+	p.mouseHandler.HitMap.Clear()
+	for _, r := range rows {
+		if r.line >= p.toolScroll && r.line < end {
+			p.mouseHandler.HitMap.AddRect(r.id, 0, r.line-p.toolScroll, p.width, 1, r.index)
+		}
+	}
+	return strings.Join(lines[p.toolScroll:end], "\n")
+}
+
+// formatToolDuration formats a tool call duration.
+func formatToolDuration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	case d < time.Minute:
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	}
+	return formatSessionDuration(d)
+}
+
+// percent returns n as a percentage of total.
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(n) / float64(total)
+}
+
+// padToWidth pads s with spaces to width cells.
+func padToWidth(s string, width int) string {
+	if w := ansi.StringWidth(s); w < width {
+		return s + strings.Repeat(" ", width-w)
+	}
+	return s
+}
@@ -0,0 +1,245 @@
+package conversations
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/mouse"
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+func TestExtractToolCalls(t *testing.T) {
+	start := time.Date(2026, 9, 1, 10, 0, 0, 0, time.UTC)
+	messages := []adapter.Message{
+		{ID: "m1", Role: "assistant", Model: "claude-opus-4", Timestamp: start, ContentBlocks: []adapter.ContentBlock{
+			{Type: "text", Text: "Running tests"},
+			{Type: "tool_use", ToolUseID: "t1", ToolName: "Bash", ToolInput: `{"command":"go test ./...\necho done"}`},
+			{Type: "tool_use", ToolUseID: "t2", ToolName: "Read", ToolInput: `{"file_path":"/src/main.go"}`},
+		}, ToolUses: []adapter.ToolUse{
+			{ID: "t1", Name: "Bash"},
+			{ID: "t2", Name: "Read"},
+		}},
+		{ID: "m2", Role: "user", Timestamp: start.Add(3 * time.Second), ContentBlocks: []adapter.ContentBlock{
+			{Type: "tool_result", ToolUseID: "t1", ToolOutput: "FAIL: TestX\nexit status 1", IsError: true},
+			{Type: "tool_result", ToolUseID: "t2", ToolOutput: "package main"},
+		}},
+		// Adapters without content blocks only report ToolUses
+		{ID: "m3", Role: "assistant", Model: "gpt-5", Timestamp: start.Add(time.Minute), ToolUses: []adapter.ToolUse{
+			{ID: "t3", Name: "shell", Input: `{"command":["bash","-lc","make lint"]}`, Output: "ok"},
+		}},
+	}
+
+	calls := extractToolCalls(messages)
+	if len(calls) != 3 {
+		t.Fatalf("got %d calls, want 3", len(calls))
+	}
+	bash := calls[0]
+	if bash.Tool != "Bash" || bash.MessageID != "m1" || bash.Command != "go test ./..." {
+		t.Errorf("bash call = %+v", bash)
+	}
+	if !bash.Failed || bash.Error != "FAIL: TestX" || bash.Duration != 3*time.Second || bash.OutputSize != 25 {
+		t.Errorf("bash result = %+v", bash)
+	}
+	if read := calls[1]; read.Failed || read.Command != "" || !read.HasResult || read.OutputSize != 12 {
+		t.Errorf("read call = %+v", read)
+	}
+	if shell := calls[2]; shell.Model != "gpt-5" || shell.Command != "make lint" || !shell.HasResult || shell.OutputSize != 2 {
+		t.Errorf("shell call = %+v", shell)
+	}
+}
+
+func TestBuildToolReport(t *testing.T) {
+	now := time.Now()
+	calls := []toolCall{
+		{SessionID: "s1", Tool: "Bash", Command: "go test", Failed: true, HasResult: true, OutputSize: 100, Timestamp: now.Add(-3 * time.Minute), Duration: 2 * time.Second},
+		{SessionID: "s1", Tool: "Bash", Command: "go test", Failed: true, HasResult: true, OutputSize: 300, Timestamp: now.Add(-2 * time.Minute), Duration: 4 * time.Second},
+		{SessionID: "s2", Tool: "Bash", Command: "make", Failed: true, HasResult: true, Timestamp: now.Add(-time.Minute)},
+		{SessionID: "s2", Tool: "Bash", Command: "ls", HasResult: true, OutputSize: 200, Timestamp: now},
+		{SessionID: "s2", Tool: "Read"},
+	}
+	r := buildToolReport(calls)
+	if r.Calls != 5 || r.Errors != 3 || r.Sessions != 2 || len(r.Tools) != 2 {
+		t.Fatalf("report = %+v", r)
+	}
+	bash := r.Tools[0]
+	if bash.Tool != "Bash" || bash.Calls != 4 || bash.Errors != 3 || bash.ErrorRate() != 0.75 {
+		t.Errorf("bash stats = %+v", bash)
+	}
+	if bash.AvgOutput() != 150 || bash.AvgDuration() != 3*time.Second {
+		t.Errorf("avg output = %d, avg duration = %v", bash.AvgOutput(), bash.AvgDuration())
+	}
+	if read := r.Tools[1]; read.AvgOutput() != 0 || read.AvgDuration() != 0 {
+		t.Errorf("read stats = %+v", read)
+	}
+	if len(r.FailingCommands) != 2 || r.FailingCommands[0] != (failingCommand{"go test", 2}) {
+		t.Errorf("failing commands = %+v", r.FailingCommands)
+	}
+	if got := r.CallsByTool["Bash"]; got[0].Command != "ls" || got[3].Duration != 2*time.Second {
+		t.Errorf("calls should be newest first: %+v", got)
+	}
+}
+
+func TestCallMatches(t *testing.T) {
+	c := &toolCall{Tool: "Bash", Model: "claude-opus-4", Timestamp: time.Date(2026, 9, 10, 12, 0, 0, 0, time.Local)}
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"model:opus", true},
+		{"model:sonnet", false},
+		{"has:tool:bash", true},
+		{"has:tool:Read", false},
+		{"after:2026-09-01", true},
+		{"before:2026-09-01", false},
+		{"adapter:codex", true}, // session-level, not checked per call
+	}
+	for _, tt := range tests {
+		f, err := ParseQuery(tt.query, nil)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.query, err)
+		}
+		if got := callMatches(&f, c); got != tt.want {
+			t.Errorf("%q: callMatches = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	// Sessions updated after the end date can still hold earlier calls
+	f, _ := ParseQuery("model:opus before:2026-09-01 after:2026-08-01", nil)
+	sf := toolSessionFilters(f)
+	if sf.Models != nil || !sf.DateRange.End.IsZero() || sf.DateRange.Start.IsZero() {
+		t.Errorf("session filters = %+v", sf)
+	}
+}
+
+// callAdapter returns fixed messages per session.
+type callAdapter struct {
+	mockAdapter
+	messages map[string][]adapter.Message
+}
+
+func (a *callAdapter) Messages(sessionID string) ([]adapter.Message, error) {
+	return a.messages[sessionID], nil
+}
+
+func newToolAnalyticsPlugin() *Plugin {
+	now := time.Now()
+	failed := func(id, cmd string) []adapter.Message {
+		return []adapter.Message{
+			{ID: id + "-use", Role: "assistant", Model: "claude-opus-4", Timestamp: now, ContentBlocks: []adapter.ContentBlock{
+				{Type: "tool_use", ToolUseID: id, ToolName: "Bash", ToolInput: `{"command":"` + cmd + `"}`},
+				{Type: "tool_use", ToolUseID: id + "r", ToolName: "Read", ToolInput: `{"file_path":"/a.go"}`},
+			}},
+			{ID: id + "-result", Role: "user", Timestamp: now, ContentBlocks: []adapter.ContentBlock{
+				{Type: "tool_result", ToolUseID: id, ToolOutput: "exit status 1", IsError: true},
+				{Type: "tool_result", ToolUseID: id + "r", ToolOutput: "package a"},
+			}},
+		}
+	}
+	p := New()
+	p.ctx = &plugin.Context{WorkDir: "/proj"}
+	p.width, p.height = 120, 40
+	p.adapters = map[string]adapter.Adapter{"mock": &callAdapter{messages: map[string][]adapter.Message{
+		"s1": failed("t1", "go test"),
+		"s2": failed("t2", "make"),
+	}}}
+	p.sessions = []adapter.Session{
+		{ID: "s1", Name: "first", AdapterID: "mock", MessageCount: 2, UpdatedAt: now},
+		{ID: "s2", Name: "second", AdapterID: "mock", MessageCount: 2, UpdatedAt: now.Add(-time.Minute)},
+	}
+	return p
+}
+
+func TestToolAnalyticsFlow(t *testing.T) {
+	p := newToolAnalyticsPlugin()
+	cmd := p.openToolAnalytics("")
+	if p.view != ViewToolAnalytics || p.toolReport.Pending != 2 {
+		t.Fatalf("view = %v, report = %+v", p.view, p.toolReport)
+	}
+	if !strings.Contains(p.renderToolAnalytics(), "indexing 2…") {
+		t.Error("view should show indexing progress")
+	}
+	for _, m := range runBatch(cmd) {
+		if im, ok := m.(MessageIndexMsg); ok {
+			p.applyMessageIndex(im)
+		}
+	}
+
+	r := p.toolReport
+	if r.Pending != 0 || r.Calls != 4 || r.Errors != 2 || r.Tools[0].Tool != "Bash" {
+		t.Fatalf("report = %+v", r)
+	}
+	out := p.renderToolAnalytics()
+	for _, want := range []string{"Failing Shell Commands", "go test", "Failed Calls: Bash", "exit status 1"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("view missing %q", want)
+		}
+	}
+
+	// Only failures are listed until e shows all calls
+	p.toolCursor = 1
+	if len(p.selectedToolCalls()) != 0 {
+		t.Error("Read has no failures")
+	}
+	p.updateToolAnalytics(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	if len(p.selectedToolCalls()) != 2 {
+		t.Errorf("all Read calls = %d, want 2", len(p.selectedToolCalls()))
+	}
+
+	// Filters apply per call and per session
+	p.toolQueryMode = true
+	p.toolQueryInput = "has:tool:Read"
+	p.updateToolQuery(tea.KeyMsg{Type: tea.KeyEnter})
+	if p.toolReport.Calls != 2 || p.toolReport.Tools[0].Tool != "Read" {
+		t.Errorf("filtered report = %+v", p.toolReport)
+	}
+	p.toolQueryMode = true
+	p.toolQueryInput = "cost>"
+	p.updateToolQuery(tea.KeyMsg{Type: tea.KeyEnter})
+	if p.toolQueryErr == nil || !p.toolQueryMode {
+		t.Error("invalid filter should stay in edit mode with an error")
+	}
+
+	// Scoping to one session keeps the filter
+	p.toolQueryInput = "has:tool:Bash"
+	p.updateToolQuery(tea.KeyMsg{Type: tea.KeyEnter})
+	p.openToolAnalytics("s2")
+	if p.toolReport.Sessions != 1 || len(p.toolReport.FailingCommands) != 1 || p.toolReport.FailingCommands[0].Command != "make" {
+		t.Errorf("session report = %+v", p.toolReport)
+	}
+}
+
+func TestToolAnalyticsOpenCall(t *testing.T) {
+	p := newToolAnalyticsPlugin()
+	for _, m := range runBatch(p.openToolAnalytics("")) {
+		if im, ok := m.(MessageIndexMsg); ok {
+			p.applyMessageIndex(im)
+		}
+	}
+	p.renderToolAnalytics()
+
+	// Clicking a call selects it; clicking it again opens its message
+	var region *mouse.Region
+	for _, r := range p.mouseHandler.HitMap.Regions() {
+		if r.ID == regionToolCall && r.Data == 1 {
+			region = &r
+		}
+	}
+	if region == nil {
+		t.Fatal("no hit region for the second call")
+	}
+	click := mouse.MouseAction{Type: mouse.ActionClick, Region: region}
+	if cmd := p.handleToolAnalyticsMouse(click); cmd != nil || p.toolFocus != toolFocusCalls || p.toolCallCursor != 1 {
+		t.Fatalf("focus = %d, cursor = %d", p.toolFocus, p.toolCallCursor)
+	}
+	want := p.selectedToolCalls()[1]
+	p.handleToolAnalyticsMouse(click)
+	if p.view != ViewSessions || p.selectedSession != want.SessionID {
+		t.Errorf("view = %v, session = %q, want %q", p.view, p.selectedSession, want.SessionID)
+	}
+	if !p.pendingScrollActive || p.pendingScrollMsgID != want.MessageID {
+		t.Errorf("pending scroll = %v %q, want %q", p.pendingScrollActive, p.pendingScrollMsgID, want.MessageID)
+	}
+}
@@ -0,0 +1,400 @@
+package conversations
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/marcus/sidecar/internal/app"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/plugins/gitstatus"
+	"github.com/marcus/sidecar/internal/styles"
+)
+
+// ChangesLoadedMsg carries the file changes extracted from a whole session.
+type ChangesLoadedMsg struct {
+	Epoch     uint64
+	SessionID string
+	Changes   []FileChange
+	Err       error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m ChangesLoadedMsg) GetEpoch() uint64 { return m.Epoch }
+
+// openChanges shows the files-touched timeline for the current session.
+func (p *Plugin) openChanges() tea.Cmd {
+	if p.selectedSession == "" {
+		return nil
+	}
+	p.changes = nil
+	p.changesMode = true
+	p.changesLoading = true
+	p.changesErr = nil
+	p.changeCursor = 0
+	p.changeScroll = 0
+	p.changeDiffScroll = 0
+	return p.loadChanges(p.selectedSession)
+}
+
+// loadChanges extracts file changes from every message in a session, not
+// just the page shown in the message list.
+func (p *Plugin) loadChanges(sessionID string) tea.Cmd {
+	a := p.adapterForSession(sessionID)
+	var epoch uint64
+	if p.ctx != nil {
+		epoch = p.ctx.Epoch
+	}
+	return func() tea.Msg {
+		msg := ChangesLoadedMsg{Epoch: epoch, SessionID: sessionID}
+		if a == nil {
+			msg.Err = fmt.Errorf("no adapter for session")
+			return msg
+		}
+		messages, err := a.Messages(sessionID)
+		if err != nil {
+			msg.Err = err
+			return msg
+		}
+		msg.Changes = ExtractFileChanges(messages)
+		return msg
+	}
+}
+
+// applyChangesLoaded shows loaded changes. Sessions only grow, so a refresh
+// keeps the cursor where it was.
+func (p *Plugin) applyChangesLoaded(msg ChangesLoadedMsg) {
+	if !p.changesMode || msg.SessionID != p.selectedSession {
+		return
+	}
+	p.changesLoading = false
+	p.changesErr = msg.Err
+	if msg.Err != nil {
+		return
+	}
+	p.changes = msg.Changes
+	if p.changeCursor >= len(p.changes) {
+		p.changeCursor = max(len(p.changes)-1, 0)
+	}
+}
+
+// closeChanges leaves the changes view.
+func (p *Plugin) closeChanges() {
+	p.changesMode = false
+	p.changesLoading = false
+	p.changesErr = nil
+	p.changes = nil
+	p.changeCursor = 0
+	p.changeScroll = 0
+	p.changeDiffScroll = 0
+}
+
+// refreshChanges reloads changes after new messages arrive in the session
+// being shown.
+func (p *Plugin) refreshChanges(sessionID string) tea.Cmd {
+	if !p.changesMode || p.changesLoading || sessionID != p.selectedSession {
+		return nil
+	}
+	p.changesLoading = true
+	return p.loadChanges(sessionID)
+}
+
+// selectedChange returns the change under the cursor.
+func (p *Plugin) selectedChange() *FileChange {
+	if p.changeCursor < 0 || p.changeCursor >= len(p.changes) {
+		return nil
+	}
+	return &p.changes[p.changeCursor]
+}
+
+// changeTurnIndex returns the index of the loaded turn containing a change's
+// message, or -1 if that message isn't in the loaded page.
+func (p *Plugin) changeTurnIndex(c *FileChange) int {
+	for i := range p.turns {
+		for _, m := range p.turns[i].Messages {
+			if m.ID == c.MessageID {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// changeProjectPath returns a change's current path relative to the project
+// root, or false if it lies outside the project.
+func (p *Plugin) changeProjectPath(c *FileChange) (string, bool) {
+	path := c.Path
+	if c.NewPath != "" && c.Op == FileOpMove {
+		path = c.NewPath
+	}
+	if !filepath.IsAbs(path) {
+		return filepath.Clean(path), true
+	}
+	rel, err := filepath.Rel(p.ctx.WorkDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+// updateChangesMode handles key events in the changes view.
+func (p *Plugin) updateChangesMode(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "h", "left", "D":
+		p.closeChanges()
+
+	case "j", "down":
+		if p.changeCursor < len(p.changes)-1 {
+			p.changeCursor++
+			p.changeDiffScroll = 0
+		}
+
+	case "k", "up":
+		if p.changeCursor > 0 {
+			p.changeCursor--
+			p.changeDiffScroll = 0
+		}
+
+	case "g":
+		p.changeCursor = 0
+		p.changeDiffScroll = 0
+
+	case "G":
+		p.changeCursor = max(len(p.changes)-1, 0)
+		p.changeDiffScroll = 0
+
+	case "ctrl+d":
+		p.changeDiffScroll += 10
+
+	case "ctrl+u":
+		p.changeDiffScroll = max(p.changeDiffScroll-10, 0)
+
+	case "enter":
+		// Show the turn that made the change
+		c := p.selectedChange()
+		if c == nil {
+			return p, nil
+		}
+		idx := p.changeTurnIndex(c)
+		if idx < 0 {
+			return p, appmsg.ShowToast("Turn is not in the loaded messages", 2*time.Second)
+		}
+		p.closeChanges()
+		p.turnCursor = idx
+		p.detailTurn = &p.turns[idx]
+		p.detailScroll = 0
+		p.detailMode = true
+
+	case "o":
+		c := p.selectedChange()
+		if c == nil {
+			return p, nil
+		}
+		path, ok := p.changeProjectPath(c)
+		if !ok {
+			return p, appmsg.ShowToast("File is outside the project", 2*time.Second)
+		}
+		return p, tea.Batch(
+			app.FocusPlugin("file-browser"),
+			func() tea.Msg { return appmsg.NavigateToFileMsg{Path: path} },
+		)
+
+	case "d":
+		c := p.selectedChange()
+		if c == nil {
+			return p, nil
+		}
+		path, ok := p.changeProjectPath(c)
+		if !ok {
+			return p, appmsg.ShowToast("File is outside the project", 2*time.Second)
+		}
+		return p, tea.Batch(
+			app.FocusPlugin("git-status"),
+			func() tea.Msg { return appmsg.ShowFileDiffMsg{Path: path} },
+		)
+	}
+	return p, nil
+}
+
+// changeOpLabel returns the styled, fixed-width label for an operation.
+func changeOpLabel(op FileOp) string {
+	label := fmt.Sprintf("%-6s", strings.ToUpper(string(op)))
+	switch op {
+	case FileOpWrite, FileOpCopy:
+		return styles.StatusStaged.Render(label)
+	case FileOpDelete:
+		return styles.StatusDeleted.Render(label)
+	case FileOpMove:
+		return styles.Subtitle.Render(label)
+	}
+	return styles.StatusModified.Render(label)
+}
+
+// changeSummary returns "N changes to M files, +A -R" for the timeline.
+func changeSummary(changes []FileChange) string {
+	files := make(map[string]bool)
+	added, removed := 0, 0
+	for i := range changes {
+		files[changes[i].Path] = true
+		a, r := changes[i].DiffStats()
+		added += a
+		removed += r
+	}
+	noun := "changes"
+	if len(changes) == 1 {
+		noun = "change"
+	}
+	fileNoun := "files"
+	if len(files) == 1 {
+		fileNoun = "file"
+	}
+	return fmt.Sprintf("%d %s to %d %s, +%d -%d", len(changes), noun, len(files), fileNoun, added, removed)
+}
+
+// renderChangesPaneContent renders the files-touched timeline with the
+// selected change's diff below it.
+func (p *Plugin) renderChangesPaneContent(contentWidth, height int) string {
+	var sb strings.Builder
+	sb.WriteString(styles.Title.Render("File Changes"))
+	sb.WriteString("  ")
+	sb.WriteString(styles.Muted.Render("[esc]"))
+	sb.WriteString("\n")
+
+	switch {
+	case p.changesErr != nil:
+		sb.WriteString(styles.StatusDeleted.Render("Unable to load changes: " + p.changesErr.Error()))
+		return sb.String()
+	case len(p.changes) == 0 && p.changesLoading:
+		sb.WriteString(styles.Muted.Render("Loading file changes…"))
+		return sb.String()
+	case len(p.changes) == 0:
+		sb.WriteString(styles.Muted.Render("No file changes found in this session"))
+		return sb.String()
+	}
+	sb.WriteString(styles.Muted.Render(ansi.Truncate(changeSummary(p.changes), contentWidth, "…")))
+	sb.WriteString("\n")
+
+	// The timeline takes up to 40% of the pane; the diff gets the rest
+	body := max(height-2, 4)
+	listHeight := min(len(p.changes), max(body*2/5, 3))
+	if p.changeCursor < p.changeScroll {
+		p.changeScroll = p.changeCursor
+	}
+	if p.changeCursor >= p.changeScroll+listHeight {
+		p.changeScroll = p.changeCursor - listHeight + 1
+	}
+	p.changeScroll = max(min(p.changeScroll, len(p.changes)-listHeight), 0)
+
+	for i := p.changeScroll; i < p.changeScroll+listHeight && i < len(p.changes); i++ {
+		sb.WriteString(p.renderChangeRow(i, contentWidth))
+		sb.WriteString("\n")
+	}
+
+	sepWidth := min(contentWidth, 60)
+	sb.WriteString(styles.Muted.Render(strings.Repeat("─", sepWidth)))
+	sb.WriteString("\n")
+	sb.WriteString(p.renderChangeDetail(contentWidth, body-listHeight-1))
+	return sb.String()
+}
+
+// renderChangeRow renders one timeline entry.
+func (p *Plugin) renderChangeRow(i, width int) string {
+	c := &p.changes[i]
+	turn := ""
+	if idx := p.changeTurnIndex(c); idx >= 0 {
+		turn = fmt.Sprintf("#%d", idx+1)
+	}
+	ts := ""
+	if !c.Timestamp.IsZero() {
+		ts = c.Timestamp.Local().Format("15:04")
+	}
+	path := c.Path
+	if c.NewPath != "" {
+		path += " → " + c.NewPath
+	}
+	stats := ""
+	if a, r := c.DiffStats(); a > 0 || r > 0 {
+		stats = fmt.Sprintf(" +%d -%d", a, r)
+	}
+	if c.Failed {
+		stats += " failed"
+	}
+
+	prefix := fmt.Sprintf("%5s %4s ", ts, turn)
+	opWidth := 7
+	pathWidth := max(width-ansi.StringWidth(prefix)-opWidth-len(stats), 10)
+	path = truncateLeft(path, pathWidth)
+
+	if i == p.changeCursor {
+		row := prefix + fmt.Sprintf("%-6s ", strings.ToUpper(string(c.Op))) + path + stats
+		if w := ansi.StringWidth(row); w < width {
+			row += strings.Repeat(" ", width-w)
+		}
+		return styles.ListItemSelected.Render(row)
+	}
+	statStyle := styles.Muted
+	if c.Failed {
+		statStyle = styles.StatusDeleted
+	}
+	return styles.Muted.Render(prefix) + changeOpLabel(c.Op) + " " + path + statStyle.Render(stats)
+}
+
+// truncateLeft shortens a path from the left so the file name stays visible.
+func truncateLeft(s string, width int) string {
+	if ansi.StringWidth(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && ansi.StringWidth(string(runes))+1 > width {
+		runes = runes[1:]
+	}
+	return "…" + string(runes)
+}
+
+// renderChangeDetail renders the selected change's context and diff.
+func (p *Plugin) renderChangeDetail(width, height int) string {
+	c := p.selectedChange()
+	if c == nil || height < 1 {
+		return ""
+	}
+
+	var lines []string
+	meta := c.Tool
+	if idx := p.changeTurnIndex(c); idx >= 0 {
+		meta = fmt.Sprintf("turn %d · %s", idx+1, c.Tool)
+	}
+	lines = append(lines, styles.Muted.Render(ansi.Truncate(meta, width, "…")))
+	reason := c.Reason
+	if reason == "" {
+		if idx := p.changeTurnIndex(c); idx >= 0 {
+			reason = p.turns[idx].Preview(width)
+		}
+	}
+	if reason != "" {
+		lines = append(lines, ansi.Truncate(reason, width, "…"))
+	}
+	if c.Command != "" {
+		lines = append(lines, styles.Code.Render(ansi.Truncate("$ "+firstLine(c.Command), width, "…")))
+	}
+
+	diffHeight := height - len(lines)
+	switch {
+	case diffHeight < 1:
+	case c.Diff == nil || len(c.Diff.Hunks) == 0:
+		note := "No diff available for this change"
+		if c.Op == FileOpDelete {
+			note = "File deleted"
+		}
+		lines = append(lines, styles.Muted.Render(note))
+	default:
+		total := c.Diff.TotalLines()
+		p.changeDiffScroll = max(min(p.changeDiffScroll, total-diffHeight), 0)
+		highlighter := gitstatus.NewSyntaxHighlighter(c.Path)
+		lines = append(lines, gitstatus.RenderLineDiff(c.Diff, width, p.changeDiffScroll, diffHeight, 0, highlighter, false))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -120,29 +120,40 @@ func formatCost(cost float64) string {
 	return fmt.Sprintf("$%.1f", cost)
 }
 
-// renderCategoryBadge returns a dim category badge for non-interactive sessions.
+// renderCategoryBadge returns a dim category badge for non-interactive, archived or imported sessions.
 // Interactive sessions return empty string (clean default).
 func renderCategoryBadge(session adapter.Session) string {
-	switch session.SessionCategory {
-	case adapter.SessionCategoryCron:
-		return lipgloss.NewStyle().Foreground(styles.TextSubtle).Render("cron")
-	case adapter.SessionCategorySystem:
-		return lipgloss.NewStyle().Foreground(styles.TextSubtle).Render("sys")
-	default:
+	text := categoryBadgeText(session)
+	if text == "" {
 		return ""
 	}
+	return lipgloss.NewStyle().Foreground(styles.TextSubtle).Render(text)
 }
 
 // categoryBadgeText returns the plain text for a category badge (for width calculations).
+// Sessions served from the archive are marked "archived", and sessions
+// imported from transcript files "imported".
 func categoryBadgeText(session adapter.Session) string {
+	var text string
 	switch session.SessionCategory {
 	case adapter.SessionCategoryCron:
-		return "cron"
+		text = "cron"
 	case adapter.SessionCategorySystem:
-		return "sys"
-	default:
-		return ""
+		text = "sys"
+	}
+	if session.Archived {
+		if text != "" {
+			text += " "
+		}
+		text += "archived"
+	}
+	if session.Imported {
+		if text != "" {
+			text += " "
+		}
+		text += "imported"
 	}
+	return text
 }
 
 func adapterBadgeText(session adapter.Session) string {
@@ -326,6 +337,27 @@ func adapterFilterOptions(adapters map[string]adapter.Adapter) []adapterFilterOp
 	return options
 }
 
+// tagFilterKeys are the filter menu keys for tags, in order. Tags beyond
+// these can be searched with "#tag".
+const tagFilterKeys = "4567890"
+
+type tagFilterOption struct {
+	key string
+	tag string
+}
+
+// tagFilterOptions assigns filter menu keys to the first tags.
+func tagFilterOptions(tags []string) []tagFilterOption {
+	var options []tagFilterOption
+	for i, tag := range tags {
+		if i >= len(tagFilterKeys) {
+			break
+		}
+		options = append(options, tagFilterOption{key: tagFilterKeys[i : i+1], tag: tag})
+	}
+	return options
+}
+
 func resumeCommand(session *adapter.Session) string {
 	if session == nil || session.ID == "" {
 		return ""
@@ -714,6 +746,22 @@ func (p *Plugin) renderMessageBubble(msg adapter.Message, msgIndex int, maxWidth
 	}
 	lines = append(lines, headerLine)
 
+	// Bookmark note under the header
+	if b := p.selectedAnnotations().Bookmark(msg.ID); b != nil {
+		note := "◆ bookmarked"
+		if b.Note != "" {
+			note = "◆ " + b.Note
+		}
+		if runes := []rune(note); len(runes) > maxWidth-4 && maxWidth > 7 {
+			note = string(runes[:maxWidth-7]) + "..."
+		}
+		if selected {
+			lines = append(lines, "    "+note)
+		} else {
+			lines = append(lines, "    "+styles.StatusModified.Render(note))
+		}
+	}
+
 	// Render content blocks (same for selected and non-selected)
 	if len(msg.ContentBlocks) > 0 {
 		blockLines := p.renderContentBlocks(msg, maxWidth-4)
@@ -932,6 +980,12 @@ func (p *Plugin) renderToolUseBlock(block adapter.ContentBlock, maxWidth int) []
 		lines = append(lines, styles.Code.Render(toolHeader))
 	}
 
+	// Link to the sub-agent transcript this call spawned
+	if childID := p.subAgentCalls[block.ToolUseID]; childID != "" && block.ToolUseID != "" {
+		link := "  ↳ sub-agent: " + p.sessionDisplayName(childID, max(10, maxWidth-24)) + " [a:open]"
+		lines = append(lines, styles.Subtle.Render(link))
+	}
+
 	// Show result if expanded or if there's an error
 	if block.ToolOutput != "" && (expanded || block.IsError) {
 		output := block.ToolOutput
@@ -1072,12 +1126,33 @@ func (p *Plugin) renderFilterMenu(height int) string {
 	}
 	sb.WriteString("\n")
 
+	// Tag filters
+	if tagOptions := tagFilterOptions(p.allTags()); len(tagOptions) > 0 {
+		sb.WriteString(styles.Subtitle.Render("Tag:"))
+		sb.WriteString("\n")
+		for _, t := range tagOptions {
+			checkbox := "[ ]"
+			if p.filters.HasTag(t.tag) {
+				checkbox = "[✓]"
+			}
+			sb.WriteString(fmt.Sprintf("  %s %s #%s\n", styles.Code.Render(t.key), checkbox, t.tag))
+		}
+		sb.WriteString("\n")
+	}
+
 	// Active only
 	activeCheck := "[ ]"
 	if p.filters.ActiveOnly {
 		activeCheck = "[✓]"
 	}
 	sb.WriteString(fmt.Sprintf("  %s %s Active only\n", styles.Code.Render("a"), activeCheck))
+
+	// Starred only
+	starredCheck := "[ ]"
+	if p.filters.Starred {
+		starredCheck = "[✓]"
+	}
+	sb.WriteString(fmt.Sprintf("  %s %s Starred only\n", styles.Code.Render("*"), starredCheck))
 	sb.WriteString("\n")
 
 	// Clear filters
@@ -5,11 +5,39 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/marcus/sidecar/internal/adapter"
 	"github.com/marcus/sidecar/internal/styles"
 	"github.com/marcus/sidecar/internal/ui"
 )
 
+// renderSearchLine renders the search bar: the query, then its syntax
+// error, completion candidates or indexing status.
+func (p *Plugin) renderSearchLine(width int) string {
+	if p.querySaveMode {
+		return ansi.Truncate(styles.Muted.Render("Save query as ")+styles.StatusInProgress.Render("@"+p.querySaveName+"█"), width, "…")
+	}
+	// Long queries scroll so the end being typed stays visible
+	query := fmt.Sprintf("/%s█", p.searchQuery)
+	if over := ansi.StringWidth(query) - width; over > 0 {
+		query = ansi.TruncateLeft(query, over+1, "…")
+	}
+	line := styles.StatusInProgress.Render(query)
+	switch {
+	case p.searchErr != nil:
+		msg := p.searchErr.Error()
+		if qe, ok := p.searchErr.(*QueryError); ok {
+			msg = qe.Msg
+		}
+		line += " " + styles.StatusBlocked.Render("✗ "+msg)
+	case len(p.searchHints) > 0:
+		line += " " + styles.Muted.Render(strings.Join(p.searchHints, " "))
+	case p.isIndexingMessages(&p.searchFilters):
+		line += " " + styles.Muted.Render("indexing…")
+	}
+	return ansi.Truncate(line, width, "…")
+}
+
 // renderTwoPane renders the two-pane layout with sessions on the left and messages on the right.
 func (p *Plugin) renderTwoPane() string {
 	// Check if hit regions need rebuilding (td-ea784b03)
@@ -164,11 +192,9 @@ func (p *Plugin) registerSessionHitRegions(sidebarWidth, contentHeight int) {
 	currentGroup := ""
 
 	for i := p.scrollOff; i < len(sessions) && lineCount < contentHeight; i++ {
-		session := sessions[i]
-
 		// In grouped mode (not searching), account for group headers and spacers
 		if !p.searchMode {
-			sessionGroup := getSessionGroup(session.UpdatedAt)
+			sessionGroup := p.sessionListGroup(sessions, i)
 			if sessionGroup != currentGroup {
 				// Spacer before Yesterday/This Week (except first group)
 				if currentGroup != "" && (sessionGroup == "Yesterday" || sessionGroup == "This Week") {
@@ -195,7 +221,7 @@ func (p *Plugin) registerSessionHitRegions(sidebarWidth, contentHeight int) {
 
 // registerTurnHitRegions registers mouse hit regions for visible turn items in the main pane.
 func (p *Plugin) registerTurnHitRegions(mainX, contentWidth, contentHeight int) {
-	if p.detailMode {
+	if p.detailMode || p.changesMode {
 		return
 	}
 
@@ -293,6 +319,9 @@ func (p *Plugin) renderSidebarPane(height int) string {
 		}
 		sb.WriteString(" " + styles.RenderPillWithStyle(catLabel, styles.BarChipActive, ""))
 	}
+	if p.compareMark != "" {
+		sb.WriteString(" " + styles.StatusModified.Render("[=]"))
+	}
 	// Show animated spinner while adapters are still sending batches (td-7198a5)
 	if p.loadingAdapters {
 		sb.WriteString(" " + p.adapterSpinner.View())
@@ -303,15 +332,14 @@ func (p *Plugin) renderSidebarPane(height int) string {
 
 	// Search bar (if in search mode)
 	if p.searchMode {
-		searchLine := fmt.Sprintf("/%s█", p.searchQuery)
-		if len(searchLine) > contentWidth {
-			searchLine = searchLine[:contentWidth]
-		}
-		sb.WriteString(styles.StatusInProgress.Render(searchLine))
+		sb.WriteString(p.renderSearchLine(contentWidth))
 		sb.WriteString("\n")
 		linesUsed++
 	} else if p.filterActive {
 		filterStr := p.filters.String()
+		if p.isIndexingMessages(&p.filters) {
+			filterStr += " indexing…"
+		}
 		if len(filterStr) > contentWidth {
 			filterStr = filterStr[:contentWidth-3] + "..."
 		}
@@ -419,7 +447,7 @@ func (p *Plugin) renderGroupedCompactSessions(sb *strings.Builder, groups []Sess
 
 	for i := p.scrollOff; i < len(sessions) && lineCount < contentHeight; i++ {
 		session := sessions[i]
-		sessionGroup := getSessionGroup(session.UpdatedAt)
+		sessionGroup := p.sessionListGroup(sessions, i)
 
 		if sessionGroup != currentGroup {
 			if currentGroup != "" && (sessionGroup == "Yesterday" || sessionGroup == "This Week") {
@@ -484,10 +512,20 @@ func (p *Plugin) renderCompactSessionRow(session adapter.Session, selected bool,
 		lengthCol = formatSessionDuration(session.Duration)
 	}
 
-	// Format token count - only if we have data
+	// Format token count - only if we have data. Parents include their
+	// sub-agents' tokens.
 	tokenCol := ""
-	if session.TotalTokens > 0 {
-		tokenCol = formatK(session.TotalTokens)
+	totalTokens := session.TotalTokens
+	treeBadge := ""
+	if t, ok := p.subAgentTotals[session.ID]; ok {
+		totalTokens += t.TotalTokens
+		treeBadge = fmt.Sprintf("▸%d", t.Count)
+		if p.expandedSubAgents[session.ID] {
+			treeBadge = fmt.Sprintf("▾%d", t.Count)
+		}
+	}
+	if totalTokens > 0 {
+		tokenCol = formatK(totalTokens)
 	}
 
 	// Calculate right column width (only for columns that have data)
@@ -505,6 +543,12 @@ func (p *Plugin) renderCompactSessionRow(session adapter.Session, selected bool,
 	// Category badge (cron/sys) for non-interactive sessions
 	catBadge := categoryBadgeText(session)
 
+	// Star for starred sessions
+	starBadge := ""
+	if p.annotationsFor(session).starred() {
+		starBadge = "★"
+	}
+
 	// Calculate prefix length for width calculations
 	// active(1) + badge + space + worktree + space (if worktree)
 	prefixLen := 1 + len(badgeText) + 1
@@ -514,6 +558,12 @@ func (p *Plugin) renderCompactSessionRow(session adapter.Session, selected bool,
 	if catBadge != "" {
 		prefixLen += len(catBadge) + 1 // category badge + space
 	}
+	if treeBadge != "" {
+		prefixLen += lipgloss.Width(treeBadge) + 1 // sub-agent badge + space
+	}
+	if starBadge != "" {
+		prefixLen += 2 // star + space
+	}
 	if session.IsSubAgent {
 		prefixLen += 2 // extra indent for sub-agents
 	}
@@ -552,6 +602,12 @@ func (p *Plugin) renderCompactSessionRow(session adapter.Session, selected bool,
 	if catBadge != "" {
 		visibleLen += len(catBadge) + 1 // category badge + space
 	}
+	if treeBadge != "" {
+		visibleLen += lipgloss.Width(treeBadge) + 1 // sub-agent badge + space
+	}
+	if starBadge != "" {
+		visibleLen += 2 // star + space
+	}
 	padding := maxWidth - visibleLen - rightColWidth - 1
 	if padding < 0 {
 		padding = 0
@@ -602,6 +658,17 @@ func (p *Plugin) renderCompactSessionRow(session adapter.Session, selected bool,
 		sb.WriteString(renderCategoryBadge(session))
 	}
 
+	// Sub-agent count after name (▸ collapsed, ▾ expanded)
+	if treeBadge != "" {
+		sb.WriteString(" ")
+		sb.WriteString(styles.Muted.Render(treeBadge))
+	}
+
+	if starBadge != "" {
+		sb.WriteString(" ")
+		sb.WriteString(styles.StatusModified.Render(starBadge))
+	}
+
 	// Padding and right-aligned stats (only if we have data)
 	if rightColWidth > 0 && padding > 0 {
 		sb.WriteString(strings.Repeat(" ", padding))
@@ -647,6 +714,14 @@ func (p *Plugin) renderCompactSessionRow(session adapter.Session, selected bool,
 			plain.WriteString(" ")
 			plain.WriteString(catBadge)
 		}
+		if treeBadge != "" {
+			plain.WriteString(" ")
+			plain.WriteString(treeBadge)
+		}
+		if starBadge != "" {
+			plain.WriteString(" ")
+			plain.WriteString(starBadge)
+		}
 		if rightColWidth > 0 && padding > 0 {
 			plain.WriteString(strings.Repeat(" ", padding))
 			plain.WriteString(" ")
@@ -662,8 +737,8 @@ func (p *Plugin) renderCompactSessionRow(session adapter.Session, selected bool,
 		}
 		plainRow := plain.String()
 		// Pad to full width for proper background highlight
-		if len(plainRow) < maxWidth {
-			plainRow += strings.Repeat(" ", maxWidth-len(plainRow))
+		if w := lipgloss.Width(plainRow); w < maxWidth {
+			plainRow += strings.Repeat(" ", maxWidth-w)
 		}
 		return styles.ListItemSelected.Render(plainRow)
 	}
@@ -683,6 +758,10 @@ func (p *Plugin) renderMainPane(paneWidth, height int) string {
 		return styles.Muted.Render("Select a session to view messages")
 	}
 
+	if p.changesMode {
+		return p.renderChangesPaneContent(contentWidth, height)
+	}
+
 	// If in detail mode, render the turn detail instead of turn list
 	if p.detailMode && p.detailTurn != nil {
 		return p.renderDetailPaneContent(contentWidth, height)
@@ -720,6 +799,10 @@ func (p *Plugin) renderMainPane(paneWidth, height int) string {
 		sb.WriteString(" ")
 	}
 	sb.WriteString(styles.Title.Render(sessionName))
+	if badge := renderAnnotationBadge(p.selectedAnnotations()); badge != "" && lipgloss.Width(sessionName)+lipgloss.Width(badge)+5 <= contentWidth {
+		sb.WriteString(" ")
+		sb.WriteString(styles.StatusModified.Render(badge))
+	}
 	sb.WriteString("\n")
 
 	// Header Line 2: Model badge │ msgs │ tokens │ cost │ date
@@ -754,6 +837,15 @@ func (p *Plugin) renderMainPane(paneWidth, height int) string {
 			statsParts = append(statsParts, formatCost(session.EstCost))
 		}
 
+		// Sub-agents with the cost including them, or the parent link
+		if session != nil {
+			if t, ok := p.subAgentTotals[session.ID]; ok {
+				statsParts = append(statsParts, fmt.Sprintf("%d sub-agents %s total", t.Count, formatCost(session.EstCost+t.EstCost)))
+			} else if p.hasVisibleParent(*session) {
+				statsParts = append(statsParts, "sub-agent of "+p.sessionDisplayName(session.ParentSessionID, 20)+" [a]")
+			}
+		}
+
 		// Last updated
 		if session != nil && !session.UpdatedAt.IsZero() {
 			statsParts = append(statsParts, session.UpdatedAt.Local().Format("Jan 02 15:04"))
@@ -1061,6 +1153,17 @@ func (p *Plugin) renderCompactTurn(turn Turn, turnIndex int, maxWidth int) []str
 		statsStr = " (" + strings.Join(stats, ", ") + ")"
 	}
 
+	// Bookmark marker when any message in the turn is bookmarked
+	bookmark := ""
+	if ann := p.selectedAnnotations(); ann != nil {
+		for _, m := range turn.Messages {
+			if ann.Bookmark(m.ID) != nil {
+				bookmark = " ◆"
+				break
+			}
+		}
+	}
+
 	// Get friendly role name
 	session := p.findSelectedSession()
 	var roleName string
@@ -1073,7 +1176,7 @@ func (p *Plugin) renderCompactTurn(turn Turn, turnIndex int, maxWidth int) []str
 	// Build header line
 	if selected {
 		// For selected: plain text with background highlight
-		headerContent := fmt.Sprintf("[%s] %s%s", ts, roleName, statsStr)
+		headerContent := fmt.Sprintf("[%s] %s%s%s", ts, roleName, statsStr, bookmark)
 		lines = append(lines, p.styleTurnLine(headerContent, true, maxWidth))
 	} else {
 		// For unselected: colored role badge with muted styling
@@ -1083,10 +1186,11 @@ func (p *Plugin) renderCompactTurn(turn Turn, turnIndex int, maxWidth int) []str
 		} else {
 			roleStyle = styles.StatusStaged
 		}
-		styledHeader := fmt.Sprintf("[%s] %s%s",
+		styledHeader := fmt.Sprintf("[%s] %s%s%s",
 			styles.Muted.Render(ts),
 			roleStyle.Render(roleName),
-			styles.Muted.Render(statsStr))
+			styles.Muted.Render(statsStr),
+			styles.StatusModified.Render(bookmark))
 		lines = append(lines, styledHeader)
 	}
 
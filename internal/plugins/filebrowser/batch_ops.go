@@ -0,0 +1,1044 @@
+package filebrowser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/trash"
+)
+
+const (
+	batchProgressEvery = 100 * time.Millisecond // Interval between progress updates
+	batchUndoLimit     = 20                     // Batches kept on the undo stack
+	globSelectMax      = 10000                  // Max paths a glob-select adds
+)
+
+// batchKind identifies a file operation applied to a multi-selection.
+type batchKind int
+
+const (
+	batchMove batchKind = iota
+	batchCopy
+	batchDelete
+	batchRename
+)
+
+// String returns the verb shown in progress and toast messages.
+func (k batchKind) String() string {
+	switch k {
+	case batchCopy:
+		return "copy"
+	case batchDelete:
+		return "trash"
+	case batchRename:
+		return "rename"
+	}
+	return "move"
+}
+
+// batchStep is one entry of a batch transaction: Src was moved, copied or
+// renamed to Dst. For deletes Dst is the item's place in the trash.
+type batchStep struct {
+	Src  string
+	Dst  string
+	Item *trash.Item // Trashed item (deletes only)
+}
+
+// batchTxn is the log of a completed batch, kept so it can be undone.
+type batchTxn struct {
+	Kind  batchKind
+	Steps []batchStep
+	Trash *trash.Trash // Trash holding deleted items (deletes only)
+}
+
+// batchRun streams the messages of a running batch operation.
+type batchRun struct {
+	ch chan tea.Msg
+}
+
+// next waits for the run's next message.
+func (r *batchRun) next() tea.Msg {
+	msg, ok := <-r.ch
+	if !ok {
+		return nil
+	}
+	return msg
+}
+
+// BatchProgressMsg reports how far a running batch operation has got.
+type BatchProgressMsg struct {
+	Epoch      uint64
+	Kind       batchKind
+	Undo       bool
+	Done       int
+	Total      int
+	Bytes      int64
+	TotalBytes int64
+	run        *batchRun
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m BatchProgressMsg) GetEpoch() uint64 { return m.Epoch }
+
+// BatchDoneMsg is sent when a batch operation or its undo finishes. Txn holds
+// the steps that completed; on error these are still undoable.
+type BatchDoneMsg struct {
+	Epoch uint64
+	Txn   batchTxn
+	Undo  bool
+	Err   error
+	run   *batchRun
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m BatchDoneMsg) GetEpoch() uint64 { return m.Epoch }
+
+// GlobSelectMsg delivers the project files matching a glob-select pattern.
+type GlobSelectMsg struct {
+	Epoch     uint64
+	Pattern   string
+	Paths     []string
+	Truncated bool
+	Err       error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m GlobSelectMsg) GetEpoch() uint64 { return m.Epoch }
+
+// pruneNested sorts paths and drops any that lie inside another selected
+// directory, since operating on the parent already covers them.
+func pruneNested(paths []string) []string {
+	sorted := append([]string(nil), paths...)
+	sort.Strings(sorted)
+	var out []string
+	for _, p := range sorted {
+		if n := len(out); n > 0 {
+			last := out[n-1]
+			if p == last || strings.HasPrefix(p, last+string(filepath.Separator)) {
+				continue
+			}
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// checkBatchSources resolves relative paths to absolute ones, rejecting
+// missing items and anything outside the project or inside .sidecar.
+func checkBatchSources(workDir string, paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return nil, errors.New("nothing selected")
+	}
+	var abs []string
+	for _, rel := range pruneNested(paths) {
+		rel = filepath.Clean(rel)
+		if rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.IsAbs(rel) {
+			return nil, fmt.Errorf("cannot operate on %s", rel)
+		}
+		if rel == ".sidecar" || strings.HasPrefix(rel, ".sidecar"+string(filepath.Separator)) {
+			return nil, fmt.Errorf("cannot operate on sidecar data: %s", rel)
+		}
+		full := filepath.Join(workDir, rel)
+		if _, err := os.Lstat(full); err != nil {
+			return nil, fmt.Errorf("not found: %s", rel)
+		}
+		abs = append(abs, full)
+	}
+	return abs, nil
+}
+
+// checkDestinations rejects destinations that already exist or collide with
+// each other. Names differing only in case collide only on filesystems that
+// ignore case.
+func checkDestinations(workDir string, steps []batchStep) error {
+	foldCase := caseInsensitiveFS(workDir)
+	seen := make(map[string]bool, len(steps))
+	for _, s := range steps {
+		key := s.Dst
+		if foldCase {
+			key = strings.ToLower(key)
+		}
+		if seen[key] {
+			return fmt.Errorf("more than one item would become %s", relTo(workDir, s.Dst))
+		}
+		seen[key] = true
+		if isCaseOnlyRename(s.Src, s.Dst) {
+			continue
+		}
+		if _, err := os.Lstat(s.Dst); err == nil {
+			return fmt.Errorf("destination already exists: %s", relTo(workDir, s.Dst))
+		}
+	}
+	return nil
+}
+
+// isCaseOnlyRename reports whether dst names the same file as src with
+// different letter case, as on case-insensitive filesystems. A dst that is
+// a different file is not a case-only rename, even if the names fold equal.
+func isCaseOnlyRename(src, dst string) bool {
+	if src == dst || !strings.EqualFold(src, dst) {
+		return false
+	}
+	srcInfo, err := os.Lstat(src)
+	if err != nil {
+		return false
+	}
+	dstInfo, err := os.Lstat(dst)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(srcInfo, dstInfo)
+}
+
+// caseInsensitiveFS reports whether dir is on a filesystem that ignores
+// letter case, by looking dir up again with its name's case flipped.
+func caseInsensitiveFS(dir string) bool {
+	base := filepath.Base(dir)
+	flipped := strings.Map(func(r rune) rune {
+		if unicode.IsUpper(r) {
+			return unicode.ToLower(r)
+		}
+		return unicode.ToUpper(r)
+	}, base)
+	if flipped == base {
+		return false // No letters to flip; assume case-sensitive
+	}
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return false
+	}
+	other, err := os.Lstat(filepath.Join(filepath.Dir(dir), flipped))
+	return err == nil && os.SameFile(info, other)
+}
+
+// relTo returns p relative to workDir for messages.
+func relTo(workDir, p string) string {
+	if rel, err := filepath.Rel(workDir, p); err == nil {
+		return rel
+	}
+	return p
+}
+
+// batchDestDir resolves a destination directory relative to the project root.
+func batchDestDir(workDir, destRel string) (string, error) {
+	destRel = strings.TrimSpace(destRel)
+	if destRel == "" {
+		destRel = "."
+	}
+	if filepath.IsAbs(destRel) {
+		return "", errors.New("absolute paths not allowed")
+	}
+	dest := filepath.Join(workDir, destRel)
+	rel, err := filepath.Rel(workDir, dest)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.New("cannot move files outside project directory")
+	}
+	if info, err := os.Stat(dest); err == nil && !info.IsDir() {
+		return "", fmt.Errorf("not a directory: %s", rel)
+	}
+	return dest, nil
+}
+
+// planBatchMove moves every selected item into destRel, keeping names.
+func planBatchMove(workDir string, paths []string, destRel string) ([]batchStep, error) {
+	srcs, err := checkBatchSources(workDir, paths)
+	if err != nil {
+		return nil, err
+	}
+	dest, err := batchDestDir(workDir, destRel)
+	if err != nil {
+		return nil, err
+	}
+	var steps []batchStep
+	for _, src := range srcs {
+		if dest == src || strings.HasPrefix(dest, src+string(filepath.Separator)) {
+			return nil, fmt.Errorf("cannot move %s into itself", relTo(workDir, src))
+		}
+		dst := filepath.Join(dest, filepath.Base(src))
+		if dst == src {
+			continue // Already there
+		}
+		steps = append(steps, batchStep{Src: src, Dst: dst})
+	}
+	if len(steps) == 0 {
+		return nil, errors.New("items are already in that directory")
+	}
+	return steps, checkDestinations(workDir, steps)
+}
+
+// planBatchCopy copies every selected item into destDir, appending _copy
+// suffixes on name conflicts like a single paste.
+func planBatchCopy(workDir string, paths []string, destDir string) ([]batchStep, error) {
+	srcs, err := checkBatchSources(workDir, paths)
+	if err != nil {
+		return nil, err
+	}
+	taken := make(map[string]bool)
+	var steps []batchStep
+	for _, src := range srcs {
+		if destDir == src || strings.HasPrefix(destDir, src+string(filepath.Separator)) {
+			return nil, fmt.Errorf("cannot copy %s into itself", relTo(workDir, src))
+		}
+		dst, err := copyDestPath(destDir, filepath.Base(src), taken)
+		if err != nil {
+			return nil, err
+		}
+		taken[dst] = true
+		steps = append(steps, batchStep{Src: src, Dst: dst})
+	}
+	return steps, nil
+}
+
+// copyDestPath picks name in dir, or name_copy, name_copy2, ... if taken on
+// disk or by an earlier item of the same batch.
+func copyDestPath(dir, name string, taken map[string]bool) (string, error) {
+	free := func(p string) bool {
+		if taken[p] {
+			return false
+		}
+		_, err := os.Lstat(p)
+		return os.IsNotExist(err)
+	}
+	dst := filepath.Join(dir, name)
+	if free(dst) {
+		return dst, nil
+	}
+	base, ext := splitExt(name)
+	for i := 1; i <= 100; i++ {
+		suffix := "_copy"
+		if i > 1 {
+			suffix = fmt.Sprintf("_copy%d", i)
+		}
+		dst = filepath.Join(dir, base+suffix+ext)
+		if free(dst) {
+			return dst, nil
+		}
+	}
+	return "", errors.New("too many copies")
+}
+
+// splitExt splits a file name into base and extension. Dotfiles without a
+// further extension have none.
+func splitExt(name string) (string, string) {
+	ext := filepath.Ext(name)
+	if ext == name {
+		return name, ""
+	}
+	return strings.TrimSuffix(name, ext), ext
+}
+
+// planBatchDelete moves every selected item to the trash. Destinations are
+// filled in as items are trashed.
+func planBatchDelete(workDir string, paths []string) ([]batchStep, error) {
+	srcs, err := checkBatchSources(workDir, paths)
+	if err != nil {
+		return nil, err
+	}
+	steps := make([]batchStep, 0, len(srcs))
+	for _, src := range srcs {
+		steps = append(steps, batchStep{Src: src})
+	}
+	return steps, nil
+}
+
+// renamePattern is a compiled rename pattern: either a sed-style
+// s/regexp/replacement/ applied to the name, or a template with {name},
+// {ext}, {n} and zero-padded {n:3} placeholders.
+type renamePattern struct {
+	re       *regexp.Regexp
+	repl     string
+	global   bool
+	template string
+}
+
+var renameCounter = regexp.MustCompile(`\{n(?::(\d+))?\}`)
+
+// parseRenamePattern compiles a rename pattern.
+func parseRenamePattern(pattern string) (*renamePattern, error) {
+	if pattern == "" {
+		return nil, errors.New("empty pattern")
+	}
+	if len(pattern) > 2 && pattern[0] == 's' && strings.IndexByte(sedDelimiters, pattern[1]) >= 0 {
+		parts := splitSedExpr(pattern[2:], pattern[1])
+		if len(parts) != 3 || (parts[2] != "" && parts[2] != "g") {
+			return nil, errors.New("use s/regexp/replacement/ or s/regexp/replacement/g")
+		}
+		re, err := regexp.Compile(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid regexp: %v", err)
+		}
+		return &renamePattern{re: re, repl: parts[1], global: parts[2] == "g"}, nil
+	}
+	if !strings.Contains(pattern, "{") {
+		return nil, errors.New("pattern needs {name}, {ext} or {n}; or use s/regexp/replacement/")
+	}
+	return &renamePattern{template: pattern}, nil
+}
+
+// sedDelimiters are the characters accepted after "s" in a sed-style
+// pattern; none of them can appear in a file name template by accident.
+const sedDelimiters = "/|#,@!%;~"
+
+// splitSedExpr splits "re/repl/flags" on unescaped delimiters. Escaped
+// delimiters become literal ones.
+func splitSedExpr(s string, delim byte) []string {
+	var parts []string
+	var cur strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && s[i+1] == delim {
+			cur.WriteByte(delim)
+			i++
+			continue
+		}
+		if s[i] == delim {
+			parts = append(parts, cur.String())
+			cur.Reset()
+			continue
+		}
+		cur.WriteByte(s[i])
+	}
+	return append(parts, cur.String())
+}
+
+// Apply returns the new name for the n-th (1-based) item called name.
+func (rp *renamePattern) Apply(name string, n int) string {
+	if rp.re != nil {
+		if rp.global {
+			return rp.re.ReplaceAllString(name, rp.repl)
+		}
+		done := false
+		return rp.re.ReplaceAllStringFunc(name, func(m string) string {
+			if done {
+				return m
+			}
+			done = true
+			return rp.re.ReplaceAllString(m, rp.repl)
+		})
+	}
+	base, ext := splitExt(name)
+	out := strings.NewReplacer("{name}", base, "{ext}", ext).Replace(rp.template)
+	return renameCounter.ReplaceAllStringFunc(out, func(m string) string {
+		width := 0
+		if sub := renameCounter.FindStringSubmatch(m); sub[1] != "" {
+			width, _ = strconv.Atoi(sub[1])
+		}
+		return fmt.Sprintf("%0*d", width, n)
+	})
+}
+
+// planBatchRename renames every selected item in place using pattern. Items
+// are numbered in path order.
+func planBatchRename(workDir string, paths []string, pattern string) ([]batchStep, error) {
+	rp, err := parseRenamePattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	srcs, err := checkBatchSources(workDir, paths)
+	if err != nil {
+		return nil, err
+	}
+	var steps []batchStep
+	for i, src := range srcs {
+		name := rp.Apply(filepath.Base(src), i+1)
+		if strings.ContainsRune(name, filepath.Separator) || strings.Contains(name, "/") {
+			return nil, fmt.Errorf("use 'm' to move to a different directory: %s", name)
+		}
+		if err := validateFilename(name); err != nil {
+			return nil, fmt.Errorf("%s: %v", filepath.Base(src), err)
+		}
+		dst := filepath.Join(filepath.Dir(src), name)
+		if dst == src {
+			continue
+		}
+		steps = append(steps, batchStep{Src: src, Dst: dst})
+	}
+	if len(steps) == 0 {
+		return nil, errors.New("pattern leaves every name unchanged")
+	}
+	return steps, checkDestinations(workDir, steps)
+}
+
+// batchProgress accumulates progress and sends throttled updates.
+type batchProgress struct {
+	msg  BatchProgressMsg
+	ch   chan tea.Msg
+	last time.Time
+}
+
+func (bp *batchProgress) report(force bool) {
+	if !force && time.Since(bp.last) < batchProgressEvery {
+		return
+	}
+	bp.last = time.Now()
+	select {
+	case bp.ch <- bp.msg:
+	default: // UI hasn't taken the previous update yet
+	}
+}
+
+// startBatch runs txn in the background, streaming progress and a final
+// BatchDoneMsg.
+func startBatch(txn batchTxn, undo bool, epoch uint64) tea.Cmd {
+	run := &batchRun{ch: make(chan tea.Msg, 1)}
+	go func() {
+		defer close(run.ch)
+		bp := &batchProgress{
+			msg: BatchProgressMsg{Epoch: epoch, Kind: txn.Kind, Undo: undo, Total: len(txn.Steps), run: run},
+			ch:  run.ch,
+		}
+		var done batchTxn
+		var err error
+		if undo {
+			done, err = undoBatch(txn, bp)
+		} else {
+			done, err = runBatch(txn, bp)
+		}
+		run.ch <- BatchDoneMsg{Epoch: epoch, Txn: done, Undo: undo, Err: err, run: run}
+	}()
+	return run.next
+}
+
+// runBatch performs the steps of txn in order, stopping at the first error.
+// The returned transaction holds the steps that completed.
+func runBatch(txn batchTxn, bp *batchProgress) (batchTxn, error) {
+	done := batchTxn{Kind: txn.Kind, Trash: txn.Trash}
+	if txn.Kind == batchCopy {
+		for _, s := range txn.Steps {
+			bp.msg.TotalBytes += trash.PathSize(s.Src)
+		}
+	}
+	bp.report(true)
+	for _, s := range txn.Steps {
+		var err error
+		switch txn.Kind {
+		case batchCopy:
+			err = copyPathProgress(s.Src, s.Dst, func(n int64) {
+				bp.msg.Bytes += n
+				bp.report(false)
+			})
+			if err != nil {
+				_ = os.RemoveAll(s.Dst) // Don't leave a partial copy behind
+			}
+		case batchDelete:
+			var item trash.Item
+			if item, err = txn.Trash.Put(s.Src); err == nil {
+				s.Dst, s.Item = item.Path(), &item
+			}
+		default:
+			err = renamePath(s.Src, s.Dst)
+		}
+		if err != nil {
+			return done, fmt.Errorf("%s %s: %w", txn.Kind, filepath.Base(s.Src), err)
+		}
+		done.Steps = append(done.Steps, s)
+		bp.msg.Done++
+		bp.report(false)
+	}
+	return done, nil
+}
+
+// undoBatch reverts txn's steps in reverse order. The returned transaction
+// holds the steps that could not be reverted, if any.
+func undoBatch(txn batchTxn, bp *batchProgress) (batchTxn, error) {
+	bp.report(true)
+	for i := len(txn.Steps) - 1; i >= 0; i-- {
+		s := txn.Steps[i]
+		var err error
+		switch {
+		case txn.Kind == batchCopy:
+			err = os.RemoveAll(s.Dst)
+		case s.Item != nil:
+			err = txn.Trash.Restore(*s.Item)
+		default:
+			if _, statErr := os.Lstat(s.Dst); statErr != nil {
+				err = fmt.Errorf("%s no longer exists", filepath.Base(s.Dst))
+			} else {
+				err = renamePath(s.Dst, s.Src)
+			}
+		}
+		if err != nil {
+			rest := txn
+			rest.Steps = txn.Steps[:i+1]
+			return rest, fmt.Errorf("undo %s: %w", txn.Kind, err)
+		}
+		bp.msg.Done++
+		bp.report(false)
+	}
+	return batchTxn{Kind: txn.Kind}, nil
+}
+
+// renamePath moves src to dst, creating parent directories. Case-only
+// renames go through a temporary name for case-insensitive filesystems.
+func renamePath(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if isCaseOnlyRename(src, dst) {
+		tmp := src + ".sidecar-rename-tmp"
+		if err := os.Rename(src, tmp); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, dst); err != nil {
+			_ = os.Rename(tmp, src)
+			return err
+		}
+		return nil
+	}
+	if _, err := os.Lstat(dst); err == nil {
+		return fmt.Errorf("destination already exists: %s", filepath.Base(dst))
+	}
+	return os.Rename(src, dst)
+}
+
+// progressWriter reports every write to onWrite.
+type progressWriter struct {
+	w       io.Writer
+	onWrite func(int64)
+}
+
+func (pw progressWriter) Write(b []byte) (int, error) {
+	n, err := pw.w.Write(b)
+	pw.onWrite(int64(n))
+	return n, err
+}
+
+// copyPathProgress copies a file or directory tree, reporting bytes written.
+func copyPathProgress(src, dst string, onWrite func(int64)) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case !d.Type().IsRegular():
+			return nil // Skip sockets, devices and pipes
+		}
+		in, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = in.Close() }()
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_EXCL, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(progressWriter{w: out, onWrite: onWrite}, in); err != nil {
+			_ = out.Close()
+			return err
+		}
+		return out.Close()
+	})
+}
+
+// globSelect lists the project files matching pattern, respecting
+// .gitignore like project search.
+func globSelect(workDir, pattern string, epoch uint64) tea.Cmd {
+	return func() tea.Msg {
+		msg := GlobSelectMsg{Epoch: epoch, Pattern: pattern}
+		if _, err := path.Match(strings.ReplaceAll(pattern, "**", "*"), ""); err != nil {
+			msg.Err = fmt.Errorf("invalid pattern: %v", err)
+			return msg
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), projectSearchTimeout)
+		defer cancel()
+		msg.Err = walkSearchFiles(ctx, workDir, "", nil, func(rel string) bool {
+			if !globMatch(pattern, rel) {
+				return true
+			}
+			if len(msg.Paths) >= globSelectMax {
+				msg.Truncated = true
+				return false
+			}
+			msg.Paths = append(msg.Paths, filepath.FromSlash(rel))
+			return true
+		})
+		return msg
+	}
+}
+
+// globMatch matches a slash-separated project path against a glob. Patterns
+// without a slash match the file name at any depth; "**" matches any
+// number of directories.
+func globMatch(pattern, rel string) bool {
+	pattern = strings.TrimPrefix(pattern, "./")
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(rel))
+		return ok
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(rel, "/"))
+}
+
+func matchSegments(pat, parts []string) bool {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(pat[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pat[0], parts[0]); !ok {
+			return false
+		}
+		pat, parts = pat[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
+
+// hasSelection reports whether any tree items are selected.
+func (p *Plugin) hasSelection() bool {
+	return len(p.selected) > 0
+}
+
+// selectedPaths returns the selected paths in sorted order, dropping any
+// that no longer exist.
+func (p *Plugin) selectedPaths() []string {
+	paths := make([]string, 0, len(p.selected))
+	for path := range p.selected {
+		if _, err := os.Lstat(filepath.Join(p.ctx.WorkDir, path)); err != nil {
+			delete(p.selected, path)
+			continue
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// toggleSelected adds or removes node from the selection and makes it the
+// anchor for range selection.
+func (p *Plugin) toggleSelected(node *FileNode) {
+	if node == nil || node == p.tree.Root {
+		return
+	}
+	if p.selected == nil {
+		p.selected = make(map[string]bool)
+	}
+	if p.selected[node.Path] {
+		delete(p.selected, node.Path)
+	} else {
+		p.selected[node.Path] = true
+	}
+	p.selectAnchor = node.Path
+}
+
+// selectRange selects every visible node between the anchor and the cursor.
+func (p *Plugin) selectRange() {
+	anchor := -1
+	if p.selectAnchor != "" {
+		if node := p.tree.FindByPath(p.selectAnchor); node != nil {
+			anchor = p.tree.IndexOf(node)
+		}
+	}
+	if anchor < 0 {
+		anchor = p.treeCursor
+	}
+	if p.selected == nil {
+		p.selected = make(map[string]bool)
+	}
+	lo, hi := min(anchor, p.treeCursor), max(anchor, p.treeCursor)
+	for i := lo; i <= hi; i++ {
+		if node := p.tree.GetNode(i); node != nil && node != p.tree.Root {
+			p.selected[node.Path] = true
+		}
+	}
+	if node := p.tree.GetNode(p.treeCursor); node != nil {
+		p.selectAnchor = node.Path
+	}
+}
+
+// clearSelection empties the selection.
+func (p *Plugin) clearSelection() {
+	p.selected = nil
+	p.selectAnchor = ""
+}
+
+// openBatchOp opens the file op bar for a move, rename or delete of the
+// current selection.
+func (p *Plugin) openBatchOp(mode FileOpMode) {
+	p.fileOpMode = mode
+	p.fileOpTarget = nil
+	p.fileOpBatch = p.selectedPaths()
+	p.fileOpError = ""
+	p.fileOpButtonFocus = 0
+	p.fileOpShowSuggestions = false
+	p.fileOpTextInput = textinput.New()
+
+	switch mode {
+	case FileOpDelete:
+		p.fileOpConfirmDelete = true
+		p.fileOpButtonFocus = 1 // Start with confirm button focused
+		return
+	case FileOpMove:
+		// Default to the directory under the cursor
+		if node := p.tree.GetNode(p.treeCursor); node != nil && node != p.tree.Root {
+			dir := node.Path
+			if !node.IsDir {
+				dir = filepath.Dir(node.Path)
+			}
+			if dir != "." {
+				p.fileOpTextInput.SetValue(dir + string(filepath.Separator))
+			}
+		}
+	case FileOpRename:
+		p.fileOpTextInput.SetValue("{name}{ext}")
+	case FileOpGlobSelect:
+		p.fileOpBatch = nil
+		p.fileOpTextInput.Placeholder = "*.go, src/**/*_test.go"
+	}
+	p.fileOpTextInput.Focus()
+	p.fileOpTextInput.CursorEnd()
+}
+
+// closeFileOp leaves file operation mode.
+func (p *Plugin) closeFileOp() {
+	p.fileOpMode = FileOpNone
+	p.fileOpTarget = nil
+	p.fileOpBatch = nil
+	p.fileOpError = ""
+	p.fileOpShowSuggestions = false
+	p.fileOpConfirmDelete = false
+	p.fileOpConfirmCreate = false
+}
+
+// executeBatchOp plans the pending batch operation from the file op bar and
+// starts it, or shows why it can't run.
+func (p *Plugin) executeBatchOp() (plugin.Plugin, tea.Cmd) {
+	input := strings.TrimSpace(p.fileOpTextInput.Value())
+	workDir := p.ctx.WorkDir
+
+	if p.fileOpMode == FileOpGlobSelect {
+		p.closeFileOp()
+		if input == "" {
+			return p, nil
+		}
+		return p, globSelect(workDir, input, p.ctx.Epoch)
+	}
+
+	txn := batchTxn{}
+	var err error
+	switch p.fileOpMode {
+	case FileOpMove:
+		dest, destErr := batchDestDir(workDir, input)
+		if destErr == nil {
+			if _, statErr := os.Stat(dest); os.IsNotExist(statErr) {
+				// Ask before creating the destination directory
+				p.fileOpConfirmCreate = true
+				p.fileOpConfirmPath = dest
+				return p, nil
+			}
+		}
+		txn.Kind = batchMove
+		txn.Steps, err = planBatchMove(workDir, p.fileOpBatch, input)
+	case FileOpRename:
+		txn.Kind = batchRename
+		txn.Steps, err = planBatchRename(workDir, p.fileOpBatch, input)
+	case FileOpDelete:
+		txn.Kind = batchDelete
+		txn.Trash = p.projectTrash()
+		txn.Steps, err = planBatchDelete(workDir, p.fileOpBatch)
+	default:
+		p.closeFileOp()
+		return p, nil
+	}
+	if err != nil {
+		if p.fileOpMode == FileOpDelete {
+			// No input to correct; report and close
+			p.closeFileOp()
+			return p, appmsg.ShowToast("Delete failed: "+err.Error(), 3*time.Second)
+		}
+		p.fileOpError = err.Error()
+		return p, nil
+	}
+	p.closeFileOp()
+	return p, p.startBatchOp(txn)
+}
+
+// pasteBatch copies the yanked multi-selection next to the cursor node.
+func (p *Plugin) pasteBatch(target *FileNode) tea.Cmd {
+	destDir := filepath.Join(p.ctx.WorkDir, target.Path)
+	if !target.IsDir {
+		destDir = filepath.Dir(destDir)
+	}
+	steps, err := planBatchCopy(p.ctx.WorkDir, p.clipboardPaths, destDir)
+	if err != nil {
+		return appmsg.ShowToast("Paste failed: "+err.Error(), 3*time.Second)
+	}
+	return p.startBatchOp(batchTxn{Kind: batchCopy, Steps: steps})
+}
+
+// startBatchOp runs txn unless another batch is still in progress.
+func (p *Plugin) startBatchOp(txn batchTxn) tea.Cmd {
+	if p.batchProgress != nil {
+		return appmsg.ShowToast("Another file operation is still running", 2*time.Second)
+	}
+	p.batchProgress = &BatchProgressMsg{Kind: txn.Kind, Total: len(txn.Steps)}
+	return startBatch(txn, false, p.ctx.Epoch)
+}
+
+// undoLastBatch reverts the most recent batch operation.
+func (p *Plugin) undoLastBatch() tea.Cmd {
+	if len(p.batchUndo) == 0 {
+		return appmsg.ShowToast("Nothing to undo", 2*time.Second)
+	}
+	if p.batchProgress != nil {
+		return appmsg.ShowToast("Another file operation is still running", 2*time.Second)
+	}
+	txn := p.batchUndo[len(p.batchUndo)-1]
+	p.batchUndo = p.batchUndo[:len(p.batchUndo)-1]
+	p.batchProgress = &BatchProgressMsg{Kind: txn.Kind, Undo: true, Total: len(txn.Steps)}
+	return startBatch(txn, true, p.ctx.Epoch)
+}
+
+// applyBatchDone records a finished batch on the undo stack and refreshes
+// the tree.
+func (p *Plugin) applyBatchDone(m BatchDoneMsg) tea.Cmd {
+	p.batchProgress = nil
+	if len(m.Txn.Steps) > 0 {
+		// A finished batch, or the part of an undo that failed, can be undone
+		p.batchUndo = append(p.batchUndo, m.Txn)
+		if len(p.batchUndo) > batchUndoLimit {
+			p.batchUndo = p.batchUndo[len(p.batchUndo)-batchUndoLimit:]
+		}
+	}
+	if !m.Undo && m.Txn.Kind != batchCopy {
+		for _, s := range m.Txn.Steps {
+			p.closeTabsForPath(relTo(p.ctx.WorkDir, s.Src))
+		}
+	}
+	if !m.Undo && m.Err == nil {
+		p.clearSelection()
+	}
+
+	var toast tea.Cmd
+	switch {
+	case m.Err != nil:
+		toast = appmsg.ShowToast(capitalize(m.Err.Error()), 4*time.Second)
+	case m.Undo:
+		toast = appmsg.ShowToast("Undid "+m.Txn.Kind.String(), 2*time.Second)
+	default:
+		toast = appmsg.ShowToast(fmt.Sprintf("%s %s (u to undo)", batchDoneVerb(m.Txn.Kind), pluralItems(len(m.Txn.Steps))), 3*time.Second)
+	}
+	return tea.Batch(toast, p.refresh())
+}
+
+// applyGlobSelect adds the paths matched by a glob-select to the selection.
+func (p *Plugin) applyGlobSelect(m GlobSelectMsg) tea.Cmd {
+	if m.Err != nil {
+		return appmsg.ShowToast(capitalize(m.Err.Error()), 3*time.Second)
+	}
+	if len(m.Paths) == 0 {
+		return appmsg.ShowToast("No files match "+m.Pattern, 2*time.Second)
+	}
+	if p.selected == nil {
+		p.selected = make(map[string]bool)
+	}
+	for _, path := range m.Paths {
+		p.selected[path] = true
+	}
+	text := fmt.Sprintf("Selected %s matching %s", pluralItems(len(m.Paths)), m.Pattern)
+	if m.Truncated {
+		text += fmt.Sprintf(" (first %d)", globSelectMax)
+	}
+	return appmsg.ShowToast(text, 2*time.Second)
+}
+
+// batchDoneVerb is the past tense used in completion toasts.
+func batchDoneVerb(k batchKind) string {
+	switch k {
+	case batchCopy:
+		return "Copied"
+	case batchDelete:
+		return "Trashed"
+	case batchRename:
+		return "Renamed"
+	}
+	return "Moved"
+}
+
+// pluralItems formats an item count.
+func pluralItems(n int) string {
+	if n == 1 {
+		return "1 item"
+	}
+	return strconv.Itoa(n) + " items"
+}
+
+// capitalize upper-cases the first letter of an error for display.
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// batchStatus describes the running batch for the tree header.
+func (p *Plugin) batchStatus() string {
+	bp := p.batchProgress
+	if bp == nil {
+		return ""
+	}
+	verb := bp.Kind.String()
+	if bp.Undo {
+		verb = "undo " + verb
+	}
+	s := fmt.Sprintf("%s %d/%d", verb, bp.Done, bp.Total)
+	if bp.Kind == batchCopy && bp.TotalBytes > 0 && !bp.Undo {
+		s += fmt.Sprintf(" · %s/%s", formatSize(bp.Bytes), formatSize(bp.TotalBytes))
+	}
+	return s
+}
+
+// batchRenamePreview shows what the first selected item would be renamed to.
+func (p *Plugin) batchRenamePreview() string {
+	if len(p.fileOpBatch) == 0 {
+		return ""
+	}
+	rp, err := parseRenamePattern(p.fileOpTextInput.Value())
+	if err != nil {
+		return ""
+	}
+	first := pruneNested(p.fileOpBatch)[0]
+	name := filepath.Base(first)
+	s := name + " → " + rp.Apply(name, 1)
+	if n := len(p.fileOpBatch); n > 1 {
+		s += fmt.Sprintf(" (+%d more)", n-1)
+	}
+	return s
+}
@@ -0,0 +1,318 @@
+package filebrowser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/marcus/sidecar/internal/mouse"
+)
+
+// runBatchCmd feeds a batch command's messages to the plugin until the
+// batch finishes.
+func runBatchCmd(t *testing.T, p *Plugin, cmd tea.Cmd) BatchDoneMsg {
+	t.Helper()
+	for i := 0; cmd != nil && i < 1000; i++ {
+		m := cmd()
+		_, next := p.Update(m)
+		if done, ok := m.(BatchDoneMsg); ok {
+			return done
+		}
+		if _, ok := m.(BatchProgressMsg); !ok {
+			t.Fatalf("unexpected message %T", m)
+		}
+		cmd = next
+	}
+	t.Fatal("batch did not finish")
+	return BatchDoneMsg{}
+}
+
+func selectPaths(t *testing.T, p *Plugin, paths ...string) {
+	t.Helper()
+	for _, path := range paths {
+		node := p.tree.FindByPath(path)
+		if node == nil {
+			t.Fatalf("node %s not in tree", path)
+		}
+		p.toggleSelected(node)
+	}
+}
+
+func TestPruneNested(t *testing.T) {
+	sep := string(filepath.Separator)
+	got := pruneNested([]string{"src" + sep + "app.go", "main.go", "src", "srcs", "main.go"})
+	want := []string{"main.go", "src", "srcs"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("pruneNested = %v, want %v", got, want)
+	}
+}
+
+func TestRenamePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		n       int
+		want    string
+	}{
+		{"{name}-v2{ext}", "photo.jpg", 1, "photo-v2.jpg"},
+		{"img_{n:3}{ext}", "photo.jpg", 7, "img_007.jpg"},
+		{"{n}_{name}", ".env", 2, "2_.env"},
+		{"s/foo/bar/", "foo_foo.go", 1, "bar_foo.go"},
+		{"s/foo/bar/g", "foo_foo.go", 1, "bar_bar.go"},
+		{`s|(\w+)\.txt|$1.md|`, "notes.txt", 1, "notes.md"},
+	}
+	for _, tt := range tests {
+		rp, err := parseRenamePattern(tt.pattern)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.pattern, err)
+		}
+		if got := rp.Apply(tt.name, tt.n); got != tt.want {
+			t.Errorf("%q on %q = %q, want %q", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "plain", "s/(/x/", "s/a/b/x"} {
+		if _, err := parseRenamePattern(bad); err == nil {
+			t.Errorf("parseRenamePattern(%q) should fail", bad)
+		}
+	}
+}
+
+func TestPlanBatchRename_Conflicts(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt", "taken.txt"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := planBatchRename(tmpDir, []string{"a.txt", "b.txt"}, "same{ext}"); err == nil ||
+		!strings.Contains(err.Error(), "more than one") {
+		t.Errorf("colliding names: err = %v", err)
+	}
+	if _, err := planBatchRename(tmpDir, []string{"a.txt"}, "taken{ext}"); err == nil ||
+		!strings.Contains(err.Error(), "already exists") {
+		t.Errorf("existing destination: err = %v", err)
+	}
+	if _, err := planBatchRename(tmpDir, []string{"a.txt"}, "sub/{name}"); err == nil {
+		t.Error("rename into a directory should fail")
+	}
+	steps, err := planBatchRename(tmpDir, []string{"b.txt", "a.txt"}, "{n}{ext}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 2 || filepath.Base(steps[0].Dst) != "1.txt" || filepath.Base(steps[0].Src) != "a.txt" {
+		t.Errorf("steps = %+v", steps)
+	}
+}
+
+func TestPlanBatchRename_CaseOnly(t *testing.T) {
+	tmpDir := t.TempDir()
+	if caseInsensitiveFS(tmpDir) {
+		t.Skip("needs a case-sensitive filesystem")
+	}
+	for _, name := range []string{"a.txt", "A.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A.txt is another file, not a.txt under a different case
+	if _, err := planBatchRename(tmpDir, []string{"a.txt"}, "s/a/A/"); err == nil ||
+		!strings.Contains(err.Error(), "already exists") {
+		t.Errorf("rename onto a file differing only in case: err = %v", err)
+	}
+	if isCaseOnlyRename(filepath.Join(tmpDir, "a.txt"), filepath.Join(tmpDir, "A.txt")) {
+		t.Error("distinct files reported as a case-only rename")
+	}
+
+	// Names differing only in case don't collide here
+	if err := checkDestinations(tmpDir, []batchStep{
+		{Src: filepath.Join(tmpDir, "a.txt"), Dst: filepath.Join(tmpDir, "c.txt")},
+		{Src: filepath.Join(tmpDir, "b.txt"), Dst: filepath.Join(tmpDir, "C.txt")},
+	}); err != nil {
+		t.Errorf("case-distinct destinations: err = %v", err)
+	}
+}
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern, rel string
+		want         bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "src/deep/app.go", true},
+		{"*.go", "main.go.bak", false},
+		{"src/*.go", "src/app.go", true},
+		{"src/*.go", "src/deep/app.go", false},
+		{"src/**/*.go", "src/app.go", true},
+		{"src/**/*.go", "src/a/b/app.go", true},
+		{"**/test/*", "x/test/y", true},
+		{"./src/*", "src/app.go", true},
+	}
+	for _, tt := range tests {
+		if got := globMatch(tt.pattern, tt.rel); got != tt.want {
+			t.Errorf("globMatch(%q, %q) = %v, want %v", tt.pattern, tt.rel, got, tt.want)
+		}
+	}
+}
+
+func TestBatchMoveAndUndo(t *testing.T) {
+	tmpDir := t.TempDir()
+	p := createTestPlugin(t, tmpDir)
+	selectPaths(t, p, "main.go", "README.md")
+	p.treeCursor = p.tree.IndexOf(p.tree.FindByPath("src"))
+
+	_, _ = p.handleTreeKey("m")
+	if p.fileOpMode != FileOpMove || len(p.fileOpBatch) != 2 {
+		t.Fatalf("m with a selection should open a batch move, mode=%v batch=%v", p.fileOpMode, p.fileOpBatch)
+	}
+	if got := p.fileOpTextInput.Value(); got != "src"+string(filepath.Separator) {
+		t.Errorf("move destination defaults to %q", got)
+	}
+	if bar := ansi.Strip(p.renderFileOpBar()); !strings.Contains(bar, "Move 2 items to:") {
+		t.Errorf("bar = %q", bar)
+	}
+
+	_, cmd := p.executeFileOp()
+	done := runBatchCmd(t, p, cmd)
+	if done.Err != nil {
+		t.Fatal(done.Err)
+	}
+	for _, name := range []string{"main.go", "README.md"} {
+		if _, err := os.Stat(filepath.Join(tmpDir, "src", name)); err != nil {
+			t.Errorf("%s not moved: %v", name, err)
+		}
+	}
+	if p.hasSelection() || len(p.batchUndo) != 1 || p.batchProgress != nil {
+		t.Fatalf("after batch: selected=%v undo=%d progress=%v", p.selected, len(p.batchUndo), p.batchProgress)
+	}
+
+	done = runBatchCmd(t, p, p.undoLastBatch())
+	if done.Err != nil || !done.Undo {
+		t.Fatalf("undo: %+v", done)
+	}
+	for _, name := range []string{"main.go", "README.md"} {
+		if _, err := os.Stat(filepath.Join(tmpDir, name)); err != nil {
+			t.Errorf("%s not restored: %v", name, err)
+		}
+	}
+	if len(p.batchUndo) != 0 {
+		t.Errorf("undo stack = %d", len(p.batchUndo))
+	}
+}
+
+func TestBatchDeleteToTrashAndUndo(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	tmpDir := t.TempDir()
+	p := createTestPlugin(t, tmpDir)
+	_ = p.tree.Expand(p.tree.FindByPath("src"))
+	p.treeCursor = p.tree.IndexOf(p.tree.FindByPath("main.go"))
+	_, _ = p.handleTreeKey(" ")
+	selectPaths(t, p, "src")
+	if len(p.selected) != 2 {
+		t.Fatalf("selected = %v", p.selected)
+	}
+	if line := ansi.Strip(p.renderTreeNode(p.tree.FindByPath("main.go"), false, 40)); !strings.HasPrefix(line, "✓ ") {
+		t.Errorf("selected node renders %q", line)
+	}
+
+	p.mouseHandler = mouse.NewHandler()
+	_, _ = p.handleTreeKey("D")
+	if !p.fileOpConfirmDelete || !strings.Contains(ansi.Strip(p.renderFileOpBar()), "Move 2 items to trash?") {
+		t.Fatal("D with a selection should ask to trash the items")
+	}
+	_, cmd := p.handleFileOpKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	done := runBatchCmd(t, p, cmd)
+	if done.Err != nil {
+		t.Fatal(done.Err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "src")); !os.IsNotExist(err) {
+		t.Error("src should be gone")
+	}
+	items, err := p.projectTrash().List()
+	if err != nil || len(items) != 2 {
+		t.Fatalf("trash items = %v, err = %v", items, err)
+	}
+	for _, it := range items {
+		if it.IsDir {
+			if _, err := os.Stat(filepath.Join(it.Path(), "app.go")); err != nil {
+				t.Errorf("trash should keep the tree: %v", err)
+			}
+		}
+	}
+
+	_, cmd = p.handleTreeKey("u")
+	if done = runBatchCmd(t, p, cmd); done.Err != nil {
+		t.Fatal(done.Err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "src", "app.go")); err != nil {
+		t.Errorf("src not restored: %v", err)
+	}
+	if items, _ := p.projectTrash().List(); len(items) != 0 {
+		t.Errorf("trash should be empty after undo, got %v", items)
+	}
+}
+
+func TestBatchCopyPaste(t *testing.T) {
+	tmpDir := t.TempDir()
+	p := createTestPlugin(t, tmpDir)
+	selectPaths(t, p, "main.go", "src")
+	_, _ = p.handleTreeKey("y")
+	if len(p.clipboardPaths) != 2 {
+		t.Fatalf("clipboard = %v", p.clipboardPaths)
+	}
+	p.treeCursor = p.tree.IndexOf(p.tree.FindByPath("README.md"))
+	_, cmd := p.handleTreeKey("p")
+	done := runBatchCmd(t, p, cmd)
+	if done.Err != nil {
+		t.Fatal(done.Err)
+	}
+	for _, name := range []string{"main_copy.go", filepath.Join("src_copy", "app.go")} {
+		if _, err := os.Stat(filepath.Join(tmpDir, name)); err != nil {
+			t.Errorf("%s not copied: %v", name, err)
+		}
+	}
+
+	if done = runBatchCmd(t, p, p.undoLastBatch()); done.Err != nil {
+		t.Fatal(done.Err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "src_copy")); !os.IsNotExist(err) {
+		t.Error("undo should remove copies")
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "src", "app.go")); err != nil {
+		t.Errorf("undo must keep originals: %v", err)
+	}
+}
+
+func TestSelectRangeAndGlob(t *testing.T) {
+	tmpDir := t.TempDir()
+	p := createTestPlugin(t, tmpDir)
+	_, _ = p.handleTreeKey(" ") // Toggle the first node
+	last := p.tree.Len() - 1
+	p.treeCursor = last
+	_, _ = p.handleTreeKey("V")
+	if len(p.selected) != p.tree.Len() {
+		t.Errorf("range selected %d of %d", len(p.selected), p.tree.Len())
+	}
+	_, _ = p.handleTreeKey("esc")
+	if p.hasSelection() {
+		t.Error("esc should clear the selection")
+	}
+
+	_, _ = p.handleTreeKey("*")
+	if p.fileOpMode != FileOpGlobSelect {
+		t.Fatal("* should open glob select")
+	}
+	p.fileOpTextInput.SetValue("*.go")
+	_, cmd := p.executeFileOp()
+	_, _ = p.Update(cmd())
+	want := map[string]bool{"main.go": true, filepath.Join("src", "app.go"): true}
+	if !reflect.DeepEqual(p.selected, want) {
+		t.Errorf("glob selected %v", p.selected)
+	}
+}
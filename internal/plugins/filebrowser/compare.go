@@ -0,0 +1,451 @@
+package filebrowser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/config"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/plugins/gitstatus"
+)
+
+const (
+	// compareMaxFiles caps how many files a directory comparison walks.
+	compareMaxFiles = 20000
+	// compareMaxFileSize is the largest file diffed in the compare view.
+	compareMaxFileSize = 2 << 20
+	// compareContext is the number of unchanged lines around each change.
+	compareContext = 3
+)
+
+// compareStatus is how a file differs between two compared directories.
+type compareStatus int
+
+const (
+	compareChanged compareStatus = iota
+	compareAdded                 // Only in the right-hand directory
+	compareRemoved               // Only in the left-hand directory
+)
+
+// compareEntry is one differing file in a directory comparison.
+type compareEntry struct {
+	Path   string // Relative to both compared directories
+	Status compareStatus
+}
+
+// compareView is the state of an open comparison.
+type compareView struct {
+	Left, Right string // Absolute paths; left is the marked ("old") side
+	IsDir       bool
+	Loading     bool
+	Err         error
+
+	// Directory comparisons
+	Entries   []compareEntry
+	Identical int
+	Truncated bool
+	Cursor    int
+
+	// File diff, for file comparisons or an entry opened from the list
+	FilePath string // Entry path when drilled into a directory comparison
+	Diff     *gitstatus.ParsedDiff
+	DiffErr  error
+	Scroll   int
+	Unified  bool // Show the unified renderer instead of side-by-side
+}
+
+// inDiff reports whether the view shows a file diff rather than a list.
+func (v *compareView) inDiff() bool {
+	return !v.IsDir || v.FilePath != ""
+}
+
+// CompareLoadedMsg delivers a directory comparison or file diff.
+type CompareLoadedMsg struct {
+	Epoch       uint64
+	Left, Right string
+	IsDir       bool
+	Entries     []compareEntry
+	Identical   int
+	Truncated   bool
+	Diff        *gitstatus.ParsedDiff
+	Err         error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m CompareLoadedMsg) GetEpoch() uint64 { return m.Epoch }
+
+// CompareDiffMsg delivers the diff of one entry in a directory comparison.
+type CompareDiffMsg struct {
+	Epoch uint64
+	Path  string
+	Diff  *gitstatus.ParsedDiff
+	Err   error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m CompareDiffMsg) GetEpoch() uint64 { return m.Epoch }
+
+// markOrCompare marks a node for comparison, or compares it with the node
+// marked earlier. Pressing it again on the marked node clears the mark.
+func (p *Plugin) markOrCompare(node *FileNode) tea.Cmd {
+	if node == nil || node == p.tree.Root {
+		return nil
+	}
+	path := filepath.Join(p.ctx.WorkDir, node.Path)
+	switch p.compareMark {
+	case "":
+		p.compareMark = path
+		return appmsg.ShowToast("Marked "+node.Path+" for compare (= on another item to compare)", 2*time.Second)
+	case path:
+		p.compareMark = ""
+		return appmsg.ShowToast("Compare mark cleared", 2*time.Second)
+	}
+	left := p.compareMark
+	p.compareMark = ""
+	return p.startCompare(left, path)
+}
+
+// openCompareInput prompts for any path, inside or outside the project, to
+// compare the node with.
+func (p *Plugin) openCompareInput(node *FileNode) {
+	if node == nil || node == p.tree.Root {
+		return
+	}
+	p.fileOpMode = FileOpCompare
+	p.fileOpTarget = node
+	p.fileOpError = ""
+	p.fileOpTextInput = textinput.New()
+	p.fileOpTextInput.Placeholder = "path, ../other-worktree/" + node.Path + " or ~/backup"
+	if p.compareMark != "" {
+		p.fileOpTextInput.SetValue(p.compareDisplayPath(p.compareMark))
+	}
+	p.fileOpTextInput.Focus()
+	p.fileOpTextInput.CursorEnd()
+}
+
+// executeCompareInput compares the prompt's path (old side) with the node.
+func (p *Plugin) executeCompareInput() (plugin.Plugin, tea.Cmd) {
+	input := strings.TrimSpace(p.fileOpTextInput.Value())
+	node := p.fileOpTarget
+	if input == "" || node == nil {
+		p.closeFileOp()
+		return p, nil
+	}
+	other := config.ExpandPath(input)
+	if !filepath.IsAbs(other) {
+		other = filepath.Join(p.ctx.WorkDir, other)
+	}
+	if _, err := os.Stat(other); err != nil {
+		p.fileOpError = "not found: " + input
+		return p, nil
+	}
+	p.closeFileOp()
+	p.compareMark = ""
+	return p, p.startCompare(filepath.Clean(other), filepath.Join(p.ctx.WorkDir, node.Path))
+}
+
+// startCompare opens the compare view for two paths and loads it.
+func (p *Plugin) startCompare(left, right string) tea.Cmd {
+	p.compare = &compareView{Left: left, Right: right, Loading: true}
+	epoch := p.ctx.Epoch
+	return func() tea.Msg {
+		msg := CompareLoadedMsg{Epoch: epoch, Left: left, Right: right}
+		li, err := os.Stat(left)
+		if err != nil {
+			msg.Err = err
+			return msg
+		}
+		ri, err := os.Stat(right)
+		if err != nil {
+			msg.Err = err
+			return msg
+		}
+		switch {
+		case li.IsDir() && ri.IsDir():
+			msg.IsDir = true
+			msg.Entries, msg.Identical, msg.Truncated, msg.Err = compareDirs(left, right)
+		case !li.IsDir() && !ri.IsDir():
+			msg.Diff, msg.Err = diffPaths(left, right, filepath.Base(left), filepath.Base(right))
+		default:
+			msg.Err = errors.New("can't compare a file with a directory")
+		}
+		return msg
+	}
+}
+
+// applyCompareLoaded stores a finished comparison.
+func (p *Plugin) applyCompareLoaded(m CompareLoadedMsg) {
+	v := p.compare
+	if v == nil || v.Left != m.Left || v.Right != m.Right {
+		return
+	}
+	v.Loading = false
+	v.IsDir = m.IsDir
+	v.Err = m.Err
+	v.Entries = m.Entries
+	v.Identical = m.Identical
+	v.Truncated = m.Truncated
+	v.Diff = m.Diff
+}
+
+// openCompareEntry diffs the directory entry under the cursor.
+func (p *Plugin) openCompareEntry() tea.Cmd {
+	v := p.compare
+	if v == nil || v.Cursor < 0 || v.Cursor >= len(v.Entries) {
+		return nil
+	}
+	e := v.Entries[v.Cursor]
+	v.FilePath = e.Path
+	v.Diff = nil
+	v.DiffErr = nil
+	v.Scroll = 0
+	left, right, epoch := filepath.Join(v.Left, e.Path), filepath.Join(v.Right, e.Path), p.ctx.Epoch
+	return func() tea.Msg {
+		diff, err := diffPaths(left, right, e.Path, e.Path)
+		return CompareDiffMsg{Epoch: epoch, Path: e.Path, Diff: diff, Err: err}
+	}
+}
+
+// applyCompareDiff stores an entry's diff if it is still open.
+func (p *Plugin) applyCompareDiff(m CompareDiffMsg) {
+	if v := p.compare; v != nil && v.FilePath == m.Path {
+		v.Diff = m.Diff
+		v.DiffErr = m.Err
+	}
+}
+
+// compareDisplayPath shows project paths relative to the project root and
+// others with ~ for the home directory.
+func (p *Plugin) compareDisplayPath(path string) string {
+	if rel := relTo(p.ctx.WorkDir, path); rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel) {
+		return rel
+	}
+	if home, err := os.UserHomeDir(); err == nil && strings.HasPrefix(path, home+string(filepath.Separator)) {
+		return "~" + strings.TrimPrefix(path, home)
+	}
+	return path
+}
+
+// compareDirs lists files that differ between two directory trees. Version
+// control and sidecar data are skipped, but ignored files are included,
+// since generated output is usually what's being compared.
+func compareDirs(left, right string) ([]compareEntry, int, bool, error) {
+	leftFiles, truncL, err := listCompareFiles(left)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	rightFiles, truncR, err := listCompareFiles(right)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	var entries []compareEntry
+	identical := 0
+	for rel, lsize := range leftFiles {
+		rsize, ok := rightFiles[rel]
+		switch {
+		case !ok:
+			entries = append(entries, compareEntry{Path: rel, Status: compareRemoved})
+		case lsize != rsize || !sameContent(filepath.Join(left, rel), filepath.Join(right, rel)):
+			entries = append(entries, compareEntry{Path: rel, Status: compareChanged})
+		default:
+			identical++
+		}
+	}
+	for rel := range rightFiles {
+		if _, ok := leftFiles[rel]; !ok {
+			entries = append(entries, compareEntry{Path: rel, Status: compareAdded})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return entries, identical, truncL || truncR, nil
+}
+
+// listCompareFiles maps the regular files under root to their sizes.
+func listCompareFiles(root string) (map[string]int64, bool, error) {
+	files := make(map[string]int64)
+	truncated := false
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if path != root && (d.Name() == ".git" || d.Name() == ".sidecar") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if len(files) >= compareMaxFiles {
+			truncated = true
+			return filepath.SkipAll
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		files[filepath.ToSlash(rel)] = info.Size()
+		return nil
+	})
+	return files, truncated, err
+}
+
+// sameContent reports whether two files have identical bytes.
+func sameContent(a, b string) bool {
+	fa, err := os.Open(a)
+	if err != nil {
+		return false
+	}
+	defer func() { _ = fa.Close() }()
+	fb, err := os.Open(b)
+	if err != nil {
+		return false
+	}
+	defer func() { _ = fb.Close() }()
+
+	bufA, bufB := make([]byte, 32*1024), make([]byte, 32*1024)
+	for {
+		na, errA := io.ReadFull(fa, bufA)
+		nb, errB := io.ReadFull(fb, bufB)
+		if na != nb || !bytes.Equal(bufA[:na], bufB[:nb]) {
+			return false
+		}
+		doneA := errA == io.EOF || errA == io.ErrUnexpectedEOF
+		doneB := errB == io.EOF || errB == io.ErrUnexpectedEOF
+		if doneA || doneB {
+			return doneA && doneB
+		}
+		if errA != nil || errB != nil {
+			return false
+		}
+	}
+}
+
+// diffPaths diffs two files in-process. A missing file diffs as empty, so
+// added and removed entries show their whole content.
+func diffPaths(left, right, leftName, rightName string) (*gitstatus.ParsedDiff, error) {
+	a, err := readCompareFile(left)
+	if err != nil {
+		return nil, err
+	}
+	b, err := readCompareFile(right)
+	if err != nil {
+		return nil, err
+	}
+	if isBinaryContent(a) || isBinaryContent(b) {
+		return &gitstatus.ParsedDiff{OldFile: leftName, NewFile: rightName, Binary: true}, nil
+	}
+	return gitstatus.DiffText(leftName, rightName, string(a), string(b), compareContext), nil
+}
+
+// readCompareFile reads a file for diffing, treating a missing one as empty.
+func readCompareFile(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > compareMaxFileSize {
+		return nil, fmt.Errorf("%s is too large to diff (%s)", filepath.Base(path), formatSize(info.Size()))
+	}
+	return os.ReadFile(path)
+}
+
+// isBinaryContent reports whether data looks binary (has a NUL byte early on).
+func isBinaryContent(data []byte) bool {
+	return bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0
+}
+
+// compareDiffLines returns the number of rendered rows of the open diff.
+func (v *compareView) compareDiffLines() int {
+	if v.Diff == nil {
+		return 0
+	}
+	return v.Diff.TotalLines()
+}
+
+// handleCompareKey handles key input in the compare view.
+func (p *Plugin) handleCompareKey(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
+	v := p.compare
+	key := msg.String()
+
+	if !v.inDiff() {
+		switch key {
+		case "esc", "q":
+			p.compare = nil
+		case "j", "down":
+			if v.Cursor < len(v.Entries)-1 {
+				v.Cursor++
+			}
+		case "k", "up":
+			if v.Cursor > 0 {
+				v.Cursor--
+			}
+		case "g":
+			v.Cursor = 0
+		case "G":
+			v.Cursor = max(0, len(v.Entries)-1)
+		case "enter", "l", "right":
+			return p, p.openCompareEntry()
+		}
+		return p, nil
+	}
+
+	page := max(p.compareDiffHeight()/2, 1)
+	maxScroll := max(v.compareDiffLines()-p.compareDiffHeight(), 0)
+	switch key {
+	case "esc", "q", "h", "left":
+		if v.IsDir {
+			v.FilePath = ""
+			v.Diff = nil
+		} else if key == "esc" || key == "q" {
+			p.compare = nil
+		}
+	case "j", "down":
+		v.Scroll = min(v.Scroll+1, maxScroll)
+	case "k", "up":
+		v.Scroll = max(v.Scroll-1, 0)
+	case "ctrl+d", "pgdown":
+		v.Scroll = min(v.Scroll+page, maxScroll)
+	case "ctrl+u", "pgup":
+		v.Scroll = max(v.Scroll-page, 0)
+	case "g":
+		v.Scroll = 0
+	case "G":
+		v.Scroll = maxScroll
+	case "v":
+		v.Unified = !v.Unified
+	case "n", "N":
+		// Step through a directory comparison's files without the list
+		if v.IsDir {
+			step := 1
+			if key == "N" {
+				step = -1
+			}
+			if next := v.Cursor + step; next >= 0 && next < len(v.Entries) {
+				v.Cursor = next
+				return p, p.openCompareEntry()
+			}
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,121 @@
+package filebrowser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestCompareDirectoriesAndFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	p := createTestPlugin(t, tmpDir)
+
+	// lib is src with one file changed, one removed and one added
+	lib := filepath.Join(tmpDir, "lib")
+	if err := os.Mkdir(lib, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"app.go":      "package src\n\nfunc Run() {}\n",
+		"config.json": "{}",
+		"extra.go":    "package src\n",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(lib, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "src", "old.go"), []byte("package src\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.tree.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	// = marks src, = on lib compares them
+	p.treeCursor = p.tree.IndexOf(p.tree.FindByPath("src"))
+	_, _ = p.handleTreeKey("=")
+	if p.compareMark != filepath.Join(tmpDir, "src") {
+		t.Fatalf("compareMark = %q", p.compareMark)
+	}
+	p.treeCursor = p.tree.IndexOf(p.tree.FindByPath("lib"))
+	_, cmd := p.handleTreeKey("=")
+	if p.compare == nil || cmd == nil || p.compareMark != "" {
+		t.Fatal("= on a second item should open the comparison")
+	}
+	_, _ = p.Update(cmd())
+
+	v := p.compare
+	want := []compareEntry{
+		{Path: "app.go", Status: compareChanged},
+		{Path: "extra.go", Status: compareAdded},
+		{Path: "old.go", Status: compareRemoved},
+	}
+	if len(v.Entries) != len(want) || v.Identical != 1 {
+		t.Fatalf("entries = %+v, identical = %d", v.Entries, v.Identical)
+	}
+	for i, e := range want {
+		if v.Entries[i] != e {
+			t.Errorf("entry %d = %+v, want %+v", i, v.Entries[i], e)
+		}
+	}
+	p.width, p.height = 120, 30
+	if view := ansi.Strip(p.renderCompareModalContent()); !strings.Contains(view, "1 added, 1 removed, 1 changed, 1 identical") {
+		t.Errorf("compare list:\n%s", view)
+	}
+
+	// enter diffs the changed file in-process
+	_, cmd = p.handleCompareKey(tea.KeyMsg{Type: tea.KeyEnter})
+	_, _ = p.Update(cmd())
+	if v.Diff == nil || len(v.Diff.Hunks) != 1 {
+		t.Fatalf("expected one hunk, got %+v (err %v)", v.Diff, v.DiffErr)
+	}
+	if view := ansi.Strip(p.renderCompareModalContent()); !strings.Contains(view, "func Run() {}") {
+		t.Errorf("compare diff:\n%s", view)
+	}
+
+	// esc goes back to the list, then closes
+	_, _ = p.handleCompareKey(tea.KeyMsg{Type: tea.KeyEsc})
+	if p.compare == nil || p.compare.inDiff() {
+		t.Fatal("esc in a diff should return to the file list")
+	}
+	_, _ = p.handleCompareKey(tea.KeyMsg{Type: tea.KeyEsc})
+	if p.compare != nil {
+		t.Fatal("esc in the list should close the comparison")
+	}
+}
+
+func TestCompareWithTypedPath(t *testing.T) {
+	tmpDir := t.TempDir()
+	p := createTestPlugin(t, tmpDir)
+	other := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(other, []byte("package other"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p.treeCursor = p.tree.IndexOf(p.tree.FindByPath("main.go"))
+	_, _ = p.handleTreeKey("+")
+	if p.fileOpMode != FileOpCompare {
+		t.Fatal("+ should prompt for a path")
+	}
+	p.fileOpTextInput.SetValue("does/not/exist")
+	_, _ = p.executeFileOp()
+	if p.fileOpError == "" || p.compare != nil {
+		t.Fatal("a missing path should be reported in the prompt")
+	}
+
+	p.fileOpTextInput.SetValue(other)
+	_, cmd := p.executeFileOp()
+	if p.fileOpMode != FileOpNone || cmd == nil {
+		t.Fatal("a valid path should start the comparison")
+	}
+	_, _ = p.Update(cmd())
+	d := p.compare.Diff
+	if d == nil || len(d.Hunks) != 1 || d.Hunks[0].Lines[0].Content != "package other" {
+		t.Fatalf("diff = %+v (err %v)", d, p.compare.Err)
+	}
+}
@@ -0,0 +1,230 @@
+package filebrowser
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/plugins/gitstatus"
+)
+
+// gutterMark is the change marker shown beside a preview line.
+type gutterMark int
+
+const (
+	gutterNone gutterMark = iota
+	gutterAdded
+	gutterModified
+	gutterDeleted // Lines were removed below this one
+)
+
+// sidecarBaseFile is where the workspace plugin records a worktree's base
+// branch.
+const sidecarBaseFile = ".sidecar-base"
+
+// DiffGutter holds the changes of a previewed file against a base revision.
+type DiffGutter struct {
+	Path    string
+	Base    string             // Label of the revision compared against
+	Marks   map[int]gutterMark // Keyed by 0-indexed line in the working file
+	Hunks   []gitstatus.Hunk   // Parsed hunks, for the peek view
+	Added   int
+	Removed int
+}
+
+// DiffGutterLoadedMsg delivers the change gutter for a previewed file.
+type DiffGutterLoadedMsg struct {
+	Epoch  uint64
+	Path   string
+	Gutter *DiffGutter // nil when the file isn't in a git repository
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m DiffGutterLoadedMsg) GetEpoch() uint64 { return m.Epoch }
+
+// LoadDiffGutter diffs a file against HEAD, or against its merge-base with
+// the worktree's base branch when againstBase is set.
+func LoadDiffGutter(workDir, path string, againstBase bool, epoch uint64) tea.Cmd {
+	return func() tea.Msg {
+		return DiffGutterLoadedMsg{Epoch: epoch, Path: path, Gutter: loadDiffGutter(workDir, path, againstBase)}
+	}
+}
+
+func loadDiffGutter(workDir, path string, againstBase bool) *DiffGutter {
+	ref, label := "HEAD", "HEAD"
+	if againstBase {
+		branch := worktreeBaseBranch(workDir)
+		out, err := gitOutput(workDir, "merge-base", branch, "HEAD")
+		if err != nil {
+			return nil
+		}
+		ref, label = strings.TrimSpace(out), branch
+	}
+
+	// Untracked files have no diff; every line is new
+	if _, err := gitOutput(workDir, "ls-files", "--error-unmatch", "--", path); err != nil {
+		if _, err := gitOutput(workDir, "rev-parse", "--git-dir"); err != nil {
+			return nil // Not a git repository
+		}
+		data, err := os.ReadFile(filepath.Join(workDir, path))
+		if err != nil {
+			return nil
+		}
+		g := &DiffGutter{Path: path, Base: label, Marks: make(map[int]gutterMark)}
+		n := bytes.Count(data, []byte("\n"))
+		if len(data) > 0 && data[len(data)-1] != '\n' {
+			n++
+		}
+		for i := 0; i < n; i++ {
+			g.Marks[i] = gutterAdded
+		}
+		g.Added = n
+		return g
+	}
+
+	diff, err := gitstatus.GetDiffFromRef(workDir, path, ref)
+	if err != nil {
+		return nil
+	}
+	g := &DiffGutter{Path: path, Base: label, Marks: make(map[int]gutterMark)}
+	if parsed := gitstatus.ParseMultiFileDiff(diff); len(parsed.Files) > 0 {
+		f := parsed.Files[0]
+		g.Hunks = f.Diff.Hunks
+		g.Added, g.Removed = f.Additions, f.Deletions
+		g.Marks = gutterMarks(g.Hunks)
+	}
+	return g
+}
+
+// gutterMarks classifies changed lines: additions paired with removals are
+// modifications, unpaired additions are new lines, and removals with no
+// replacement mark the line above them.
+func gutterMarks(hunks []gitstatus.Hunk) map[int]gutterMark {
+	marks := make(map[int]gutterMark)
+	for _, h := range hunks {
+		// Next line number in the working file. A hunk with no new lines
+		// starts after NewStart rather than at it.
+		newLine := h.NewStart
+		if h.NewCount == 0 {
+			newLine++
+		}
+		lines := h.Lines
+		for i := 0; i < len(lines); {
+			if lines[i].Type == gitstatus.LineContext {
+				newLine++
+				i++
+				continue
+			}
+			removed := 0
+			for i < len(lines) && lines[i].Type == gitstatus.LineRemove {
+				removed++
+				i++
+			}
+			added := 0
+			for i < len(lines) && lines[i].Type == gitstatus.LineAdd {
+				mark := gutterAdded
+				if added < removed {
+					mark = gutterModified
+				}
+				marks[newLine-1] = mark
+				added++
+				newLine++
+				i++
+			}
+			if added == 0 && removed > 0 {
+				line := newLine - 2
+				if line < 0 {
+					line = 0
+				}
+				if marks[line] == gutterNone {
+					marks[line] = gutterDeleted
+				}
+			}
+		}
+	}
+	return marks
+}
+
+// changeStarts returns the first line of each run of changed lines, in order.
+func (g *DiffGutter) changeStarts() []int {
+	var starts []int
+	for line := range g.Marks {
+		if g.Marks[line-1] == gutterNone {
+			starts = append(starts, line)
+		}
+	}
+	sort.Ints(starts)
+	return starts
+}
+
+// NextChange returns the start of the first change after line, or -1.
+func (g *DiffGutter) NextChange(line int) int {
+	for _, s := range g.changeStarts() {
+		if s > line {
+			return s
+		}
+	}
+	return -1
+}
+
+// PrevChange returns the start of the last change before line, or -1.
+func (g *DiffGutter) PrevChange(line int) int {
+	starts := g.changeStarts()
+	for i := len(starts) - 1; i >= 0; i-- {
+		if starts[i] < line {
+			return starts[i]
+		}
+	}
+	return -1
+}
+
+// HunkAt returns the hunk covering 0-indexed working file line, or nil.
+func (g *DiffGutter) HunkAt(line int) *gitstatus.Hunk {
+	lineNo := line + 1
+	for i := range g.Hunks {
+		h := &g.Hunks[i]
+		start, end := h.NewStart, h.NewStart+h.NewCount
+		if h.NewCount == 0 {
+			// Pure deletion: marked on the line above (or the first line)
+			start = max(h.NewStart, 1)
+			end = start + 1
+		}
+		if lineNo >= start && lineNo < end {
+			return h
+		}
+	}
+	return nil
+}
+
+// worktreeBaseBranch returns the branch recorded by the workspace plugin
+// for this worktree, falling back to the repository's default branch.
+func worktreeBaseBranch(workDir string) string {
+	if root, err := gitOutput(workDir, "rev-parse", "--show-toplevel"); err == nil {
+		if data, err := os.ReadFile(filepath.Join(strings.TrimSpace(root), sidecarBaseFile)); err == nil {
+			if branch := strings.TrimSpace(string(data)); branch != "" {
+				return branch
+			}
+		}
+	}
+	if out, err := gitOutput(workDir, "symbolic-ref", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		return strings.TrimSpace(out)
+	}
+	for _, branch := range []string{"main", "master"} {
+		if _, err := gitOutput(workDir, "rev-parse", "--verify", "--quiet", branch); err == nil {
+			return branch
+		}
+	}
+	return "main"
+}
+
+// gitOutput runs a git command in workDir and returns its stdout.
+func gitOutput(workDir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = workDir
+	out, err := cmd.Output()
+	return string(out), err
+}
@@ -0,0 +1,170 @@
+package filebrowser
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marcus/sidecar/internal/plugins/gitstatus"
+)
+
+func TestGutterMarks(t *testing.T) {
+	diff := `diff --git a/f.txt b/f.txt
+--- a/f.txt
++++ b/f.txt
+@@ -1,7 +1,8 @@
+ one
+-two
++TWO
++two and a half
+ three
+-four
+ five
+ six
++seven
+@@ -20,2 +21,0 @@
+-gone
+-also gone
+`
+	parsed := gitstatus.ParseMultiFileDiff(diff)
+	if len(parsed.Files) != 1 {
+		t.Fatalf("parsed %d files", len(parsed.Files))
+	}
+	marks := gutterMarks(parsed.Files[0].Diff.Hunks)
+
+	want := map[int]gutterMark{
+		1:  gutterModified, // TWO replaces two
+		2:  gutterAdded,    // two and a half
+		3:  gutterDeleted,  // four removed below three
+		6:  gutterAdded,    // seven
+		20: gutterDeleted,  // Removed after line 21
+	}
+	for line, mark := range want {
+		if marks[line] != mark {
+			t.Errorf("line %d: mark %d, want %d", line, marks[line], mark)
+		}
+	}
+	for line := range marks {
+		if _, ok := want[line]; !ok {
+			t.Errorf("unexpected mark on line %d", line)
+		}
+	}
+
+	g := &DiffGutter{Marks: marks, Hunks: parsed.Files[0].Diff.Hunks}
+	if got := g.NextChange(0); got != 1 {
+		t.Errorf("NextChange(0) = %d, want 1", got)
+	}
+	// Adjacent marked lines (1-3) form one change
+	if got := g.NextChange(1); got != 6 {
+		t.Errorf("NextChange(1) = %d, want 6", got)
+	}
+	if got := g.PrevChange(6); got != 1 {
+		t.Errorf("PrevChange(6) = %d, want 1", got)
+	}
+	if got := g.NextChange(20); got != -1 {
+		t.Errorf("NextChange(20) = %d, want -1", got)
+	}
+	if h := g.HunkAt(20); h == nil || h.OldStart != 20 {
+		t.Errorf("HunkAt(20) = %+v", h)
+	}
+	if h := g.HunkAt(12); h != nil {
+		t.Errorf("HunkAt(12) = %+v, want nil", h)
+	}
+}
+
+func TestLoadDiffGutter(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v (%s)", args, err, strings.TrimSpace(string(out)))
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "-q", "-b", "main")
+	write("a.txt", "one\ntwo\nthree\n")
+	git("add", "a.txt")
+	git("commit", "-q", "-m", "first")
+	git("checkout", "-q", "-b", "feature")
+	write("a.txt", "one\nTWO\nthree\n")
+	git("commit", "-q", "-am", "second")
+	// Staged and unstaged changes both count against HEAD
+	write("a.txt", "one\nTWO\nthree\nfour\n")
+	git("add", "a.txt")
+	write("a.txt", "zero\none\nTWO\nthree\nfour\n")
+
+	g := loadDiffGutter(dir, "a.txt", false)
+	if g == nil || g.Base != "HEAD" {
+		t.Fatalf("gutter = %+v", g)
+	}
+	if g.Marks[0] != gutterAdded || g.Marks[4] != gutterAdded || g.Marks[2] != gutterNone {
+		t.Errorf("vs HEAD marks = %v", g.Marks)
+	}
+
+	// Against the base branch the committed change shows too
+	g = loadDiffGutter(dir, "a.txt", true)
+	if g == nil || g.Base != "main" {
+		t.Fatalf("base gutter = %+v", g)
+	}
+	if g.Marks[2] != gutterModified {
+		t.Errorf("vs main marks = %v", g.Marks)
+	}
+
+	write("new.txt", "a\nb")
+	if g := loadDiffGutter(dir, "new.txt", false); g == nil || len(g.Marks) != 2 || g.Marks[1] != gutterAdded {
+		t.Errorf("untracked gutter = %+v", g)
+	}
+
+	if g := loadDiffGutter(t.TempDir(), "a.txt", false); g != nil {
+		t.Errorf("expected no gutter outside a repository, got %+v", g)
+	}
+}
+
+func TestJumpToChange(t *testing.T) {
+	p := createTestPluginWithPreview(t, t.TempDir(), strings.Repeat("line\n", 100))
+	p.diffGutter = &DiffGutter{
+		Path:  p.previewFile,
+		Marks: map[int]gutterMark{5: gutterAdded, 6: gutterAdded, 60: gutterModified},
+		Hunks: []gitstatus.Hunk{{NewStart: 61, NewCount: 1, OldStart: 58, OldCount: 1}},
+	}
+
+	_, _ = p.handlePreviewKey("}")
+	if p.diffChangeLine != 6 {
+		t.Fatalf("first jump to line %d, want 6", p.diffChangeLine)
+	}
+	_, _ = p.handlePreviewKey("}")
+	if p.diffChangeLine != 61 || p.previewScroll == 0 {
+		t.Fatalf("second jump to line %d (scroll %d), want 61", p.diffChangeLine, p.previewScroll)
+	}
+
+	_, _ = p.handlePreviewKey("p")
+	if !p.diffPeekOpen {
+		t.Error("peek should open on a changed hunk")
+	}
+	_, _ = p.handlePreviewKey("esc")
+	if p.diffPeekOpen || p.activePane != PanePreview {
+		t.Error("esc should close the peek before leaving the preview")
+	}
+
+	_, _ = p.handlePreviewKey("{")
+	if p.diffChangeLine != 6 {
+		t.Errorf("jump back to line %d, want 6", p.diffChangeLine)
+	}
+}
@@ -28,6 +28,11 @@ func (p *Plugin) handleKey(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 		return p.handleQuickOpenKey(msg)
 	}
 
+	// Handle symbol palette
+	if p.symbolMode {
+		return p.handleSymbolKey(msg)
+	}
+
 	// Handle info modal
 	if p.infoMode {
 		return p.handleInfoKey(msg)
@@ -38,6 +43,21 @@ func (p *Plugin) handleKey(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 		return p.handleBlameKey(msg)
 	}
 
+	// Handle trash view
+	if p.trashMode {
+		return p.handleTrashKey(msg)
+	}
+
+	// Handle local history timeline
+	if p.historyMode {
+		return p.handleHistoryKey(msg)
+	}
+
+	// Handle compare view
+	if p.compare != nil {
+		return p.handleCompareKey(msg)
+	}
+
 	// Handle file operation mode (move/rename/create/delete)
 	if p.fileOpMode != FileOpNone {
 		return p.handleFileOpKey(msg)
@@ -58,6 +78,11 @@ func (p *Plugin) handleKey(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 		return p.handleSearchKey(msg)
 	}
 
+	// Handle tail filter input
+	if p.tail != nil && p.tail.Editing {
+		return p, p.handleTailFilterKey(key)
+	}
+
 	// Quick open and project search only from tree/preview (not during text input modes)
 	if key == "ctrl+p" {
 		return p.openQuickOpen()
@@ -65,6 +90,12 @@ func (p *Plugin) handleKey(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 	if key == "f" {
 		return p.openProjectSearch()
 	}
+	if key == "ctrl+t" {
+		return p.openProjectSymbols()
+	}
+	if key == "O" {
+		return p.openSymbolOutline()
+	}
 
 	// Handle keys based on active pane
 	if p.activePane == PanePreview {
@@ -209,11 +240,16 @@ func (p *Plugin) handleTreeKey(key string) (plugin.Plugin, tea.Cmd) {
 		}
 
 	case "R":
-		// Rename file/directory
+		// Rename file/directory, or every selected item with a pattern
+		if p.hasSelection() {
+			p.openBatchOp(FileOpRename)
+			return p, nil
+		}
 		node := p.tree.GetNode(p.treeCursor)
 		if node != nil && node != p.tree.Root {
 			p.fileOpMode = FileOpRename
 			p.fileOpTarget = node
+			p.fileOpBatch = nil
 			p.fileOpTextInput = textinput.New()
 			p.fileOpTextInput.SetValue(node.Name)
 			p.fileOpTextInput.Focus()
@@ -253,11 +289,16 @@ func (p *Plugin) handleTreeKey(key string) (plugin.Plugin, tea.Cmd) {
 		return p, p.refresh()
 
 	case "m":
-		// Move file/directory
+		// Move file/directory, or every selected item into a directory
+		if p.hasSelection() {
+			p.openBatchOp(FileOpMove)
+			return p, nil
+		}
 		node := p.tree.GetNode(p.treeCursor)
 		if node != nil && node != p.tree.Root {
 			p.fileOpMode = FileOpMove
 			p.fileOpTarget = node
+			p.fileOpBatch = nil
 			p.fileOpTextInput = textinput.New()
 			p.fileOpTextInput.SetValue(node.Path)
 			p.fileOpTextInput.Focus()
@@ -294,22 +335,34 @@ func (p *Plugin) handleTreeKey(key string) (plugin.Plugin, tea.Cmd) {
 		}
 
 	case "D":
-		// Delete file/directory (requires confirmation)
+		// Delete file/directory (requires confirmation). A selection is
+		// moved to the trash so the batch can be undone.
+		if p.hasSelection() {
+			p.openBatchOp(FileOpDelete)
+			return p, nil
+		}
 		node := p.tree.GetNode(p.treeCursor)
 		if node != nil && node != p.tree.Root {
 			p.fileOpMode = FileOpDelete
 			p.fileOpTarget = node
+			p.fileOpBatch = nil
 			p.fileOpConfirmDelete = true
 			p.fileOpError = ""
 			p.fileOpButtonFocus = 1 // Start with confirm button focused
 		}
 
 	case "y":
-		// Yank (mark) file/directory for paste
+		// Yank (mark) file/directory, or the whole selection, for paste
+		if p.hasSelection() {
+			p.clipboardPaths = p.selectedPaths()
+			p.clipboardPath = ""
+			return p, appmsg.ShowToast("Marked for copy: "+pluralItems(len(p.clipboardPaths)), 2*time.Second)
+		}
 		node := p.tree.GetNode(p.treeCursor)
 		if node != nil && node != p.tree.Root {
 			p.clipboardPath = node.Path
 			p.clipboardIsDir = node.IsDir
+			p.clipboardPaths = nil
 			return p, appmsg.ShowToast("Marked for copy: "+node.Path, 2*time.Second)
 		}
 
@@ -325,6 +378,11 @@ func (p *Plugin) handleTreeKey(key string) (plugin.Plugin, tea.Cmd) {
 
 	case "p":
 		// Paste file/directory from clipboard
+		if len(p.clipboardPaths) > 0 {
+			if node := p.tree.GetNode(p.treeCursor); node != nil {
+				return p, p.pasteBatch(node)
+			}
+		}
 		if p.clipboardPath != "" {
 			node := p.tree.GetNode(p.treeCursor)
 			if node != nil {
@@ -332,6 +390,42 @@ func (p *Plugin) handleTreeKey(key string) (plugin.Plugin, tea.Cmd) {
 			}
 		}
 
+	case " ":
+		// Toggle selection and move down
+		p.toggleSelected(p.tree.GetNode(p.treeCursor))
+		if p.treeCursor < p.tree.Len()-1 {
+			p.treeCursor++
+			p.ensureTreeCursorVisible()
+			return p, p.loadPreviewForCursor()
+		}
+
+	case "V":
+		// Select from the last toggled item to the cursor
+		p.selectRange()
+
+	case "*":
+		// Select files matching a glob
+		p.openBatchOp(FileOpGlobSelect)
+
+	case "esc":
+		p.clearSelection()
+
+	case "u":
+		// Undo the last batch operation
+		return p, p.undoLastBatch()
+
+	case "T":
+		// View trashed files
+		return p, p.openTrashView()
+
+	case "=":
+		// Mark for compare, or compare with the marked item
+		return p, p.markOrCompare(p.tree.GetNode(p.treeCursor))
+
+	case "+":
+		// Compare with a path typed in, e.g. in another worktree
+		p.openCompareInput(p.tree.GetNode(p.treeCursor))
+
 	case "s":
 		// Cycle sort mode
 		newMode := p.tree.SortMode.Next()
@@ -413,6 +507,17 @@ func (p *Plugin) handleTreeKey(key string) (plugin.Plugin, tea.Cmd) {
 }
 
 func (p *Plugin) handlePreviewKey(key string) (plugin.Plugin, tea.Cmd) {
+	if p.structuredActive() {
+		if handled, cmd := p.handleStructuredKey(key); handled {
+			return p, cmd
+		}
+	}
+	if p.tailActive() {
+		if handled, cmd := p.handleTailKey(key); handled {
+			return p, cmd
+		}
+	}
+
 	lines := p.getPreviewLines()
 	visibleHeight := p.visibleContentHeight()
 	maxScroll := len(lines) - visibleHeight
@@ -462,6 +567,11 @@ func (p *Plugin) handlePreviewKey(key string) (plugin.Plugin, tea.Cmd) {
 		}
 
 	case "h", "left", "esc":
+		// Esc closes an open change peek first
+		if key == "esc" && p.diffPeekOpen {
+			p.diffPeekOpen = false
+			return p, nil
+		}
 		// Restore tree pane if hidden, otherwise return to it
 		if !p.treeVisible {
 			p.treeVisible = true
@@ -489,6 +599,39 @@ func (p *Plugin) handlePreviewKey(key string) (plugin.Plugin, tea.Cmd) {
 		p.lineJumpMode = true
 		p.lineJumpBuffer = ""
 
+	case "d":
+		// Go to definition of the symbol under the selection or on the current line
+		return p.goToDefinition()
+
+	case "}":
+		// Jump to next git change
+		p.jumpToChange(true)
+
+	case "{":
+		// Jump to previous git change
+		p.jumpToChange(false)
+
+	case "p":
+		// Peek at the original lines of the change under the cursor
+		if p.diffPeekOpen {
+			p.diffPeekOpen = false
+		} else if p.currentDiffHunk() != nil {
+			p.diffPeekOpen = true
+		}
+
+	case "b":
+		// Compare against HEAD or the worktree base branch
+		p.diffGutterBase = !p.diffGutterBase
+		label := "HEAD"
+		if p.diffGutterBase {
+			label = "base branch"
+		}
+		return p, tea.Batch(p.refreshDiffGutter(), appmsg.ShowToast("Changes vs "+label, 2*time.Second))
+
+	case "ctrl+o":
+		// Return to the location before the last symbol jump
+		return p.jumpBack()
+
 	case "/":
 		// Enter content search mode if we have content to search
 		if len(p.previewLines) > 0 && !p.isBinary {
@@ -559,6 +702,10 @@ func (p *Plugin) handlePreviewKey(key string) (plugin.Plugin, tea.Cmd) {
 			p.toggleMarkdownRender()
 		}
 
+	case "v":
+		// Toggle structured view for JSON, YAML, CSV and TSV files
+		return p, p.toggleStructuredView()
+
 	case "w":
 		// Toggle line wrapping
 		p.previewWrapEnabled = !p.previewWrapEnabled
@@ -571,6 +718,14 @@ func (p *Plugin) handlePreviewKey(key string) (plugin.Plugin, tea.Cmd) {
 			return p.openBlameView(p.previewFile)
 		}
 
+	case "F":
+		// Follow the file as it grows, like tail -f
+		return p, p.toggleTail()
+
+	case "L":
+		// Show local history timeline for current preview file
+		return p, p.openHistoryView()
+
 	case "[":
 		return p, p.cycleTab(-1)
 
@@ -613,16 +768,21 @@ func (p *Plugin) handleFileOpKey(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 				// Cancel button focused, treat as cancel
 				p.fileOpMode = FileOpNone
 				p.fileOpTarget = nil
+				p.fileOpBatch = nil
 				p.fileOpError = ""
 				p.fileOpConfirmDelete = false
 				return p, nil
 			}
 			p.fileOpConfirmDelete = false
+			if len(p.fileOpBatch) > 0 {
+				return p.executeBatchOp()
+			}
 			return p, p.doDelete()
 		case "n", "N", "esc":
 			// Cancel delete
 			p.fileOpMode = FileOpNone
 			p.fileOpTarget = nil
+			p.fileOpBatch = nil
 			p.fileOpError = ""
 			p.fileOpConfirmDelete = false
 			return p, nil
@@ -694,10 +854,7 @@ func (p *Plugin) handleFileOpKey(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 	switch key {
 	case "esc":
 		// Cancel file operation
-		p.fileOpMode = FileOpNone
-		p.fileOpTarget = nil
-		p.fileOpError = ""
-		p.fileOpShowSuggestions = false
+		p.closeFileOp()
 		return p, nil
 
 	case "up", "ctrl+p":
@@ -761,6 +918,7 @@ func (p *Plugin) handleFileOpKey(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 		if p.fileOpButtonFocus == 2 {
 			p.fileOpMode = FileOpNone
 			p.fileOpTarget = nil
+			p.fileOpBatch = nil
 			p.fileOpError = ""
 			p.fileOpShowSuggestions = false
 			return p, nil
@@ -968,6 +1126,158 @@ func (p *Plugin) handleQuickOpenKey(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 	return p, nil
 }
 
+// handleStructuredKey handles keys in the structured view. Keys it doesn't
+// use fall through to the preview so tabs, editing and pane switching keep
+// working.
+func (p *Plugin) handleStructuredKey(key string) (bool, tea.Cmd) {
+	v := p.structured
+	if v == nil {
+		// Still loading or failed: only leaving the view is meaningful
+		return key != "v" && key != "esc" && key != "h" && key != "left" &&
+			key != "tab" && key != "shift+tab" && key != "[" && key != "]" && key != "x", nil
+	}
+	height := p.structuredBodyHeight()
+	defer v.EnsureVisible(height)
+
+	switch key {
+	case "j", "down":
+		v.MoveCursor(1)
+	case "k", "up":
+		v.MoveCursor(-1)
+	case "g", "home":
+		v.Cursor = 0
+	case "G", "end":
+		v.MoveCursor(v.RowCount())
+	case "ctrl+d":
+		v.MoveCursor(height / 2)
+	case "ctrl+u":
+		v.MoveCursor(-height / 2)
+	case "ctrl+f", "pgdown":
+		v.MoveCursor(height)
+	case "ctrl+b", "pgup":
+		v.MoveCursor(-height)
+	case "y":
+		return true, p.copyStructuredValue()
+	case "/":
+		// Search works on the text view
+		p.structuredMode = false
+		return v.Kind == StructuredSQLite && p.isBinary, nil
+	default:
+		if v.Kind.IsTree() {
+			return p.handleStructuredTreeKey(key)
+		}
+		return p.handleStructuredTableKey(key)
+	}
+	return true, nil
+}
+
+// handleStructuredTreeKey handles expand and collapse in the JSON/YAML tree.
+func (p *Plugin) handleStructuredTreeKey(key string) (bool, tea.Cmd) {
+	v := p.structured
+	switch key {
+	case "enter", " ":
+		if n := v.CurrentNode(); n != nil && n.Kind != DataScalar {
+			v.SetExpanded(!v.expanded[n.Path])
+		}
+	case "l", "right":
+		if !v.SetExpanded(true) {
+			v.MoveCursor(1)
+		}
+	case "h", "left":
+		// Collapse, then climb; at the root, fall through to leave the pane
+		return v.SetExpanded(false) || v.CursorToParent(), nil
+	case "E":
+		v.expandAll()
+	case "C":
+		v.expanded = map[string]bool{".": true}
+		v.rebuildRows()
+		v.Cursor = 0
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// handleStructuredTableKey handles column selection, sorting and SQLite
+// table and page navigation.
+func (p *Plugin) handleStructuredTableKey(key string) (bool, tea.Cmd) {
+	v := p.structured
+	switch key {
+	case "l", "right":
+		if v.Table != nil && v.Column < len(v.Table.Columns)-1 {
+			v.Column++
+		}
+	case "h", "left":
+		// At the first column, fall through to leave the pane
+		if v.Column == 0 {
+			return false, nil
+		}
+		v.Column--
+	case "s":
+		v.CycleSort()
+		if v.Kind == StructuredSQLite {
+			// Databases sort the whole table, starting again from page one
+			v.Page = 0
+			return true, p.loadSQLitePage()
+		}
+	case "t":
+		return true, p.selectSQLiteTable(1)
+	case "T":
+		return true, p.selectSQLiteTable(-1)
+	case ">", "n":
+		return true, p.changeSQLitePage(1)
+	case "<", "N":
+		return true, p.changeSQLitePage(-1)
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// handleSymbolKey handles key input in the symbol palette.
+func (p *Plugin) handleSymbolKey(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
+	key := msg.String()
+
+	switch key {
+	case "esc":
+		p.closeSymbolPalette()
+
+	case "enter":
+		return p.selectSymbolMatch()
+
+	case "tab":
+		return p.toggleSymbolScope()
+
+	case "up", "ctrl+p":
+		if p.symbolCursor > 0 {
+			p.symbolCursor--
+		}
+
+	case "down", "ctrl+n":
+		if p.symbolCursor < len(p.symbolMatches)-1 {
+			p.symbolCursor++
+		}
+
+	case "backspace":
+		if len(p.symbolQuery) > 0 {
+			runes := []rune(p.symbolQuery)
+			p.symbolQuery = string(runes[:len(runes)-1])
+			p.symbolCursor = 0
+			p.updateSymbolMatches()
+		}
+
+	default:
+		// Append printable characters
+		if len(key) == 1 && key[0] >= 32 && key[0] <= 126 {
+			p.symbolQuery += key
+			p.symbolCursor = 0
+			p.updateSymbolMatches()
+		}
+	}
+
+	return p, nil
+}
+
 // handleProjectSearchKey handles key input during project search mode.
 func (p *Plugin) handleProjectSearchKey(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 	key := msg.String()
@@ -1112,6 +1422,43 @@ func (p *Plugin) handleProjectSearchKey(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd)
 			state.Cursor = state.NearestMatchIndex(state.Cursor)
 		}
 
+	case "ctrl+r":
+		// Toggle replace mode, focusing the replacement field when enabled
+		if state != nil {
+			state.ReplaceMode = !state.ReplaceMode
+			state.ReplaceFocus = state.ReplaceMode
+		}
+
+	case "ctrl+f":
+		// Switch typing between the search and replacement fields
+		if state != nil && state.ReplaceMode {
+			state.ReplaceFocus = !state.ReplaceFocus
+		}
+
+	case "ctrl+x":
+		// Include/exclude the selected match from replacement
+		if state != nil && state.ReplaceMode {
+			state.ToggleMatch()
+		}
+
+	case "alt+x":
+		// Include/exclude every match in the selected file
+		if state != nil && state.ReplaceMode {
+			state.ToggleFile()
+		}
+
+	case "ctrl+s":
+		// Apply replacement to included matches
+		if state != nil && state.ReplaceMode {
+			return p, p.applyProjectReplace()
+		}
+
+	case "ctrl+z":
+		// Undo the last replace
+		if p.lastReplace != nil {
+			return p, RunUndoProjectReplace(p.ctx.WorkDir, p.lastReplace, p.ctx.Epoch)
+		}
+
 	case "alt+r":
 		// Toggle regex mode
 		return p.toggleProjectSearchOption(state, &state.UseRegex)
@@ -1125,13 +1472,19 @@ func (p *Plugin) handleProjectSearchKey(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd)
 		return p.toggleProjectSearchOption(state, &state.WholeWord)
 
 	case "backspace":
-		if state != nil && len(state.Query) > 0 {
+		if state != nil && state.ReplaceMode && state.ReplaceFocus {
+			if runes := []rune(state.Replacement); len(runes) > 0 {
+				state.Replacement = string(runes[:len(runes)-1])
+			}
+		} else if state != nil && len(state.Query) > 0 {
 			runes := []rune(state.Query)
 			state.Query = string(runes[:len(runes)-1])
 			if state.Query == "" {
 				state.Results = nil
 				state.Error = ""
+				state.IsSearching = false
 				state.DebounceVersion++ // Cancel any pending search
+				state.cancelSearch()
 			} else {
 				state.IsSearching = true
 				state.DebounceVersion++
@@ -1141,7 +1494,11 @@ func (p *Plugin) handleProjectSearchKey(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd)
 
 	default:
 		// Append printable characters
-		if state != nil && len(key) == 1 && key[0] >= 32 && key[0] <= 126 {
+		if state != nil && state.ReplaceMode && state.ReplaceFocus {
+			if len(key) == 1 && key[0] >= 32 && key[0] <= 126 {
+				state.Replacement += key
+			}
+		} else if state != nil && len(key) == 1 && key[0] >= 32 && key[0] <= 126 {
 			state.Query += key
 			state.IsSearching = true
 			state.DebounceVersion++
@@ -1152,6 +1509,53 @@ func (p *Plugin) handleProjectSearchKey(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd)
 	return p, cmd
 }
 
+// applyProjectReplace validates the replace inputs and starts the replace.
+func (p *Plugin) applyProjectReplace() tea.Cmd {
+	state := p.projectSearchState
+	if state.IsSearching {
+		return func() tea.Msg {
+			return appmsg.ToastMsg{Message: "Search still running", Duration: 2 * time.Second}
+		}
+	}
+	if _, err := state.replacePattern(); err != nil {
+		return func() tea.Msg {
+			return appmsg.ToastMsg{Message: "Invalid pattern: " + err.Error(), Duration: 3 * time.Second, IsError: true}
+		}
+	}
+	if matches, _ := state.IncludedMatches(); matches == 0 {
+		return func() tea.Msg {
+			return appmsg.ToastMsg{Message: "No matches selected", Duration: 2 * time.Second}
+		}
+	}
+	return RunProjectReplace(p.ctx.WorkDir, state, p.ctx.Epoch)
+}
+
+// afterProjectReplace refreshes views of the rewritten files, reruns the open
+// search and notifies other plugins of the changed paths.
+func (p *Plugin) afterProjectReplace(paths []string, toast string) tea.Cmd {
+	cmds := []tea.Cmd{
+		p.refresh(),
+		func() tea.Msg { return FilesChangedMsg{Paths: paths} },
+	}
+	for _, path := range paths {
+		if path == p.previewFile {
+			cmds = append(cmds, LoadPreview(p.ctx.WorkDir, p.previewFile, p.ctx.Epoch))
+			break
+		}
+	}
+	if state := p.projectSearchState; state != nil && state.Query != "" {
+		state.IsSearching = true
+		state.DebounceVersion++
+		cmds = append(cmds, RunProjectSearch(p.ctx.WorkDir, state, p.ctx.Epoch))
+	}
+	if toast != "" {
+		cmds = append(cmds, func() tea.Msg {
+			return appmsg.ToastMsg{Message: toast, Duration: 3 * time.Second}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
 func (p *Plugin) toggleProjectSearchOption(state *ProjectSearchState, option *bool) (plugin.Plugin, tea.Cmd) {
 	if state == nil || option == nil {
 		return p, nil
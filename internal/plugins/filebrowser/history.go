@@ -0,0 +1,312 @@
+package filebrowser
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/localhistory"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/plugins/gitstatus"
+)
+
+// historyCurrent is the timeline's compare base when no snapshot is marked:
+// the file as it is on disk.
+const historyCurrent = -1
+
+// HistorySnapshotMsg reports snapshots taken of a watched file.
+type HistorySnapshotMsg struct {
+	Epoch uint64
+	Path  string
+	Added bool
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m HistorySnapshotMsg) GetEpoch() uint64 { return m.Epoch }
+
+// HistoryListMsg delivers a file's local history, newest first.
+type HistoryListMsg struct {
+	Epoch     uint64
+	Path      string
+	Snapshots []localhistory.Snapshot
+	Err       error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m HistoryListMsg) GetEpoch() uint64 { return m.Epoch }
+
+// HistoryDiffMsg delivers the diff between two versions in the timeline.
+type HistoryDiffMsg struct {
+	Epoch uint64
+	Key   string // Identifies the compared pair; stale diffs are dropped
+	Diff  *gitstatus.ParsedDiff
+	Err   error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m HistoryDiffMsg) GetEpoch() uint64 { return m.Epoch }
+
+// HistoryRestoredMsg is sent when a snapshot was written back to its file.
+type HistoryRestoredMsg struct {
+	Epoch    uint64
+	Snapshot localhistory.Snapshot
+	Err      error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m HistoryRestoredMsg) GetEpoch() uint64 { return m.Epoch }
+
+// snapshotHistory records the current content of a project file.
+func (p *Plugin) snapshotHistory(path string) tea.Cmd {
+	store := p.history
+	if store == nil || path == "" {
+		return nil
+	}
+	epoch := p.ctx.Epoch
+	return func() tea.Msg {
+		added, err := store.Snapshot(path)
+		if err != nil {
+			return nil // Vanished or unreadable files have no version to keep
+		}
+		return HistorySnapshotMsg{Epoch: epoch, Path: path, Added: added}
+	}
+}
+
+// openHistoryView shows the local history timeline of the previewed file.
+func (p *Plugin) openHistoryView() tea.Cmd {
+	if p.previewFile == "" {
+		return nil
+	}
+	if p.history == nil {
+		return appmsg.ShowToast("Local history is disabled", 2*time.Second)
+	}
+	p.historyMode = true
+	p.historyPath = p.previewFile
+	p.historySnaps = nil
+	p.historyCursor = 0
+	p.historyBase = historyCurrent
+	p.historyDiff = nil
+	p.historyDiffKey = ""
+	p.historyDiffScroll = 0
+	p.historyDiffErr = nil
+	p.historyErr = nil
+	p.historyConfirm = false
+	// Snapshot first so the list always includes the current version
+	return tea.Sequence(p.snapshotHistory(p.historyPath), p.loadHistory())
+}
+
+// closeHistoryView hides the timeline.
+func (p *Plugin) closeHistoryView() {
+	p.historyMode = false
+	p.historySnaps = nil
+	p.historyDiff = nil
+	p.historyConfirm = false
+}
+
+// loadHistory lists the timeline file's snapshots in the background.
+func (p *Plugin) loadHistory() tea.Cmd {
+	store, path, epoch := p.history, p.historyPath, p.ctx.Epoch
+	return func() tea.Msg {
+		snaps, err := store.List(path)
+		return HistoryListMsg{Epoch: epoch, Path: path, Snapshots: snaps, Err: err}
+	}
+}
+
+// applyHistoryList stores a loaded timeline and diffs the selected entry.
+func (p *Plugin) applyHistoryList(m HistoryListMsg) tea.Cmd {
+	if !p.historyMode || m.Path != p.historyPath {
+		return nil
+	}
+	// Keep the cursor and mark on the same snapshots as entries are added
+	selected, base := p.selectedSnapshot(), p.baseSnapshot()
+	p.historySnaps = m.Snapshots
+	p.historyErr = m.Err
+	p.historyCursor = 0
+	p.historyBase = historyCurrent
+	for i, snap := range p.historySnaps {
+		if selected != nil && snap == *selected {
+			p.historyCursor = i
+		}
+		if base != nil && snap == *base {
+			p.historyBase = i
+		}
+	}
+	return p.loadHistoryDiff()
+}
+
+// selectedSnapshot returns the snapshot under the cursor.
+func (p *Plugin) selectedSnapshot() *localhistory.Snapshot {
+	if p.historyCursor < 0 || p.historyCursor >= len(p.historySnaps) {
+		return nil
+	}
+	return &p.historySnaps[p.historyCursor]
+}
+
+// baseSnapshot returns the marked compare base, or nil for the current file.
+func (p *Plugin) baseSnapshot() *localhistory.Snapshot {
+	if p.historyBase < 0 || p.historyBase >= len(p.historySnaps) {
+		return nil
+	}
+	return &p.historySnaps[p.historyBase]
+}
+
+// historyPair returns the older and newer sides of the comparison with
+// their labels. The newer side is the marked snapshot or the current file.
+func (p *Plugin) historyPair() (oldPath, newPath, oldLabel, newLabel string, ok bool) {
+	sel := p.selectedSnapshot()
+	if sel == nil {
+		return "", "", "", "", false
+	}
+	oldPath, oldLabel = p.history.ObjectPath(*sel), snapshotLabel(*sel)
+	newPath, newLabel = filepath.Join(p.ctx.WorkDir, p.historyPath), "current"
+	if base := p.baseSnapshot(); base != nil {
+		newPath, newLabel = p.history.ObjectPath(*base), snapshotLabel(*base)
+		if base.Time.Before(sel.Time) {
+			oldPath, newPath = newPath, oldPath
+			oldLabel, newLabel = newLabel, oldLabel
+		}
+	}
+	return oldPath, newPath, oldLabel, newLabel, true
+}
+
+// loadHistoryDiff diffs the selected snapshot against the compare base.
+func (p *Plugin) loadHistoryDiff() tea.Cmd {
+	oldPath, newPath, _, _, ok := p.historyPair()
+	if !ok {
+		p.historyDiff = nil
+		p.historyDiffKey = ""
+		return nil
+	}
+	key := oldPath + "\x00" + newPath
+	if key == p.historyDiffKey && p.historyDiff != nil && p.historyBase != historyCurrent {
+		return nil // Snapshots never change; only the current file does
+	}
+	p.historyDiffKey = key
+	p.historyDiffScroll = 0
+	workDir, epoch := p.ctx.WorkDir, p.ctx.Epoch
+	return func() tea.Msg {
+		diff, err := diffFiles(workDir, oldPath, newPath)
+		return HistoryDiffMsg{Epoch: epoch, Key: key, Diff: diff, Err: err}
+	}
+}
+
+// diffFiles runs git's no-index diff on two files.
+func diffFiles(workDir, oldPath, newPath string) (*gitstatus.ParsedDiff, error) {
+	out, err := gitOutput(workDir, "diff", "--no-index", "--no-color", "--no-ext-diff", "-U3", "--", oldPath, newPath)
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+		return nil, fmt.Errorf("diff failed: %w", err)
+	}
+	return gitstatus.ParseUnifiedDiff(out)
+}
+
+// applyHistoryDiff stores a diff if it is still the selected comparison.
+func (p *Plugin) applyHistoryDiff(m HistoryDiffMsg) {
+	if m.Key != p.historyDiffKey {
+		return
+	}
+	p.historyDiff = m.Diff
+	p.historyDiffErr = m.Err
+}
+
+// restoreSnapshot writes the selected snapshot back to the file.
+func (p *Plugin) restoreSnapshot() tea.Cmd {
+	sel := p.selectedSnapshot()
+	if sel == nil {
+		return nil
+	}
+	store, snap, epoch := p.history, *sel, p.ctx.Epoch
+	return func() tea.Msg {
+		return HistoryRestoredMsg{Epoch: epoch, Snapshot: snap, Err: store.Restore(snap)}
+	}
+}
+
+// applyHistoryRestored reloads the preview and timeline after a restore.
+func (p *Plugin) applyHistoryRestored(m HistoryRestoredMsg) tea.Cmd {
+	if m.Err != nil {
+		return appmsg.ShowToast("Restore failed: "+m.Err.Error(), 3*time.Second)
+	}
+	path := filepath.FromSlash(m.Snapshot.Path)
+	cmds := []tea.Cmd{
+		appmsg.ShowToast(fmt.Sprintf("Restored %s from %s; previous content kept in history", filepath.Base(path), snapshotLabel(m.Snapshot)), 3*time.Second),
+		func() tea.Msg { return FilesChangedMsg{Paths: []string{path}} },
+	}
+	if path == p.previewFile {
+		cmds = append(cmds, LoadPreview(p.ctx.WorkDir, p.previewFile, p.ctx.Epoch))
+	}
+	if p.historyMode {
+		cmds = append(cmds, p.loadHistory())
+	}
+	return tea.Batch(cmds...)
+}
+
+// snapshotLabel formats when a snapshot was taken.
+func snapshotLabel(s localhistory.Snapshot) string {
+	if s.Time.YearDay() == time.Now().YearDay() && s.Time.Year() == time.Now().Year() {
+		return s.Time.Format("15:04:05")
+	}
+	return s.Time.Format("Jan 2 15:04")
+}
+
+// handleHistoryKey handles key input in the timeline view.
+func (p *Plugin) handleHistoryKey(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
+	key := msg.String()
+
+	if p.historyConfirm {
+		p.historyConfirm = false
+		if key == "y" || key == "Y" || key == "enter" {
+			return p, p.restoreSnapshot()
+		}
+		return p, nil
+	}
+
+	switch key {
+	case "esc", "q", "L":
+		p.closeHistoryView()
+
+	case "j", "down":
+		if p.historyCursor < len(p.historySnaps)-1 {
+			p.historyCursor++
+			return p, p.loadHistoryDiff()
+		}
+
+	case "k", "up":
+		if p.historyCursor > 0 {
+			p.historyCursor--
+			return p, p.loadHistoryDiff()
+		}
+
+	case "g":
+		p.historyCursor = 0
+		return p, p.loadHistoryDiff()
+
+	case "G":
+		p.historyCursor = max(0, len(p.historySnaps)-1)
+		return p, p.loadHistoryDiff()
+
+	case " ", "m":
+		// Mark the selected snapshot as the compare base; again to unmark
+		if p.historyBase == p.historyCursor {
+			p.historyBase = historyCurrent
+		} else if p.selectedSnapshot() != nil {
+			p.historyBase = p.historyCursor
+		}
+		return p, p.loadHistoryDiff()
+
+	case "ctrl+d", "J":
+		p.historyDiffScroll += 10
+
+	case "ctrl+u", "K":
+		p.historyDiffScroll = max(0, p.historyDiffScroll-10)
+
+	case "r", "enter":
+		if p.selectedSnapshot() != nil {
+			p.historyConfirm = true
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,75 @@
+package filebrowser
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/marcus/sidecar/internal/localhistory"
+)
+
+func TestLocalHistoryTimelineDiffAndRestore(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	tmpDir := t.TempDir()
+	p := createTestPlugin(t, tmpDir)
+	p.history = localhistory.Open(tmpDir)
+	p.previewFile = "main.go"
+	file := filepath.Join(tmpDir, "main.go")
+
+	// The preview load snapshots the original content
+	if m := p.snapshotHistory("main.go")(); !m.(HistorySnapshotMsg).Added {
+		t.Fatal("opening a file should snapshot it")
+	}
+	if err := os.WriteFile(file, []byte("package main\n\nfunc agent() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Second)
+	_ = os.Chtimes(file, future, future)
+
+	// Opening the timeline snapshots the rewrite, newest first
+	p.openHistoryView()
+	if !p.historyMode || p.FocusContext() != "file-browser-history" {
+		t.Fatal("L should open the timeline")
+	}
+	_, _ = p.Update(p.snapshotHistory("main.go")())
+	_, _ = p.Update(p.loadHistory()())
+	if len(p.historySnaps) != 2 {
+		t.Fatalf("snapshots = %d, want 2", len(p.historySnaps))
+	}
+
+	// The original version diffs against the current file
+	_, cmd := p.handleHistoryKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	_, _ = p.Update(cmd())
+	if p.historyDiff == nil || len(p.historyDiff.Hunks) == 0 {
+		t.Fatalf("expected a diff, got %+v (err %v)", p.historyDiff, p.historyDiffErr)
+	}
+	p.width, p.height = 100, 40
+	view := ansi.Strip(p.renderHistoryModalContent())
+	if !strings.Contains(view, "+ func agent() {}") || !strings.Contains(view, "→ current") {
+		t.Errorf("timeline view:\n%s", view)
+	}
+
+	// Restore asks first, then writes the old version back
+	_, _ = p.handleHistoryKey(tea.KeyMsg{Type: tea.KeyEnter})
+	if !p.historyConfirm {
+		t.Fatal("restore should ask for confirmation")
+	}
+	_, cmd = p.handleHistoryKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	restored := cmd().(HistoryRestoredMsg)
+	if restored.Err != nil {
+		t.Fatal(restored.Err)
+	}
+	if data, _ := os.ReadFile(file); string(data) != "package main" {
+		t.Errorf("restored content = %q", data)
+	}
+	if snaps, _ := p.history.List("main.go"); len(snaps) != 3 {
+		t.Errorf("restore should keep the overwritten version, have %d snapshots", len(snaps))
+	}
+}
@@ -23,6 +23,7 @@ const (
 	regionPaneDivider = "pane-divider" // Border between tree and preview
 	regionTreeItem    = "tree-item"    // Individual file/folder (Data: visible index)
 	regionQuickOpen   = "quick-open"   // Quick open modal item (Data: match index)
+	regionSymbol      = "symbol"       // Symbol palette item (Data: match index)
 	regionPreviewLine = "preview-line" // Individual preview line (Data: line index)
 	regionPreviewTab  = "preview-tab"  // Preview tab (Data: tab index)
 
@@ -183,6 +184,11 @@ func (p *Plugin) handleMouse(msg tea.MouseMsg) (*Plugin, tea.Cmd) {
 		return p.handleQuickOpenMouse(msg)
 	}
 
+	// Handle symbol palette if active
+	if p.symbolMode {
+		return p.handleSymbolMouse(msg)
+	}
+
 	// Handle info modal if active
 	if p.infoMode {
 		return p.handleInfoModalMouse(msg)
@@ -193,6 +199,11 @@ func (p *Plugin) handleMouse(msg tea.MouseMsg) (*Plugin, tea.Cmd) {
 		return p.handleBlameModalMouse(msg)
 	}
 
+	// Trash, history and compare views are keyboard-only; swallow mouse events
+	if p.trashMode || p.historyMode || p.compare != nil {
+		return p, nil
+	}
+
 	action := p.mouseHandler.HandleMouse(msg)
 
 	switch action.Type {
@@ -305,6 +316,7 @@ func (p *Plugin) handleMouseClick(action mouse.MouseAction) (*Plugin, tea.Cmd) {
 		if p.fileOpMode != FileOpNone {
 			p.fileOpMode = FileOpNone
 			p.fileOpTarget = nil
+			p.fileOpBatch = nil
 			p.fileOpError = ""
 			p.fileOpShowSuggestions = false
 			p.fileOpConfirmDelete = false
@@ -388,6 +400,18 @@ func (p *Plugin) handleMouseScroll(action mouse.MouseAction) (*Plugin, tea.Cmd)
 		return p, p.loadPreviewForCursor()
 	}
 
+	if p.tailActive() {
+		p.tail.scroll(delta, p.tailBodyHeight())
+		return p, nil
+	}
+
+	// Structured views scroll by moving their cursor
+	if p.structuredActive() && p.structured != nil {
+		p.structured.MoveCursor(delta)
+		p.structured.EnsureVisible(p.structuredBodyHeight())
+		return p, nil
+	}
+
 	// Scroll preview pane
 	lines := p.getPreviewLines()
 	visibleHeight := p.visibleContentHeight()
@@ -537,6 +561,44 @@ func (p *Plugin) handleQuickOpenMouse(msg tea.MouseMsg) (*Plugin, tea.Cmd) {
 	return p, nil
 }
 
+// handleSymbolMouse handles mouse events in the symbol palette.
+func (p *Plugin) handleSymbolMouse(msg tea.MouseMsg) (*Plugin, tea.Cmd) {
+	action := p.mouseHandler.HandleMouse(msg)
+
+	switch action.Type {
+	case mouse.ActionClick:
+		if action.Region != nil && action.Region.ID == regionSymbol {
+			if idx, ok := action.Region.Data.(int); ok {
+				p.symbolCursor = idx
+			}
+		}
+
+	case mouse.ActionDoubleClick:
+		if action.Region != nil && action.Region.ID == regionSymbol {
+			if idx, ok := action.Region.Data.(int); ok {
+				p.symbolCursor = idx
+				plug, cmd := p.selectSymbolMatch()
+				return plug.(*Plugin), cmd
+			}
+		}
+
+	case mouse.ActionScrollUp, mouse.ActionScrollDown:
+		delta := 3
+		if action.Type == mouse.ActionScrollUp {
+			delta = -3
+		}
+		p.symbolCursor += delta
+		if p.symbolCursor >= len(p.symbolMatches) {
+			p.symbolCursor = len(p.symbolMatches) - 1
+		}
+		if p.symbolCursor < 0 {
+			p.symbolCursor = 0
+		}
+	}
+
+	return p, nil
+}
+
 // handleProjectSearchMouse handles mouse events in project search modal.
 func (p *Plugin) handleProjectSearchMouse(msg tea.MouseMsg) (*Plugin, tea.Cmd) {
 	p.ensureProjectSearchModal()
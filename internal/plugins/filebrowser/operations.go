@@ -18,6 +18,8 @@ import (
 	"github.com/charmbracelet/x/ansi"
 	"github.com/marcus/sidecar/internal/msg"
 	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/plugins/gitstatus"
+	"github.com/marcus/sidecar/internal/ui"
 )
 
 // openFile returns a command to open a file in the user's editor.
@@ -155,6 +157,14 @@ func validateFilename(name string) error {
 
 // executeFileOp performs the pending file operation.
 func (p *Plugin) executeFileOp() (plugin.Plugin, tea.Cmd) {
+	if p.fileOpMode == FileOpGlobSelect || len(p.fileOpBatch) > 0 {
+		return p.executeBatchOp()
+	}
+
+	if p.fileOpMode == FileOpCompare {
+		return p.executeCompareInput()
+	}
+
 	input := p.fileOpTextInput.Value()
 
 	// Handle create operations
@@ -359,12 +369,13 @@ func (p *Plugin) doDelete() tea.Cmd {
 			return FileOpErrorMsg{Err: fmt.Errorf("cannot delete project root")}
 		}
 
-		// Remove file or directory (recursively for directories)
-		if err := os.RemoveAll(fullPath); err != nil {
+		// Move to the trash so the delete can be undone
+		item, err := p.projectTrash().Put(fullPath)
+		if err != nil {
 			return FileOpErrorMsg{Err: err}
 		}
 
-		return DeleteSuccessMsg{Path: fullPath}
+		return DeleteSuccessMsg{Path: fullPath, Item: item}
 	}
 }
 
@@ -674,10 +685,312 @@ func (p *Plugin) selectQuickOpenMatch() (plugin.Plugin, tea.Cmd) {
 	return p, cmd
 }
 
+// openSymbolOutline extracts the previewed file's symbols in the background;
+// the palette opens when they arrive (see applySymbolOutline).
+func (p *Plugin) openSymbolOutline() (plugin.Plugin, tea.Cmd) {
+	return p, p.loadSymbolOutline("")
+}
+
+// loadSymbolOutline extracts the symbols of the previewed file. query is
+// kept as the palette query once the outline opens.
+func (p *Plugin) loadSymbolOutline(query string) tea.Cmd {
+	if p.previewFile == "" || p.isBinary || len(p.previewLines) == 0 {
+		return nil
+	}
+	epoch := p.ctx.Epoch
+	workDir, rel := p.ctx.WorkDir, p.previewFile
+	line := p.getCurrentPreviewLine() + 1
+	src := []byte(strings.Join(p.previewLines, "\n"))
+	return func() tea.Msg {
+		syms, err := ExtractSymbols(workDir, rel, src)
+		return SymbolOutlineMsg{Epoch: epoch, Path: rel, Line: line, Query: query, Symbols: syms, Err: err}
+	}
+}
+
+// applySymbolOutline opens the symbol palette on a file's symbols, with the
+// cursor on the symbol enclosing the line the outline was requested from.
+func (p *Plugin) applySymbolOutline(m SymbolOutlineMsg) tea.Cmd {
+	if m.Path != p.previewFile {
+		return nil // Preview moved on while extracting
+	}
+	if m.Err != nil || len(m.Symbols) == 0 {
+		return msg.ShowToast("No symbols in "+filepath.Base(m.Path), 2*time.Second)
+	}
+
+	p.symbolMode = true
+	p.symbolScope = SymbolScopeFile
+	p.symbolQuery = m.Query
+	p.symbolSource = m.Symbols
+	p.symbolCursor = 0
+	p.updateSymbolMatches()
+	if m.Query == "" {
+		if i := SymbolAtLine(m.Symbols, m.Line); i >= 0 && i < len(p.symbolMatches) {
+			p.symbolCursor = i
+		}
+	}
+	return nil
+}
+
+// openProjectSymbols opens the symbol palette on the project index,
+// building it first if needed.
+func (p *Plugin) openProjectSymbols() (plugin.Plugin, tea.Cmd) {
+	p.symbolMode = true
+	p.symbolScope = SymbolScopeProject
+	p.symbolQuery = ""
+	p.symbolSource = nil
+	if p.symbolIndex != nil {
+		p.symbolSource = p.symbolIndex.Symbols
+	}
+	p.symbolCursor = 0
+	p.updateSymbolMatches()
+	return p, p.ensureSymbolIndex()
+}
+
+// toggleSymbolScope switches the palette between the file outline and
+// project symbols, keeping the query.
+func (p *Plugin) toggleSymbolScope() (plugin.Plugin, tea.Cmd) {
+	query := p.symbolQuery
+	if p.symbolScope != SymbolScopeFile {
+		// The palette switches once the outline loads; with no symbols in
+		// the file it stays on project symbols
+		return p, p.loadSymbolOutline(query)
+	}
+	_, cmd := p.openProjectSymbols()
+	p.symbolQuery = query
+	p.updateSymbolMatches()
+	return p, cmd
+}
+
+// ensureSymbolIndex starts a background index build when there is no index
+// or files changed since the last build.
+func (p *Plugin) ensureSymbolIndex() tea.Cmd {
+	if p.symbolIndexing || (p.symbolIndex != nil && !p.symbolIndexStale) {
+		return nil
+	}
+	p.symbolIndexing = true
+	p.symbolIndexStale = false
+	return BuildSymbolIndex(p.ctx.WorkDir, p.ctx.Epoch)
+}
+
+// applySymbolIndex stores a built index, refreshes an open project palette
+// and resolves a go-to-definition that was waiting for it.
+func (p *Plugin) applySymbolIndex(m SymbolIndexMsg) tea.Cmd {
+	p.symbolIndexing = false
+	if m.Err != nil {
+		p.ctx.Logger.Error("file browser: symbol index failed", "error", m.Err)
+		p.symbolPending = nil
+		return msg.ShowToast("Symbol index failed: "+m.Err.Error(), 3*time.Second)
+	}
+	p.symbolIndex = m.Index
+
+	if p.symbolMode && p.symbolScope == SymbolScopeProject {
+		p.symbolSource = m.Index.Symbols
+		p.updateSymbolMatches()
+	}
+	if req := p.symbolPending; req != nil {
+		p.symbolPending = nil
+		_, cmd := p.resolveDefinition(*req)
+		return cmd
+	}
+	return nil
+}
+
+// updateSymbolMatches filters the palette's symbols by the query.
+func (p *Plugin) updateSymbolMatches() {
+	p.symbolMatches = FilterSymbols(p.symbolSource, p.symbolQuery, symbolMaxResults)
+	if p.symbolCursor >= len(p.symbolMatches) {
+		p.symbolCursor = len(p.symbolMatches) - 1
+	}
+	if p.symbolCursor < 0 {
+		p.symbolCursor = 0
+	}
+}
+
+// closeSymbolPalette exits the symbol palette.
+func (p *Plugin) closeSymbolPalette() {
+	p.symbolMode = false
+	p.symbolQuery = ""
+	p.symbolSource = nil
+	p.symbolMatches = nil
+	p.symbolCursor = 0
+}
+
+// selectSymbolMatch jumps to the selected palette symbol.
+func (p *Plugin) selectSymbolMatch() (plugin.Plugin, tea.Cmd) {
+	if p.symbolCursor >= len(p.symbolMatches) {
+		return p, nil
+	}
+	sym := p.symbolMatches[p.symbolCursor].Symbol
+	p.closeSymbolPalette()
+	return p.jumpToSymbol(sym)
+}
+
+// jumpToSymbol opens a symbol's file at its line, remembering the current
+// location for jumpBack.
+func (p *Plugin) jumpToSymbol(sym Symbol) (plugin.Plugin, tea.Cmd) {
+	if p.previewFile != "" {
+		p.symbolJumpBack = append(p.symbolJumpBack, symbolLocation{Path: p.previewFile, Line: p.previewScroll + 1})
+	}
+	return p, p.openLocation(sym.Path, sym.Line)
+}
+
+// jumpBack returns to the location before the last symbol jump.
+func (p *Plugin) jumpBack() (plugin.Plugin, tea.Cmd) {
+	n := len(p.symbolJumpBack)
+	if n == 0 {
+		return p, nil
+	}
+	loc := p.symbolJumpBack[n-1]
+	p.symbolJumpBack = p.symbolJumpBack[:n-1]
+	return p, p.openLocation(loc.Path, loc.Line)
+}
+
+// openLocation reveals path in the tree and shows it in the preview
+// scrolled to line (1-indexed).
+func (p *Plugin) openLocation(path string, line int) tea.Cmd {
+	p.selection.Clear()
+	if targetNode := p.findAndExpandPath(path); targetNode != nil {
+		p.tree.Flatten()
+		if idx := p.tree.IndexOf(targetNode); idx >= 0 {
+			p.treeCursor = idx
+			p.ensureTreeCursorVisible()
+		}
+	}
+
+	p.activePane = PanePreview
+	cmd := p.openTabAtLine(path, line, TabOpenReplace)
+	p.pinTab(p.activeTab)
+	return cmd
+}
+
+// goToDefinition finds the definition of the identifier under the selection
+// or current content search match, otherwise of any identifier on the
+// current preview line.
+func (p *Plugin) goToDefinition() (plugin.Plugin, tea.Cmd) {
+	if p.previewFile == "" || len(p.previewLines) == 0 {
+		return p, nil
+	}
+	lineIdx := p.getCurrentPreviewLine()
+	line := p.previewLines[lineIdx]
+
+	var words []string
+	switch {
+	case p.selection.HasSelection():
+		// Selection columns are in tab-expanded display space
+		if w := identifierAt(ui.ExpandTabs(line, 8), p.selection.Start.Col); w != "" {
+			words = []string{w}
+		}
+	case p.contentSearchCommitted && p.contentSearchCursor < len(p.contentSearchMatches) &&
+		p.contentSearchMatches[p.contentSearchCursor].LineNo == lineIdx:
+		if w := identifierAt(line, p.contentSearchMatches[p.contentSearchCursor].StartCol); w != "" {
+			words = []string{w}
+		}
+	}
+	if words == nil {
+		words = lineIdentifiers(line)
+	}
+	if len(words) == 0 {
+		return p, msg.ShowToast("No identifier on this line", 2*time.Second)
+	}
+
+	req := definitionRequest{Words: words, Path: p.previewFile, Line: lineIdx + 1}
+	cmd := p.ensureSymbolIndex()
+	if p.symbolIndex == nil {
+		// Resolved when the index arrives
+		p.symbolPending = &req
+		return p, tea.Batch(cmd, msg.ShowToast("Indexing symbols...", 2*time.Second))
+	}
+	_, jumpCmd := p.resolveDefinition(req)
+	return p, tea.Batch(cmd, jumpCmd)
+}
+
+// resolveDefinition jumps to a single definition or lists several in the
+// symbol palette.
+func (p *Plugin) resolveDefinition(req definitionRequest) (plugin.Plugin, tea.Cmd) {
+	defs := FindDefinitions(p.symbolIndex, req.Words, req.Path, req.Line)
+	switch len(defs) {
+	case 0:
+		name := "this line"
+		if len(req.Words) == 1 {
+			name = req.Words[0]
+		}
+		return p, msg.ShowToast("No definition found for "+name, 2*time.Second)
+	case 1:
+		return p.jumpToSymbol(defs[0])
+	}
+
+	p.symbolMode = true
+	p.symbolScope = SymbolScopeDefinitions
+	p.symbolQuery = ""
+	p.symbolSource = defs
+	p.symbolCursor = 0
+	p.updateSymbolMatches()
+	return p, nil
+}
+
+// refreshDiffGutter reloads the git change gutter for the previewed file.
+func (p *Plugin) refreshDiffGutter() tea.Cmd {
+	if p.previewFile == "" || p.isBinary || p.isImage {
+		return nil
+	}
+	return LoadDiffGutter(p.ctx.WorkDir, p.previewFile, p.diffGutterBase, p.ctx.Epoch)
+}
+
+// currentDiffGutter returns the gutter if it belongs to the previewed file.
+func (p *Plugin) currentDiffGutter() *DiffGutter {
+	if p.diffGutter == nil || p.diffGutter.Path != p.previewFile {
+		return nil
+	}
+	return p.diffGutter
+}
+
+// changeRefLine returns the 0-indexed line change navigation and peek work
+// from: the last change jumped to while it is on screen, otherwise the
+// current preview line.
+func (p *Plugin) changeRefLine() int {
+	if line := p.diffChangeLine - 1; line >= 0 && line >= p.previewScroll && line < p.previewScroll+p.visibleContentHeight() {
+		return line
+	}
+	return p.getCurrentPreviewLine()
+}
+
+// jumpToChange scrolls to the next or previous run of changed lines,
+// placing it in the middle of the viewport.
+func (p *Plugin) jumpToChange(forward bool) {
+	g := p.currentDiffGutter()
+	if g == nil {
+		return
+	}
+	from := p.changeRefLine()
+	target := g.PrevChange(from)
+	if forward {
+		target = g.NextChange(from)
+	}
+	if target < 0 {
+		return
+	}
+	p.selection.Clear()
+	p.diffChangeLine = target + 1
+	p.previewScroll = target - p.visibleContentHeight()/2
+	p.clampPreviewScroll()
+}
+
+// currentDiffHunk returns the hunk under the change reference line.
+func (p *Plugin) currentDiffHunk() *gitstatus.Hunk {
+	g := p.currentDiffGutter()
+	if g == nil {
+		return nil
+	}
+	return g.HunkAt(p.changeRefLine())
+}
+
 // openProjectSearch enters project-wide search mode.
 func (p *Plugin) openProjectSearch() (plugin.Plugin, tea.Cmd) {
 	p.projectSearchMode = true
 	p.projectSearchState = NewProjectSearchState()
+	if p.ctx != nil && p.ctx.Config != nil {
+		p.projectSearchState.Backend = p.ctx.Config.Plugins.FileBrowser.SearchBackend
+	}
 	p.clearProjectSearchModal()
 	return p, nil
 }
@@ -1234,3 +1547,174 @@ func (p *Plugin) renderMarkdownContent() {
 	}
 	p.markdownRendered = p.markdownRenderer.RenderContent(content, width)
 }
+
+// structuredActive reports whether the preview shows the structured view:
+// when toggled on for a supported format, and always for SQLite databases,
+// which have no text preview.
+func (p *Plugin) structuredActive() bool {
+	kind := structuredKindFor(p.previewFile)
+	if kind == StructuredNone || p.isImage {
+		return false
+	}
+	return p.structuredMode || (kind == StructuredSQLite && p.isBinary)
+}
+
+// refreshStructured loads the structured view of the previewed file.
+func (p *Plugin) refreshStructured() tea.Cmd {
+	if !p.structuredActive() || p.ctx == nil {
+		return nil
+	}
+	p.structuredLoading = true
+	return LoadStructured(p.ctx.WorkDir, p.previewFile, p.ctx.Epoch)
+}
+
+// applyStructured installs a loaded view, keeping the place in the previous
+// view of the same file.
+func (p *Plugin) applyStructured(v *StructuredView, err error) tea.Cmd {
+	p.structuredLoading = false
+	p.structuredErr = err
+	if err != nil {
+		p.structured = nil
+		return nil
+	}
+	old := p.structured
+	v.inherit(old)
+	p.structured = v
+	if old != nil && old.Kind == StructuredSQLite && old.Path == v.Path &&
+		old.TableIdx < len(v.Tables) && (old.TableIdx > 0 || old.Page > 0) {
+		// Reload the table and page that were showing
+		v.TableIdx, v.Page = old.TableIdx, old.Page
+		return p.loadSQLitePage()
+	}
+	v.EnsureVisible(p.structuredBodyHeight())
+	return nil
+}
+
+// toggleStructuredView switches supported formats between text and the
+// structured view.
+func (p *Plugin) toggleStructuredView() tea.Cmd {
+	kind := structuredKindFor(p.previewFile)
+	if kind == StructuredNone {
+		return nil
+	}
+	if kind == StructuredSQLite && p.isBinary {
+		return msg.ShowToast("SQLite databases have no text view", 2*time.Second)
+	}
+	p.structuredMode = !p.structuredMode
+	if !p.structuredMode {
+		return nil
+	}
+	p.selection.Clear()
+	p.contentSearchMode = false
+	p.diffPeekOpen = false
+	if p.structured == nil || p.structured.Path != p.previewFile {
+		return p.refreshStructured()
+	}
+	return nil
+}
+
+// structuredBodyHeight returns the rows available for tree nodes or table
+// rows below the view's status line and table header.
+func (p *Plugin) structuredBodyHeight() int {
+	h := p.visibleContentHeight() - 1
+	if v := p.structured; v != nil && !v.Kind.IsTree() {
+		h -= 2
+	}
+	return max(h, 1)
+}
+
+// sqliteQuery returns the table, page and order being browsed.
+func (v *StructuredView) sqliteQuery() sqliteQuery {
+	q := sqliteQuery{Page: v.Page, Desc: v.SortDesc}
+	if v.TableIdx >= 0 && v.TableIdx < len(v.Tables) {
+		q.Table = v.Tables[v.TableIdx].Name
+	}
+	if v.Table != nil && v.SortCol >= 0 && v.SortCol < len(v.Table.Columns) {
+		q.OrderBy = v.Table.Columns[v.SortCol]
+	}
+	return q
+}
+
+// selectSQLiteTable moves to another table and loads its first page.
+func (p *Plugin) selectSQLiteTable(delta int) tea.Cmd {
+	v := p.structured
+	if v == nil || len(v.Tables) < 2 {
+		return nil
+	}
+	v.TableIdx = (v.TableIdx + delta + len(v.Tables)) % len(v.Tables)
+	v.Page = 0
+	v.SortCol, v.SortDesc = -1, false
+	return p.loadSQLitePage()
+}
+
+// changeSQLitePage moves to the next or previous page of the table.
+func (p *Plugin) changeSQLitePage(delta int) tea.Cmd {
+	v := p.structured
+	if v == nil || len(v.Tables) == 0 {
+		return nil
+	}
+	page := v.Page + delta
+	count := v.Tables[v.TableIdx].Count
+	if page < 0 || (count >= 0 && int64(page)*sqlitePageSize >= count) {
+		return nil
+	}
+	v.Page = page
+	return p.loadSQLitePage()
+}
+
+func (p *Plugin) loadSQLitePage() tea.Cmd {
+	v := p.structured
+	p.structuredLoading = true
+	// Count the table the first time it is shown
+	count := v.TableIdx < len(v.Tables) && v.Tables[v.TableIdx].Count < 0
+	return LoadSQLitePage(p.ctx.WorkDir, p.previewFile, v.sqliteQuery(), count, p.ctx.Epoch)
+}
+
+// applySQLitePage installs a page of rows if it is still the one wanted.
+func (p *Plugin) applySQLitePage(m SQLitePageMsg) {
+	v := p.structured
+	if v == nil || m.Path != p.previewFile || m.Query != v.sqliteQuery() {
+		return
+	}
+	p.structuredLoading = false
+	p.structuredErr = m.Err
+	if m.Err != nil {
+		return
+	}
+	if m.Count >= 0 {
+		v.Tables[v.TableIdx].Count = m.Count
+	}
+	// Keep the selected column when only the page or order changed
+	if v.Table == nil || len(v.Table.Columns) != len(m.Data.Columns) {
+		v.Column = 0
+	}
+	v.Cursor, v.Scroll = 0, 0
+	v.setTable(m.Data)
+}
+
+// copyStructuredValue copies the path of the tree node under the cursor, or
+// the selected table cell.
+func (p *Plugin) copyStructuredValue() tea.Cmd {
+	v := p.structured
+	if v == nil {
+		return nil
+	}
+	text, label := "", ""
+	if v.Kind.IsTree() {
+		n := v.CurrentNode()
+		if n == nil {
+			return nil
+		}
+		text, label = n.Path, n.Path
+	} else {
+		row := v.TableRow(v.Cursor)
+		if v.Column >= len(row) {
+			return nil
+		}
+		text, label = row[v.Column], "cell"
+	}
+	if err := clipboard.WriteAll(text); err != nil {
+		return msg.ShowToast("Copy failed: "+err.Error(), 2*time.Second)
+	}
+	return msg.ShowToast("Copied: "+label, 2*time.Second)
+}
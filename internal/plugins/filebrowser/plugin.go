@@ -1,6 +1,7 @@
 package filebrowser
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"time"
@@ -10,11 +11,15 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/marcus/sidecar/internal/app"
 	"github.com/marcus/sidecar/internal/image"
+	"github.com/marcus/sidecar/internal/localhistory"
 	"github.com/marcus/sidecar/internal/markdown"
 	"github.com/marcus/sidecar/internal/modal"
 	"github.com/marcus/sidecar/internal/mouse"
+	appmsg "github.com/marcus/sidecar/internal/msg"
 	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/plugins/gitstatus"
 	"github.com/marcus/sidecar/internal/state"
+	"github.com/marcus/sidecar/internal/trash"
 	"github.com/marcus/sidecar/internal/tty"
 	"github.com/marcus/sidecar/internal/ui"
 )
@@ -44,6 +49,8 @@ const (
 	FileOpCreateFile
 	FileOpCreateDir
 	FileOpDelete
+	FileOpGlobSelect
+	FileOpCompare
 )
 
 // Message types
@@ -58,9 +65,7 @@ type (
 	WatchStartedMsg struct{ Watcher *Watcher }
 	WatchEventMsg   struct{}
 	// NavigateToFileMsg requests navigation to a specific file (from other plugins).
-	NavigateToFileMsg struct {
-		Path string // Relative path from workdir
-	}
+	NavigateToFileMsg = appmsg.NavigateToFileMsg
 	// RevealErrorMsg is sent when reveal in file manager fails.
 	RevealErrorMsg struct {
 		Err error
@@ -79,9 +84,10 @@ type (
 		Path  string
 		IsDir bool
 	}
-	// DeleteSuccessMsg is sent when a file/directory is deleted.
+	// DeleteSuccessMsg is sent when a file/directory is moved to the trash.
 	DeleteSuccessMsg struct {
 		Path string
+		Item trash.Item
 	}
 	// PasteSuccessMsg is sent when a file/directory is pasted.
 	PasteSuccessMsg struct {
@@ -129,6 +135,12 @@ type Plugin struct {
 	previewModTime     time.Time
 	previewMode        os.FileMode
 
+	// Git change gutter state
+	diffGutter     *DiffGutter
+	diffGutterBase bool // Compare against the worktree base branch instead of HEAD
+	diffPeekOpen   bool // Show the original hunk below the preview
+	diffChangeLine int  // Last change jumped to (1-indexed, 0 = none)
+
 	// Tab state
 	tabs      []FileTab
 	activeTab int
@@ -142,6 +154,15 @@ type Plugin struct {
 	markdownRenderMode bool               // true=rendered, false=raw
 	markdownRendered   []string           // Cached rendered lines
 
+	// Structured view state (JSON/YAML tree, CSV/TSV table, SQLite tables)
+	structuredMode    bool            // Show supported formats structured instead of as text
+	structured        *StructuredView // Parsed view of the current file
+	structuredErr     error           // Parse or query error for the current file
+	structuredLoading bool
+
+	// Tail mode state (follow a growing file like tail -f)
+	tail *TailView // Followed file, nil when not tailing
+
 	// Image preview state
 	imageRenderer *image.Renderer     // Terminal graphics renderer
 	isImage       bool                // True if current preview is an image
@@ -179,11 +200,25 @@ type Plugin struct {
 	quickOpenFiles   []string // Cached file paths (relative)
 	quickOpenError   string   // Error message if scan failed/limited
 
+	// Symbol palette state (outline, project symbols, go-to-definition)
+	symbolMode       bool
+	symbolScope      SymbolScope
+	symbolQuery      string
+	symbolSource     []Symbol // Symbols listed for the current scope
+	symbolMatches    []SymbolMatch
+	symbolCursor     int
+	symbolIndex      *SymbolIndex       // Project index, nil until first built
+	symbolIndexing   bool               // True while an index build is running
+	symbolIndexStale bool               // Files changed since the index was built
+	symbolPending    *definitionRequest // Go-to-definition waiting for the index
+	symbolJumpBack   []symbolLocation   // Locations to return to (ctrl+o)
+
 	// Project-wide search state (ctrl+s)
 	projectSearchMode       bool
 	projectSearchState      *ProjectSearchState
 	projectSearchModal      *modal.Modal
 	projectSearchModalWidth int
+	lastReplace             *ReplaceSnapshot // Undo snapshot of the last project replace
 
 	// Info modal state
 	infoMode       bool
@@ -220,8 +255,43 @@ type Plugin struct {
 	fileOpShowSuggestions bool     // Show suggestions dropdown
 
 	// Clipboard state (yank/paste)
-	clipboardPath  string // Relative path of yanked file/directory
-	clipboardIsDir bool   // Whether yanked item is a directory
+	clipboardPath  string   // Relative path of yanked file/directory
+	clipboardIsDir bool     // Whether yanked item is a directory
+	clipboardPaths []string // Relative paths yanked from a multi-selection
+
+	// Multi-selection and batch operation state
+	selected      map[string]bool   // Relative paths selected in the tree
+	selectAnchor  string            // Path of the last toggled node, start of V ranges
+	fileOpBatch   []string          // Paths the pending file op applies to (batch mode)
+	batchProgress *BatchProgressMsg // Progress of the running batch, nil when idle
+	batchUndo     []batchTxn        // Completed batches, most recent last
+
+	// Trash view state
+	trash        *trash.Trash
+	trashMode    bool
+	trashItems   []trash.Item
+	trashCursor  int
+	trashErr     error
+	trashLoading bool
+	trashConfirm trashConfirm // Purge awaiting confirmation
+
+	// Local history timeline state
+	history           *localhistory.Store // nil when local history is disabled
+	historyMode       bool
+	historyPath       string // File whose timeline is shown
+	historySnaps      []localhistory.Snapshot
+	historyCursor     int
+	historyBase       int // Marked compare base, or historyCurrent
+	historyDiff       *gitstatus.ParsedDiff
+	historyDiffKey    string
+	historyDiffErr    error
+	historyDiffScroll int
+	historyErr        error
+	historyConfirm    bool // Restore awaiting confirmation
+
+	// Compare state
+	compareMark string       // Absolute path marked with =, empty when none
+	compare     *compareView // Open comparison, nil when closed
 
 	// File watcher
 	watcher     *Watcher
@@ -283,6 +353,17 @@ func (p *Plugin) Init(ctx *plugin.Context) error {
 
 	// Reset state flags for reinit support (project switching)
 	p.stateRestored = false
+	p.clearSelection()
+	p.clipboardPaths = nil
+	p.batchProgress = nil
+	p.batchUndo = nil
+	p.trash = trash.New(ctx.WorkDir)
+	p.closeTrashView()
+	p.history = localhistory.OpenFromConfig(ctx.WorkDir, ctx.Config)
+	p.closeHistoryView()
+	p.compareMark = ""
+	p.compare = nil
+	p.tail = nil
 
 	// Initialize markdown renderer
 	renderer, err := markdown.NewRenderer()
@@ -304,6 +385,7 @@ func (p *Plugin) Start() tea.Cmd {
 	return tea.Batch(
 		p.refresh(),
 		p.startWatcher(),
+		p.expireTrash(),
 	)
 }
 
@@ -518,7 +600,7 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 			return p, nil
 		}
 		p.lastRefresh = time.Now()
-		return p, p.refresh()
+		return p, tea.Batch(p.refresh(), p.refreshDiffGutter())
 
 	case tea.MouseMsg:
 		return p.handleMouse(msg)
@@ -541,6 +623,7 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		if msg.Err != nil {
 			p.ctx.Logger.Error("tree build failed", "error", msg.Err)
 		}
+		p.symbolIndexStale = true
 		// Handle pending auto-open from file creation
 		if p.pendingOpenFile != "" {
 			path := p.pendingOpenFile
@@ -627,6 +710,10 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		if plugin.IsStale(p.ctx, msg) {
 			return p, nil
 		}
+		if p.tail != nil && p.tail.Path != p.previewFile {
+			// Tail mode ends when another file is previewed
+			p.tail = nil
+		}
 		if msg.Path == p.previewFile {
 			p.applyPreviewResult(msg.Result)
 			p.updateActiveTabResult(msg.Result)
@@ -642,6 +729,48 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 				}
 			}
 		}
+		// Snapshot on open so the first change has a version to go back to
+		return p, tea.Batch(p.refreshDiffGutter(), p.refreshStructured(), p.snapshotHistory(msg.Path))
+
+	case TailReadMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		return p, p.applyTailRead(msg)
+
+	case StructuredLoadedMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		if msg.Path == p.previewFile {
+			return p, p.applyStructured(msg.View, msg.Err)
+		}
+
+	case SQLitePageMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		p.applySQLitePage(msg)
+
+	case DiffGutterLoadedMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		if msg.Path == p.previewFile {
+			p.diffGutter = msg.Gutter
+		}
+
+	case SymbolIndexMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		return p, p.applySymbolIndex(msg)
+
+	case SymbolOutlineMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		return p, p.applySymbolOutline(msg)
 
 	case RefreshMsg:
 		return p, p.refresh()
@@ -652,9 +781,16 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 
 	case WatchEventMsg:
 		// Watched file changed - reload preview (watcher only watches the previewed file)
+		p.symbolIndexStale = true
 		cmds := []tea.Cmd{p.listenForWatchEvents()}
+		if p.tailActive() {
+			// Read only the appended bytes instead of reloading the file
+			return p, tea.Batch(append(cmds, p.readTail())...)
+		}
 		if p.previewFile != "" {
-			cmds = append(cmds, LoadPreview(p.ctx.WorkDir, p.previewFile, p.ctx.Epoch))
+			cmds = append(cmds,
+				LoadPreview(p.ctx.WorkDir, p.previewFile, p.ctx.Epoch),
+				p.snapshotHistory(p.previewFile))
 		}
 		return p, tea.Batch(cmds...)
 
@@ -695,12 +831,104 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		p.fileOpConfirmDelete = false
 		// Clean up tabs for the deleted file/directory
 		p.closeTabsForPath(msg.Path)
-		return p, p.refresh()
+		// Record the delete so u can restore it like a batch
+		p.batchUndo = append(p.batchUndo, batchTxn{
+			Kind:  batchDelete,
+			Steps: []batchStep{{Src: msg.Item.OriginalPath, Dst: msg.Item.Path(), Item: &msg.Item}},
+			Trash: p.projectTrash(),
+		})
+		if len(p.batchUndo) > batchUndoLimit {
+			p.batchUndo = p.batchUndo[len(p.batchUndo)-batchUndoLimit:]
+		}
+		toast := appmsg.ShowToast("Moved to trash: "+filepath.Base(msg.Path)+" (u to undo, T to view)", 3*time.Second)
+		return p, tea.Batch(toast, p.refresh())
 
 	case PasteSuccessMsg:
 		// Refresh after paste
 		return p, p.refresh()
 
+	case BatchProgressMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		p.batchProgress = &msg
+		return p, msg.run.next
+
+	case BatchDoneMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		return p, p.applyBatchDone(msg)
+
+	case TrashListMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		p.applyTrashList(msg)
+		return p, nil
+
+	case TrashActionMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		return p, p.applyTrashAction(msg)
+
+	case HistorySnapshotMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		if p.historyMode && msg.Added && msg.Path == p.historyPath {
+			return p, p.loadHistory()
+		}
+		if p.historyMode && msg.Path == p.historyPath && p.historyBase == historyCurrent {
+			// Same content, new mtime: the current file may still differ
+			return p, p.loadHistoryDiff()
+		}
+
+	case HistoryListMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		return p, p.applyHistoryList(msg)
+
+	case CompareLoadedMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		p.applyCompareLoaded(msg)
+
+	case CompareDiffMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		p.applyCompareDiff(msg)
+
+	case HistoryDiffMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		p.applyHistoryDiff(msg)
+
+	case HistoryRestoredMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		return p, p.applyHistoryRestored(msg)
+
+	case TrashExpiredMsg:
+		if msg.Err != nil {
+			p.ctx.Logger.Warn("file browser: trash expiry failed", "error", msg.Err)
+		} else if msg.Purged > 0 {
+			p.ctx.Logger.Debug("file browser: expired trash items", "count", msg.Purged)
+		}
+		return p, nil
+
+	case GlobSelectMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		return p, p.applyGlobSelect(msg)
+
 	case GitInfoMsg:
 		p.gitStatus = msg.Status
 		p.gitLastCommit = msg.LastCommit
@@ -733,19 +961,74 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		if plugin.IsStale(p.ctx, msg) {
 			return p, nil
 		}
-		if p.projectSearchState != nil {
-			p.projectSearchState.IsSearching = false
-			if msg.Error != nil {
-				p.projectSearchState.Error = msg.Error.Error()
-				p.projectSearchState.Results = nil
-			} else {
-				p.projectSearchState.Error = ""
-				p.projectSearchState.Results = msg.Results
-				p.projectSearchState.ScrollOffset = 0
+		state := p.projectSearchState
+		if state == nil || msg.stream != state.stream {
+			// Superseded or closed search: stop it streaming
+			if msg.stream != nil {
+				msg.stream.cancel()
+			}
+			return p, nil
+		}
+		// Results stream in as cumulative snapshots; only reset the cursor
+		// for the first batch of a search so navigation isn't disturbed
+		firstBatch := msg.stream == nil || !msg.stream.delivered
+		if msg.stream != nil {
+			msg.stream.delivered = true
+		}
+		state.IsSearching = msg.Partial
+		state.ActiveBackend = msg.Backend
+		if !msg.Partial {
+			state.stream = nil
+		}
+		if msg.Error != nil {
+			state.Error = msg.Error.Error()
+			state.Results = nil
+		} else {
+			state.Error = ""
+			state.Results = msg.Results
+			if firstBatch {
+				state.ScrollOffset = 0
 				// Set cursor to first match (skip file headers)
-				p.projectSearchState.Cursor = p.projectSearchState.FirstMatchIndex()
+				state.Cursor = state.FirstMatchIndex()
+			}
+		}
+		if msg.Partial {
+			return p, msg.stream.next
+		}
+
+	case ProjectReplaceDoneMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		if msg.Err != nil {
+			return p, func() tea.Msg {
+				return app.ToastMsg{Message: "Replace failed: " + msg.Err.Error(), Duration: 3 * time.Second, IsError: true}
 			}
 		}
+		p.lastReplace = msg.Snapshot
+		lines := 0
+		for _, c := range msg.Snapshot.Changes {
+			lines += c.Lines
+		}
+		toast := fmt.Sprintf("Replaced %d line(s) in %d file(s) (ctrl+z to undo)", lines, len(msg.Snapshot.Changes))
+		return p, p.afterProjectReplace(msg.Snapshot.Paths(), toast)
+
+	case ProjectReplaceUndoneMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		if p.lastReplace == msg.Snapshot {
+			p.lastReplace = nil
+		}
+		if msg.Err != nil {
+			return p, tea.Batch(
+				p.afterProjectReplace(msg.Snapshot.Paths(), ""),
+				func() tea.Msg {
+					return app.ToastMsg{Message: "Undo incomplete: " + msg.Err.Error(), Duration: 3 * time.Second, IsError: true}
+				},
+			)
+		}
+		return p, p.afterProjectReplace(msg.Snapshot.Paths(), "Replace undone")
 
 	case InlineEditStartedMsg:
 		return p, p.handleInlineEditStarted(msg)
@@ -792,6 +1075,8 @@ func (p *Plugin) Commands() []plugin.Command {
 		{ID: "quick-open", Name: "Open", Description: "Quick open file by name", Category: plugin.CategorySearch, Context: "file-browser-tree", Priority: 1},
 		{ID: "new-tab", Name: "Tab+", Description: "Open file in new tab", Category: plugin.CategoryNavigation, Context: "file-browser-tree", Priority: 2},
 		{ID: "project-search", Name: "Find", Description: "Search in project", Category: plugin.CategorySearch, Context: "file-browser-tree", Priority: 2},
+		{ID: "symbol-outline", Name: "Outline", Description: "Symbols in previewed file", Category: plugin.CategorySearch, Context: "file-browser-tree", Priority: 3},
+		{ID: "project-symbols", Name: "Symbols", Description: "Jump to symbol in project", Category: plugin.CategorySearch, Context: "file-browser-tree", Priority: 3},
 		{ID: "info", Name: "Info", Description: "Show file info", Category: plugin.CategoryActions, Context: "file-browser-tree", Priority: 2},
 		{ID: "edit", Name: "Edit", Description: "Edit file inline", Category: plugin.CategoryActions, Context: "file-browser-tree", Priority: 2},
 		{ID: "edit-external", Name: "Edit+", Description: "Edit in full terminal", Category: plugin.CategoryActions, Context: "file-browser-tree", Priority: 2},
@@ -806,6 +1091,14 @@ func (p *Plugin) Commands() []plugin.Command {
 		{ID: "yank", Name: "Yank", Description: "Mark file for copy (use p to paste)", Category: plugin.CategoryActions, Context: "file-browser-tree", Priority: 5},
 		{ID: "copy-path", Name: "CopyPath", Description: "Copy relative path to clipboard", Category: plugin.CategoryActions, Context: "file-browser-tree", Priority: 5},
 		{ID: "paste", Name: "Paste", Description: "Paste yanked file", Category: plugin.CategoryActions, Context: "file-browser-tree", Priority: 5},
+		{ID: "toggle-select", Name: "Select", Description: "Toggle selection of file or directory", Category: plugin.CategoryActions, Context: "file-browser-tree", Priority: 5},
+		{ID: "select-range", Name: "Range", Description: "Select from last toggled item to cursor", Category: plugin.CategoryActions, Context: "file-browser-tree", Priority: 6},
+		{ID: "glob-select", Name: "Glob", Description: "Select files matching a glob", Category: plugin.CategoryActions, Context: "file-browser-tree", Priority: 6},
+		{ID: "undo-batch", Name: "Undo", Description: "Undo last multi-item operation", Category: plugin.CategoryActions, Context: "file-browser-tree", Priority: 6},
+		{ID: "clear-selection", Name: "Clear", Description: "Clear selection", Category: plugin.CategoryActions, Context: "file-browser-tree", Priority: 7},
+		{ID: "trash", Name: "Trash", Description: "View and restore deleted files", Category: plugin.CategoryView, Context: "file-browser-tree", Priority: 7},
+		{ID: "mark-compare", Name: "Compare", Description: "Mark for compare, or compare with marked item", Category: plugin.CategoryView, Context: "file-browser-tree", Priority: 7},
+		{ID: "compare-with", Name: "Compare with", Description: "Compare with a path", Category: plugin.CategoryView, Context: "file-browser-tree", Priority: 8},
 		{ID: "sort", Name: "Sort", Description: "Cycle sort mode", Category: plugin.CategoryActions, Context: "file-browser-tree", Priority: 6},
 		{ID: "refresh", Name: "Refresh", Description: "Refresh file tree", Category: plugin.CategoryActions, Context: "file-browser-tree", Priority: 6},
 		{ID: "rename", Name: "Rename", Description: "Rename file or directory", Category: plugin.CategoryActions, Context: "file-browser-tree", Priority: 7},
@@ -816,15 +1109,26 @@ func (p *Plugin) Commands() []plugin.Command {
 		// Preview pane commands
 		{ID: "quick-open", Name: "Open", Description: "Quick open file by name", Category: plugin.CategorySearch, Context: "file-browser-preview", Priority: 1},
 		{ID: "project-search", Name: "Find", Description: "Search in project", Category: plugin.CategorySearch, Context: "file-browser-preview", Priority: 2},
+		{ID: "goto-definition", Name: "Def", Description: "Go to definition of symbol on current line", Category: plugin.CategoryNavigation, Context: "file-browser-preview", Priority: 2},
+		{ID: "symbol-outline", Name: "Outline", Description: "Symbols in this file", Category: plugin.CategorySearch, Context: "file-browser-preview", Priority: 3},
+		{ID: "project-symbols", Name: "Symbols", Description: "Jump to symbol in project", Category: plugin.CategorySearch, Context: "file-browser-preview", Priority: 3},
+		{ID: "jump-back", Name: "Back", Description: "Return to location before last symbol jump", Category: plugin.CategoryNavigation, Context: "file-browser-preview", Priority: 4},
 		{ID: "info", Name: "Info", Description: "Show file info", Category: plugin.CategoryActions, Context: "file-browser-preview", Priority: 2},
 		{ID: "edit", Name: "Edit", Description: "Edit file inline", Category: plugin.CategoryActions, Context: "file-browser-preview", Priority: 2},
 		{ID: "edit-external", Name: "Edit+", Description: "Edit in full terminal", Category: plugin.CategoryActions, Context: "file-browser-preview", Priority: 2},
 		{ID: "prev-tab", Name: "Tab←", Description: "Previous tab", Category: plugin.CategoryNavigation, Context: "file-browser-preview", Priority: 3},
 		{ID: "next-tab", Name: "Tab→", Description: "Next tab", Category: plugin.CategoryNavigation, Context: "file-browser-preview", Priority: 3},
 		{ID: "blame", Name: "Blame", Description: "Show git blame", Category: plugin.CategoryView, Context: "file-browser-preview", Priority: 3},
+		{ID: "local-history", Name: "History", Description: "Show local history timeline", Category: plugin.CategoryView, Context: "file-browser-preview", Priority: 4},
+		{ID: "tail", Name: "Tail", Description: "Follow file as it grows", Category: plugin.CategoryView, Context: "file-browser-preview", Priority: 4},
 		{ID: "search-content", Name: "Search", Description: "Search file content", Category: plugin.CategorySearch, Context: "file-browser-preview", Priority: 3},
 		{ID: "toggle-wrap", Name: "Wrap", Description: "Toggle line wrapping", Category: plugin.CategoryView, Context: "file-browser-preview", Priority: 3},
 		{ID: "toggle-markdown", Name: "Render", Description: "Toggle markdown rendering", Category: plugin.CategoryActions, Context: "file-browser-preview", Priority: 4},
+		{ID: "toggle-structured", Name: "Struct", Description: "Show JSON/YAML as a tree and CSV/TSV as a table", Category: plugin.CategoryView, Context: "file-browser-preview", Priority: 4},
+		{ID: "next-change", Name: "Change↓", Description: "Jump to next git change", Category: plugin.CategoryGit, Context: "file-browser-preview", Priority: 4},
+		{ID: "prev-change", Name: "Change↑", Description: "Jump to previous git change", Category: plugin.CategoryGit, Context: "file-browser-preview", Priority: 4},
+		{ID: "peek-change", Name: "Peek", Description: "Show original lines of the change", Category: plugin.CategoryGit, Context: "file-browser-preview", Priority: 5},
+		{ID: "toggle-diff-base", Name: "Base", Description: "Compare changes against HEAD or the base branch", Category: plugin.CategoryGit, Context: "file-browser-preview", Priority: 6},
 		{ID: "close-tab", Name: "Close", Description: "Close active tab", Category: plugin.CategoryActions, Context: "file-browser-preview", Priority: 4},
 		{ID: "back", Name: "Back", Description: "Return to file tree", Category: plugin.CategoryNavigation, Context: "file-browser-preview", Priority: 5},
 		{ID: "refresh", Name: "Refresh", Description: "Refresh file tree", Category: plugin.CategoryActions, Context: "file-browser-preview", Priority: 5},
@@ -834,6 +1138,21 @@ func (p *Plugin) Commands() []plugin.Command {
 		{ID: "yank-path", Name: "Path", Description: "Copy file path", Category: plugin.CategoryActions, Context: "file-browser-preview", Priority: 8},
 		{ID: "toggle-sidebar", Name: "Sidebar", Description: "Toggle tree pane visibility", Category: plugin.CategoryView, Context: "file-browser-preview", Priority: 9},
 		{ID: "toggle-ignored", Name: "Ignored", Description: "Toggle git-ignored file visibility", Category: plugin.CategoryView, Context: "file-browser-preview", Priority: 9},
+		// Tail mode commands
+		{ID: "toggle-follow", Name: "Follow", Description: "Pause or resume following new lines", Category: plugin.CategoryView, Context: "file-browser-tail", Priority: 1},
+		{ID: "filter-tail", Name: "Filter", Description: "Show only lines matching a regexp", Category: plugin.CategorySearch, Context: "file-browser-tail", Priority: 2},
+		{ID: "tail", Name: "Stop", Description: "Stop tailing and show the file", Category: plugin.CategoryView, Context: "file-browser-tail", Priority: 3},
+		{ID: "back", Name: "Back", Description: "Return to file tree", Category: plugin.CategoryNavigation, Context: "file-browser-tail", Priority: 5},
+
+		// Structured view commands (JSON/YAML tree, CSV/TSV table, SQLite tables)
+		{ID: "toggle-node", Name: "Fold", Description: "Expand/collapse node", Category: plugin.CategoryView, Context: "file-browser-structured", Priority: 1},
+		{ID: "copy-path", Name: "Path", Description: "Copy path of node, or cell value", Category: plugin.CategoryActions, Context: "file-browser-structured", Priority: 2},
+		{ID: "sort-column", Name: "Sort", Description: "Sort table by selected column", Category: plugin.CategoryView, Context: "file-browser-structured", Priority: 2},
+		{ID: "next-table", Name: "Table", Description: "Next SQLite table", Category: plugin.CategoryNavigation, Context: "file-browser-structured", Priority: 3},
+		{ID: "next-page", Name: "Page→", Description: "Next page of rows", Category: plugin.CategoryNavigation, Context: "file-browser-structured", Priority: 3},
+		{ID: "prev-page", Name: "Page←", Description: "Previous page of rows", Category: plugin.CategoryNavigation, Context: "file-browser-structured", Priority: 3},
+		{ID: "toggle-structured", Name: "Raw", Description: "Show file as text", Category: plugin.CategoryView, Context: "file-browser-structured", Priority: 4},
+		{ID: "back", Name: "Back", Description: "Return to file tree", Category: plugin.CategoryNavigation, Context: "file-browser-structured", Priority: 5},
 		// Tree search commands
 		{ID: "confirm", Name: "Go", Description: "Jump to match", Category: plugin.CategoryNavigation, Context: "file-browser-search", Priority: 1},
 		{ID: "cancel", Name: "Cancel", Description: "Cancel search", Category: plugin.CategoryActions, Context: "file-browser-search", Priority: 1},
@@ -843,10 +1162,22 @@ func (p *Plugin) Commands() []plugin.Command {
 		// Quick open commands
 		{ID: "select", Name: "Open", Description: "Open selected file", Category: plugin.CategoryActions, Context: "file-browser-quick-open", Priority: 1},
 		{ID: "cancel", Name: "Cancel", Description: "Cancel quick open", Category: plugin.CategoryActions, Context: "file-browser-quick-open", Priority: 1},
+		// Symbol palette commands
+		{ID: "select", Name: "Go", Description: "Jump to symbol", Category: plugin.CategoryNavigation, Context: "file-browser-symbols", Priority: 1},
+		{ID: "cancel", Name: "Cancel", Description: "Close symbol list", Category: plugin.CategoryActions, Context: "file-browser-symbols", Priority: 1},
+		{ID: "toggle-scope", Name: "Scope", Description: "Switch between file and project symbols", Category: plugin.CategoryView, Context: "file-browser-symbols", Priority: 2},
 		// Project search commands
 		{ID: "select", Name: "Open", Description: "Open selected result", Category: plugin.CategoryActions, Context: "file-browser-project-search", Priority: 1},
 		{ID: "toggle", Name: "Toggle", Description: "Expand/collapse file", Category: plugin.CategoryActions, Context: "file-browser-project-search", Priority: 2},
 		{ID: "cancel", Name: "Close", Description: "Close search", Category: plugin.CategoryActions, Context: "file-browser-project-search", Priority: 3},
+		{ID: "toggle-replace", Name: "Replace", Description: "Toggle replace mode", Category: plugin.CategoryEdit, Context: "file-browser-project-search", Priority: 3},
+		// Project replace commands
+		{ID: "apply-replace", Name: "Apply", Description: "Replace selected matches", Category: plugin.CategoryEdit, Context: "file-browser-project-replace", Priority: 1},
+		{ID: "exclude-match", Name: "Skip", Description: "Include/exclude selected match", Category: plugin.CategoryEdit, Context: "file-browser-project-replace", Priority: 2},
+		{ID: "exclude-file", Name: "Skip file", Description: "Include/exclude all matches in file", Category: plugin.CategoryEdit, Context: "file-browser-project-replace", Priority: 3},
+		{ID: "switch-field", Name: "Field", Description: "Switch between search and replace fields", Category: plugin.CategoryNavigation, Context: "file-browser-project-replace", Priority: 3},
+		{ID: "undo-replace", Name: "Undo", Description: "Undo last replace", Category: plugin.CategoryEdit, Context: "file-browser-project-replace", Priority: 3},
+		{ID: "toggle-replace", Name: "Search", Description: "Leave replace mode", Category: plugin.CategoryEdit, Context: "file-browser-project-replace", Priority: 4},
 		// File operation commands (move/rename/create/delete)
 		{ID: "confirm", Name: "Confirm", Description: "Confirm operation", Category: plugin.CategoryActions, Context: "file-browser-file-op", Priority: 1},
 		{ID: "cancel", Name: "Cancel", Description: "Cancel operation", Category: plugin.CategoryActions, Context: "file-browser-file-op", Priority: 1},
@@ -859,6 +1190,19 @@ func (p *Plugin) Commands() []plugin.Command {
 		{ID: "close", Name: "Close", Description: "Close blame view", Category: plugin.CategoryActions, Context: "file-browser-blame", Priority: 1},
 		{ID: "view-commit", Name: "Details", Description: "View commit details", Category: plugin.CategoryActions, Context: "file-browser-blame", Priority: 2},
 		{ID: "yank-hash", Name: "Yank", Description: "Copy commit hash", Category: plugin.CategoryActions, Context: "file-browser-blame", Priority: 3},
+		// Local history timeline commands
+		{ID: "restore", Name: "Restore", Description: "Restore this version", Category: plugin.CategoryActions, Context: "file-browser-history", Priority: 1},
+		{ID: "mark-base", Name: "Mark", Description: "Compare against this version", Category: plugin.CategoryActions, Context: "file-browser-history", Priority: 2},
+		{ID: "close", Name: "Close", Description: "Close local history", Category: plugin.CategoryActions, Context: "file-browser-history", Priority: 1},
+		// Trash view commands
+		{ID: "open-diff", Name: "Diff", Description: "Show diff of this file", Category: plugin.CategoryActions, Context: "file-browser-compare", Priority: 1},
+		{ID: "toggle-diff-view", Name: "View", Description: "Toggle side-by-side and unified diff", Category: plugin.CategoryView, Context: "file-browser-compare", Priority: 2},
+		{ID: "next-file", Name: "Next", Description: "Diff next file", Category: plugin.CategoryNavigation, Context: "file-browser-compare", Priority: 3},
+		{ID: "close", Name: "Close", Description: "Close compare view", Category: plugin.CategoryActions, Context: "file-browser-compare", Priority: 1},
+		{ID: "restore", Name: "Restore", Description: "Restore to original location", Category: plugin.CategoryActions, Context: "file-browser-trash", Priority: 1},
+		{ID: "purge", Name: "Delete", Description: "Permanently delete item", Category: plugin.CategoryActions, Context: "file-browser-trash", Priority: 2},
+		{ID: "empty", Name: "Empty", Description: "Permanently delete all items", Category: plugin.CategoryActions, Context: "file-browser-trash", Priority: 3},
+		{ID: "close", Name: "Close", Description: "Close trash view", Category: plugin.CategoryActions, Context: "file-browser-trash", Priority: 1},
 	}
 }
 
@@ -868,17 +1212,32 @@ func (p *Plugin) FocusContext() string {
 		return "file-browser-inline-edit"
 	}
 	if p.projectSearchMode {
+		if p.projectSearchState != nil && p.projectSearchState.ReplaceMode {
+			return "file-browser-project-replace"
+		}
 		return "file-browser-project-search"
 	}
 	if p.quickOpenMode {
 		return "file-browser-quick-open"
 	}
+	if p.symbolMode {
+		return "file-browser-symbols"
+	}
 	if p.infoMode {
 		return "file-browser-info"
 	}
 	if p.blameMode {
 		return "file-browser-blame"
 	}
+	if p.trashMode {
+		return "file-browser-trash"
+	}
+	if p.historyMode {
+		return "file-browser-history"
+	}
+	if p.compare != nil {
+		return "file-browser-compare"
+	}
 	if p.fileOpMode != FileOpNone {
 		return "file-browser-file-op"
 	}
@@ -892,6 +1251,12 @@ func (p *Plugin) FocusContext() string {
 		return "file-browser-search"
 	}
 	if p.activePane == PanePreview {
+		if p.structuredActive() {
+			return "file-browser-structured"
+		}
+		if p.tailActive() {
+			return "file-browser-tail"
+		}
 		return "file-browser-preview"
 	}
 	return "file-browser-tree"
@@ -903,8 +1268,10 @@ func (p *Plugin) ConsumesTextInput() bool {
 	return p.searchMode ||
 		p.contentSearchMode ||
 		p.quickOpenMode ||
+		p.symbolMode ||
 		p.projectSearchMode ||
 		p.fileOpMode != FileOpNone ||
 		p.lineJumpMode ||
-		p.inlineEditMode
+		p.inlineEditMode ||
+		(p.tail != nil && p.tail.Editing)
 }
@@ -0,0 +1,428 @@
+package filebrowser
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+)
+
+// FilesChangedMsg is broadcast after the file browser rewrites files on disk
+// (project replace and its undo) so other plugins can refresh.
+type FilesChangedMsg = appmsg.FilesChangedMsg
+
+// compileSearchPattern builds a Go regexp equivalent to the ripgrep flags
+// for the given search options.
+func compileSearchPattern(query string, useRegex, caseSensitive, wholeWord bool) (*regexp.Regexp, error) {
+	pattern := query
+	if !useRegex {
+		pattern = regexp.QuoteMeta(query)
+	}
+	if wholeWord {
+		pattern = `\b(?:` + pattern + `)\b`
+	}
+	if !caseSensitive {
+		pattern = `(?i)` + pattern
+	}
+	return regexp.Compile(pattern)
+}
+
+// replacePattern returns the compiled pattern for the state's query and options.
+func (s *ProjectSearchState) replacePattern() (*regexp.Regexp, error) {
+	if s.Query == "" {
+		return nil, fmt.Errorf("empty search")
+	}
+	return compileSearchPattern(s.Query, s.UseRegex, s.CaseSensitive, s.WholeWord)
+}
+
+// matchSpan returns the submatch indexes of the occurrence of re in line
+// that starts at byte column col, or nil if none does.
+func matchSpan(re *regexp.Regexp, line string, col int) []int {
+	for _, loc := range re.FindAllStringSubmatchIndex(line, -1) {
+		if loc[0] == col && loc[1] > loc[0] {
+			return loc
+		}
+		if loc[0] > col {
+			break
+		}
+	}
+	return nil
+}
+
+// expandReplacement returns the text replacing the occurrence at loc. With
+// regex enabled, $1 / ${name} in repl expand to capture groups.
+func expandReplacement(re *regexp.Regexp, line, repl string, useRegex bool, loc []int) string {
+	if useRegex {
+		return string(re.ExpandString(nil, repl, line, loc))
+	}
+	return repl
+}
+
+// replaceSpans replaces the given occurrences of re in line, leaving every
+// other occurrence alone. spans must be in ascending order.
+func replaceSpans(re *regexp.Regexp, line, repl string, useRegex bool, spans [][]int) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range spans {
+		b.WriteString(line[last:loc[0]])
+		b.WriteString(expandReplacement(re, line, repl, useRegex, loc))
+		last = loc[1]
+	}
+	b.WriteString(line[last:])
+	return b.String()
+}
+
+// ReplaceSegment is one piece of a match line's replace preview.
+type ReplaceSegment struct {
+	Text    string
+	Removed bool // Original text being replaced
+	Added   bool // Replacement text
+}
+
+// previewReplaceLine splits line into unchanged, removed and added segments
+// for the occurrence starting at byte column col. Other occurrences on the
+// line are separate matches and stay unchanged.
+func previewReplaceLine(re *regexp.Regexp, line, repl string, useRegex bool, col int) []ReplaceSegment {
+	loc := matchSpan(re, line, col)
+	if loc == nil {
+		return []ReplaceSegment{{Text: line}}
+	}
+	var segs []ReplaceSegment
+	if loc[0] > 0 {
+		segs = append(segs, ReplaceSegment{Text: line[:loc[0]]})
+	}
+	segs = append(segs, ReplaceSegment{Text: line[loc[0]:loc[1]], Removed: true})
+	if added := expandReplacement(re, line, repl, useRegex, loc); added != "" {
+		segs = append(segs, ReplaceSegment{Text: added, Added: true})
+	}
+	if loc[1] < len(line) {
+		segs = append(segs, ReplaceSegment{Text: line[loc[1]:]})
+	}
+	return segs
+}
+
+// ToggleMatch includes/excludes the match at the cursor from replacement.
+func (s *ProjectSearchState) ToggleMatch() {
+	fileIdx, matchIdx, isFile := s.FlatItem(s.Cursor)
+	if fileIdx < 0 {
+		return
+	}
+	if isFile {
+		s.ToggleFile()
+		return
+	}
+	m := &s.Results[fileIdx].Matches[matchIdx]
+	m.Excluded = !m.Excluded
+}
+
+// ToggleFile includes/excludes every match of the file at the cursor. If any
+// match is included, all are excluded; otherwise all are included.
+func (s *ProjectSearchState) ToggleFile() {
+	fileIdx, _, _ := s.FlatItem(s.Cursor)
+	if fileIdx < 0 {
+		return
+	}
+	file := &s.Results[fileIdx]
+	exclude := file.IncludedCount() > 0
+	for i := range file.Matches {
+		file.Matches[i].Excluded = exclude
+	}
+}
+
+// IncludedCount returns the number of matches selected for replacement.
+func (f *SearchFileResult) IncludedCount() int {
+	n := 0
+	for _, m := range f.Matches {
+		if !m.Excluded {
+			n++
+		}
+	}
+	return n
+}
+
+// IncludedMatches returns the matches and files selected for replacement.
+func (s *ProjectSearchState) IncludedMatches() (matches, files int) {
+	for i := range s.Results {
+		if n := s.Results[i].IncludedCount(); n > 0 {
+			matches += n
+			files++
+		}
+	}
+	return matches, files
+}
+
+// ReplaceFileChange is the planned rewrite of one file.
+type ReplaceFileChange struct {
+	Path  string // Relative to the project root
+	Old   []byte
+	New   []byte
+	Mode  os.FileMode
+	Lines int // Number of lines rewritten
+}
+
+// ReplaceSnapshot holds the original contents of files rewritten by a
+// replace, so the operation can be undone.
+type ReplaceSnapshot struct {
+	Query       string
+	Replacement string
+	Changes     []ReplaceFileChange
+}
+
+// Paths returns the relative paths touched by the snapshot.
+func (s *ReplaceSnapshot) Paths() []string {
+	paths := make([]string, len(s.Changes))
+	for i, c := range s.Changes {
+		paths[i] = c.Path
+	}
+	return paths
+}
+
+// PlanProjectReplace computes the new contents of every file with selected
+// matches. It fails if a file changed since the search ran.
+func PlanProjectReplace(workDir string, state *ProjectSearchState) ([]ReplaceFileChange, error) {
+	re, err := state.replacePattern()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []ReplaceFileChange
+	for _, file := range state.Results {
+		if file.IncludedCount() == 0 {
+			continue
+		}
+		fullPath, err := safeProjectPath(workDir, file.Path)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(fullPath)
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(fullPath)
+		if err != nil {
+			return nil, err
+		}
+
+		// Each match is one occurrence; collect the selected spans per line
+		lines := bytes.SplitAfter(data, []byte("\n"))
+		spans := make(map[int][][]int)
+		for _, m := range file.Matches {
+			if m.Excluded {
+				continue
+			}
+			idx := m.LineNo - 1
+			if idx < 0 || idx >= len(lines) {
+				return nil, fmt.Errorf("%s changed since search (line %d missing)", file.Path, m.LineNo)
+			}
+			body := strings.TrimRight(string(lines[idx]), "\r\n")
+			if body != m.LineText {
+				return nil, fmt.Errorf("%s changed since search (line %d differs)", file.Path, m.LineNo)
+			}
+			loc := matchSpan(re, body, m.ColStart)
+			if loc == nil {
+				return nil, fmt.Errorf("%s changed since search (no match at line %d, column %d)", file.Path, m.LineNo, m.ColStart+1)
+			}
+			spans[idx] = append(spans[idx], loc)
+		}
+
+		rewritten := 0
+		for idx, locs := range spans {
+			sort.Slice(locs, func(i, j int) bool { return locs[i][0] < locs[j][0] })
+			// Drop duplicates and overlaps so each byte is replaced once
+			kept := locs[:1]
+			for _, loc := range locs[1:] {
+				if loc[0] >= kept[len(kept)-1][1] {
+					kept = append(kept, loc)
+				}
+			}
+			raw := string(lines[idx])
+			body := strings.TrimRight(raw, "\r\n")
+			newBody := replaceSpans(re, body, state.Replacement, state.UseRegex, kept)
+			if newBody != body {
+				lines[idx] = []byte(newBody + raw[len(body):])
+				rewritten++
+			}
+		}
+		if rewritten == 0 {
+			continue
+		}
+		changes = append(changes, ReplaceFileChange{
+			Path:  file.Path,
+			Old:   data,
+			New:   bytes.Join(lines, nil),
+			Mode:  info.Mode().Perm(),
+			Lines: rewritten,
+		})
+	}
+	return changes, nil
+}
+
+// ApplyReplaceChanges writes all changes or none: each file is written to a
+// temp file first, then renamed into place. If a rename fails, files already
+// replaced are restored from their original contents.
+func ApplyReplaceChanges(workDir string, changes []ReplaceFileChange) error {
+	type pending struct {
+		change ReplaceFileChange
+		full   string
+		tmp    string
+	}
+	var staged []pending
+	cleanup := func() {
+		for _, s := range staged {
+			_ = os.Remove(s.tmp)
+		}
+	}
+
+	for _, c := range changes {
+		full, err := safeProjectPath(workDir, c.Path)
+		if err != nil {
+			cleanup()
+			return err
+		}
+		tmp, err := writeTempBeside(full, c.New, c.Mode)
+		if err != nil {
+			cleanup()
+			return fmt.Errorf("write %s: %w", c.Path, err)
+		}
+		staged = append(staged, pending{change: c, full: full, tmp: tmp})
+	}
+
+	for i, s := range staged {
+		if err := os.Rename(s.tmp, s.full); err != nil {
+			// Roll back files already replaced and drop remaining temps
+			for _, done := range staged[:i] {
+				_ = os.WriteFile(done.full, done.change.Old, done.change.Mode)
+			}
+			for _, rest := range staged[i:] {
+				_ = os.Remove(rest.tmp)
+			}
+			return fmt.Errorf("replace %s: %w", s.change.Path, err)
+		}
+	}
+	return nil
+}
+
+// UndoReplace restores the files of a snapshot. Files edited since the
+// replace are left alone and reported in the error.
+func UndoReplace(workDir string, snap *ReplaceSnapshot) error {
+	var restore []ReplaceFileChange
+	var conflicts []string
+	for _, c := range snap.Changes {
+		full, err := safeProjectPath(workDir, c.Path)
+		if err != nil {
+			return err
+		}
+		current, err := os.ReadFile(full)
+		if err != nil || !bytes.Equal(current, c.New) {
+			conflicts = append(conflicts, c.Path)
+			continue
+		}
+		restore = append(restore, ReplaceFileChange{Path: c.Path, New: c.Old, Old: c.New, Mode: c.Mode})
+	}
+	if err := ApplyReplaceChanges(workDir, restore); err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("not restored (modified since replace): %s", strings.Join(conflicts, ", "))
+	}
+	return nil
+}
+
+// safeProjectPath resolves rel inside workDir, rejecting paths that escape it.
+func safeProjectPath(workDir, rel string) (string, error) {
+	full := filepath.Join(workDir, rel)
+	r, err := filepath.Rel(workDir, full)
+	if err != nil || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path outside project: %s", rel)
+	}
+	return full, nil
+}
+
+// writeTempBeside writes data to a temp file in path's directory.
+func writeTempBeside(path string, data []byte, mode os.FileMode) (string, error) {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".replace-*")
+	if err != nil {
+		return "", err
+	}
+	tmp := f.Name()
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return "", err
+	}
+	if err := os.Chmod(tmp, mode); err != nil {
+		_ = os.Remove(tmp)
+		return "", err
+	}
+	return tmp, nil
+}
+
+// ProjectReplaceDoneMsg reports the result of applying a project replace.
+type ProjectReplaceDoneMsg struct {
+	Epoch    uint64
+	Snapshot *ReplaceSnapshot
+	Err      error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m ProjectReplaceDoneMsg) GetEpoch() uint64 { return m.Epoch }
+
+// ProjectReplaceUndoneMsg reports the result of undoing a project replace.
+type ProjectReplaceUndoneMsg struct {
+	Epoch    uint64
+	Snapshot *ReplaceSnapshot
+	Err      error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m ProjectReplaceUndoneMsg) GetEpoch() uint64 { return m.Epoch }
+
+// RunProjectReplace plans and applies the replacement for the selected matches.
+func RunProjectReplace(workDir string, state *ProjectSearchState, epoch uint64) tea.Cmd {
+	// Copy inputs so the search state can keep changing while we run;
+	// toggling a match's Excluded flag must not reach the running plan
+	query, replacement := state.Query, state.Replacement
+	results := make([]SearchFileResult, len(state.Results))
+	for i, r := range state.Results {
+		r.Matches = append([]SearchMatch(nil), r.Matches...)
+		results[i] = r
+	}
+	snapshotState := &ProjectSearchState{
+		Query:         state.Query,
+		Replacement:   state.Replacement,
+		UseRegex:      state.UseRegex,
+		CaseSensitive: state.CaseSensitive,
+		WholeWord:     state.WholeWord,
+		Results:       results,
+	}
+	return func() tea.Msg {
+		changes, err := PlanProjectReplace(workDir, snapshotState)
+		if err != nil {
+			return ProjectReplaceDoneMsg{Epoch: epoch, Err: err}
+		}
+		if len(changes) == 0 {
+			return ProjectReplaceDoneMsg{Epoch: epoch, Err: fmt.Errorf("nothing to replace")}
+		}
+		if err := ApplyReplaceChanges(workDir, changes); err != nil {
+			return ProjectReplaceDoneMsg{Epoch: epoch, Err: err}
+		}
+		return ProjectReplaceDoneMsg{Epoch: epoch, Snapshot: &ReplaceSnapshot{Query: query, Replacement: replacement, Changes: changes}}
+	}
+}
+
+// RunUndoProjectReplace restores files from the last replace snapshot.
+func RunUndoProjectReplace(workDir string, snap *ReplaceSnapshot, epoch uint64) tea.Cmd {
+	return func() tea.Msg {
+		return ProjectReplaceUndoneMsg{Epoch: epoch, Snapshot: snap, Err: UndoReplace(workDir, snap)}
+	}
+}
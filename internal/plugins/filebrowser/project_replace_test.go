@@ -0,0 +1,261 @@
+package filebrowser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompileSearchPattern(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		useRegex      bool
+		caseSensitive bool
+		wholeWord     bool
+		line          string
+		want          bool
+	}{
+		{"literal escapes metachars", "a.b", false, true, false, "axb", false},
+		{"literal matches", "a.b", false, true, false, "x a.b y", true},
+		{"case insensitive", "Foo", false, false, false, "foo", true},
+		{"case sensitive", "Foo", false, true, false, "foo", false},
+		{"whole word", "foo", false, true, true, "foobar", false},
+		{"whole word alternation", "foo|bar", true, true, true, "xbar", false},
+		{"regex", `fo+\d`, true, true, false, "fooo7", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := compileSearchPattern(tt.query, tt.useRegex, tt.caseSensitive, tt.wholeWord)
+			if err != nil {
+				t.Fatalf("compile: %v", err)
+			}
+			if got := re.MatchString(tt.line); got != tt.want {
+				t.Errorf("MatchString(%q) = %v, want %v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreviewReplaceLine(t *testing.T) {
+	re, _ := compileSearchPattern(`get(\w+)`, true, true, false)
+	segs := previewReplaceLine(re, "x := getName()", "load$1", true, 5)
+
+	var plain, removed, added strings.Builder
+	for _, s := range segs {
+		switch {
+		case s.Removed:
+			removed.WriteString(s.Text)
+		case s.Added:
+			added.WriteString(s.Text)
+		default:
+			plain.WriteString(s.Text)
+		}
+	}
+	if removed.String() != "getName" || added.String() != "loadName" || plain.String() != "x := ()" {
+		t.Errorf("segments = %+v", segs)
+	}
+
+	// Without regex, $1 is literal
+	re, _ = compileSearchPattern("get", false, true, false)
+	segs = previewReplaceLine(re, "get", "$1", false, 0)
+	if len(segs) != 2 || segs[1].Text != "$1" || !segs[1].Added {
+		t.Errorf("literal segments = %+v", segs)
+	}
+}
+
+func TestProjectSearchState_ToggleMatchAndFile(t *testing.T) {
+	state := NewProjectSearchState()
+	state.Results = []SearchFileResult{
+		{Path: "a.go", Matches: []SearchMatch{{LineNo: 1}, {LineNo: 2}}},
+		{Path: "b.go", Matches: []SearchMatch{{LineNo: 3}}},
+	}
+
+	state.Cursor = 1 // a.go:1
+	state.ToggleMatch()
+	if !state.Results[0].Matches[0].Excluded {
+		t.Fatal("expected match excluded")
+	}
+	if m, f := state.IncludedMatches(); m != 2 || f != 2 {
+		t.Errorf("included = %d matches / %d files, want 2/2", m, f)
+	}
+
+	// File toggle excludes all when any match is included
+	state.ToggleFile()
+	if state.Results[0].IncludedCount() != 0 {
+		t.Error("expected all matches in a.go excluded")
+	}
+	// ...and includes all when none are
+	state.ToggleFile()
+	if state.Results[0].IncludedCount() != 2 {
+		t.Error("expected all matches in a.go included")
+	}
+}
+
+func writeReplaceFixture(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readReplaceFixture(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestProjectReplace_ApplyAndUndo(t *testing.T) {
+	dir := t.TempDir()
+	writeReplaceFixture(t, dir, "a.go", "foo := 1\r\nbar(foo)\r\nfoo()\r\n")
+	writeReplaceFixture(t, dir, "sub/b.go", "x := foo\n")
+
+	state := NewProjectSearchState()
+	state.Query = "foo"
+	state.Replacement = "baz"
+	state.Results = []SearchFileResult{
+		{Path: "a.go", Matches: []SearchMatch{
+			{LineNo: 1, LineText: "foo := 1"},
+			{LineNo: 2, LineText: "bar(foo)", ColStart: 4},
+			{LineNo: 3, LineText: "foo()", Excluded: true},
+		}},
+		{Path: "sub/b.go", Matches: []SearchMatch{{LineNo: 1, LineText: "x := foo", ColStart: 5}}},
+	}
+
+	changes, err := PlanProjectReplace(dir, state)
+	if err != nil {
+		t.Fatalf("PlanProjectReplace: %v", err)
+	}
+	if len(changes) != 2 || changes[0].Lines != 2 {
+		t.Fatalf("changes = %+v", changes)
+	}
+	if err := ApplyReplaceChanges(dir, changes); err != nil {
+		t.Fatalf("ApplyReplaceChanges: %v", err)
+	}
+
+	// Line endings preserved, excluded match untouched
+	if got := readReplaceFixture(t, dir, "a.go"); got != "baz := 1\r\nbar(baz)\r\nfoo()\r\n" {
+		t.Errorf("a.go = %q", got)
+	}
+	if got := readReplaceFixture(t, dir, "sub/b.go"); got != "x := baz\n" {
+		t.Errorf("b.go = %q", got)
+	}
+	entries, _ := os.ReadDir(filepath.Join(dir, "sub"))
+	if len(entries) != 1 {
+		t.Errorf("expected temp files cleaned up, got %d entries", len(entries))
+	}
+
+	// Undo skips files edited since the replace
+	writeReplaceFixture(t, dir, "sub/b.go", "edited\n")
+	snap := &ReplaceSnapshot{Changes: changes}
+	if err := UndoReplace(dir, snap); err == nil || !strings.Contains(err.Error(), "sub/b.go") {
+		t.Errorf("expected conflict on sub/b.go, got %v", err)
+	}
+	if got := readReplaceFixture(t, dir, "a.go"); got != "foo := 1\r\nbar(foo)\r\nfoo()\r\n" {
+		t.Errorf("a.go after undo = %q", got)
+	}
+	if got := readReplaceFixture(t, dir, "sub/b.go"); got != "edited\n" {
+		t.Errorf("b.go after undo = %q", got)
+	}
+}
+
+func TestPlanProjectReplace_RegexCaptureGroups(t *testing.T) {
+	dir := t.TempDir()
+	writeReplaceFixture(t, dir, "a.go", "getName()\n")
+
+	state := NewProjectSearchState()
+	state.Query = `get(\w+)`
+	state.UseRegex = true
+	state.CaseSensitive = true
+	state.Replacement = "load${1}Now"
+	state.Results = []SearchFileResult{
+		{Path: "a.go", Matches: []SearchMatch{{LineNo: 1, LineText: "getName()"}}},
+	}
+
+	changes, err := PlanProjectReplace(dir, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || string(changes[0].New) != "loadNameNow()\n" {
+		t.Errorf("changes = %+v", changes)
+	}
+}
+
+func TestPlanProjectReplace_PerOccurrence(t *testing.T) {
+	dir := t.TempDir()
+	writeReplaceFixture(t, dir, "a.go", "foo(foo, foo)\n")
+
+	state := NewProjectSearchState()
+	state.Query = "foo"
+	state.Replacement = "bar"
+	state.Results = []SearchFileResult{
+		{Path: "a.go", Matches: []SearchMatch{
+			{LineNo: 1, LineText: "foo(foo, foo)", ColStart: 0},
+			{LineNo: 1, LineText: "foo(foo, foo)", ColStart: 4, Excluded: true},
+			{LineNo: 1, LineText: "foo(foo, foo)", ColStart: 9},
+		}},
+	}
+
+	changes, err := PlanProjectReplace(dir, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || string(changes[0].New) != "bar(foo, bar)\n" || changes[0].Lines != 1 {
+		t.Errorf("changes = %+v", changes)
+	}
+
+	// A match whose column no longer lines up with an occurrence is stale
+	state.Results[0].Matches[0].ColStart = 1
+	if _, err := PlanProjectReplace(dir, state); err == nil {
+		t.Error("expected error for a match column without an occurrence")
+	}
+}
+
+func TestRunProjectReplace_CopiesMatches(t *testing.T) {
+	dir := t.TempDir()
+	writeReplaceFixture(t, dir, "a.go", "foo\n")
+
+	state := NewProjectSearchState()
+	state.Query = "foo"
+	state.Replacement = "bar"
+	state.Results = []SearchFileResult{
+		{Path: "a.go", Matches: []SearchMatch{{LineNo: 1, LineText: "foo"}}},
+	}
+	cmd := RunProjectReplace(dir, state, 0)
+
+	// Excluding the match after the replace started must not affect it
+	state.Results[0].Matches[0].Excluded = true
+	if msg := cmd().(ProjectReplaceDoneMsg); msg.Err != nil {
+		t.Fatalf("replace: %v", msg.Err)
+	}
+	if got := readReplaceFixture(t, dir, "a.go"); got != "bar\n" {
+		t.Errorf("a.go = %q", got)
+	}
+}
+
+func TestPlanProjectReplace_StaleFile(t *testing.T) {
+	dir := t.TempDir()
+	writeReplaceFixture(t, dir, "a.go", "changed\n")
+
+	state := NewProjectSearchState()
+	state.Query = "foo"
+	state.Results = []SearchFileResult{
+		{Path: "a.go", Matches: []SearchMatch{{LineNo: 1, LineText: "foo"}}},
+	}
+	if _, err := PlanProjectReplace(dir, state); err == nil {
+		t.Error("expected error for file changed since search")
+	}
+
+	state.Results[0].Path = "../escape.go"
+	if _, err := PlanProjectReplace(dir, state); err == nil {
+		t.Error("expected error for path outside project")
+	}
+}
@@ -12,9 +12,12 @@ import (
 )
 
 const (
-	projectSearchMaxResults  = 1000              // Max total matches to display
-	projectSearchTimeout     = 30 * time.Second  // Max time for search
+	projectSearchMaxResults  = 1000                   // Max total matches to display
+	projectSearchTimeout     = 30 * time.Second       // Max time for search
 	projectSearchDebounce    = 200 * time.Millisecond // Debounce delay before searching
+	projectSearchMaxPerFile  = 100                    // Max matches per file
+	projectSearchMaxFileSize = 1024 * 1024            // Skip files larger than this
+	projectSearchStreamEvery = 100 * time.Millisecond // Interval between partial result updates
 )
 
 // ProjectSearchState holds the state for project-wide search.
@@ -27,6 +30,12 @@ type ProjectSearchState struct {
 	CaseSensitive bool
 	WholeWord     bool
 
+	// Replace mode: Replacement is applied to every non-excluded match.
+	// ReplaceFocus routes typing to the replacement field instead of the query.
+	ReplaceMode  bool
+	Replacement  string
+	ReplaceFocus bool
+
 	// UI state
 	Cursor       int  // Index in flattened results (files + matches)
 	ScrollOffset int  // For scrolling
@@ -38,6 +47,13 @@ type ProjectSearchState struct {
 
 	// For future: multiple search tabs
 	TabID int
+
+	// Backend is the configured search backend ("auto", "ripgrep" or
+	// "builtin"); ActiveBackend names the one that produced Results.
+	Backend       string
+	ActiveBackend string
+
+	stream *projectSearchStream // Search currently streaming results
 }
 
 // projectSearchDebounceMsg is sent after debounce delay to trigger search.
@@ -55,17 +71,24 @@ type SearchFileResult struct {
 
 // SearchMatch represents a single match within a file.
 type SearchMatch struct {
-	LineNo    int    // 1-indexed line number
-	LineText  string // Full line content
-	ColStart  int    // Match start column (0-indexed)
-	ColEnd    int    // Match end column (0-indexed)
+	LineNo   int    // 1-indexed line number
+	LineText string // Full line content
+	ColStart int    // Match start column (0-indexed)
+	ColEnd   int    // Match end column (0-indexed)
+	Excluded bool   // Skipped by replace
 }
 
 // ProjectSearchResultsMsg contains results from a search.
+// While a search runs, messages with Partial set carry the results found so
+// far; the final message has Partial unset.
 type ProjectSearchResultsMsg struct {
 	Epoch   uint64 // Epoch when request was issued (for stale detection)
 	Results []SearchFileResult
 	Error   error
+	Backend string // Backend that produced the results
+	Partial bool   // More results will follow
+
+	stream *projectSearchStream
 }
 
 // GetEpoch implements plugin.EpochMessage.
@@ -241,127 +264,134 @@ func scheduleProjectSearch(version int, query string) tea.Cmd {
 	})
 }
 
-// RunProjectSearch executes ripgrep and returns results.
+// RunProjectSearch starts a search with the state's backend and returns a
+// command delivering the first batch of results. Any search still running
+// for the state is cancelled.
 func RunProjectSearch(workDir string, state *ProjectSearchState, epoch uint64) tea.Cmd {
-	return func() tea.Msg {
-		if state.Query == "" {
+	state.cancelSearch()
+	if state.Query == "" {
+		return func() tea.Msg {
 			return ProjectSearchResultsMsg{Epoch: epoch, Results: nil}
 		}
+	}
+
+	backend := selectSearchBackend(state.Backend)
+	opts := state.searchOptions()
+	opts.MaxMatches = projectSearchMaxResults
+	state.stream = startProjectSearch(workDir, backend, opts, epoch)
+	return state.stream.next
+}
 
-		ctx, cancel := context.WithTimeout(context.Background(), projectSearchTimeout)
-		defer cancel()
+// cancelSearch stops any search still streaming results into the state.
+func (s *ProjectSearchState) cancelSearch() {
+	if s.stream != nil {
+		s.stream.cancel()
+		s.stream = nil
+	}
+}
 
-		args := buildRipgrepArgs(state)
-		cmd := exec.CommandContext(ctx, "rg", args...)
-		cmd.Dir = workDir
+// searchOptions returns the query and options of the state.
+func (s *ProjectSearchState) searchOptions() SearchOptions {
+	return SearchOptions{
+		Query:         s.Query,
+		UseRegex:      s.UseRegex,
+		CaseSensitive: s.CaseSensitive,
+		WholeWord:     s.WholeWord,
+	}
+}
 
-		stdout, err := cmd.StdoutPipe()
-		if err != nil {
-			return ProjectSearchResultsMsg{Epoch: epoch, Error: err}
-		}
+// ripgrepBackend searches with the rg binary.
+type ripgrepBackend struct{}
 
-		if err := cmd.Start(); err != nil {
-			// Check if rg is not installed
-			if strings.Contains(err.Error(), "executable file not found") {
-				return ProjectSearchResultsMsg{Epoch: epoch, Error: &ripgrepNotFoundError{}}
-			}
-			return ProjectSearchResultsMsg{Epoch: epoch, Error: err}
-		}
+func (ripgrepBackend) Name() string { return searchBackendRipgrep }
 
-		results := parseRipgrepOutput(stdout, projectSearchMaxResults, len(state.Query))
+func (ripgrepBackend) Search(ctx context.Context, workDir string, opts SearchOptions, emit func(SearchFileResult) bool) error {
+	cmd := exec.CommandContext(ctx, "rg", buildRipgrepArgs(opts)...)
+	cmd.Dir = workDir
 
-		// Kill ripgrep early if we hit our limit - don't wait for it to finish
-		// This is critical for queries with many matches (e.g., common words)
-		_ = cmd.Process.Kill()
-		_ = cmd.Wait()
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
 
-		return ProjectSearchResultsMsg{Epoch: epoch, Results: results}
+	if err := cmd.Start(); err != nil {
+		// Check if rg is not installed
+		if strings.Contains(err.Error(), "executable file not found") {
+			return &ripgrepNotFoundError{}
+		}
+		return err
 	}
+
+	scanRipgrepOutput(stdout, len(opts.Query), emit)
+
+	// Kill ripgrep early if we hit our limit - don't wait for it to finish
+	// This is critical for queries with many matches (e.g., common words)
+	_ = cmd.Process.Kill()
+	_ = cmd.Wait()
+	return nil
 }
 
 // buildRipgrepArgs constructs the ripgrep command arguments.
-func buildRipgrepArgs(state *ProjectSearchState) []string {
+func buildRipgrepArgs(opts SearchOptions) []string {
 	args := []string{
-		"--line-number",    // Include line numbers
-		"--column",         // Include column numbers for match position
-		"--no-heading",     // Don't group by file (simpler parsing)
-		"--with-filename",  // Always include filename
-		"--max-count=100",  // Limit matches per file
+		"--line-number",   // Include line numbers
+		"--column",        // Include column numbers for match position
+		"--vimgrep",       // One line per occurrence, so each can be replaced on its own
+		"--no-heading",    // Don't group by file (simpler parsing)
+		"--with-filename", // Always include filename
+		"--max-count=" + strconv.Itoa(projectSearchMaxPerFile), // Limit matches per file
 		"--max-filesize=1M", // Skip very large files
 	}
 
-	if !state.CaseSensitive {
+	if !opts.CaseSensitive {
 		args = append(args, "--ignore-case")
 	}
 
-	if state.WholeWord {
+	if opts.WholeWord {
 		args = append(args, "--word-regexp")
 	}
 
-	if !state.UseRegex {
+	if !opts.UseRegex {
 		args = append(args, "--fixed-strings")
 	}
 
-	args = append(args, "--", state.Query)
+	args = append(args, "--", opts.Query)
 
 	return args
 }
 
-// parseRipgrepOutput reads ripgrep line output (filename:line:col:content) and builds results.
-func parseRipgrepOutput(reader interface{ Read([]byte) (int, error) }, maxMatches int, queryLen int) []SearchFileResult {
+// scanRipgrepOutput reads ripgrep line output and emits each file's matches
+// once the output moves on to another file. Stops early if emit returns false.
+func scanRipgrepOutput(reader interface{ Read([]byte) (int, error) }, queryLen int, emit func(SearchFileResult) bool) {
 	scanner := bufio.NewScanner(reader)
-	// Increase buffer size for long lines
 	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
 
-	fileMap := make(map[string]*SearchFileResult)
-	var fileOrder []string
-	totalMatches := 0
-
-	for scanner.Scan() && totalMatches < maxMatches {
-		line := scanner.Text()
-		if len(line) == 0 {
-			continue
-		}
-
-		// Parse format: filename:line:column:content
-		// Need to handle filenames that might contain colons (Windows paths, etc.)
-		// ripgrep guarantees line and column are numeric, so we parse from the content backwards
-		path, lineNo, colNo, content := parseRipgrepLine(line)
+	var current *SearchFileResult
+	for scanner.Scan() {
+		path, lineNo, colNo, content := parseRipgrepLine(scanner.Text())
 		if path == "" {
 			continue
 		}
-
-		// Get or create file result
-		file, exists := fileMap[path]
-		if !exists {
-			file = &SearchFileResult{
-				Path:    path,
-				Matches: make([]SearchMatch, 0, 8),
+		if current != nil && current.Path != path {
+			if !emit(*current) {
+				return
 			}
-			fileMap[path] = file
-			fileOrder = append(fileOrder, path)
+			current = nil
+		}
+		if current == nil {
+			current = &SearchFileResult{Path: path, Matches: make([]SearchMatch, 0, 8)}
 		}
-
-		// Calculate match end from query length (column is 1-indexed)
 		colStart := colNo - 1
-		colEnd := colStart + queryLen
-
-		file.Matches = append(file.Matches, SearchMatch{
+		current.Matches = append(current.Matches, SearchMatch{
 			LineNo:   lineNo,
-			LineText: content,
+			LineText: strings.TrimSuffix(content, "\r"),
 			ColStart: colStart,
-			ColEnd:   colEnd,
+			ColEnd:   colStart + queryLen,
 		})
-		totalMatches++
 	}
-
-	// Build ordered results
-	results := make([]SearchFileResult, 0, len(fileOrder))
-	for _, path := range fileOrder {
-		results = append(results, *fileMap[path])
+	if current != nil {
+		emit(*current)
 	}
-
-	return results
 }
 
 // parseRipgrepLine parses a ripgrep output line in format: filename:line:column:content
@@ -1,6 +1,7 @@
 package filebrowser
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 )
@@ -229,7 +230,7 @@ func TestBuildRipgrepArgs(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			args := buildRipgrepArgs(tc.state)
+			args := buildRipgrepArgs(tc.state.searchOptions())
 			argsStr := strings.Join(args, " ")
 
 			for _, want := range tc.expectContain {
@@ -247,14 +248,17 @@ func TestBuildRipgrepArgs(t *testing.T) {
 	}
 }
 
-func TestParseRipgrepOutput(t *testing.T) {
+func TestScanRipgrepOutput(t *testing.T) {
 	// Sample ripgrep line output (filename:line:column:content)
 	lineOutput := `test.go:10:6:func TestSomething() {
 test.go:20:4:// Test comment
 other.go:5:5:var TestVar = 1`
 
-	reader := strings.NewReader(lineOutput)
-	results := parseRipgrepOutput(reader, 100, 4) // query "Test" has length 4
+	var results []SearchFileResult
+	scanRipgrepOutput(strings.NewReader(lineOutput), 4, func(r SearchFileResult) bool { // query "Test" has length 4
+		results = append(results, r)
+		return true
+	})
 
 	if len(results) != 2 {
 		t.Fatalf("expected 2 files, got %d", len(results))
@@ -285,23 +289,20 @@ other.go:5:5:var TestVar = 1`
 	}
 }
 
-func TestParseRipgrepOutput_MaxMatches(t *testing.T) {
-	// Generate many matches in line format
+func TestScanRipgrepOutput_StopsWhenEmitDeclines(t *testing.T) {
 	var sb strings.Builder
 	for i := 0; i < 50; i++ {
-		sb.WriteString("test.go:1:1:line content x\n")
+		fmt.Fprintf(&sb, "file%d.go:1:1:line content x\n", i)
 	}
 
-	reader := strings.NewReader(sb.String())
-	results := parseRipgrepOutput(reader, 10, 1) // Limit to 10, query length 1
+	emitted := 0
+	scanRipgrepOutput(strings.NewReader(sb.String()), 1, func(SearchFileResult) bool {
+		emitted++
+		return emitted < 10
+	})
 
-	totalMatches := 0
-	for _, f := range results {
-		totalMatches += len(f.Matches)
-	}
-
-	if totalMatches > 10 {
-		t.Errorf("expected at most 10 matches, got %d", totalMatches)
+	if emitted != 10 {
+		t.Errorf("expected scanning to stop after 10 files, emitted %d", emitted)
 	}
 }
 
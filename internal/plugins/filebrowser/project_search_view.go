@@ -2,6 +2,7 @@ package filebrowser
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -231,7 +232,7 @@ func (p *Plugin) projectSearchResultsSection() modal.Section {
 			return strings.Join(lines, "\n")
 		}
 
-		if state.IsSearching {
+		if state.IsSearching && len(state.Results) == 0 {
 			return modal.RenderedSection{Content: padToMinHeight(styles.Muted.Render("Searching..."))}
 		}
 		if state.Error != "" {
@@ -259,6 +260,12 @@ func (p *Plugin) projectSearchResultsSection() modal.Section {
 			state.ScrollOffset = 0
 		}
 
+		// Compile the replace pattern once per render for previews
+		var replaceRe *regexp.Regexp
+		if state.ReplaceMode {
+			replaceRe, _ = state.replacePattern()
+		}
+
 		var lines []string
 		focusables := make([]modal.FocusableInfo, 0, maxVisible)
 		flatIdx := 0
@@ -289,7 +296,12 @@ func (p *Plugin) projectSearchResultsSection() modal.Section {
 						itemID := projectSearchMatchID(fi, mi)
 						selected := flatIdx == state.Cursor
 						hovered := itemID == hoverID
-						line := p.renderSearchMatchLine(match, mi, selected, hovered, contentWidth)
+						var line string
+						if replaceRe != nil {
+							line = p.renderReplaceMatchLine(match, replaceRe, selected, hovered, contentWidth)
+						} else {
+							line = p.renderSearchMatchLine(match, mi, selected, hovered, contentWidth)
+						}
 
 						lines = append(lines, line)
 						focusables = append(focusables, modal.FocusableInfo{
@@ -340,6 +352,16 @@ func (p *Plugin) projectSearchStatsSection() modal.Section {
 			position = fmt.Sprintf("%d/%d  ", state.Cursor+1, flatLen)
 		}
 		stats := fmt.Sprintf("%d matches in %d files", state.TotalMatches(), state.FileCount())
+		if state.ReplaceMode {
+			matches, files := state.IncludedMatches()
+			stats = fmt.Sprintf("replace %d of %d matches in %d files", matches, state.TotalMatches(), files)
+		}
+		if state.ActiveBackend == searchBackendBuiltin {
+			stats += " (built-in search)"
+		}
+		if state.IsSearching {
+			stats += "  searching..."
+		}
 
 		return modal.RenderedSection{Content: styles.Muted.Render(position + stats)}
 	}, nil)
@@ -372,8 +394,25 @@ func (p *Plugin) renderProjectSearchHeader(width int) string {
 		query = ui.TruncateStart(query, available)
 	}
 
+	if state.ReplaceMode && state.ReplaceFocus {
+		cursor = " "
+	}
 	header := fmt.Sprintf("%s%s%s", prefix, query, cursor)
-	return styles.ModalTitle.Render(header)
+	if !state.ReplaceMode {
+		return styles.ModalTitle.Render(header)
+	}
+
+	replacePrefix := "Replace: "
+	replacement := state.Replacement
+	if avail := width - len(replacePrefix) - 1; len(replacement) > avail && avail >= 0 {
+		replacement = ui.TruncateStart(replacement, avail)
+	}
+	replaceCursor := ""
+	if state.ReplaceFocus {
+		replaceCursor = "█"
+	}
+	return styles.ModalTitle.Render(header) + "\n" +
+		styles.ModalTitle.Render(replacePrefix+replacement+replaceCursor)
 }
 
 // renderSearchFileHeader renders a file header line.
@@ -457,6 +496,55 @@ func (p *Plugin) renderSearchMatchLine(match SearchMatch, matchIdx int, selected
 	)
 }
 
+// renderReplaceMatchLine renders a match line as an inline replace preview:
+// the matched text struck out in red followed by the replacement in green.
+// Excluded matches are shown unchanged.
+func (p *Plugin) renderReplaceMatchLine(match SearchMatch, re *regexp.Regexp, selected, hovered bool, width int) string {
+	state := p.projectSearchState
+	check := "[x] "
+	if match.Excluded {
+		check = "[ ] "
+	}
+	prefix := "  " + check
+	lineNum := fmt.Sprintf("%4d: ", match.LineNo)
+
+	lineText := strings.TrimLeft(match.LineText, " \t")
+	var body strings.Builder
+	if match.Excluded {
+		body.WriteString(styles.Muted.Render(lineText))
+	} else {
+		segs := previewReplaceLine(re, match.LineText, state.Replacement, state.UseRegex, match.ColStart)
+		if len(segs) > 0 && !segs[0].Removed && !segs[0].Added {
+			segs[0].Text = strings.TrimLeft(segs[0].Text, " \t")
+		}
+		for _, seg := range segs {
+			switch {
+			case seg.Removed:
+				body.WriteString(styles.DiffRemove.Strikethrough(true).Render(seg.Text))
+			case seg.Added:
+				body.WriteString(styles.DiffAdd.Render(seg.Text))
+			default:
+				body.WriteString(seg.Text)
+			}
+		}
+	}
+
+	available := width - len(prefix) - len(lineNum)
+	if available < 10 {
+		available = 10
+	}
+	preview := ansi.Truncate(body.String(), available, "…")
+
+	if selected || hovered {
+		line := styles.ListItemSelected.Render(prefix+lineNum) + preview
+		if pad := width - ansi.StringWidth(line); pad > 0 {
+			line += styles.ListItemSelected.Render(strings.Repeat(" ", pad))
+		}
+		return line
+	}
+	return styles.Muted.Render(prefix) + styles.FileBrowserLineNumber.Render(lineNum) + preview
+}
+
 // highlightMatchInSelection applies selection style with embedded match highlight.
 func highlightMatchInSelection(line string, matchStart, matchEnd int) string {
 	if matchStart < 0 {
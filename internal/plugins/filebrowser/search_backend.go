@@ -0,0 +1,305 @@
+package filebrowser
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Search backend names, as used by the file-browser searchBackend setting.
+const (
+	searchBackendRipgrep = "ripgrep"
+	searchBackendBuiltin = "builtin"
+)
+
+// SearchOptions are the query and flags for a project search.
+type SearchOptions struct {
+	Query         string
+	UseRegex      bool
+	CaseSensitive bool
+	WholeWord     bool
+	MaxMatches    int // Total matches to collect (0 = unlimited)
+}
+
+// SearchBackend finds matches across a project. Search calls emit once per
+// file with matches (possibly from several goroutines) and stops when emit
+// returns false or ctx is cancelled.
+type SearchBackend interface {
+	Name() string
+	Search(ctx context.Context, workDir string, opts SearchOptions, emit func(SearchFileResult) bool) error
+}
+
+// selectSearchBackend returns the backend for a searchBackend setting.
+// "auto" (or any unknown value) uses ripgrep when installed, otherwise the
+// built-in backend.
+func selectSearchBackend(pref string) SearchBackend {
+	switch pref {
+	case searchBackendRipgrep, "rg":
+		return ripgrepBackend{}
+	case searchBackendBuiltin:
+		return builtinBackend{}
+	}
+	if _, err := exec.LookPath("rg"); err == nil {
+		return ripgrepBackend{}
+	}
+	return builtinBackend{}
+}
+
+// projectSearchStream delivers the results of a running search as a series
+// of ProjectSearchResultsMsg.
+type projectSearchStream struct {
+	ch        chan ProjectSearchResultsMsg
+	cancel    context.CancelFunc
+	delivered bool // A batch has been applied to the search state
+}
+
+// next waits for the stream's next message.
+func (s *projectSearchStream) next() tea.Msg {
+	msg, ok := <-s.ch
+	if !ok {
+		return nil
+	}
+	return msg
+}
+
+// startProjectSearch runs backend in the background. Results found so far
+// are sent every projectSearchStreamEvery, followed by a final message once
+// the search completes, hits opts.MaxMatches or times out.
+func startProjectSearch(workDir string, backend SearchBackend, opts SearchOptions, epoch uint64) *projectSearchStream {
+	streamCtx, cancel := context.WithCancel(context.Background())
+	searchCtx, stopSearch := context.WithTimeout(streamCtx, projectSearchTimeout)
+	s := &projectSearchStream{ch: make(chan ProjectSearchResultsMsg, 1), cancel: cancel}
+
+	found := make(chan SearchFileResult)
+	done := make(chan error, 1)
+	go func() {
+		done <- backend.Search(searchCtx, workDir, opts, func(r SearchFileResult) bool {
+			select {
+			case found <- r:
+				return true
+			case <-searchCtx.Done():
+				return false
+			}
+		})
+	}()
+
+	go func() {
+		defer close(s.ch)
+		defer stopSearch()
+
+		var results []SearchFileResult
+		total := 0
+		dirty := false
+		send := func(msg ProjectSearchResultsMsg) bool {
+			msg.Epoch, msg.Backend, msg.stream = epoch, backend.Name(), s
+			msg.Results = append([]SearchFileResult(nil), results...)
+			select {
+			case s.ch <- msg:
+				return true
+			case <-streamCtx.Done():
+				return false
+			}
+		}
+
+		ticker := time.NewTicker(projectSearchStreamEvery)
+		defer ticker.Stop()
+		for {
+			select {
+			case r := <-found:
+				if opts.MaxMatches > 0 {
+					if remaining := opts.MaxMatches - total; len(r.Matches) > remaining {
+						r.Matches = r.Matches[:remaining]
+					}
+				}
+				if len(r.Matches) == 0 {
+					continue
+				}
+				results = append(results, r)
+				total += len(r.Matches)
+				dirty = true
+				if opts.MaxMatches > 0 && total >= opts.MaxMatches {
+					stopSearch()
+				}
+			case <-ticker.C:
+				if dirty && !send(ProjectSearchResultsMsg{Partial: true}) {
+					return
+				}
+				dirty = false
+			case err := <-done:
+				// Hitting the match limit or the timeout still returns what was found
+				if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+					err = nil
+				}
+				if streamCtx.Err() == nil {
+					send(ProjectSearchResultsMsg{Error: err})
+				}
+				return
+			}
+		}
+	}()
+	return s
+}
+
+// builtinBackend is a pure-Go search used when ripgrep is unavailable. Like
+// ripgrep it skips hidden, gitignored, binary and oversized files.
+type builtinBackend struct{}
+
+func (builtinBackend) Name() string { return searchBackendBuiltin }
+
+func (builtinBackend) Search(ctx context.Context, workDir string, opts SearchOptions, emit func(SearchFileResult) bool) error {
+	re, err := compileSearchPattern(opts.Query, opts.UseRegex, opts.CaseSensitive, opts.WholeWord)
+	if err != nil {
+		return err
+	}
+
+	paths := make(chan string, 64)
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for rel := range paths {
+				if ctx.Err() != nil {
+					continue // Drain remaining paths
+				}
+				if r, ok := searchFile(workDir, rel, re); ok {
+					emit(r)
+				}
+			}
+		}()
+	}
+
+	walkErr := walkSearchFiles(ctx, workDir, "", nil, func(rel string) bool {
+		select {
+		case paths <- rel:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	})
+	close(paths)
+	wg.Wait()
+
+	if walkErr != nil {
+		return walkErr
+	}
+	return ctx.Err()
+}
+
+// ignoreLayer is a .gitignore loaded from a directory relative to the root.
+type ignoreLayer struct {
+	dir string
+	gi  *GitIgnore
+}
+
+// walkSearchFiles visits every searchable file under root/rel, applying the
+// .gitignore of each directory on the way down. visit returns false to stop.
+func walkSearchFiles(ctx context.Context, root, rel string, layers []ignoreLayer, visit func(rel string) bool) error {
+	dir := filepath.Join(root, filepath.FromSlash(rel))
+
+	gi := NewGitIgnore()
+	_ = gi.LoadFile(filepath.Join(dir, ".gitignore"))
+	if len(gi.patterns) > 0 {
+		layers = append(layers[:len(layers):len(layers)], ignoreLayer{dir: rel, gi: gi})
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil // Skip unreadable directories
+	}
+	for _, e := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		name := e.Name()
+		if strings.HasPrefix(name, ".") {
+			continue // Hidden files and directories, including .git
+		}
+		childRel := path.Join(rel, name)
+		if isIgnoredByLayers(layers, childRel, e.IsDir()) {
+			continue
+		}
+		switch {
+		case e.IsDir():
+			if err := walkSearchFiles(ctx, root, childRel, layers, visit); err != nil {
+				return err
+			}
+		case e.Type().IsRegular():
+			if !visit(childRel) {
+				return ctx.Err()
+			}
+		}
+		// Symlinks are not followed, matching ripgrep's default
+	}
+	return nil
+}
+
+// isIgnoredByLayers checks rel against each .gitignore that applies to it,
+// with paths made relative to the .gitignore's directory.
+func isIgnoredByLayers(layers []ignoreLayer, rel string, isDir bool) bool {
+	for _, l := range layers {
+		p := rel
+		if l.dir != "" {
+			p = strings.TrimPrefix(rel, l.dir+"/")
+		}
+		if l.gi.IsIgnored(p, isDir) {
+			return true
+		}
+	}
+	return false
+}
+
+// searchFile returns the matches of a file, one per occurrence so each can
+// be replaced on its own. Binary and oversized files are skipped.
+func searchFile(workDir, rel string, re *regexp.Regexp) (SearchFileResult, bool) {
+	full := filepath.Join(workDir, filepath.FromSlash(rel))
+	info, err := os.Stat(full)
+	if err != nil || info.Size() > projectSearchMaxFileSize {
+		return SearchFileResult{}, false
+	}
+	data, err := os.ReadFile(full)
+	if err != nil || isBinary(data) || !re.Match(data) {
+		return SearchFileResult{}, false
+	}
+
+	result := SearchFileResult{Path: rel}
+	lineNo := 0
+	for len(data) > 0 && len(result.Matches) < projectSearchMaxPerFile {
+		lineNo++
+		line := data
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			line, data = data[:i], data[i+1:]
+		} else {
+			data = nil
+		}
+		line = bytes.TrimSuffix(line, []byte("\r"))
+
+		var text string
+		for _, loc := range re.FindAllIndex(line, -1) {
+			if loc[0] == loc[1] {
+				continue // Skip empty matches (e.g. "x*")
+			}
+			if text == "" {
+				text = string(line)
+			}
+			result.Matches = append(result.Matches, SearchMatch{
+				LineNo:   lineNo,
+				LineText: text,
+				ColStart: loc[0],
+				ColEnd:   loc[1],
+			})
+		}
+	}
+	return result, len(result.Matches) > 0
+}
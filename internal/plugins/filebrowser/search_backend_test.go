@@ -0,0 +1,259 @@
+package filebrowser
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// searchFixture creates a project exercising ignore rules, hidden files,
+// binary detection and the size limit.
+func searchFixture(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		".gitignore":         "*.log\nbuild/\n",
+		"main.go":            "package main\n\nfunc Hello() {}\nvar hello = \"HELLO\"\n",
+		"pkg/util.go":        "// helloWorld is not a whole word\nfunc helloWorld() {}\r\n",
+		"pkg/.gitignore":     "gen.go\n",
+		"pkg/gen.go":         "hello generated\n",
+		"debug.log":          "hello log\n",
+		"build/out.txt":      "hello build\n",
+		".hidden/secret.txt": "hello hidden\n",
+		"docs/readme.md":     "say hello twice hello\n",
+		"data/blob.bin":      "hello\x00binary\n",
+		"data/big.txt":       strings.Repeat("x", projectSearchMaxFileSize) + "\nhello\n",
+		"data/nomatch.txt":   "nothing here\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// ripgrep only applies .gitignore inside a git repository
+	if err := os.Mkdir(filepath.Join(dir, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// searchBackends returns the backends available in this environment.
+func searchBackends(t *testing.T) []SearchBackend {
+	backends := []SearchBackend{builtinBackend{}}
+	if _, err := exec.LookPath("rg"); err == nil {
+		backends = append(backends, ripgrepBackend{})
+	} else {
+		t.Log("rg not installed; testing built-in backend only")
+	}
+	return backends
+}
+
+// runSearch collects all results from a backend, sorted by path.
+func runSearch(t *testing.T, backend SearchBackend, dir string, opts SearchOptions) []SearchFileResult {
+	t.Helper()
+	var results []SearchFileResult
+	emitted := make(chan SearchFileResult)
+	done := make(chan error, 1)
+	go func() {
+		done <- backend.Search(context.Background(), dir, opts, func(r SearchFileResult) bool {
+			emitted <- r
+			return true
+		})
+	}()
+	for {
+		select {
+		case r := <-emitted:
+			results = append(results, r)
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("%s: Search: %v", backend.Name(), err)
+			}
+			sort.Slice(results, func(i, j int) bool { return results[i].Path < results[j].Path })
+			return results
+		}
+	}
+}
+
+func TestSearchBackends_Results(t *testing.T) {
+	dir := searchFixture(t)
+
+	tests := []struct {
+		name string
+		opts SearchOptions
+		want map[string][]int // path -> line number of each occurrence
+	}{
+		{
+			name: "ignore, hidden, binary and size rules",
+			opts: SearchOptions{Query: "hello"},
+			want: map[string][]int{
+				"main.go":        {3, 4, 4},
+				"pkg/util.go":    {1, 2},
+				"docs/readme.md": {1, 1},
+			},
+		},
+		{
+			name: "case sensitive",
+			opts: SearchOptions{Query: "HELLO", CaseSensitive: true},
+			want: map[string][]int{"main.go": {4}},
+		},
+		{
+			name: "whole word",
+			opts: SearchOptions{Query: "hello", WholeWord: true, CaseSensitive: true},
+			want: map[string][]int{"main.go": {4}, "docs/readme.md": {1, 1}},
+		},
+		{
+			name: "regex",
+			opts: SearchOptions{Query: `func \w+\(`, UseRegex: true},
+			want: map[string][]int{"main.go": {3}, "pkg/util.go": {2}},
+		},
+		{
+			name: "fixed string metacharacters",
+			opts: SearchOptions{Query: "hello()", CaseSensitive: true},
+			want: map[string][]int{},
+		},
+	}
+
+	for _, backend := range searchBackends(t) {
+		for _, tt := range tests {
+			t.Run(backend.Name()+"/"+tt.name, func(t *testing.T) {
+				results := runSearch(t, backend, dir, tt.opts)
+				got := make(map[string][]int)
+				for _, r := range results {
+					for _, m := range r.Matches {
+						got[r.Path] = append(got[r.Path], m.LineNo)
+					}
+				}
+				if len(got) != len(tt.want) {
+					t.Fatalf("got files %v, want %v", got, tt.want)
+				}
+				for path, lines := range tt.want {
+					if !reflect.DeepEqual(got[path], lines) {
+						t.Errorf("%s: lines %v, want %v", path, got[path], lines)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestSearchBackends_MatchDetails(t *testing.T) {
+	dir := searchFixture(t)
+	for _, backend := range searchBackends(t) {
+		t.Run(backend.Name(), func(t *testing.T) {
+			results := runSearch(t, backend, dir, SearchOptions{Query: "hello", CaseSensitive: true})
+			var readme, util *SearchFileResult
+			for i := range results {
+				switch results[i].Path {
+				case "docs/readme.md":
+					readme = &results[i]
+				case "pkg/util.go":
+					util = &results[i]
+				}
+			}
+			if readme == nil || util == nil {
+				t.Fatalf("missing results: %+v", results)
+			}
+			// One match per occurrence, each with the full line
+			if len(readme.Matches) != 2 {
+				t.Fatalf("readme matches = %+v", readme.Matches)
+			}
+			for i, col := range []int{4, 16} {
+				m := readme.Matches[i]
+				if m.LineText != "say hello twice hello" || m.ColStart != col || m.ColEnd != col+5 {
+					t.Errorf("readme match %d = %+v", i, m)
+				}
+			}
+			// Line terminators are not part of the line text
+			if got := util.Matches[1].LineText; got != "func helloWorld() {}" {
+				t.Errorf("CRLF line text = %q", got)
+			}
+		})
+	}
+}
+
+func TestBuiltinBackend_InvalidRegex(t *testing.T) {
+	err := builtinBackend{}.Search(context.Background(), t.TempDir(), SearchOptions{Query: "(", UseRegex: true}, func(SearchFileResult) bool { return true })
+	if err == nil {
+		t.Error("expected error for invalid regex")
+	}
+}
+
+func TestSelectSearchBackend(t *testing.T) {
+	if b := selectSearchBackend("builtin"); b.Name() != searchBackendBuiltin {
+		t.Errorf("builtin -> %s", b.Name())
+	}
+	if b := selectSearchBackend("ripgrep"); b.Name() != searchBackendRipgrep {
+		t.Errorf("ripgrep -> %s", b.Name())
+	}
+	want := searchBackendBuiltin
+	if _, err := exec.LookPath("rg"); err == nil {
+		want = searchBackendRipgrep
+	}
+	if b := selectSearchBackend("auto"); b.Name() != want {
+		t.Errorf("auto -> %s, want %s", b.Name(), want)
+	}
+}
+
+func TestRunProjectSearch_Streams(t *testing.T) {
+	dir := searchFixture(t)
+	state := NewProjectSearchState()
+	state.Query = "hello"
+	state.Backend = searchBackendBuiltin
+
+	cmd := RunProjectSearch(dir, state, 7)
+	var final ProjectSearchResultsMsg
+	for {
+		msg, ok := cmd().(ProjectSearchResultsMsg)
+		if !ok {
+			t.Fatal("expected ProjectSearchResultsMsg")
+		}
+		if msg.Epoch != 7 || msg.Backend != searchBackendBuiltin || msg.stream != state.stream {
+			t.Fatalf("msg = %+v", msg)
+		}
+		if !msg.Partial {
+			final = msg
+			break
+		}
+		cmd = msg.stream.next
+	}
+	if final.Error != nil {
+		t.Fatalf("search error: %v", final.Error)
+	}
+	if got := len(final.Results); got != 3 {
+		t.Errorf("got %d files, want 3", got)
+	}
+}
+
+func TestRunProjectSearch_MaxMatches(t *testing.T) {
+	dir := t.TempDir()
+	content := strings.Repeat("match\n", projectSearchMaxPerFile)
+	for i := 0; i < 15; i++ {
+		name := filepath.Join(dir, "f"+strconv.Itoa(i)+".txt")
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stream := startProjectSearch(dir, builtinBackend{}, SearchOptions{Query: "match", MaxMatches: 250}, 0)
+	var final ProjectSearchResultsMsg
+	for msg := stream.next(); msg != nil; msg = stream.next() {
+		final = msg.(ProjectSearchResultsMsg)
+	}
+	total := 0
+	for _, r := range final.Results {
+		total += len(r.Matches)
+	}
+	if total != 250 {
+		t.Errorf("got %d matches, want 250", total)
+	}
+}
@@ -0,0 +1,706 @@
+package filebrowser
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	structuredMaxNodes = 100000 // Tree nodes parsed before the rest is dropped
+	structuredMaxRows  = 10000  // CSV/TSV rows read before the rest is dropped
+	structuredExpandTo = 2      // Tree depth expanded when a file is first opened
+	structuredMaxColW  = 40     // Widest a table column is drawn
+)
+
+// errStructuredLimit stops parsing once a size limit is reached.
+var errStructuredLimit = errors.New("structured view limit reached")
+
+// StructuredKind identifies a file format with a structured preview.
+type StructuredKind int
+
+const (
+	StructuredNone StructuredKind = iota
+	StructuredJSON
+	StructuredYAML
+	StructuredCSV
+	StructuredTSV
+	StructuredSQLite
+)
+
+// String returns the format's display name.
+func (k StructuredKind) String() string {
+	switch k {
+	case StructuredJSON:
+		return "JSON"
+	case StructuredYAML:
+		return "YAML"
+	case StructuredCSV:
+		return "CSV"
+	case StructuredTSV:
+		return "TSV"
+	case StructuredSQLite:
+		return "SQLite"
+	}
+	return ""
+}
+
+// IsTree reports whether the format is shown as a collapsible tree.
+func (k StructuredKind) IsTree() bool {
+	return k == StructuredJSON || k == StructuredYAML
+}
+
+// structuredKindFor returns the structured format for a path by extension.
+func structuredKindFor(path string) StructuredKind {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".jsonl", ".ndjson", ".geojson":
+		return StructuredJSON
+	case ".yaml", ".yml":
+		return StructuredYAML
+	case ".csv":
+		return StructuredCSV
+	case ".tsv", ".tab":
+		return StructuredTSV
+	case ".sqlite", ".sqlite3", ".db", ".db3":
+		return StructuredSQLite
+	}
+	return StructuredNone
+}
+
+// DataNodeKind is the shape of a value in a JSON or YAML document.
+type DataNodeKind int
+
+const (
+	DataScalar DataNodeKind = iota
+	DataObject
+	DataArray
+)
+
+// ScalarType classifies scalar values for coloring.
+type ScalarType int
+
+const (
+	ScalarString ScalarType = iota
+	ScalarNumber
+	ScalarBool
+	ScalarNull
+)
+
+// DataNode is a value in a parsed JSON or YAML document.
+type DataNode struct {
+	Key      string // Object key or array index; empty for the root
+	Kind     DataNodeKind
+	Scalar   ScalarType
+	Value    string // Scalar text
+	Path     string // jq-style path from the document root
+	Children []*DataNode
+}
+
+// Summary describes a container's size, e.g. "{3}" or "[12]".
+func (n *DataNode) Summary() string {
+	switch n.Kind {
+	case DataObject:
+		return fmt.Sprintf("{%d}", len(n.Children))
+	case DataArray:
+		return fmt.Sprintf("[%d]", len(n.Children))
+	}
+	return n.Value
+}
+
+// childPath returns the jq-style path of an object member or array element.
+func childPath(parent, key string, index int) string {
+	if index >= 0 {
+		return fmt.Sprintf("%s[%d]", parent, index)
+	}
+	if parent == "." {
+		parent = ""
+	}
+	if isPathIdent(key) {
+		return parent + "." + key
+	}
+	if parent == "" {
+		parent = "."
+	}
+	return parent + "[" + strconv.Quote(key) + "]"
+}
+
+func isPathIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (i > 0 && r >= '0' && r <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// dataRow is a visible line of the tree view.
+type dataRow struct {
+	Node  *DataNode
+	Depth int
+}
+
+// DataTable holds rows of a CSV/TSV file or a page of a SQLite table.
+type DataTable struct {
+	Columns []string
+	Rows    [][]string
+}
+
+// SQLiteTable is a table or view in a SQLite database.
+type SQLiteTable struct {
+	Name  string
+	Count int64
+}
+
+// StructuredView is the format-aware preview of the current file.
+type StructuredView struct {
+	Kind      StructuredKind
+	Path      string
+	Root      *DataNode  // JSON/YAML
+	Nodes     int        // Parsed tree nodes
+	Table     *DataTable // CSV/TSV, or the current SQLite page
+	Truncated bool       // Size limit reached; only the start of the file is shown
+
+	// SQLite
+	Tables   []SQLiteTable
+	TableIdx int
+	Page     int
+
+	// Cursor is the selected tree row or table row; Column the selected column.
+	Cursor int
+	Scroll int
+	Column int
+
+	// Table sorting: SortCol is -1 for file order
+	SortCol  int
+	SortDesc bool
+
+	expanded map[string]bool // Expanded tree paths
+	rows     []dataRow       // Visible tree rows, rebuilt on expand/collapse
+	order    []int           // Display order of table rows
+	widths   []int           // Column display widths
+}
+
+// newStructuredView returns an empty view for a file.
+func newStructuredView(kind StructuredKind, path string) *StructuredView {
+	return &StructuredView{Kind: kind, Path: path, SortCol: -1, expanded: make(map[string]bool)}
+}
+
+// inherit carries the cursor, expansion and sorting of a previous view of
+// the same file, so reloads don't lose the user's place.
+func (v *StructuredView) inherit(old *StructuredView) {
+	if old == nil || old.Path != v.Path || old.Kind != v.Kind {
+		return
+	}
+	v.Cursor, v.Scroll, v.Column = old.Cursor, old.Scroll, old.Column
+	if v.Kind.IsTree() {
+		v.expanded = old.expanded
+		v.rebuildRows()
+	} else if v.Kind != StructuredSQLite {
+		v.SortCol, v.SortDesc = old.SortCol, old.SortDesc
+		v.sortTable()
+	}
+	v.clampCursor()
+}
+
+// expandDefault expands the top levels of the tree.
+func (v *StructuredView) expandDefault() {
+	var walk func(n *DataNode, depth int)
+	walk = func(n *DataNode, depth int) {
+		if n.Kind == DataScalar || depth >= structuredExpandTo {
+			return
+		}
+		v.expanded[n.Path] = true
+		for _, c := range n.Children {
+			walk(c, depth+1)
+		}
+	}
+	if v.Root != nil {
+		walk(v.Root, 0)
+	}
+	v.rebuildRows()
+}
+
+// expandAll expands every container in the tree.
+func (v *StructuredView) expandAll() {
+	var walk func(n *DataNode)
+	walk = func(n *DataNode) {
+		if n.Kind == DataScalar {
+			return
+		}
+		v.expanded[n.Path] = true
+		for _, c := range n.Children {
+			walk(c)
+		}
+	}
+	if v.Root != nil {
+		walk(v.Root)
+	}
+	v.rebuildRows()
+}
+
+// rebuildRows flattens the expanded parts of the tree.
+func (v *StructuredView) rebuildRows() {
+	v.rows = v.rows[:0]
+	var walk func(n *DataNode, depth int)
+	walk = func(n *DataNode, depth int) {
+		v.rows = append(v.rows, dataRow{Node: n, Depth: depth})
+		if n.Kind != DataScalar && v.expanded[n.Path] {
+			for _, c := range n.Children {
+				walk(c, depth+1)
+			}
+		}
+	}
+	if v.Root != nil {
+		walk(v.Root, 0)
+	}
+}
+
+// RowCount returns the number of selectable rows.
+func (v *StructuredView) RowCount() int {
+	if v.Kind.IsTree() {
+		return len(v.rows)
+	}
+	if v.Table == nil {
+		return 0
+	}
+	return len(v.Table.Rows)
+}
+
+// CurrentNode returns the tree node under the cursor.
+func (v *StructuredView) CurrentNode() *DataNode {
+	if v.Cursor < 0 || v.Cursor >= len(v.rows) {
+		return nil
+	}
+	return v.rows[v.Cursor].Node
+}
+
+// SetExpanded expands or collapses the node under the cursor and reports
+// whether anything changed.
+func (v *StructuredView) SetExpanded(expand bool) bool {
+	n := v.CurrentNode()
+	if n == nil || n.Kind == DataScalar || len(n.Children) == 0 || v.expanded[n.Path] == expand {
+		return false
+	}
+	v.expanded[n.Path] = expand
+	v.rebuildRows()
+	return true
+}
+
+// CursorToParent moves the cursor to the parent of the current row and
+// reports whether it moved.
+func (v *StructuredView) CursorToParent() bool {
+	if v.Cursor <= 0 || v.Cursor >= len(v.rows) {
+		return false
+	}
+	depth := v.rows[v.Cursor].Depth
+	for i := v.Cursor - 1; i >= 0; i-- {
+		if v.rows[i].Depth < depth {
+			v.Cursor = i
+			return true
+		}
+	}
+	return false
+}
+
+// MoveCursor moves the cursor by delta rows.
+func (v *StructuredView) MoveCursor(delta int) {
+	v.Cursor += delta
+	v.clampCursor()
+}
+
+func (v *StructuredView) clampCursor() {
+	if n := v.RowCount(); v.Cursor >= n {
+		v.Cursor = n - 1
+	}
+	if v.Cursor < 0 {
+		v.Cursor = 0
+	}
+	if v.Table != nil && v.Column >= len(v.Table.Columns) {
+		v.Column = len(v.Table.Columns) - 1
+	}
+	if v.Column < 0 {
+		v.Column = 0
+	}
+}
+
+// EnsureVisible scrolls so the cursor is within a window of height rows.
+func (v *StructuredView) EnsureVisible(height int) {
+	if height < 1 {
+		height = 1
+	}
+	if v.Cursor < v.Scroll {
+		v.Scroll = v.Cursor
+	} else if v.Cursor >= v.Scroll+height {
+		v.Scroll = v.Cursor - height + 1
+	}
+	if v.Scroll < 0 {
+		v.Scroll = 0
+	}
+}
+
+// TableRow returns the table row at display position i.
+func (v *StructuredView) TableRow(i int) []string {
+	if v.Table == nil || i < 0 || i >= len(v.Table.Rows) {
+		return nil
+	}
+	if len(v.order) == len(v.Table.Rows) {
+		return v.Table.Rows[v.order[i]]
+	}
+	return v.Table.Rows[i]
+}
+
+// CycleSort sorts by the selected column: ascending, then descending, then
+// back to file order.
+func (v *StructuredView) CycleSort() {
+	switch {
+	case v.SortCol != v.Column:
+		v.SortCol, v.SortDesc = v.Column, false
+	case !v.SortDesc:
+		v.SortDesc = true
+	default:
+		v.SortCol, v.SortDesc = -1, false
+	}
+	v.sortTable()
+}
+
+// sortTable orders rows by SortCol, comparing numerically when both values
+// are numbers.
+func (v *StructuredView) sortTable() {
+	if v.Table == nil {
+		return
+	}
+	v.order = make([]int, len(v.Table.Rows))
+	for i := range v.order {
+		v.order[i] = i
+	}
+	col := v.SortCol
+	if col < 0 || col >= len(v.Table.Columns) || v.Kind == StructuredSQLite {
+		return // Databases return rows already sorted
+	}
+	cell := func(row int) string {
+		if r := v.Table.Rows[row]; col < len(r) {
+			return r[col]
+		}
+		return ""
+	}
+	sort.SliceStable(v.order, func(a, b int) bool {
+		x, y := cell(v.order[a]), cell(v.order[b])
+		if v.SortDesc {
+			x, y = y, x
+		}
+		return compareCells(x, y) < 0
+	})
+}
+
+func compareCells(a, b string) int {
+	fa, errA := strconv.ParseFloat(strings.TrimSpace(a), 64)
+	fb, errB := strconv.ParseFloat(strings.TrimSpace(b), 64)
+	switch {
+	case errA == nil && errB == nil:
+		if fa < fb {
+			return -1
+		} else if fa > fb {
+			return 1
+		}
+		return 0
+	case errA == nil:
+		return -1 // Numbers before text
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// ColumnWidths returns the display width of each column, capped at
+// structuredMaxColW.
+func (v *StructuredView) ColumnWidths() []int {
+	if v.widths != nil || v.Table == nil {
+		return v.widths
+	}
+	widths := make([]int, len(v.Table.Columns))
+	measure := func(i int, s string) {
+		if i < len(widths) {
+			widths[i] = min(max(widths[i], cellWidth(s)), structuredMaxColW)
+		}
+	}
+	for i, c := range v.Table.Columns {
+		measure(i, c)
+	}
+	for _, row := range v.Table.Rows {
+		for i, c := range row {
+			measure(i, c)
+		}
+	}
+	for i := range widths {
+		widths[i] = max(widths[i], 1)
+	}
+	v.widths = widths
+	return widths
+}
+
+// setTable replaces the table and resets derived state.
+func (v *StructuredView) setTable(t *DataTable) {
+	v.Table = t
+	v.widths = nil
+	v.sortTable()
+	v.clampCursor()
+}
+
+// StructuredLoadedMsg delivers the structured view of a file.
+type StructuredLoadedMsg struct {
+	Epoch uint64
+	Path  string
+	View  *StructuredView
+	Err   error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m StructuredLoadedMsg) GetEpoch() uint64 { return m.Epoch }
+
+// LoadStructured parses a file for the structured view. Files are streamed
+// and parsing stops at structuredMaxNodes or structuredMaxRows, so large
+// files never load completely.
+func LoadStructured(workDir, path string, epoch uint64) tea.Cmd {
+	return func() tea.Msg {
+		v, err := loadStructured(filepath.Join(workDir, path), path)
+		return StructuredLoadedMsg{Epoch: epoch, Path: path, View: v, Err: err}
+	}
+}
+
+func loadStructured(fullPath, path string) (*StructuredView, error) {
+	kind := structuredKindFor(path)
+	v := newStructuredView(kind, path)
+	if kind == StructuredSQLite {
+		return v, loadSQLite(v, fullPath)
+	}
+
+	f, err := os.Open(fullPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	r := bufio.NewReader(f)
+
+	switch kind {
+	case StructuredJSON:
+		v.Root, v.Nodes, err = parseJSONTree(r)
+	case StructuredYAML:
+		v.Root, v.Nodes, err = parseYAMLTree(r)
+	case StructuredCSV:
+		v.Table, err = parseDelimited(r, ',')
+	case StructuredTSV:
+		v.Table, err = parseDelimited(r, '\t')
+	default:
+		return nil, fmt.Errorf("no structured view for %s", filepath.Base(path))
+	}
+	if errors.Is(err, errStructuredLimit) {
+		v.Truncated, err = true, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if kind.IsTree() {
+		v.expandDefault()
+	} else {
+		v.setTable(v.Table)
+	}
+	return v, nil
+}
+
+// parseJSONTree decodes a JSON document token by token. Several top-level
+// values (JSON Lines) become an array of documents.
+func parseJSONTree(r io.Reader) (*DataNode, int, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	b := &jsonTreeBuilder{dec: dec}
+
+	var docs []*DataNode
+	for {
+		if b.limit() != nil {
+			break
+		}
+		node := &DataNode{}
+		err := b.fill(node)
+		if err == io.EOF {
+			break
+		}
+		docs = append(docs, node)
+		if err != nil {
+			if errors.Is(err, errStructuredLimit) {
+				break
+			}
+			return nil, b.nodes, err
+		}
+		if !dec.More() {
+			break
+		}
+	}
+
+	switch len(docs) {
+	case 0:
+		return nil, 0, errors.New("empty document")
+	case 1:
+		setPaths(docs[0], ".")
+		return docs[0], b.nodes, b.err
+	}
+	root := &DataNode{Kind: DataArray, Children: docs}
+	for i, d := range docs {
+		d.Key = strconv.Itoa(i)
+	}
+	setPaths(root, ".")
+	return root, b.nodes, b.err
+}
+
+type jsonTreeBuilder struct {
+	dec   *json.Decoder
+	nodes int
+	err   error // errStructuredLimit once the node limit is hit
+}
+
+// limit reports errStructuredLimit once structuredMaxNodes have been read.
+func (b *jsonTreeBuilder) limit() error {
+	if b.nodes >= structuredMaxNodes {
+		b.err = errStructuredLimit
+	}
+	return b.err
+}
+
+// fill reads the next JSON value into node. Children are attached before
+// they are read, so a partial tree survives the node limit.
+func (b *jsonTreeBuilder) fill(node *DataNode) error {
+	tok, err := b.dec.Token()
+	if err != nil {
+		return err
+	}
+	b.nodes++
+
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '{' {
+			node.Kind = DataObject
+			for b.dec.More() {
+				kt, err := b.dec.Token()
+				if err != nil {
+					return err
+				}
+				if err := b.limit(); err != nil {
+					return err
+				}
+				key, _ := kt.(string)
+				child := &DataNode{Key: key}
+				node.Children = append(node.Children, child)
+				if err := b.fill(child); err != nil {
+					return err
+				}
+			}
+		} else {
+			node.Kind = DataArray
+			for i := 0; b.dec.More(); i++ {
+				if err := b.limit(); err != nil {
+					return err
+				}
+				child := &DataNode{Key: strconv.Itoa(i)}
+				node.Children = append(node.Children, child)
+				if err := b.fill(child); err != nil {
+					return err
+				}
+			}
+		}
+		_, err := b.dec.Token() // Closing delimiter
+		return err
+	case string:
+		node.Scalar, node.Value = ScalarString, t
+	case json.Number:
+		node.Scalar, node.Value = ScalarNumber, t.String()
+	case bool:
+		node.Scalar, node.Value = ScalarBool, strconv.FormatBool(t)
+	case nil:
+		node.Scalar, node.Value = ScalarNull, "null"
+	}
+	return nil
+}
+
+// setPaths assigns jq-style paths below node.
+func setPaths(node *DataNode, path string) {
+	node.Path = path
+	for i, c := range node.Children {
+		idx := -1
+		if node.Kind == DataArray {
+			idx = i
+		}
+		setPaths(c, childPath(path, c.Key, idx))
+	}
+}
+
+// parseDelimited reads a CSV or TSV file with its first row as the header.
+func parseDelimited(r io.Reader, comma rune) (*DataTable, error) {
+	next := tsvReader(r)
+	if comma != '\t' {
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+		cr.LazyQuotes = true
+		next = cr.Read
+	}
+
+	t := &DataTable{}
+	header, err := next()
+	if err == io.EOF {
+		return t, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.Columns = header
+
+	for {
+		if len(t.Rows) >= structuredMaxRows {
+			return t, errStructuredLimit
+		}
+		rec, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var perr *csv.ParseError
+			if errors.As(err, &perr) && len(t.Rows) > 0 {
+				break // Show what parsed before the malformed row
+			}
+			return nil, err
+		}
+		for len(t.Columns) < len(rec) {
+			t.Columns = append(t.Columns, fmt.Sprintf("column%d", len(t.Columns)+1))
+		}
+		t.Rows = append(t.Rows, rec)
+	}
+	return t, nil
+}
+
+// tsvReader returns a record reader for tab-separated values, where quotes
+// have no special meaning.
+func tsvReader(r io.Reader) func() ([]string, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	return func() ([]string, error) {
+		if !sc.Scan() {
+			if err := sc.Err(); err != nil {
+				return nil, err
+			}
+			return nil, io.EOF
+		}
+		return strings.Split(strings.TrimSuffix(sc.Text(), "\r"), "\t"), nil
+	}
+}
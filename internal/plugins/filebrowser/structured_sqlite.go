@@ -0,0 +1,208 @@
+package filebrowser
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	_ "modernc.org/sqlite"
+)
+
+// sqlitePageSize is the number of rows loaded per page of a table.
+const sqlitePageSize = 200
+
+// sqliteMagic starts every SQLite 3 database file.
+var sqliteMagic = []byte("SQLite format 3\x00")
+
+// sqliteQuery selects a page of a table in a given order.
+type sqliteQuery struct {
+	Table   string
+	Page    int
+	OrderBy string // Column to sort by; empty for table order
+	Desc    bool
+}
+
+// SQLitePageMsg delivers a page of rows from a SQLite table.
+type SQLitePageMsg struct {
+	Epoch uint64
+	Path  string
+	Query sqliteQuery
+	Data  *DataTable
+	Count int64 // Rows in the table, -1 if not counted
+	Err   error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m SQLitePageMsg) GetEpoch() uint64 { return m.Epoch }
+
+// LoadSQLitePage reads one page of rows from a table, and counts the
+// table's rows when count is set.
+func LoadSQLitePage(workDir, path string, q sqliteQuery, count bool, epoch uint64) tea.Cmd {
+	return func() tea.Msg {
+		data, n, err := querySQLitePage(filepath.Join(workDir, path), q, count)
+		return SQLitePageMsg{Epoch: epoch, Path: path, Query: q, Data: data, Count: n, Err: err}
+	}
+}
+
+// isSQLiteFile checks the database header.
+func isSQLiteFile(fullPath string) bool {
+	f, err := os.Open(fullPath)
+	if err != nil {
+		return false
+	}
+	defer func() { _ = f.Close() }()
+	header := make([]byte, len(sqliteMagic))
+	n, _ := f.Read(header)
+	return bytes.Equal(header[:n], sqliteMagic)
+}
+
+// openSQLite opens a database read-only.
+func openSQLite(fullPath string) (*sql.DB, error) {
+	if !isSQLiteFile(fullPath) {
+		return nil, errors.New("not a SQLite database")
+	}
+	// The driver only honors query parameters on file: URIs
+	dsn := (&url.URL{Scheme: "file", Path: fullPath, RawQuery: "mode=ro"}).String()
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	// Read-only browsing needs a single connection; don't hold idle FDs
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(0)
+	db.SetConnMaxLifetime(time.Second)
+	return db, nil
+}
+
+// loadSQLite lists the tables and views of a database and loads the first
+// page of the first one. Rows are counted only for that table; other tables
+// are counted when selected, so opening a large database stays fast.
+func loadSQLite(v *StructuredView, fullPath string) error {
+	db, err := openSQLite(fullPath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+
+	rows, err := db.Query(`SELECT name FROM sqlite_master
+		WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%'
+		ORDER BY name`)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		v.Tables = append(v.Tables, SQLiteTable{Name: name, Count: -1})
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(v.Tables) == 0 {
+		v.setTable(&DataTable{})
+		return nil
+	}
+	v.Tables[0].Count = sqliteCount(db, v.Tables[0].Name)
+	data, err := sqlitePage(db, sqliteQuery{Table: v.Tables[0].Name})
+	if err != nil {
+		return err
+	}
+	v.setTable(data)
+	return nil
+}
+
+func querySQLitePage(fullPath string, q sqliteQuery, count bool) (*DataTable, int64, error) {
+	db, err := openSQLite(fullPath)
+	if err != nil {
+		return nil, -1, err
+	}
+	defer func() { _ = db.Close() }()
+	n := int64(-1)
+	if count {
+		n = sqliteCount(db, q.Table)
+	}
+	data, err := sqlitePage(db, q)
+	return data, n, err
+}
+
+// sqliteCount returns the number of rows in a table, or -1 if it can't be
+// counted.
+func sqliteCount(db *sql.DB, table string) int64 {
+	var n int64
+	if err := db.QueryRow("SELECT COUNT(*) FROM " + quoteSQLIdent(table)).Scan(&n); err != nil {
+		return -1
+	}
+	return n
+}
+
+// sqlitePage reads rows [Page*sqlitePageSize, (Page+1)*sqlitePageSize).
+func sqlitePage(db *sql.DB, q sqliteQuery) (*DataTable, error) {
+	query := "SELECT * FROM " + quoteSQLIdent(q.Table)
+	if q.OrderBy != "" {
+		query += " ORDER BY " + quoteSQLIdent(q.OrderBy)
+		if q.Desc {
+			query += " DESC"
+		}
+	}
+	rows, err := db.Query(query+" LIMIT ? OFFSET ?", sqlitePageSize, q.Page*sqlitePageSize)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	t := &DataTable{Columns: cols}
+	values := make([]any, len(cols))
+	ptrs := make([]any, len(cols))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		row := make([]string, len(cols))
+		for i, v := range values {
+			row[i] = formatSQLValue(v)
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t, rows.Err()
+}
+
+// formatSQLValue renders a column value for display.
+func formatSQLValue(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		if utf8.Valid(x) && !bytes.ContainsRune(x, 0) {
+			return string(x)
+		}
+		return fmt.Sprintf("<blob %s>", formatSize(int64(len(x))))
+	case time.Time:
+		return x.Format(time.RFC3339)
+	}
+	return fmt.Sprint(v)
+}
+
+// quoteSQLIdent quotes a table name for use in a query.
+func quoteSQLIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,406 @@
+package filebrowser
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// treeSummary renders a tree as "path=value" lines, one per node.
+func treeSummary(n *DataNode) string {
+	var lines []string
+	var walk func(n *DataNode)
+	walk = func(n *DataNode) {
+		lines = append(lines, n.Path+"="+n.Summary())
+		for _, c := range n.Children {
+			walk(c)
+		}
+	}
+	walk(n)
+	return strings.Join(lines, "\n")
+}
+
+func TestParseJSONTree(t *testing.T) {
+	src := `{"users": [{"name": "ada", "age": 36, "admin": true}], "odd key": null, "n": 1.5e3}`
+	root, nodes, err := parseJSONTree(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		".={3}",
+		".users=[1]",
+		".users[0]={3}",
+		".users[0].name=ada",
+		".users[0].age=36",
+		".users[0].admin=true",
+		`.["odd key"]=null`,
+		".n=1.5e3",
+	}, "\n")
+	if got := treeSummary(root); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if nodes != 8 {
+		t.Errorf("nodes = %d, want 8", nodes)
+	}
+	if n := root.Children[0].Children[0].Children[1]; n.Scalar != ScalarNumber {
+		t.Errorf("age scalar type = %d", n.Scalar)
+	}
+}
+
+func TestParseJSONTree_Lines(t *testing.T) {
+	root, _, err := parseJSONTree(strings.NewReader("{\"a\": 1}\n{\"a\": 2}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root.Kind != DataArray || len(root.Children) != 2 || root.Children[1].Children[0].Path != ".[1].a" {
+		t.Errorf("got:\n%s", treeSummary(root))
+	}
+}
+
+func TestParseJSONTree_Limit(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("[")
+	for i := 0; i < structuredMaxNodes+10; i++ {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		fmt.Fprintf(&sb, "%d", i)
+	}
+	sb.WriteString("]")
+
+	root, nodes, err := parseJSONTree(strings.NewReader(sb.String()))
+	if !errors.Is(err, errStructuredLimit) {
+		t.Fatalf("err = %v, want limit", err)
+	}
+	if nodes != structuredMaxNodes || root == nil || len(root.Children) != structuredMaxNodes-1 {
+		t.Errorf("nodes = %d, children = %d", nodes, len(root.Children))
+	}
+}
+
+func TestParseYAMLTree(t *testing.T) {
+	src := `# service config
+name: api   # trailing comment
+replicas: 3
+enabled: yes
+empty:
+"quoted key": 'it''s'
+env:
+  - name: PORT
+    value: "8080"
+  - DEBUG
+ports: [80, 443]
+labels: {app: web, tier: "front, end"}
+script: |
+  echo one
+  # not a comment
+  echo two
+summary: >
+  folded
+  text
+tags:
+- a
+- b
+anchor: &base !!str value
+`
+	root, _, err := parseYAMLTree(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		".={12}",
+		".name=api",
+		".replicas=3",
+		".enabled=yes",
+		".empty=null",
+		`.["quoted key"]=it's`,
+		".env=[2]",
+		".env[0]={2}",
+		".env[0].name=PORT",
+		".env[0].value=8080",
+		".env[1]=DEBUG",
+		".ports=[2]",
+		".ports[0]=80",
+		".ports[1]=443",
+		".labels={2}",
+		".labels.app=web",
+		".labels.tier=front, end",
+		".script=echo one\n# not a comment\necho two\n",
+		".summary=folded text\n",
+		".tags=[2]",
+		".tags[0]=a",
+		".tags[1]=b",
+		".anchor=value",
+	}, "\n")
+	if got := treeSummary(root); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if n := root.Children[1]; n.Scalar != ScalarNumber {
+		t.Errorf("replicas scalar type = %d", n.Scalar)
+	}
+	if n := root.Children[5].Children[0].Children[1]; n.Scalar != ScalarString {
+		t.Errorf("quoted number should stay a string, got type %d", n.Scalar)
+	}
+}
+
+func TestParseYAMLTree_Documents(t *testing.T) {
+	root, _, err := parseYAMLTree(strings.NewReader("---\na: 1\n---\n- x\n...\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := treeSummary(root); got != ".=[2]\n.[0]={1}\n.[0].a=1\n.[1]=[1]\n.[1][0]=x" {
+		t.Errorf("got:\n%s", got)
+	}
+
+	if _, _, err := parseYAMLTree(strings.NewReader("a: 1\n  b: 2\n")); err == nil {
+		t.Error("expected error for bad indentation")
+	}
+}
+
+func TestParseYAMLTree_Anchors(t *testing.T) {
+	src := `base: &base
+  image: web
+  port: 80
+copy: *base
+merged:
+  <<: *base
+  port: !!str 8080
+`
+	root, _, err := parseYAMLTree(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		".={3}",
+		".base={2}",
+		".base.image=web",
+		".base.port=80",
+		".copy={2}",
+		".copy.image=web",
+		".copy.port=80",
+		".merged={2}",
+		`.merged["<<"]={2}`,
+		`.merged["<<"].image=web`,
+		`.merged["<<"].port=80`,
+		".merged.port=8080",
+	}, "\n")
+	if got := treeSummary(root); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if n := root.Children[2].Children[1]; n.Scalar != ScalarString {
+		t.Errorf("!!str tag should make a string, got type %d", n.Scalar)
+	}
+
+	// Constructs without a tree form are errors, not a wrong tree
+	if _, _, err := parseYAMLTree(strings.NewReader("? [a, b]\n: c\n")); err == nil || !strings.Contains(err.Error(), "unsupported") {
+		t.Errorf("err = %v, want unsupported construct", err)
+	}
+	if _, _, err := parseYAMLTree(strings.NewReader("a: &x [*x]\n")); err == nil {
+		t.Error("expected error for a recursive alias")
+	}
+}
+
+func TestStructuredTreeNavigation(t *testing.T) {
+	root, nodes, err := parseJSONTree(strings.NewReader(`{"a": {"b": {"c": 1}}, "d": [1, 2]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := newStructuredView(StructuredJSON, "x.json")
+	v.Root, v.Nodes = root, nodes
+	v.expandDefault()
+
+	// Root and its children are expanded; grandchildren are not
+	if len(v.rows) != 6 {
+		t.Fatalf("rows = %d, want 6", len(v.rows))
+	}
+	v.Cursor = 2 // .a.b
+	if n := v.CurrentNode(); n.Path != ".a.b" || v.expanded[n.Path] {
+		t.Fatalf("cursor on %s", n.Path)
+	}
+	if !v.SetExpanded(true) || len(v.rows) != 7 {
+		t.Errorf("expand: rows = %d", len(v.rows))
+	}
+	v.Cursor = 3 // .a.b.c
+	if !v.CursorToParent() || v.CurrentNode().Path != ".a.b" {
+		t.Errorf("parent: %s", v.CurrentNode().Path)
+	}
+
+	// A reload keeps expansion and cursor
+	reloaded := newStructuredView(StructuredJSON, "x.json")
+	reloaded.Root = root
+	reloaded.expandDefault()
+	reloaded.inherit(v)
+	if len(reloaded.rows) != 7 || reloaded.CurrentNode().Path != ".a.b" {
+		t.Errorf("inherit: rows=%d cursor=%s", len(reloaded.rows), reloaded.CurrentNode().Path)
+	}
+}
+
+func TestParseDelimitedAndSort(t *testing.T) {
+	table, err := parseDelimited(strings.NewReader("name,size\nb,10\na,9\n\"c, d\",100,extra\n"), ',')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(table.Columns, "|") != "name|size|column3" || len(table.Rows) != 3 || table.Rows[2][0] != "c, d" {
+		t.Fatalf("table = %+v", table)
+	}
+
+	v := newStructuredView(StructuredCSV, "x.csv")
+	v.setTable(table)
+	v.Column = 1
+	order := func() string {
+		var names []string
+		for i := 0; i < v.RowCount(); i++ {
+			names = append(names, v.TableRow(i)[0])
+		}
+		return strings.Join(names, ",")
+	}
+
+	v.CycleSort()
+	if got := order(); got != "a,b,c, d" {
+		t.Errorf("ascending numeric sort = %s", got)
+	}
+	v.CycleSort()
+	if got := order(); got != "c, d,b,a" {
+		t.Errorf("descending sort = %s", got)
+	}
+	v.CycleSort()
+	if got := order(); got != "b,a,c, d" || v.SortCol != -1 {
+		t.Errorf("file order = %s", got)
+	}
+
+	tsv, err := parseDelimited(strings.NewReader("a\tb\r\nsay \"hi\"\t2\r\n"), '\t')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tsv.Rows) != 1 || tsv.Rows[0][0] != `say "hi"` || tsv.Rows[0][1] != "2" {
+		t.Errorf("tsv = %+v", tsv)
+	}
+}
+
+func TestLoadStructured_SQLite(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "app.db")
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmts := []string{
+		`CREATE TABLE "user data" (id INTEGER, name TEXT, avatar BLOB)`,
+		`CREATE TABLE empty (x INTEGER)`,
+		`CREATE VIEW names AS SELECT name FROM "user data"`,
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < sqlitePageSize+5; i++ {
+		if _, err := db.Exec(`INSERT INTO "user data" VALUES (?, ?, ?)`, i, fmt.Sprintf("user%03d", i), []byte{0, 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = db.Close()
+
+	v, err := loadStructured(dbPath, "app.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tables []string
+	for _, tbl := range v.Tables {
+		tables = append(tables, fmt.Sprintf("%s:%d", tbl.Name, tbl.Count))
+	}
+	// Only the first table is counted up front
+	if got := strings.Join(tables, ","); got != "empty:0,names:-1,user data:-1" {
+		t.Fatalf("tables = %s", got)
+	}
+	if len(v.Table.Rows) != 0 || v.Table.Columns[0] != "x" {
+		t.Errorf("first table = %+v", v.Table)
+	}
+
+	page, count, err := querySQLitePage(dbPath, sqliteQuery{Table: "user data", Page: 1, OrderBy: "id", Desc: true}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 205 {
+		t.Errorf("count = %d, want 205", count)
+	}
+	if len(page.Rows) != 5 || page.Rows[0][0] != "4" || page.Rows[0][2] != "<blob 2B>" {
+		t.Errorf("page 2 desc = %v", page.Rows)
+	}
+
+	// The browser must never write to the database
+	ro, err := openSQLite(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ro.Exec(`INSERT INTO empty VALUES (1)`); err == nil {
+		t.Error("expected write through the browser's handle to fail")
+	}
+	_ = ro.Close()
+
+	if err := os.WriteFile(filepath.Join(dir, "notes.db"), []byte("plain text"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadStructured(filepath.Join(dir, "notes.db"), "notes.db"); err == nil {
+		t.Error("expected error for a non-SQLite .db file")
+	}
+}
+
+func TestStructuredViewKeys(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := `{"a": {"b": 1}, "list": [1, 2, 3]}`
+	if err := os.WriteFile(filepath.Join(tmpDir, "data.json"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p := createTestPluginWithPreview(t, tmpDir, content)
+	p.previewFile = "data.json"
+
+	_, cmd := p.handlePreviewKey("v")
+	if !p.structuredActive() || cmd == nil {
+		t.Fatal("v should switch to the structured view and load it")
+	}
+	_, _ = p.Update(cmd())
+	if p.structured == nil || p.FocusContext() != "file-browser-structured" {
+		t.Fatalf("structured view not loaded: err=%v", p.structuredErr)
+	}
+	p.previewWidth = 60
+	if out := ansi.Strip(p.renderStructuredView(10)); !strings.Contains(out, "▾ list: [3]") || !strings.Contains(out, "JSON · 7 nodes") {
+		t.Errorf("render:\n%s", out)
+	}
+
+	_, _ = p.handlePreviewKey("j")
+	_, _ = p.handlePreviewKey("j")
+	_, _ = p.handlePreviewKey("j")
+	if n := p.structured.CurrentNode(); n == nil || n.Path != ".list" {
+		t.Fatalf("cursor on %v", n)
+	}
+	_, _ = p.handlePreviewKey("enter")
+	if p.structured.RowCount() != 4 {
+		t.Errorf("collapse: rows = %d", p.structured.RowCount())
+	}
+
+	// h climbs to the root, collapses it, then leaves the pane
+	_, _ = p.handlePreviewKey("h")
+	if p.activePane != PanePreview || p.structured.Cursor != 0 {
+		t.Fatalf("h should climb to the root first, cursor=%d", p.structured.Cursor)
+	}
+	_, _ = p.handlePreviewKey("h")
+	if p.structured.RowCount() != 1 {
+		t.Fatalf("h on the root should collapse it, rows = %d", p.structured.RowCount())
+	}
+	_, _ = p.handlePreviewKey("h")
+	if p.activePane != PaneTree {
+		t.Error("h on the collapsed root should return to the tree")
+	}
+
+	p.activePane = PanePreview
+	_, _ = p.handlePreviewKey("v")
+	if p.structuredActive() {
+		t.Error("v should return to the text view")
+	}
+}
@@ -0,0 +1,151 @@
+package filebrowser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// structuredMaxYAMLBytes caps the YAML read for the tree view. Unlike JSON,
+// a YAML document is decoded whole, so it can't stop at the node limit.
+const structuredMaxYAMLBytes = 16 * 1024 * 1024
+
+// parseYAMLTree decodes a YAML stream. Aliases are expanded to the node
+// their anchor marks and tags resolve scalar types. Several documents
+// become an array of documents.
+func parseYAMLTree(r io.Reader) (*DataNode, int, error) {
+	data, err := io.ReadAll(io.LimitReader(r, structuredMaxYAMLBytes+1))
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(data) > structuredMaxYAMLBytes {
+		return nil, 0, fmt.Errorf("YAML file is larger than %dMB", structuredMaxYAMLBytes/(1024*1024))
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	b := &yamlTreeBuilder{}
+	var docs []*DataNode
+	for b.err == nil {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, b.nodes, err
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+		node := &DataNode{}
+		docs = append(docs, node)
+		if err := b.fill(node, doc.Content[0]); err != nil && !errors.Is(err, errStructuredLimit) {
+			return nil, b.nodes, err
+		}
+	}
+
+	switch len(docs) {
+	case 0:
+		return nil, 0, errors.New("empty document")
+	case 1:
+		setPaths(docs[0], ".")
+		return docs[0], b.nodes, b.err
+	}
+	root := &DataNode{Kind: DataArray, Children: docs}
+	for i, d := range docs {
+		d.Key = strconv.Itoa(i)
+	}
+	setPaths(root, ".")
+	return root, b.nodes, b.err
+}
+
+type yamlTreeBuilder struct {
+	nodes    int
+	err      error               // errStructuredLimit once the node limit is hit
+	visiting map[*yaml.Node]bool // anchors being expanded, to catch alias cycles
+}
+
+// fill converts n into node. Children are attached before they are filled,
+// so a partial tree survives the node limit.
+func (b *yamlTreeBuilder) fill(node *DataNode, n *yaml.Node) error {
+	if b.nodes >= structuredMaxNodes {
+		b.err = errStructuredLimit
+		return b.err
+	}
+	b.nodes++
+
+	switch n.Kind {
+	case yaml.AliasNode:
+		if b.visiting[n.Alias] {
+			return fmt.Errorf("line %d: alias *%s refers to itself", n.Line, n.Value)
+		}
+		if b.visiting == nil {
+			b.visiting = make(map[*yaml.Node]bool)
+		}
+		b.visiting[n.Alias] = true
+		defer delete(b.visiting, n.Alias)
+		b.nodes--
+		return b.fill(node, n.Alias)
+
+	case yaml.MappingNode:
+		node.Kind = DataObject
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, err := yamlKey(n.Content[i])
+			if err != nil {
+				return err
+			}
+			child := &DataNode{Key: key}
+			node.Children = append(node.Children, child)
+			if err := b.fill(child, n.Content[i+1]); err != nil {
+				return err
+			}
+		}
+
+	case yaml.SequenceNode:
+		node.Kind = DataArray
+		for i, item := range n.Content {
+			child := &DataNode{Key: strconv.Itoa(i)}
+			node.Children = append(node.Children, child)
+			if err := b.fill(child, item); err != nil {
+				return err
+			}
+		}
+
+	case yaml.ScalarNode:
+		setYAMLScalar(node, n)
+
+	default:
+		return fmt.Errorf("line %d: unsupported YAML node", n.Line)
+	}
+	return nil
+}
+
+// yamlKey returns the text of a mapping key. Keys that are collections
+// have no path form and are rejected rather than shown flattened.
+func yamlKey(n *yaml.Node) (string, error) {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	if n.Kind != yaml.ScalarNode {
+		return "", fmt.Errorf("line %d: unsupported construct: collection used as a mapping key", n.Line)
+	}
+	return n.Value, nil
+}
+
+// setYAMLScalar stores a scalar and the type its tag resolves to.
+func setYAMLScalar(node *DataNode, n *yaml.Node) {
+	node.Kind = DataScalar
+	switch n.ShortTag() {
+	case "!!null":
+		node.Scalar, node.Value = ScalarNull, "null"
+	case "!!bool":
+		node.Scalar, node.Value = ScalarBool, strings.ToLower(n.Value)
+	case "!!int", "!!float":
+		node.Scalar, node.Value = ScalarNumber, n.Value
+	default:
+		node.Scalar, node.Value = ScalarString, n.Value
+	}
+}
@@ -0,0 +1,302 @@
+package filebrowser
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	symbolIndexTimeout  = 10 * time.Second // Max time to spend indexing a project
+	symbolIndexMaxFiles = 20000            // Max files to index (prevents stalls on huge repos)
+	symbolMaxResults    = 100              // Max palette matches to show
+)
+
+// SymbolScope selects the symbols listed in the symbol palette.
+type SymbolScope int
+
+const (
+	SymbolScopeFile        SymbolScope = iota // Outline of the previewed file
+	SymbolScopeProject                        // All symbols in the project
+	SymbolScopeDefinitions                    // Candidate definitions from go-to-definition
+)
+
+// SymbolIndex holds the symbols of every indexed file in a project.
+type SymbolIndex struct {
+	Symbols []Symbol
+	Limited bool // Indexing stopped at the file limit or timeout
+	byName  map[string][]int
+}
+
+// NewSymbolIndex builds an index over syms, sorted by path then line.
+func NewSymbolIndex(syms []Symbol) *SymbolIndex {
+	sort.SliceStable(syms, func(i, j int) bool {
+		if syms[i].Path != syms[j].Path {
+			return syms[i].Path < syms[j].Path
+		}
+		return syms[i].Line < syms[j].Line
+	})
+	idx := &SymbolIndex{Symbols: syms, byName: make(map[string][]int)}
+	for i, s := range syms {
+		idx.byName[s.Name] = append(idx.byName[s.Name], i)
+	}
+	return idx
+}
+
+// Definitions returns the symbols named name.
+func (idx *SymbolIndex) Definitions(name string) []Symbol {
+	if idx == nil {
+		return nil
+	}
+	var defs []Symbol
+	for _, i := range idx.byName[name] {
+		defs = append(defs, idx.Symbols[i])
+	}
+	return defs
+}
+
+// SymbolIndexMsg delivers a built project symbol index.
+type SymbolIndexMsg struct {
+	Epoch uint64
+	Index *SymbolIndex
+	Err   error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m SymbolIndexMsg) GetEpoch() uint64 { return m.Epoch }
+
+// SymbolOutlineMsg delivers the symbols of a previewed file for the outline.
+type SymbolOutlineMsg struct {
+	Epoch   uint64
+	Path    string // Previewed file the symbols belong to
+	Line    int    // 1-based preview line when the outline was requested
+	Query   string // Palette query to keep (set when toggling scope)
+	Symbols []Symbol
+	Err     error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m SymbolOutlineMsg) GetEpoch() uint64 { return m.Epoch }
+
+// BuildSymbolIndex indexes the project in the background. Files are found
+// the same way as the built-in project search, so ignored, hidden, binary
+// and oversized files are skipped.
+func BuildSymbolIndex(workDir string, epoch uint64) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), symbolIndexTimeout)
+		defer cancel()
+		idx, err := buildSymbolIndex(ctx, workDir)
+		return SymbolIndexMsg{Epoch: epoch, Index: idx, Err: err}
+	}
+}
+
+func buildSymbolIndex(ctx context.Context, workDir string) (*SymbolIndex, error) {
+	var files, ctagsFiles []string
+	limited := false
+	useCtags := hasUniversalCtags()
+	err := walkSearchFiles(ctx, workDir, "", nil, func(rel string) bool {
+		e := extractorFor(rel)
+		if e == nil {
+			return true
+		}
+		if len(files)+len(ctagsFiles) >= symbolIndexMaxFiles {
+			limited = true
+			return false
+		}
+		// ctags indexes a whole batch in one process; other extractors run per file
+		if useCtags && e.Name() == "ctags" {
+			ctagsFiles = append(ctagsFiles, rel)
+		} else {
+			files = append(files, rel)
+		}
+		return true
+	})
+	if err != nil && ctx.Err() == nil {
+		return nil, err
+	}
+	if ctx.Err() != nil {
+		limited = true
+	}
+
+	var (
+		mu   sync.Mutex
+		syms []Symbol
+	)
+	paths := make(chan string, 64)
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for rel := range paths {
+				if ctx.Err() != nil {
+					continue // Drain remaining paths
+				}
+				fileSyms := extractFileSymbols(workDir, rel)
+				mu.Lock()
+				syms = append(syms, fileSyms...)
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, rel := range files {
+		paths <- rel
+	}
+	close(paths)
+	wg.Wait()
+
+	if len(ctagsFiles) > 0 {
+		syms = append(syms, runCtagsBatch(ctx, workDir, ctagsFiles)...)
+	}
+
+	idx := NewSymbolIndex(syms)
+	idx.Limited = limited || ctx.Err() != nil
+	return idx, nil
+}
+
+// extractFileSymbols reads and extracts one file, skipping binary and
+// oversized files.
+func extractFileSymbols(workDir, rel string) []Symbol {
+	full := filepath.Join(workDir, filepath.FromSlash(rel))
+	info, err := os.Stat(full)
+	if err != nil || info.Size() > projectSearchMaxFileSize {
+		return nil
+	}
+	data, err := os.ReadFile(full)
+	if err != nil || isBinary(data) {
+		return nil
+	}
+	syms, _ := ExtractSymbols(workDir, rel, data)
+	return syms
+}
+
+// runCtagsBatch indexes files with a single ctags process, reading the file
+// list from stdin. Falls back to the built-in patterns if ctags fails.
+func runCtagsBatch(ctx context.Context, workDir string, files []string) []Symbol {
+	cmd := exec.CommandContext(ctx, "ctags", ctagsArgs("-L", "-")...)
+	cmd.Dir = workDir
+	cmd.Stdin = strings.NewReader(strings.Join(files, "\n") + "\n")
+	out, err := cmd.Output()
+	if err == nil {
+		syms := parseCtagsJSON(out)
+		for i := range syms {
+			syms[i].Path = filepath.ToSlash(syms[i].Path)
+		}
+		return syms
+	}
+
+	var syms []Symbol
+	for _, rel := range files {
+		data, err := os.ReadFile(filepath.Join(workDir, filepath.FromSlash(rel)))
+		if err != nil || isBinary(data) {
+			continue
+		}
+		fileSyms, _ := patternSymbolExtractor{}.Extract(rel, data)
+		for i := range fileSyms {
+			fileSyms[i].Path = rel
+		}
+		syms = append(syms, fileSyms...)
+	}
+	return syms
+}
+
+// SymbolMatch is a symbol matching the palette query.
+type SymbolMatch struct {
+	Symbol      Symbol
+	Score       int
+	MatchRanges []MatchRange // Ranges within Symbol.QualifiedName()
+}
+
+// FilterSymbols fuzzy-matches query against qualified symbol names. With an
+// empty query the first maxResults symbols are returned in their original
+// order, which keeps a file outline in line order.
+func FilterSymbols(syms []Symbol, query string, maxResults int) []SymbolMatch {
+	var matches []SymbolMatch
+	if query == "" {
+		for i, s := range syms {
+			if i >= maxResults {
+				break
+			}
+			matches = append(matches, SymbolMatch{Symbol: s})
+		}
+		return matches
+	}
+
+	for _, s := range syms {
+		if score, ranges := FuzzyMatch(query, s.QualifiedName()); score > 0 {
+			matches = append(matches, SymbolMatch{Symbol: s, Score: score, MatchRanges: ranges})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return len(matches[i].Symbol.QualifiedName()) < len(matches[j].Symbol.QualifiedName())
+	})
+	if len(matches) > maxResults {
+		matches = matches[:maxResults]
+	}
+	return matches
+}
+
+var identifierPattern = regexp.MustCompile(`[A-Za-z_$][\w$]*`)
+
+// identifierAt returns the identifier spanning byte offset col of line.
+func identifierAt(line string, col int) string {
+	for _, loc := range identifierPattern.FindAllStringIndex(line, -1) {
+		if col >= loc[0] && col < loc[1] {
+			return line[loc[0]:loc[1]]
+		}
+	}
+	return ""
+}
+
+// lineIdentifiers returns the distinct identifiers on a line in order.
+func lineIdentifiers(line string) []string {
+	seen := make(map[string]bool)
+	var words []string
+	for _, w := range identifierPattern.FindAllString(line, -1) {
+		if !seen[w] {
+			seen[w] = true
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
+// FindDefinitions returns the definitions of words, skipping the symbols
+// defined at path:line itself (the line being navigated from).
+func FindDefinitions(idx *SymbolIndex, words []string, path string, line int) []Symbol {
+	var defs []Symbol
+	for _, w := range words {
+		for _, s := range idx.Definitions(w) {
+			if s.Path == path && s.Line == line {
+				continue
+			}
+			defs = append(defs, s)
+		}
+	}
+	return defs
+}
+
+// symbolLocation is a position to return to after a symbol jump.
+type symbolLocation struct {
+	Path string
+	Line int // 1-indexed line at the top of the preview
+}
+
+// definitionRequest is a go-to-definition waiting for the index to build.
+type definitionRequest struct {
+	Words []string
+	Path  string
+	Line  int
+}
@@ -0,0 +1,399 @@
+package filebrowser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Symbol kinds reported by the extractors.
+const (
+	SymbolFunc      = "func"
+	SymbolMethod    = "method"
+	SymbolType      = "type"
+	SymbolClass     = "class"
+	SymbolInterface = "interface"
+	SymbolConst     = "const"
+	SymbolVar       = "var"
+)
+
+// Symbol is a named definition in a source file.
+type Symbol struct {
+	Name   string
+	Kind   string
+	Path   string // Relative to the project root
+	Line   int    // 1-indexed
+	Parent string // Enclosing type/class (receiver for Go methods)
+}
+
+// QualifiedName returns Parent.Name for members, Name otherwise.
+func (s Symbol) QualifiedName() string {
+	if s.Parent != "" {
+		return s.Parent + "." + s.Name
+	}
+	return s.Name
+}
+
+// SymbolExtractor finds the definitions in a file. path is the file's
+// location on disk; src is its content.
+type SymbolExtractor interface {
+	Name() string
+	Supports(path string) bool
+	Extract(path string, src []byte) ([]Symbol, error)
+}
+
+// symbolExtractors lists extractors in order of preference: the Go AST for
+// Go files, universal-ctags when installed, then the built-in patterns.
+var symbolExtractors = []SymbolExtractor{
+	goSymbolExtractor{},
+	ctagsSymbolExtractor{},
+	patternSymbolExtractor{},
+}
+
+// extractorFor returns the preferred extractor for a file, or nil.
+func extractorFor(path string) SymbolExtractor {
+	for _, e := range symbolExtractors {
+		if e.Supports(path) {
+			return e
+		}
+	}
+	return nil
+}
+
+// ExtractSymbols returns the symbols of a file sorted by line. rel is set
+// as each symbol's Path.
+func ExtractSymbols(workDir, rel string, src []byte) ([]Symbol, error) {
+	e := extractorFor(rel)
+	if e == nil {
+		return nil, nil
+	}
+	syms, err := e.Extract(filepath.Join(workDir, rel), src)
+	if err != nil {
+		return nil, err
+	}
+	for i := range syms {
+		syms[i].Path = rel
+	}
+	sort.SliceStable(syms, func(i, j int) bool { return syms[i].Line < syms[j].Line })
+	return syms, nil
+}
+
+// goSymbolExtractor parses Go files with go/parser.
+type goSymbolExtractor struct{}
+
+func (goSymbolExtractor) Name() string { return "go" }
+
+func (goSymbolExtractor) Supports(path string) bool {
+	return strings.HasSuffix(path, ".go")
+}
+
+func (goSymbolExtractor) Extract(path string, src []byte) ([]Symbol, error) {
+	fset := token.NewFileSet()
+	// Partial ASTs are still useful while a file is being edited
+	file, err := parser.ParseFile(fset, path, src, parser.SkipObjectResolution)
+	if file == nil {
+		return nil, err
+	}
+
+	var syms []Symbol
+	line := func(pos token.Pos) int { return fset.Position(pos).Line }
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			s := Symbol{Name: d.Name.Name, Kind: SymbolFunc, Line: line(d.Name.Pos())}
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				s.Kind = SymbolMethod
+				s.Parent = receiverTypeName(d.Recv.List[0].Type)
+			}
+			syms = append(syms, s)
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch sp := spec.(type) {
+				case *ast.TypeSpec:
+					kind := SymbolType
+					if _, ok := sp.Type.(*ast.InterfaceType); ok {
+						kind = SymbolInterface
+					}
+					syms = append(syms, Symbol{Name: sp.Name.Name, Kind: kind, Line: line(sp.Name.Pos())})
+				case *ast.ValueSpec:
+					kind := SymbolVar
+					if d.Tok == token.CONST {
+						kind = SymbolConst
+					}
+					for _, n := range sp.Names {
+						if n.Name != "_" {
+							syms = append(syms, Symbol{Name: n.Name, Kind: kind, Line: line(n.Pos())})
+						}
+					}
+				}
+			}
+		}
+	}
+	return syms, nil
+}
+
+// receiverTypeName returns the type name of a method receiver
+// (T, *T, T[K] and *T[K] all give T).
+func receiverTypeName(expr ast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// ctagsSymbolExtractor runs universal-ctags, which understands most
+// languages. Exuberant ctags lacks JSON output and is not used.
+type ctagsSymbolExtractor struct{}
+
+var (
+	ctagsOnce      sync.Once
+	ctagsAvailable bool
+)
+
+// hasUniversalCtags reports whether universal-ctags is on PATH.
+func hasUniversalCtags() bool {
+	ctagsOnce.Do(func() {
+		out, err := exec.Command("ctags", "--version").Output()
+		ctagsAvailable = err == nil && bytes.Contains(out, []byte("Universal Ctags"))
+	})
+	return ctagsAvailable
+}
+
+func (ctagsSymbolExtractor) Name() string { return "ctags" }
+
+func (ctagsSymbolExtractor) Supports(path string) bool {
+	return symbolPatternsFor(path) != nil && hasUniversalCtags()
+}
+
+func (ctagsSymbolExtractor) Extract(path string, _ []byte) ([]Symbol, error) {
+	out, err := exec.Command("ctags", ctagsArgs(path)...).Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseCtagsJSON(out), nil
+}
+
+// ctagsArgs returns the arguments for JSON tag output with line numbers.
+func ctagsArgs(paths ...string) []string {
+	return append([]string{"--output-format=json", "--fields=+nKZ", "-o", "-"}, paths...)
+}
+
+// ctagsTag is one line of universal-ctags JSON output.
+type ctagsTag struct {
+	Type  string `json:"_type"`
+	Name  string `json:"name"`
+	Path  string `json:"path"`
+	Line  int    `json:"line"`
+	Kind  string `json:"kind"`
+	Scope string `json:"scope"`
+}
+
+// parseCtagsJSON converts ctags JSON lines into symbols. Paths are left as
+// ctags reported them.
+func parseCtagsJSON(out []byte) []Symbol {
+	var syms []Symbol
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var tag ctagsTag
+		if err := json.Unmarshal(scanner.Bytes(), &tag); err != nil || tag.Type != "tag" {
+			continue
+		}
+		kind := ctagsKind(tag.Kind)
+		if kind == "" {
+			continue
+		}
+		parent := tag.Scope
+		if i := strings.LastIndexAny(parent, ".:"); i >= 0 {
+			parent = parent[i+1:]
+		}
+		syms = append(syms, Symbol{Name: tag.Name, Kind: kind, Path: tag.Path, Line: tag.Line, Parent: parent})
+	}
+	return syms
+}
+
+// ctagsKind maps ctags long kind names to symbol kinds. Kinds that aren't
+// definitions worth navigating to (imports, locals, parameters) are dropped.
+func ctagsKind(kind string) string {
+	switch kind {
+	case "function", "subroutine", "procedure":
+		return SymbolFunc
+	case "method", "member", "singletonMethod":
+		return SymbolMethod
+	case "class", "module":
+		return SymbolClass
+	case "interface", "trait", "protocol":
+		return SymbolInterface
+	case "struct", "enum", "typedef", "type", "alias", "union", "implementation":
+		return SymbolType
+	case "constant", "macro", "enumerator":
+		return SymbolConst
+	case "variable":
+		return SymbolVar
+	}
+	return ""
+}
+
+// symbolPattern matches a definition line; the name is capture group 1.
+type symbolPattern struct {
+	re        *regexp.Regexp
+	kind      string
+	container bool // Symbols indented below this one are its members
+}
+
+var (
+	pythonSymbolPatterns = []symbolPattern{
+		{regexp.MustCompile(`^\s*class\s+([A-Za-z_]\w*)`), SymbolClass, true},
+		{regexp.MustCompile(`^\s*(?:async\s+)?def\s+([A-Za-z_]\w*)`), SymbolFunc, false},
+	}
+	scriptSymbolPatterns = []symbolPattern{
+		{regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:abstract\s+)?class\s+([A-Za-z_$][\w$]*)`), SymbolClass, true},
+		{regexp.MustCompile(`^\s*(?:export\s+)?interface\s+([A-Za-z_$][\w$]*)`), SymbolInterface, true},
+		{regexp.MustCompile(`^\s*(?:export\s+)?(?:declare\s+)?type\s+([A-Za-z_$][\w$]*)\s*(?:<[^=]*>)?\s*=`), SymbolType, false},
+		{regexp.MustCompile(`^\s*(?:export\s+)?(?:const\s+)?enum\s+([A-Za-z_$][\w$]*)`), SymbolType, false},
+		{regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:async\s+)?function\s*\*?\s*([A-Za-z_$][\w$]*)`), SymbolFunc, false},
+		{regexp.MustCompile(`^\s*(?:export\s+)?(?:const|let|var)\s+([A-Za-z_$][\w$]*)\s*(?::[^=]+)?=\s*(?:async\s+)?(?:function|\([^)]*\)\s*(?::[^=]+)?=>|[A-Za-z_$][\w$]*\s*=>)`), SymbolFunc, false},
+		{regexp.MustCompile(`^\s+(?:(?:public|private|protected|static|async|readonly|override|get|set)\s+)*([A-Za-z_$][\w$]*)\s*(?:<[^>]*>)?\([^)]*\)\s*(?::[^{]+)?\{`), SymbolMethod, false},
+	}
+	rustSymbolPatterns = []symbolPattern{
+		{regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?struct\s+([A-Za-z_]\w*)`), SymbolType, false},
+		{regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?enum\s+([A-Za-z_]\w*)`), SymbolType, false},
+		{regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?trait\s+([A-Za-z_]\w*)`), SymbolInterface, true},
+		{regexp.MustCompile(`^\s*impl(?:<[^>]*>)?\s+(?:[\w:<>, ]+\s+for\s+)?([A-Za-z_]\w*)`), SymbolType, true},
+		{regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?(?:const\s+|async\s+|unsafe\s+)*fn\s+([A-Za-z_]\w*)`), SymbolFunc, false},
+		{regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?(?:const|static)\s+([A-Z_][A-Z0-9_]*)\s*:`), SymbolConst, false},
+	}
+	rubySymbolPatterns = []symbolPattern{
+		{regexp.MustCompile(`^\s*class\s+([A-Z]\w*)`), SymbolClass, true},
+		{regexp.MustCompile(`^\s*module\s+([A-Z]\w*)`), SymbolClass, true},
+		{regexp.MustCompile(`^\s*def\s+(?:self\.)?([A-Za-z_]\w*[?!=]?)`), SymbolFunc, false},
+	}
+	javaSymbolPatterns = []symbolPattern{
+		{regexp.MustCompile(`^\s*(?:(?:public|private|protected|abstract|final|static|sealed|data|open|internal)\s+)*(?:class|record|object)\s+([A-Za-z_]\w*)`), SymbolClass, true},
+		{regexp.MustCompile(`^\s*(?:(?:public|private|protected|sealed)\s+)*interface\s+([A-Za-z_]\w*)`), SymbolInterface, true},
+		{regexp.MustCompile(`^\s*(?:(?:public|private|protected)\s+)*enum\s+(?:class\s+)?([A-Za-z_]\w*)`), SymbolType, false},
+		{regexp.MustCompile(`^\s*(?:(?:public|private|protected|internal|override|suspend|open)\s+)*fun\s+(?:<[^>]*>\s*)?(?:[\w.]+\.)?([A-Za-z_]\w*)\s*\(`), SymbolFunc, false},
+		{regexp.MustCompile(`^\s+(?:(?:public|private|protected|static|final|abstract|synchronized)\s+)+[\w<>\[\], ]+\s+([A-Za-z_]\w*)\s*\([^;]*$`), SymbolMethod, false},
+	}
+	shellSymbolPatterns = []symbolPattern{
+		{regexp.MustCompile(`^\s*(?:function\s+)?([A-Za-z_][\w-]*)\s*\(\)\s*\{?`), SymbolFunc, false},
+	}
+)
+
+// symbolPatternsFor returns the pattern set for a file's language.
+func symbolPatternsFor(path string) []symbolPattern {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".py", ".pyi":
+		return pythonSymbolPatterns
+	case ".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs", ".mts", ".cts":
+		return scriptSymbolPatterns
+	case ".rs":
+		return rustSymbolPatterns
+	case ".rb":
+		return rubySymbolPatterns
+	case ".java", ".kt", ".kts", ".scala":
+		return javaSymbolPatterns
+	case ".sh", ".bash", ".zsh":
+		return shellSymbolPatterns
+	}
+	return nil
+}
+
+// patternSymbolExtractor finds definitions with per-language line patterns.
+// Members are attributed to the nearest less-indented container (class,
+// trait, impl), which covers conventionally formatted code.
+type patternSymbolExtractor struct{}
+
+func (patternSymbolExtractor) Name() string { return "patterns" }
+
+func (patternSymbolExtractor) Supports(path string) bool {
+	return symbolPatternsFor(path) != nil
+}
+
+// scriptKeywords are control-flow words the method pattern would otherwise
+// mistake for method definitions ("if (x) {").
+var scriptKeywords = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "catch": true,
+	"function": true, "return": true, "with": true,
+}
+
+func (patternSymbolExtractor) Extract(path string, src []byte) ([]Symbol, error) {
+	patterns := symbolPatternsFor(path)
+
+	type container struct {
+		name   string
+		indent int
+	}
+	var stack []container
+	var syms []Symbol
+
+	lineNo := 0
+	for _, line := range strings.Split(string(src), "\n") {
+		lineNo++
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		for len(stack) > 0 && indent <= stack[len(stack)-1].indent {
+			stack = stack[:len(stack)-1]
+		}
+		if strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "*") {
+			continue
+		}
+
+		for _, p := range patterns {
+			m := p.re.FindStringSubmatch(line)
+			if m == nil || scriptKeywords[m[1]] {
+				continue
+			}
+			s := Symbol{Name: m[1], Kind: p.kind, Line: lineNo}
+			if len(stack) > 0 {
+				s.Parent = stack[len(stack)-1].name
+				if s.Kind == SymbolFunc {
+					s.Kind = SymbolMethod
+				}
+			} else if s.Kind == SymbolMethod {
+				break // Method pattern outside any container is noise
+			}
+			syms = append(syms, s)
+			if p.container {
+				stack = append(stack, container{name: s.Name, indent: indent})
+			}
+			break
+		}
+	}
+	return syms, nil
+}
+
+// SymbolAtLine returns the innermost symbol starting at or before line
+// (1-indexed) in a file's symbols, for highlighting the current position.
+func SymbolAtLine(syms []Symbol, line int) int {
+	best := -1
+	for i, s := range syms {
+		if s.Line > line {
+			break
+		}
+		best = i
+	}
+	return best
+}
@@ -0,0 +1,306 @@
+package filebrowser
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// symbolSummary renders symbols as "kind Parent.Name:line" for comparison.
+func symbolSummary(syms []Symbol) string {
+	var parts []string
+	for _, s := range syms {
+		parts = append(parts, fmt.Sprintf("%s %s:%d", s.Kind, s.QualifiedName(), s.Line))
+	}
+	return strings.Join(parts, "\n")
+}
+
+func TestGoSymbolExtractor(t *testing.T) {
+	src := `package demo
+
+const Max = 3
+
+var (
+	a, _ = 1, 2
+)
+
+type Store[K comparable] struct{}
+
+type Reader interface{ Read() }
+
+func New() *Store[string] { return nil }
+
+func (s *Store[K]) Get(k K) {}
+
+func (Store[K]) len() int { return 0 }
+`
+	syms, err := goSymbolExtractor{}.Extract("demo.go", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"const Max:3",
+		"var a:6",
+		"type Store:9",
+		"interface Reader:11",
+		"func New:13",
+		"method Store.Get:15",
+		"method Store.len:17",
+	}, "\n")
+	if got := symbolSummary(syms); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestGoSymbolExtractor_SyntaxError(t *testing.T) {
+	// Symbols before a syntax error are still reported
+	syms, _ := goSymbolExtractor{}.Extract("broken.go", []byte("package x\n\nfunc Ok() {}\n\nfunc Broken( {\n"))
+	if len(syms) == 0 || syms[0].Name != "Ok" {
+		t.Errorf("got %+v", syms)
+	}
+}
+
+func TestPatternSymbolExtractor(t *testing.T) {
+	tests := []struct {
+		path string
+		src  string
+		want []string
+	}{
+		{
+			path: "app.py",
+			src: `import os
+
+class Server:
+    def start(self):
+        if True:
+            pass
+
+    async def stop(self):
+        pass
+
+def main():
+    pass
+`,
+			want: []string{"class Server:3", "method Server.start:4", "method Server.stop:8", "func main:11"},
+		},
+		{
+			path: "app.ts",
+			src: `export interface Props {
+  name: string
+}
+
+export class Widget {
+  render(): string {
+    if (x) {
+    }
+    return ""
+  }
+}
+
+export const handler = async (req: Request) => {
+}
+
+export function helper() {}
+`,
+			want: []string{"interface Props:1", "class Widget:5", "method Widget.render:6", "func handler:13", "func helper:16"},
+		},
+		{
+			path: "lib.rs",
+			src: `pub struct Point {}
+
+impl Point {
+    pub fn new() -> Self {}
+}
+
+fn main() {}
+`,
+			want: []string{"type Point:1", "type Point:3", "method Point.new:4", "func main:7"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			syms, err := patternSymbolExtractor{}.Extract(tt.path, []byte(tt.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := symbolSummary(syms), strings.Join(tt.want, "\n"); got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestParseCtagsJSON(t *testing.T) {
+	out := `{"_type": "ptag", "name": "JSON_OUTPUT_VERSION"}
+{"_type": "tag", "name": "Server", "path": "src/app.py", "line": 3, "kind": "class"}
+{"_type": "tag", "name": "start", "path": "src/app.py", "line": 4, "kind": "member", "scope": "Server", "scopeKind": "class"}
+{"_type": "tag", "name": "os", "path": "src/app.py", "line": 1, "kind": "namespace"}
+{"_type": "tag", "name": "run", "path": "src/app.py", "line": 9, "kind": "function", "scope": "pkg.mod", "scopeKind": "module"}
+`
+	syms := parseCtagsJSON([]byte(out))
+	want := "class Server:3\nmethod Server.start:4\nfunc mod.run:9"
+	if got := symbolSummary(syms); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if syms[0].Path != "src/app.py" {
+		t.Errorf("path = %q", syms[0].Path)
+	}
+}
+
+func TestFilterSymbols(t *testing.T) {
+	syms := []Symbol{
+		{Name: "View", Kind: SymbolMethod, Parent: "Plugin", Line: 1},
+		{Name: "renderView", Kind: SymbolMethod, Parent: "Plugin", Line: 2},
+		{Name: "New", Kind: SymbolFunc, Line: 3},
+	}
+
+	// Empty query keeps source order
+	all := FilterSymbols(syms, "", 10)
+	if len(all) != 3 || all[2].Symbol.Name != "New" {
+		t.Errorf("empty query: %+v", all)
+	}
+
+	// Exact qualified name ranks first
+	got := FilterSymbols(syms, "plugin.view", 10)
+	if len(got) == 0 || got[0].Symbol.Name != "View" {
+		t.Errorf("qualified query: %+v", got)
+	}
+
+	if got := FilterSymbols(syms, "view", 1); len(got) != 1 {
+		t.Errorf("max results not applied: %d", len(got))
+	}
+}
+
+func TestIdentifiers(t *testing.T) {
+	line := "\tresult := p.renderView(width)"
+	if got := identifierAt(line, 15); got != "renderView" {
+		t.Errorf("identifierAt = %q", got)
+	}
+	if got := identifierAt(line, 9); got != "" {
+		t.Errorf("identifierAt on operator = %q", got)
+	}
+	got := lineIdentifiers("x := x + y.x")
+	if strings.Join(got, ",") != "x,y" {
+		t.Errorf("lineIdentifiers = %v", got)
+	}
+}
+
+func TestFindDefinitions_SkipsOwnLine(t *testing.T) {
+	idx := NewSymbolIndex([]Symbol{
+		{Name: "Load", Kind: SymbolFunc, Path: "a.go", Line: 10},
+		{Name: "Load", Kind: SymbolMethod, Parent: "Cache", Path: "b.go", Line: 4},
+	})
+	defs := FindDefinitions(idx, []string{"Load"}, "a.go", 10)
+	if len(defs) != 1 || defs[0].Path != "b.go" {
+		t.Errorf("got %+v", defs)
+	}
+}
+
+func TestBuildSymbolIndex(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		".gitignore":      "gen/\n",
+		"main.go":         "package main\n\nfunc main() {}\n",
+		"pkg/util.py":     "def helper():\n    pass\n",
+		"gen/skip.go":     "package gen\n\nfunc Skipped() {}\n",
+		"notes/readme.md": "# not indexed\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	idx, err := buildSymbolIndex(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if defs := idx.Definitions("main"); len(defs) != 1 || defs[0].Path != "main.go" || defs[0].Line != 3 {
+		t.Errorf("main: %+v", defs)
+	}
+	if defs := idx.Definitions("helper"); len(defs) != 1 || defs[0].Path != "pkg/util.py" {
+		t.Errorf("helper: %+v", defs)
+	}
+	if defs := idx.Definitions("Skipped"); len(defs) != 0 {
+		t.Errorf("ignored file indexed: %+v", defs)
+	}
+}
+
+func TestGoToDefinition(t *testing.T) {
+	tmpDir := t.TempDir()
+	p := createTestPluginWithPreview(t, tmpDir, "package main\n\nfunc main() {\n\tNew()\n}")
+	p.previewFile = "main.go"
+	p.symbolIndex = NewSymbolIndex([]Symbol{
+		{Name: "main", Kind: SymbolFunc, Path: "main.go", Line: 3},
+		{Name: "New", Kind: SymbolFunc, Path: "src/app.go", Line: 7},
+		{Name: "New", Kind: SymbolFunc, Path: "src/other.go", Line: 2},
+	})
+
+	// Several definitions open the palette
+	p.previewScroll = 3
+	p.selection.Start.Line, p.selection.Start.Col = 3, 8 // "New" after tab expansion
+	p.selection.End = p.selection.Start
+	_, _ = p.handlePreviewKey("d")
+	if !p.symbolMode || p.symbolScope != SymbolScopeDefinitions || len(p.symbolMatches) != 2 {
+		t.Fatalf("expected definitions palette, got mode=%v matches=%+v", p.symbolMode, p.symbolMatches)
+	}
+
+	// Choosing one jumps there and records the way back
+	p.symbolCursor = 1
+	_, _ = p.handleSymbolKey(tea.KeyMsg{Type: tea.KeyEnter})
+	if p.symbolMode || p.previewFile != "src/other.go" || p.previewScroll != 1 {
+		t.Errorf("after jump: mode=%v file=%q scroll=%d", p.symbolMode, p.previewFile, p.previewScroll)
+	}
+	if len(p.symbolJumpBack) != 1 || p.symbolJumpBack[0].Path != "main.go" {
+		t.Fatalf("jump back stack = %+v", p.symbolJumpBack)
+	}
+
+	_, _ = p.handlePreviewKey("ctrl+o")
+	if p.previewFile != "main.go" || len(p.symbolJumpBack) != 0 {
+		t.Errorf("after ctrl+o: file=%q stack=%+v", p.previewFile, p.symbolJumpBack)
+	}
+}
+
+func TestSymbolOutline_CursorOnCurrentSymbol(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := "package main\n\nfunc a() {}\n\nfunc b() {\n\treturn\n}\n"
+	p := createTestPluginWithPreview(t, tmpDir, content)
+	p.previewFile = "main.go"
+	p.selection.Start.Line, p.selection.Start.Col = 5, 1
+	p.selection.End = p.selection.Start
+
+	_, cmd := p.handleKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'O'}})
+	if p.symbolMode || cmd == nil {
+		t.Fatal("expected symbols to be extracted in the background")
+	}
+	outline := cmd().(SymbolOutlineMsg)
+
+	// A result for a file no longer previewed is dropped
+	p.previewFile = "other.go"
+	p.Update(outline)
+	if p.symbolMode {
+		t.Fatal("outline for a previous file opened the palette")
+	}
+
+	p.previewFile = "main.go"
+	p.Update(outline)
+	if !p.symbolMode || p.symbolScope != SymbolScopeFile {
+		t.Fatal("expected outline palette")
+	}
+	if got := p.symbolMatches[p.symbolCursor].Symbol.Name; got != "b" {
+		t.Errorf("cursor on %q, want b", got)
+	}
+	if p.FocusContext() != "file-browser-symbols" {
+		t.Errorf("focus context = %s", p.FocusContext())
+	}
+}
@@ -259,7 +259,7 @@ func (p *Plugin) applyActiveTab() tea.Cmd {
 	if tab.Loaded {
 		p.applyPreviewResult(tab.Result)
 		p.clampPreviewScroll()
-		return nil
+		return tea.Batch(p.refreshDiffGutter(), p.refreshStructured())
 	}
 
 	return LoadPreview(p.ctx.WorkDir, tab.Path, p.ctx.Epoch)
@@ -361,6 +361,11 @@ func (p *Plugin) resetPreviewModes() {
 	p.blameModalWidth = 0
 	p.markdownRendered = nil
 	p.imageResult = nil
+	p.diffPeekOpen = false
+	p.diffChangeLine = 0
+	p.structured = nil
+	p.structuredErr = nil
+	p.structuredLoading = false
 }
 
 func (p *Plugin) resetPreviewContent() {
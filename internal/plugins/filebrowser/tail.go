@@ -0,0 +1,431 @@
+package filebrowser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	tailInitialBytes = 256 << 10 // Read from the end of the file when tail mode opens
+	tailMaxChunk     = 4 << 20   // Most bytes read per change; anything before is skipped
+	tailMaxLines     = 50000     // Lines kept in memory
+	tailMaxLineLen   = 64 << 10  // Unterminated lines longer than this are shown anyway
+)
+
+// TailView follows a growing file like `tail -f`, reading only the bytes
+// appended since the last read so it stays cheap on multi-GB logs.
+type TailView struct {
+	Path string // Relative path of the followed file
+
+	Lines    []string // Complete lines, oldest first
+	Follow   bool     // Keep the newest line in view
+	Scroll   int      // First visible row among shown lines
+	NewLines int      // Lines that arrived while paused
+
+	Filter     *regexp.Regexp
+	FilterText string
+	FilterErr  error
+	Editing    bool  // Typing the filter
+	matches    []int // Indices into Lines passing the filter
+
+	Notice string // Last rotation, truncation or skip
+	Err    error
+
+	info    os.FileInfo // Identity of the file read so far, to detect rotation
+	offset  int64       // Bytes consumed from the file
+	partial string      // Trailing text not yet ended by a newline
+	reading bool
+	pending bool // A change arrived while reading
+}
+
+// TailReadMsg delivers bytes appended to a followed file, split into lines.
+type TailReadMsg struct {
+	Epoch   uint64
+	Path    string
+	Info    os.FileInfo
+	Offset  int64
+	Lines   []string
+	Partial string
+	Reset   bool // The file was rotated or truncated; drop earlier lines
+	Notice  string
+	Err     error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m TailReadMsg) GetEpoch() uint64 { return m.Epoch }
+
+// readTail reads what was appended to path after offset. Without a previous
+// read it starts near the end of the file. A different file at the path
+// (rotation) or a shorter one (truncation) is read from the start.
+func readTail(path string, prev os.FileInfo, offset int64, partial string) TailReadMsg {
+	msg := TailReadMsg{Info: prev, Offset: offset, Partial: partial}
+	f, err := os.Open(path)
+	if err != nil {
+		// Mid-rotation the path can briefly be missing; keep what we have
+		msg.Err = err
+		return msg
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		msg.Err = err
+		return msg
+	}
+
+	size := info.Size()
+	start, skipFirst := offset, false
+	switch {
+	case prev == nil:
+		start = max(size-tailInitialBytes, 0)
+		skipFirst = start > 0
+	case !os.SameFile(prev, info):
+		start, msg.Reset, msg.Notice = 0, true, "file rotated"
+	case size < offset:
+		start, msg.Reset, msg.Notice = 0, true, "file truncated"
+	}
+	if msg.Reset {
+		partial = ""
+	}
+	if size-start > tailMaxChunk {
+		msg.Notice = joinNotice(msg.Notice, "skipped "+formatSize(size-start-tailMaxChunk))
+		start = size - tailMaxChunk
+		partial, skipFirst = "", true
+	}
+	msg.Info, msg.Offset = info, size
+	if size == start {
+		msg.Partial = partial
+		return msg
+	}
+
+	data := make([]byte, size-start)
+	n, err := io.ReadFull(io.NewSectionReader(f, start, size-start), data)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		msg.Err = err
+		return msg
+	}
+	data = data[:n]
+	msg.Offset = start + int64(n)
+	if isBinary(data) {
+		msg.Err = errors.New("binary file")
+		return msg
+	}
+	if skipFirst {
+		// Started mid-line; drop the fragment
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+		} else {
+			data = nil
+		}
+	}
+	msg.Lines, msg.Partial = splitTailLines(partial + string(data))
+	return msg
+}
+
+// splitTailLines splits text into complete lines and the unterminated rest.
+func splitTailLines(s string) ([]string, string) {
+	lines := strings.Split(s, "\n")
+	rest := lines[len(lines)-1]
+	lines = lines[:len(lines)-1]
+	if len(rest) > tailMaxLineLen {
+		lines, rest = append(lines, rest), ""
+	}
+	for i, l := range lines {
+		lines[i] = strings.TrimSuffix(l, "\r")
+	}
+	return lines, rest
+}
+
+// tailMatch matches a filter against a line's text, ignoring ANSI colors.
+func tailMatch(re *regexp.Regexp, line string) bool {
+	if strings.IndexByte(line, 0x1b) >= 0 {
+		line = ansi.Strip(line)
+	}
+	return re.MatchString(line)
+}
+
+func joinNotice(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + ", " + b
+}
+
+// tailActive reports whether the preview is following the current file.
+func (p *Plugin) tailActive() bool {
+	return p.tail != nil && p.tail.Path == p.previewFile && !p.isImage
+}
+
+// toggleTail starts or stops following the previewed file.
+func (p *Plugin) toggleTail() tea.Cmd {
+	if p.tailActive() {
+		p.tail = nil
+		p.previewScroll = 0
+		return LoadPreview(p.ctx.WorkDir, p.previewFile, p.ctx.Epoch)
+	}
+	if p.previewFile == "" || p.isImage {
+		return nil
+	}
+	p.tail = &TailView{Path: p.previewFile, Follow: true}
+	p.structuredMode = false
+	p.contentSearchMode = false
+	p.diffPeekOpen = false
+	p.selection.Clear()
+	return p.readTail()
+}
+
+// readTail reads newly appended bytes, or notes that another read is needed
+// once the one in flight finishes.
+func (p *Plugin) readTail() tea.Cmd {
+	t := p.tail
+	if t == nil {
+		return nil
+	}
+	if t.reading {
+		t.pending = true
+		return nil
+	}
+	t.reading = true
+	path := filepath.Join(p.ctx.WorkDir, t.Path)
+	rel, prev, offset, partial, epoch := t.Path, t.info, t.offset, t.partial, p.ctx.Epoch
+	return func() tea.Msg {
+		msg := readTail(path, prev, offset, partial)
+		msg.Epoch, msg.Path = epoch, rel
+		return msg
+	}
+}
+
+// applyTailRead appends a read to the view.
+func (p *Plugin) applyTailRead(m TailReadMsg) tea.Cmd {
+	t := p.tail
+	if t == nil || t.Path != m.Path {
+		return nil
+	}
+	t.reading = false
+	t.Err = m.Err
+	if m.Err == nil {
+		if m.Reset {
+			t.Lines, t.matches = nil, nil
+			t.Scroll = 0
+		}
+		if m.Notice != "" {
+			t.Notice = m.Notice
+		}
+		t.info, t.offset, t.partial = m.Info, m.Offset, m.Partial
+		t.appendLines(m.Lines)
+		if !t.Follow {
+			t.NewLines += len(m.Lines)
+		}
+	}
+	if t.pending {
+		t.pending = false
+		return p.readTail()
+	}
+	return nil
+}
+
+// appendLines adds lines, dropping the oldest beyond tailMaxLines.
+func (t *TailView) appendLines(lines []string) {
+	first := len(t.Lines)
+	t.Lines = append(t.Lines, lines...)
+	if t.Filter != nil {
+		for i := first; i < len(t.Lines); i++ {
+			if tailMatch(t.Filter, t.Lines[i]) {
+				t.matches = append(t.matches, i)
+			}
+		}
+	}
+	drop := len(t.Lines) - tailMaxLines
+	if drop <= 0 {
+		return
+	}
+	// Drop an extra tenth so trimming isn't needed on every append
+	drop = min(drop+tailMaxLines/10, len(t.Lines))
+	t.Lines = append([]string(nil), t.Lines[drop:]...)
+	shownDropped := drop
+	if t.Filter != nil {
+		shownDropped = 0
+		kept := t.matches[:0]
+		for _, i := range t.matches {
+			if i >= drop {
+				kept = append(kept, i-drop)
+			} else {
+				shownDropped++
+			}
+		}
+		t.matches = kept
+	}
+	if !t.Follow {
+		// Keep the same lines in view while paused
+		t.Scroll = max(t.Scroll-shownDropped, 0)
+	}
+}
+
+// shownCount returns the number of lines shown, after filtering.
+func (t *TailView) shownCount() int {
+	if t.Filter != nil {
+		return len(t.matches)
+	}
+	return len(t.Lines)
+}
+
+// shownLine returns the i-th shown line.
+func (t *TailView) shownLine(i int) string {
+	if t.Filter != nil {
+		return t.Lines[t.matches[i]]
+	}
+	return t.Lines[i]
+}
+
+// setFilter applies a regular expression filter. Lowercase patterns match
+// case-insensitively. An invalid pattern keeps the previous filter.
+func (t *TailView) setFilter(text string) {
+	t.FilterText = text
+	t.FilterErr = nil
+	if text == "" {
+		t.Filter, t.matches = nil, nil
+		t.clampScroll()
+		return
+	}
+	pattern := text
+	if strings.ToLower(text) == text {
+		pattern = "(?i)" + text
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		t.FilterErr = err
+		return
+	}
+	t.Filter = re
+	t.matches = t.matches[:0]
+	for i, l := range t.Lines {
+		if tailMatch(re, l) {
+			t.matches = append(t.matches, i)
+		}
+	}
+	t.clampScroll()
+}
+
+func (t *TailView) clampScroll() {
+	t.Scroll = max(min(t.Scroll, t.shownCount()-1), 0)
+}
+
+// scroll moves the view by delta rows; moving up pauses following.
+func (t *TailView) scroll(delta, height int) {
+	maxScroll := max(t.shownCount()-height, 0)
+	if t.Follow {
+		t.Scroll = maxScroll
+	}
+	t.Scroll = max(min(t.Scroll+delta, maxScroll), 0)
+	if delta < 0 && t.Follow {
+		t.Follow = false
+		t.NewLines = 0
+	}
+	if t.Scroll == maxScroll && delta > 0 {
+		t.follow()
+	}
+}
+
+// follow resumes following from the newest line.
+func (t *TailView) follow() {
+	t.Follow = true
+	t.NewLines = 0
+}
+
+// tailBodyHeight returns the rows available for lines below the status line.
+func (p *Plugin) tailBodyHeight() int {
+	return max(p.visibleContentHeight()-1, 1)
+}
+
+// handleTailKey handles keys while following a file. Keys it doesn't
+// handle fall through to the regular preview keys.
+func (p *Plugin) handleTailKey(key string) (bool, tea.Cmd) {
+	t := p.tail
+	height := p.tailBodyHeight()
+	switch key {
+	case "j", "down":
+		t.scroll(1, height)
+	case "k", "up":
+		t.scroll(-1, height)
+	case "ctrl+d", "ctrl+f", "pgdown":
+		t.scroll(height/2, height)
+	case "ctrl+u", "ctrl+b", "pgup":
+		t.scroll(-height/2, height)
+	case "g":
+		t.scroll(-max(t.shownCount(), 1), height)
+	case "G":
+		t.follow()
+	case " ", "space":
+		if t.Follow {
+			t.Scroll = max(t.shownCount()-height, 0)
+			t.Follow = false
+		} else {
+			t.follow()
+		}
+	case "/":
+		t.Editing = true
+	case "esc":
+		if t.FilterText == "" {
+			return false, nil
+		}
+		t.setFilter("")
+	case "F":
+		return true, p.toggleTail()
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// handleTailFilterKey edits the filter, applying it as it is typed.
+func (p *Plugin) handleTailFilterKey(key string) tea.Cmd {
+	t := p.tail
+	switch key {
+	case "enter":
+		t.Editing = false
+	case "esc":
+		t.Editing = false
+		t.setFilter("")
+	case "backspace":
+		if r := []rune(t.FilterText); len(r) > 0 {
+			t.setFilter(string(r[:len(r)-1]))
+		}
+	default:
+		if len(key) == 1 && key[0] >= 32 && key[0] <= 126 {
+			t.setFilter(t.FilterText + key)
+		} else if key == "space" {
+			t.setFilter(t.FilterText + " ")
+		}
+	}
+	return nil
+}
+
+// tailStatus describes the follow state, line counts and filter.
+func (t *TailView) tailStatus() string {
+	parts := []string{"● following"}
+	if !t.Follow {
+		parts[0] = "‖ paused"
+		if t.NewLines > 0 {
+			parts[0] += fmt.Sprintf(" (%d new)", t.NewLines)
+		}
+	}
+	parts = append(parts, fmt.Sprintf("%d lines", len(t.Lines)))
+	if t.Filter != nil {
+		parts = append(parts, fmt.Sprintf("/%s/ %d matches", t.FilterText, len(t.matches)))
+	}
+	if t.Notice != "" {
+		parts = append(parts, t.Notice)
+	}
+	if t.Err != nil {
+		parts = append(parts, t.Err.Error())
+	}
+	return strings.Join(parts, " · ")
+}
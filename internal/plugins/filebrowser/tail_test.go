@@ -0,0 +1,154 @@
+package filebrowser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func appendFile(t *testing.T, path, data string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := f.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	// The first read starts near the end, dropping the cut-off line
+	var big strings.Builder
+	for i := 0; big.Len() <= tailInitialBytes; i++ {
+		fmt.Fprintf(&big, "line %d\n", i)
+	}
+	big.WriteString("last\npart")
+	appendFile(t, path, big.String())
+	m := readTail(path, nil, 0, "")
+	if m.Err != nil {
+		t.Fatal(m.Err)
+	}
+	if got := m.Lines[len(m.Lines)-1]; got != "last" || m.Partial != "part" {
+		t.Fatalf("last line %q, partial %q", got, m.Partial)
+	}
+	if !strings.HasPrefix(m.Lines[0], "line ") || len(m.Lines[0]) < len("line 1") {
+		t.Errorf("first line %q should be a whole line", m.Lines[0])
+	}
+
+	// Appends complete the partial line
+	appendFile(t, path, "ial\r\nnext\n")
+	m = readTail(path, m.Info, m.Offset, m.Partial)
+	if strings.Join(m.Lines, "|") != "partial|next" || m.Partial != "" || m.Reset {
+		t.Fatalf("append read %q partial %q", m.Lines, m.Partial)
+	}
+
+	// Truncation starts over from the beginning
+	if err := os.WriteFile(path, []byte("fresh\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m = readTail(path, m.Info, m.Offset, m.Partial)
+	if !m.Reset || m.Notice != "file truncated" || strings.Join(m.Lines, "|") != "fresh" {
+		t.Fatalf("truncate read %+v", m)
+	}
+
+	// Rotation (a new file at the path) starts over too
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendFile(t, path, "rotated\n")
+	m = readTail(path, m.Info, m.Offset, m.Partial)
+	if !m.Reset || m.Notice != "file rotated" || strings.Join(m.Lines, "|") != "rotated" {
+		t.Fatalf("rotate read %+v", m)
+	}
+}
+
+func TestTailModeFollowFilter(t *testing.T) {
+	tmpDir := t.TempDir()
+	p := createTestPlugin(t, tmpDir)
+	logPath := filepath.Join(tmpDir, "dev.log")
+	appendFile(t, logPath, "INFO starting\nWARN slow query\n")
+	p.previewFile = "dev.log"
+	p.activePane = PanePreview
+	p.width, p.height, p.previewWidth = 100, 12, 80
+
+	_, cmd := p.handlePreviewKey("F")
+	if !p.tailActive() || p.FocusContext() != "file-browser-tail" {
+		t.Fatal("F should start tail mode")
+	}
+	_, _ = p.Update(cmd())
+	if len(p.tail.Lines) != 2 {
+		t.Fatalf("lines = %q", p.tail.Lines)
+	}
+
+	// Appended lines arrive on watch events while following
+	for i := 0; i < 20; i++ {
+		appendFile(t, logPath, fmt.Sprintf("ERROR request %d failed\n", i))
+	}
+	_, _ = p.Update(p.readTail()())
+	view := ansi.Strip(p.renderTailView(p.visibleContentHeight()))
+	if !strings.Contains(view, "● following") || !strings.Contains(view, "request 19 failed") {
+		t.Errorf("following view:\n%s", view)
+	}
+
+	// Scrolling up pauses, and new lines are counted instead of shown
+	_, _ = p.handlePreviewKey("k")
+	appendFile(t, logPath, "INFO done\n")
+	_, _ = p.Update(p.readTail()())
+	if p.tail.Follow || p.tail.NewLines != 1 {
+		t.Fatalf("follow=%v new=%d", p.tail.Follow, p.tail.NewLines)
+	}
+	_, _ = p.handlePreviewKey("G")
+	if !p.tail.Follow {
+		t.Error("G should resume following")
+	}
+
+	// The filter applies as it's typed and doesn't trigger global keys
+	_, _ = p.handlePreviewKey("/")
+	if !p.ConsumesTextInput() {
+		t.Error("filter input should consume text")
+	}
+	for _, r := range "warn|fail" {
+		_, _ = p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	_, _ = p.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if p.tail.Editing || p.tail.shownCount() != 21 {
+		t.Fatalf("filter shows %d lines, editing=%v", p.tail.shownCount(), p.tail.Editing)
+	}
+	if p.projectSearchMode {
+		t.Error("typing f in the filter opened project search")
+	}
+
+	// F again returns to the normal preview
+	_, cmd = p.handlePreviewKey("F")
+	if p.tailActive() || cmd == nil {
+		t.Error("F should stop tail mode and reload the preview")
+	}
+}
+
+func TestLogLevelStyle(t *testing.T) {
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{"2024-01-01 ERROR boom", true},
+		{`{"level":"warn","msg":"x"}`, true},
+		{"[debug] detail", true},
+		{"plain output", false},
+		{"\x1b[31mERROR\x1b[0m colored by the app", false},
+		{"terrorist attack", false},
+	}
+	for _, tt := range tests {
+		if got := logLevelStyle(tt.line) != nil; got != tt.want {
+			t.Errorf("logLevelStyle(%q) styled = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package filebrowser
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/trash"
+)
+
+// trashConfirm is the destructive action awaiting y/n in the trash view.
+type trashConfirm int
+
+const (
+	trashConfirmNone trashConfirm = iota
+	trashConfirmPurge
+	trashConfirmEmpty
+)
+
+// TrashListMsg delivers the items trashed from the project.
+type TrashListMsg struct {
+	Epoch uint64
+	Items []trash.Item
+	Err   error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m TrashListMsg) GetEpoch() uint64 { return m.Epoch }
+
+// TrashActionMsg is sent when trash items were restored or purged.
+type TrashActionMsg struct {
+	Epoch    uint64
+	Restored string // Original path of a restored item
+	Purged   int
+	Err      error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m TrashActionMsg) GetEpoch() uint64 { return m.Epoch }
+
+// TrashExpiredMsg reports items purged by trash expiry.
+type TrashExpiredMsg struct {
+	Purged int
+	Err    error
+}
+
+// projectTrash returns the trash for the current project.
+func (p *Plugin) projectTrash() *trash.Trash {
+	if p.trash == nil {
+		p.trash = trash.New(p.ctx.WorkDir)
+	}
+	return p.trash
+}
+
+// trashLimits returns the configured expiry limits, applying defaults.
+// Negative values disable a limit.
+func (p *Plugin) trashLimits() (time.Duration, int64) {
+	maxAge, maxSize := trash.DefaultMaxAge, trash.DefaultMaxSize
+	if p.ctx != nil && p.ctx.Config != nil {
+		cfg := p.ctx.Config.Plugins.FileBrowser
+		if cfg.TrashMaxAge != 0 {
+			maxAge = max(cfg.TrashMaxAge, 0)
+		}
+		if cfg.TrashMaxSizeMB != 0 {
+			maxSize = max(int64(cfg.TrashMaxSizeMB), 0) << 20
+		}
+	}
+	return maxAge, maxSize
+}
+
+// expireTrash purges trashed items past the configured age and size limits.
+func (p *Plugin) expireTrash() tea.Cmd {
+	t := p.projectTrash()
+	maxAge, maxSize := p.trashLimits()
+	return func() tea.Msg {
+		n, err := t.Expire(maxAge, maxSize)
+		return TrashExpiredMsg{Purged: n, Err: err}
+	}
+}
+
+// openTrashView shows the project's trashed items.
+func (p *Plugin) openTrashView() tea.Cmd {
+	p.trashMode = true
+	p.trashCursor = 0
+	p.trashErr = nil
+	p.trashConfirm = trashConfirmNone
+	return p.loadTrash()
+}
+
+// closeTrashView hides the trash view.
+func (p *Plugin) closeTrashView() {
+	p.trashMode = false
+	p.trashItems = nil
+	p.trashConfirm = trashConfirmNone
+}
+
+// loadTrash lists the trash in the background.
+func (p *Plugin) loadTrash() tea.Cmd {
+	t := p.projectTrash()
+	epoch := p.ctx.Epoch
+	p.trashLoading = true
+	return func() tea.Msg {
+		items, err := t.List()
+		return TrashListMsg{Epoch: epoch, Items: items, Err: err}
+	}
+}
+
+// applyTrashList stores a loaded trash listing.
+func (p *Plugin) applyTrashList(m TrashListMsg) {
+	p.trashLoading = false
+	p.trashItems = m.Items
+	p.trashErr = m.Err
+	p.trashCursor = max(0, min(p.trashCursor, len(p.trashItems)-1))
+}
+
+// selectedTrashItem returns the item under the cursor.
+func (p *Plugin) selectedTrashItem() *trash.Item {
+	if p.trashCursor < 0 || p.trashCursor >= len(p.trashItems) {
+		return nil
+	}
+	return &p.trashItems[p.trashCursor]
+}
+
+// restoreTrashItem moves the selected item back to where it was deleted from.
+func (p *Plugin) restoreTrashItem() tea.Cmd {
+	item := p.selectedTrashItem()
+	if item == nil {
+		return nil
+	}
+	t, it, epoch, workDir := p.projectTrash(), *item, p.ctx.Epoch, p.ctx.WorkDir
+	return func() tea.Msg {
+		if err := t.Restore(it); err != nil {
+			if errors.Is(err, trash.ErrExists) {
+				err = fmt.Errorf("can't restore: %s already exists", relTo(workDir, it.OriginalPath))
+			}
+			return TrashActionMsg{Epoch: epoch, Err: err}
+		}
+		return TrashActionMsg{Epoch: epoch, Restored: it.OriginalPath}
+	}
+}
+
+// purgeTrashItems permanently deletes the given items.
+func (p *Plugin) purgeTrashItems(items []trash.Item) tea.Cmd {
+	t, epoch := p.projectTrash(), p.ctx.Epoch
+	items = append([]trash.Item(nil), items...)
+	return func() tea.Msg {
+		msg := TrashActionMsg{Epoch: epoch}
+		for _, it := range items {
+			if err := t.Purge(it); err != nil {
+				msg.Err = err
+				break
+			}
+			msg.Purged++
+		}
+		return msg
+	}
+}
+
+// applyTrashAction reports a restore or purge and reloads the trash and tree.
+func (p *Plugin) applyTrashAction(m TrashActionMsg) tea.Cmd {
+	var toast tea.Cmd
+	switch {
+	case m.Err != nil:
+		toast = appmsg.ShowToast(capitalize(m.Err.Error()), 3*time.Second)
+	case m.Restored != "":
+		toast = appmsg.ShowToast("Restored: "+relTo(p.ctx.WorkDir, m.Restored), 2*time.Second)
+	case m.Purged > 0:
+		toast = appmsg.ShowToast(fmt.Sprintf("Permanently deleted %s", pluralItems(m.Purged)), 2*time.Second)
+	}
+	cmds := []tea.Cmd{toast, p.refresh()}
+	if p.trashMode {
+		cmds = append(cmds, p.loadTrash())
+	}
+	return tea.Batch(cmds...)
+}
+
+// handleTrashKey handles key input in the trash view.
+func (p *Plugin) handleTrashKey(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
+	key := msg.String()
+
+	if p.trashConfirm != trashConfirmNone {
+		confirm := p.trashConfirm
+		p.trashConfirm = trashConfirmNone
+		if key != "y" && key != "Y" && key != "enter" {
+			return p, nil
+		}
+		if confirm == trashConfirmEmpty {
+			return p, p.purgeTrashItems(p.trashItems)
+		}
+		if item := p.selectedTrashItem(); item != nil {
+			return p, p.purgeTrashItems([]trash.Item{*item})
+		}
+		return p, nil
+	}
+
+	switch key {
+	case "esc", "q", "T":
+		p.closeTrashView()
+
+	case "j", "down":
+		if p.trashCursor < len(p.trashItems)-1 {
+			p.trashCursor++
+		}
+
+	case "k", "up":
+		if p.trashCursor > 0 {
+			p.trashCursor--
+		}
+
+	case "g":
+		p.trashCursor = 0
+
+	case "G":
+		p.trashCursor = max(0, len(p.trashItems)-1)
+
+	case "enter", "r":
+		return p, p.restoreTrashItem()
+
+	case "D":
+		if p.selectedTrashItem() != nil {
+			p.trashConfirm = trashConfirmPurge
+		}
+
+	case "E":
+		if len(p.trashItems) > 0 {
+			p.trashConfirm = trashConfirmEmpty
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,104 @@
+package filebrowser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/marcus/sidecar/internal/config"
+)
+
+func TestDeleteMovesToTrashAndRestores(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	tmpDir := t.TempDir()
+	p := createTestPlugin(t, tmpDir)
+	p.fileOpTarget = p.tree.FindByPath("main.go")
+
+	m := p.doDelete()()
+	del, ok := m.(DeleteSuccessMsg)
+	if !ok {
+		t.Fatalf("doDelete returned %T: %v", m, m)
+	}
+	_, _ = p.Update(del)
+	if _, err := os.Stat(filepath.Join(tmpDir, "main.go")); !os.IsNotExist(err) {
+		t.Fatal("main.go should be moved out of the project")
+	}
+	if len(p.batchUndo) != 1 {
+		t.Errorf("single delete should be undoable, undo stack = %d", len(p.batchUndo))
+	}
+
+	cmd := p.openTrashView()
+	if !p.trashMode || p.FocusContext() != "file-browser-trash" {
+		t.Fatal("T should open the trash view")
+	}
+	_, _ = p.Update(cmd())
+	if len(p.trashItems) != 1 || p.trashItems[0].OriginalPath != filepath.Join(tmpDir, "main.go") {
+		t.Fatalf("trash items = %+v", p.trashItems)
+	}
+	p.width, p.height = 100, 30
+	if view := ansi.Strip(p.renderTrashModalContent()); !strings.Contains(view, "main.go") {
+		t.Errorf("trash view should list main.go:\n%s", view)
+	}
+
+	// Restoring over an existing file is refused
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, cmd = p.handleTrashKey(tea.KeyMsg{Type: tea.KeyEnter})
+	if act := cmd().(TrashActionMsg); act.Err == nil || !strings.Contains(act.Err.Error(), "already exists") {
+		t.Errorf("restore over existing file: %+v", act)
+	}
+
+	_ = os.Remove(filepath.Join(tmpDir, "main.go"))
+	_, cmd = p.handleTrashKey(tea.KeyMsg{Type: tea.KeyEnter})
+	act := cmd().(TrashActionMsg)
+	if act.Err != nil {
+		t.Fatal(act.Err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "main.go")); err != nil {
+		t.Errorf("main.go not restored: %v", err)
+	}
+}
+
+func TestTrashPurgeNeedsConfirmation(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	tmpDir := t.TempDir()
+	p := createTestPlugin(t, tmpDir)
+	for _, name := range []string{"main.go", "README.md"} {
+		if _, err := p.projectTrash().Put(filepath.Join(tmpDir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, _ = p.Update(p.openTrashView()())
+
+	keyE := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("E")}
+	_, _ = p.handleTrashKey(keyE)
+	if _, cmd := p.handleTrashKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")}); cmd != nil {
+		t.Fatal("declining should not purge")
+	}
+	_, _ = p.handleTrashKey(keyE)
+	_, cmd := p.handleTrashKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if act := cmd().(TrashActionMsg); act.Err != nil || act.Purged != 2 {
+		t.Errorf("empty trash: %+v", act)
+	}
+	if items, _ := p.projectTrash().List(); len(items) != 0 {
+		t.Errorf("trash not emptied: %v", items)
+	}
+}
+
+func TestTrashLimits(t *testing.T) {
+	p := createTestPlugin(t, t.TempDir())
+	p.ctx.Config = config.Default()
+	if age, size := p.trashLimits(); age != 30*24*time.Hour || size != 1<<30 {
+		t.Errorf("defaults = %v, %d", age, size)
+	}
+	p.ctx.Config.Plugins.FileBrowser.TrashMaxAge = -1
+	p.ctx.Config.Plugins.FileBrowser.TrashMaxSizeMB = 10
+	if age, size := p.trashLimits(); age != 0 || size != 10<<20 {
+		t.Errorf("configured = %v, %d", age, size)
+	}
+}
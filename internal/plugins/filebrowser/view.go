@@ -76,6 +76,13 @@ func (p *Plugin) renderView() string {
 		return ui.OverlayModal(background, modal, p.width, p.height)
 	}
 
+	// Symbol palette is a full overlay - render modal over dimmed background
+	if p.symbolMode {
+		background := p.renderNormalPanes()
+		modal := p.renderSymbolModalContent()
+		return ui.OverlayModal(background, modal, p.width, p.height)
+	}
+
 	// Info modal is a full overlay - render modal over dimmed background
 	if p.infoMode {
 		background := p.renderNormalPanes()
@@ -90,6 +97,27 @@ func (p *Plugin) renderView() string {
 		return ui.OverlayModal(background, modal, p.width, p.height)
 	}
 
+	// Trash view is a full overlay
+	if p.trashMode {
+		background := p.renderNormalPanes()
+		modal := p.renderTrashModalContent()
+		return ui.OverlayModal(background, modal, p.width, p.height)
+	}
+
+	// Local history timeline is a full overlay
+	if p.historyMode {
+		background := p.renderNormalPanes()
+		modal := p.renderHistoryModalContent()
+		return ui.OverlayModal(background, modal, p.width, p.height)
+	}
+
+	// Compare view is a full overlay
+	if p.compare != nil {
+		background := p.renderNormalPanes()
+		modal := p.renderCompareModalContent()
+		return ui.OverlayModal(background, modal, p.width, p.height)
+	}
+
 	return p.renderNormalPanes()
 }
 
@@ -314,12 +342,15 @@ func (p *Plugin) renderTreeSearchBar() string {
 // renderFileOpBar renders the file operation input bar (move/rename/create/delete).
 func (p *Plugin) renderFileOpBar() string {
 	// Handle delete confirmation mode
+	if p.fileOpConfirmDelete && len(p.fileOpBatch) > 0 {
+		return p.renderFileOpConfirmation(fmt.Sprintf("Move %s to trash?", pluralItems(len(p.fileOpBatch))))
+	}
 	if p.fileOpConfirmDelete && p.fileOpTarget != nil {
 		itemType := "file"
 		if p.fileOpTarget.IsDir {
 			itemType = "directory"
 		}
-		return p.renderFileOpConfirmation(fmt.Sprintf("Delete %s '%s'?", itemType, p.fileOpTarget.Name))
+		return p.renderFileOpConfirmation(fmt.Sprintf("Move %s '%s' to trash?", itemType, p.fileOpTarget.Name))
 	}
 
 	// Handle confirmation mode for directory creation (during move)
@@ -331,8 +362,18 @@ func (p *Plugin) renderFileOpBar() string {
 	switch p.fileOpMode {
 	case FileOpRename:
 		prompt = "Rename: "
+		if len(p.fileOpBatch) > 0 {
+			prompt = fmt.Sprintf("Rename %s: ", pluralItems(len(p.fileOpBatch)))
+		}
 	case FileOpMove:
 		prompt = "Move to: "
+		if len(p.fileOpBatch) > 0 {
+			prompt = fmt.Sprintf("Move %s to: ", pluralItems(len(p.fileOpBatch)))
+		}
+	case FileOpGlobSelect:
+		prompt = "Select: "
+	case FileOpCompare:
+		prompt = "Compare with: "
 	case FileOpCreateFile:
 		prompt = "New file: "
 	case FileOpCreateDir:
@@ -344,7 +385,11 @@ func (p *Plugin) renderFileOpBar() string {
 	inputLine := fmt.Sprintf(" %s%s", prompt, p.fileOpTextInput.View())
 
 	var lines []string
-	lines = append(lines, styles.ModalTitle.Render(inputLine))
+	if preview := p.batchRenamePreview(); preview != "" && p.fileOpMode == FileOpRename {
+		lines = append(lines, styles.ModalTitle.Render(inputLine)+"  "+styles.Muted.Render(preview))
+	} else {
+		lines = append(lines, styles.ModalTitle.Render(inputLine))
+	}
 
 	if p.fileOpError != "" {
 		lines = append(lines, styles.StatusDeleted.Render(" "+p.fileOpError))
@@ -436,6 +481,18 @@ func (p *Plugin) renderTreePane(visibleHeight int) string {
 			sb.WriteString(" ")
 			sb.WriteString(styles.Muted.Render("[ignored: hidden]"))
 		}
+		if p.hasSelection() {
+			sb.WriteString(" ")
+			sb.WriteString(styles.StatusModified.Render(fmt.Sprintf("[%d selected]", len(p.selected))))
+		}
+		if p.compareMark != "" {
+			sb.WriteString(" ")
+			sb.WriteString(styles.StatusModified.Render("[= " + filepath.Base(p.compareMark) + "]"))
+		}
+		if status := p.batchStatus(); status != "" {
+			sb.WriteString(" ")
+			sb.WriteString(styles.Muted.Render("[" + status + "]"))
+		}
 	}
 	sb.WriteString("\n")
 
@@ -563,6 +620,15 @@ func (p *Plugin) renderTreeNode(node *FileNode, selected bool, maxWidth int) str
 	// Indentation
 	indent := strings.Repeat("  ", node.Depth)
 
+	// Selection mark column, shown while anything is selected
+	mark, markWidth := "", 0
+	if p.hasSelection() {
+		mark, markWidth = "  ", 2
+		if p.selected[node.Path] {
+			mark = "✓ "
+		}
+	}
+
 	// Icon for directories
 	icon := "  "
 	if node.IsDir {
@@ -574,7 +640,7 @@ func (p *Plugin) renderTreeNode(node *FileNode, selected bool, maxWidth int) str
 	}
 
 	// Calculate available width for name (after indent and icon)
-	prefixLen := len(indent) + len(icon)
+	prefixLen := markWidth + len(indent) + len(icon)
 	availableWidth := maxWidth - prefixLen
 	if availableWidth < 3 {
 		availableWidth = 3
@@ -596,16 +662,20 @@ func (p *Plugin) renderTreeNode(node *FileNode, selected bool, maxWidth int) str
 		name = styles.FileBrowserFile.Render(displayName)
 	}
 
-	line := fmt.Sprintf("%s%s%s", indent, styles.FileBrowserIcon.Render(icon), name)
+	styledMark := mark
+	if mark != "" {
+		styledMark = styles.StatusModified.Render(mark)
+	}
+	line := fmt.Sprintf("%s%s%s%s", styledMark, indent, styles.FileBrowserIcon.Render(icon), name)
 
 	if selected {
 		// Build plain text version for full-width highlight
 		plainLine := indent + icon + displayName
 		// Pad to full width
-		if len(plainLine) < maxWidth {
-			plainLine += strings.Repeat(" ", maxWidth-len(plainLine))
+		if width := markWidth + len(plainLine); width < maxWidth {
+			plainLine += strings.Repeat(" ", maxWidth-width)
 		}
-		return styles.ListItemSelected.Render(plainLine)
+		return styles.ListItemSelected.Render(mark + plainLine)
 	}
 	return line
 }
@@ -639,6 +709,12 @@ func (p *Plugin) renderPreviewPane(visibleHeight int) string {
 		if p.isMarkdownFile() && p.markdownRenderMode {
 			header += " [rendered]"
 		}
+		if p.structuredActive() {
+			header += " [" + strings.ToLower(structuredKindFor(p.previewFile).String()) + "]"
+		}
+		if p.tailActive() {
+			header += " [tail]"
+		}
 	}
 	sb.WriteString(styles.Title.Render(header))
 
@@ -674,6 +750,16 @@ func (p *Plugin) renderPreviewPane(visibleHeight int) string {
 		return sb.String()
 	}
 
+	if p.structuredActive() {
+		sb.WriteString(p.renderStructuredView(visibleHeight))
+		return sb.String()
+	}
+
+	if p.tailActive() {
+		sb.WriteString(p.renderTailView(visibleHeight))
+		return sb.String()
+	}
+
 	if p.isBinary {
 		sb.WriteString(styles.Muted.Render("Binary file"))
 		return sb.String()
@@ -693,6 +779,15 @@ func (p *Plugin) renderPreviewPane(visibleHeight int) string {
 		lines = p.previewLines
 	}
 
+	// An open change peek takes rows from the bottom of the pane
+	peek := ""
+	if p.diffPeekOpen && showLineNumbers {
+		if h := p.currentDiffHunk(); h != nil {
+			peek = p.renderDiffPeek(h, p.previewWidth-4, visibleHeight/2)
+			visibleHeight -= lipgloss.Height(peek)
+		}
+	}
+
 	start := p.previewScroll
 	end := start + visibleHeight
 	if end > len(lines) {
@@ -772,7 +867,7 @@ func (p *Plugin) renderPreviewPane(visibleHeight int) string {
 
 					// Line number with selection background (first wrapped line only)
 					if wi == 0 {
-						lineNumStr := fmt.Sprintf("%4d ", i+1)
+						lineNumStr := fmt.Sprintf("%4d", i+1) + gutterGlyph(p.lineGutterMark(i))
 						sb.WriteString(ui.InjectSelectionBackground(lineNumStr))
 					} else {
 						sb.WriteString(lineNumPad)
@@ -792,7 +887,7 @@ func (p *Plugin) renderPreviewPane(visibleHeight int) string {
 				lineContent = ui.ExpandTabs(lineContent, 8)
 				lineContent = ui.InjectCharacterRangeBackground(lineContent, startCol, endCol)
 				// Truncate using lipgloss (handles ANSI codes properly)
-				lineNumStr := fmt.Sprintf("%4d ", i+1)
+				lineNumStr := fmt.Sprintf("%4d", i+1) + gutterGlyph(p.lineGutterMark(i))
 				sb.WriteString(ui.InjectSelectionBackground(lineNumStr))
 				lineContent = lipgloss.NewStyle().MaxWidth(maxLineWidth).Render(lineContent)
 				sb.WriteString(lineContent)
@@ -832,8 +927,7 @@ func (p *Plugin) renderPreviewPane(visibleHeight int) string {
 					}
 					if showLineNumbers {
 						if wi == 0 {
-							lineNum := styles.FileBrowserLineNumber.Render(fmt.Sprintf("%4d ", i+1))
-							sb.WriteString(lineNum)
+							sb.WriteString(p.previewLineNumber(i))
 						} else {
 							sb.WriteString(lineNumPad)
 						}
@@ -850,8 +944,7 @@ func (p *Plugin) renderPreviewPane(visibleHeight int) string {
 
 				// Render with or without line numbers
 				if showLineNumbers {
-					lineNum := styles.FileBrowserLineNumber.Render(fmt.Sprintf("%4d ", i+1))
-					sb.WriteString(lineNum)
+					sb.WriteString(p.previewLineNumber(i))
 				}
 				sb.WriteString(line)
 				visualLinesRendered++
@@ -870,6 +963,11 @@ func (p *Plugin) renderPreviewPane(visibleHeight int) string {
 		sb.WriteString(styles.Muted.Render("... (file truncated)"))
 	}
 
+	if peek != "" {
+		sb.WriteString("\n")
+		sb.WriteString(peek)
+	}
+
 	return sb.String()
 }
 
@@ -0,0 +1,150 @@
+package filebrowser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/marcus/sidecar/internal/plugins/gitstatus"
+	"github.com/marcus/sidecar/internal/styles"
+)
+
+// compareModalWidth returns the width of the compare modal.
+func (p *Plugin) compareModalWidth() int {
+	return min(max(p.width-4, 40), 160)
+}
+
+// compareDiffHeight returns the number of diff rows the modal shows.
+func (p *Plugin) compareDiffHeight() int {
+	return max(p.height-10, 4)
+}
+
+// renderCompareModalContent renders the compare view: a list of differing
+// files for directories, or a diff for files.
+func (p *Plugin) renderCompareModalContent() string {
+	v := p.compare
+	modalWidth := p.compareModalWidth()
+	innerWidth := modalWidth - 4
+
+	var sb strings.Builder
+	title := fmt.Sprintf("Compare: %s ↔ %s", p.compareDisplayPath(v.Left), p.compareDisplayPath(v.Right))
+	sb.WriteString(styles.ModalTitle.Render(ansi.Truncate(title, innerWidth, "…")))
+	sb.WriteString("\n\n")
+
+	var footer string
+	switch {
+	case v.Err != nil:
+		sb.WriteString(styles.StatusDeleted.Render(ansi.Truncate(v.Err.Error(), innerWidth, "…")))
+		footer = "esc close"
+	case v.Loading:
+		sb.WriteString(styles.Muted.Render("Comparing..."))
+		footer = "esc close"
+	case v.inDiff():
+		sb.WriteString(p.renderCompareDiff(innerWidth, p.compareDiffHeight()))
+		footer = "j/k scroll · v toggle view · esc close"
+		if v.IsDir {
+			footer = fmt.Sprintf("(%d/%d)  j/k scroll · n/N next/prev file · v toggle view · esc back",
+				v.Cursor+1, len(v.Entries))
+		}
+	default:
+		sb.WriteString(p.renderCompareEntries(innerWidth, p.compareDiffHeight()))
+		footer = "enter diff · esc close"
+	}
+
+	sb.WriteString("\n\n")
+	sb.WriteString(styles.Muted.Render(ansi.Truncate(footer, innerWidth, "…")))
+
+	return styles.ModalBox.
+		Width(modalWidth).
+		Render(sb.String())
+}
+
+// renderCompareEntries renders the differing files of a directory
+// comparison with a summary line.
+func (p *Plugin) renderCompareEntries(width, height int) string {
+	v := p.compare
+	var added, removed, changed int
+	for _, e := range v.Entries {
+		switch e.Status {
+		case compareAdded:
+			added++
+		case compareRemoved:
+			removed++
+		default:
+			changed++
+		}
+	}
+	summary := fmt.Sprintf("%d added, %d removed, %d changed, %d identical", added, removed, changed, v.Identical)
+	if v.Truncated {
+		summary += fmt.Sprintf(" (stopped after %d files)", compareMaxFiles)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(styles.Muted.Render(ansi.Truncate(summary, width, "…")))
+	sb.WriteString("\n\n")
+	if len(v.Entries) == 0 {
+		sb.WriteString(styles.Muted.Render("Directories are identical"))
+		return sb.String()
+	}
+
+	listHeight := max(height-2, 1)
+	start := 0
+	if v.Cursor >= listHeight {
+		start = v.Cursor - listHeight + 1
+	}
+	end := min(start+listHeight, len(v.Entries))
+	for i := start; i < end; i++ {
+		e := v.Entries[i]
+		var status string
+		switch e.Status {
+		case compareAdded:
+			status = styles.StatusStaged.Render("A")
+		case compareRemoved:
+			status = styles.StatusDeleted.Render("D")
+		default:
+			status = styles.StatusModified.Render("M")
+		}
+		path := ansi.Truncate(e.Path, width-6, "…")
+		if i == v.Cursor {
+			sb.WriteString(styles.QuickOpenItemSelected.Render("> ") + status + styles.QuickOpenItemSelected.Render(" "+path))
+		} else {
+			sb.WriteString(styles.QuickOpenItem.Render("  ") + status + styles.QuickOpenItem.Render(" "+path))
+		}
+		if i < end-1 {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// renderCompareDiff renders the open file diff with the git status diff
+// renderers, scrolled by the view's offset.
+func (p *Plugin) renderCompareDiff(width, height int) string {
+	v := p.compare
+	name := v.FilePath
+	if name == "" {
+		name = p.compareDisplayPath(v.Right)
+	}
+
+	switch {
+	case v.DiffErr != nil:
+		return styles.StatusDeleted.Render(ansi.Truncate(v.DiffErr.Error(), width, "…"))
+	case v.Diff == nil:
+		return styles.Muted.Render("Loading diff...")
+	case v.Diff.Binary:
+		return styles.Muted.Render("Binary files differ")
+	case len(v.Diff.Hunks) == 0:
+		return styles.Muted.Render("Files are identical")
+	}
+
+	header := styles.DiffHeader.Render(ansi.Truncate(fmt.Sprintf("── %s ", name), width, ""))
+	highlighter := gitstatus.NewSyntaxHighlighter(name)
+	scroll := min(v.Scroll, max(v.compareDiffLines()-(height-1), 0))
+	var body string
+	if v.Unified {
+		body = gitstatus.RenderLineDiff(v.Diff, width, scroll, height-1, 0, highlighter, false)
+	} else {
+		body = gitstatus.RenderSideBySide(v.Diff, width, scroll, height-1, 0, highlighter, false)
+	}
+	return header + "\n" + body
+}
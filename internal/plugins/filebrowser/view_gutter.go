@@ -0,0 +1,90 @@
+package filebrowser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/marcus/sidecar/internal/plugins/gitstatus"
+	"github.com/marcus/sidecar/internal/styles"
+	"github.com/marcus/sidecar/internal/ui"
+)
+
+// lineGutterMark returns the git change marker for a 0-indexed preview line.
+func (p *Plugin) lineGutterMark(i int) gutterMark {
+	if g := p.currentDiffGutter(); g != nil {
+		return g.Marks[i]
+	}
+	return gutterNone
+}
+
+// gutterGlyph returns the unstyled one-cell marker for a change.
+func gutterGlyph(mark gutterMark) string {
+	switch mark {
+	case gutterAdded, gutterModified:
+		return "▎"
+	case gutterDeleted:
+		return "▁"
+	}
+	return " "
+}
+
+// previewLineNumber renders the line number column: a 4-cell number and
+// the change marker in place of the trailing space.
+func (p *Plugin) previewLineNumber(i int) string {
+	num := styles.FileBrowserLineNumber.Width(4).Render(fmt.Sprintf("%4d", i+1))
+	mark := p.lineGutterMark(i)
+	switch mark {
+	case gutterAdded:
+		return num + styles.DiffAdd.Render(gutterGlyph(mark))
+	case gutterModified:
+		return num + styles.StatusModified.Render(gutterGlyph(mark))
+	case gutterDeleted:
+		return num + styles.DiffRemove.Render(gutterGlyph(mark))
+	}
+	return num + " "
+}
+
+// renderDiffPeek renders a hunk's original and new lines for display below
+// the preview, using at most maxHeight rows.
+func (p *Plugin) renderDiffPeek(h *gitstatus.Hunk, width, maxHeight int) string {
+	if maxHeight < 3 {
+		maxHeight = 3
+	}
+	base := "HEAD"
+	if g := p.currentDiffGutter(); g != nil {
+		base = g.Base
+	}
+
+	var sb strings.Builder
+	header := fmt.Sprintf("── vs %s @@ -%d,%d +%d,%d @@ ", base, h.OldStart, h.OldCount, h.NewStart, h.NewCount)
+	if w := ansi.StringWidth(header); w < width {
+		header += strings.Repeat("─", width-w)
+	}
+	sb.WriteString(styles.DiffHeader.Render(ansi.Truncate(header, width, "")))
+
+	rows := maxHeight - 1
+	lines := h.Lines
+	// ParseUnifiedDiff keeps the blank line after the final hunk as context
+	if n := len(lines); n > 0 && lines[n-1].Type == gitstatus.LineContext && lines[n-1].Content == "" {
+		lines = lines[:n-1]
+	}
+	for i, dl := range lines {
+		if i == rows-1 && len(lines) > rows {
+			sb.WriteString("\n")
+			sb.WriteString(styles.Muted.Render(fmt.Sprintf("… %d more lines", len(lines)-i)))
+			break
+		}
+		text := ansi.Truncate(ui.ExpandTabs(dl.Content, 8), width-2, "…")
+		sb.WriteString("\n")
+		switch dl.Type {
+		case gitstatus.LineAdd:
+			sb.WriteString(styles.DiffAdd.Render("+ " + text))
+		case gitstatus.LineRemove:
+			sb.WriteString(styles.DiffRemove.Render("- " + text))
+		default:
+			sb.WriteString(styles.DiffContext.Render("  " + text))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,149 @@
+package filebrowser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/marcus/sidecar/internal/localhistory"
+	"github.com/marcus/sidecar/internal/plugins/gitstatus"
+	"github.com/marcus/sidecar/internal/styles"
+	"github.com/marcus/sidecar/internal/ui"
+)
+
+// renderHistoryModalContent renders the local history timeline: snapshots
+// of the previewed file above the diff of the selected one.
+func (p *Plugin) renderHistoryModalContent() string {
+	modalWidth := min(max(p.width-4, 40), 120)
+	innerWidth := modalWidth - 4
+	listHeight := min(max(len(p.historySnaps), 1), 8)
+	diffHeight := max(p.height-listHeight-12, 4)
+
+	var sb strings.Builder
+	sb.WriteString(styles.ModalTitle.Render("Local History: " + ansi.Truncate(p.historyPath, innerWidth-15, "…")))
+	sb.WriteString("\n\n")
+
+	switch {
+	case p.historyErr != nil:
+		sb.WriteString(styles.StatusDeleted.Render(ansi.Truncate(p.historyErr.Error(), innerWidth, "…")))
+	case len(p.historySnaps) == 0:
+		sb.WriteString(styles.Muted.Render("No snapshots yet"))
+	default:
+		start := 0
+		if p.historyCursor >= listHeight {
+			start = p.historyCursor - listHeight + 1
+		}
+		end := min(start+listHeight, len(p.historySnaps))
+		for i := start; i < end; i++ {
+			line := p.renderSnapshotRow(i, p.historySnaps[i], innerWidth-2)
+			if i == p.historyCursor {
+				sb.WriteString(styles.QuickOpenItemSelected.Render("> " + line))
+			} else {
+				sb.WriteString(styles.QuickOpenItem.Render("  " + line))
+			}
+			if i < end-1 {
+				sb.WriteString("\n")
+			}
+		}
+	}
+
+	sb.WriteString("\n\n")
+	sb.WriteString(p.renderHistoryDiff(innerWidth, diffHeight))
+	sb.WriteString("\n\n")
+
+	if p.historyConfirm {
+		if sel := p.selectedSnapshot(); sel != nil {
+			sb.WriteString(styles.StatusModified.Render(
+				fmt.Sprintf("Restore %s to the version from %s? (y/n)", p.historyPath, snapshotLabel(*sel))))
+		}
+	} else {
+		footer := "enter restore · space mark base · J/K scroll diff · esc close"
+		if len(p.historySnaps) > 0 {
+			footer = fmt.Sprintf("(%d/%d)  %s", p.historyCursor+1, len(p.historySnaps), footer)
+		}
+		sb.WriteString(styles.Muted.Render(ansi.Truncate(footer, innerWidth, "…")))
+	}
+
+	return styles.ModalBox.
+		Width(modalWidth).
+		Render(sb.String())
+}
+
+// renderSnapshotRow renders one timeline entry: time, age, size and a
+// marker for the compare base.
+func (p *Plugin) renderSnapshotRow(i int, s localhistory.Snapshot, width int) string {
+	mark := "  "
+	if i == p.historyBase {
+		mark = "◆ "
+	}
+	row := fmt.Sprintf("%s%-15s %-9s %8s", mark, snapshotLabel(s), trashAge(s.Time), formatSize(s.Size))
+	if i == 0 {
+		row += styles.Muted.Render("  latest")
+	}
+	return ansi.Truncate(row, width, "…")
+}
+
+// renderHistoryDiff renders the selected comparison, scrolled by
+// historyDiffScroll and limited to height rows.
+func (p *Plugin) renderHistoryDiff(width, height int) string {
+	_, _, oldLabel, newLabel, ok := p.historyPair()
+	if !ok {
+		return styles.Muted.Render(strings.Repeat("─", width))
+	}
+	header := fmt.Sprintf("── %s → %s ", oldLabel, newLabel)
+	if w := ansi.StringWidth(header); w < width {
+		header += strings.Repeat("─", width-w)
+	}
+
+	var lines []string
+	switch {
+	case p.historyDiffErr != nil:
+		lines = []string{styles.StatusDeleted.Render(ansi.Truncate(p.historyDiffErr.Error(), width, "…"))}
+	case p.historyDiff == nil:
+		lines = []string{styles.Muted.Render("Loading diff...")}
+	case p.historyDiff.Binary:
+		lines = []string{styles.Muted.Render("Binary content differs")}
+	case len(p.historyDiff.Hunks) == 0:
+		lines = []string{styles.Muted.Render("No differences")}
+	default:
+		lines = renderHunkLines(p.historyDiff.Hunks, width)
+	}
+
+	maxScroll := max(len(lines)-(height-1), 0)
+	scroll := min(p.historyDiffScroll, maxScroll)
+	end := min(scroll+height-1, len(lines))
+
+	var sb strings.Builder
+	sb.WriteString(styles.DiffHeader.Render(ansi.Truncate(header, width, "")))
+	for _, line := range lines[scroll:end] {
+		sb.WriteString("\n")
+		sb.WriteString(line)
+	}
+	return sb.String()
+}
+
+// renderHunkLines renders hunks as styled diff lines with @@ separators.
+func renderHunkLines(hunks []gitstatus.Hunk, width int) []string {
+	var out []string
+	for _, h := range hunks {
+		out = append(out, styles.DiffHeader.Render(ansi.Truncate(
+			fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.OldStart, h.OldCount, h.NewStart, h.NewCount), width, "")))
+		lines := h.Lines
+		// ParseUnifiedDiff keeps the blank line after the final hunk as context
+		if n := len(lines); n > 0 && lines[n-1].Type == gitstatus.LineContext && lines[n-1].Content == "" {
+			lines = lines[:n-1]
+		}
+		for _, dl := range lines {
+			text := ansi.Truncate(ui.ExpandTabs(dl.Content, 8), width-2, "…")
+			switch dl.Type {
+			case gitstatus.LineAdd:
+				out = append(out, styles.DiffAdd.Render("+ "+text))
+			case gitstatus.LineRemove:
+				out = append(out, styles.DiffRemove.Render("- "+text))
+			default:
+				out = append(out, styles.DiffContext.Render("  "+text))
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,258 @@
+package filebrowser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/marcus/sidecar/internal/styles"
+)
+
+// cleanCell flattens a value onto one line for table and tree display.
+func cleanCell(s string) string {
+	if !strings.ContainsAny(s, "\n\r\t") {
+		return s
+	}
+	return strings.NewReplacer("\r\n", "↵", "\n", "↵", "\r", "↵", "\t", " ").Replace(s)
+}
+
+func cellWidth(s string) int {
+	return ansi.StringWidth(cleanCell(s))
+}
+
+// padCell truncates or pads a value to exactly width cells.
+func padCell(s string, width int) string {
+	s = ansi.Truncate(cleanCell(s), width, "…")
+	if w := ansi.StringWidth(s); w < width {
+		s += strings.Repeat(" ", width-w)
+	}
+	return s
+}
+
+// renderStructuredView renders the tree or table view of the current file
+// in place of the text preview.
+func (p *Plugin) renderStructuredView(height int) string {
+	width := max(p.previewWidth-4, 10)
+	v := p.structured
+	kind := structuredKindFor(p.previewFile)
+
+	if p.structuredErr != nil {
+		msg := styles.StatusDeleted.Render(ansi.Truncate(kind.String()+": "+p.structuredErr.Error(), width, "…"))
+		if kind != StructuredSQLite || !p.isBinary {
+			msg += "\n" + styles.Muted.Render("Press v to view as text")
+		}
+		return msg
+	}
+	if v == nil {
+		return styles.Muted.Render("Loading " + kind.String() + "…")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(styles.Muted.Render(ansi.Truncate(p.structuredStatus(v), width, "…")))
+	sb.WriteString("\n")
+	if v.Kind.IsTree() {
+		sb.WriteString(p.renderStructuredTree(v, width, height-1))
+	} else {
+		sb.WriteString(p.renderStructuredTable(v, width, height-1))
+	}
+	return sb.String()
+}
+
+// structuredStatus describes the view: format, size, position and sorting.
+func (p *Plugin) structuredStatus(v *StructuredView) string {
+	var parts []string
+	switch {
+	case v.Kind.IsTree():
+		parts = append(parts, fmt.Sprintf("%s · %d nodes", v.Kind, v.Nodes))
+		if v.Truncated {
+			parts = append(parts, "first "+strconv.Itoa(structuredMaxNodes)+" nodes")
+		}
+		if n := v.CurrentNode(); n != nil {
+			parts = append(parts, n.Path)
+		}
+	case v.Kind == StructuredSQLite:
+		if len(v.Tables) == 0 {
+			return "SQLite · no tables"
+		}
+		t := v.Tables[v.TableIdx]
+		parts = append(parts, fmt.Sprintf("SQLite · %s (%d/%d)", t.Name, v.TableIdx+1, len(v.Tables)))
+		if v.Table != nil && len(v.Table.Rows) > 0 {
+			first := v.Page*sqlitePageSize + 1
+			rows := fmt.Sprintf("rows %d–%d", first, first+len(v.Table.Rows)-1)
+			if t.Count >= 0 {
+				rows += fmt.Sprintf(" of %d", t.Count)
+			}
+			parts = append(parts, rows)
+		}
+	default:
+		rows := 0
+		cols := 0
+		if v.Table != nil {
+			rows, cols = len(v.Table.Rows), len(v.Table.Columns)
+		}
+		parts = append(parts, fmt.Sprintf("%s · %d rows × %d columns", v.Kind, rows, cols))
+		if v.Truncated {
+			parts = append(parts, "first "+strconv.Itoa(structuredMaxRows)+" rows")
+		}
+	}
+	if !v.Kind.IsTree() && v.Table != nil && v.SortCol >= 0 && v.SortCol < len(v.Table.Columns) {
+		dir := "▲"
+		if v.SortDesc {
+			dir = "▼"
+		}
+		parts = append(parts, "sorted by "+v.Table.Columns[v.SortCol]+" "+dir)
+	}
+	if p.structuredLoading {
+		parts = append(parts, "loading…")
+	}
+	return strings.Join(parts, " · ")
+}
+
+// renderStructuredTree renders the visible rows of a JSON/YAML tree.
+func (p *Plugin) renderStructuredTree(v *StructuredView, width, height int) string {
+	var sb strings.Builder
+	end := min(v.Scroll+height, len(v.rows))
+	for i := v.Scroll; i < end; i++ {
+		row := v.rows[i]
+		n := row.Node
+		indent := strings.Repeat("  ", row.Depth)
+
+		icon := "  "
+		if n.Kind != DataScalar {
+			icon = "▸ "
+			if v.expanded[n.Path] {
+				icon = "▾ "
+			}
+		}
+		key := ""
+		if row.Depth > 0 {
+			key = n.Key + ": "
+		}
+		value := n.Summary()
+		if n.Kind == DataScalar && n.Scalar == ScalarString {
+			value = strconv.Quote(n.Value)
+		}
+
+		if i > v.Scroll {
+			sb.WriteString("\n")
+		}
+		if i == v.Cursor {
+			line := ansi.Truncate(indent+icon+key+cleanCell(value), width, "…")
+			sb.WriteString(styles.ListItemSelected.Render(padCell(line, width)))
+			continue
+		}
+
+		line := indent + styles.FileBrowserIcon.Render(icon)
+		if key != "" {
+			if _, err := strconv.Atoi(n.Key); err == nil {
+				line += styles.Muted.Render(key)
+			} else {
+				line += styles.FileBrowserDir.Render(key)
+			}
+		}
+		line += scalarStyle(n).Render(cleanCell(value))
+		sb.WriteString(ansi.Truncate(line, width, "…"))
+	}
+	return sb.String()
+}
+
+// scalarStyle colors values by type.
+func scalarStyle(n *DataNode) lipgloss.Style {
+	if n.Kind != DataScalar {
+		return styles.Muted
+	}
+	switch n.Scalar {
+	case ScalarString:
+		return styles.DiffAdd
+	case ScalarNumber, ScalarBool:
+		return styles.Code
+	}
+	return styles.Muted
+}
+
+// renderStructuredTable renders a header, separator and the visible rows,
+// scrolled horizontally to keep the selected column in view.
+func (p *Plugin) renderStructuredTable(v *StructuredView, width, height int) string {
+	t := v.Table
+	if t == nil || len(t.Columns) == 0 {
+		return styles.Muted.Render("No rows")
+	}
+	widths := v.ColumnWidths()
+	const sep = "  "
+
+	// First column shown: as far left as keeps the selected column visible
+	start := v.Column
+	used := widths[v.Column]
+	for start > 0 && used+len(sep)+widths[start-1] <= width {
+		start--
+		used += len(sep) + widths[start]
+	}
+	var cols []int
+	used = 0
+	for c := start; c < len(t.Columns); c++ {
+		if len(cols) > 0 && used+len(sep) >= width {
+			break
+		}
+		cols = append(cols, c)
+		used += widths[c] + len(sep)
+	}
+
+	var sb strings.Builder
+	var header []string
+	for _, c := range cols {
+		name := t.Columns[c]
+		if c == v.SortCol {
+			if v.SortDesc {
+				name += " ▼"
+			} else {
+				name += " ▲"
+			}
+		}
+		cell := padCell(name, widths[c])
+		if c == v.Column {
+			header = append(header, styles.FileBrowserDir.Render(cell))
+		} else {
+			header = append(header, styles.Title.Render(cell))
+		}
+	}
+	sb.WriteString(ansi.Truncate(strings.Join(header, sep), width, ""))
+	sb.WriteString("\n")
+	sb.WriteString(styles.Muted.Render(strings.Repeat("─", width)))
+
+	if len(t.Rows) == 0 {
+		sb.WriteString("\n")
+		sb.WriteString(styles.Muted.Render("No rows"))
+		return sb.String()
+	}
+
+	end := min(v.Scroll+height-2, len(t.Rows))
+	for i := v.Scroll; i < end; i++ {
+		row := v.TableRow(i)
+		sb.WriteString("\n")
+		var cells []string
+		for _, c := range cols {
+			val := ""
+			if c < len(row) {
+				val = row[c]
+			}
+			cell := padCell(val, widths[c])
+			switch {
+			case i == v.Cursor && c == v.Column:
+				cell = styles.ListItemFocused.Render(cell)
+			case i == v.Cursor:
+				cell = styles.ListItemSelected.Render(cell)
+			case v.Kind == StructuredSQLite && val == "NULL":
+				cell = styles.Muted.Render(cell)
+			}
+			cells = append(cells, cell)
+		}
+		joiner := sep
+		if i == v.Cursor {
+			joiner = styles.ListItemSelected.Render(sep)
+		}
+		sb.WriteString(ansi.Truncate(strings.Join(cells, joiner), width, ""))
+	}
+	return sb.String()
+}
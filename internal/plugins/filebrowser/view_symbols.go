@@ -0,0 +1,146 @@
+package filebrowser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/marcus/sidecar/internal/styles"
+)
+
+// symbolKindWidth is the column width of the kind label in the palette.
+const symbolKindWidth = 9
+
+// renderSymbolModalContent renders the symbol palette for the current scope.
+func (p *Plugin) renderSymbolModalContent() string {
+	modalWidth := p.width - 4
+	if modalWidth > 90 {
+		modalWidth = 90
+	}
+	if modalWidth < 30 {
+		modalWidth = 30
+	}
+
+	maxListHeight := p.height - 8
+	if maxListHeight < 5 {
+		maxListHeight = 5
+	}
+	if maxListHeight > 20 {
+		maxListHeight = 20
+	}
+
+	var sb strings.Builder
+
+	var title string
+	switch p.symbolScope {
+	case SymbolScopeFile:
+		title = "Outline"
+	case SymbolScopeProject:
+		title = "Symbols"
+	case SymbolScopeDefinitions:
+		title = "Definitions"
+	}
+	sb.WriteString(styles.ModalTitle.Render(fmt.Sprintf("%s: %s█", title, p.symbolQuery)))
+	sb.WriteString("\n\n")
+
+	// Hit regions: same layout as quick open
+	hPad := (p.width - modalWidth - 4) / 2
+	if hPad < 0 {
+		hPad = 0
+	}
+	modalX := hPad + 1
+	modalItemY := 2 + 3
+
+	if len(p.symbolMatches) == 0 {
+		switch {
+		case p.symbolScope == SymbolScopeProject && p.symbolIndexing && p.symbolIndex == nil:
+			sb.WriteString(styles.Muted.Render("Indexing symbols..."))
+		case p.symbolQuery != "":
+			sb.WriteString(styles.Muted.Render("No matches"))
+		default:
+			sb.WriteString(styles.Muted.Render("No symbols"))
+		}
+	} else {
+		listHeight := maxListHeight
+		if listHeight > len(p.symbolMatches) {
+			listHeight = len(p.symbolMatches)
+		}
+		start := 0
+		if p.symbolCursor >= listHeight {
+			start = p.symbolCursor - listHeight + 1
+		}
+		end := start + listHeight
+		if end > len(p.symbolMatches) {
+			end = len(p.symbolMatches)
+		}
+
+		for i := start; i < end; i++ {
+			p.mouseHandler.HitMap.AddRect(regionSymbol, modalX, modalItemY+(i-start), modalWidth-2, 1, i)
+
+			line := p.renderSymbolMatch(p.symbolMatches[i], modalWidth-4)
+			if i == p.symbolCursor {
+				sb.WriteString(styles.QuickOpenItemSelected.Render("> " + line))
+			} else {
+				sb.WriteString(styles.QuickOpenItem.Render("  " + line))
+			}
+			if i < end-1 {
+				sb.WriteString("\n")
+			}
+		}
+	}
+
+	// Footer with position and index status
+	footer := ""
+	if len(p.symbolMatches) > 0 {
+		footer = fmt.Sprintf("(%d/%d)", p.symbolCursor+1, len(p.symbolMatches))
+	}
+	if p.symbolScope == SymbolScopeProject {
+		if p.symbolIndexing && p.symbolIndex != nil {
+			footer += "  updating index..."
+		} else if p.symbolIndex != nil && p.symbolIndex.Limited {
+			footer += "  ⚠ index incomplete (project too large)"
+		}
+	}
+	if footer != "" {
+		sb.WriteString("\n\n")
+		sb.WriteString(styles.Muted.Render(strings.TrimSpace(footer)))
+	}
+
+	return styles.ModalBox.
+		Width(modalWidth).
+		Render(sb.String())
+}
+
+// renderSymbolMatch renders one palette row: kind, highlighted name and,
+// outside the file outline, the symbol's location.
+func (p *Plugin) renderSymbolMatch(match SymbolMatch, maxWidth int) string {
+	sym := match.Symbol
+	kind := sym.Kind
+	if len(kind) > symbolKindWidth-1 {
+		kind = kind[:symbolKindWidth-1]
+	}
+	kindCol := styles.Muted.Render(kind + strings.Repeat(" ", symbolKindWidth-len(kind)))
+
+	name := sym.QualifiedName()
+	loc := ":" + strconv.Itoa(sym.Line)
+	if p.symbolScope != SymbolScopeFile {
+		loc = sym.Path + loc
+	}
+
+	nameWidth := maxWidth - symbolKindWidth - len(loc) - 2
+	if nameWidth < 10 {
+		nameWidth = 10
+	}
+	var nameCol string
+	if len(name) > nameWidth {
+		nameCol = name[:nameWidth-3] + "..."
+	} else {
+		nameCol = p.highlightFuzzyMatch(name, match.MatchRanges) + strings.Repeat(" ", nameWidth-len(name))
+	}
+
+	locWidth := maxWidth - symbolKindWidth - nameWidth - 2
+	if locWidth > 3 && len(loc) > locWidth {
+		loc = "..." + loc[len(loc)-locWidth+3:]
+	}
+	return kindCol + nameCol + "  " + styles.Muted.Render(loc)
+}
@@ -0,0 +1,93 @@
+package filebrowser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/marcus/sidecar/internal/styles"
+	"github.com/marcus/sidecar/internal/ui"
+)
+
+// logLevelPattern finds the first log level word in a line, covering plain
+// ("ERROR"), bracketed ("[warn]") and key-value ("level=info") forms.
+var logLevelPattern = regexp.MustCompile(`(?i)\b(fatal|panic|crit(?:ical)?|err(?:or)?|warn(?:ing)?|info|debug|dbg|trace)\b`)
+
+// nonColorEscapes matches terminal escapes other than SGR colors (cursor
+// movement, line clearing, OSC titles) that would corrupt the layout.
+var nonColorEscapes = regexp.MustCompile(`\x1b\[[0-9;?]*[@-ln-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)|[\x00-\x08\x0b-\x1a\x1c-\x1f\x7f]`)
+
+// logLevelStyle returns the style for a line by its log level, or nil to
+// leave it unstyled. Lines with their own ANSI colors are left alone.
+func logLevelStyle(line string) *lipgloss.Style {
+	if strings.Contains(line, "\x1b[") {
+		return nil
+	}
+	m := logLevelPattern.FindStringSubmatch(line)
+	if m == nil {
+		return nil
+	}
+	switch strings.ToLower(m[1])[0] {
+	case 'f', 'p', 'c', 'e':
+		return &styles.StatusDeleted
+	case 'w':
+		return &styles.StatusModified
+	case 'd', 't':
+		return &styles.Muted
+	}
+	return nil
+}
+
+// renderTailView renders the followed file's newest lines, or the scrolled
+// position while paused.
+func (p *Plugin) renderTailView(height int) string {
+	t := p.tail
+	width := max(p.previewWidth-4, 10)
+
+	var sb strings.Builder
+	status := t.tailStatus()
+	if t.Editing || t.FilterErr != nil {
+		status = "filter: " + t.FilterText
+		if t.Editing {
+			status += "█"
+		}
+		if t.FilterErr != nil {
+			status += "  (invalid pattern)"
+		}
+	}
+	sb.WriteString(styles.Muted.Render(ansi.Truncate(status, width, "…")))
+
+	body := max(height-1, 1)
+	count := t.shownCount()
+	start := t.Scroll
+	if t.Follow {
+		start = count - body
+	}
+	start = max(min(start, count-body), 0)
+	end := min(start+body, count)
+	if count == 0 {
+		sb.WriteString("\n")
+		switch {
+		case t.Filter != nil:
+			sb.WriteString(styles.Muted.Render("No lines match the filter"))
+		case t.Err == nil:
+			sb.WriteString(styles.Muted.Render("Waiting for output…"))
+		}
+		return sb.String()
+	}
+
+	for i := start; i < end; i++ {
+		line := ui.ExpandTabs(nonColorEscapes.ReplaceAllString(t.shownLine(i), ""), 8)
+		line = ansi.Truncate(line, width, "…")
+		if style := logLevelStyle(line); style != nil {
+			line = style.Render(line)
+		} else if strings.Contains(line, "\x1b[") {
+			// Reset so a color left open doesn't bleed into the next row
+			line += "\x1b[0m"
+		}
+		sb.WriteString("\n")
+		sb.WriteString(line)
+	}
+	return sb.String()
+}
@@ -0,0 +1,109 @@
+package filebrowser
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/marcus/sidecar/internal/styles"
+	"github.com/marcus/sidecar/internal/trash"
+)
+
+// renderTrashModalContent renders the list of items trashed from the project.
+func (p *Plugin) renderTrashModalContent() string {
+	modalWidth := min(max(p.width-4, 30), 90)
+	maxListHeight := min(max(p.height-10, 5), 20)
+	innerWidth := modalWidth - 4
+
+	var sb strings.Builder
+	sb.WriteString(styles.ModalTitle.Render("Trash"))
+	sb.WriteString("\n\n")
+
+	switch {
+	case p.trashErr != nil:
+		sb.WriteString(styles.StatusDeleted.Render(ansi.Truncate(p.trashErr.Error(), innerWidth, "…")))
+	case len(p.trashItems) == 0 && p.trashLoading:
+		sb.WriteString(styles.Muted.Render("Loading..."))
+	case len(p.trashItems) == 0:
+		sb.WriteString(styles.Muted.Render("Trash is empty"))
+	default:
+		listHeight := min(maxListHeight, len(p.trashItems))
+		start := 0
+		if p.trashCursor >= listHeight {
+			start = p.trashCursor - listHeight + 1
+		}
+		end := min(start+listHeight, len(p.trashItems))
+		for i := start; i < end; i++ {
+			line := p.renderTrashItem(p.trashItems[i], innerWidth-2)
+			if i == p.trashCursor {
+				sb.WriteString(styles.QuickOpenItemSelected.Render("> " + line))
+			} else {
+				sb.WriteString(styles.QuickOpenItem.Render("  " + line))
+			}
+			if i < end-1 {
+				sb.WriteString("\n")
+			}
+		}
+	}
+
+	sb.WriteString("\n\n")
+	switch p.trashConfirm {
+	case trashConfirmPurge:
+		if item := p.selectedTrashItem(); item != nil {
+			sb.WriteString(styles.StatusDeleted.Render(ansi.Truncate(
+				fmt.Sprintf("Permanently delete %s? (y/n)", p.trashDisplayPath(*item)), innerWidth, "…")))
+		}
+	case trashConfirmEmpty:
+		sb.WriteString(styles.StatusDeleted.Render(
+			fmt.Sprintf("Permanently delete all %s? (y/n)", pluralItems(len(p.trashItems)))))
+	default:
+		var total int64
+		for _, it := range p.trashItems {
+			total += it.Size
+		}
+		footer := fmt.Sprintf("%s · %s", pluralItems(len(p.trashItems)), formatSize(total))
+		if len(p.trashItems) > 0 {
+			footer = fmt.Sprintf("(%d/%d) %s", p.trashCursor+1, len(p.trashItems), footer)
+		}
+		sb.WriteString(styles.Muted.Render(footer + "  enter restore · D delete · E empty"))
+	}
+
+	return styles.ModalBox.
+		Width(modalWidth).
+		Render(sb.String())
+}
+
+// trashDisplayPath shows an item's original path relative to the project.
+func (p *Plugin) trashDisplayPath(it trash.Item) string {
+	rel := relTo(p.ctx.WorkDir, it.OriginalPath)
+	if it.IsDir {
+		rel += "/"
+	}
+	return rel
+}
+
+// renderTrashItem renders one row: original path, size and deletion age.
+func (p *Plugin) renderTrashItem(it trash.Item, width int) string {
+	meta := fmt.Sprintf("%8s  %s", formatSize(it.Size), trashAge(it.DeletedAt))
+	pathWidth := max(width-ansi.StringWidth(meta)-2, 10)
+	path := ansi.Truncate(p.trashDisplayPath(it), pathWidth, "…")
+	if w := ansi.StringWidth(path); w < pathWidth {
+		path += strings.Repeat(" ", pathWidth-w)
+	}
+	return path + "  " + styles.Muted.Render(meta)
+}
+
+// trashAge formats how long ago an item was deleted.
+func trashAge(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+}
@@ -8,6 +8,10 @@ import (
 	"github.com/fsnotify/fsnotify"
 )
 
+// maxDebounceDelay bounds how long debouncing can hold back an event, so a
+// file written continuously (a growing log) still reports changes.
+const maxDebounceDelay = 500 * time.Millisecond
+
 // Watcher monitors a single file for changes.
 // Only watches the currently previewed file, not the entire directory tree.
 type Watcher struct {
@@ -16,6 +20,7 @@ type Watcher struct {
 	events       chan struct{}
 	stop         chan struct{}
 	debounce     *time.Timer
+	pendingSince time.Time // When the pending debounced event was first held back
 	mu           sync.Mutex
 	closed       bool
 }
@@ -111,13 +116,23 @@ func (w *Watcher) run() {
 
 			// Debounce: wait 100ms for more events before signaling
 			w.mu.Lock()
+			if !w.pendingSince.IsZero() && time.Since(w.pendingSince) >= maxDebounceDelay {
+				// Let the pending timer fire rather than postponing it again
+				w.mu.Unlock()
+				continue
+			}
 			if w.debounce != nil {
 				w.debounce.Stop()
 			}
+			if w.pendingSince.IsZero() {
+				w.pendingSince = time.Now()
+			}
 			w.debounce = time.AfterFunc(100*time.Millisecond, func() {
 				w.mu.Lock()
 				defer w.mu.Unlock()
 
+				w.pendingSince = time.Time{}
+
 				if w.closed {
 					return
 				}
@@ -396,3 +396,49 @@ func TestWatcher_WatchClosedWatcher(t *testing.T) {
 	// WatchFile on closed watcher should not panic (some error is acceptable)
 	_ = w.WatchFile("/some/path")
 }
+
+func TestWatcher_ContinuousWritesStillSignal(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "app.log")
+
+	if err := os.WriteFile(testFile, []byte(""), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	w, err := NewWatcher()
+	if err != nil {
+		t.Fatalf("NewWatcher() failed: %v", err)
+	}
+	defer w.Stop()
+
+	if err := w.WatchFile(testFile); err != nil {
+		t.Fatalf("WatchFile() failed: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	// Write faster than the debounce interval, like a busy log
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(20 * time.Millisecond):
+				f, err := os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+				if err != nil {
+					return
+				}
+				_, _ = f.WriteString("line\n")
+				_ = f.Close()
+			}
+		}
+	}()
+
+	select {
+	case <-w.Events():
+		// Event arrived while writes continue
+	case <-time.After(2 * time.Second):
+		t.Error("continuous writes postponed the change event")
+	}
+}
@@ -0,0 +1,283 @@
+package gitstatus
+
+import (
+	"fmt"
+	"math/bits"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// BisectTerm is a verdict recorded for a commit during bisect.
+type BisectTerm string
+
+const (
+	BisectGood BisectTerm = "good"
+	BisectBad  BisectTerm = "bad"
+	BisectSkip BisectTerm = "skip"
+)
+
+// BisectState describes an in-progress (or finished) git bisect session.
+type BisectState struct {
+	Active     bool
+	Bad        string          // Hash marked bad (refs/bisect/bad)
+	Good       []string        // Hashes marked good (refs/bisect/good-*)
+	Skipped    []string        // Hashes marked skip (refs/bisect/skip-*)
+	Current    string          // HEAD hash (commit currently being tested)
+	Candidates map[string]bool // Commits still suspected of introducing the regression
+	Culprit    string          // First bad commit, once found
+}
+
+var (
+	bisectProgressRe = regexp.MustCompile(`Bisecting: (\d+) revisions? left to test after this \(roughly (\d+) steps?\)`)
+	bisectCulpritRe  = regexp.MustCompile(`(?m)^([0-9a-f]{7,40}) is the first bad commit`)
+	bisectLogFirstRe = regexp.MustCompile(`(?m)^# first bad commit: \[([0-9a-f]{7,40})\]`)
+)
+
+// Remaining returns the number of suspect commits left.
+func (s *BisectState) Remaining() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.Candidates)
+}
+
+// Steps returns the estimated number of test steps left.
+func (s *BisectState) Steps() int {
+	if s == nil || s.Culprit != "" {
+		return 0
+	}
+	return EstimateBisectSteps(s.Remaining())
+}
+
+// Summary returns a short status label for the sidebar header.
+func (s *BisectState) Summary() string {
+	if s == nil || !s.Active {
+		return ""
+	}
+	if s.Culprit != "" {
+		return "[bisect: found " + shortHash(s.Culprit) + "]"
+	}
+	if s.Bad == "" || len(s.Good) == 0 {
+		var missing []string
+		if s.Bad == "" {
+			missing = append(missing, "bad")
+		}
+		if len(s.Good) == 0 {
+			missing = append(missing, "good")
+		}
+		return "[bisect: need " + strings.Join(missing, "+") + "]"
+	}
+	return fmt.Sprintf("[bisect: %d left, ~%d steps]", s.Remaining(), s.Steps())
+}
+
+// TermFor returns the verdict recorded for hash, or "" if none.
+func (s *BisectState) TermFor(hash string) BisectTerm {
+	if s == nil || !s.Active {
+		return ""
+	}
+	if hash == s.Bad {
+		return BisectBad
+	}
+	for _, h := range s.Good {
+		if h == hash {
+			return BisectGood
+		}
+	}
+	for _, h := range s.Skipped {
+		if h == hash {
+			return BisectSkip
+		}
+	}
+	return ""
+}
+
+// EstimateBisectSteps mirrors git's estimate of the remaining bisect steps
+// for a range of n suspect commits.
+func EstimateBisectSteps(n int) int {
+	if n < 2 {
+		return 0
+	}
+	log := bits.Len(uint(n)) - 1 // floor(log2(n))
+	e := 1 << log
+	x := n - e
+	if e < 3*x {
+		return log
+	}
+	return log - 1
+}
+
+// ParseBisectOutput extracts progress and culprit information from the output
+// of git bisect good/bad/skip/run.
+func ParseBisectOutput(output string) (remaining, steps int, culprit string) {
+	remaining, steps = -1, -1
+	if m := bisectProgressRe.FindAllStringSubmatch(output, -1); len(m) > 0 {
+		last := m[len(m)-1]
+		remaining, _ = strconv.Atoi(last[1])
+		steps, _ = strconv.Atoi(last[2])
+	}
+	if m := bisectCulpritRe.FindStringSubmatch(output); len(m) == 2 {
+		culprit = m[1]
+	}
+	return remaining, steps, culprit
+}
+
+// IsBisecting reports whether a bisect session is in progress.
+func IsBisecting(workDir string) bool {
+	path := gitPath(workDir, "BISECT_LOG")
+	if path == "" {
+		return false
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// GetBisectState reads the current bisect refs, suspect range and culprit.
+func GetBisectState(workDir string) (*BisectState, error) {
+	state := &BisectState{Candidates: make(map[string]bool)}
+	if !IsBisecting(workDir) {
+		return state, nil
+	}
+	state.Active = true
+
+	cmd := exec.Command("git", "for-each-ref", "--format=%(refname)%00%(objectname)", "refs/bisect/")
+	cmd.Dir = workDir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		parts := strings.SplitN(line, "\x00", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		ref, hash := parts[0], parts[1]
+		switch {
+		case ref == "refs/bisect/bad":
+			state.Bad = hash
+		case strings.HasPrefix(ref, "refs/bisect/good-"):
+			state.Good = append(state.Good, hash)
+		case strings.HasPrefix(ref, "refs/bisect/skip-"):
+			state.Skipped = append(state.Skipped, hash)
+		}
+	}
+
+	if out, err := runGit(workDir, "rev-parse", "HEAD"); err == nil {
+		state.Current = strings.TrimSpace(out)
+	}
+
+	if out, err := runGit(workDir, "bisect", "log"); err == nil {
+		if m := bisectLogFirstRe.FindStringSubmatch(out); len(m) == 2 {
+			state.Culprit = m[1]
+		}
+	}
+
+	if state.Culprit != "" {
+		state.Candidates[state.Culprit] = true
+		return state, nil
+	}
+	if state.Bad == "" || len(state.Good) == 0 {
+		return state, nil
+	}
+
+	args := []string{"rev-list", state.Bad, "--not"}
+	args = append(args, state.Good...)
+	out, err := runGit(workDir, args...)
+	if err != nil {
+		return state, nil
+	}
+	skipped := make(map[string]bool, len(state.Skipped))
+	for _, h := range state.Skipped {
+		skipped[h] = true
+	}
+	for _, h := range strings.Fields(out) {
+		if !skipped[h] {
+			state.Candidates[h] = true
+		}
+	}
+	return state, nil
+}
+
+// BisectMark records a verdict for rev, starting a bisect session first if
+// none is active. Returns git's output, which carries progress and culprit info.
+func BisectMark(workDir string, term BisectTerm, rev string) (string, error) {
+	if !IsBisecting(workDir) {
+		if out, err := runGit(workDir, "bisect", "start"); err != nil {
+			return "", &BisectError{Output: out, Err: err}
+		}
+	}
+	args := []string{"bisect", string(term)}
+	if rev != "" {
+		args = append(args, rev)
+	}
+	out, err := runGit(workDir, args...)
+	if err != nil {
+		return "", &BisectError{Output: out, Err: err}
+	}
+	return out, nil
+}
+
+// BisectReset ends the bisect session and returns to the original HEAD.
+func BisectReset(workDir string) error {
+	out, err := runGit(workDir, "bisect", "reset")
+	if err != nil {
+		return &BisectError{Output: out, Err: err}
+	}
+	return nil
+}
+
+// BisectError wraps a git bisect error with its output.
+type BisectError struct {
+	Output string
+	Err    error
+}
+
+func (e *BisectError) Error() string {
+	if msg := strings.TrimSpace(e.Output); msg != "" {
+		return msg
+	}
+	return e.Err.Error()
+}
+
+// BisectRunCommand returns the shell command line that runs
+// `git bisect run` in workDir, executing command through sh.
+func BisectRunCommand(workDir, command string) string {
+	return "cd " + shellQuote(workDir) + " && git bisect run sh -c " + shellQuote(command)
+}
+
+// gitPath resolves a path inside the git directory (handles worktrees).
+func gitPath(workDir, name string) string {
+	out, err := runGit(workDir, "rev-parse", "--git-path", name)
+	if err != nil {
+		return ""
+	}
+	path := strings.TrimSpace(out)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(workDir, path)
+	}
+	return path
+}
+
+// runGit runs a git command in workDir and returns its combined output.
+func runGit(workDir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = workDir
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+// shortHash truncates a hash to 7 characters for display.
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+// shellQuote single-quotes s for sh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
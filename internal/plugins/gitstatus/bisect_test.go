@@ -0,0 +1,298 @@
+package gitstatus
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/app"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+)
+
+func TestEstimateBisectSteps(t *testing.T) {
+	tests := []struct {
+		n    int
+		want int
+	}{
+		{0, 0},
+		{1, 0},
+		{2, 0},
+		{3, 1},
+		{4, 1},
+		{7, 2},
+		{8, 2},
+		{15, 3},
+		{100, 6},
+		{1000, 9},
+	}
+	for _, tt := range tests {
+		if got := EstimateBisectSteps(tt.n); got != tt.want {
+			t.Errorf("EstimateBisectSteps(%d) = %d, want %d", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestParseBisectOutput(t *testing.T) {
+	tests := []struct {
+		name          string
+		output        string
+		wantRemaining int
+		wantSteps     int
+		wantCulprit   string
+	}{
+		{
+			name:          "progress",
+			output:        "Bisecting: 12 revisions left to test after this (roughly 4 steps)\n[abc1234] subject\n",
+			wantRemaining: 12,
+			wantSteps:     4,
+		},
+		{
+			name:          "singular",
+			output:        "Bisecting: 1 revision left to test after this (roughly 1 step)\n",
+			wantRemaining: 1,
+			wantSteps:     1,
+		},
+		{
+			name: "run uses last progress line",
+			output: "Bisecting: 6 revisions left to test after this (roughly 3 steps)\n" +
+				"Bisecting: 2 revisions left to test after this (roughly 1 step)\n",
+			wantRemaining: 2,
+			wantSteps:     1,
+		},
+		{
+			name:          "culprit",
+			output:        "running 'sh' '-c' 'false'\n0123456789abcdef0123456789abcdef01234567 is the first bad commit\ncommit 0123456\n",
+			wantRemaining: -1,
+			wantSteps:     -1,
+			wantCulprit:   "0123456789abcdef0123456789abcdef01234567",
+		},
+		{
+			name:          "empty",
+			output:        "",
+			wantRemaining: -1,
+			wantSteps:     -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remaining, steps, culprit := ParseBisectOutput(tt.output)
+			if remaining != tt.wantRemaining || steps != tt.wantSteps || culprit != tt.wantCulprit {
+				t.Errorf("ParseBisectOutput() = (%d, %d, %q), want (%d, %d, %q)",
+					remaining, steps, culprit, tt.wantRemaining, tt.wantSteps, tt.wantCulprit)
+			}
+		})
+	}
+}
+
+func TestBisectStateSummary(t *testing.T) {
+	tests := []struct {
+		name  string
+		state *BisectState
+		want  string
+	}{
+		{"nil", nil, ""},
+		{"inactive", &BisectState{}, ""},
+		{"needs both", &BisectState{Active: true}, "[bisect: need bad+good]"},
+		{"needs good", &BisectState{Active: true, Bad: "aaaaaaaaaa"}, "[bisect: need good]"},
+		{
+			"in progress",
+			&BisectState{
+				Active: true, Bad: "aaaaaaaaaa", Good: []string{"bbbbbbbbbb"},
+				Candidates: map[string]bool{"1": true, "2": true, "3": true, "4": true},
+			},
+			"[bisect: 4 left, ~1 steps]",
+		},
+		{"found", &BisectState{Active: true, Culprit: "cccccccccc"}, "[bisect: found ccccccc]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.state.Summary(); got != tt.want {
+				t.Errorf("Summary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBisectStateTermFor(t *testing.T) {
+	s := &BisectState{
+		Active:  true,
+		Bad:     "bad",
+		Good:    []string{"good1", "good2"},
+		Skipped: []string{"skip"},
+	}
+	tests := map[string]BisectTerm{
+		"bad":   BisectBad,
+		"good2": BisectGood,
+		"skip":  BisectSkip,
+		"other": "",
+	}
+	for hash, want := range tests {
+		if got := s.TermFor(hash); got != want {
+			t.Errorf("TermFor(%q) = %q, want %q", hash, got, want)
+		}
+	}
+
+	s.Active = false
+	if got := s.TermFor("bad"); got != "" {
+		t.Errorf("TermFor on inactive state = %q, want empty", got)
+	}
+}
+
+// initBisectRepo creates a repo with n commits where commit badAt (1-based)
+// introduces the word "bug" into state.txt. Returns the dir and hashes oldest first.
+func initBisectRepo(t *testing.T, n, badAt int) (string, []string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v (%s)", args, err, strings.TrimSpace(string(out)))
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q")
+
+	var hashes []string
+	for i := 1; i <= n; i++ {
+		content := "ok"
+		if i >= badAt {
+			content = "bug"
+		}
+		if err := os.WriteFile(filepath.Join(dir, "state.txt"), []byte(fmt.Sprintf("%s %d\n", content, i)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		git("add", "state.txt")
+		git("commit", "-q", "-m", fmt.Sprintf("commit %d", i))
+		hashes = append(hashes, git("rev-parse", "HEAD"))
+	}
+	return dir, hashes
+}
+
+func TestBisectMark_FindsCulprit(t *testing.T) {
+	dir, hashes := initBisectRepo(t, 9, 6)
+	culpritWant := hashes[5]
+
+	if IsBisecting(dir) {
+		t.Fatal("IsBisecting() = true before start")
+	}
+	if _, err := BisectMark(dir, BisectBad, hashes[len(hashes)-1]); err != nil {
+		t.Fatalf("mark bad: %v", err)
+	}
+	if !IsBisecting(dir) {
+		t.Fatal("IsBisecting() = false after marking bad")
+	}
+	out, err := BisectMark(dir, BisectGood, hashes[0])
+	if err != nil {
+		t.Fatalf("mark good: %v", err)
+	}
+
+	state, err := GetBisectState(dir)
+	if err != nil {
+		t.Fatalf("GetBisectState: %v", err)
+	}
+	if state.Remaining() != len(hashes)-1 {
+		t.Errorf("Remaining() = %d, want %d", state.Remaining(), len(hashes)-1)
+	}
+
+	var culprit string
+	for i := 0; i < len(hashes) && culprit == ""; i++ {
+		data, err := os.ReadFile(filepath.Join(dir, "state.txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		term := BisectGood
+		if strings.HasPrefix(string(data), "bug") {
+			term = BisectBad
+		}
+		out, err = BisectMark(dir, term, "")
+		if err != nil {
+			t.Fatalf("mark %s: %v", term, err)
+		}
+		_, _, culprit = ParseBisectOutput(out)
+	}
+	if culprit != culpritWant {
+		t.Fatalf("culprit = %q, want %q", culprit, culpritWant)
+	}
+
+	state, err = GetBisectState(dir)
+	if err != nil {
+		t.Fatalf("GetBisectState: %v", err)
+	}
+	if state.Culprit != culpritWant {
+		t.Errorf("state.Culprit = %q, want %q", state.Culprit, culpritWant)
+	}
+
+	if err := BisectReset(dir); err != nil {
+		t.Fatalf("BisectReset: %v", err)
+	}
+	if IsBisecting(dir) {
+		t.Error("IsBisecting() = true after reset")
+	}
+}
+
+func TestBisectRunCommand_FindsCulprit(t *testing.T) {
+	dir, hashes := initBisectRepo(t, 12, 4)
+	if _, err := BisectMark(dir, BisectBad, hashes[len(hashes)-1]); err != nil {
+		t.Fatalf("mark bad: %v", err)
+	}
+	if _, err := BisectMark(dir, BisectGood, hashes[0]); err != nil {
+		t.Fatalf("mark good: %v", err)
+	}
+
+	// The quote in the test command must survive the shell line
+	line := BisectRunCommand(dir, "! grep -q 'bug' state.txt")
+	if out, err := exec.Command("sh", "-c", line).CombinedOutput(); err != nil {
+		t.Fatalf("bisect run: %v\n%s", err, out)
+	}
+
+	state, err := GetBisectState(dir)
+	if err != nil {
+		t.Fatalf("GetBisectState: %v", err)
+	}
+	if state.Culprit != hashes[3] {
+		t.Errorf("culprit = %q, want %q", state.Culprit, hashes[3])
+	}
+}
+
+func TestStartBisectRun_RunsInShell(t *testing.T) {
+	p := &Plugin{repoRoot: "/repo", viewMode: ViewModeBisect, bisectReturnMode: ViewModeStatus}
+	cmd := p.startBisectRun("go test ./...")
+	if p.viewMode != ViewModeStatus || !p.bisectRunPending {
+		t.Fatalf("viewMode = %v, pending = %v", p.viewMode, p.bisectRunPending)
+	}
+	var run *appmsg.RunInShellMsg
+	for _, c := range cmd().(tea.BatchMsg) {
+		if m, ok := c().(appmsg.RunInShellMsg); ok {
+			run = &m
+		}
+	}
+	if run == nil || run.Command != "cd '/repo' && git bisect run sh -c 'go test ./...'" {
+		t.Fatalf("run = %+v", run)
+	}
+
+	// The culprit is announced once the state shows it
+	p.Update(BisectStateLoadedMsg{State: &BisectState{Active: true}})
+	if !p.bisectRunPending {
+		t.Fatal("run still going, pending should stay set")
+	}
+	_, cmd = p.Update(BisectStateLoadedMsg{State: &BisectState{Active: true, Culprit: "abc1234"}})
+	if p.bisectRunPending || cmd == nil {
+		t.Fatal("culprit should clear pending and toast")
+	}
+	if toast, ok := cmd().(app.ToastMsg); !ok || !strings.Contains(toast.Message, "abc1234") {
+		t.Errorf("toast = %+v", toast)
+	}
+}
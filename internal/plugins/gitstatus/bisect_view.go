@@ -0,0 +1,413 @@
+package gitstatus
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/app"
+	"github.com/marcus/sidecar/internal/modal"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/styles"
+	"github.com/marcus/sidecar/internal/ui"
+)
+
+const (
+	bisectActionGood  = "bisect-good"
+	bisectActionBad   = "bisect-bad"
+	bisectActionSkip  = "bisect-skip"
+	bisectActionRun   = "bisect-run"
+	bisectActionReset = "bisect-reset"
+
+	bisectRunInputID    = "bisect-run-command"
+	bisectRunSubmitID   = "bisect-run-submit"
+	bisectModalMinWidth = 30
+)
+
+// openBisect opens the bisect modal targeting the selected commit (or HEAD).
+func (p *Plugin) openBisect() tea.Cmd {
+	p.bisectTarget = nil
+	if p.cursorOnCommit() {
+		p.bisectTarget = p.getCurrentCommit()
+	}
+	p.bisectReturnMode = p.viewMode
+	p.viewMode = ViewModeBisect
+	p.bisectSelectedIdx = 0
+	p.bisectRunInputMode = false
+	p.clearBisectModal()
+	return p.loadBisectState()
+}
+
+// closeBisect closes the bisect modal.
+func (p *Plugin) closeBisect() {
+	p.viewMode = p.bisectReturnMode
+	p.bisectRunInputMode = false
+	p.clearBisectModal()
+}
+
+func (p *Plugin) clearBisectModal() {
+	p.bisectModal = nil
+	p.bisectModalWidth = 0
+}
+
+// bisectTargetRev returns the revision the next verdict applies to.
+func (p *Plugin) bisectTargetRev() string {
+	if p.bisectTarget != nil {
+		return p.bisectTarget.Hash
+	}
+	return "HEAD"
+}
+
+func (p *Plugin) bisectTargetLabel() string {
+	if p.bisectTarget != nil {
+		return p.bisectTarget.ShortHash
+	}
+	if p.bisectState != nil && p.bisectState.Current != "" {
+		return shortHash(p.bisectState.Current) + " (HEAD)"
+	}
+	return "HEAD"
+}
+
+// bisectActions returns the menu items valid for the current bisect state.
+func (p *Plugin) bisectActions() []modal.ListItem {
+	target := p.bisectTargetLabel()
+	items := []modal.ListItem{
+		{ID: bisectActionBad, Label: "Mark " + target + " bad"},
+		{ID: bisectActionGood, Label: "Mark " + target + " good"},
+	}
+	active := p.bisectState != nil && p.bisectState.Active
+	if active {
+		items = append(items, modal.ListItem{ID: bisectActionSkip, Label: "Skip " + target})
+		if p.bisectState.Bad != "" && len(p.bisectState.Good) > 0 && p.bisectState.Culprit == "" {
+			items = append(items, modal.ListItem{ID: bisectActionRun, Label: "Run test command (git bisect run)"})
+		}
+		items = append(items, modal.ListItem{ID: bisectActionReset, Label: "Reset bisect"})
+	}
+	return items
+}
+
+// ensureBisectModal builds/rebuilds the bisect modal.
+func (p *Plugin) ensureBisectModal() {
+	modalW := ui.ModalWidthLarge
+	if modalW > p.width-4 {
+		modalW = p.width - 4
+	}
+	if modalW < bisectModalMinWidth {
+		modalW = bisectModalMinWidth
+	}
+	if p.bisectModal != nil && p.bisectModalWidth == modalW {
+		return
+	}
+	p.bisectModalWidth = modalW
+
+	m := modal.New("Bisect",
+		modal.WithWidth(modalW),
+		modal.WithHints(false),
+	).
+		AddSection(p.bisectStatusSection()).
+		AddSection(modal.Spacer())
+
+	if p.bisectRunInputMode {
+		m = m.AddSection(modal.InputWithLabel(bisectRunInputID, "Test command (exit 0 = good, 125 = skip):", &p.bisectRunInput,
+			modal.WithSubmitAction(bisectRunSubmitID)))
+	} else {
+		items := p.bisectActions()
+		if p.bisectSelectedIdx >= len(items) {
+			p.bisectSelectedIdx = len(items) - 1
+		}
+		m = m.AddSection(modal.List("bisect-options", items, &p.bisectSelectedIdx, modal.WithMaxVisible(len(items))))
+	}
+
+	m = m.AddSection(modal.Spacer()).
+		AddSection(p.bisectHintsSection())
+	p.bisectModal = m
+	if p.bisectRunInputMode {
+		p.bisectModal.SetFocus(bisectRunInputID)
+	}
+}
+
+func (p *Plugin) bisectStatusSection() modal.Section {
+	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
+		s := p.bisectState
+		if s == nil || !s.Active {
+			lines := []string{
+				styles.Muted.Render("No bisect in progress."),
+				styles.Muted.Render("Mark a bad commit and a good commit to start."),
+			}
+			return modal.RenderedSection{Content: strings.Join(lines, "\n")}
+		}
+
+		var lines []string
+		bad := "(not set)"
+		if s.Bad != "" {
+			bad = shortHash(s.Bad)
+		}
+		lines = append(lines, fmt.Sprintf("%s %s   %s %d   %s %d",
+			styles.StatusDeleted.Render("bad"), bad,
+			styles.StatusStaged.Render("good"), len(s.Good),
+			styles.Muted.Render("skipped"), len(s.Skipped)))
+
+		switch {
+		case s.Culprit != "":
+			line := "First bad commit: " + shortHash(s.Culprit)
+			if c := p.findCommit(s.Culprit); c != nil {
+				line += " " + c.Subject
+			}
+			lines = append(lines, styles.StatusDeleted.Render(truncateStr(line, contentWidth)))
+		case s.Bad != "" && len(s.Good) > 0:
+			lines = append(lines, fmt.Sprintf("%d suspect commits, roughly %d steps left", s.Remaining(), s.Steps()))
+			if s.Current != "" {
+				line := "Testing " + shortHash(s.Current)
+				if c := p.findCommit(s.Current); c != nil {
+					line += " " + c.Subject
+				}
+				lines = append(lines, styles.Muted.Render(truncateStr(line, contentWidth)))
+			}
+		default:
+			lines = append(lines, styles.Muted.Render("Waiting for both a good and a bad commit."))
+		}
+		if p.bisectRunPending {
+			lines = append(lines, styles.StatusInProgress.Render(truncateStr("Running in a shell: "+p.bisectRunCommand, contentWidth)))
+		}
+		return modal.RenderedSection{Content: strings.Join(lines, "\n")}
+	}, nil)
+}
+
+func (p *Plugin) bisectHintsSection() modal.Section {
+	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
+		hint := "b bad · g good · s skip · r run · x reset · Esc close"
+		if p.bisectRunInputMode {
+			hint = "Enter to run · Esc to go back"
+		}
+		return modal.RenderedSection{Content: styles.Muted.Render(hint)}
+	}, nil)
+}
+
+// renderBisect renders the bisect modal over the status view.
+func (p *Plugin) renderBisect() string {
+	background := p.renderThreePaneView()
+
+	p.ensureBisectModal()
+	if p.bisectModal == nil {
+		return background
+	}
+
+	modalContent := p.bisectModal.Render(p.width, p.height, p.mouseHandler)
+	return ui.OverlayModal(background, modalContent, p.width, p.height)
+}
+
+// updateBisect handles key events in the bisect modal.
+func (p *Plugin) updateBisect(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
+	p.ensureBisectModal()
+	if p.bisectModal == nil {
+		return p, nil
+	}
+
+	if p.bisectRunInputMode {
+		if msg.String() == "esc" {
+			p.bisectRunInputMode = false
+			p.clearBisectModal()
+			return p, nil
+		}
+		action, cmd := p.bisectModal.HandleKey(msg)
+		if action == bisectRunSubmitID {
+			return p, p.startBisectRun(p.bisectRunInput.Value())
+		}
+		return p, cmd
+	}
+
+	switch msg.String() {
+	case "esc", "q":
+		p.closeBisect()
+		return p, nil
+	case "b":
+		return p.executeBisectAction(bisectActionBad)
+	case "g":
+		return p.executeBisectAction(bisectActionGood)
+	case "s":
+		return p.executeBisectAction(bisectActionSkip)
+	case "r":
+		return p.executeBisectAction(bisectActionRun)
+	case "x":
+		return p.executeBisectAction(bisectActionReset)
+	}
+
+	action, cmd := p.bisectModal.HandleKey(msg)
+	if action == "cancel" {
+		p.closeBisect()
+		return p, nil
+	}
+	if action != "" {
+		return p.executeBisectAction(action)
+	}
+	return p, cmd
+}
+
+// handleBisectMouse processes mouse events in the bisect modal.
+func (p *Plugin) handleBisectMouse(msg tea.MouseMsg) (plugin.Plugin, tea.Cmd) {
+	p.ensureBisectModal()
+	if p.bisectModal == nil {
+		return p, nil
+	}
+	action := p.bisectModal.HandleMouse(msg, p.mouseHandler)
+	switch action {
+	case "":
+		return p, nil
+	case "cancel":
+		p.closeBisect()
+		return p, nil
+	case bisectRunSubmitID:
+		return p, p.startBisectRun(p.bisectRunInput.Value())
+	}
+	return p.executeBisectAction(action)
+}
+
+// executeBisectAction runs a bisect menu action.
+func (p *Plugin) executeBisectAction(action string) (plugin.Plugin, tea.Cmd) {
+	active := p.bisectState != nil && p.bisectState.Active
+	switch action {
+	case bisectActionBad:
+		return p, p.doBisectMark(BisectBad)
+	case bisectActionGood:
+		return p, p.doBisectMark(BisectGood)
+	case bisectActionSkip:
+		if active {
+			return p, p.doBisectMark(BisectSkip)
+		}
+	case bisectActionRun:
+		if active {
+			p.bisectRunInputMode = true
+			p.bisectRunInput = textinput.New()
+			p.bisectRunInput.Placeholder = "go test ./..."
+			p.bisectRunInput.SetValue(p.bisectRunCommand)
+			p.bisectRunInput.Focus()
+			p.clearBisectModal()
+			return p, textinput.Blink
+		}
+	case bisectActionReset:
+		if active {
+			return p, p.doBisectReset()
+		}
+	}
+	return p, nil
+}
+
+// loadBisectState loads bisect refs and the suspect range.
+func (p *Plugin) loadBisectState() tea.Cmd {
+	if p.ctx == nil || p.repoRoot == "" {
+		return nil
+	}
+	epoch, gen := p.ctx.Epoch, p.repoGen
+	workDir := p.repoRoot
+	return func() tea.Msg {
+		state, err := GetBisectState(workDir)
+		if err != nil {
+			return BisectStateLoadedMsg{Epoch: epoch, RepoGen: gen}
+		}
+		return BisectStateLoadedMsg{Epoch: epoch, RepoGen: gen, State: state}
+	}
+}
+
+// doBisectMark records a verdict for the current bisect target.
+func (p *Plugin) doBisectMark(term BisectTerm) tea.Cmd {
+	workDir := p.repoRoot
+	rev := p.bisectTargetRev()
+	// Subsequent verdicts apply to the commit git checks out next
+	p.bisectTarget = nil
+	return func() tea.Msg {
+		output, err := BisectMark(workDir, term, rev)
+		return BisectResultMsg{Output: output, Err: err}
+	}
+}
+
+// doBisectReset ends the bisect session.
+func (p *Plugin) doBisectReset() tea.Cmd {
+	workDir := p.repoRoot
+	return func() tea.Msg {
+		err := BisectReset(workDir)
+		return BisectResultMsg{Reset: true, Err: err}
+	}
+}
+
+// startBisectRun runs `git bisect run` in a new workspace shell, where its
+// output can be followed. The culprit is announced once the bisect state
+// shows it.
+func (p *Plugin) startBisectRun(command string) tea.Cmd {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return nil
+	}
+	p.bisectRunCommand = command
+	p.bisectRunPending = true
+	p.closeBisect()
+	line := BisectRunCommand(p.repoRoot, command)
+	return tea.Batch(
+		app.FocusPlugin("workspace-manager"),
+		func() tea.Msg { return appmsg.RunInShellMsg{Command: line} },
+	)
+}
+
+// bisectCulpritToast announces the first bad commit.
+func (p *Plugin) bisectCulpritToast(hash string) tea.Cmd {
+	label := "First bad commit: " + shortHash(hash)
+	if c := p.findCommit(hash); c != nil {
+		label += " " + truncateStr(c.Subject, 40)
+	}
+	return func() tea.Msg {
+		return app.ToastMsg{Message: label, Duration: 5 * time.Second}
+	}
+}
+
+// findCommit returns the loaded commit matching hash, if any.
+func (p *Plugin) findCommit(hash string) *Commit {
+	for _, c := range p.recentCommits {
+		if c != nil && (c.Hash == hash || strings.HasPrefix(c.Hash, hash)) {
+			return c
+		}
+	}
+	return nil
+}
+
+// bisectIndicator returns the styled and plain two-column marker for a commit
+// in the sidebar while a bisect is active.
+func (p *Plugin) bisectIndicator(hash string) (styled, plain string, ok bool) {
+	s := p.bisectState
+	if s == nil || !s.Active {
+		return "", "", false
+	}
+	switch {
+	case s.Culprit != "" && strings.HasPrefix(hash, s.Culprit):
+		return styles.StatusDeleted.Render("◆") + " ", "◆ ", true
+	case s.TermFor(hash) == BisectBad:
+		return styles.StatusDeleted.Render("✗") + " ", "✗ ", true
+	case s.TermFor(hash) == BisectGood:
+		return styles.StatusStaged.Render("✓") + " ", "✓ ", true
+	case s.TermFor(hash) == BisectSkip:
+		return styles.Muted.Render("~") + " ", "~ ", true
+	case hash == s.Current && s.Culprit == "":
+		return styles.StatusInProgress.Render("▸") + " ", "▸ ", true
+	case s.Candidates[hash] && s.Culprit == "":
+		return styles.StatusModified.Render("?") + " ", "? ", true
+	}
+	return "  ", "  ", true
+}
+
+// BisectStateLoadedMsg is sent when bisect state has been read.
+type BisectStateLoadedMsg struct {
+	Epoch   uint64 // Epoch when request was issued (for stale detection)
+	RepoGen uint64 // Repository generation when issued (see switchRepo)
+	State   *BisectState
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m BisectStateLoadedMsg) GetEpoch() uint64 { return m.Epoch }
+
+// BisectResultMsg is sent when a bisect mark or reset completes.
+type BisectResultMsg struct {
+	Output string
+	Reset  bool
+	Err    error
+}
@@ -66,6 +66,24 @@ func (p *Plugin) updateBranchPicker(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 	case "enter":
 		// Switch to selected branch
 		return p, p.switchSelectedBranch()
+
+	case "D":
+		// Delete selected branch (merged only; confirmed and undoable)
+		if p.branchCursor < len(p.branches) && !p.branches[p.branchCursor].IsCurrent {
+			name := p.branches[p.branchCursor].Name
+			p.openUndoableConfirm(&undoableConfirm{
+				Title:   "Delete Branch",
+				Prompt:  "Delete merged branch:",
+				Target:  name,
+				Warning: undoHint + " recreates it at its current tip.",
+				Button:  "Delete",
+				Run:     p.doDeleteBranch(name),
+			})
+		}
+		return p, nil
+
+	case "alt+z":
+		return p, p.doUndo()
 	}
 
 	action, cmd := p.branchPickerModal.HandleKey(msg)
@@ -217,7 +235,7 @@ func (p *Plugin) branchPickerListUpdate(msg tea.Msg, focusID string) (string, te
 
 func (p *Plugin) branchPickerHintsSection() modal.Section {
 	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
-		return modal.RenderedSection{Content: styles.Muted.Render("  Enter to switch, j/k to navigate, Esc to cancel")}
+		return modal.RenderedSection{Content: styles.Muted.Render("  Enter to switch, D to delete, j/k to navigate, Esc to cancel")}
 	}, nil)
 }
 
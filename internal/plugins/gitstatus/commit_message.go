@@ -0,0 +1,200 @@
+package gitstatus
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/markdown"
+)
+
+const (
+	// commitMessageTimeout bounds how long the generator command may run.
+	commitMessageTimeout = 2 * time.Minute
+	// commitMessageMaxDiff caps the diff bytes sent to the generator.
+	commitMessageMaxDiff = 100 * 1024
+	// commitMessageSubjects is the number of recent subjects included as style examples.
+	commitMessageSubjects = 10
+
+	templateConventional = "conventional"
+)
+
+// conventionalTypes are the commit types accepted by the conventional template.
+var conventionalTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
+var conventionalSubjectRe = regexp.MustCompile(`^(` + strings.Join(conventionalTypes, "|") + `)(\([^()\s]+\))?!?: \S`)
+
+// commitTemplateInstructions returns the instructions for a message template.
+func commitTemplateInstructions(template string) string {
+	switch strings.TrimSpace(template) {
+	case "":
+		return "Write a git commit message for the staged changes below. " +
+			"Match the style of the recent commit subjects. " +
+			"Use a concise subject line (72 characters max), optionally followed by a blank line and a short body. " +
+			"Output only the commit message."
+	case templateConventional:
+		return "Write a git commit message for the staged changes below using the Conventional Commits format: " +
+			"<type>(<optional scope>): <description>. " +
+			"Allowed types: " + strings.Join(conventionalTypes, ", ") + ". " +
+			"Use the imperative mood, keep the subject under 72 characters, and add a short body after a blank line only if needed. " +
+			"Mark breaking changes with ! after the type and a BREAKING CHANGE: footer. " +
+			"Output only the commit message."
+	default:
+		return template
+	}
+}
+
+// BuildCommitMessagePrompt assembles the generator input from template
+// instructions, recent commit subjects and the staged diff.
+func BuildCommitMessagePrompt(template, diff string, recentSubjects []string) string {
+	var sb strings.Builder
+	sb.WriteString(commitTemplateInstructions(template))
+	sb.WriteString("\n")
+
+	if len(recentSubjects) > 0 {
+		sb.WriteString("\nRecent commit subjects:\n")
+		for _, s := range recentSubjects {
+			sb.WriteString("- " + s + "\n")
+		}
+	}
+
+	if len(diff) > commitMessageMaxDiff {
+		diff = headUTF8Safe(diff, commitMessageMaxDiff) + "\n[diff truncated]"
+	}
+	sb.WriteString("\nStaged diff:\n")
+	sb.WriteString(diff)
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// headUTF8Safe returns the first n bytes of s, backing up so a multi-byte
+// character is not split.
+func headUTF8Safe(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// GenerateCommitMessage runs command through sh in workDir with prompt on
+// stdin and returns the cleaned-up message from stdout.
+func GenerateCommitMessage(ctx context.Context, workDir, command, prompt string) (string, error) {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = workDir
+	cmd.Stdin = strings.NewReader(prompt)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("commit message command timed out")
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", errors.New(msg)
+		}
+		return "", err
+	}
+
+	message := CleanCommitMessage(stdout.String())
+	if message == "" {
+		return "", fmt.Errorf("commit message command produced no output")
+	}
+	return message, nil
+}
+
+// CleanCommitMessage strips surrounding whitespace and markdown code fences
+// that chat-style tools tend to wrap their answers in.
+func CleanCommitMessage(output string) string {
+	return markdown.StripCodeFence(output)
+}
+
+// ValidateCommitMessage checks a generated message against the template.
+// Returns a user-facing warning, or "" if the message conforms.
+func ValidateCommitMessage(template, message string) string {
+	if strings.TrimSpace(template) != templateConventional {
+		return ""
+	}
+	subject := strings.SplitN(message, "\n", 2)[0]
+	if !conventionalSubjectRe.MatchString(subject) {
+		return "Subject is not a Conventional Commit (type(scope): description)"
+	}
+	return ""
+}
+
+// commitMessageCommand returns the configured generator command, if any.
+func (p *Plugin) commitMessageCommand() string {
+	if p.ctx == nil || p.ctx.Config == nil {
+		return ""
+	}
+	return strings.TrimSpace(p.ctx.Config.Plugins.GitStatus.CommitMessageCommand)
+}
+
+func (p *Plugin) commitMessageTemplate() string {
+	if p.ctx == nil || p.ctx.Config == nil {
+		return ""
+	}
+	return p.ctx.Config.Plugins.GitStatus.CommitMessageTemplate
+}
+
+// doGenerateCommitMessage pipes the staged diff and recent subjects into the
+// configured command and fills the commit message field with the result.
+func (p *Plugin) doGenerateCommitMessage() tea.Cmd {
+	command := p.commitMessageCommand()
+	if command == "" {
+		p.commitError = "Set plugins.git-status.commitMessageCommand to generate messages"
+		return nil
+	}
+	if p.commitGenerating {
+		return nil
+	}
+	p.commitGenerating = true
+	p.commitError = ""
+	gen := p.commitGen
+
+	workDir := p.repoRoot
+	template := p.commitMessageTemplate()
+	var subjects []string
+	for i, c := range p.recentCommits {
+		if i >= commitMessageSubjects {
+			break
+		}
+		subjects = append(subjects, c.Subject)
+	}
+
+	return func() tea.Msg {
+		diff, err := GetFullDiff(workDir, true)
+		if err != nil {
+			return CommitMessageGeneratedMsg{Gen: gen, Err: err}
+		}
+		if strings.TrimSpace(diff) == "" {
+			return CommitMessageGeneratedMsg{Gen: gen, Err: fmt.Errorf("no staged changes to describe")}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), commitMessageTimeout)
+		defer cancel()
+		message, err := GenerateCommitMessage(ctx, workDir, command, BuildCommitMessagePrompt(template, diff, subjects))
+		if err != nil {
+			return CommitMessageGeneratedMsg{Gen: gen, Err: err}
+		}
+		return CommitMessageGeneratedMsg{Gen: gen, Message: message, Warning: ValidateCommitMessage(template, message)}
+	}
+}
+
+// CommitMessageGeneratedMsg is sent when the generator command finishes.
+type CommitMessageGeneratedMsg struct {
+	Gen     int // commitGen when the request was made
+	Message string
+	Warning string // Template mismatch warning (message is still used)
+	Err     error
+}
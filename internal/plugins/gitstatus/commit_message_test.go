@@ -0,0 +1,205 @@
+package gitstatus
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/marcus/sidecar/internal/config"
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+func TestBuildCommitMessagePrompt(t *testing.T) {
+	prompt := BuildCommitMessagePrompt("conventional", "diff --git a/x b/x\n+hello", []string{"feat: add x", "fix: y"})
+
+	for _, want := range []string{"Conventional Commits", "- feat: add x", "- fix: y", "Staged diff:\ndiff --git a/x b/x\n+hello"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+
+	custom := BuildCommitMessagePrompt("Write it in haiku.", "d", nil)
+	if !strings.HasPrefix(custom, "Write it in haiku.\n") {
+		t.Errorf("custom template not used verbatim:\n%s", custom)
+	}
+	if strings.Contains(custom, "Recent commit subjects") {
+		t.Error("expected no subjects section when none given")
+	}
+
+	long := BuildCommitMessagePrompt("", strings.Repeat("x", commitMessageMaxDiff+10), nil)
+	if !strings.Contains(long, "[diff truncated]") {
+		t.Error("expected oversized diff to be truncated")
+	}
+
+	// The cut backs up rather than splitting a multi-byte character
+	wide := BuildCommitMessagePrompt("", "x"+strings.Repeat("é", commitMessageMaxDiff), nil)
+	if !utf8.ValidString(wide) {
+		t.Error("truncated diff split a UTF-8 character")
+	}
+}
+
+func TestCleanCommitMessage(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"  fix: thing\n\n", "fix: thing"},
+		{"```\nfeat: add\n\nbody\n```", "feat: add\n\nbody"},
+		{"```text\nchore: bump\n```\n", "chore: bump"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := CleanCommitMessage(tt.in); got != tt.want {
+			t.Errorf("CleanCommitMessage(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestValidateCommitMessage(t *testing.T) {
+	tests := []struct {
+		template string
+		message  string
+		wantWarn bool
+	}{
+		{"conventional", "feat: add search", false},
+		{"conventional", "fix(parser): handle empty input\n\nbody", false},
+		{"conventional", "refactor!: drop v1 api", false},
+		{"conventional", "Add search", true},
+		{"conventional", "feature: add search", true},
+		{"conventional", "feat:missing space", true},
+		{"", "Add search", false},
+	}
+	for _, tt := range tests {
+		got := ValidateCommitMessage(tt.template, tt.message)
+		if (got != "") != tt.wantWarn {
+			t.Errorf("ValidateCommitMessage(%q, %q) = %q, wantWarn %v", tt.template, tt.message, got, tt.wantWarn)
+		}
+	}
+}
+
+func TestGenerateCommitMessage_FakeCommand(t *testing.T) {
+	dir := t.TempDir()
+	// Fake generator: records its stdin and answers with a fenced message
+	command := `cat > prompt.txt; printf '%s\n' '` + "```" + `' 'feat: generated' '` + "```" + `'`
+
+	msg, err := GenerateCommitMessage(context.Background(), dir, command, "the prompt")
+	if err != nil {
+		t.Fatalf("GenerateCommitMessage: %v", err)
+	}
+	if msg != "feat: generated" {
+		t.Errorf("message = %q, want %q", msg, "feat: generated")
+	}
+	if got := readFile(t, dir, "prompt.txt"); got != "the prompt" {
+		t.Errorf("stdin = %q, want prompt", got)
+	}
+}
+
+func TestGenerateCommitMessage_Errors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := GenerateCommitMessage(context.Background(), dir, "echo boom >&2; exit 3", ""); err == nil || err.Error() != "boom" {
+		t.Errorf("err = %v, want stderr text", err)
+	}
+	if _, err := GenerateCommitMessage(context.Background(), dir, "true", ""); err == nil {
+		t.Error("expected error for empty output")
+	}
+}
+
+func TestDoGenerateCommitMessage(t *testing.T) {
+	dir, git := initUndoRepo(t)
+	writeFile(t, dir, "a.txt", "one\ntwo\n")
+	git("add", "a.txt")
+
+	script := filepath.Join(t.TempDir(), "gen.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ncat > \"$OUT\"\necho 'Update a.txt'\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	promptFile := filepath.Join(t.TempDir(), "prompt.txt")
+	t.Setenv("OUT", promptFile)
+
+	cfg := config.Default()
+	cfg.Plugins.GitStatus.CommitMessageCommand = script
+	cfg.Plugins.GitStatus.CommitMessageTemplate = "conventional"
+
+	p := New()
+	if err := p.Init(&plugin.Context{WorkDir: dir, Config: cfg}); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	p.recentCommits = []*Commit{{Subject: "first"}}
+	p.viewMode = ViewModeCommit
+	p.initCommitTextarea()
+
+	cmd := p.doGenerateCommitMessage()
+	if cmd == nil {
+		t.Fatal("expected a command")
+	}
+	if !p.commitGenerating {
+		t.Error("expected commitGenerating to be set")
+	}
+	msg, ok := cmd().(CommitMessageGeneratedMsg)
+	if !ok {
+		t.Fatalf("unexpected message type %T", msg)
+	}
+	if msg.Err != nil {
+		t.Fatalf("generate: %v", msg.Err)
+	}
+	if msg.Message != "Update a.txt" {
+		t.Errorf("Message = %q", msg.Message)
+	}
+	if msg.Warning == "" {
+		t.Error("expected conventional-commit warning for non-conforming subject")
+	}
+
+	prompt, err := os.ReadFile(promptFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"- first", "+two"} {
+		if !strings.Contains(string(prompt), want) {
+			t.Errorf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+
+	p.Update(msg)
+	if p.commitGenerating {
+		t.Error("commitGenerating should be cleared")
+	}
+	if got := p.commitMessage.Value(); got != "Update a.txt" {
+		t.Errorf("commit message field = %q", got)
+	}
+}
+
+func TestCommitMessageGenerated_StaleModal(t *testing.T) {
+	p := New()
+	p.ctx = &plugin.Context{Config: config.Default()}
+	p.viewMode = ViewModeCommit
+	p.initCommitTextarea()
+	p.commitGenerating = true
+	stale := CommitMessageGeneratedMsg{Gen: p.commitGen, Message: "old"}
+
+	// Closing and reopening the modal makes the running generation stale
+	p.initCommitTextarea()
+	p.commitMessage.SetValue("typed")
+	p.Update(stale)
+	if got := p.commitMessage.Value(); got != "typed" {
+		t.Errorf("commit message field = %q, want stale result dropped", got)
+	}
+
+	p.Update(CommitMessageGeneratedMsg{Gen: p.commitGen, Message: "new"})
+	if got := p.commitMessage.Value(); got != "new" {
+		t.Errorf("commit message field = %q, want current result", got)
+	}
+}
+
+func TestDoGenerateCommitMessage_NotConfigured(t *testing.T) {
+	p := New()
+	p.ctx = &plugin.Context{Config: config.Default()}
+	if cmd := p.doGenerateCommitMessage(); cmd != nil {
+		t.Error("expected nil command when no generator is configured")
+	}
+	if p.commitError == "" {
+		t.Error("expected a hint about configuring the command")
+	}
+}
@@ -165,6 +165,10 @@ func (p *Plugin) commitStatusSection() modal.Section {
 				progressText = "Amending..."
 			}
 			lines = append(lines, styles.Muted.Render(progressText))
+		} else if p.commitGenerating {
+			lines = append(lines, styles.Muted.Render("Generating message..."))
+		} else if len(lines) == 0 && p.commitMessageCommand() != "" {
+			lines = append(lines, styles.Muted.Render("ctrl+g to generate message"))
 		}
 		return modal.RenderedSection{Content: strings.Join(lines, "\n")}
 	}, nil)
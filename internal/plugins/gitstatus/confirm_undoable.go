@@ -0,0 +1,143 @@
+package gitstatus
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/modal"
+	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/styles"
+	"github.com/marcus/sidecar/internal/ui"
+)
+
+// undoableConfirm is a one-key destructive action (hard reset, branch
+// delete) waiting for confirmation. It can be undone, but still asks first.
+type undoableConfirm struct {
+	Title      string   // Modal title
+	Prompt     string   // e.g. "Hard reset HEAD to:"
+	Target     string   // Commit or branch the action applies to
+	Warning    string   // What happens, and how to undo it
+	Button     string   // Confirm button label
+	Run        tea.Cmd  // Performs the action
+	ReturnMode ViewMode // Mode to return to when the modal closes
+}
+
+// openUndoableConfirm asks before running c.Run.
+func (p *Plugin) openUndoableConfirm(c *undoableConfirm) {
+	c.ReturnMode = p.viewMode
+	p.undoableConfirm = c
+	p.undoableModal = nil
+	p.viewMode = ViewModeConfirmUndoable
+}
+
+// buildUndoableModal creates the confirmation modal.
+func (p *Plugin) buildUndoableModal() {
+	c := p.undoableConfirm
+	if c == nil {
+		p.undoableModal = nil
+		return
+	}
+
+	modalWidth := 50
+	if len(c.Target) > 35 {
+		modalWidth = len(c.Target) + 15
+	}
+	if modalWidth > p.width-10 {
+		modalWidth = p.width - 10
+	}
+
+	p.undoableModal = modal.New(c.Title,
+		modal.WithVariant(modal.VariantDanger),
+		modal.WithWidth(modalWidth),
+	).
+		AddSection(modal.Text(c.Prompt)).
+		AddSection(modal.Text(styles.Subtitle.Render(c.Target))).
+		AddSection(modal.Spacer()).
+		AddSection(modal.Text(styles.Muted.Render(c.Warning))).
+		AddSection(modal.Spacer()).
+		AddSection(modal.Buttons(
+			modal.Btn(" "+c.Button+" ", "confirm", modal.BtnDanger()),
+			modal.Btn(" Cancel ", "cancel"),
+		))
+}
+
+// renderConfirmUndoable renders the confirmation over the view it came from.
+func (p *Plugin) renderConfirmUndoable() string {
+	var background string
+	switch {
+	case p.undoableConfirm == nil:
+		return p.renderThreePaneView()
+	case p.undoableConfirm.ReturnMode == ViewModeReflog:
+		background = p.renderReflog()
+	case p.undoableConfirm.ReturnMode == ViewModeBranchPicker:
+		background = p.renderBranchPicker()
+	default:
+		background = p.renderThreePaneView()
+	}
+
+	if p.undoableModal == nil {
+		p.buildUndoableModal()
+	}
+	modalContent := p.undoableModal.Render(p.width, p.height, p.mouseHandler)
+	return ui.OverlayModal(background, modalContent, p.width, p.height)
+}
+
+// updateConfirmUndoable handles key events in the confirmation modal.
+func (p *Plugin) updateConfirmUndoable(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
+	if p.undoableModal == nil {
+		p.buildUndoableModal()
+	}
+	if p.undoableModal == nil {
+		return p, nil
+	}
+
+	// Quick confirm shortcut
+	switch msg.String() {
+	case "y", "Y":
+		return p.confirmUndoable()
+	}
+
+	action, cmd := p.undoableModal.HandleKey(msg)
+	switch action {
+	case "confirm":
+		return p.confirmUndoable()
+	case "cancel":
+		return p.cancelUndoable()
+	}
+	return p, cmd
+}
+
+// handleUndoableMouse handles mouse events for the confirmation modal.
+func (p *Plugin) handleUndoableMouse(msg tea.MouseMsg) (plugin.Plugin, tea.Cmd) {
+	if p.undoableModal == nil {
+		return p, nil
+	}
+	switch p.undoableModal.HandleMouse(msg, p.mouseHandler) {
+	case "confirm":
+		return p.confirmUndoable()
+	case "cancel":
+		return p.cancelUndoable()
+	}
+	return p, nil
+}
+
+// confirmUndoable runs the action and returns to the previous view.
+func (p *Plugin) confirmUndoable() (plugin.Plugin, tea.Cmd) {
+	var cmd tea.Cmd
+	if p.undoableConfirm != nil {
+		cmd = p.undoableConfirm.Run
+	}
+	return p.closeUndoable(cmd)
+}
+
+// cancelUndoable closes the modal without running the action.
+func (p *Plugin) cancelUndoable() (plugin.Plugin, tea.Cmd) {
+	return p.closeUndoable(nil)
+}
+
+func (p *Plugin) closeUndoable(cmd tea.Cmd) (plugin.Plugin, tea.Cmd) {
+	if p.undoableConfirm != nil {
+		p.viewMode = p.undoableConfirm.ReturnMode
+	}
+	p.undoableConfirm = nil
+	p.undoableModal = nil
+	return p, cmd
+}
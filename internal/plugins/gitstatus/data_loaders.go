@@ -5,59 +5,70 @@ import (
 )
 
 // loadDiff loads the diff for a file.
-func (p *Plugin) loadDiff(path string, staged bool, status FileStatus) tea.Cmd {
+func (p *Plugin) loadDiff(entry *FileEntry) tea.Cmd {
 	epoch := p.ctx.Epoch
+	gen := p.repoGen
 	workDir := p.repoRoot
+	path, staged, status, submodule := entry.Path, entry.Staged, entry.Status, entry.Submodule != nil
 	return func() tea.Msg {
 		var rawDiff string
 		var err error
 
 		// Untracked files need special handling - create new file diff
-		if status == StatusUntracked {
+		switch {
+		case status == StatusUntracked:
 			rawDiff, err = GetNewFileDiff(workDir, path)
-		} else {
+		case submodule:
+			rawDiff, err = GetSubmoduleDiff(workDir, path, staged)
+		default:
 			rawDiff, err = GetDiff(workDir, path, staged)
 		}
 		if err != nil {
 			return ErrorMsg{Err: err}
 		}
 
-		return DiffLoadedMsg{Epoch: epoch, Content: rawDiff, Raw: rawDiff}
+		return DiffLoadedMsg{Epoch: epoch, RepoGen: gen, Content: rawDiff, Raw: rawDiff}
 	}
 }
 
 // loadInlineDiff loads a diff for inline preview in the three-pane view.
-func (p *Plugin) loadInlineDiff(path string, staged bool, status FileStatus) tea.Cmd {
+func (p *Plugin) loadInlineDiff(entry *FileEntry) tea.Cmd {
 	epoch := p.ctx.Epoch
+	gen := p.repoGen
 	workDir := p.repoRoot
+	path, staged, status, submodule := entry.Path, entry.Staged, entry.Status, entry.Submodule != nil
 	return func() tea.Msg {
 		var rawDiff string
 		var err error
 
 		// Untracked files need special handling - create new file diff
-		if status == StatusUntracked {
+		switch {
+		case status == StatusUntracked:
 			rawDiff, err = GetNewFileDiff(workDir, path)
-		} else {
+		case submodule:
+			rawDiff, err = GetSubmoduleDiff(workDir, path, staged)
+		default:
 			rawDiff, err = GetDiff(workDir, path, staged)
 		}
 		if err != nil {
-			return InlineDiffLoadedMsg{Epoch: epoch, File: path, Raw: "", Parsed: nil}
+			return InlineDiffLoadedMsg{Epoch: epoch, RepoGen: gen, File: path, Raw: "", Parsed: nil}
 		}
 		parsed, _ := ParseUnifiedDiff(rawDiff)
-		return InlineDiffLoadedMsg{Epoch: epoch, File: path, Raw: rawDiff, Parsed: parsed}
+		return InlineDiffLoadedMsg{Epoch: epoch, RepoGen: gen, File: path, Raw: rawDiff, Parsed: parsed}
 	}
 }
 
 // loadRecentCommits loads recent commits for the sidebar with push status.
 func (p *Plugin) loadRecentCommits() tea.Cmd {
 	epoch := p.ctx.Epoch
+	gen := p.repoGen
 	workDir := p.repoRoot
 	return func() tea.Msg {
 		commits, pushStatus, err := GetCommitHistoryWithPushStatus(workDir, commitHistoryPageSize)
 		if err != nil {
-			return RecentCommitsLoadedMsg{Epoch: epoch, Commits: nil, PushStatus: nil}
+			return RecentCommitsLoadedMsg{Epoch: epoch, RepoGen: gen, Commits: nil, PushStatus: nil}
 		}
-		return RecentCommitsLoadedMsg{Epoch: epoch, Commits: commits, PushStatus: pushStatus}
+		return RecentCommitsLoadedMsg{Epoch: epoch, RepoGen: gen, Commits: commits, PushStatus: pushStatus}
 	}
 }
 
@@ -69,14 +80,15 @@ func (p *Plugin) loadMoreCommits() tea.Cmd {
 	p.loadingMoreCommits = true
 
 	epoch := p.ctx.Epoch
+	gen := p.repoGen
 	workDir := p.repoRoot
 	skip := len(p.recentCommits)
 	return func() tea.Msg {
 		commits, pushStatus, err := GetCommitHistoryWithPushStatusOffset(workDir, commitHistoryPageSize, skip)
 		if err != nil {
-			return MoreCommitsLoadedMsg{Epoch: epoch, Commits: nil, PushStatus: nil}
+			return MoreCommitsLoadedMsg{Epoch: epoch, RepoGen: gen, Commits: nil, PushStatus: nil}
 		}
-		return MoreCommitsLoadedMsg{Epoch: epoch, Commits: commits, PushStatus: pushStatus}
+		return MoreCommitsLoadedMsg{Epoch: epoch, RepoGen: gen, Commits: commits, PushStatus: pushStatus}
 	}
 }
 
@@ -84,6 +96,7 @@ func (p *Plugin) loadMoreCommits() tea.Cmd {
 // loadFilteredCommits fetches commits with current filter options.
 func (p *Plugin) loadFilteredCommits() tea.Cmd {
 	epoch := p.ctx.Epoch
+	gen := p.repoGen
 	workDir := p.repoRoot
 	opts := HistoryFilterOpts{
 		Author: p.historyFilterAuthor,
@@ -93,31 +106,33 @@ func (p *Plugin) loadFilteredCommits() tea.Cmd {
 	return func() tea.Msg {
 		commits, pushStatus, err := GetCommitHistoryFilteredWithPushStatus(workDir, opts)
 		if err != nil {
-			return FilteredCommitsLoadedMsg{Epoch: epoch, Commits: nil, PushStatus: nil}
+			return FilteredCommitsLoadedMsg{Epoch: epoch, RepoGen: gen, Commits: nil, PushStatus: nil}
 		}
-		return FilteredCommitsLoadedMsg{Epoch: epoch, Commits: commits, PushStatus: pushStatus}
+		return FilteredCommitsLoadedMsg{Epoch: epoch, RepoGen: gen, Commits: commits, PushStatus: pushStatus}
 	}
 }
 
 // loadFolderDiff loads a concatenated diff for all files in a folder.
 func (p *Plugin) loadFolderDiff(entry *FileEntry) tea.Cmd {
 	epoch := p.ctx.Epoch
+	gen := p.repoGen
 	workDir := p.repoRoot
 	folderPath := entry.Path
 	children := entry.Children
 	return func() tea.Msg {
 		rawDiff, err := GetFolderDiff(workDir, children)
 		if err != nil {
-			return InlineDiffLoadedMsg{Epoch: epoch, File: folderPath, Raw: "", Parsed: nil}
+			return InlineDiffLoadedMsg{Epoch: epoch, RepoGen: gen, File: folderPath, Raw: "", Parsed: nil}
 		}
 		parsed, _ := ParseUnifiedDiff(rawDiff)
-		return InlineDiffLoadedMsg{Epoch: epoch, File: folderPath, Raw: rawDiff, Parsed: parsed}
+		return InlineDiffLoadedMsg{Epoch: epoch, RepoGen: gen, File: folderPath, Raw: rawDiff, Parsed: parsed}
 	}
 }
 
 // loadFullFolderDiff loads a concatenated diff for full-screen view.
 func (p *Plugin) loadFullFolderDiff(entry *FileEntry) tea.Cmd {
 	epoch := p.ctx.Epoch
+	gen := p.repoGen
 	workDir := p.repoRoot
 	children := entry.Children
 	return func() tea.Msg {
@@ -126,7 +141,7 @@ func (p *Plugin) loadFullFolderDiff(entry *FileEntry) tea.Cmd {
 			return ErrorMsg{Err: err}
 		}
 
-		return DiffLoadedMsg{Epoch: epoch, Content: rawDiff, Raw: rawDiff}
+		return DiffLoadedMsg{Epoch: epoch, RepoGen: gen, Content: rawDiff, Raw: rawDiff}
 	}
 }
 
@@ -134,6 +149,7 @@ func (p *Plugin) loadFullFolderDiff(entry *FileEntry) tea.Cmd {
 // parentHash should be the first parent hash for merge commits, or "" for regular commits.
 func (p *Plugin) loadCommitFileDiff(hash, path, parentHash string) tea.Cmd {
 	epoch := p.ctx.Epoch
+	gen := p.repoGen
 	workDir := p.repoRoot
 	return func() tea.Msg {
 		rawDiff, err := GetCommitDiff(workDir, hash, path, parentHash)
@@ -141,7 +157,7 @@ func (p *Plugin) loadCommitFileDiff(hash, path, parentHash string) tea.Cmd {
 			return ErrorMsg{Err: err}
 		}
 
-		return DiffLoadedMsg{Epoch: epoch, Content: rawDiff, Raw: rawDiff}
+		return DiffLoadedMsg{Epoch: epoch, RepoGen: gen, Content: rawDiff, Raw: rawDiff}
 	}
 }
 
@@ -149,12 +165,13 @@ func (p *Plugin) loadCommitFileDiff(hash, path, parentHash string) tea.Cmd {
 // loadCommitDetailForPreview loads commit detail for inline preview.
 func (p *Plugin) loadCommitDetailForPreview(hash string) tea.Cmd {
 	epoch := p.ctx.Epoch
+	gen := p.repoGen
 	workDir := p.repoRoot
 	return func() tea.Msg {
 		commit, err := GetCommitDetail(workDir, hash)
 		if err != nil {
 			return ErrorMsg{Err: err}
 		}
-		return CommitPreviewLoadedMsg{Epoch: epoch, Commit: commit}
+		return CommitPreviewLoadedMsg{Epoch: epoch, RepoGen: gen, Commit: commit}
 	}
 }
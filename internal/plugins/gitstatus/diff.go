@@ -10,7 +10,17 @@ import (
 
 // GetDiff returns the diff for a file.
 func GetDiff(workDir, path string, staged bool) (string, error) {
-	args := []string{"diff"}
+	return getDiff(workDir, path, staged)
+}
+
+// GetSubmoduleDiff returns the diff for a submodule path, showing the
+// submodule's own changes instead of just its pointer.
+func GetSubmoduleDiff(workDir, path string, staged bool) (string, error) {
+	return getDiff(workDir, path, staged, "--submodule=diff")
+}
+
+func getDiff(workDir, path string, staged bool, flags ...string) (string, error) {
+	args := append([]string{"diff"}, flags...)
 	if staged {
 		args = append(args, "--cached")
 	}
@@ -32,6 +42,24 @@ func GetDiff(workDir, path string, staged bool) (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+// GetDiffFromRef returns the diff of a file's working tree content against
+// ref (a commit, branch or HEAD), covering both staged and unstaged changes.
+func GetDiffFromRef(workDir, path, ref string) (string, error) {
+	cmd := exec.Command("git", "diff", ref, "--", path)
+	cmd.Dir = workDir
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			if exitErr.ExitCode() == 1 {
+				return string(output), nil
+			}
+		}
+		return "", err
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
 // GetFullDiff returns the diff for all changes.
 func GetFullDiff(workDir string, staged bool) (string, error) {
 	args := []string{"diff"}
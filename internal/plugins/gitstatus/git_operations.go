@@ -69,11 +69,39 @@ func (p *Plugin) doPushSetUpstream() tea.Cmd {
 	}
 }
 
+// doPushToRemote pushes to a specific remote. idx follows the push menu:
+// 0=push (sets upstream for new branches), 1=force, 2=push & set upstream.
+func (p *Plugin) doPushToRemote(remote string, idx int) tea.Cmd {
+	workDir := p.repoRoot
+	setUpstream := idx == 2 || (idx == 0 && p.pushStatus != nil && !p.pushStatus.HasUpstream)
+	force := idx == 1
+	return func() tea.Msg {
+		output, err := ExecutePushToRemote(workDir, remote, force, setUpstream)
+		if err != nil {
+			return PushErrorMsg{Err: err}
+		}
+		return PushSuccessMsg{Output: output}
+	}
+}
+
 // canPush returns true if there are commits that can be pushed.
 func (p *Plugin) canPush() bool {
 	return p.pushStatus != nil && p.pushStatus.CanPush()
 }
 
+// canPushTo returns true if the current branch can be pushed to remote.
+// Ahead/behind is only known for the upstream's remote; any other remote
+// is left for git to compare.
+func (p *Plugin) canPushTo(remote string) bool {
+	if p.pushStatus == nil || p.pushStatus.DetachedHead || p.currentBranch() == "" {
+		return false
+	}
+	if remote == "" || remote == p.upstreamRemoteName() {
+		return p.canPush()
+	}
+	return true
+}
+
 // doStashPush stashes all current changes.
 func (p *Plugin) doStashPush() tea.Cmd {
 	workDir := p.repoRoot
@@ -161,6 +189,32 @@ func (p *Plugin) doPullAutostash() tea.Cmd {
 	}
 }
 
+// doPullFromRemote pulls the current branch from a specific remote using
+// the strategy of the given pull menu option.
+func (p *Plugin) doPullFromRemote(remote, actionID string) tea.Cmd {
+	workDir := p.repoRoot
+	branch := p.currentBranch()
+	var flags []string
+	strategy := "merge"
+	switch actionID {
+	case pullMenuOptionRebase:
+		flags, strategy = []string{"--rebase"}, "rebase"
+	case pullMenuOptionFFOnly:
+		flags, strategy = []string{"--ff-only"}, "ff-only"
+	case pullMenuOptionAutostash:
+		flags, strategy = []string{"--rebase", "--autostash"}, "autostash"
+	default:
+		flags = []string{"--no-rebase"}
+	}
+	return func() tea.Msg {
+		output, err := ExecutePullFromRemote(workDir, remote, branch, flags...)
+		if err != nil {
+			return PullErrorMsg{Err: err, Strategy: strategy}
+		}
+		return PullSuccessMsg{Output: output}
+	}
+}
+
 // doAbortPull aborts the current merge or rebase.
 func (p *Plugin) doAbortPull() tea.Cmd {
 	workDir := p.repoRoot
@@ -187,24 +241,26 @@ func (p *Plugin) canPull() bool {
 	return HasRemote(p.repoRoot)
 }
 
+// canPullFrom returns true if the current branch can be pulled from remote.
+// Without an upstream there is nothing to pull from the default target.
+func (p *Plugin) canPullFrom(remote string) bool {
+	if p.pushStatus == nil || p.pushStatus.DetachedHead {
+		return false
+	}
+	if remote == "" || remote == p.upstreamRemoteName() {
+		return p.pushStatus.HasUpstream
+	}
+	return p.currentBranch() != ""
+}
+
 // doDiscard executes the git discard operation.
 func (p *Plugin) doDiscard(entry *FileEntry) tea.Cmd {
-	workDir := p.repoRoot
+	workDir, gen := p.repoRoot, p.repoGen
 	return func() tea.Msg {
-		var err error
-		if entry.Status == StatusUntracked {
-			// Remove untracked file
-			err = DiscardUntracked(workDir, entry.Path)
-		} else if entry.Staged {
-			// Unstage and restore staged file
-			err = DiscardStaged(workDir, entry.Path)
-		} else {
-			// Restore modified file
-			err = DiscardModified(workDir, entry.Path)
-		}
-		if err != nil {
+		// Snapshot into the undo journal first so the discard can be reverted
+		if err := DiscardWithSnapshot(workDir, entry); err != nil {
 			return ErrorMsg{Err: err}
 		}
-		return RefreshDoneMsg{}
+		return RefreshDoneMsg{RepoGen: gen}
 	}
 }
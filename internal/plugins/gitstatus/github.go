@@ -32,32 +32,123 @@ func GetRemoteURL(workDir string) string {
 // ParseGitHubInfo extracts owner/repo from a GitHub remote URL.
 // Returns nil if not a GitHub URL.
 func ParseGitHubInfo(remoteURL string) *GitHubInfo {
+	remoteURL = strings.TrimSpace(remoteURL)
 	if !strings.Contains(remoteURL, "github.com") {
 		return nil
 	}
 
-	// Handle SSH: git@github.com:owner/repo.git
-	if strings.HasPrefix(remoteURL, "git@github.com:") {
-		path := strings.TrimPrefix(remoteURL, "git@github.com:")
-		path = strings.TrimSuffix(path, ".git")
-		parts := strings.SplitN(path, "/", 2)
-		if len(parts) == 2 {
-			return &GitHubInfo{Owner: parts[0], Repo: parts[1]}
+	var path string
+	switch {
+	case strings.HasPrefix(remoteURL, "git@github.com:"):
+		// SSH: git@github.com:owner/repo.git
+		path = strings.TrimPrefix(remoteURL, "git@github.com:")
+	case strings.Contains(remoteURL, "github.com/"):
+		// HTTPS (optionally with credentials) or ssh://git@github.com/owner/repo.git
+		idx := strings.Index(remoteURL, "github.com/")
+		path = remoteURL[idx+len("github.com/"):]
+	default:
+		return nil
+	}
+
+	path = strings.TrimSuffix(strings.TrimSuffix(path, "/"), ".git")
+	parts := strings.Split(path, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil
+	}
+	return &GitHubInfo{Owner: parts[0], Repo: parts[1]}
+}
+
+// SameRepo reports whether two infos refer to the same GitHub repository.
+func (g *GitHubInfo) SameRepo(other *GitHubInfo) bool {
+	if g == nil || other == nil {
+		return false
+	}
+	return strings.EqualFold(g.Owner, other.Owner) && strings.EqualFold(g.Repo, other.Repo)
+}
+
+// GitHubRemotes maps remote names to their GitHub repositories, skipping
+// non-GitHub remotes.
+func GitHubRemotes(remotes []*Remote) map[string]*GitHubInfo {
+	infos := make(map[string]*GitHubInfo)
+	for _, r := range remotes {
+		if info := ParseGitHubInfo(r.FetchURL); info != nil {
+			infos[r.Name] = info
 		}
 	}
+	return infos
+}
 
-	// Handle HTTPS: https://github.com/owner/repo.git
-	if strings.Contains(remoteURL, "github.com/") {
-		idx := strings.Index(remoteURL, "github.com/")
-		path := remoteURL[idx+len("github.com/"):]
-		path = strings.TrimSuffix(path, ".git")
-		parts := strings.SplitN(path, "/", 2)
-		if len(parts) == 2 {
-			return &GitHubInfo{Owner: parts[0], Repo: parts[1]}
+// githubRemotePreference orders remotes for commit links. In a fork setup
+// "upstream" is the canonical repo, so links there survive fork deletion.
+func githubRemotePreference(names []string, trackingRemote string) []string {
+	ordered := make([]string, 0, len(names))
+	seen := make(map[string]bool)
+	add := func(name string) {
+		for _, n := range names {
+			if n == name && !seen[name] {
+				seen[name] = true
+				ordered = append(ordered, name)
+			}
 		}
 	}
+	add("upstream")
+	add(trackingRemote)
+	add("origin")
+	for _, n := range names {
+		add(n)
+	}
+	return ordered
+}
 
-	return nil
+// ResolveCommitGitHubInfo picks the GitHub repository that hosts a commit.
+// With fork/upstream pairs it prefers a remote whose tracking branches
+// contain the commit (upstream first), falling back to the current branch's
+// remote and then origin. Returns nil if no remote is on GitHub.
+func ResolveCommitGitHubInfo(workDir, hash string) *GitHubInfo {
+	remotes, err := GetRemotes(workDir)
+	if err != nil || len(remotes) == 0 {
+		return nil
+	}
+	infos := GitHubRemotes(remotes)
+	if len(infos) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(infos))
+	for _, r := range remotes {
+		if infos[r.Name] != nil {
+			names = append(names, r.Name)
+		}
+	}
+
+	tracking := ""
+	if out, err := runGit(workDir, "branch", "--show-current"); err == nil {
+		tracking = GetUpstreamRemote(workDir, strings.TrimSpace(out))
+	}
+	ordered := githubRemotePreference(names, tracking)
+
+	// Remotes whose remote-tracking branches contain the commit
+	containing := make(map[string]bool)
+	if out, err := runGit(workDir, "branch", "-r", "--contains", hash, "--format=%(refname:short)"); err == nil {
+		for _, ref := range strings.Fields(out) {
+			if remote, _, ok := strings.Cut(ref, "/"); ok {
+				containing[remote] = true
+			}
+		}
+	}
+	for _, name := range ordered {
+		if containing[name] {
+			return infos[name]
+		}
+	}
+
+	// Unpushed commit: link where it will most likely land
+	if tracking != "" && infos[tracking] != nil {
+		return infos[tracking]
+	}
+	if info := infos["origin"]; info != nil {
+		return info
+	}
+	return infos[ordered[0]]
 }
 
 // BuildCommitURL constructs a GitHub commit URL.
@@ -93,12 +184,11 @@ func (p *Plugin) openCommitInGitHub() tea.Cmd {
 		return nil
 	}
 
-	remoteURL := GetRemoteURL(p.repoRoot)
-	if remoteURL == "" {
+	if !HasRemote(p.repoRoot) {
 		return msg.ShowToast("No remote configured", 2*time.Second)
 	}
 
-	ghInfo := ParseGitHubInfo(remoteURL)
+	ghInfo := ResolveCommitGitHubInfo(p.repoRoot, commit.Hash)
 	if ghInfo == nil {
 		return msg.ShowToast("Not a GitHub repository", 2*time.Second)
 	}
@@ -0,0 +1,225 @@
+package gitstatus
+
+import "strings"
+
+// maxDiffEdits bounds the Myers search. Inputs needing more edits than this
+// are shown as one replacement rather than spending quadratic time.
+const maxDiffEdits = 2000
+
+// diffOp is one step of an edit script.
+type diffOp struct {
+	kind LineType // LineContext, LineRemove or LineAdd
+	a, b int      // Index into the old and new lines
+}
+
+// DiffText computes a line diff of two texts in-process, for content that
+// isn't tracked by git. The result has unified hunks with the given lines
+// of context, like `git diff -U<context>`, so the diff renderers can show it.
+func DiffText(oldName, newName, oldText, newText string, context int) *ParsedDiff {
+	a, b := splitDiffLines(oldText), splitDiffLines(newText)
+	diff := &ParsedDiff{OldFile: oldName, NewFile: newName}
+	diff.Hunks = buildHunks(a, b, diffLines(a, b), context)
+	for i := range diff.Hunks {
+		computeWordDiffs(&diff.Hunks[i])
+	}
+	return diff
+}
+
+// splitDiffLines splits text into lines, ignoring the final newline.
+func splitDiffLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines returns the edit script turning a into b.
+func diffLines(a, b []string) []diffOp {
+	// Intern lines so comparisons are integer compares
+	ids := make(map[string]int)
+	intern := func(lines []string) []int {
+		out := make([]int, len(lines))
+		for i, l := range lines {
+			id, ok := ids[l]
+			if !ok {
+				id = len(ids)
+				ids[l] = id
+			}
+			out[i] = id
+		}
+		return out
+	}
+	x, y := intern(a), intern(b)
+
+	// Common prefix and suffix need no search
+	pre := 0
+	for pre < len(x) && pre < len(y) && x[pre] == y[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(x)-pre && suf < len(y)-pre && x[len(x)-1-suf] == y[len(y)-1-suf] {
+		suf++
+	}
+
+	var ops []diffOp
+	for i := 0; i < pre; i++ {
+		ops = append(ops, diffOp{LineContext, i, i})
+	}
+	mid := myers(x[pre:len(x)-suf], y[pre:len(y)-suf])
+	if mid == nil {
+		// Too different: replace the whole middle
+		for i := pre; i < len(x)-suf; i++ {
+			ops = append(ops, diffOp{LineRemove, i, pre})
+		}
+		for j := pre; j < len(y)-suf; j++ {
+			ops = append(ops, diffOp{LineAdd, len(x) - suf, j})
+		}
+	} else {
+		for _, op := range mid {
+			ops = append(ops, diffOp{op.kind, op.a + pre, op.b + pre})
+		}
+	}
+	for i := 0; i < suf; i++ {
+		ops = append(ops, diffOp{LineContext, len(x) - suf + i, len(y) - suf + i})
+	}
+	return ops
+}
+
+// myers finds a shortest edit script with Myers' O(ND) algorithm. It
+// returns nil when more than maxDiffEdits edits would be needed.
+func myers(a, b []int) []diffOp {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return []diffOp{}
+	}
+	limit := min(n+m, maxDiffEdits)
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+	// trace[d] holds v[-d-1..d+1] as it was before step d
+	var trace [][]int
+
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // Down: insertion
+			} else {
+				x = v[offset+k-1] + 1 // Right: deletion
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, n, m)
+			}
+		}
+	}
+	return nil
+}
+
+// backtrack walks the saved frontiers from the end back to the start,
+// emitting the edit script in order.
+func backtrack(trace [][]int, n, m int) []diffOp {
+	var rev []diffOp
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		frontier := trace[d]
+		at := func(k int) int { return frontier[k+d+1] }
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			rev = append(rev, diffOp{LineContext, x, y})
+		}
+		if d > 0 {
+			if x == prevX {
+				rev = append(rev, diffOp{LineAdd, x, prevY})
+			} else {
+				rev = append(rev, diffOp{LineRemove, prevX, y})
+			}
+		}
+		x, y = prevX, prevY
+	}
+	ops := make([]diffOp, len(rev))
+	for i, op := range rev {
+		ops[len(rev)-1-i] = op
+	}
+	return ops
+}
+
+// buildHunks groups an edit script into hunks with context lines around
+// each change, merging changes whose context would overlap.
+func buildHunks(a, b []string, ops []diffOp, context int) []Hunk {
+	var hunks []Hunk
+	for i := 0; i < len(ops); {
+		if ops[i].kind == LineContext {
+			i++
+			continue
+		}
+		// Extend over changes separated by at most 2*context equal lines
+		start := max(0, i-context)
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != LineContext {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == LineContext {
+				run++
+			}
+			if run == len(ops) || run-end > 2*context {
+				end = min(end+context, len(ops))
+				break
+			}
+			end = run
+		}
+		hunks = append(hunks, makeHunk(a, b, ops[start:end]))
+		i = end
+	}
+	return hunks
+}
+
+// makeHunk converts a slice of the edit script into a hunk with 1-based
+// line numbers. An empty side starts at the line before it, as git does.
+func makeHunk(a, b []string, ops []diffOp) Hunk {
+	h := Hunk{OldStart: -1, NewStart: -1}
+	for _, op := range ops {
+		switch op.kind {
+		case LineContext:
+			h.Lines = append(h.Lines, DiffLine{Type: LineContext, OldLineNo: op.a + 1, NewLineNo: op.b + 1, Content: a[op.a]})
+			h.OldCount++
+			h.NewCount++
+		case LineRemove:
+			h.Lines = append(h.Lines, DiffLine{Type: LineRemove, OldLineNo: op.a + 1, Content: a[op.a]})
+			h.OldCount++
+		case LineAdd:
+			h.Lines = append(h.Lines, DiffLine{Type: LineAdd, NewLineNo: op.b + 1, Content: b[op.b]})
+			h.NewCount++
+		}
+		if h.OldStart < 0 && op.kind != LineAdd {
+			h.OldStart = op.a + 1
+		}
+		if h.NewStart < 0 && op.kind != LineRemove {
+			h.NewStart = op.b + 1
+		}
+	}
+	if h.OldStart < 0 {
+		// Pure insertion: position after the old line preceding it
+		h.OldStart = ops[0].a
+	}
+	if h.NewStart < 0 {
+		h.NewStart = ops[0].b
+	}
+	return h
+}
@@ -0,0 +1,86 @@
+package gitstatus
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// applyHunks rebuilds the new text from the old one and a diff's hunks.
+func applyHunks(old []string, hunks []Hunk) []string {
+	var out []string
+	next := 0 // Next old line index to copy
+	for _, h := range hunks {
+		start := h.OldStart - 1
+		if h.OldCount == 0 {
+			start = h.OldStart
+		}
+		out = append(out, old[next:start]...)
+		next = start
+		for _, l := range h.Lines {
+			switch l.Type {
+			case LineContext:
+				out = append(out, old[next])
+				next++
+			case LineRemove:
+				next++
+			case LineAdd:
+				out = append(out, l.Content)
+			}
+		}
+	}
+	return append(out, old[next:]...)
+}
+
+func TestDiffText(t *testing.T) {
+	oldText := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	newText := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\n"
+	diff := DiffText("old", "new", oldText, newText, 1)
+	if len(diff.Hunks) != 2 {
+		t.Fatalf("hunks = %d, want 2 (changes are far apart)", len(diff.Hunks))
+	}
+	h := diff.Hunks[0]
+	if h.OldStart != 1 || h.OldCount != 3 || h.NewStart != 1 || h.NewCount != 3 {
+		t.Errorf("first hunk = -%d,%d +%d,%d, want -1,3 +1,3", h.OldStart, h.OldCount, h.NewStart, h.NewCount)
+	}
+	if h.Lines[1].Type != LineRemove || h.Lines[2].Type != LineAdd || h.Lines[2].Content != "B" {
+		t.Errorf("first hunk lines = %+v", h.Lines)
+	}
+	if h := diff.Hunks[1]; h.OldStart != 10 || h.OldCount != 1 || h.NewStart != 10 || h.NewCount != 2 {
+		t.Errorf("second hunk = -%d,%d +%d,%d, want -10,1 +10,2", h.OldStart, h.OldCount, h.NewStart, h.NewCount)
+	}
+
+	// With enough context the two changes merge
+	if merged := DiffText("old", "new", oldText, newText, 4); len(merged.Hunks) != 1 {
+		t.Errorf("merged hunks = %d, want 1", len(merged.Hunks))
+	}
+	if same := DiffText("a", "b", oldText, oldText, 3); len(same.Hunks) != 0 {
+		t.Errorf("identical texts produced %d hunks", len(same.Hunks))
+	}
+
+	// Pure insertion into an empty file starts at line 0, like git
+	if ins := DiffText("a", "b", "", "x\ny\n", 3); len(ins.Hunks) != 1 || ins.Hunks[0].OldStart != 0 || ins.Hunks[0].NewStart != 1 {
+		t.Errorf("insert into empty = %+v", ins.Hunks)
+	}
+}
+
+func TestDiffTextRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	words := []string{"a", "b", "c", "d", ""}
+	gen := func() []string {
+		lines := make([]string, rng.Intn(40))
+		for i := range lines {
+			lines[i] = words[rng.Intn(len(words))]
+		}
+		return lines
+	}
+	for i := 0; i < 200; i++ {
+		a, b := gen(), gen()
+		diff := DiffText("a", "b", strings.Join(a, "\n")+"\n", strings.Join(b, "\n")+"\n", 2)
+		got := applyHunks(splitDiffLines(strings.Join(a, "\n")+"\n"), diff.Hunks)
+		want := splitDiffLines(strings.Join(b, "\n") + "\n")
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Fatalf("case %d: applying the diff gave %q, want %q", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package gitstatus
+
+import (
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxHistoryFiles bounds how many changed files are snapshotted per refresh,
+// so a huge untracked tree doesn't stall the background command.
+const maxHistoryFiles = 500
+
+// snapshotChangedFiles records the working tree content of changed files in
+// the local history, so edits made between commits can be recovered from
+// the file browser's timeline. Unchanged files are skipped cheaply.
+func (p *Plugin) snapshotChangedFiles() tea.Cmd {
+	if p.history == nil || p.tree == nil {
+		return nil
+	}
+	paths := p.tree.workingPaths(maxHistoryFiles)
+	if len(paths) == 0 {
+		return nil
+	}
+	store, root := p.history, p.tree.workDir
+	return func() tea.Msg {
+		abs := make([]string, len(paths))
+		for i, path := range paths {
+			abs[i] = filepath.Join(root, path)
+		}
+		store.SnapshotAll(abs)
+		return nil
+	}
+}
@@ -176,7 +176,7 @@ func (p *Plugin) handleMouseDoubleClick(action mouse.MouseAction) (*Plugin, tea.
 				if entry.IsFolder {
 					return p, p.loadFullFolderDiff(entry)
 				}
-				return p, p.loadDiff(entry.Path, entry.Staged, entry.Status)
+				return p, p.loadDiff(entry)
 			}
 		}
 		return p, nil
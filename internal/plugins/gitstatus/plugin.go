@@ -9,13 +9,15 @@ import (
 	"time"
 
 	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/marcus/sidecar/internal/app"
+	"github.com/marcus/sidecar/internal/localhistory"
 	"github.com/marcus/sidecar/internal/modal"
 	"github.com/marcus/sidecar/internal/mouse"
+	appmsg "github.com/marcus/sidecar/internal/msg"
 	"github.com/marcus/sidecar/internal/plugin"
-	"github.com/marcus/sidecar/internal/plugins/filebrowser"
 	"github.com/marcus/sidecar/internal/state"
 	"github.com/marcus/sidecar/internal/styles"
 	"github.com/marcus/sidecar/internal/ui"
@@ -41,6 +43,11 @@ const (
 	ViewModeConfirmStashPop                 // Confirm stash pop modal
 	ViewModePullConflict                    // Pull conflict resolution modal
 	ViewModeError                           // Generic error modal for git operation failures
+	ViewModeBisect                          // Bisect control modal
+	ViewModeReflog                          // Reflog browser modal
+	ViewModeRemotes                         // Remotes management modal
+	ViewModeSubmodules                      // Submodules modal
+	ViewModeConfirmUndoable                 // Confirm hard reset or branch delete modal
 )
 
 // FocusPane represents which pane is active in the three-pane view.
@@ -139,10 +146,15 @@ type Plugin struct {
 	watcher     *Watcher
 	lastRefresh time.Time // Debounce rapid refreshes
 
+	// Local history of changed files, nil when disabled
+	history *localhistory.Store
+
 	// Commit state
 	commitMessage         textarea.Model
 	commitError           string
 	commitInProgress      bool
+	commitGenerating      bool // true while the message generator command runs
+	commitGen             int  // bumped each time the commit modal opens; drops stale generated messages
 	commitAmend           bool // true when amending last commit
 	commitButtonFocus     bool // true when button is focused instead of textarea
 	commitButtonHover     bool // true when mouse is hovering over button
@@ -165,6 +177,10 @@ type Plugin struct {
 	discardReturnMode ViewMode     // Mode to return to when modal closes
 	discardModal      *modal.Modal // Modal instance for discard confirmation
 
+	// Hard reset / branch delete confirm state
+	undoableConfirm *undoableConfirm // Action awaiting confirmation
+	undoableModal   *modal.Modal     // Modal instance for the confirmation
+
 	// Stash pop confirm state
 	stashPopItem  *Stash       // Stash being confirmed for pop
 	stashPopModal *modal.Modal // Modal instance for stash pop confirmation
@@ -206,6 +222,50 @@ type Plugin struct {
 	showCommitGraph  bool        // True when graph column is displayed
 	commitGraphLines []GraphLine // Cached graph computation
 
+	// Bisect state
+	bisectState        *BisectState // Current bisect refs and suspect range
+	bisectTarget       *Commit      // Commit the next verdict applies to (nil = HEAD)
+	bisectReturnMode   ViewMode     // Mode to return to when bisect modal closes
+	bisectModal        *modal.Modal
+	bisectModalWidth   int
+	bisectSelectedIdx  int
+	bisectRunInput     textinput.Model // Test command input for git bisect run
+	bisectRunInputMode bool            // True while editing the test command
+	bisectRunCommand   string          // Last command passed to git bisect run
+	bisectRunPending   bool            // git bisect run started in a shell, culprit not yet seen
+
+	// Reflog browser state
+	reflogEntries    []*ReflogEntry
+	reflogRef        string   // Ref being browsed (HEAD or current branch)
+	reflogCursor     int      // Selected entry
+	reflogReturnMode ViewMode // Mode to return to when reflog closes
+	reflogModal      *modal.Modal
+	reflogModalWidth int
+
+	// Remotes pane state
+	remotes             []*Remote
+	remotesCursor       int      // Selected remote
+	remotesReturnMode   ViewMode // Mode to return to when remotes pane closes
+	remotesModal        *modal.Modal
+	remotesModalWidth   int
+	remoteInputMode     string          // "", "add", "rename" or "set-url"
+	remoteNameInput     textinput.Model // Remote name input (add/rename)
+	remoteURLInput      textinput.Model // Remote URL input (add/set-url)
+	remoteConfirmRemove string          // Remote awaiting a second d to confirm removal
+	pushRemote          string          // Remote chosen in the push menu ("" = default)
+	pullRemote          string          // Remote chosen in the pull menu ("" = upstream)
+
+	// Submodule state
+	submodules           []*Submodule
+	submoduleCursor      int      // Selected submodule
+	submodulesReturnMode ViewMode // Mode to return to when submodules modal closes
+	submodulesModal      *modal.Modal
+	submodulesModalWidth int
+	submoduleBusy        string   // Submodule being updated ("" when idle)
+	repoStack            []string // Parent repo roots while inside a submodule
+	repoGen              uint64   // Bumped by switchRepo; loads stamped with an older value are dropped
+	restoreCursorPath    string   // Entry to put the cursor on once the switched-to tree loads
+
 	// Truncation cache to eliminate ANSI parser allocation churn
 	truncateCache *ui.TruncateCache
 }
@@ -279,6 +339,7 @@ func (p *Plugin) Init(ctx *plugin.Context) error {
 	// Set up context and repo
 	p.ctx = ctx
 	p.tree = NewFileTree(ctx.WorkDir)
+	p.history = localhistory.OpenFromConfig(ctx.WorkDir, ctx.Config)
 
 	// Load user preferences from state
 	if state.GetGitDiffMode() == "side-by-side" {
@@ -315,6 +376,7 @@ func (p *Plugin) Start() tea.Cmd {
 		p.refresh(),
 		p.startWatcher(),
 		p.loadRecentCommits(),
+		p.loadBisectState(),
 	)
 }
 
@@ -360,6 +422,16 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 			return p.updateBranchPicker(msg)
 		case ViewModeError:
 			return p.updateErrorModal(msg)
+		case ViewModeBisect:
+			return p.updateBisect(msg)
+		case ViewModeReflog:
+			return p.updateReflog(msg)
+		case ViewModeRemotes:
+			return p.updateRemotes(msg)
+		case ViewModeSubmodules:
+			return p.updateSubmodules(msg)
+		case ViewModeConfirmUndoable:
+			return p.updateConfirmUndoable(msg)
 		}
 
 	case tea.MouseMsg:
@@ -388,6 +460,16 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 			return p.handleStashPopMouse(msg)
 		case ViewModeError:
 			return p.handleErrorModalMouse(msg)
+		case ViewModeBisect:
+			return p.handleBisectMouse(msg)
+		case ViewModeReflog:
+			return p.handleReflogMouse(msg)
+		case ViewModeRemotes:
+			return p.handleRemotesMouse(msg)
+		case ViewModeSubmodules:
+			return p.handleSubmodulesMouse(msg)
+		case ViewModeConfirmUndoable:
+			return p.handleUndoableMouse(msg)
 		}
 
 	case app.RefreshMsg:
@@ -402,10 +484,10 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		}
 		// Refresh data when navigating to this plugin
 		p.lastRefresh = time.Now()
-		return p, tea.Batch(p.refresh(), p.loadRecentCommits())
+		return p, tea.Batch(p.refresh(), p.loadRecentCommits(), p.loadBisectState())
 
 	case WatchStartedMsg:
-		if p.inNoRepoMode() {
+		if p.inNoRepoMode() || msg.RepoGen != p.repoGen {
 			if msg.Watcher != nil {
 				msg.Watcher.Stop()
 			}
@@ -423,12 +505,33 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 			return p, p.listenForWatchEvents() // Skip refresh, keep listening
 		}
 		p.lastRefresh = time.Now()
-		return p, tea.Batch(p.refresh(), p.loadRecentCommits(), p.listenForWatchEvents())
+		return p, tea.Batch(p.refresh(), p.loadRecentCommits(), p.loadBisectState(), p.listenForWatchEvents())
 
-	case RefreshDoneMsg:
+	case appmsg.FilesChangedMsg:
+		// The file browser rewrote files (e.g. project replace); refresh
+		// immediately rather than waiting on the watcher
 		if p.inNoRepoMode() {
 			return p, nil
 		}
+		p.lastRefresh = time.Now()
+		return p, p.refresh()
+
+	case appmsg.ShowFileDiffMsg:
+		return p, p.showFileDiff(msg.Path)
+
+	case RefreshDoneMsg:
+		if p.inNoRepoMode() || msg.RepoGen != p.repoGen {
+			return p, nil // Ignore a refresh of the repository that was left
+		}
+		if path := p.restoreCursorPath; path != "" {
+			p.restoreCursorPath = ""
+			for i, e := range p.tree.AllEntries() {
+				if e.Path == path {
+					p.cursor = i
+					break
+				}
+			}
+		}
 		// Clamp cursor to valid range if files changed
 		maxCursor := p.totalSelectableItems() - 1
 		if maxCursor < 0 {
@@ -437,14 +540,15 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		if p.cursor > maxCursor {
 			p.cursor = maxCursor
 		}
+		snapshot := p.snapshotChangedFiles()
 		// Auto-load preview for current cursor position after refresh
 		if p.viewMode == ViewModeStatus {
-			return p, p.autoLoadPreview(true)
+			return p, tea.Batch(p.autoLoadPreview(true), snapshot)
 		}
-		return p, nil
+		return p, snapshot
 
 	case DiffLoadedMsg:
-		if plugin.IsStale(p.ctx, msg) {
+		if plugin.IsStale(p.ctx, msg) || msg.RepoGen != p.repoGen {
 			return p, nil // Ignore stale message from previous project
 		}
 		p.diffContent = msg.Content
@@ -470,8 +574,21 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		p.commitInProgress = false
 		return p, nil
 
+	case CommitMessageGeneratedMsg:
+		if msg.Gen != p.commitGen || p.viewMode != ViewModeCommit {
+			return p, nil // Modal was closed (and maybe reopened) while generating
+		}
+		p.commitGenerating = false
+		if msg.Err != nil {
+			p.commitError = "Generate failed: " + msg.Err.Error()
+			return p, nil
+		}
+		p.commitMessage.SetValue(msg.Message)
+		p.commitError = msg.Warning
+		return p, nil
+
 	case InlineDiffLoadedMsg:
-		if plugin.IsStale(p.ctx, msg) {
+		if plugin.IsStale(p.ctx, msg) || msg.RepoGen != p.repoGen {
 			return p, nil // Ignore stale message from previous project
 		}
 		// Only update if this is still the selected file
@@ -492,7 +609,7 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		return p, nil
 
 	case RecentCommitsLoadedMsg:
-		if plugin.IsStale(p.ctx, msg) {
+		if plugin.IsStale(p.ctx, msg) || msg.RepoGen != p.repoGen {
 			return p, nil // Ignore stale message from previous project
 		}
 		if msg.Commits == nil {
@@ -544,7 +661,7 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		return p, p.ensureCommitListFilled()
 
 	case MoreCommitsLoadedMsg:
-		if plugin.IsStale(p.ctx, msg) {
+		if plugin.IsStale(p.ctx, msg) || msg.RepoGen != p.repoGen {
 			return p, nil // Ignore stale message from previous project
 		}
 		p.loadingMoreCommits = false
@@ -564,7 +681,7 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		return p, nil
 
 	case FilteredCommitsLoadedMsg:
-		if plugin.IsStale(p.ctx, msg) {
+		if plugin.IsStale(p.ctx, msg) || msg.RepoGen != p.repoGen {
 			return p, nil // Ignore stale message from previous project
 		}
 		if msg.Commits != nil {
@@ -606,7 +723,7 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		return p, nil
 
 	case CommitPreviewLoadedMsg:
-		if plugin.IsStale(p.ctx, msg) {
+		if plugin.IsStale(p.ctx, msg) || msg.RepoGen != p.repoGen {
 			return p, nil // Ignore stale message from previous project
 		}
 		// Commit preview loaded for right pane (in status view)
@@ -815,6 +932,130 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 			},
 		)
 
+	case BisectStateLoadedMsg:
+		if plugin.IsStale(p.ctx, msg) || msg.RepoGen != p.repoGen {
+			return p, nil
+		}
+		p.bisectState = msg.State
+		if p.viewMode == ViewModeBisect && !p.bisectRunInputMode {
+			p.clearBisectModal()
+		}
+		// A run in the workspace shell finished; announce what it found
+		if p.bisectRunPending && (msg.State == nil || !msg.State.Active || msg.State.Culprit != "") {
+			p.bisectRunPending = false
+			if msg.State != nil && msg.State.Culprit != "" {
+				return p, p.bisectCulpritToast(msg.State.Culprit)
+			}
+		}
+		return p, nil
+
+	case BisectResultMsg:
+		if msg.Err != nil {
+			p.showErrorModal("Bisect Failed", msg.Err)
+			return p, p.loadBisectState()
+		}
+		cmds := []tea.Cmd{p.refresh(), p.loadRecentCommits(), p.loadBisectState()}
+		if msg.Reset {
+			p.bisectRunPending = false
+			cmds = append(cmds, func() tea.Msg {
+				return app.ToastMsg{Message: "Bisect reset", Duration: 2 * time.Second}
+			})
+		} else if _, _, culprit := ParseBisectOutput(msg.Output); culprit != "" {
+			cmds = append(cmds, p.bisectCulpritToast(culprit))
+		}
+		return p, tea.Batch(cmds...)
+
+	case ReflogLoadedMsg:
+		if plugin.IsStale(p.ctx, msg) || msg.Ref != p.reflogRef {
+			return p, nil
+		}
+		if msg.Err != nil {
+			p.reflogEntries = []*ReflogEntry{}
+		} else {
+			p.reflogEntries = msg.Entries
+		}
+		if p.reflogCursor >= len(p.reflogEntries) {
+			p.reflogCursor = 0
+		}
+		return p, nil
+
+	case RemotesLoadedMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		p.remotes = msg.Remotes
+		if p.remotes == nil {
+			p.remotes = []*Remote{}
+		}
+		if p.remotesCursor >= len(p.remotes) {
+			p.remotesCursor = 0
+		}
+		p.clearRemotesModal()
+		return p, nil
+
+	case RemoteOpDoneMsg:
+		if msg.Err != nil {
+			p.showErrorModal("Remote Operation Failed", msg.Err)
+			return p, nil
+		}
+		p.remoteInputMode = ""
+		p.clearRemotesModal()
+		cmds := []tea.Cmd{p.loadRemotes(), p.refresh()}
+		if msg.Message != "" {
+			cmds = append(cmds, remoteOpToast(msg.Message))
+		}
+		return p, tea.Batch(cmds...)
+
+	case SubmodulesLoadedMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		p.submodules = msg.Submodules
+		if p.submodules == nil {
+			p.submodules = []*Submodule{}
+		}
+		if p.submoduleCursor >= len(p.submodules) {
+			p.submoduleCursor = 0
+		}
+		if msg.Err != nil {
+			p.showErrorModal("Submodules Failed", msg.Err)
+		}
+		return p, nil
+
+	case SubmoduleOpDoneMsg:
+		p.submoduleBusy = ""
+		if msg.Err != nil {
+			p.showErrorModal("Submodule Operation Failed", msg.Err)
+			return p, nil
+		}
+		cmds := []tea.Cmd{p.refresh()}
+		if p.viewMode == ViewModeSubmodules {
+			cmds = append(cmds, p.loadSubmodules())
+		}
+		if msg.Message != "" {
+			cmds = append(cmds, remoteOpToast(msg.Message))
+		}
+		return p, tea.Batch(cmds...)
+
+	case UndoableOpDoneMsg:
+		if msg.Err != nil {
+			p.showErrorModal("Operation Failed", msg.Err)
+			return p, nil
+		}
+		return p, p.undoDoneCmds(msg.Message)
+
+	case UndoDoneMsg:
+		if msg.Err != nil {
+			p.showErrorModal("Undo Failed", msg.Err)
+			return p, nil
+		}
+		if msg.Op == nil {
+			return p, func() tea.Msg {
+				return app.ToastMsg{Message: "Nothing to undo", Duration: 2 * time.Second}
+			}
+		}
+		return p, p.undoDoneCmds("Undid " + msg.Op.Description())
+
 	case StashPopConfirmMsg:
 		// Show stash pop confirmation modal
 		p.stashPopItem = msg.Stash
@@ -833,8 +1074,9 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 
 // CommitPreviewLoadedMsg is sent when commit preview is loaded.
 type CommitPreviewLoadedMsg struct {
-	Epoch  uint64 // Epoch when request was issued (for stale detection)
-	Commit *Commit
+	Epoch   uint64 // Epoch when request was issued (for stale detection)
+	RepoGen uint64 // Repository generation when issued (see switchRepo)
+	Commit  *Commit
 }
 
 // GetEpoch implements plugin.EpochMessage.
@@ -889,6 +1131,16 @@ func (p *Plugin) View(width, height int) string {
 			content = p.renderBranchPicker()
 		case ViewModeError:
 			content = p.renderErrorModal()
+		case ViewModeBisect:
+			content = p.renderBisect()
+		case ViewModeReflog:
+			content = p.renderReflog()
+		case ViewModeRemotes:
+			content = p.renderRemotes()
+		case ViewModeSubmodules:
+			content = p.renderSubmodules()
+		case ViewModeConfirmUndoable:
+			content = p.renderConfirmUndoable()
 		default:
 			// Use three-pane layout for status view
 			content = p.renderThreePaneView()
@@ -942,6 +1194,12 @@ func (p *Plugin) Commands() []plugin.Command {
 		{ID: "stash-apply", Name: "Apply", Description: "Apply latest stash", Category: plugin.CategoryGit, Context: "git-status", Priority: 4},
 		{ID: "open-in-file-browser", Name: "Browse", Description: "Open file in file browser", Category: plugin.CategoryNavigation, Context: "git-status", Priority: 4},
 		{ID: "open-in-github", Name: "GitHub", Description: "Open commit in GitHub", Category: plugin.CategoryActions, Context: "git-status", Priority: 4},
+		{ID: "bisect", Name: "Bisect", Description: "Bisect to find the commit that introduced a bug", Category: plugin.CategoryGit, Context: "git-status", Priority: 5},
+		{ID: "reflog", Name: "Reflog", Description: "Browse HEAD and branch movements", Category: plugin.CategoryGit, Context: "git-status", Priority: 5},
+		{ID: "undo", Name: "Undo", Description: "Undo last discard, hard reset or branch delete", Category: plugin.CategoryGit, Context: "git-status", Priority: 4},
+		{ID: "remotes", Name: "Remotes", Description: "Manage remotes and upstream tracking", Category: plugin.CategoryGit, Context: "git-status", Priority: 5},
+		{ID: "submodules", Name: "Submodules", Description: "List, init and update submodules", Category: plugin.CategoryGit, Context: "git-status", Priority: 5},
+		{ID: "leave-submodule", Name: "Parent", Description: "Return to the parent repository", Category: plugin.CategoryNavigation, Context: "git-status", Priority: 5},
 		{ID: "toggle-sidebar", Name: "Sidebar", Description: "Toggle sidebar visibility", Category: plugin.CategoryView, Context: "git-status", Priority: 5},
 		// git-status-commits context (recent commits in sidebar)
 		{ID: "view-commit", Name: "View", Description: "View commit details", Category: plugin.CategoryView, Context: "git-status-commits", Priority: 1},
@@ -958,6 +1216,10 @@ func (p *Plugin) Commands() []plugin.Command {
 		{ID: "yank-id", Name: "YankID", Description: "Copy commit ID", Category: plugin.CategoryActions, Context: "git-status-commits", Priority: 3},
 		{ID: "open-in-github", Name: "GitHub", Description: "Open commit in GitHub", Category: plugin.CategoryActions, Context: "git-status-commits", Priority: 3},
 		{ID: "toggle-graph", Name: "Graph", Description: "Toggle commit graph display", Category: plugin.CategoryView, Context: "git-status-commits", Priority: 2},
+		{ID: "bisect", Name: "Bisect", Description: "Mark commit good/bad for bisect", Category: plugin.CategoryGit, Context: "git-status-commits", Priority: 3},
+		{ID: "remotes", Name: "Remotes", Description: "Manage remotes and upstream tracking", Category: plugin.CategoryGit, Context: "git-status-commits", Priority: 4},
+		{ID: "submodules", Name: "Submodules", Description: "List, init and update submodules", Category: plugin.CategoryGit, Context: "git-status-commits", Priority: 5},
+		{ID: "leave-submodule", Name: "Parent", Description: "Return to the parent repository", Category: plugin.CategoryNavigation, Context: "git-status-commits", Priority: 5},
 		{ID: "toggle-sidebar", Name: "Sidebar", Description: "Toggle sidebar visibility", Category: plugin.CategoryView, Context: "git-status-commits", Priority: 5},
 		// git-history-search context (commit search modal)
 		{ID: "select", Name: "Select", Description: "Jump to selected match", Category: plugin.CategoryActions, Context: "git-history-search", Priority: 1},
@@ -990,6 +1252,7 @@ func (p *Plugin) Commands() []plugin.Command {
 		// git-commit context
 		{ID: "execute-commit", Name: "Commit", Description: "Create commit with message", Category: plugin.CategoryGit, Context: "git-commit", Priority: 1},
 		{ID: "cancel", Name: "Cancel", Description: "Cancel commit", Category: plugin.CategoryActions, Context: "git-commit", Priority: 1},
+		{ID: "generate-message", Name: "Generate", Description: "Generate message from staged diff", Category: plugin.CategoryGit, Context: "git-commit", Priority: 2},
 		// git-push-menu context
 		{ID: "push", Name: "Push", Description: "Push to remote", Category: plugin.CategoryGit, Context: "git-push-menu", Priority: 1},
 		{ID: "force-push", Name: "Force", Description: "Force push", Category: plugin.CategoryGit, Context: "git-push-menu", Priority: 1},
@@ -1011,6 +1274,38 @@ func (p *Plugin) Commands() []plugin.Command {
 		// git-stash-pop context (stash pop confirmation modal)
 		{ID: "confirm-pop", Name: "Pop", Description: "Confirm stash pop", Category: plugin.CategoryGit, Context: "git-stash-pop", Priority: 1},
 		{ID: "dismiss", Name: "Cancel", Description: "Cancel stash pop", Category: plugin.CategoryNavigation, Context: "git-stash-pop", Priority: 2},
+		// git-confirm-undoable context (hard reset / branch delete confirmation)
+		{ID: "confirm", Name: "Confirm", Description: "Confirm reset or branch delete", Category: plugin.CategoryGit, Context: "git-confirm-undoable", Priority: 1},
+		{ID: "dismiss", Name: "Cancel", Description: "Cancel", Category: plugin.CategoryNavigation, Context: "git-confirm-undoable", Priority: 2},
+		// git-bisect context (bisect modal)
+		{ID: "bisect-bad", Name: "Bad", Description: "Mark commit bad", Category: plugin.CategoryGit, Context: "git-bisect", Priority: 1},
+		{ID: "bisect-good", Name: "Good", Description: "Mark commit good", Category: plugin.CategoryGit, Context: "git-bisect", Priority: 1},
+		{ID: "bisect-skip", Name: "Skip", Description: "Skip commit", Category: plugin.CategoryGit, Context: "git-bisect", Priority: 2},
+		{ID: "bisect-run", Name: "Run", Description: "Run test command with git bisect run", Category: plugin.CategoryGit, Context: "git-bisect", Priority: 2},
+		{ID: "bisect-reset", Name: "Reset", Description: "End bisect and restore HEAD", Category: plugin.CategoryGit, Context: "git-bisect", Priority: 3},
+		{ID: "close", Name: "Close", Description: "Close bisect", Category: plugin.CategoryNavigation, Context: "git-bisect", Priority: 3},
+		// git-reflog context (reflog browser)
+		{ID: "view-diff", Name: "Diff", Description: "Show changes for reflog entry", Category: plugin.CategoryView, Context: "git-reflog", Priority: 1},
+		{ID: "reset-hard", Name: "Reset", Description: "Hard reset to reflog entry (undoable)", Category: plugin.CategoryGit, Context: "git-reflog", Priority: 2},
+		{ID: "undo", Name: "Undo", Description: "Undo last discard, hard reset or branch delete", Category: plugin.CategoryGit, Context: "git-reflog", Priority: 2},
+		{ID: "toggle-ref", Name: "Ref", Description: "Switch between HEAD and branch reflog", Category: plugin.CategoryView, Context: "git-reflog", Priority: 3},
+		{ID: "close", Name: "Close", Description: "Close reflog", Category: plugin.CategoryNavigation, Context: "git-reflog", Priority: 3},
+		// git-submodules context (submodules modal)
+		{ID: "enter-submodule", Name: "Open", Description: "Step into submodule", Category: plugin.CategoryNavigation, Context: "git-submodules", Priority: 1},
+		{ID: "init-submodule", Name: "Init", Description: "Initialize and update submodule", Category: plugin.CategoryGit, Context: "git-submodules", Priority: 1},
+		{ID: "update-submodule", Name: "Update", Description: "Check out recorded commit", Category: plugin.CategoryGit, Context: "git-submodules", Priority: 2},
+		{ID: "stage-submodule", Name: "Stage", Description: "Stage submodule pointer", Category: plugin.CategoryGit, Context: "git-submodules", Priority: 2},
+		{ID: "close", Name: "Close", Description: "Close submodules", Category: plugin.CategoryNavigation, Context: "git-submodules", Priority: 3},
+		// git-remotes context (remotes pane)
+		{ID: "add-remote", Name: "Add", Description: "Add a remote", Category: plugin.CategoryGit, Context: "git-remotes", Priority: 1},
+		{ID: "fetch-remote", Name: "Fetch", Description: "Fetch selected remote", Category: plugin.CategoryGit, Context: "git-remotes", Priority: 1},
+		{ID: "set-upstream", Name: "Track", Description: "Set upstream of current branch to selected remote", Category: plugin.CategoryGit, Context: "git-remotes", Priority: 2},
+		{ID: "rename-remote", Name: "Rename", Description: "Rename selected remote", Category: plugin.CategoryGit, Context: "git-remotes", Priority: 2},
+		{ID: "edit-remote-url", Name: "URL", Description: "Change URL of selected remote", Category: plugin.CategoryGit, Context: "git-remotes", Priority: 3},
+		{ID: "remove-remote", Name: "Remove", Description: "Remove selected remote", Category: plugin.CategoryGit, Context: "git-remotes", Priority: 3},
+		{ID: "push-to-remote", Name: "Push", Description: "Push to selected remote", Category: plugin.CategoryGit, Context: "git-remotes", Priority: 3},
+		{ID: "pull-from-remote", Name: "Pull", Description: "Pull from selected remote", Category: plugin.CategoryGit, Context: "git-remotes", Priority: 3},
+		{ID: "close", Name: "Close", Description: "Close remotes", Category: plugin.CategoryNavigation, Context: "git-remotes", Priority: 3},
 	}
 }
 
@@ -1041,6 +1336,16 @@ func (p *Plugin) FocusContext() string {
 		return "git-error"
 	case ViewModeConfirmStashPop:
 		return "git-stash-pop"
+	case ViewModeBisect:
+		return "git-bisect"
+	case ViewModeReflog:
+		return "git-reflog"
+	case ViewModeRemotes:
+		return "git-remotes"
+	case ViewModeSubmodules:
+		return "git-submodules"
+	case ViewModeConfirmUndoable:
+		return "git-confirm-undoable"
 	default:
 		if p.activePane == PaneDiff {
 			// Commit preview pane has different context than file diff pane
@@ -1060,7 +1365,8 @@ func (p *Plugin) FocusContext() string {
 // ConsumesTextInput reports whether the plugin is currently in a mode where
 // printable keys should be treated as text input.
 func (p *Plugin) ConsumesTextInput() bool {
-	return p.viewMode == ViewModeCommit || p.historySearchMode || p.pathFilterMode
+	return p.viewMode == ViewModeCommit || p.historySearchMode || p.pathFilterMode ||
+		(p.viewMode == ViewModeBisect && p.bisectRunInputMode)
 }
 
 // Diagnostics returns plugin health info.
@@ -1085,11 +1391,12 @@ func (p *Plugin) refresh() tea.Cmd {
 	if !p.hasRepo || p.tree == nil {
 		return nil
 	}
+	tree, gen := p.tree, p.repoGen
 	return func() tea.Msg {
-		if err := p.tree.Refresh(); err != nil {
+		if err := tree.Refresh(); err != nil {
 			return ErrorMsg{Err: err}
 		}
-		return RefreshDoneMsg{}
+		return RefreshDoneMsg{RepoGen: gen}
 	}
 }
 
@@ -1098,12 +1405,13 @@ func (p *Plugin) startWatcher() tea.Cmd {
 	if !p.hasRepo || p.repoRoot == "" {
 		return nil
 	}
+	root, gen := p.repoRoot, p.repoGen
 	return func() tea.Msg {
-		watcher, err := NewWatcher(p.repoRoot)
+		watcher, err := NewWatcher(root)
 		if err != nil {
 			return ErrorMsg{Err: err}
 		}
-		return WatchStartedMsg{Watcher: watcher}
+		return WatchStartedMsg{RepoGen: gen, Watcher: watcher}
 	}
 }
 
@@ -1138,11 +1446,51 @@ func (p *Plugin) openInFileBrowser(path string) tea.Cmd {
 	return tea.Batch(
 		app.FocusPlugin("file-browser"),
 		func() tea.Msg {
-			return filebrowser.NavigateToFileMsg{Path: path}
+			return appmsg.NavigateToFileMsg{Path: path}
 		},
 	)
 }
 
+// showFileDiff opens the full-screen diff of a changed file on request from
+// another plugin, or explains why there is nothing to show.
+func (p *Plugin) showFileDiff(path string) tea.Cmd {
+	if p.inNoRepoMode() {
+		return appmsg.ShowToast("Not a git repository", 2*time.Second)
+	}
+	if rel, err := filepath.Rel(p.repoRoot, filepath.Join(p.ctx.WorkDir, path)); err == nil {
+		path = filepath.ToSlash(rel)
+	}
+
+	// Reveal files hidden inside a collapsed untracked folder
+	for _, folder := range p.tree.Untracked {
+		for _, child := range folder.Children {
+			if child.Path == path {
+				folder.IsExpanded = true
+			}
+		}
+	}
+
+	for i, entry := range p.tree.AllEntries() {
+		if entry.IsFolder || entry.Path != path {
+			continue
+		}
+		p.cursor = i
+		p.ensureCursorVisible()
+		if p.viewMode != ViewModeDiff {
+			p.diffReturnMode = p.viewMode
+		}
+		p.viewMode = ViewModeDiff
+		p.diffFile = entry.Path
+		p.diffCommit = ""
+		p.diffCommitSubject = ""
+		p.diffCommitShortHash = ""
+		p.diffScroll = 0
+		p.diffLoaded = false
+		return p.loadDiff(entry)
+	}
+	return appmsg.ShowToast("No uncommitted changes to "+path, 2*time.Second)
+}
+
 func mergeRecentCommits(existing, latest []*Commit) []*Commit {
 	if len(latest) == 0 {
 		return existing
@@ -1193,12 +1541,18 @@ func countLines(s string) int {
 }
 
 // Message types
-type RefreshDoneMsg struct{}
+type RefreshDoneMsg struct {
+	RepoGen uint64 // Repository generation when issued (see switchRepo)
+}
 type WatchEventMsg struct{}
-type WatchStartedMsg struct{ Watcher *Watcher }
+type WatchStartedMsg struct {
+	RepoGen uint64 // Repository generation when issued (see switchRepo)
+	Watcher *Watcher
+}
 type ErrorMsg struct{ Err error }
 type DiffLoadedMsg struct {
 	Epoch   uint64 // Epoch when request was issued (for stale detection)
+	RepoGen uint64 // Repository generation when issued (see switchRepo)
 	Content string // Rendered content (may be from delta)
 	Raw     string // Raw diff for built-in rendering
 }
@@ -1216,10 +1570,11 @@ type CommitErrorMsg struct {
 
 // InlineDiffLoadedMsg is sent when an inline diff finishes loading.
 type InlineDiffLoadedMsg struct {
-	Epoch  uint64 // Epoch when request was issued (for stale detection)
-	File   string
-	Raw    string
-	Parsed *ParsedDiff
+	Epoch   uint64 // Epoch when request was issued (for stale detection)
+	RepoGen uint64 // Repository generation when issued (see switchRepo)
+	File    string
+	Raw     string
+	Parsed  *ParsedDiff
 }
 
 // GetEpoch implements plugin.EpochMessage.
@@ -1228,6 +1583,7 @@ func (m InlineDiffLoadedMsg) GetEpoch() uint64 { return m.Epoch }
 // RecentCommitsLoadedMsg is sent when recent commits are loaded for sidebar.
 type RecentCommitsLoadedMsg struct {
 	Epoch      uint64 // Epoch when request was issued (for stale detection)
+	RepoGen    uint64 // Repository generation when issued (see switchRepo)
 	Commits    []*Commit
 	PushStatus *PushStatus
 }
@@ -1238,6 +1594,7 @@ func (m RecentCommitsLoadedMsg) GetEpoch() uint64 { return m.Epoch }
 // MoreCommitsLoadedMsg is sent when additional commits are fetched for infinite scroll.
 type MoreCommitsLoadedMsg struct {
 	Epoch      uint64 // Epoch when request was issued (for stale detection)
+	RepoGen    uint64 // Repository generation when issued (see switchRepo)
 	Commits    []*Commit
 	PushStatus *PushStatus
 }
@@ -1248,6 +1605,7 @@ func (m MoreCommitsLoadedMsg) GetEpoch() uint64 { return m.Epoch }
 // FilteredCommitsLoadedMsg is sent when filtered commits are fetched.
 type FilteredCommitsLoadedMsg struct {
 	Epoch      uint64 // Epoch when request was issued (for stale detection)
+	RepoGen    uint64 // Repository generation when issued (see switchRepo)
 	Commits    []*Commit
 	PushStatus *PushStatus
 }
@@ -1365,6 +1723,9 @@ func (p *Plugin) initCommitTextarea() {
 	p.commitButtonHover = false
 	p.commitModal = nil
 	p.commitModalWidthCache = 0
+	// A generation still running belongs to the previous modal
+	p.commitGen++
+	p.commitGenerating = false
 }
 
 // clearPushSuccessAfterDelay returns a command that clears the push success indicator after 3 seconds.
@@ -0,0 +1,45 @@
+package gitstatus
+
+import (
+	"testing"
+
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+func TestShowFileDiff(t *testing.T) {
+	p := &Plugin{
+		ctx:      &plugin.Context{WorkDir: "/repo/sub"},
+		hasRepo:  true,
+		repoRoot: "/repo",
+		height:   20,
+		tree: &FileTree{
+			Modified: []*FileEntry{{Path: "sub/a.go", Status: StatusModified}},
+			Untracked: []*FileEntry{{Path: "new/", IsFolder: true, Children: []*FileEntry{
+				{Path: "new/b.go", Status: StatusUntracked},
+			}}},
+		},
+	}
+
+	// Paths arrive relative to the workdir
+	if cmd := p.showFileDiff("a.go"); cmd == nil || p.viewMode != ViewModeDiff || p.diffFile != "sub/a.go" {
+		t.Fatalf("viewMode = %v, diffFile = %q", p.viewMode, p.diffFile)
+	}
+	if p.diffReturnMode != ViewModeStatus {
+		t.Errorf("diffReturnMode = %v", p.diffReturnMode)
+	}
+
+	// Files in a collapsed untracked folder are revealed
+	_ = p.showFileDiff("../new/b.go")
+	if p.diffFile != "new/b.go" || !p.tree.Untracked[0].IsExpanded || p.cursor != 2 {
+		t.Errorf("diffFile = %q, cursor = %d", p.diffFile, p.cursor)
+	}
+	if p.diffReturnMode != ViewModeStatus {
+		t.Error("opening a second diff should keep the original return mode")
+	}
+
+	p.viewMode = ViewModeStatus
+	msg := p.showFileDiff("clean.go")()
+	if p.viewMode != ViewModeStatus || msg == nil {
+		t.Error("unchanged files should only show a toast")
+	}
+}
@@ -77,7 +77,7 @@ func (p *Plugin) autoLoadDiff() tea.Cmd {
 		return p.loadFolderDiff(entry)
 	}
 
-	return p.loadInlineDiff(entry.Path, entry.Staged, entry.Status)
+	return p.loadInlineDiff(entry)
 }
 
 // autoLoadCommitPreview triggers loading commit detail for the currently selected commit.
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/marcus/sidecar/internal/modal"
 	"github.com/marcus/sidecar/internal/styles"
 	"github.com/marcus/sidecar/internal/ui"
@@ -50,9 +51,45 @@ func (p *Plugin) ensurePullModal() {
 		modal.WithWidth(modalW),
 		modal.WithPrimaryAction(pullMenuActionID),
 	).
+		AddSection(p.pullRemoteSection()).
 		AddSection(modal.List("pull-options", items, &p.pullSelectedIdx, modal.WithMaxVisible(4)))
 }
 
+// openPullMenu shows the pull menu, loading remotes for R-cycling.
+func (p *Plugin) openPullMenu() tea.Cmd {
+	p.pullMenuReturnMode = p.viewMode
+	p.viewMode = ViewModePullMenu
+	p.pullSelectedIdx = 0
+	p.clearPullModal()
+	if p.remotes == nil {
+		return p.loadRemotes()
+	}
+	return nil
+}
+
+// pullMenuRemote returns the remote the pull menu targets: the chosen
+// remote, else the upstream's remote, else the default remote.
+func (p *Plugin) pullMenuRemote() string {
+	if p.pullRemote != "" {
+		return p.pullRemote
+	}
+	if r := p.upstreamRemoteName(); r != "" {
+		return r
+	}
+	return defaultRemoteName(p.remotes)
+}
+
+func (p *Plugin) pullRemoteSection() modal.Section {
+	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
+		label := "From " + p.pullMenuRemote()
+		if branch := p.currentBranch(); branch != "" {
+			label += "/" + branch
+		}
+		content := styles.Muted.Render(truncateStr(label+" · R to change remote", contentWidth))
+		return modal.RenderedSection{Content: content + "\n"}
+	}, nil)
+}
+
 // renderPullMenu renders the pull options popup menu.
 func (p *Plugin) renderPullMenu() string {
 	background := p.renderThreePaneView()
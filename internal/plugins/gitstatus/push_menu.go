@@ -1,6 +1,7 @@
 package gitstatus
 
 import (
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/marcus/sidecar/internal/modal"
 	"github.com/marcus/sidecar/internal/styles"
 	"github.com/marcus/sidecar/internal/ui"
@@ -30,7 +31,7 @@ func (p *Plugin) ensurePushMenuModal() {
 	p.pushMenuModalWidth = modalW
 
 	items := []modal.ListItem{
-		{ID: pushMenuOptionPush, Label: "Push to origin"},
+		{ID: pushMenuOptionPush, Label: "Push to " + p.pushMenuRemote()},
 		{ID: pushMenuOptionForce, Label: "Force push (--force-with-lease)"},
 		{ID: pushMenuOptionUpstream, Label: "Push & set upstream (-u)"},
 	}
@@ -45,6 +46,56 @@ func (p *Plugin) ensurePushMenuModal() {
 		AddSection(p.pushMenuHintsSection())
 }
 
+// openPushMenu shows the push menu, loading remotes for R-cycling.
+func (p *Plugin) openPushMenu() tea.Cmd {
+	p.pushMenuReturnMode = p.viewMode
+	p.viewMode = ViewModePushMenu
+	p.pushMenuFocus = 0
+	p.clearPushMenuModal()
+	if p.remotes == nil {
+		return p.loadRemotes()
+	}
+	return nil
+}
+
+// pushMenuRemote returns the remote the push menu targets: the chosen
+// remote, else the upstream's remote, else the default remote.
+func (p *Plugin) pushMenuRemote() string {
+	if p.pushRemote != "" {
+		return p.pushRemote
+	}
+	if r := p.upstreamRemoteName(); r != "" {
+		return r
+	}
+	return defaultRemoteName(p.remotes)
+}
+
+// cycleRemote returns the remote after current in list order.
+func cycleRemote(remotes []*Remote, current string) string {
+	if len(remotes) == 0 {
+		return current
+	}
+	for i, r := range remotes {
+		if r.Name == current {
+			return remotes[(i+1)%len(remotes)].Name
+		}
+	}
+	return remotes[0].Name
+}
+
+// defaultRemoteName mirrors GetRemoteName for an already-loaded remote list.
+func defaultRemoteName(remotes []*Remote) string {
+	for _, r := range remotes {
+		if r.Name == "origin" {
+			return r.Name
+		}
+	}
+	if len(remotes) > 0 {
+		return remotes[0].Name
+	}
+	return "origin"
+}
+
 func (p *Plugin) clearPushMenuModal() {
 	p.pushMenuModal = nil
 	p.pushMenuModalWidth = 0
@@ -53,7 +104,7 @@ func (p *Plugin) clearPushMenuModal() {
 func (p *Plugin) pushMenuHintsSection() modal.Section {
 	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
 		return modal.RenderedSection{
-			Content: styles.Muted.Render("p/f/u shortcut · Enter to select · Esc to cancel"),
+			Content: styles.Muted.Render("p/f/u shortcut · R remote · Enter to select · Esc to cancel"),
 		}
 	}, nil)
 }
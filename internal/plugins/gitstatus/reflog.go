@@ -0,0 +1,417 @@
+package gitstatus
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// undoRef is the hidden ref whose reflog records undoable operations.
+// Each entry points at a snapshot commit, which also keeps the recovered
+// objects reachable so gc cannot prune them.
+const undoRef = "refs/sidecar/undo"
+
+// ReflogEntry is a single movement of HEAD or a branch.
+type ReflogEntry struct {
+	Hash      string
+	ShortHash string
+	Selector  string // e.g. HEAD@{3}
+	Action    string // e.g. commit, checkout, reset
+	Message   string // Reflog message without the action prefix
+	Date      time.Time
+}
+
+// GetReflog returns up to limit reflog entries for ref, newest first.
+func GetReflog(workDir, ref string, limit int) ([]*ReflogEntry, error) {
+	args := []string{"reflog", "show", "--date=unix", "--format=%H%x00%gd%x00%gs"}
+	if limit > 0 {
+		args = append(args, "-n", strconv.Itoa(limit))
+	}
+	args = append(args, ref, "--")
+	cmd := exec.Command("git", args...)
+	cmd.Dir = workDir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*ReflogEntry
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		parts := strings.SplitN(line, "\x00", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		entry := &ReflogEntry{
+			Hash:      parts[0],
+			ShortHash: shortHash(parts[0]),
+			Selector:  fmt.Sprintf("%s@{%d}", ref, len(entries)),
+		}
+		// %gd with --date=unix renders as ref@{<timestamp>}
+		if i := strings.LastIndex(parts[1], "@{"); i >= 0 {
+			if ts, err := strconv.ParseInt(strings.TrimSuffix(parts[1][i+2:], "}"), 10, 64); err == nil {
+				entry.Date = time.Unix(ts, 0)
+			}
+		}
+		entry.Action, entry.Message = splitReflogSubject(parts[2])
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// splitReflogSubject splits "checkout: moving from a to b" into action and message.
+func splitReflogSubject(subject string) (action, message string) {
+	if i := strings.Index(subject, ": "); i >= 0 {
+		action = subject[:i]
+		message = subject[i+2:]
+	} else {
+		message = subject
+	}
+	// "commit (amend)", "pull --rebase (finish)" etc. keep only the verb
+	if f := strings.Fields(action); len(f) > 0 {
+		action = f[0]
+	}
+	return action, message
+}
+
+// GetReflogDiff returns the diff introduced by moving from one commit to another.
+// If from is empty, the diff of the commit itself is returned.
+func GetReflogDiff(workDir, from, to string) (string, error) {
+	args := []string{"diff", from, to}
+	if from == "" {
+		args = []string{"show", "--format=", "--patch", to}
+	}
+	out, err := runGit(workDir, args...)
+	if err != nil {
+		return "", &UndoError{Output: out, Err: err}
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// UndoKind identifies an operation recorded in the undo journal.
+type UndoKind string
+
+const (
+	UndoDiscard      UndoKind = "discard"
+	UndoReset        UndoKind = "reset"
+	UndoBranchDelete UndoKind = "delete-branch"
+)
+
+// UndoOp is an operation that can be reverted.
+type UndoOp struct {
+	Kind      UndoKind
+	Entry     string // Journal commit
+	Path      string // Discarded path
+	IndexMode string // Staged file mode before discard (empty if not staged)
+	IndexBlob string // Staged blob before discard
+	Branch    string // Deleted branch name
+	Hash      string // Branch tip or HEAD before reset
+	Snapshot  string // Stash snapshot of tracked changes lost by reset
+	Date      time.Time
+}
+
+// Description returns a short human-readable summary.
+func (o *UndoOp) Description() string {
+	switch o.Kind {
+	case UndoDiscard:
+		return "discard of " + o.Path
+	case UndoReset:
+		return "reset from " + shortHash(o.Hash)
+	case UndoBranchDelete:
+		return "deletion of branch " + o.Branch
+	}
+	return string(o.Kind)
+}
+
+// UndoError wraps a git error from a reflog or undo operation.
+type UndoError struct {
+	Output string
+	Err    error
+}
+
+func (e *UndoError) Error() string {
+	if msg := strings.TrimSpace(e.Output); msg != "" {
+		return msg
+	}
+	return e.Err.Error()
+}
+
+// DiscardWithSnapshot snapshots a path into the undo journal, then discards it.
+func DiscardWithSnapshot(workDir string, entry *FileEntry) error {
+	if err := SnapshotDiscard(workDir, entry.Path, entry.Staged); err != nil {
+		return err
+	}
+	if entry.Status == StatusUntracked {
+		return DiscardUntracked(workDir, entry.Path)
+	}
+	if entry.Staged {
+		return DiscardStaged(workDir, entry.Path)
+	}
+	return DiscardModified(workDir, entry.Path)
+}
+
+// SnapshotDiscard records the working-tree (and, if staged, index) content of
+// path in the undo journal so a following discard can be reverted.
+func SnapshotDiscard(workDir, path string, staged bool) error {
+	tmp, err := os.CreateTemp("", "sidecar-index-*")
+	if err != nil {
+		return err
+	}
+	indexFile := tmp.Name()
+	_ = tmp.Close()
+	_ = os.Remove(indexFile) // git creates a fresh index at this path
+	defer os.Remove(indexFile)
+
+	env := append(os.Environ(), "GIT_INDEX_FILE="+indexFile)
+	if _, err := os.Lstat(filepath.Join(workDir, path)); err == nil {
+		if out, err := runGitEnv(workDir, env, "add", "--force", "--", path); err != nil {
+			return &UndoError{Output: out, Err: err}
+		}
+	}
+	out, err := runGitEnv(workDir, env, "write-tree")
+	if err != nil {
+		return &UndoError{Output: out, Err: err}
+	}
+	tree := strings.TrimSpace(out)
+
+	body := []string{"path: " + path}
+	if staged {
+		if out, err := runGit(workDir, "ls-files", "-s", "--", path); err == nil {
+			// <mode> <blob> <stage>\t<path>
+			if f := strings.Fields(out); len(f) >= 2 {
+				body = append(body, "index: "+f[0]+" "+f[1])
+			}
+		}
+	}
+	return recordUndo(workDir, UndoDiscard, "discard "+path, tree, nil, body)
+}
+
+// ResetHard moves HEAD to target with `git reset --hard`, recording the
+// previous HEAD and a snapshot of tracked changes in the undo journal.
+func ResetHard(workDir, target string) error {
+	out, err := runGit(workDir, "rev-parse", "HEAD")
+	if err != nil {
+		return &UndoError{Output: out, Err: err}
+	}
+	head := strings.TrimSpace(out)
+
+	parents := []string{head}
+	out, err = runGit(workDir, "stash", "create")
+	if err != nil {
+		return &UndoError{Output: out, Err: err}
+	}
+	if snap := strings.TrimSpace(out); snap != "" {
+		parents = append(parents, snap)
+	}
+
+	// Record only once the reset succeeded; a failed reset has nothing to undo
+	if out, err := runGit(workDir, "reset", "--hard", target); err != nil {
+		return &UndoError{Output: out, Err: err}
+	}
+	return recordUndo(workDir, UndoReset, "reset "+shortHash(head)+" -> "+target, head+"^{tree}", parents, nil)
+}
+
+// DeleteBranchWithUndo deletes a merged branch, recording its tip in the undo journal.
+func DeleteBranchWithUndo(workDir, branchName string) error {
+	out, err := runGit(workDir, "rev-parse", "--verify", "refs/heads/"+branchName)
+	if err != nil {
+		return &BranchError{Output: out, Err: err}
+	}
+	tip := strings.TrimSpace(out)
+	if err := DeleteBranch(workDir, branchName); err != nil {
+		return err
+	}
+	body := []string{"branch: " + branchName}
+	return recordUndo(workDir, UndoBranchDelete, "delete-branch "+branchName, tip+"^{tree}", []string{tip}, body)
+}
+
+// recordUndo writes a journal commit and appends it to the undo reflog.
+func recordUndo(workDir string, kind UndoKind, summary, tree string, parents, body []string) error {
+	lines := append([]string{summary, "", "kind: " + string(kind)}, body...)
+	// Nanosecond stamp keeps otherwise identical entries distinct
+	lines = append(lines, "time: "+strconv.FormatInt(time.Now().UnixNano(), 10))
+
+	args := []string{"commit-tree", tree}
+	for _, parent := range parents {
+		args = append(args, "-p", parent)
+	}
+	args = append(args, "-m", strings.Join(lines, "\n"))
+	out, err := runGit(workDir, args...)
+	if err != nil {
+		return &UndoError{Output: out, Err: err}
+	}
+	commit := strings.TrimSpace(out)
+
+	if out, err := runGit(workDir, "update-ref", "--create-reflog", "-m", "sidecar: "+summary, undoRef, commit); err != nil {
+		return &UndoError{Output: out, Err: err}
+	}
+	return nil
+}
+
+// GetUndoJournal returns the recorded undoable operations, newest first.
+func GetUndoJournal(workDir string) ([]*UndoOp, error) {
+	if _, err := runGit(workDir, "rev-parse", "--verify", "-q", undoRef); err != nil {
+		return nil, nil
+	}
+	out, err := runGit(workDir, "reflog", "show", "--format=%H%x00%P%x00%B%x1e", undoRef, "--")
+	if err != nil {
+		return nil, &UndoError{Output: out, Err: err}
+	}
+
+	var ops []*UndoOp
+	for _, record := range strings.Split(out, "\x1e") {
+		parts := strings.SplitN(strings.TrimLeft(record, "\n"), "\x00", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if op := parseUndoCommit(parts[0], strings.Fields(parts[1]), parts[2]); op != nil {
+			ops = append(ops, op)
+		}
+	}
+	return ops, nil
+}
+
+// parseUndoCommit decodes a journal commit message.
+func parseUndoCommit(hash string, parents []string, message string) *UndoOp {
+	op := &UndoOp{Entry: hash}
+	for _, line := range strings.Split(message, "\n") {
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "kind":
+			op.Kind = UndoKind(value)
+		case "path":
+			op.Path = value
+		case "branch":
+			op.Branch = value
+		case "index":
+			if f := strings.Fields(value); len(f) == 2 {
+				op.IndexMode, op.IndexBlob = f[0], f[1]
+			}
+		case "time":
+			if ns, err := strconv.ParseInt(value, 10, 64); err == nil {
+				op.Date = time.Unix(0, ns)
+			}
+		}
+	}
+	if len(parents) > 0 {
+		op.Hash = parents[0]
+	}
+	if len(parents) > 1 {
+		op.Snapshot = parents[1]
+	}
+	if op.Kind == "" {
+		return nil
+	}
+	return op
+}
+
+// LastUndoOp returns the most recent operation in the undo journal, or nil.
+// Only operations sidecar recorded are undone; resets made elsewhere (or by
+// an undo) can be recovered from the reflog instead.
+func LastUndoOp(workDir string) (*UndoOp, error) {
+	ops, err := GetUndoJournal(workDir)
+	if err != nil || len(ops) == 0 {
+		return nil, err
+	}
+	return ops[0], nil
+}
+
+// UndoLast reverts the most recent undoable operation and returns it.
+// Returns nil if there is nothing to undo.
+func UndoLast(workDir string) (*UndoOp, error) {
+	op, err := LastUndoOp(workDir)
+	if err != nil || op == nil {
+		return nil, err
+	}
+	if err := applyUndo(workDir, op); err != nil {
+		return nil, err
+	}
+	if err := popUndo(workDir); err != nil {
+		return op, err
+	}
+	return op, nil
+}
+
+func applyUndo(workDir string, op *UndoOp) error {
+	switch op.Kind {
+	case UndoDiscard:
+		out, err := runGit(workDir, "status", "--porcelain", "--", op.Path)
+		if err != nil {
+			return &UndoError{Output: out, Err: err}
+		}
+		if strings.TrimSpace(out) != "" {
+			return fmt.Errorf("%s has changed since it was discarded", op.Path)
+		}
+		if _, err := runGit(workDir, "cat-file", "-e", op.Entry+":"+op.Path); err == nil {
+			if out, err := runGit(workDir, "restore", "--source="+op.Entry, "--worktree", "--", op.Path); err != nil {
+				return &UndoError{Output: out, Err: err}
+			}
+		} else if err := os.Remove(filepath.Join(workDir, op.Path)); err != nil && !os.IsNotExist(err) {
+			// Discarding a deletion restored the file; undo deletes it again
+			return err
+		}
+		if op.IndexBlob != "" {
+			cacheInfo := op.IndexMode + "," + op.IndexBlob + "," + op.Path
+			if out, err := runGit(workDir, "update-index", "--add", "--cacheinfo", cacheInfo); err != nil {
+				return &UndoError{Output: out, Err: err}
+			}
+		}
+		return nil
+
+	case UndoReset:
+		out, err := runGit(workDir, "stash", "create")
+		if err != nil {
+			return &UndoError{Output: out, Err: err}
+		}
+		if strings.TrimSpace(out) != "" {
+			return fmt.Errorf("working tree has uncommitted changes; stash or commit them before undoing the reset")
+		}
+		if out, err := runGit(workDir, "reset", "--hard", op.Hash); err != nil {
+			return &UndoError{Output: out, Err: err}
+		}
+		if op.Snapshot != "" {
+			if out, err := runGit(workDir, "stash", "apply", "--index", op.Snapshot); err != nil {
+				return &UndoError{Output: out, Err: err}
+			}
+		}
+		return nil
+
+	case UndoBranchDelete:
+		if out, err := runGit(workDir, "branch", op.Branch, op.Hash); err != nil {
+			return &BranchError{Output: out, Err: err}
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown undo operation %q", op.Kind)
+}
+
+// popUndo drops the newest journal entry.
+func popUndo(workDir string) error {
+	ops, err := GetUndoJournal(workDir)
+	if err != nil {
+		return err
+	}
+	args := []string{"reflog", "delete", "--updateref", undoRef + "@{0}"}
+	if len(ops) <= 1 {
+		args = []string{"update-ref", "-d", undoRef}
+	}
+	if out, err := runGit(workDir, args...); err != nil {
+		return &UndoError{Output: out, Err: err}
+	}
+	return nil
+}
+
+// runGitEnv runs a git command with a custom environment.
+func runGitEnv(workDir string, env []string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = workDir
+	cmd.Env = env
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
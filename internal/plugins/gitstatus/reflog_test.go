@@ -0,0 +1,368 @@
+package gitstatus
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// initUndoRepo creates a repo with a single committed file a.txt.
+func initUndoRepo(t *testing.T) (string, func(args ...string) string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v (%s)", args, err, strings.TrimSpace(string(out)))
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q", "-b", "main")
+	writeFile(t, dir, "a.txt", "one\n")
+	git("add", "a.txt")
+	git("commit", "-q", "-m", "first")
+	return dir, git
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestSplitReflogSubject(t *testing.T) {
+	tests := []struct {
+		subject     string
+		wantAction  string
+		wantMessage string
+	}{
+		{"commit: add feature", "commit", "add feature"},
+		{"commit (amend): fix typo", "commit", "fix typo"},
+		{"checkout: moving from main to dev", "checkout", "moving from main to dev"},
+		{"reset: moving to HEAD~1", "reset", "moving to HEAD~1"},
+		{"branch: Created from HEAD", "branch", "Created from HEAD"},
+		{"no prefix", "", "no prefix"},
+	}
+	for _, tt := range tests {
+		action, message := splitReflogSubject(tt.subject)
+		if action != tt.wantAction || message != tt.wantMessage {
+			t.Errorf("splitReflogSubject(%q) = (%q, %q), want (%q, %q)",
+				tt.subject, action, message, tt.wantAction, tt.wantMessage)
+		}
+	}
+}
+
+func TestGetReflog(t *testing.T) {
+	dir, git := initUndoRepo(t)
+	writeFile(t, dir, "a.txt", "two\n")
+	git("commit", "-q", "-am", "second")
+	git("checkout", "-q", "-b", "dev")
+
+	entries, err := GetReflog(dir, "HEAD", 10)
+	if err != nil {
+		t.Fatalf("GetReflog: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	if entries[0].Action != "checkout" || entries[0].Selector != "HEAD@{0}" {
+		t.Errorf("entries[0] = %+v, want checkout at HEAD@{0}", entries[0])
+	}
+	if entries[1].Action != "commit" || entries[1].Message != "second" {
+		t.Errorf("entries[1] = %+v, want commit 'second'", entries[1])
+	}
+	if entries[2].Date.IsZero() {
+		t.Error("expected reflog date to be parsed")
+	}
+
+	diff, err := GetReflogDiff(dir, entries[2].Hash, entries[1].Hash)
+	if err != nil {
+		t.Fatalf("GetReflogDiff: %v", err)
+	}
+	if !strings.Contains(diff, "+two") {
+		t.Errorf("diff missing change:\n%s", diff)
+	}
+}
+
+func TestUndoDiscard(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, dir string, git func(...string) string) *FileEntry
+		check func(t *testing.T, dir string, git func(...string) string)
+	}{
+		{
+			name: "modified",
+			setup: func(t *testing.T, dir string, git func(...string) string) *FileEntry {
+				writeFile(t, dir, "a.txt", "changed\n")
+				return &FileEntry{Path: "a.txt", Status: StatusModified}
+			},
+			check: func(t *testing.T, dir string, git func(...string) string) {
+				if got := readFile(t, dir, "a.txt"); got != "changed\n" {
+					t.Errorf("a.txt = %q, want restored change", got)
+				}
+			},
+		},
+		{
+			name: "staged",
+			setup: func(t *testing.T, dir string, git func(...string) string) *FileEntry {
+				writeFile(t, dir, "a.txt", "staged\n")
+				git("add", "a.txt")
+				writeFile(t, dir, "a.txt", "staged and more\n")
+				return &FileEntry{Path: "a.txt", Status: StatusModified, Staged: true}
+			},
+			check: func(t *testing.T, dir string, git func(...string) string) {
+				if got := readFile(t, dir, "a.txt"); got != "staged and more\n" {
+					t.Errorf("a.txt = %q, want working tree content", got)
+				}
+				if got := git("show", ":a.txt"); got != "staged" {
+					t.Errorf("index a.txt = %q, want staged content", got)
+				}
+			},
+		},
+		{
+			name: "untracked",
+			setup: func(t *testing.T, dir string, git func(...string) string) *FileEntry {
+				if err := os.MkdirAll(filepath.Join(dir, "sub"), 0755); err != nil {
+					t.Fatal(err)
+				}
+				writeFile(t, dir, "sub/new.txt", "new\n")
+				return &FileEntry{Path: "sub/new.txt", Status: StatusUntracked}
+			},
+			check: func(t *testing.T, dir string, git func(...string) string) {
+				if got := readFile(t, dir, "sub/new.txt"); got != "new\n" {
+					t.Errorf("sub/new.txt = %q, want restored file", got)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, git := initUndoRepo(t)
+			entry := tt.setup(t, dir, git)
+			if err := DiscardWithSnapshot(dir, entry); err != nil {
+				t.Fatalf("DiscardWithSnapshot: %v", err)
+			}
+			if status := git("status", "--porcelain"); status != "" {
+				t.Fatalf("status after discard = %q, want clean", status)
+			}
+			if list := git("stash", "list"); list != "" {
+				t.Errorf("snapshot leaked into stash list: %q", list)
+			}
+
+			op, err := UndoLast(dir)
+			if err != nil {
+				t.Fatalf("UndoLast: %v", err)
+			}
+			if op == nil || op.Kind != UndoDiscard || op.Path != entry.Path {
+				t.Fatalf("UndoLast op = %+v, want discard of %s", op, entry.Path)
+			}
+			tt.check(t, dir, git)
+
+			if ops, _ := GetUndoJournal(dir); len(ops) != 0 {
+				t.Errorf("journal has %d entries after undo, want 0", len(ops))
+			}
+		})
+	}
+}
+
+func TestUndoDiscard_RefusesWhenPathChanged(t *testing.T) {
+	dir, _ := initUndoRepo(t)
+	writeFile(t, dir, "a.txt", "changed\n")
+	if err := DiscardWithSnapshot(dir, &FileEntry{Path: "a.txt", Status: StatusModified}); err != nil {
+		t.Fatalf("DiscardWithSnapshot: %v", err)
+	}
+	writeFile(t, dir, "a.txt", "edited again\n")
+
+	if _, err := UndoLast(dir); err == nil {
+		t.Fatal("expected UndoLast to refuse overwriting new changes")
+	}
+	if got := readFile(t, dir, "a.txt"); got != "edited again\n" {
+		t.Errorf("a.txt = %q, want untouched", got)
+	}
+}
+
+func TestUndoResetHard(t *testing.T) {
+	dir, git := initUndoRepo(t)
+	first := git("rev-parse", "HEAD")
+	writeFile(t, dir, "a.txt", "two\n")
+	git("commit", "-q", "-am", "second")
+	second := git("rev-parse", "HEAD")
+	writeFile(t, dir, "a.txt", "uncommitted\n")
+
+	if err := ResetHard(dir, first); err != nil {
+		t.Fatalf("ResetHard: %v", err)
+	}
+	if got := git("rev-parse", "HEAD"); got != first {
+		t.Fatalf("HEAD = %s, want %s", got, first)
+	}
+
+	op, err := UndoLast(dir)
+	if err != nil {
+		t.Fatalf("UndoLast: %v", err)
+	}
+	if op.Kind != UndoReset {
+		t.Fatalf("op.Kind = %q, want reset", op.Kind)
+	}
+	if got := git("rev-parse", "HEAD"); got != second {
+		t.Errorf("HEAD = %s, want %s", got, second)
+	}
+	if got := readFile(t, dir, "a.txt"); got != "uncommitted\n" {
+		t.Errorf("a.txt = %q, want uncommitted change restored", got)
+	}
+}
+
+func TestResetHardFailureRecordsNothing(t *testing.T) {
+	dir, _ := initUndoRepo(t)
+	if err := ResetHard(dir, "no-such-ref"); err == nil {
+		t.Fatal("ResetHard to a missing ref should fail")
+	}
+	ops, err := GetUndoJournal(dir)
+	if err != nil {
+		t.Fatalf("GetUndoJournal: %v", err)
+	}
+	if len(ops) != 0 {
+		t.Errorf("journal = %+v, want no entry for a failed reset", ops)
+	}
+}
+
+func TestUndoIgnoresUnrecordedResets(t *testing.T) {
+	dir, git := initUndoRepo(t)
+	first := git("rev-parse", "HEAD")
+	writeFile(t, dir, "a.txt", "two\n")
+	git("commit", "-q", "-am", "second")
+
+	// A reset made outside sidecar isn't in the journal
+	git("reset", "-q", "--hard", "HEAD~1")
+	op, err := UndoLast(dir)
+	if err != nil || op != nil {
+		t.Fatalf("UndoLast = %+v, %v; want nothing to undo", op, err)
+	}
+	if got := git("rev-parse", "HEAD"); got != first {
+		t.Errorf("HEAD = %s, want %s", got, first)
+	}
+
+	// Undoing a recorded reset doesn't leave the undo itself undoable
+	second := git("rev-parse", "HEAD@{1}")
+	if err := ResetHard(dir, second); err != nil {
+		t.Fatalf("ResetHard: %v", err)
+	}
+	if op, err := UndoLast(dir); err != nil || op == nil {
+		t.Fatalf("UndoLast = %+v, %v", op, err)
+	}
+	if op, err := UndoLast(dir); err != nil || op != nil {
+		t.Fatalf("second UndoLast = %+v, %v; want nothing to undo", op, err)
+	}
+	if got := git("rev-parse", "HEAD"); got != first {
+		t.Errorf("HEAD = %s, want %s", got, first)
+	}
+}
+
+func TestUndoBranchDelete(t *testing.T) {
+	dir, git := initUndoRepo(t)
+	git("branch", "feature")
+	tip := git("rev-parse", "feature")
+
+	if err := DeleteBranchWithUndo(dir, "feature"); err != nil {
+		t.Fatalf("DeleteBranchWithUndo: %v", err)
+	}
+	if out := git("branch", "--list", "feature"); out != "" {
+		t.Fatalf("branch still exists: %q", out)
+	}
+
+	op, err := UndoLast(dir)
+	if err != nil {
+		t.Fatalf("UndoLast: %v", err)
+	}
+	if op.Kind != UndoBranchDelete || op.Branch != "feature" {
+		t.Fatalf("op = %+v, want branch delete of feature", op)
+	}
+	if got := git("rev-parse", "feature"); got != tip {
+		t.Errorf("feature = %s, want %s", got, tip)
+	}
+}
+
+func TestUndoJournalOrder(t *testing.T) {
+	dir, git := initUndoRepo(t)
+	git("branch", "one")
+	git("branch", "two")
+	if err := DeleteBranchWithUndo(dir, "one"); err != nil {
+		t.Fatal(err)
+	}
+	if err := DeleteBranchWithUndo(dir, "two"); err != nil {
+		t.Fatal(err)
+	}
+
+	ops, err := GetUndoJournal(dir)
+	if err != nil {
+		t.Fatalf("GetUndoJournal: %v", err)
+	}
+	if len(ops) != 2 || ops[0].Branch != "two" || ops[1].Branch != "one" {
+		t.Fatalf("journal = %+v, want [two, one]", ops)
+	}
+
+	if _, err := UndoLast(dir); err != nil {
+		t.Fatal(err)
+	}
+	op, err := LastUndoOp(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op == nil || op.Branch != "one" {
+		t.Fatalf("next undo = %+v, want branch one", op)
+	}
+}
+
+func TestResetHardAsksFirst(t *testing.T) {
+	dir, git := initUndoRepo(t)
+	first := git("rev-parse", "HEAD")
+	writeFile(t, dir, "a.txt", "two\n")
+	git("commit", "-q", "-am", "second")
+	second := git("rev-parse", "HEAD")
+
+	p := &Plugin{repoRoot: dir, viewMode: ViewModeReflog, width: 100, height: 40,
+		reflogEntries: []*ReflogEntry{{Hash: first, ShortHash: first[:7], Message: "first"}}}
+	p.updateReflog(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'X'}})
+	if p.viewMode != ViewModeConfirmUndoable {
+		t.Fatalf("viewMode = %v, want confirmation", p.viewMode)
+	}
+	p.updateConfirmUndoable(tea.KeyMsg{Type: tea.KeyEsc})
+	if p.viewMode != ViewModeReflog || git("rev-parse", "HEAD") != second {
+		t.Fatalf("cancel: viewMode = %v, HEAD moved", p.viewMode)
+	}
+
+	p.updateReflog(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'X'}})
+	_, cmd := p.updateConfirmUndoable(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	if p.viewMode != ViewModeReflog || cmd == nil {
+		t.Fatalf("confirm: viewMode = %v, cmd = %v", p.viewMode, cmd)
+	}
+	if msg, ok := cmd().(UndoableOpDoneMsg); !ok || msg.Err != nil || !strings.Contains(msg.Message, "alt+z to undo") {
+		t.Fatalf("msg = %+v", msg)
+	}
+	if got := git("rev-parse", "HEAD"); got != first {
+		t.Errorf("HEAD = %s, want %s", got, first)
+	}
+}
@@ -0,0 +1,425 @@
+package gitstatus
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/marcus/sidecar/internal/app"
+	"github.com/marcus/sidecar/internal/modal"
+	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/styles"
+	"github.com/marcus/sidecar/internal/ui"
+)
+
+const (
+	reflogItemPrefix = "reflog-item-"
+	reflogLimit      = 200
+)
+
+func reflogItemID(idx int) string {
+	return fmt.Sprintf("%s%d", reflogItemPrefix, idx)
+}
+
+func parseReflogItem(id string) (int, bool) {
+	if !strings.HasPrefix(id, reflogItemPrefix) {
+		return 0, false
+	}
+	idx, err := strconv.Atoi(strings.TrimPrefix(id, reflogItemPrefix))
+	if err != nil {
+		return 0, false
+	}
+	return idx, true
+}
+
+// openReflog opens the reflog browser for HEAD.
+func (p *Plugin) openReflog() tea.Cmd {
+	p.reflogReturnMode = p.viewMode
+	p.viewMode = ViewModeReflog
+	p.reflogRef = "HEAD"
+	p.reflogCursor = 0
+	p.reflogEntries = nil
+	p.clearReflogModal()
+	return p.loadReflog()
+}
+
+func (p *Plugin) closeReflog() {
+	p.viewMode = p.reflogReturnMode
+	p.reflogEntries = nil
+	p.clearReflogModal()
+}
+
+func (p *Plugin) clearReflogModal() {
+	p.reflogModal = nil
+	p.reflogModalWidth = 0
+}
+
+// toggleReflogRef switches between the HEAD reflog and the current branch's reflog.
+func (p *Plugin) toggleReflogRef() tea.Cmd {
+	if p.reflogRef != "HEAD" {
+		p.reflogRef = "HEAD"
+	} else if p.pushStatus != nil && p.pushStatus.CurrentBranch != "" {
+		p.reflogRef = p.pushStatus.CurrentBranch
+	} else {
+		return nil
+	}
+	p.reflogCursor = 0
+	p.reflogEntries = nil
+	p.clearReflogModal()
+	return p.loadReflog()
+}
+
+// updateReflog handles key events in the reflog browser.
+func (p *Plugin) updateReflog(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
+	p.ensureReflogModal()
+	if p.reflogModal == nil {
+		return p, nil
+	}
+
+	switch msg.String() {
+	case "esc", "q":
+		p.closeReflog()
+		return p, nil
+
+	case "j", "down":
+		p.moveReflogCursor(1)
+		return p, nil
+
+	case "k", "up":
+		p.moveReflogCursor(-1)
+		return p, nil
+
+	case "g":
+		p.reflogCursor = 0
+		return p, nil
+
+	case "G":
+		if len(p.reflogEntries) > 0 {
+			p.reflogCursor = len(p.reflogEntries) - 1
+		}
+		return p, nil
+
+	case "enter", "d":
+		return p, p.openReflogDiff(p.reflogCursor)
+
+	case "tab":
+		return p, p.toggleReflogRef()
+
+	case "X":
+		// Hard reset to the selected entry (confirmed, recorded for undo)
+		if p.reflogCursor < len(p.reflogEntries) {
+			entry := p.reflogEntries[p.reflogCursor]
+			p.openUndoableConfirm(&undoableConfirm{
+				Title:   "Hard Reset",
+				Prompt:  "Hard reset HEAD to:",
+				Target:  entry.ShortHash + " " + entry.Message,
+				Warning: "Uncommitted changes are snapshotted first; " + undoHint + " restores them.",
+				Button:  "Reset",
+				Run:     p.doResetHard(entry),
+			})
+		}
+		return p, nil
+
+	case "u", "alt+z":
+		return p, p.doUndo()
+	}
+
+	action, cmd := p.reflogModal.HandleKey(msg)
+	if action == "cancel" {
+		p.closeReflog()
+		return p, nil
+	}
+	if idx, ok := parseReflogItem(action); ok {
+		return p, p.openReflogDiff(idx)
+	}
+	return p, cmd
+}
+
+// handleReflogMouse processes mouse events in the reflog browser.
+func (p *Plugin) handleReflogMouse(msg tea.MouseMsg) (plugin.Plugin, tea.Cmd) {
+	p.ensureReflogModal()
+	if p.reflogModal == nil {
+		return p, nil
+	}
+
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		p.moveReflogCursor(-1)
+		return p, nil
+	case tea.MouseButtonWheelDown:
+		p.moveReflogCursor(1)
+		return p, nil
+	}
+
+	action := p.reflogModal.HandleMouse(msg, p.mouseHandler)
+	if action == "cancel" {
+		p.closeReflog()
+		return p, nil
+	}
+	if idx, ok := parseReflogItem(action); ok {
+		p.reflogCursor = idx
+		return p, p.openReflogDiff(idx)
+	}
+	return p, nil
+}
+
+func (p *Plugin) moveReflogCursor(delta int) {
+	if len(p.reflogEntries) == 0 {
+		return
+	}
+	p.reflogCursor += delta
+	if p.reflogCursor < 0 {
+		p.reflogCursor = 0
+	}
+	if p.reflogCursor >= len(p.reflogEntries) {
+		p.reflogCursor = len(p.reflogEntries) - 1
+	}
+}
+
+// openReflogDiff shows what the movement at idx changed, relative to the
+// position before it.
+func (p *Plugin) openReflogDiff(idx int) tea.Cmd {
+	if idx < 0 || idx >= len(p.reflogEntries) {
+		return nil
+	}
+	entry := p.reflogEntries[idx]
+	from := ""
+	if idx+1 < len(p.reflogEntries) {
+		from = p.reflogEntries[idx+1].Hash
+	}
+
+	p.diffReturnMode = p.viewMode
+	p.viewMode = ViewModeDiff
+	p.diffFile = entry.Selector
+	p.diffCommit = entry.Hash
+	p.diffCommitShortHash = entry.ShortHash
+	p.diffCommitSubject = entry.Action + ": " + entry.Message
+	p.diffScroll = 0
+	p.diffLoaded = false
+
+	epoch := p.ctx.Epoch
+	gen := p.repoGen
+	workDir := p.repoRoot
+	to := entry.Hash
+	return func() tea.Msg {
+		rawDiff, err := GetReflogDiff(workDir, from, to)
+		if err != nil {
+			return ErrorMsg{Err: err}
+		}
+		return DiffLoadedMsg{Epoch: epoch, RepoGen: gen, Content: rawDiff, Raw: rawDiff}
+	}
+}
+
+// loadReflog loads entries for the selected reflog ref.
+func (p *Plugin) loadReflog() tea.Cmd {
+	epoch := p.ctx.Epoch
+	workDir := p.repoRoot
+	ref := p.reflogRef
+	return func() tea.Msg {
+		entries, err := GetReflog(workDir, ref, reflogLimit)
+		return ReflogLoadedMsg{Epoch: epoch, Ref: ref, Entries: entries, Err: err}
+	}
+}
+
+// doResetHard hard-resets HEAD to a reflog entry.
+func (p *Plugin) doResetHard(entry *ReflogEntry) tea.Cmd {
+	workDir := p.repoRoot
+	target := entry.Hash
+	label := entry.ShortHash
+	return func() tea.Msg {
+		if err := ResetHard(workDir, target); err != nil {
+			return UndoableOpDoneMsg{Err: err}
+		}
+		return UndoableOpDoneMsg{Message: "Reset to " + label + " (" + undoHint + " to undo)"}
+	}
+}
+
+// doDeleteBranch deletes a merged branch, recording it for undo.
+func (p *Plugin) doDeleteBranch(name string) tea.Cmd {
+	workDir := p.repoRoot
+	return func() tea.Msg {
+		if err := DeleteBranchWithUndo(workDir, name); err != nil {
+			return UndoableOpDoneMsg{Err: err}
+		}
+		return UndoableOpDoneMsg{Message: "Deleted branch " + name + " (" + undoHint + " to undo)"}
+	}
+}
+
+// undoHint names the key that undoes the last operation, for toasts.
+const undoHint = "alt+z"
+
+// doUndo reverts the last discard, hard reset or branch deletion.
+func (p *Plugin) doUndo() tea.Cmd {
+	workDir := p.repoRoot
+	return func() tea.Msg {
+		op, err := UndoLast(workDir)
+		return UndoDoneMsg{Op: op, Err: err}
+	}
+}
+
+// undoDoneCmds refreshes everything an undoable operation may have changed.
+func (p *Plugin) undoDoneCmds(toast string) tea.Cmd {
+	cmds := []tea.Cmd{
+		p.refresh(),
+		p.loadRecentCommits(),
+		func() tea.Msg {
+			return app.ToastMsg{Message: toast, Duration: 3 * time.Second}
+		},
+	}
+	switch p.viewMode {
+	case ViewModeReflog:
+		cmds = append(cmds, p.loadReflog())
+	case ViewModeBranchPicker:
+		cmds = append(cmds, p.loadBranches())
+	}
+	return tea.Batch(cmds...)
+}
+
+// ensureReflogModal builds/rebuilds the reflog modal.
+func (p *Plugin) ensureReflogModal() {
+	modalW := p.width - 10
+	if modalW > 100 {
+		modalW = 100
+	}
+	if modalW < 40 {
+		modalW = 40
+	}
+	if p.reflogModal != nil && p.reflogModalWidth == modalW {
+		return
+	}
+	p.reflogModalWidth = modalW
+
+	p.reflogModal = modal.New("Reflog: "+p.reflogRef,
+		modal.WithWidth(modalW),
+		modal.WithHints(false),
+	).
+		AddSection(p.reflogListSection()).
+		AddSection(modal.Spacer()).
+		AddSection(p.reflogHintsSection())
+}
+
+func (p *Plugin) reflogListSection() modal.Section {
+	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
+		if p.reflogEntries == nil {
+			return modal.RenderedSection{Content: styles.Muted.Render("  Loading reflog...")}
+		}
+		if len(p.reflogEntries) == 0 {
+			return modal.RenderedSection{Content: styles.Muted.Render("  No reflog entries")}
+		}
+
+		maxVisible := p.branchPickerMaxVisible()
+		start := 0
+		if p.reflogCursor >= maxVisible {
+			start = p.reflogCursor - maxVisible + 1
+		}
+		end := start + maxVisible
+		if end > len(p.reflogEntries) {
+			end = len(p.reflogEntries)
+		}
+
+		var sb strings.Builder
+		focusables := make([]modal.FocusableInfo, 0, end-start)
+		for i := start; i < end; i++ {
+			itemID := reflogItemID(i)
+			line := p.renderReflogLine(p.reflogEntries[i], i == p.reflogCursor || itemID == hoverID, contentWidth)
+			if i > start {
+				sb.WriteString("\n")
+			}
+			sb.WriteString(line)
+			focusables = append(focusables, modal.FocusableInfo{
+				ID:      itemID,
+				OffsetX: 0,
+				OffsetY: i - start,
+				Width:   ansi.StringWidth(line),
+				Height:  1,
+			})
+		}
+
+		content := sb.String()
+		if len(p.reflogEntries) > maxVisible {
+			content += "\n\n" + styles.Muted.Render(fmt.Sprintf("  %d/%d entries", p.reflogCursor+1, len(p.reflogEntries)))
+		}
+		return modal.RenderedSection{Content: content, Focusables: focusables}
+	}, nil)
+}
+
+// renderReflogLine renders a single reflog entry.
+func (p *Plugin) renderReflogLine(entry *ReflogEntry, selected bool, width int) string {
+	when := ""
+	if !entry.Date.IsZero() {
+		when = RelativeTime(entry.Date)
+	}
+	action := fmt.Sprintf("%-9s", truncateStr(entry.Action, 9))
+	// hash(7) + spaces(4) + action(9) + when(~8)
+	msgWidth := width - 7 - 4 - 9 - len(when)
+	if msgWidth < 10 {
+		msgWidth = 10
+	}
+	message := truncateStr(entry.Message, msgWidth)
+
+	if selected {
+		plain := fmt.Sprintf("%s %s %s", entry.ShortHash, action, message)
+		if pad := width - len(when) - 1 - ansi.StringWidth(plain); pad > 0 {
+			plain += strings.Repeat(" ", pad)
+		}
+		return styles.ListItemSelected.Render(plain + " " + when)
+	}
+
+	actionStyle := styles.Muted
+	switch entry.Action {
+	case "reset", "rebase":
+		actionStyle = styles.StatusModified
+	case "commit", "merge", "pull", "cherry-pick":
+		actionStyle = styles.StatusStaged
+	}
+	line := fmt.Sprintf("%s %s %s", styles.Code.Render(entry.ShortHash), actionStyle.Render(action), message)
+	if pad := width - len(when) - 1 - ansi.StringWidth(line); pad > 0 {
+		line += strings.Repeat(" ", pad)
+	}
+	return line + " " + styles.Muted.Render(when)
+}
+
+func (p *Plugin) reflogHintsSection() modal.Section {
+	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
+		return modal.RenderedSection{Content: styles.Muted.Render("  Enter diff · X reset --hard here · u undo last · Tab HEAD/branch · Esc close")}
+	}, nil)
+}
+
+// renderReflog renders the reflog browser over the status view.
+func (p *Plugin) renderReflog() string {
+	background := p.renderThreePaneView()
+
+	p.ensureReflogModal()
+	if p.reflogModal == nil {
+		return background
+	}
+
+	modalContent := p.reflogModal.Render(p.width, p.height, p.mouseHandler)
+	return ui.OverlayModal(background, modalContent, p.width, p.height)
+}
+
+// ReflogLoadedMsg is sent when reflog entries have been loaded.
+type ReflogLoadedMsg struct {
+	Epoch   uint64
+	Ref     string
+	Entries []*ReflogEntry
+	Err     error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m ReflogLoadedMsg) GetEpoch() uint64 { return m.Epoch }
+
+// UndoableOpDoneMsg is sent after a reset or branch deletion recorded for undo.
+type UndoableOpDoneMsg struct {
+	Message string
+	Err     error
+}
+
+// UndoDoneMsg is sent after an undo attempt. Op is nil if there was nothing to undo.
+type UndoDoneMsg struct {
+	Op  *UndoOp
+	Err error
+}
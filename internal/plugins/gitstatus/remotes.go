@@ -0,0 +1,159 @@
+package gitstatus
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// Remote is a configured git remote.
+type Remote struct {
+	Name     string
+	FetchURL string
+	PushURL  string // Same as FetchURL unless a separate push URL is configured
+}
+
+// GetRemotes returns all configured remotes in `git remote` order.
+func GetRemotes(workDir string) ([]*Remote, error) {
+	cmd := exec.Command("git", "remote", "-v")
+	cmd.Dir = workDir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseRemotes(string(output)), nil
+}
+
+// parseRemotes parses `git remote -v` output: "<name>\t<url> (fetch|push)".
+func parseRemotes(output string) []*Remote {
+	var remotes []*Remote
+	byName := make(map[string]*Remote)
+	for _, line := range strings.Split(output, "\n") {
+		name, rest, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if !ok {
+			continue
+		}
+		url, kind, _ := strings.Cut(rest, " ")
+		r := byName[name]
+		if r == nil {
+			r = &Remote{Name: name}
+			byName[name] = r
+			remotes = append(remotes, r)
+		}
+		switch kind {
+		case "(fetch)":
+			r.FetchURL = url
+		case "(push)":
+			r.PushURL = url
+		}
+	}
+	for _, r := range remotes {
+		if r.PushURL == "" {
+			r.PushURL = r.FetchURL
+		}
+	}
+	return remotes
+}
+
+// AddRemote adds a new remote.
+func AddRemote(workDir, name, url string) error {
+	return runRemoteCmd(workDir, "remote", "add", name, url)
+}
+
+// RemoveRemote removes a remote and its remote-tracking branches.
+func RemoveRemote(workDir, name string) error {
+	return runRemoteCmd(workDir, "remote", "remove", name)
+}
+
+// RenameRemote renames a remote, updating tracking configuration.
+func RenameRemote(workDir, oldName, newName string) error {
+	return runRemoteCmd(workDir, "remote", "rename", oldName, newName)
+}
+
+// SetRemoteURL changes the fetch URL of a remote.
+func SetRemoteURL(workDir, name, url string) error {
+	return runRemoteCmd(workDir, "remote", "set-url", name, url)
+}
+
+// ExecuteFetchRemote fetches a single remote, pruning deleted branches.
+func ExecuteFetchRemote(workDir, remote string) (string, error) {
+	cmd := exec.Command("git", "fetch", "--prune", remote)
+	cmd.Dir = workDir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", &RemoteError{Output: string(output), Err: err}
+	}
+	return string(output), nil
+}
+
+// SetUpstream sets the upstream of the current branch to remote/<branch>.
+// The remote branch must already exist (fetch or push -u first).
+func SetUpstream(workDir, remote, branch string) error {
+	if branch == "" {
+		return &RemoteError{Output: "Detached HEAD - no branch to track", Err: errors.New("detached head")}
+	}
+	return runRemoteCmd(workDir, "branch", "--set-upstream-to="+remote+"/"+branch, branch)
+}
+
+// GetUpstreamRemote returns the remote the current branch tracks, or "".
+func GetUpstreamRemote(workDir, branch string) string {
+	if branch == "" {
+		return ""
+	}
+	cmd := exec.Command("git", "config", "--get", "branch."+branch+".remote")
+	cmd.Dir = workDir
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// ExecutePushToRemote pushes HEAD to the named remote.
+// With setUpstream, the remote branch becomes the current branch's upstream.
+func ExecutePushToRemote(workDir, remote string, force, setUpstream bool) (string, error) {
+	args := []string{"push"}
+	if force {
+		args = append(args, "--force-with-lease")
+	}
+	if setUpstream {
+		args = append(args, "-u")
+	}
+	args = append(args, remote, "HEAD")
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = workDir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return string(output), &PushError{Output: string(output), Err: err}
+	}
+	return string(output), nil
+}
+
+// ExecutePullFromRemote pulls branch from the named remote. Extra flags select
+// the strategy (e.g. --rebase, --ff-only).
+func ExecutePullFromRemote(workDir, remote, branch string, flags ...string) (string, error) {
+	if branch == "" {
+		return "", &RemoteError{Output: "Detached HEAD - cannot pull", Err: errors.New("detached head")}
+	}
+	args := append([]string{"pull"}, flags...)
+	args = append(args, remote, branch)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = workDir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", &RemoteError{Output: string(output), Err: err}
+	}
+	return string(output), nil
+}
+
+// runRemoteCmd runs a git command, wrapping failures in a RemoteError.
+func runRemoteCmd(workDir string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = workDir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return &RemoteError{Output: string(output), Err: err}
+	}
+	return nil
+}
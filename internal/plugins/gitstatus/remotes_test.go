@@ -0,0 +1,206 @@
+package gitstatus
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestParseRemotes(t *testing.T) {
+	output := "origin\tgit@github.com:me/repo.git (fetch)\n" +
+		"origin\tgit@github.com:me/repo.git (push)\n" +
+		"upstream\thttps://github.com/owner/repo.git (fetch)\n" +
+		"upstream\tno_push (push)\n" +
+		"mirror\t/srv/mirror.git (fetch)\n"
+
+	remotes := parseRemotes(output)
+	if len(remotes) != 3 {
+		t.Fatalf("got %d remotes, want 3", len(remotes))
+	}
+	want := []Remote{
+		{Name: "origin", FetchURL: "git@github.com:me/repo.git", PushURL: "git@github.com:me/repo.git"},
+		{Name: "upstream", FetchURL: "https://github.com/owner/repo.git", PushURL: "no_push"},
+		{Name: "mirror", FetchURL: "/srv/mirror.git", PushURL: "/srv/mirror.git"},
+	}
+	for i, w := range want {
+		if *remotes[i] != w {
+			t.Errorf("remote %d = %+v, want %+v", i, *remotes[i], w)
+		}
+	}
+
+	if got := parseRemotes(""); len(got) != 0 {
+		t.Errorf("expected no remotes for empty output, got %d", len(got))
+	}
+}
+
+func TestCycleRemote(t *testing.T) {
+	remotes := []*Remote{{Name: "origin"}, {Name: "upstream"}}
+	if got := cycleRemote(remotes, "origin"); got != "upstream" {
+		t.Errorf("cycleRemote(origin) = %q", got)
+	}
+	if got := cycleRemote(remotes, "upstream"); got != "origin" {
+		t.Errorf("cycleRemote(upstream) = %q", got)
+	}
+	if got := cycleRemote(remotes, "gone"); got != "origin" {
+		t.Errorf("cycleRemote(gone) = %q", got)
+	}
+	if got := defaultRemoteName([]*Remote{{Name: "fork"}, {Name: "origin"}}); got != "origin" {
+		t.Errorf("defaultRemoteName = %q, want origin", got)
+	}
+}
+
+// initBareRemote creates a bare repository to act as a remote.
+func initBareRemote(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	cmd := exec.Command("git", "init", "-q", "--bare", "-b", "main", dir)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git init --bare: %v (%s)", err, out)
+	}
+	return dir
+}
+
+func TestRemoteManagement(t *testing.T) {
+	dir, git := initUndoRepo(t)
+	originDir := initBareRemote(t)
+	forkDir := initBareRemote(t)
+
+	if err := AddRemote(dir, "origin", originDir); err != nil {
+		t.Fatalf("AddRemote: %v", err)
+	}
+	if err := AddRemote(dir, "fork", forkDir); err != nil {
+		t.Fatalf("AddRemote: %v", err)
+	}
+	if err := AddRemote(dir, "origin", forkDir); err == nil {
+		t.Error("expected error adding duplicate remote")
+	}
+
+	remotes, err := GetRemotes(dir)
+	if err != nil {
+		t.Fatalf("GetRemotes: %v", err)
+	}
+	if len(remotes) != 2 {
+		t.Fatalf("got %d remotes, want 2", len(remotes))
+	}
+
+	// Push to a non-default remote and track it
+	if _, err := ExecutePushToRemote(dir, "fork", false, true); err != nil {
+		t.Fatalf("ExecutePushToRemote: %v", err)
+	}
+	if got := GetUpstreamRemote(dir, "main"); got != "fork" {
+		t.Errorf("upstream remote = %q, want fork", got)
+	}
+
+	// Re-point upstream at origin once its branch exists
+	if _, err := ExecutePushToRemote(dir, "origin", false, false); err != nil {
+		t.Fatalf("ExecutePushToRemote: %v", err)
+	}
+	if _, err := ExecuteFetchRemote(dir, "origin"); err != nil {
+		t.Fatalf("ExecuteFetchRemote: %v", err)
+	}
+	if err := SetUpstream(dir, "origin", "main"); err != nil {
+		t.Fatalf("SetUpstream: %v", err)
+	}
+	if got := git("rev-parse", "--abbrev-ref", "main@{upstream}"); got != "origin/main" {
+		t.Errorf("upstream = %q, want origin/main", got)
+	}
+	if err := SetUpstream(dir, "origin", ""); err == nil {
+		t.Error("expected error setting upstream without a branch")
+	}
+
+	// Rename carries the tracking config along
+	if err := RenameRemote(dir, "origin", "upstream"); err != nil {
+		t.Fatalf("RenameRemote: %v", err)
+	}
+	if got := GetUpstreamRemote(dir, "main"); got != "upstream" {
+		t.Errorf("upstream remote after rename = %q, want upstream", got)
+	}
+
+	if err := SetRemoteURL(dir, "fork", originDir); err != nil {
+		t.Fatalf("SetRemoteURL: %v", err)
+	}
+	if got := git("remote", "get-url", "fork"); got != originDir {
+		t.Errorf("fork URL = %q, want %q", got, originDir)
+	}
+
+	if err := RemoveRemote(dir, "fork"); err != nil {
+		t.Fatalf("RemoveRemote: %v", err)
+	}
+	if got := git("remote"); got != "upstream" {
+		t.Errorf("remotes = %q, want upstream", got)
+	}
+	if err := RemoveRemote(dir, "fork"); err == nil {
+		t.Error("expected error removing missing remote")
+	}
+}
+
+func TestExecutePullFromRemote(t *testing.T) {
+	dir, git := initUndoRepo(t)
+	remoteDir := initBareRemote(t)
+	if err := AddRemote(dir, "other", remoteDir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ExecutePushToRemote(dir, "other", false, false); err != nil {
+		t.Fatal(err)
+	}
+
+	// Advance the remote from a second clone
+	clone := t.TempDir()
+	if out, err := exec.Command("git", "clone", "-q", remoteDir, clone).CombinedOutput(); err != nil {
+		t.Fatalf("clone: %v (%s)", err, out)
+	}
+	writeFile(t, clone, "b.txt", "two\n")
+	for _, args := range [][]string{{"add", "b.txt"}, {"commit", "-q", "-m", "second"}, {"push", "-q", "origin", "main"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = clone
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v (%s)", args, err, out)
+		}
+	}
+
+	if _, err := ExecutePullFromRemote(dir, "other", "main", "--ff-only"); err != nil {
+		t.Fatalf("ExecutePullFromRemote: %v", err)
+	}
+	if got := git("log", "-1", "--format=%s"); got != "second" {
+		t.Errorf("HEAD subject = %q, want second", got)
+	}
+	if _, err := ExecutePullFromRemote(dir, "other", ""); err == nil || !strings.Contains(err.Error(), "Detached") {
+		t.Errorf("expected detached HEAD error, got %v", err)
+	}
+}
+
+func TestPushPullGatedOnSelectedRemote(t *testing.T) {
+	p := &Plugin{
+		remotes: []*Remote{{Name: "origin"}, {Name: "fork"}},
+		pushStatus: &PushStatus{
+			HasUpstream:    true,
+			UpstreamBranch: "origin/main",
+			CurrentBranch:  "main",
+		},
+	}
+
+	// Up to date with origin: nothing to push there, but fork is fair game
+	if p.canPushTo("origin") {
+		t.Error("canPushTo(origin) = true with nothing ahead of upstream")
+	}
+	if !p.canPushTo("fork") {
+		t.Error("canPushTo(fork) = false, want true")
+	}
+
+	// No upstream: the default target has nothing to pull, a chosen remote does
+	p.pushStatus = &PushStatus{CurrentBranch: "main"}
+	if p.canPullFrom("") {
+		t.Error("canPullFrom(\"\") = true without upstream")
+	}
+	if !p.canPullFrom("fork") {
+		t.Error("canPullFrom(fork) = false, want true")
+	}
+	if !p.canPushTo("fork") {
+		t.Error("canPushTo(fork) = false for a branch without upstream")
+	}
+
+	p.pushStatus = &PushStatus{DetachedHead: true}
+	if p.canPushTo("fork") || p.canPullFrom("fork") {
+		t.Error("detached HEAD should not push or pull")
+	}
+}
@@ -0,0 +1,510 @@
+package gitstatus
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/marcus/sidecar/internal/app"
+	"github.com/marcus/sidecar/internal/modal"
+	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/styles"
+	"github.com/marcus/sidecar/internal/ui"
+)
+
+const (
+	remoteItemPrefix = "remote-item-"
+
+	remoteNameInputID = "remote-name"
+	remoteURLInputID  = "remote-url"
+	remoteSubmitID    = "remote-submit"
+
+	remoteInputAdd    = "add"
+	remoteInputRename = "rename"
+	remoteInputURL    = "set-url"
+)
+
+func remoteItemID(idx int) string {
+	return fmt.Sprintf("%s%d", remoteItemPrefix, idx)
+}
+
+func parseRemoteItem(id string) (int, bool) {
+	if !strings.HasPrefix(id, remoteItemPrefix) {
+		return 0, false
+	}
+	idx, err := strconv.Atoi(strings.TrimPrefix(id, remoteItemPrefix))
+	if err != nil {
+		return 0, false
+	}
+	return idx, true
+}
+
+// openRemotes opens the remotes pane.
+func (p *Plugin) openRemotes() tea.Cmd {
+	p.remotesReturnMode = p.viewMode
+	p.viewMode = ViewModeRemotes
+	p.remotesCursor = 0
+	p.remoteInputMode = ""
+	p.remoteConfirmRemove = ""
+	p.clearRemotesModal()
+	return p.loadRemotes()
+}
+
+func (p *Plugin) closeRemotes() {
+	p.viewMode = p.remotesReturnMode
+	p.remoteInputMode = ""
+	p.remoteConfirmRemove = ""
+	p.clearRemotesModal()
+}
+
+func (p *Plugin) clearRemotesModal() {
+	p.remotesModal = nil
+	p.remotesModalWidth = 0
+}
+
+// selectedRemote returns the remote under the cursor, or nil.
+func (p *Plugin) selectedRemote() *Remote {
+	if p.remotesCursor < 0 || p.remotesCursor >= len(p.remotes) {
+		return nil
+	}
+	return p.remotes[p.remotesCursor]
+}
+
+func (p *Plugin) currentBranch() string {
+	if p.pushStatus == nil {
+		return ""
+	}
+	return p.pushStatus.CurrentBranch
+}
+
+// upstreamRemoteName returns the remote part of the current upstream, if any.
+func (p *Plugin) upstreamRemoteName() string {
+	if p.pushStatus == nil || !p.pushStatus.HasUpstream {
+		return ""
+	}
+	for _, r := range p.remotes {
+		if strings.HasPrefix(p.pushStatus.UpstreamBranch, r.Name+"/") {
+			return r.Name
+		}
+	}
+	remote, _, _ := strings.Cut(p.pushStatus.UpstreamBranch, "/")
+	return remote
+}
+
+// startRemoteInput switches the pane into add/rename/set-url input mode.
+func (p *Plugin) startRemoteInput(mode string) tea.Cmd {
+	r := p.selectedRemote()
+	if mode != remoteInputAdd && r == nil {
+		return nil
+	}
+	p.remoteInputMode = mode
+	p.remoteConfirmRemove = ""
+	p.remoteNameInput = textinput.New()
+	p.remoteURLInput = textinput.New()
+	switch mode {
+	case remoteInputAdd:
+		p.remoteNameInput.Placeholder = "upstream"
+		p.remoteURLInput.Placeholder = "git@github.com:owner/repo.git"
+		p.remoteNameInput.Focus()
+	case remoteInputRename:
+		p.remoteNameInput.SetValue(r.Name)
+		p.remoteNameInput.Focus()
+	case remoteInputURL:
+		p.remoteURLInput.SetValue(r.FetchURL)
+		p.remoteURLInput.Focus()
+	}
+	p.clearRemotesModal()
+	return textinput.Blink
+}
+
+func (p *Plugin) cancelRemoteInput() {
+	p.remoteInputMode = ""
+	p.clearRemotesModal()
+}
+
+// submitRemoteInput runs the add/rename/set-url operation for the inputs.
+func (p *Plugin) submitRemoteInput() tea.Cmd {
+	name := strings.TrimSpace(p.remoteNameInput.Value())
+	url := strings.TrimSpace(p.remoteURLInput.Value())
+	workDir := p.repoRoot
+	mode := p.remoteInputMode
+	r := p.selectedRemote()
+
+	switch mode {
+	case remoteInputAdd:
+		if name == "" || url == "" {
+			return nil
+		}
+		return p.doRemoteOp("Added remote "+name, func() error { return AddRemote(workDir, name, url) })
+	case remoteInputRename:
+		if r == nil || name == "" || name == r.Name {
+			p.cancelRemoteInput()
+			return nil
+		}
+		oldName := r.Name
+		return p.doRemoteOp("Renamed "+oldName+" to "+name, func() error { return RenameRemote(workDir, oldName, name) })
+	case remoteInputURL:
+		if r == nil || url == "" {
+			return nil
+		}
+		remoteName := r.Name
+		return p.doRemoteOp("Updated URL of "+remoteName, func() error { return SetRemoteURL(workDir, remoteName, url) })
+	}
+	return nil
+}
+
+// updateRemotes handles key events in the remotes pane.
+func (p *Plugin) updateRemotes(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
+	p.ensureRemotesModal()
+	if p.remotesModal == nil {
+		return p, nil
+	}
+
+	if p.remoteInputMode != "" {
+		if msg.String() == "esc" {
+			p.cancelRemoteInput()
+			return p, nil
+		}
+		action, cmd := p.remotesModal.HandleKey(msg)
+		if action == remoteSubmitID {
+			return p, p.submitRemoteInput()
+		}
+		return p, cmd
+	}
+
+	key := msg.String()
+	confirming := p.remoteConfirmRemove
+	p.remoteConfirmRemove = ""
+
+	switch key {
+	case "esc", "q":
+		p.closeRemotes()
+		return p, nil
+	case "j", "down":
+		p.moveRemotesCursor(1)
+		return p, nil
+	case "k", "up":
+		p.moveRemotesCursor(-1)
+		return p, nil
+	case "a":
+		return p, p.startRemoteInput(remoteInputAdd)
+	case "r":
+		return p, p.startRemoteInput(remoteInputRename)
+	case "e":
+		return p, p.startRemoteInput(remoteInputURL)
+	case "d":
+		// Remove requires a second press to confirm
+		if r := p.selectedRemote(); r != nil {
+			if confirming == r.Name {
+				workDir := p.repoRoot
+				name := r.Name
+				return p, p.doRemoteOp("Removed remote "+name, func() error { return RemoveRemote(workDir, name) })
+			}
+			p.remoteConfirmRemove = r.Name
+		}
+		return p, nil
+	case "f":
+		if r := p.selectedRemote(); r != nil {
+			return p, p.doFetchRemote(r.Name)
+		}
+		return p, nil
+	case "u":
+		if r := p.selectedRemote(); r != nil {
+			workDir := p.repoRoot
+			name := r.Name
+			branch := p.currentBranch()
+			return p, p.doRemoteOp("Tracking "+name+"/"+branch, func() error { return SetUpstream(workDir, name, branch) })
+		}
+		return p, nil
+	case "P":
+		if r := p.selectedRemote(); r != nil {
+			p.pushRemote = r.Name
+			p.closeRemotes()
+			return p, p.openPushMenu()
+		}
+		return p, nil
+	case "L":
+		if r := p.selectedRemote(); r != nil {
+			p.pullRemote = r.Name
+			p.closeRemotes()
+			return p, p.openPullMenu()
+		}
+		return p, nil
+	}
+
+	action, cmd := p.remotesModal.HandleKey(msg)
+	if action == "cancel" {
+		p.closeRemotes()
+		return p, nil
+	}
+	return p, cmd
+}
+
+// handleRemotesMouse processes mouse events in the remotes pane.
+func (p *Plugin) handleRemotesMouse(msg tea.MouseMsg) (plugin.Plugin, tea.Cmd) {
+	p.ensureRemotesModal()
+	if p.remotesModal == nil {
+		return p, nil
+	}
+
+	if p.remoteInputMode == "" {
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			p.moveRemotesCursor(-1)
+			return p, nil
+		case tea.MouseButtonWheelDown:
+			p.moveRemotesCursor(1)
+			return p, nil
+		}
+	}
+
+	action := p.remotesModal.HandleMouse(msg, p.mouseHandler)
+	switch action {
+	case "cancel":
+		if p.remoteInputMode != "" {
+			p.cancelRemoteInput()
+		} else {
+			p.closeRemotes()
+		}
+	case remoteSubmitID:
+		return p, p.submitRemoteInput()
+	}
+	if idx, ok := parseRemoteItem(action); ok {
+		p.remotesCursor = idx
+	}
+	return p, nil
+}
+
+func (p *Plugin) moveRemotesCursor(delta int) {
+	if len(p.remotes) == 0 {
+		return
+	}
+	p.remotesCursor += delta
+	if p.remotesCursor < 0 {
+		p.remotesCursor = 0
+	}
+	if p.remotesCursor >= len(p.remotes) {
+		p.remotesCursor = len(p.remotes) - 1
+	}
+}
+
+// loadRemotes loads the remote list.
+func (p *Plugin) loadRemotes() tea.Cmd {
+	epoch := p.ctx.Epoch
+	workDir := p.repoRoot
+	return func() tea.Msg {
+		remotes, err := GetRemotes(workDir)
+		if err != nil {
+			return RemoteOpDoneMsg{Err: err}
+		}
+		return RemotesLoadedMsg{Epoch: epoch, Remotes: remotes}
+	}
+}
+
+// doRemoteOp runs a remote configuration change and reports the result.
+func (p *Plugin) doRemoteOp(success string, op func() error) tea.Cmd {
+	return func() tea.Msg {
+		if err := op(); err != nil {
+			return RemoteOpDoneMsg{Err: err}
+		}
+		return RemoteOpDoneMsg{Message: success}
+	}
+}
+
+// doFetchRemote fetches a single remote.
+func (p *Plugin) doFetchRemote(remote string) tea.Cmd {
+	workDir := p.repoRoot
+	p.fetchInProgress = true
+	p.fetchError = ""
+	p.fetchSuccess = false
+	return func() tea.Msg {
+		output, err := ExecuteFetchRemote(workDir, remote)
+		if err != nil {
+			return FetchErrorMsg{Err: err}
+		}
+		return FetchSuccessMsg{Output: output}
+	}
+}
+
+// ensureRemotesModal builds/rebuilds the remotes modal.
+func (p *Plugin) ensureRemotesModal() {
+	modalW := p.width - 10
+	if modalW > 90 {
+		modalW = 90
+	}
+	if modalW < 40 {
+		modalW = 40
+	}
+	if p.remotesModal != nil && p.remotesModalWidth == modalW {
+		return
+	}
+	p.remotesModalWidth = modalW
+
+	m := modal.New("Remotes",
+		modal.WithWidth(modalW),
+		modal.WithHints(false),
+	)
+	switch p.remoteInputMode {
+	case remoteInputAdd:
+		m = m.AddSection(modal.InputWithLabel(remoteNameInputID, "Name:", &p.remoteNameInput)).
+			AddSection(modal.InputWithLabel(remoteURLInputID, "URL:", &p.remoteURLInput, modal.WithSubmitAction(remoteSubmitID)))
+	case remoteInputRename:
+		m = m.AddSection(modal.InputWithLabel(remoteNameInputID, "New name:", &p.remoteNameInput, modal.WithSubmitAction(remoteSubmitID)))
+	case remoteInputURL:
+		m = m.AddSection(modal.InputWithLabel(remoteURLInputID, "URL:", &p.remoteURLInput, modal.WithSubmitAction(remoteSubmitID)))
+	default:
+		m = m.AddSection(p.remotesListSection()).
+			AddSection(p.remotesStatusSection())
+	}
+	p.remotesModal = m.
+		AddSection(modal.Spacer()).
+		AddSection(p.remotesHintsSection())
+
+	switch p.remoteInputMode {
+	case remoteInputAdd, remoteInputRename:
+		p.remotesModal.SetFocus(remoteNameInputID)
+	case remoteInputURL:
+		p.remotesModal.SetFocus(remoteURLInputID)
+	}
+}
+
+func (p *Plugin) remotesListSection() modal.Section {
+	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
+		if p.remotes == nil {
+			return modal.RenderedSection{Content: styles.Muted.Render("  Loading remotes...")}
+		}
+		if len(p.remotes) == 0 {
+			return modal.RenderedSection{Content: styles.Muted.Render("  No remotes configured. Press a to add one.")}
+		}
+
+		upstream := p.upstreamRemoteName()
+		infos := GitHubRemotes(p.remotes)
+		nameWidth := 6
+		for _, r := range p.remotes {
+			if len(r.Name) > nameWidth {
+				nameWidth = len(r.Name)
+			}
+		}
+
+		var sb strings.Builder
+		focusables := make([]modal.FocusableInfo, 0, len(p.remotes))
+		row := 0
+		for i, r := range p.remotes {
+			marker := "  "
+			if r.Name == upstream {
+				marker = "* "
+			}
+			label := r.FetchURL
+			if info := infos[r.Name]; info != nil {
+				label = info.Owner + "/" + info.Repo
+			}
+			line := fmt.Sprintf("%s%-*s  %s", marker, nameWidth, r.Name, label)
+			line = truncateStr(line, contentWidth)
+
+			if row > 0 {
+				sb.WriteString("\n")
+			}
+			itemID := remoteItemID(i)
+			if i == p.remotesCursor || itemID == hoverID {
+				sb.WriteString(styles.ListItemSelected.Render(line))
+			} else {
+				nameStyle := styles.Body
+				if r.Name == upstream {
+					nameStyle = styles.StatusStaged
+				}
+				rest := strings.TrimPrefix(line, marker+fmt.Sprintf("%-*s", nameWidth, r.Name))
+				sb.WriteString(marker + nameStyle.Render(fmt.Sprintf("%-*s", nameWidth, r.Name)) + styles.Muted.Render(rest))
+			}
+			focusables = append(focusables, modal.FocusableInfo{
+				ID:      itemID,
+				OffsetY: row,
+				Width:   ansi.StringWidth(line),
+				Height:  1,
+			})
+			row++
+
+			// Show URLs for the selected remote
+			if i == p.remotesCursor {
+				sb.WriteString("\n" + styles.Muted.Render(truncateStr("    fetch "+r.FetchURL, contentWidth)))
+				row++
+				if r.PushURL != r.FetchURL {
+					sb.WriteString("\n" + styles.Muted.Render(truncateStr("    push  "+r.PushURL, contentWidth)))
+					row++
+				}
+			}
+		}
+		return modal.RenderedSection{Content: sb.String(), Focusables: focusables}
+	}, nil)
+}
+
+func (p *Plugin) remotesStatusSection() modal.Section {
+	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
+		var lines []string
+		if p.remoteConfirmRemove != "" {
+			lines = append(lines, styles.StatusDeleted.Render("Press d again to remove "+p.remoteConfirmRemove))
+		} else if p.fetchInProgress {
+			lines = append(lines, styles.StatusInProgress.Render("Fetching..."))
+		}
+		branch := p.currentBranch()
+		if branch != "" {
+			tracking := "no upstream"
+			if p.pushStatus != nil && p.pushStatus.HasUpstream {
+				tracking = "tracks " + p.pushStatus.UpstreamBranch
+			}
+			lines = append(lines, styles.Muted.Render(truncateStr(branch+" "+tracking, contentWidth)))
+		}
+		if len(lines) == 0 {
+			return modal.RenderedSection{}
+		}
+		return modal.RenderedSection{Content: "\n" + strings.Join(lines, "\n")}
+	}, nil)
+}
+
+func (p *Plugin) remotesHintsSection() modal.Section {
+	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
+		hint := "a add · r rename · e edit URL · d remove · f fetch · u track · P push · L pull · Esc close"
+		if p.remoteInputMode != "" {
+			hint = "Enter to save · Tab next field · Esc to go back"
+		}
+		return modal.RenderedSection{Content: styles.Muted.Render(hint)}
+	}, nil)
+}
+
+// renderRemotes renders the remotes pane over the status view.
+func (p *Plugin) renderRemotes() string {
+	background := p.renderThreePaneView()
+
+	p.ensureRemotesModal()
+	if p.remotesModal == nil {
+		return background
+	}
+
+	modalContent := p.remotesModal.Render(p.width, p.height, p.mouseHandler)
+	return ui.OverlayModal(background, modalContent, p.width, p.height)
+}
+
+// remoteOpToast shows the result of a remote operation.
+func remoteOpToast(message string) tea.Cmd {
+	return func() tea.Msg {
+		return app.ToastMsg{Message: message, Duration: 2 * time.Second}
+	}
+}
+
+// RemotesLoadedMsg is sent when the remote list has been loaded.
+type RemotesLoadedMsg struct {
+	Epoch   uint64
+	Remotes []*Remote
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m RemotesLoadedMsg) GetEpoch() uint64 { return m.Epoch }
+
+// RemoteOpDoneMsg is sent after adding, removing, renaming or re-tracking a remote.
+type RemoteOpDoneMsg struct {
+	Message string
+	Err     error
+}
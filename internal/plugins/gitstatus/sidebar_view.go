@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/marcus/sidecar/internal/styles"
 	"github.com/marcus/sidecar/internal/ui"
 )
@@ -134,6 +135,10 @@ func (p *Plugin) renderSidebar(visibleHeight int) string {
 			header += " " + styles.Muted.Render("(detached)")
 		}
 	}
+	if p.inSubmodule() {
+		// Nested repository: show where we are relative to the outer repo
+		header += " " + styles.StatusModified.Render("["+p.submoduleLabel()+"]")
+	}
 	sb.WriteString(header)
 	sb.WriteString("\n\n")
 
@@ -364,21 +369,40 @@ func (p *Plugin) renderSidebarEntry(entry *FileEntry, selected bool, maxWidth in
 		return styles.ListItemNormal.Render(fmt.Sprintf("%s %s%s %s", status, indicator, displayName, styles.Muted.Render(countStr)))
 	}
 
+	// Submodules show their commit movement and dirty state after the path
+	subInfo := ""
+	if entry.Submodule != nil {
+		subInfo = entry.Submodule.Summary()
+	}
+
 	// Path - truncate if needed
 	path := entry.Path
 	availableWidth := maxWidth - 2 // status + space
+	if subInfo != "" {
+		if ansi.StringWidth(subInfo)+12 > availableWidth {
+			subInfo = ""
+		} else {
+			availableWidth -= ansi.StringWidth(subInfo) + 1
+		}
+	}
 	if len(path) > availableWidth && availableWidth > 3 {
 		path = "…" + path[len(path)-availableWidth+1:]
 	}
 
 	if selected {
 		plainLine := fmt.Sprintf("%s %s", string(entry.Status), path)
-		if len(plainLine) < maxWidth {
-			plainLine += strings.Repeat(" ", maxWidth-len(plainLine))
+		if subInfo != "" {
+			plainLine += " " + subInfo
+		}
+		if w := ansi.StringWidth(plainLine); w < maxWidth {
+			plainLine += strings.Repeat(" ", maxWidth-w)
 		}
 		return styles.ListItemSelected.Render(plainLine)
 	}
 
+	if subInfo != "" {
+		return styles.ListItemNormal.Render(fmt.Sprintf("%s %s %s", status, path, styles.Muted.Render(subInfo)))
+	}
 	return styles.ListItemNormal.Render(fmt.Sprintf("%s %s", status, path))
 }
 
@@ -407,6 +431,10 @@ func (p *Plugin) renderRecentCommits(currentY *int, maxVisible int) string {
 			header = fmt.Sprintf("Recent Commits %s", styles.StatusModified.Render(status))
 		}
 	}
+	// Bisect progress indicator
+	if summary := p.bisectState.Summary(); summary != "" {
+		header += " " + styles.StatusModified.Render(summary)
+	}
 	// Add graph indicator if enabled
 	if p.showCommitGraph {
 		header += " " + styles.Muted.Render("[graph]")
@@ -483,11 +511,16 @@ func (p *Plugin) renderRecentCommits(currentY *int, maxVisible int) string {
 		}
 
 		// Push indicator: ↑ for unpushed, nothing for pushed
-		var indicator string
-		if !commit.Pushed {
+		// During bisect the column shows verdicts and the suspect range instead
+		var indicator, plainIndicator string
+		if bisectStyled, bisectPlain, ok := p.bisectIndicator(commit.Hash); ok {
+			indicator, plainIndicator = bisectStyled, bisectPlain
+		} else if !commit.Pushed {
 			indicator = styles.StatusModified.Render("↑") + " "
+			plainIndicator = "↑ "
 		} else {
 			indicator = "  " // Two spaces to align with indicator
+			plainIndicator = "  "
 		}
 
 		// Format: "[graph] ↑ abc1234 commit message..."
@@ -506,10 +539,6 @@ func (p *Plugin) renderRecentCommits(currentY *int, maxVisible int) string {
 		p.mouseHandler.HitMap.AddRect(regionCommit, 1, *currentY, p.sidebarWidth-3, 1, i)
 
 		if selected {
-			plainIndicator := "  "
-			if !commit.Pushed {
-				plainIndicator = "↑ "
-			}
 			// For selected lines, include graph prefix without styling (will be styled by selection)
 			graphPlain := ""
 			if graphStr != "" {
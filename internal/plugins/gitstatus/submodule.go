@@ -0,0 +1,151 @@
+package gitstatus
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SubmoduleState describes a submodule entry in the status tree.
+type SubmoduleState struct {
+	CommitChanged    bool   // Checked-out commit differs from the recorded one
+	TrackedChanges   bool   // Submodule has modified tracked files
+	UntrackedChanges bool   // Submodule has untracked files
+	Recorded         string // Commit recorded in HEAD
+	Index            string // Commit recorded in the index (differs from Recorded when the pointer is staged)
+	Checkout         string // Commit checked out in the submodule
+	Ahead            int    // Commits in Checkout not in Index
+	Behind           int    // Commits in Index not in Checkout
+}
+
+// Dirty reports whether the submodule has uncommitted changes of its own.
+func (s *SubmoduleState) Dirty() bool {
+	return s.TrackedChanges || s.UntrackedChanges
+}
+
+// Summary returns a compact description like "a1b2c3d→e4f5a6b ↑2 dirty".
+func (s *SubmoduleState) Summary() string {
+	var parts []string
+	if s.CommitChanged && s.Checkout != "" {
+		parts = append(parts, shortHash(s.Index)+"→"+shortHash(s.Checkout))
+	}
+	if s.Ahead > 0 {
+		parts = append(parts, "↑"+strconv.Itoa(s.Ahead))
+	}
+	if s.Behind > 0 {
+		parts = append(parts, "↓"+strconv.Itoa(s.Behind))
+	}
+	if s.TrackedChanges {
+		parts = append(parts, "modified")
+	} else if s.UntrackedChanges {
+		parts = append(parts, "untracked")
+	}
+	return strings.Join(parts, " ")
+}
+
+// parseSubmoduleField parses the porcelain v2 <sub> field ("N..." or
+// "S<c><m><u>"). Returns nil for regular files.
+func parseSubmoduleField(sub string) *SubmoduleState {
+	if len(sub) != 4 || sub[0] != 'S' {
+		return nil
+	}
+	return &SubmoduleState{
+		CommitChanged:    sub[1] == 'C',
+		TrackedChanges:   sub[2] == 'M',
+		UntrackedChanges: sub[3] == 'U',
+	}
+}
+
+// loadSubmoduleCommit fills in the checked-out commit and its distance from
+// the recorded commit.
+func loadSubmoduleCommit(workDir, path string, s *SubmoduleState) {
+	subDir := filepath.Join(workDir, path)
+	out, err := runGit(subDir, "rev-parse", "HEAD")
+	if err != nil {
+		return
+	}
+	s.Checkout = strings.TrimSpace(out)
+	if !s.CommitChanged || s.Index == "" || isZeroHash(s.Index) {
+		return
+	}
+	out, err = runGit(subDir, "rev-list", "--left-right", "--count", s.Index+"..."+s.Checkout)
+	if err != nil {
+		return // Recorded commit not fetched in the submodule
+	}
+	fields := strings.Fields(out)
+	if len(fields) == 2 {
+		s.Behind, _ = strconv.Atoi(fields[0])
+		s.Ahead, _ = strconv.Atoi(fields[1])
+	}
+}
+
+func isZeroHash(hash string) bool {
+	return strings.Trim(hash, "0") == ""
+}
+
+// Submodule is a submodule listed by `git submodule status`.
+type Submodule struct {
+	Path        string
+	Commit      string // Checked-out commit, or the recorded one when uninitialized
+	Describe    string // Output of git describe for the commit, if any
+	Initialized bool
+	OutOfSync   bool // Checked-out commit differs from the recorded one
+	Conflict    bool // Merge conflict on the submodule pointer
+}
+
+// GetSubmodules lists the repository's submodules, including uninitialized ones.
+func GetSubmodules(workDir string) ([]*Submodule, error) {
+	cmd := exec.Command("git", "submodule", "status")
+	cmd.Dir = workDir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, &RemoteError{Output: string(output), Err: err}
+	}
+	return parseSubmoduleStatus(string(output)), nil
+}
+
+// parseSubmoduleStatus parses `git submodule status` lines:
+// "<flag><sha1> <path>[ (<describe>)]" where flag is ' ', '-', '+' or 'U'.
+func parseSubmoduleStatus(output string) []*Submodule {
+	var subs []*Submodule
+	for _, line := range strings.Split(output, "\n") {
+		if len(line) < 2 {
+			continue
+		}
+		flag := line[0]
+		hash, rest, ok := strings.Cut(line[1:], " ")
+		if !ok {
+			continue
+		}
+		path, describe := rest, ""
+		if idx := strings.LastIndex(rest, " ("); idx >= 0 && strings.HasSuffix(rest, ")") {
+			path, describe = rest[:idx], rest[idx+2:len(rest)-1]
+		}
+		subs = append(subs, &Submodule{
+			Path:        path,
+			Commit:      hash,
+			Describe:    describe,
+			Initialized: flag != '-',
+			OutOfSync:   flag == '+',
+			Conflict:    flag == 'U',
+		})
+	}
+	return subs
+}
+
+// UpdateSubmodule checks out the recorded commit in a submodule. With init,
+// the submodule (and any nested ones) is cloned first if needed.
+func UpdateSubmodule(workDir, path string, init bool) error {
+	args := []string{"submodule", "update"}
+	if init {
+		args = append(args, "--init", "--recursive")
+	}
+	args = append(args, "--", path)
+	return runRemoteCmd(workDir, args...)
+}
+
+// StageSubmodulePointer records the submodule's checked-out commit in the index.
+func StageSubmodulePointer(workDir, path string) error {
+	return runRemoteCmd(workDir, "add", "--", path)
+}
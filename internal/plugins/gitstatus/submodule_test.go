@@ -0,0 +1,283 @@
+package gitstatus
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marcus/sidecar/internal/config"
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+func TestParseSubmoduleField(t *testing.T) {
+	if s := parseSubmoduleField("N..."); s != nil {
+		t.Errorf("regular file parsed as submodule: %+v", s)
+	}
+	s := parseSubmoduleField("SC.U")
+	if s == nil {
+		t.Fatal("expected submodule state")
+	}
+	if !s.CommitChanged || s.TrackedChanges || !s.UntrackedChanges {
+		t.Errorf("flags = %+v", s)
+	}
+	if !s.Dirty() {
+		t.Error("expected untracked changes to count as dirty")
+	}
+}
+
+func TestParseOrdinaryEntry_Submodule(t *testing.T) {
+	tree := &FileTree{}
+	line := "1 .M SC.. 160000 160000 160000 1111111111111111111111111111111111111111 2222222222222222222222222222222222222222 vendor/lib"
+	entry := tree.parseOrdinaryEntry(line)
+	if entry == nil || entry.Submodule == nil {
+		t.Fatalf("expected submodule entry, got %+v", entry)
+	}
+	if entry.Submodule.Recorded != strings.Repeat("1", 40) || entry.Submodule.Index != strings.Repeat("2", 40) {
+		t.Errorf("recorded/index = %s/%s", entry.Submodule.Recorded, entry.Submodule.Index)
+	}
+
+	regular := tree.parseOrdinaryEntry("1 .M N... 100644 100644 100644 abc abc file.go")
+	if regular == nil || regular.Submodule != nil {
+		t.Errorf("expected regular entry, got %+v", regular)
+	}
+}
+
+func TestSubmoduleStateSummary(t *testing.T) {
+	s := &SubmoduleState{
+		CommitChanged:  true,
+		TrackedChanges: true,
+		Index:          "aaaaaaaaaaaa",
+		Checkout:       "bbbbbbbbbbbb",
+		Ahead:          2,
+		Behind:         1,
+	}
+	if got, want := s.Summary(), "aaaaaaa→bbbbbbb ↑2 ↓1 modified"; got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+	if got := (&SubmoduleState{UntrackedChanges: true}).Summary(); got != "untracked" {
+		t.Errorf("Summary() = %q, want untracked", got)
+	}
+}
+
+func TestParseSubmoduleStatus(t *testing.T) {
+	output := " 1111111111111111111111111111111111111111 libs/a (v1.2.0)\n" +
+		"-2222222222222222222222222222222222222222 libs/b\n" +
+		"+3333333333333333333333333333333333333333 libs/with space (heads/main)\n" +
+		"U4444444444444444444444444444444444444444 libs/d\n"
+
+	subs := parseSubmoduleStatus(output)
+	if len(subs) != 4 {
+		t.Fatalf("got %d submodules, want 4", len(subs))
+	}
+	if subs[0].Path != "libs/a" || subs[0].Describe != "v1.2.0" || !subs[0].Initialized || subs[0].OutOfSync {
+		t.Errorf("subs[0] = %+v", subs[0])
+	}
+	if subs[1].Path != "libs/b" || subs[1].Initialized {
+		t.Errorf("subs[1] = %+v", subs[1])
+	}
+	if subs[2].Path != "libs/with space" || !subs[2].OutOfSync {
+		t.Errorf("subs[2] = %+v", subs[2])
+	}
+	if !subs[3].Conflict {
+		t.Errorf("subs[3] = %+v", subs[3])
+	}
+}
+
+// initSubmoduleRepo creates a repo with lib/ as a submodule of a separate
+// library repository. Returns the superproject dir, its git helper and the
+// library dir.
+func initSubmoduleRepo(t *testing.T) (string, func(args ...string) string, string) {
+	t.Helper()
+	libDir, libGit := initUndoRepo(t)
+	libGit("commit", "-q", "--allow-empty", "-m", "lib second")
+
+	dir, git := initUndoRepo(t)
+	// File-protocol submodules are blocked by default since git 2.38.1
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "protocol.file.allow")
+	t.Setenv("GIT_CONFIG_VALUE_0", "always")
+	git("submodule", "add", "-q", libDir, "lib")
+	git("commit", "-q", "-m", "add lib")
+	return dir, git, libDir
+}
+
+func TestFileTreeRefresh_Submodule(t *testing.T) {
+	dir, _, _ := initSubmoduleRepo(t)
+	subDir := filepath.Join(dir, "lib")
+
+	// Move the submodule back one commit and dirty it
+	cmd := exec.Command("git", "checkout", "-q", "HEAD~1")
+	cmd.Dir = subDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("checkout: %v (%s)", err, out)
+	}
+	writeFile(t, subDir, "a.txt", "changed\n")
+
+	tree := NewFileTree(dir)
+	if err := tree.Refresh(); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if len(tree.Modified) != 1 {
+		t.Fatalf("modified = %d entries, want 1", len(tree.Modified))
+	}
+	s := tree.Modified[0].Submodule
+	if s == nil {
+		t.Fatal("expected submodule state on lib entry")
+	}
+	if !s.CommitChanged || !s.TrackedChanges {
+		t.Errorf("flags = %+v", s)
+	}
+	if s.Behind != 1 || s.Ahead != 0 {
+		t.Errorf("ahead/behind = %d/%d, want 0/1", s.Ahead, s.Behind)
+	}
+	if s.Checkout == "" || s.Checkout == s.Index {
+		t.Errorf("checkout = %q, index = %q", s.Checkout, s.Index)
+	}
+
+	// Staging the pointer records the checked-out commit
+	if err := StageSubmodulePointer(dir, "lib"); err != nil {
+		t.Fatalf("StageSubmodulePointer: %v", err)
+	}
+	if err := tree.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if len(tree.Staged) != 1 || tree.Staged[0].Submodule == nil {
+		t.Fatalf("expected staged submodule pointer, got %+v", tree.Staged)
+	}
+	if tree.Staged[0].Submodule.Index != s.Checkout {
+		t.Errorf("staged pointer = %s, want %s", tree.Staged[0].Submodule.Index, s.Checkout)
+	}
+}
+
+func TestGetSubmoduleDiff(t *testing.T) {
+	dir, _, _ := initSubmoduleRepo(t)
+	writeFile(t, filepath.Join(dir, "lib"), "a.txt", "changed\n")
+
+	// Plain diffs keep git's default pointer summary
+	diff, err := GetDiff(dir, "lib", false)
+	if err != nil {
+		t.Fatalf("GetDiff: %v", err)
+	}
+	if !strings.Contains(diff, "Subproject commit") || strings.Contains(diff, "+changed") {
+		t.Errorf("GetDiff = %q, want pointer summary only", diff)
+	}
+
+	diff, err = GetSubmoduleDiff(dir, "lib", false)
+	if err != nil {
+		t.Fatalf("GetSubmoduleDiff: %v", err)
+	}
+	if !strings.Contains(diff, "+changed") {
+		t.Errorf("GetSubmoduleDiff = %q, want the submodule's own changes", diff)
+	}
+}
+
+func TestUpdateSubmodule(t *testing.T) {
+	dir, git, _ := initSubmoduleRepo(t)
+	recorded := git("rev-parse", "HEAD:lib")
+
+	git("submodule", "deinit", "-q", "-f", "lib")
+	subs, err := GetSubmodules(dir)
+	if err != nil {
+		t.Fatalf("GetSubmodules: %v", err)
+	}
+	if len(subs) != 1 || subs[0].Initialized {
+		t.Fatalf("expected uninitialized lib, got %+v", subs)
+	}
+
+	if err := UpdateSubmodule(dir, "lib", true); err != nil {
+		t.Fatalf("UpdateSubmodule(init): %v", err)
+	}
+	subs, err = GetSubmodules(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !subs[0].Initialized || subs[0].OutOfSync || subs[0].Commit != recorded {
+		t.Errorf("after init: %+v (recorded %s)", subs[0], recorded)
+	}
+}
+
+func TestEnterAndLeaveSubmodule(t *testing.T) {
+	dir, _, _ := initSubmoduleRepo(t)
+
+	p := New()
+	if err := p.Init(&plugin.Context{WorkDir: dir, Config: config.Default()}); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	root := p.repoRoot
+	p.recentCommits = []*Commit{{Hash: "parent"}}
+
+	if cmd := p.enterSubmodule("lib"); cmd == nil {
+		t.Fatal("expected reload command")
+	}
+	if !p.inSubmodule() || p.submoduleLabel() != "lib" {
+		t.Fatalf("inSubmodule=%v label=%q", p.inSubmodule(), p.submoduleLabel())
+	}
+	if p.repoRoot != filepath.Join(root, "lib") || p.tree.workDir != p.repoRoot {
+		t.Errorf("repoRoot = %q, tree = %q", p.repoRoot, p.tree.workDir)
+	}
+	if p.recentCommits != nil {
+		t.Error("expected parent history to be cleared")
+	}
+	if p.watcher != nil {
+		p.watcher.Stop()
+	}
+
+	p.leaveSubmodule()
+	if p.inSubmodule() || p.repoRoot != root {
+		t.Errorf("after leave: inSubmodule=%v repoRoot=%q", p.inSubmodule(), p.repoRoot)
+	}
+}
+
+func TestEnterSubmodule_Uninitialized(t *testing.T) {
+	dir, git, _ := initSubmoduleRepo(t)
+	git("submodule", "deinit", "-q", "-f", "lib")
+
+	p := New()
+	if err := p.Init(&plugin.Context{WorkDir: dir, Config: config.Default()}); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	if cmd := p.enterSubmodule("lib"); cmd == nil {
+		t.Fatal("expected a toast command")
+	}
+	if p.inSubmodule() {
+		t.Error("should not enter an uninitialized submodule")
+	}
+}
+
+func TestSwitchRepo_DropsLoadsFromPreviousRepo(t *testing.T) {
+	dir, _, _ := initSubmoduleRepo(t)
+
+	p := New()
+	if err := p.Init(&plugin.Context{WorkDir: dir, Config: config.Default()}); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	writeFile(t, dir, "a.txt", "changed\n")
+	writeFile(t, filepath.Join(dir, "lib"), "a.txt", "changed\n")
+	_ = p.tree.Refresh()
+	gen := p.repoGen
+	stale := p.refresh()
+
+	p.enterSubmodule("lib")
+	if p.watcher != nil {
+		p.watcher.Stop()
+	}
+	p.Update(RecentCommitsLoadedMsg{Epoch: p.ctx.Epoch, RepoGen: gen, Commits: []*Commit{{Hash: "parent"}}})
+	if p.recentCommits != nil {
+		t.Error("commits loaded for the parent repo were applied inside the submodule")
+	}
+	if msg := stale(); msg.(RefreshDoneMsg).RepoGen == p.repoGen {
+		t.Error("refresh issued before the switch carries the new generation")
+	}
+
+	// Leaving restores the cursor once the parent tree has loaded
+	p.leaveSubmodule()
+	if p.watcher != nil {
+		p.watcher.Stop()
+	}
+	p.Update(p.refresh()())
+	entries := p.tree.AllEntries()
+	if p.cursor >= len(entries) || entries[p.cursor].Path != "lib" {
+		t.Errorf("cursor = %d, want the lib entry in %d entries", p.cursor, len(entries))
+	}
+}
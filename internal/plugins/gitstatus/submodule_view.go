@@ -0,0 +1,429 @@
+package gitstatus
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/marcus/sidecar/internal/app"
+	"github.com/marcus/sidecar/internal/modal"
+	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/styles"
+	"github.com/marcus/sidecar/internal/ui"
+)
+
+const submoduleItemPrefix = "submodule-item-"
+
+func submoduleItemID(idx int) string {
+	return fmt.Sprintf("%s%d", submoduleItemPrefix, idx)
+}
+
+func parseSubmoduleItem(id string) (int, bool) {
+	if !strings.HasPrefix(id, submoduleItemPrefix) {
+		return 0, false
+	}
+	idx, err := strconv.Atoi(strings.TrimPrefix(id, submoduleItemPrefix))
+	if err != nil {
+		return 0, false
+	}
+	return idx, true
+}
+
+// openSubmodules opens the submodules modal.
+func (p *Plugin) openSubmodules() tea.Cmd {
+	p.submodulesReturnMode = p.viewMode
+	p.viewMode = ViewModeSubmodules
+	p.submoduleCursor = 0
+	p.submodules = nil
+	p.clearSubmodulesModal()
+	return p.loadSubmodules()
+}
+
+func (p *Plugin) closeSubmodules() {
+	p.viewMode = p.submodulesReturnMode
+	p.clearSubmodulesModal()
+}
+
+func (p *Plugin) clearSubmodulesModal() {
+	p.submodulesModal = nil
+	p.submodulesModalWidth = 0
+}
+
+// selectedSubmodule returns the submodule under the cursor, or nil.
+func (p *Plugin) selectedSubmodule() *Submodule {
+	if p.submoduleCursor < 0 || p.submoduleCursor >= len(p.submodules) {
+		return nil
+	}
+	return p.submodules[p.submoduleCursor]
+}
+
+// inSubmodule reports whether the plugin is showing a nested repository.
+func (p *Plugin) inSubmodule() bool {
+	return len(p.repoStack) > 0
+}
+
+// submoduleLabel returns the nested repository path relative to the
+// outermost repository, e.g. "vendor/lib".
+func (p *Plugin) submoduleLabel() string {
+	if !p.inSubmodule() {
+		return ""
+	}
+	rel, err := filepath.Rel(p.repoStack[0], p.repoRoot)
+	if err != nil {
+		return filepath.Base(p.repoRoot)
+	}
+	return rel
+}
+
+// enterSubmodule switches the plugin into the submodule at path (relative
+// to the current repository) as a nested git context.
+func (p *Plugin) enterSubmodule(path string) tea.Cmd {
+	subRoot := filepath.Join(p.repoRoot, strings.TrimSuffix(path, "/"))
+	if _, err := os.Stat(filepath.Join(subRoot, ".git")); err != nil {
+		return func() tea.Msg {
+			return app.ToastMsg{Message: "Submodule not initialized (press i to init)", Duration: 3 * time.Second, IsError: true}
+		}
+	}
+	p.repoStack = append(p.repoStack, p.repoRoot)
+	return p.switchRepo(subRoot)
+}
+
+// leaveSubmodule returns to the parent repository.
+func (p *Plugin) leaveSubmodule() tea.Cmd {
+	if !p.inSubmodule() {
+		return nil
+	}
+	child := p.repoRoot
+	parent := p.repoStack[len(p.repoStack)-1]
+	p.repoStack = p.repoStack[:len(p.repoStack)-1]
+	cmd := p.switchRepo(parent)
+
+	// Put the cursor back on the submodule we came from once the tree loads
+	if rel, err := filepath.Rel(parent, child); err == nil {
+		p.restoreCursorPath = rel
+	}
+	return cmd
+}
+
+// switchRepo points the plugin at another repository root, resetting
+// per-repository state and reloading status, history and the watcher.
+// Bumping repoGen drops loads still in flight for the previous root.
+func (p *Plugin) switchRepo(root string) tea.Cmd {
+	if p.watcher != nil {
+		p.watcher.Stop()
+		p.watcher = nil
+	}
+	p.repoGen++
+	p.repoRoot = root
+	p.tree = NewFileTree(root)
+	p.restoreCursorPath = ""
+
+	p.viewMode = ViewModeStatus
+	p.activePane = PaneSidebar
+	p.cursor = 0
+	p.scrollOff = 0
+	p.recentCommits = nil
+	p.commitScrollOff = 0
+	p.moreCommitsAvailable = false
+	p.loadingMoreCommits = false
+	p.commitGraphLines = nil
+	p.pushStatus = nil
+	p.previewCommit = nil
+	p.selectedDiffFile = ""
+	p.diffPaneParsedDiff = nil
+	p.historyFilterActive = false
+	p.historyFilterAuthor = ""
+	p.historyFilterPath = ""
+	p.filteredCommits = nil
+	p.historySearchState = nil
+	p.bisectState = nil
+	p.remotes = nil
+	p.branches = nil
+	p.pushRemote = ""
+	p.pullRemote = ""
+
+	return tea.Batch(
+		p.refresh(),
+		p.startWatcher(),
+		p.loadRecentCommits(),
+		p.loadBisectState(),
+	)
+}
+
+// updateSubmodules handles key events in the submodules modal.
+func (p *Plugin) updateSubmodules(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
+	p.ensureSubmodulesModal()
+	if p.submodulesModal == nil {
+		return p, nil
+	}
+
+	switch msg.String() {
+	case "esc", "q":
+		p.closeSubmodules()
+		return p, nil
+	case "j", "down":
+		p.moveSubmoduleCursor(1)
+		return p, nil
+	case "k", "up":
+		p.moveSubmoduleCursor(-1)
+		return p, nil
+	case "enter":
+		if sub := p.selectedSubmodule(); sub != nil {
+			return p, p.enterSubmodule(sub.Path)
+		}
+		return p, nil
+	case "i":
+		if sub := p.selectedSubmodule(); sub != nil {
+			return p, p.doSubmoduleUpdate(sub.Path, true)
+		}
+		return p, nil
+	case "u":
+		if sub := p.selectedSubmodule(); sub != nil {
+			return p, p.doSubmoduleUpdate(sub.Path, false)
+		}
+		return p, nil
+	case "s":
+		if sub := p.selectedSubmodule(); sub != nil {
+			return p, p.doStageSubmodule(sub.Path)
+		}
+		return p, nil
+	}
+
+	action, cmd := p.submodulesModal.HandleKey(msg)
+	if action == "cancel" {
+		p.closeSubmodules()
+		return p, nil
+	}
+	return p, cmd
+}
+
+// handleSubmodulesMouse processes mouse events in the submodules modal.
+func (p *Plugin) handleSubmodulesMouse(msg tea.MouseMsg) (plugin.Plugin, tea.Cmd) {
+	p.ensureSubmodulesModal()
+	if p.submodulesModal == nil {
+		return p, nil
+	}
+
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		p.moveSubmoduleCursor(-1)
+		return p, nil
+	case tea.MouseButtonWheelDown:
+		p.moveSubmoduleCursor(1)
+		return p, nil
+	}
+
+	action := p.submodulesModal.HandleMouse(msg, p.mouseHandler)
+	if action == "cancel" {
+		p.closeSubmodules()
+		return p, nil
+	}
+	if idx, ok := parseSubmoduleItem(action); ok {
+		if idx == p.submoduleCursor {
+			// Click on the selected submodule steps into it
+			if sub := p.selectedSubmodule(); sub != nil {
+				return p, p.enterSubmodule(sub.Path)
+			}
+		}
+		p.submoduleCursor = idx
+	}
+	return p, nil
+}
+
+func (p *Plugin) moveSubmoduleCursor(delta int) {
+	if len(p.submodules) == 0 {
+		return
+	}
+	p.submoduleCursor += delta
+	if p.submoduleCursor < 0 {
+		p.submoduleCursor = 0
+	}
+	if p.submoduleCursor >= len(p.submodules) {
+		p.submoduleCursor = len(p.submodules) - 1
+	}
+}
+
+// loadSubmodules loads the submodule list.
+func (p *Plugin) loadSubmodules() tea.Cmd {
+	epoch := p.ctx.Epoch
+	workDir := p.repoRoot
+	return func() tea.Msg {
+		subs, err := GetSubmodules(workDir)
+		return SubmodulesLoadedMsg{Epoch: epoch, Submodules: subs, Err: err}
+	}
+}
+
+// doSubmoduleUpdate checks out the recorded commit, initializing if asked.
+func (p *Plugin) doSubmoduleUpdate(path string, init bool) tea.Cmd {
+	workDir := p.repoRoot
+	p.submoduleBusy = path
+	return func() tea.Msg {
+		if err := UpdateSubmodule(workDir, path, init); err != nil {
+			return SubmoduleOpDoneMsg{Err: err}
+		}
+		verb := "Updated "
+		if init {
+			verb = "Initialized "
+		}
+		return SubmoduleOpDoneMsg{Message: verb + path}
+	}
+}
+
+// doStageSubmodule stages the submodule's checked-out commit as the new pointer.
+func (p *Plugin) doStageSubmodule(path string) tea.Cmd {
+	workDir := p.repoRoot
+	return func() tea.Msg {
+		if err := StageSubmodulePointer(workDir, path); err != nil {
+			return SubmoduleOpDoneMsg{Err: err}
+		}
+		return SubmoduleOpDoneMsg{Message: "Staged " + path + " pointer"}
+	}
+}
+
+// submoduleStateFor returns the status-tree state of a submodule, if changed.
+func (p *Plugin) submoduleStateFor(path string) *SubmoduleState {
+	if p.tree == nil {
+		return nil
+	}
+	for _, e := range p.tree.AllEntries() {
+		if e.Submodule != nil && e.Path == path {
+			return e.Submodule
+		}
+	}
+	return nil
+}
+
+// ensureSubmodulesModal builds/rebuilds the submodules modal.
+func (p *Plugin) ensureSubmodulesModal() {
+	modalW := p.width - 10
+	if modalW > 90 {
+		modalW = 90
+	}
+	if modalW < 40 {
+		modalW = 40
+	}
+	if p.submodulesModal != nil && p.submodulesModalWidth == modalW {
+		return
+	}
+	p.submodulesModalWidth = modalW
+
+	p.submodulesModal = modal.New("Submodules",
+		modal.WithWidth(modalW),
+		modal.WithHints(false),
+	).
+		AddSection(p.submodulesListSection()).
+		AddSection(modal.Spacer()).
+		AddSection(p.submodulesHintsSection())
+}
+
+func (p *Plugin) submodulesListSection() modal.Section {
+	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
+		if p.submodules == nil {
+			return modal.RenderedSection{Content: styles.Muted.Render("  Loading submodules...")}
+		}
+		if len(p.submodules) == 0 {
+			return modal.RenderedSection{Content: styles.Muted.Render("  No submodules in this repository.")}
+		}
+
+		var sb strings.Builder
+		focusables := make([]modal.FocusableInfo, 0, len(p.submodules))
+		for i, sub := range p.submodules {
+			if i > 0 {
+				sb.WriteString("\n")
+			}
+
+			state, stateStyle := "", styles.Muted
+			switch {
+			case !sub.Initialized:
+				state, stateStyle = "not initialized", styles.StatusUntracked
+			case sub.Conflict:
+				state, stateStyle = "conflict", styles.StatusDeleted
+			default:
+				if s := p.submoduleStateFor(sub.Path); s != nil {
+					state = s.Summary()
+				} else if sub.OutOfSync {
+					state = "out of sync"
+				}
+				if state != "" {
+					stateStyle = styles.StatusModified
+				}
+			}
+
+			hashLabel := shortHash(sub.Commit)
+			if sub.Describe != "" {
+				hashLabel += " (" + sub.Describe + ")"
+			}
+			line := fmt.Sprintf("  %s  %s", sub.Path, hashLabel)
+			if state != "" {
+				line += "  " + state
+			}
+			line = truncateStr(line, contentWidth)
+
+			itemID := submoduleItemID(i)
+			if i == p.submoduleCursor || itemID == hoverID {
+				sb.WriteString(styles.ListItemSelected.Render(line))
+			} else {
+				rendered := "  " + styles.Body.Render(sub.Path) + "  " + styles.Muted.Render(hashLabel)
+				if state != "" {
+					rendered += "  " + stateStyle.Render(state)
+				}
+				if ansi.StringWidth(rendered) > contentWidth {
+					rendered = styles.Body.Render(line)
+				}
+				sb.WriteString(rendered)
+			}
+			focusables = append(focusables, modal.FocusableInfo{
+				ID:      itemID,
+				OffsetY: i,
+				Width:   ansi.StringWidth(line),
+				Height:  1,
+			})
+		}
+		return modal.RenderedSection{Content: sb.String(), Focusables: focusables}
+	}, nil)
+}
+
+func (p *Plugin) submodulesHintsSection() modal.Section {
+	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
+		var lines []string
+		if p.submoduleBusy != "" {
+			lines = append(lines, styles.StatusInProgress.Render("Updating "+p.submoduleBusy+"..."))
+		}
+		lines = append(lines, styles.Muted.Render("Enter step in · i init · u update · s stage pointer · Esc close"))
+		return modal.RenderedSection{Content: strings.Join(lines, "\n")}
+	}, nil)
+}
+
+// renderSubmodules renders the submodules modal over the status view.
+func (p *Plugin) renderSubmodules() string {
+	background := p.renderThreePaneView()
+
+	p.ensureSubmodulesModal()
+	if p.submodulesModal == nil {
+		return background
+	}
+
+	modalContent := p.submodulesModal.Render(p.width, p.height, p.mouseHandler)
+	return ui.OverlayModal(background, modalContent, p.width, p.height)
+}
+
+// SubmodulesLoadedMsg is sent when the submodule list has been loaded.
+type SubmodulesLoadedMsg struct {
+	Epoch      uint64
+	Submodules []*Submodule
+	Err        error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m SubmodulesLoadedMsg) GetEpoch() uint64 { return m.Epoch }
+
+// SubmoduleOpDoneMsg is sent after a submodule update or pointer stage.
+type SubmoduleOpDoneMsg struct {
+	Message string
+	Err     error
+}
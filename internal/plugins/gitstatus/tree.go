@@ -36,8 +36,9 @@ type FileEntry struct {
 	OldPath    string // For renames
 	DiffStats  DiffStats
 	IsExpanded bool
-	IsFolder   bool         // True if this represents an untracked folder
-	Children   []*FileEntry // Files within this folder (when IsFolder is true)
+	IsFolder   bool            // True if this represents an untracked folder
+	Children   []*FileEntry    // Files within this folder (when IsFolder is true)
+	Submodule  *SubmoduleState // Non-nil when the path is a submodule
 }
 
 // DiffStats holds addition/deletion counts.
@@ -79,6 +80,9 @@ func (t *FileTree) Refresh() error {
 	// Get diff stats for all files
 	_ = temp.loadDiffStats() // Non-fatal: continue without stats
 
+	// Resolve checked-out commits for submodule entries
+	temp.loadSubmoduleCommits()
+
 	// Group untracked files by folder
 	temp.groupUntrackedFolders()
 
@@ -162,7 +166,12 @@ func (t *FileTree) parseOrdinaryEntry(line string) *FileEntry {
 	path := fields[8]
 
 	entry := &FileEntry{
-		Path: path,
+		Path:      path,
+		Submodule: parseSubmoduleField(fields[2]),
+	}
+	if entry.Submodule != nil {
+		entry.Submodule.Recorded = fields[6]
+		entry.Submodule.Index = fields[7]
 	}
 
 	// X = index status, Y = worktree status
@@ -239,9 +248,10 @@ func (t *FileTree) addEntry(entry *FileEntry) {
 		// File has both staged and unstaged changes
 		// Add a copy to modified list
 		modEntry := &FileEntry{
-			Path:     entry.Path,
-			Status:   entry.Status,
-			Unstaged: true,
+			Path:      entry.Path,
+			Status:    entry.Status,
+			Unstaged:  true,
+			Submodule: entry.Submodule,
 		}
 		t.Modified = append(t.Modified, modEntry)
 	}
@@ -310,11 +320,48 @@ func (t *FileTree) loadDiffStatsFor(staged bool) error {
 	return nil
 }
 
+// loadSubmoduleCommits resolves checkout and ahead/behind for submodule entries.
+func (t *FileTree) loadSubmoduleCommits() {
+	seen := make(map[*SubmoduleState]bool)
+	for _, e := range append(t.Staged, t.Modified...) {
+		if e.Submodule == nil || seen[e.Submodule] {
+			continue
+		}
+		seen[e.Submodule] = true
+		loadSubmoduleCommit(t.workDir, e.Path, e.Submodule)
+	}
+}
+
 // TotalCount returns the total number of changed files.
 func (t *FileTree) TotalCount() int {
 	return len(t.Staged) + len(t.Modified) + len(t.Untracked)
 }
 
+// workingPaths returns up to limit paths of files whose working tree
+// content differs from HEAD: unstaged and untracked files, skipping
+// deletions, submodules and folder groupings (their children are listed).
+func (t *FileTree) workingPaths(limit int) []string {
+	var paths []string
+	var add func(entries []*FileEntry)
+	add = func(entries []*FileEntry) {
+		for _, e := range entries {
+			if len(paths) >= limit {
+				return
+			}
+			switch {
+			case e.IsFolder:
+				add(e.Children)
+			case e.Status == StatusDeleted || e.Submodule != nil:
+			default:
+				paths = append(paths, e.Path)
+			}
+		}
+	}
+	add(t.Modified)
+	add(t.Untracked)
+	return paths
+}
+
 // groupUntrackedFolders groups untracked files that share a common top-level directory.
 // Files within a folder are collapsed into a single folder entry with Children.
 func (t *FileTree) groupUntrackedFolders() {
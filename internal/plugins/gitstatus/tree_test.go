@@ -2,6 +2,7 @@ package gitstatus
 
 import (
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -460,3 +461,22 @@ func TestTotalCount(t *testing.T) {
 		t.Errorf("expected 6, got %d", tree.TotalCount())
 	}
 }
+
+func TestFileTreeWorkingPaths(t *testing.T) {
+	tree := &FileTree{
+		Staged:   []*FileEntry{{Path: "staged-only.go", Staged: true}},
+		Modified: []*FileEntry{{Path: "a.go"}, {Path: "gone.go", Status: StatusDeleted}, {Path: "sub", Submodule: &SubmoduleState{}}},
+		Untracked: []*FileEntry{
+			{Path: "new/", IsFolder: true, Children: []*FileEntry{{Path: "new/x.go"}, {Path: "new/y.go"}}},
+			{Path: "z.txt"},
+		},
+	}
+	got := tree.workingPaths(10)
+	want := []string{"a.go", "new/x.go", "new/y.go", "z.txt"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("workingPaths = %v, want %v", got, want)
+	}
+	if got := tree.workingPaths(2); len(got) != 2 {
+		t.Errorf("limit ignored: %v", got)
+	}
+}
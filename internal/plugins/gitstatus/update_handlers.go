@@ -111,9 +111,8 @@ func (p *Plugin) updateStatus(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 	case "L":
 		// Open pull menu
 		if p.canPull() && !p.pullInProgress {
-			p.pullMenuReturnMode = p.viewMode
-			p.viewMode = ViewModePullMenu
-			p.pullSelectedIdx = 0
+			p.pullRemote = ""
+			return p, p.openPullMenu()
 		}
 
 	case "tab", "shift+tab":
@@ -195,7 +194,7 @@ func (p *Plugin) updateStatus(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 			if entry.IsFolder {
 				return p, p.loadFullFolderDiff(entry)
 			}
-			return p, p.loadDiff(entry.Path, entry.Staged, entry.Status)
+			return p, p.loadDiff(entry)
 		}
 		// For commits, focus the preview pane (same as l/right)
 		if p.cursorOnCommit() && p.previewCommit != nil {
@@ -205,6 +204,7 @@ func (p *Plugin) updateStatus(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 	case "enter":
 		// For folders: toggle expand/collapse
 		// For files: open in editor
+		// For submodules: step into the nested repository
 		// For commits: focus the preview pane
 		if p.cursorOnCommit() {
 			if p.previewCommit != nil {
@@ -218,6 +218,10 @@ func (p *Plugin) updateStatus(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 				// Reload diff for this folder
 				return p, p.autoLoadDiff()
 			}
+			if entry.Submodule != nil {
+				// Step into the submodule as a nested repository
+				return p, p.enterSubmodule(entry.Path)
+			}
 			return p, p.openFile(entry.Path)
 		}
 
@@ -280,12 +284,12 @@ func (p *Plugin) updateStatus(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 		}
 
 	case "P":
-		// Open push menu (following lazygit convention)
-		if p.canPush() && !p.pushInProgress {
-			p.pushMenuReturnMode = p.viewMode
-			p.viewMode = ViewModePushMenu
-			p.pushMenuFocus = 0
-			p.clearPushMenuModal()
+		// Open push menu (following lazygit convention). With nothing ahead
+		// of the upstream the menu still opens so another remote can be
+		// chosen with R; the push itself is gated on that remote.
+		if (p.canPush() || p.canPull()) && !p.pushInProgress {
+			p.pushRemote = ""
+			return p, p.openPushMenu()
 		}
 
 	case "y":
@@ -334,6 +338,32 @@ func (p *Plugin) updateStatus(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 		// Apply latest stash (non-destructive, stash entry preserved)
 		return p, p.doStashApply()
 
+	case "B":
+		// Open bisect modal (targets selected commit, or HEAD from file list)
+		return p, p.openBisect()
+
+	case "R":
+		// Open reflog browser
+		return p, p.openReflog()
+
+	case "alt+z":
+		// Undo last discard, hard reset or branch delete
+		return p, p.doUndo()
+
+	case "M":
+		// Open remotes pane
+		return p, p.openRemotes()
+
+	case "m":
+		// Open submodules modal
+		return p, p.openSubmodules()
+
+	case "backspace":
+		// Step out of a submodule back to its parent repository
+		if p.inSubmodule() {
+			return p, p.leaveSubmodule()
+		}
+
 	case "b":
 		// Open branch picker
 		p.branchReturnMode = p.viewMode
@@ -553,7 +583,7 @@ func (p *Plugin) updateStatusDiffPane(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 			p.diffCommitShortHash = ""
 			p.diffScroll = 0
 			p.diffLoaded = false
-			return p, p.loadDiff(entry.Path, entry.Staged, entry.Status)
+			return p, p.loadDiff(entry)
 		}
 	}
 
@@ -767,6 +797,10 @@ func (p *Plugin) updateCommit(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 	case "ctrl+s", "ctrl+enter":
 		return p, p.tryCommit()
 
+	case "ctrl+g":
+		// Generate message from staged diff via configured command
+		return p, p.doGenerateCommitMessage()
+
 	case "ctrl+a":
 		// Toggle amend mode (only if there are commits to amend and staged files)
 		if len(p.recentCommits) > 0 && p.tree.HasStagedFiles() {
@@ -840,6 +874,10 @@ func (p *Plugin) updatePushMenu(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 		return p.executePushMenuAction(1)
 	case "u":
 		return p.executePushMenuAction(2)
+	case "R":
+		p.pushRemote = cycleRemote(p.remotes, p.pushMenuRemote())
+		p.clearPushMenuModal()
+		return p, nil
 	}
 
 	switch msg.String() {
@@ -888,6 +926,12 @@ func (p *Plugin) updatePullMenu(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 		return p.executePullMenuAction(pullMenuOptionFFOnly)
 	case "a":
 		return p.executePullMenuAction(pullMenuOptionAutostash)
+	case "R":
+		idx := p.pullSelectedIdx
+		p.pullRemote = cycleRemote(p.remotes, p.pullMenuRemote())
+		p.clearPullModal()
+		p.pullSelectedIdx = idx
+		return p, nil
 	}
 
 	action, cmd := p.pullModal.HandleKey(msg)
@@ -909,12 +953,24 @@ func (p *Plugin) updatePullMenu(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 
 // executePullMenuAction executes the pull menu action by ID.
 func (p *Plugin) executePullMenuAction(actionID string) (plugin.Plugin, tea.Cmd) {
+	remote := p.pullMenuRemote()
+	if !p.canPullFrom(remote) {
+		return p, appmsg.ShowToast("No upstream to pull from; press R to choose a remote", 2*time.Second)
+	}
 	p.viewMode = p.pullMenuReturnMode
 	p.pullInProgress = true
 	p.pullError = ""
 	p.pullSuccess = false
 	p.clearPullModal()
 
+	// Pulling from a remote other than the upstream's (or a branch without
+	// upstream) names the remote explicitly
+	if remote != "" && remote != p.upstreamRemoteName() {
+		p.pullRemote = ""
+		return p, p.doPullFromRemote(remote, actionID)
+	}
+	p.pullRemote = ""
+
 	switch actionID {
 	case pullMenuOptionMerge:
 		return p, p.doPull()
@@ -985,6 +1041,9 @@ func (p *Plugin) dismissPullConflict() (plugin.Plugin, tea.Cmd) {
 
 // executePushMenuAction executes the push menu action at the given index.
 func (p *Plugin) executePushMenuAction(idx int) (plugin.Plugin, tea.Cmd) {
+	if remote := p.pushMenuRemote(); !p.canPushTo(remote) {
+		return p, appmsg.ShowToast("Nothing to push to "+remote, 2*time.Second)
+	}
 	p.viewMode = p.pushMenuReturnMode
 	p.pushInProgress = true
 	p.pushError = ""
@@ -1002,6 +1061,12 @@ func (p *Plugin) executePushMenuAction(idx int) (plugin.Plugin, tea.Cmd) {
 		}
 	}
 
+	// A specific remote was chosen: push there instead of the default
+	if remote := p.pushRemote; remote != "" {
+		p.pushRemote = ""
+		return p, p.doPushToRemote(remote, idx)
+	}
+
 	switch idx {
 	case 0:
 		return p, p.doPush(false)
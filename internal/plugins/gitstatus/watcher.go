@@ -2,6 +2,7 @@ package gitstatus
 
 import (
 	"log/slog"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -33,7 +34,7 @@ func NewWatcher(workDir string) (*Watcher, error) {
 	}
 
 	// Watch .git/index for staging changes
-	gitDir := filepath.Join(workDir, ".git")
+	gitDir := resolveGitDir(workDir)
 	indexPath := filepath.Join(gitDir, "index")
 	headPath := filepath.Join(gitDir, "HEAD")
 	refsDir := filepath.Join(gitDir, "refs")
@@ -59,6 +60,19 @@ func NewWatcher(workDir string) (*Watcher, error) {
 	return w, nil
 }
 
+// resolveGitDir returns the repository's git directory. Submodules and linked
+// worktrees use a .git file pointing elsewhere, so ask git rather than
+// assuming <workDir>/.git.
+func resolveGitDir(workDir string) string {
+	cmd := exec.Command("git", "rev-parse", "--absolute-git-dir")
+	cmd.Dir = workDir
+	out, err := cmd.Output()
+	if err != nil || strings.TrimSpace(string(out)) == "" {
+		return filepath.Join(workDir, ".git")
+	}
+	return strings.TrimSpace(string(out))
+}
+
 // Events returns the channel that receives change notifications.
 func (w *Watcher) Events() <-chan struct{} {
 	return w.events
@@ -2,6 +2,8 @@ package workspace
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log/slog"
@@ -436,7 +438,9 @@ func (p *Plugin) buildAgentCommand(agentType AgentType, wt *Worktree, skipPerms
 
 	// Determine context to pass to agent
 	var ctx string
-	if prompt != nil {
+	if prompt != nil && prompt.Raw {
+		ctx = prompt.Body
+	} else if prompt != nil {
 		// Use prompt template with ticket expansion
 		ctx = ExpandPromptTemplate(prompt.Body, wt.TaskID)
 	} else if wt.TaskID != "" {
@@ -484,38 +488,45 @@ fi
 	// Use a heredoc with quoted delimiter to prevent ALL shell expansion.
 	// This safely handles backticks, $variables, quotes, newlines, etc.
 	// The prompt is embedded directly in the script, not read from a file.
+	// Prompts can carry transcripts and diffs, so the delimiter is random
+	// and never appears in the prompt; a matching line would end the
+	// heredoc and run the rest as shell.
+	eof, err := heredocDelimiter(prompt)
+	if err != nil {
+		return "", err
+	}
 	var script string
 	switch agentType {
 	case AgentAider:
 		// aider uses --message flag
 		script = fmt.Sprintf(`#!/bin/bash
 %s
-%s --message "$(cat <<'SIDECAR_PROMPT_EOF'
+%s --message "$(cat <<'%s'
+%s
 %s
-SIDECAR_PROMPT_EOF
 )"
 rm -f %q
-`, shellSetup, baseCmd, prompt, launcherFile)
+`, shellSetup, baseCmd, eof, prompt, eof, launcherFile)
 	case AgentOpenCode:
 		// opencode uses 'run' subcommand
 		script = fmt.Sprintf(`#!/bin/bash
 %s
-%s run "$(cat <<'SIDECAR_PROMPT_EOF'
+%s run "$(cat <<'%s'
+%s
 %s
-SIDECAR_PROMPT_EOF
 )"
 rm -f %q
-`, shellSetup, baseCmd, prompt, launcherFile)
+`, shellSetup, baseCmd, eof, prompt, eof, launcherFile)
 	default:
 		// Most agents (claude, codex, gemini, cursor) take prompt as positional argument
 		script = fmt.Sprintf(`#!/bin/bash
 %s
-%s "$(cat <<'SIDECAR_PROMPT_EOF'
+%s "$(cat <<'%s'
+%s
 %s
-SIDECAR_PROMPT_EOF
 )"
 rm -f %q
-`, shellSetup, baseCmd, prompt, launcherFile)
+`, shellSetup, baseCmd, eof, prompt, eof, launcherFile)
 	}
 
 	if err := os.WriteFile(launcherFile, []byte(script), 0700); err != nil {
@@ -525,6 +536,20 @@ rm -f %q
 	return "bash " + shellQuote(launcherFile), nil
 }
 
+// heredocDelimiter returns a heredoc delimiter that does not occur in body.
+func heredocDelimiter(body string) (string, error) {
+	for {
+		b := make([]byte, 8)
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		eof := "SIDECAR_PROMPT_EOF_" + hex.EncodeToString(b)
+		if !strings.Contains(body, eof) {
+			return eof, nil
+		}
+	}
+}
+
 // getAgentCommandWithContext returns the agent command with optional task context (legacy, no skip perms).
 func (p *Plugin) getAgentCommandWithContext(agentType AgentType, wt *Worktree) string {
 	return p.buildAgentCommand(agentType, wt, false, nil)
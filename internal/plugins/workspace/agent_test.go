@@ -2,6 +2,8 @@ package workspace
 
 import (
 	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -917,6 +919,48 @@ func TestWriteAgentLauncher(t *testing.T) {
 	}
 }
 
+func TestWriteAgentLauncher_DelimiterInPrompt(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+	tmpDir := t.TempDir()
+	p := &Plugin{}
+
+	// A prompt line equal to the delimiter must not end the heredoc
+	prompt := "before\nSIDECAR_PROMPT_EOF\n)\"\ntouch pwned\nafter"
+	cmd, err := p.writeAgentLauncher(tmpDir, AgentClaude, "printf %s", prompt)
+	if err != nil {
+		t.Fatalf("writeAgentLauncher failed: %v", err)
+	}
+	run := exec.Command("bash", "-c", cmd)
+	run.Dir = tmpDir
+	out, err := run.Output()
+	if err != nil {
+		t.Fatalf("launcher failed: %v", err)
+	}
+	if string(out) != prompt {
+		t.Errorf("agent got %q, want the whole prompt", out)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "pwned")); err == nil {
+		t.Error("prompt content ran as shell")
+	}
+}
+
+func TestBuildAgentCommand_RawPrompt(t *testing.T) {
+	tmpDir := t.TempDir()
+	p := &Plugin{}
+	wt := &Worktree{Path: tmpDir, TaskID: "td-123"}
+
+	p.buildAgentCommand(AgentClaude, wt, false, handoffPrompt("see {{ticket}}"))
+	script, err := os.ReadFile(filepath.Join(tmpDir, ".sidecar-start.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(script), "see {{ticket}}") {
+		t.Errorf("handoff prompt should not be expanded:\n%s", script)
+	}
+}
+
 func TestExtractLastNLines(t *testing.T) {
 	tests := []struct {
 		name     string
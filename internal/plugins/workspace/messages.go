@@ -212,12 +212,16 @@ type ResumeConversationMsg struct {
 	SessionID string // Adapter session ID for resume command
 	AdapterID string // Adapter type (claude-code, codex, etc.)
 	ResumeCmd string // Full resume command (e.g., "claude --resume xyz")
-	Type      string // "shell" or "worktree"
+	Type      string // "shell", "worktree", or "existing" (handoff into an existing worktree)
 	// Worktree-specific fields (only used when Type == "worktree")
 	WorktreeName string    // Branch name for new worktree
 	BaseBranch   string    // Base branch to create from
 	AgentType    AgentType // Agent to start (matches adapter or user selection)
 	SkipPerms    bool      // Whether to auto-approve agent actions
+	// Handoff fields: when HandoffPrompt is set, AgentType starts fresh with
+	// it as the initial prompt instead of running ResumeCmd
+	HandoffPrompt string
+	WorktreePath  string // Existing worktree to start in (Type == "existing")
 }
 
 // cursorPositionMsg delivers async cursor position updates for interactive mode (td-648af4).
@@ -303,6 +303,7 @@ type Plugin struct {
 
 // Resume conversation state (td-aa4136)
 	pendingResumeCmd      string // Resume command to inject after shell creation
+	pendingResumeRun      bool   // Execute pendingResumeCmd instead of only typing it
 	pendingResumeWorktree string // Worktree name to enter interactive mode after agent starts
 
 	// Fetch PR modal state
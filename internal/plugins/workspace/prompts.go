@@ -24,6 +24,7 @@ type Prompt struct {
 	TicketMode TicketMode `json:"ticketMode"`
 	Body       string     `json:"body"`
 	Source     string     `json:"-"` // "global" or "project" (set at load time)
+	Raw        bool       `json:"-"` // Body is passed as-is, without template expansion
 }
 
 // configWithPrompts is the config structure for loading prompts.
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/app"
 	"github.com/marcus/sidecar/internal/features"
 	"github.com/marcus/sidecar/internal/state"
 	"github.com/marcus/sidecar/internal/tty"
@@ -938,6 +939,8 @@ func (p *Plugin) handleResumeConversation(msg ResumeConversationMsg) (*Plugin, t
 		return p, p.createShellWithResume(msg.ResumeCmd)
 	case "worktree":
 		return p, p.createWorktreeWithResume(msg)
+	case "existing":
+		return p, p.startHandoffInWorktree(msg)
 	default:
 		return p, nil
 	}
@@ -948,21 +951,34 @@ func (p *Plugin) handleResumeConversation(msg ResumeConversationMsg) (*Plugin, t
 func (p *Plugin) createShellWithResume(resumeCmd string) tea.Cmd {
 	// Store pending resume command to inject after shell creation
 	p.pendingResumeCmd = resumeCmd
+	p.pendingResumeRun = false
 
 	// Create new shell (ShellCreatedMsg will trigger command injection)
 	return p.createNewShell("")
 }
 
-// sendResumeCommandToShell injects a command into the shell without executing it.
-func (p *Plugin) sendResumeCommandToShell(tmuxSession string, resumeCmd string) tea.Cmd {
+// createShellWithCommand creates a new shell and runs command in it.
+func (p *Plugin) createShellWithCommand(command string) tea.Cmd {
+	p.pendingResumeCmd = command
+	p.pendingResumeRun = true
+	return p.createNewShell("")
+}
+
+// sendResumeCommandToShell injects a command into the shell, executing it
+// only when run is set.
+func (p *Plugin) sendResumeCommandToShell(tmuxSession string, resumeCmd string, run bool) tea.Cmd {
 	if !isTmuxInstalled() {
 		return nil
 	}
 
 	return func() tea.Msg {
-		// Use tmux send-keys to type the command without pressing Enter
-		// This lets the user review before executing
-		cmd := exec.Command("tmux", "send-keys", "-t", tmuxSession, resumeCmd)
+		// Type the command; resumes skip Enter so the user can review
+		// them before executing
+		args := []string{"send-keys", "-t", tmuxSession, resumeCmd}
+		if run {
+			args = append(args, "Enter")
+		}
+		cmd := exec.Command("tmux", args...)
 		if err := cmd.Run(); err != nil {
 			return shellResumeErrorMsg{Err: err}
 		}
@@ -982,11 +998,12 @@ type shellResumeErrorMsg struct {
 
 // worktreeResumeCreatedMsg signals that a worktree for resume was created (td-aa4136).
 type worktreeResumeCreatedMsg struct {
-	Worktree  *Worktree
-	ResumeCmd string
-	AgentType AgentType
-	SkipPerms bool
-	Err       error
+	Worktree      *Worktree
+	ResumeCmd     string
+	HandoffPrompt string
+	AgentType     AgentType
+	SkipPerms     bool
+	Err           error
 }
 
 // createWorktreeWithResume creates a new worktree and starts the agent with the resume command.
@@ -1011,14 +1028,55 @@ func (p *Plugin) createWorktreeWithResume(msg ResumeConversationMsg) tea.Cmd {
 		}
 
 		return worktreeResumeCreatedMsg{
-			Worktree:  wt,
-			ResumeCmd: resumeCmd,
-			AgentType: agentType,
-			SkipPerms: skipPerms,
+			Worktree:      wt,
+			ResumeCmd:     resumeCmd,
+			HandoffPrompt: msg.HandoffPrompt,
+			AgentType:     agentType,
+			SkipPerms:     skipPerms,
 		}
 	}
 }
 
+// startHandoffInWorktree starts a handoff agent in an existing worktree with
+// the handoff context as its initial prompt.
+func (p *Plugin) startHandoffInWorktree(msg ResumeConversationMsg) tea.Cmd {
+	idx := -1
+	for i, wt := range p.worktrees {
+		if filepath.Clean(wt.Path) == filepath.Clean(msg.WorktreePath) {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return func() tea.Msg {
+			return app.ToastMsg{Message: "Workspace not found: " + msg.WorktreePath, Duration: 3 * time.Second, IsError: true}
+		}
+	}
+	wt := p.worktrees[idx]
+	if wt.Agent != nil {
+		return func() tea.Msg {
+			return app.ToastMsg{Message: wt.Name + " already has a running agent", Duration: 3 * time.Second, IsError: true}
+		}
+	}
+
+	p.shellSelected = false
+	p.selectedIdx = idx
+	p.previewOffset = 0
+	p.autoScrollOutput = true
+	p.resetScrollBaseLineCount()
+	p.saveSelectionState()
+	p.ensureVisible()
+	p.pendingResumeWorktree = wt.Name
+
+	return p.StartAgentWithOptions(wt, msg.AgentType, msg.SkipPerms, handoffPrompt(msg.HandoffPrompt))
+}
+
+// handoffPrompt wraps handoff context as a prompt for agent startup. The
+// context quotes session content, so it is not expanded as a template.
+func handoffPrompt(body string) *Prompt {
+	return &Prompt{Name: "Handoff", Body: body, Raw: true}
+}
+
 // startAgentWithResumeCmd starts an agent in a worktree with a resume command instead of normal startup.
 func (p *Plugin) startAgentWithResumeCmd(wt *Worktree, agentType AgentType, skipPerms bool, resumeCmd string) tea.Cmd {
 	epoch := p.ctx.Epoch // Capture epoch for stale detection
@@ -9,6 +9,7 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	app "github.com/marcus/sidecar/internal/app"
+	appmsg "github.com/marcus/sidecar/internal/msg"
 	"github.com/marcus/sidecar/internal/plugin"
 	"github.com/marcus/sidecar/internal/plugins/gitstatus"
 )
@@ -520,6 +521,7 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		if msg.Err != nil {
 			// Creation failed, show error toast
 			p.pendingResumeCmd = "" // Clear pending resume
+			p.pendingResumeRun = false
 			return p, func() tea.Msg {
 				return app.ToastMsg{Message: msg.Err.Error(), Duration: 5 * time.Second, IsError: true}
 			}
@@ -608,9 +610,10 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 
 		// If there's a pending resume command, inject it and enter interactive mode (td-aa4136)
 		if p.pendingResumeCmd != "" {
-			resumeCmd := p.pendingResumeCmd
+			resumeCmd, run := p.pendingResumeCmd, p.pendingResumeRun
 			p.pendingResumeCmd = "" // Clear pending command
-			cmds = append(cmds, p.sendResumeCommandToShell(msg.SessionName, resumeCmd))
+			p.pendingResumeRun = false
+			cmds = append(cmds, p.sendResumeCommandToShell(msg.SessionName, resumeCmd, run))
 			// Enter interactive mode after command is injected
 			cmds = append(cmds, func() tea.Msg { return shellResumeInjectedMsg{TmuxSession: msg.SessionName} })
 		} else if msg.AgentType != AgentNone && msg.AgentType != "" {
@@ -710,6 +713,11 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		// Store pending resume state to enter interactive mode after agent starts
 		p.pendingResumeWorktree = msg.Worktree.Name
 
+		// Handoffs start a fresh agent with the generated context
+		if msg.HandoffPrompt != "" {
+			return p, p.StartAgentWithOptions(msg.Worktree, msg.AgentType, msg.SkipPerms, handoffPrompt(msg.HandoffPrompt))
+		}
+
 		// Start agent with resume command
 		return p, p.startAgentWithResumeCmd(msg.Worktree, msg.AgentType, msg.SkipPerms, msg.ResumeCmd)
 
@@ -1286,6 +1294,10 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		// Handle resume from conversations plugin (td-aa4136)
 		return p.handleResumeConversation(msg)
 
+	case appmsg.RunInShellMsg:
+		// Run a command from another plugin (e.g. git bisect run) in a new shell
+		return p, p.createShellWithCommand(msg.Command)
+
 	case cursorPositionMsg:
 		// Update cached cursor position for interactive mode rendering (td-648af4)
 		if p.interactiveState != nil && p.interactiveState.Active {
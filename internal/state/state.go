@@ -28,6 +28,9 @@ type State struct {
 
 	// Worktree state: maps main repo path -> last active worktree path
 	LastWorktreePath map[string]string `json:"lastWorktreePath,omitempty"`
+
+	// Named conversation search queries: name -> query
+	SavedQueries map[string]string `json:"savedQueries,omitempty"`
 }
 
 // FileBrowserTabState holds persistent tab state for the file browser.
@@ -409,6 +412,39 @@ func ClearLastWorktreePath(mainRepoPath string) error {
 	return Save()
 }
 
+// GetSavedQueries returns a copy of the saved conversation search queries.
+func GetSavedQueries() map[string]string {
+	mu.RLock()
+	defer mu.RUnlock()
+	if current == nil || len(current.SavedQueries) == 0 {
+		return nil
+	}
+	queries := make(map[string]string, len(current.SavedQueries))
+	for name, q := range current.SavedQueries {
+		queries[name] = q
+	}
+	return queries
+}
+
+// SetSavedQuery saves a named conversation search query. An empty query
+// deletes the name.
+func SetSavedQuery(name, query string) error {
+	mu.Lock()
+	if current == nil {
+		current = &State{}
+	}
+	if query == "" {
+		delete(current.SavedQueries, name)
+	} else {
+		if current.SavedQueries == nil {
+			current.SavedQueries = make(map[string]string)
+		}
+		current.SavedQueries[name] = query
+	}
+	mu.Unlock()
+	return Save()
+}
+
 // GetNotesState returns the saved notes state for a given working directory.
 func GetNotesState(workdir string) NotesState {
 	mu.RLock()
@@ -754,3 +754,50 @@ func TestSetLineWrapEnabled_InitializesNilState(t *testing.T) {
 		t.Errorf("LineWrapEnabled = %v, want true", current.LineWrapEnabled)
 	}
 }
+
+func TestSavedQueries(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalPath := path
+	originalCurrent := current
+	defer func() {
+		path = originalPath
+		current = originalCurrent
+	}()
+
+	path = filepath.Join(tmpDir, "state.json")
+	current = nil
+
+	if got := GetSavedQueries(); got != nil {
+		t.Errorf("GetSavedQueries() = %v, want nil", got)
+	}
+	if err := SetSavedQuery("costly", "cost>2"); err != nil {
+		t.Fatalf("SetSavedQuery() failed: %v", err)
+	}
+	if err := SetSavedQuery("bash", "has:tool:Bash"); err != nil {
+		t.Fatalf("SetSavedQuery() failed: %v", err)
+	}
+
+	// The returned map is a copy
+	got := GetSavedQueries()
+	got["costly"] = "changed"
+	if q := GetSavedQueries()["costly"]; q != "cost>2" {
+		t.Errorf("saved query = %q, want %q", q, "cost>2")
+	}
+
+	// Saved queries survive a reload
+	current = nil
+	if err := Load(); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if q := GetSavedQueries()["bash"]; q != "has:tool:Bash" {
+		t.Errorf("loaded query = %q, want %q", q, "has:tool:Bash")
+	}
+
+	// An empty query deletes the name
+	if err := SetSavedQuery("bash", ""); err != nil {
+		t.Fatalf("SetSavedQuery() failed: %v", err)
+	}
+	if _, ok := GetSavedQueries()["bash"]; ok {
+		t.Error("empty query should delete the saved query")
+	}
+}
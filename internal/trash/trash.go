@@ -0,0 +1,434 @@
+// Package trash moves deleted files into a recoverable trash instead of
+// removing them. On Linux it uses the freedesktop.org home trash
+// ($XDG_DATA_HOME/Trash) so items also show up in desktop file managers;
+// elsewhere, or when the home trash is on another filesystem, items go to
+// .sidecar/trash in the project. Both use the spec's files/ and info/
+// layout, with a .trashinfo file recording the original path and time.
+//
+// Expiry only purges items sidecar trashed itself: everything in the
+// project trash, and home trash items listed in the project's manifest.
+// Items other programs put in the home trash are left to the desktop.
+package trash
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	infoExt      = ".trashinfo"
+	manifestName = "owned" // In the project trash; home trash items sidecar put there
+	dateLayout = "2006-01-02T15:04:05" // DeletionDate format from the spec, local time
+
+	// DefaultMaxAge is how long items stay in the trash before expiring.
+	DefaultMaxAge = 30 * 24 * time.Hour
+	// DefaultMaxSize is the total size of a project's trashed items above
+	// which the oldest expire.
+	DefaultMaxSize int64 = 1 << 30
+)
+
+// ErrExists is returned when restoring over an existing path.
+var ErrExists = errors.New("original path already exists")
+
+// Item is a trashed file or directory.
+type Item struct {
+	Name         string    // Entry name inside the trash's files/ directory
+	OriginalPath string    // Absolute path the item was deleted from
+	DeletedAt    time.Time // When the item was trashed
+	Size         int64     // Total size of the item's regular files
+	IsDir        bool
+	Dir          string // Trash directory holding the item
+}
+
+// Path returns where the item's data lives inside the trash.
+func (it Item) Path() string {
+	return filepath.Join(it.Dir, "files", it.Name)
+}
+
+func (it Item) infoPath() string {
+	return filepath.Join(it.Dir, "info", it.Name+infoExt)
+}
+
+// Trash stores deleted items for one project.
+type Trash struct {
+	root     string // Absolute project root; only items from here are listed
+	home     string // Home trash directory, empty when not used
+	fallback string // Project trash directory
+}
+
+// New returns the trash for a project rooted at root.
+func New(root string) *Trash {
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	t := &Trash{
+		root:     root,
+		fallback: filepath.Join(root, ".sidecar", "trash"),
+	}
+	if runtime.GOOS == "linux" {
+		t.home = homeTrashDir()
+	}
+	return t
+}
+
+// homeTrashDir returns the freedesktop.org home trash directory.
+func homeTrashDir() string {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dataHome, "Trash")
+}
+
+// Put moves path into the trash. The home trash is tried first; items on
+// a different filesystem go to the project trash.
+func (t *Trash) Put(path string) (Item, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return Item{}, err
+	}
+	info, err := os.Lstat(abs)
+	if err != nil {
+		return Item{}, err
+	}
+	if abs == t.root || abs == t.fallback ||
+		strings.HasPrefix(t.fallback, abs+string(filepath.Separator)) ||
+		strings.HasPrefix(abs, t.fallback+string(filepath.Separator)) {
+		return Item{}, fmt.Errorf("cannot trash %s", filepath.Base(abs))
+	}
+
+	item := Item{
+		OriginalPath: abs,
+		DeletedAt:    time.Now().Truncate(time.Second),
+		IsDir:        info.IsDir(),
+	}
+	item.Size = PathSize(abs)
+
+	var lastErr error
+	for _, dir := range []string{t.home, t.fallback} {
+		if dir == "" {
+			continue
+		}
+		item.Dir = dir
+		if err := t.moveInto(&item); err != nil {
+			lastErr = err
+			continue
+		}
+		if dir == t.home {
+			// Without a manifest entry the item is only kept, never expired
+			_ = t.markOwned(item)
+		}
+		return item, nil
+	}
+	return Item{}, lastErr
+}
+
+// moveInto claims a unique name in item.Dir by creating its info file, then
+// moves the data in. The info file is removed again if the move fails.
+func (t *Trash) moveInto(item *Item) error {
+	if err := os.MkdirAll(filepath.Join(item.Dir, "files"), 0700); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Join(item.Dir, "info"), 0700); err != nil {
+		return err
+	}
+
+	base := filepath.Base(item.OriginalPath)
+	for n := 1; ; n++ {
+		item.Name = base
+		if n > 1 {
+			item.Name = base + "." + strconv.Itoa(n)
+		}
+		f, err := os.OpenFile(item.infoPath(), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := os.Lstat(item.Path()); err == nil {
+			// Data without info: leave it alone and pick another name
+			_ = f.Close()
+			_ = os.Remove(item.infoPath())
+			continue
+		}
+		_, err = fmt.Fprintf(f, "[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+			(&url.URL{Path: item.OriginalPath}).EscapedPath(), item.DeletedAt.Format(dateLayout))
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = os.Rename(item.OriginalPath, item.Path())
+		}
+		if err != nil {
+			_ = os.Remove(item.infoPath())
+			return err
+		}
+		return nil
+	}
+}
+
+// List returns the items trashed from this project, newest first.
+func (t *Trash) List() ([]Item, error) {
+	var items []Item
+	for _, dir := range []string{t.home, t.fallback} {
+		if dir == "" {
+			continue
+		}
+		found, err := t.listDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, found...)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+	return items, nil
+}
+
+// listDir reads the info files of one trash directory.
+func (t *Trash) listDir(dir string) ([]Item, error) {
+	entries, err := os.ReadDir(filepath.Join(dir, "info"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var items []Item
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), infoExt) {
+			continue
+		}
+		item, err := readInfo(dir, strings.TrimSuffix(e.Name(), infoExt))
+		if err != nil || !t.inProject(item.OriginalPath) {
+			continue
+		}
+		info, err := os.Lstat(item.Path())
+		if err != nil {
+			continue // Info without data, e.g. purged by a file manager
+		}
+		item.IsDir = info.IsDir()
+		item.Size = PathSize(item.Path())
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// inProject reports whether path is inside the project root.
+func (t *Trash) inProject(path string) bool {
+	return path == t.root || strings.HasPrefix(path, t.root+string(filepath.Separator))
+}
+
+// readInfo parses a .trashinfo file.
+func readInfo(dir, name string) (Item, error) {
+	item := Item{Name: name, Dir: dir}
+	f, err := os.Open(item.infoPath())
+	if err != nil {
+		return item, err
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "Path":
+			p, err := url.PathUnescape(value)
+			if err != nil {
+				return item, err
+			}
+			if !filepath.IsAbs(p) {
+				// Relative paths are relative to the trash's parent directory
+				p = filepath.Join(filepath.Dir(dir), p)
+			}
+			item.OriginalPath = filepath.Clean(p)
+		case "DeletionDate":
+			if ts, err := time.ParseInLocation(dateLayout, value, time.Local); err == nil {
+				item.DeletedAt = ts
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return item, err
+	}
+	if item.OriginalPath == "" {
+		return item, errors.New("trash info without Path")
+	}
+	return item, nil
+}
+
+// Restore moves an item back to its original path, recreating missing
+// parent directories. It fails with ErrExists rather than overwrite.
+func (t *Trash) Restore(item Item) error {
+	if _, err := os.Lstat(item.OriginalPath); err == nil {
+		return fmt.Errorf("%w: %s", ErrExists, item.OriginalPath)
+	}
+	if err := os.MkdirAll(filepath.Dir(item.OriginalPath), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(item.Path(), item.OriginalPath); err != nil {
+		return err
+	}
+	t.unmarkOwned(item)
+	return os.Remove(item.infoPath())
+}
+
+// Purge permanently deletes an item.
+func (t *Trash) Purge(item Item) error {
+	if err := os.RemoveAll(item.Path()); err != nil {
+		return err
+	}
+	t.unmarkOwned(item)
+	if err := os.Remove(item.infoPath()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Expire purges this project's items older than maxAge, then the oldest
+// remaining ones until their total size is at most maxSize. Zero limits
+// are ignored. Only items sidecar trashed count (see the package doc). It
+// returns the number of items purged.
+func (t *Trash) Expire(maxAge time.Duration, maxSize int64) (int, error) {
+	listed, err := t.List()
+	if err != nil {
+		return 0, err
+	}
+	owned := t.readOwned()
+	var items []Item
+	present := make(map[string]bool, len(owned))
+	for _, it := range listed {
+		switch {
+		case it.Dir == t.fallback:
+			items = append(items, it)
+		case owned[ownedKey(it)]:
+			items = append(items, it)
+			present[ownedKey(it)] = true
+		}
+	}
+	// Forget entries whose item was emptied elsewhere, so a later item
+	// that reuses the name isn't mistaken for ours
+	if len(present) != len(owned) {
+		_ = t.writeOwned(present)
+	}
+
+	var total int64
+	for _, it := range items {
+		total += it.Size
+	}
+
+	purged := 0
+	now := time.Now()
+	// Oldest first so the size limit drops the oldest items
+	for i := len(items) - 1; i >= 0; i-- {
+		it := items[i]
+		tooOld := maxAge > 0 && now.Sub(it.DeletedAt) > maxAge
+		tooBig := maxSize > 0 && total > maxSize
+		if !tooOld && !tooBig {
+			continue
+		}
+		if err := t.Purge(it); err != nil {
+			return purged, err
+		}
+		total -= it.Size
+		purged++
+	}
+	return purged, nil
+}
+
+// ownedKey identifies a home trash item in the manifest. The deletion time
+// tells it apart from a later item given the same name.
+func ownedKey(it Item) string {
+	return it.Name + "\t" + it.DeletedAt.Format(dateLayout)
+}
+
+func (t *Trash) manifestPath() string {
+	return filepath.Join(t.fallback, manifestName)
+}
+
+// readOwned loads the manifest of home trash items sidecar put there.
+func (t *Trash) readOwned() map[string]bool {
+	owned := make(map[string]bool)
+	data, err := os.ReadFile(t.manifestPath())
+	if err != nil {
+		return owned
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			owned[line] = true
+		}
+	}
+	return owned
+}
+
+// writeOwned replaces the manifest.
+func (t *Trash) writeOwned(owned map[string]bool) error {
+	keys := make([]string, 0, len(owned))
+	for k := range owned {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if err := os.MkdirAll(t.fallback, 0700); err != nil {
+		return err
+	}
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k + "\n")
+	}
+	return os.WriteFile(t.manifestPath(), []byte(b.String()), 0600)
+}
+
+// markOwned records a home trash item in the manifest.
+func (t *Trash) markOwned(item Item) error {
+	owned := t.readOwned()
+	owned[ownedKey(item)] = true
+	return t.writeOwned(owned)
+}
+
+// unmarkOwned drops a home trash item from the manifest.
+func (t *Trash) unmarkOwned(item Item) {
+	if item.Dir != t.home || t.home == "" {
+		return
+	}
+	owned := t.readOwned()
+	if owned[ownedKey(item)] {
+		delete(owned, ownedKey(item))
+		_ = t.writeOwned(owned)
+	}
+}
+
+// PathSize returns the total size of the regular files under p.
+func PathSize(p string) int64 {
+	var total int64
+	_ = filepath.WalkDir(p, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total
+}
@@ -0,0 +1,204 @@
+package trash
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestTrash(t *testing.T) (*Trash, string) {
+	t.Helper()
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	root := t.TempDir()
+	return New(root), root
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPutListRestore(t *testing.T) {
+	tr, root := newTestTrash(t)
+	file := filepath.Join(root, "src", "a b%.txt")
+	writeFile(t, file, "hello")
+
+	item, err := tr.Put(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Fatal("file should be gone after Put")
+	}
+	info, err := os.ReadFile(item.infoPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(info), "[Trash Info]\nPath=") || !strings.Contains(string(info), "a%20b%25.txt") {
+		t.Errorf("info file:\n%s", info)
+	}
+
+	items, err := tr.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].OriginalPath != file || items[0].Size != 5 || items[0].IsDir {
+		t.Fatalf("List = %+v", items)
+	}
+
+	// Restore recreates the parent directory
+	if err := os.RemoveAll(filepath.Join(root, "src")); err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Restore(items[0]); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(file); err != nil || string(data) != "hello" {
+		t.Fatalf("restored content = %q, %v", data, err)
+	}
+	if items, _ := tr.List(); len(items) != 0 {
+		t.Errorf("trash should be empty after restore, got %d", len(items))
+	}
+}
+
+func TestPutNameCollisions(t *testing.T) {
+	tr, root := newTestTrash(t)
+	file := filepath.Join(root, "x.txt")
+	var names []string
+	for i := 0; i < 3; i++ {
+		writeFile(t, file, strings.Repeat("x", i))
+		item, err := tr.Put(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, item.Name)
+	}
+	if names[0] != "x.txt" || names[1] != "x.txt.2" || names[2] != "x.txt.3" {
+		t.Errorf("names = %v", names)
+	}
+
+	// Restoring over an existing file is refused
+	items, _ := tr.List()
+	writeFile(t, file, "new")
+	if err := tr.Restore(items[0]); !errors.Is(err, ErrExists) {
+		t.Errorf("Restore over existing file: err = %v", err)
+	}
+}
+
+func TestListOnlyProjectItems(t *testing.T) {
+	tr, root := newTestTrash(t)
+	other := New(t.TempDir())
+	otherFile := filepath.Join(other.root, "other.txt")
+	writeFile(t, otherFile, "x")
+	if _, err := other.Put(otherFile); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(root, "dir")
+	writeFile(t, filepath.Join(dir, "inner.txt"), "abc")
+	if _, err := tr.Put(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := tr.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || !items[0].IsDir || items[0].Size != 3 {
+		t.Errorf("List = %+v", items)
+	}
+}
+
+func TestPutRefusesRootAndTrash(t *testing.T) {
+	tr, root := newTestTrash(t)
+	if _, err := tr.Put(root); err == nil {
+		t.Error("trashing the project root should fail")
+	}
+	writeFile(t, filepath.Join(root, ".sidecar", "state.json"), "{}")
+	if _, err := tr.Put(filepath.Join(root, ".sidecar")); err == nil {
+		t.Error("trashing the directory holding the trash should fail")
+	}
+}
+
+func TestFallbackTrash(t *testing.T) {
+	root := t.TempDir()
+	tr := New(root)
+	tr.home = "" // As on non-Linux systems
+	file := filepath.Join(root, "f.txt")
+	writeFile(t, file, "data")
+
+	item, err := tr.Put(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Dir != filepath.Join(root, ".sidecar", "trash") {
+		t.Errorf("item stored in %s", item.Dir)
+	}
+	if err := tr.Purge(item); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(item.Path()); !os.IsNotExist(err) {
+		t.Error("purge should remove the data")
+	}
+}
+
+func TestExpire(t *testing.T) {
+	tr, root := newTestTrash(t)
+	for i, name := range []string{"old.txt", "mid.txt", "new.txt"} {
+		file := filepath.Join(root, name)
+		writeFile(t, file, strings.Repeat("x", 10))
+		item, err := tr.Put(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Backdate the deletion: old 40 days, mid 2 days, new now
+		age := []time.Duration{40 * 24 * time.Hour, 48 * time.Hour, 0}[i]
+		backdate(t, tr, item, age)
+	}
+
+	// An item another program put in the home trash is never expired
+	foreign := Item{Name: "foreign.txt", Dir: tr.home, OriginalPath: filepath.Join(root, "foreign.txt")}
+	writeFile(t, foreign.Path(), strings.Repeat("x", 100))
+	writeFile(t, foreign.infoPath(), "[Trash Info]\nPath="+foreign.OriginalPath+"\nDeletionDate="+
+		time.Now().Add(-60*24*time.Hour).Format(dateLayout)+"\n")
+
+	n, err := tr.Expire(DefaultMaxAge, 0)
+	if err != nil || n != 1 {
+		t.Fatalf("age expiry purged %d, %v", n, err)
+	}
+	n, err = tr.Expire(0, 15)
+	if err != nil || n != 1 {
+		t.Fatalf("size expiry purged %d, %v", n, err)
+	}
+	items, _ := tr.List()
+	var names []string
+	for _, it := range items {
+		names = append(names, filepath.Base(it.OriginalPath))
+	}
+	if strings.Join(names, ",") != "new.txt,foreign.txt" {
+		t.Errorf("remaining = %v", names)
+	}
+}
+
+// backdate rewrites an item's deletion time, keeping the manifest in step.
+func backdate(t *testing.T, tr *Trash, item Item, age time.Duration) {
+	t.Helper()
+	tr.unmarkOwned(item)
+	item.DeletedAt = time.Now().Add(-age).Truncate(time.Second)
+	content := "[Trash Info]\nPath=" + item.OriginalPath + "\nDeletionDate=" + item.DeletedAt.Format(dateLayout) + "\n"
+	if err := os.WriteFile(item.infoPath(), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if item.Dir == tr.home {
+		if err := tr.markOwned(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+}